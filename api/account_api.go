@@ -3,9 +3,13 @@ package api
 import (
 	"github.com/idena-network/idena-go/common"
 	"github.com/idena-network/idena-go/keystore"
+	"github.com/pkg/errors"
 	"time"
 )
 
+// maxDerivedAccounts limits the number of accounts derived by one call since their addresses are returned at once
+const maxDerivedAccounts = 1000
+
 // NetApi offers helper utils
 type AccountApi struct {
 	baseApi *BaseApi
@@ -37,3 +41,37 @@ func (api *AccountApi) Unlock(addr common.Address, passPhrase string, timeout ti
 func (api *AccountApi) Lock(addr common.Address) error {
 	return api.baseApi.ks.Lock(addr)
 }
+
+// ImportMnemonic creates the HD wallet from the BIP-39 mnemonic, passPhrase encrypts the seed and unlocks derived
+// accounts, basePath is m/44'/60'/0'/0 by default
+func (api *AccountApi) ImportMnemonic(mnemonic string, mnemonicPassword string, passPhrase string, basePath *string) error {
+	var path keystore.DerivationPath
+	if basePath != nil {
+		var err error
+		if path, err = keystore.ParseDerivationPath(*basePath); err != nil {
+			return err
+		}
+	}
+	return api.baseApi.ks.ImportMnemonic(mnemonic, mnemonicPassword, passPhrase, path)
+}
+
+// Derive derives next count accounts of the HD wallet
+func (api *AccountApi) Derive(count int, passPhrase string) ([]keystore.HDAccount, error) {
+	if count > maxDerivedAccounts {
+		return nil, errors.Errorf("count exceeds %v", maxDerivedAccounts)
+	}
+	return api.baseApi.ks.DeriveAccounts(count, passPhrase)
+}
+
+func (api *AccountApi) ListDerived() ([]keystore.HDAccount, error) {
+	return api.baseApi.ks.HDAccounts()
+}
+
+// Export returns the key of the account as the key file JSON encrypted with newPassPhrase
+func (api *AccountApi) Export(addr common.Address, passPhrase string, newPassPhrase string) (string, error) {
+	if newPassPhrase == "" {
+		return "", errors.New("new passphrase should not be empty")
+	}
+	keyJSON, err := api.baseApi.ks.Export(keystore.Account{Address: addr}, passPhrase, newPassPhrase)
+	return string(keyJSON), err
+}
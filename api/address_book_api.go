@@ -0,0 +1,43 @@
+package api
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/core/addressbook"
+)
+
+// AddressBookApi maintains local labels of addresses which annotate identities and delegators in responses
+type AddressBookApi struct {
+	book *addressbook.Book
+}
+
+// NewAddressBookApi creates a new AddressBookApi instance
+func NewAddressBookApi(book *addressbook.Book) *AddressBookApi {
+	return &AddressBookApi{book}
+}
+
+type SetAddressLabelArgs struct {
+	Address common.Address `json:"address"`
+	Label   string         `json:"label"`
+	Note    string         `json:"note"`
+}
+
+func (api *AddressBookApi) Set(args SetAddressLabelArgs) (*addressbook.Entry, error) {
+	return api.book.Set(args.Address, args.Label, args.Note)
+}
+
+func (api *AddressBookApi) Remove(address common.Address) bool {
+	return api.book.Remove(address)
+}
+
+func (api *AddressBookApi) Get(address common.Address) *addressbook.Entry {
+	return api.book.Get(address)
+}
+
+func (api *AddressBookApi) List() []*addressbook.Entry {
+	return api.book.List()
+}
+
+// Label returns the label of the address, automatic labels of the node addresses included
+func (api *AddressBookApi) Label(address common.Address) string {
+	return api.book.Label(address)
+}
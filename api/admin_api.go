@@ -0,0 +1,198 @@
+package api
+
+import (
+	"context"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/consensus"
+	"github.com/idena-network/idena-go/log"
+	"github.com/idena-network/idena-go/protocol"
+	"github.com/idena-network/idena-go/rpc"
+	"github.com/pkg/errors"
+)
+
+type nodeAdmin interface {
+	ReloadConfig() ([]string, error)
+	EffectiveConfig() (*config.Config, error)
+	Compact() error
+	Compacting() bool
+	RotateLog() error
+	RotateApiKey(key string) (string, error)
+	Shutdown()
+	AddPriorityPeer(url string, static, trusted bool) error
+	RemovePriorityPeer(id string) error
+}
+
+// AdminApi offers node runtime settings and lifecycle management, all requests must be made with the API key or JWT
+type AdminApi struct {
+	node   nodeAdmin
+	engine *consensus.Engine
+	pm     *protocol.IdenaGossipHandler
+}
+
+// NewAdminApi creates a new AdminApi instance
+func NewAdminApi(node nodeAdmin, engine *consensus.Engine, pm *protocol.IdenaGossipHandler) *AdminApi {
+	return &AdminApi{node, engine, pm}
+}
+
+func authorize(ctx context.Context, action string) error {
+	if !rpc.IsAuthenticated(ctx) {
+		return errors.New("authentication is required")
+	}
+	log.Info("Admin request", "action", action, "ip", ctx.Value("remote"))
+	return nil
+}
+
+// ReloadConfig reloads the config file and applies settings which can be changed without restart,
+// names of changed settings are returned
+func (api *AdminApi) ReloadConfig(ctx context.Context) ([]string, error) {
+	if err := authorize(ctx, "reloadConfig"); err != nil {
+		return nil, err
+	}
+	return api.node.ReloadConfig()
+}
+
+// GetConfig returns the effective node config with secrets redacted
+func (api *AdminApi) GetConfig(ctx context.Context) (*config.Config, error) {
+	if !rpc.IsAuthenticated(ctx) {
+		return nil, errors.New("authentication is required")
+	}
+	return api.node.EffectiveConfig()
+}
+
+type NodeStatus struct {
+	MiningPaused bool `json:"miningPaused"`
+	ImportPaused bool `json:"importPaused"`
+	Compacting   bool `json:"compacting"`
+	Peers        int  `json:"peers"`
+}
+
+func (api *AdminApi) Status(ctx context.Context) (NodeStatus, error) {
+	if !rpc.IsAuthenticated(ctx) {
+		return NodeStatus{}, errors.New("authentication is required")
+	}
+	return NodeStatus{
+		MiningPaused: api.engine.MiningPaused(),
+		ImportPaused: api.engine.ImportPaused(),
+		Compacting:   api.node.Compacting(),
+		Peers:        api.pm.PeersCount(),
+	}, nil
+}
+
+// AddPeer connects to the peer by its multiaddress
+func (api *AdminApi) AddPeer(ctx context.Context, url string) error {
+	if err := authorize(ctx, "addPeer"); err != nil {
+		return err
+	}
+	return api.pm.AddPeer(url)
+}
+
+// RemovePeer disconnects the peer by its id
+func (api *AdminApi) RemovePeer(ctx context.Context, id string) error {
+	if err := authorize(ctx, "removePeer"); err != nil {
+		return err
+	}
+	return api.pm.RemovePeer(id)
+}
+
+// AddStaticPeer adds the peer which is always redialed and never evicted, the peer is kept across restarts
+func (api *AdminApi) AddStaticPeer(ctx context.Context, url string) error {
+	if err := authorize(ctx, "addStaticPeer"); err != nil {
+		return err
+	}
+	return api.node.AddPriorityPeer(url, true, false)
+}
+
+// AddTrustedPeer adds the peer which is always accepted and never banned, the peer is kept across restarts
+func (api *AdminApi) AddTrustedPeer(ctx context.Context, url string) error {
+	if err := authorize(ctx, "addTrustedPeer"); err != nil {
+		return err
+	}
+	return api.node.AddPriorityPeer(url, false, true)
+}
+
+func (api *AdminApi) RemovePriorityPeer(ctx context.Context, id string) error {
+	if err := authorize(ctx, "removePriorityPeer"); err != nil {
+		return err
+	}
+	return api.node.RemovePriorityPeer(id)
+}
+
+func (api *AdminApi) PriorityPeers(ctx context.Context) ([]protocol.PriorityPeer, error) {
+	if err := authorize(ctx, "priorityPeers"); err != nil {
+		return nil, err
+	}
+	return api.pm.PriorityPeers(), nil
+}
+
+// StartMining resumes proposing blocks and voting
+func (api *AdminApi) StartMining(ctx context.Context) error {
+	if err := authorize(ctx, "startMining"); err != nil {
+		return err
+	}
+	api.engine.SetMiningPaused(false)
+	return nil
+}
+
+// StopMining stops proposing blocks and voting without changing the online status of the identity,
+// the identity still can be penalized for missed rounds
+func (api *AdminApi) StopMining(ctx context.Context) error {
+	if err := authorize(ctx, "stopMining"); err != nil {
+		return err
+	}
+	api.engine.SetMiningPaused(true)
+	return nil
+}
+
+// PauseImport stops syncing and consensus rounds after the current round
+func (api *AdminApi) PauseImport(ctx context.Context) error {
+	if err := authorize(ctx, "pauseImport"); err != nil {
+		return err
+	}
+	api.engine.SetImportPaused(true)
+	return nil
+}
+
+func (api *AdminApi) ResumeImport(ctx context.Context) error {
+	if err := authorize(ctx, "resumeImport"); err != nil {
+		return err
+	}
+	api.engine.SetImportPaused(false)
+	return nil
+}
+
+// Compact starts the database compaction in background
+func (api *AdminApi) Compact(ctx context.Context) error {
+	if err := authorize(ctx, "compact"); err != nil {
+		return err
+	}
+	return api.node.Compact()
+}
+
+func (api *AdminApi) RotateLog(ctx context.Context) error {
+	if err := authorize(ctx, "rotateLog"); err != nil {
+		return err
+	}
+	return api.node.RotateLog()
+}
+
+// RotateApiKey replaces the RPC API key and returns the new one, the key is read from the secret source or generated
+// if key isn't set. Requests with the previous key are rejected after the rotation
+func (api *AdminApi) RotateApiKey(ctx context.Context, key *string) (string, error) {
+	if err := authorize(ctx, "rotateApiKey"); err != nil {
+		return "", err
+	}
+	var value string
+	if key != nil {
+		value = *key
+	}
+	return api.node.RotateApiKey(value)
+}
+
+// Shutdown stops the node gracefully waiting for the current round to finish
+func (api *AdminApi) Shutdown(ctx context.Context) error {
+	if err := authorize(ctx, "shutdown"); err != nil {
+		return err
+	}
+	api.node.Shutdown()
+	return nil
+}
@@ -0,0 +1,23 @@
+package api
+
+import "github.com/idena-network/idena-go/core/backup"
+
+// BackupApi makes encrypted backups of the node key, the API key and pending ceremony artifacts
+type BackupApi struct {
+	backups *backup.Manager
+}
+
+// NewBackupApi creates a new BackupApi instance
+func NewBackupApi(backups *backup.Manager) *BackupApi {
+	return &BackupApi{backups}
+}
+
+// Now writes the backup to the archive directory and the S3 storage if it is configured
+func (api *BackupApi) Now() (*backup.Result, error) {
+	return api.backups.BackupNow()
+}
+
+// Last returns the last backup made since the node start
+func (api *BackupApi) Last() *backup.Result {
+	return api.backups.Last()
+}
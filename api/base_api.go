@@ -47,15 +47,20 @@ func (api *BaseApi) getCurrentCoinbase() common.Address {
 
 func (api *BaseApi) getTx(from common.Address, to *common.Address, txType types.TxType, amount decimal.Decimal,
 	maxFee decimal.Decimal, tips decimal.Decimal, nonce uint32, epoch uint16, payload []byte) *types.Transaction {
+	return buildTx(api.getAppState(), from, to, txType, amount, maxFee, tips, nonce, epoch, payload)
+}
+
+func buildTx(appState *appstate.AppState, from common.Address, to *common.Address, txType types.TxType, amount decimal.Decimal,
+	maxFee decimal.Decimal, tips decimal.Decimal, nonce uint32, epoch uint16, payload []byte) *types.Transaction {
 
 	// if maxFee is not set, we set it as 2x from fee
 	if maxFee == (decimal.Decimal{}) || maxFee == decimal.Zero {
-		tx := blockchain.BuildTx(api.getAppState(), from, to, txType, amount, maxFee, tips, nonce, epoch, payload)
-		txFee := fee.CalculateFee(api.getAppState().ValidatorsCache.NetworkSize(), api.getAppState().State.FeePerByte(), tx)
+		tx := blockchain.BuildTx(appState, from, to, txType, amount, maxFee, tips, nonce, epoch, payload)
+		txFee := fee.CalculateFee(appState.ValidatorsCache.NetworkSize(), appState.State.FeePerByte(), tx)
 		maxFee = blockchain.ConvertToFloat(new(big.Int).Mul(txFee, big.NewInt(2)))
 	}
 
-	tx := blockchain.BuildTx(api.getAppState(), from, to, txType, amount, maxFee, tips, nonce, epoch, payload)
+	tx := blockchain.BuildTx(appState, from, to, txType, amount, maxFee, tips, nonce, epoch, payload)
 
 	return tx
 }
@@ -85,7 +90,7 @@ func (api *BaseApi) sendTx(ctx context.Context, from common.Address, to *common.
 func (api *BaseApi) sendInternalTx(ctx context.Context, tx *types.Transaction) (common.Hash, error) {
 	log.Info("Sending new tx", "ip", ctx.Value("remote"), "type", tx.Type, "hash", tx.Hash().Hex(), "nonce", tx.AccountNonce, "epoch", tx.Epoch)
 
-	if err := api.txpool.Add(tx); err != nil {
+	if err := api.txpool.AddLocal(tx); err != nil {
 		return common.Hash{}, err
 	}
 
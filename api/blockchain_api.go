@@ -1,17 +1,31 @@
 package api
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
 	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/blockchain/attachments"
 	"github.com/idena-network/idena-go/blockchain/fee"
 	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/blockchain/validation"
 	"github.com/idena-network/idena-go/common"
 	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/consensus"
+	"github.com/idena-network/idena-go/core/ancient"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/backpressure"
+	"github.com/idena-network/idena-go/core/hardfork"
 	"github.com/idena-network/idena-go/core/mempool"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/core/supply"
 	"github.com/idena-network/idena-go/ipfs"
+	"github.com/idena-network/idena-go/log"
 	"github.com/idena-network/idena-go/protocol"
 	"github.com/idena-network/idena-go/rlp"
+	"github.com/idena-network/idena-go/rpc"
 	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
 	"math/big"
 	"sort"
@@ -44,10 +58,15 @@ type BlockchainApi struct {
 	pool    *mempool.TxPool
 	d       *protocol.Downloader
 	pm      *protocol.IdenaGossipHandler
+	forks   *hardfork.Rules
+	votes   *hardfork.VoteTracker
+	ancient *ancient.Archiver
+	supply  *supply.Tracker
+	busy    *backpressure.Monitor
 }
 
-func NewBlockchainApi(baseApi *BaseApi, bc *blockchain.Blockchain, ipfs ipfs.Proxy, pool *mempool.TxPool, d *protocol.Downloader, pm *protocol.IdenaGossipHandler) *BlockchainApi {
-	return &BlockchainApi{bc, baseApi, ipfs, pool, d, pm}
+func NewBlockchainApi(baseApi *BaseApi, bc *blockchain.Blockchain, ipfs ipfs.Proxy, pool *mempool.TxPool, d *protocol.Downloader, pm *protocol.IdenaGossipHandler, forks *hardfork.Rules, votes *hardfork.VoteTracker, archiver *ancient.Archiver, supply *supply.Tracker, busy *backpressure.Monitor) *BlockchainApi {
+	return &BlockchainApi{bc, baseApi, ipfs, pool, d, pm, forks, votes, archiver, supply, busy}
 }
 
 type Block struct {
@@ -103,6 +122,52 @@ func (api *BlockchainApi) Block(hash common.Hash) *Block {
 	return convertToBlock(block)
 }
 
+type CertificateVoter struct {
+	Address     common.Address `json:"address"`
+	TurnOffline bool           `json:"turnOffline"`
+	Upgrade     uint16         `json:"upgrade"`
+	Signature   hexutil.Bytes  `json:"signature"`
+}
+
+type BlockCertificate struct {
+	Height     uint64             `json:"height"`
+	Hash       common.Hash        `json:"hash"`
+	ParentHash common.Hash        `json:"parentHash"`
+	Round      uint64             `json:"round"`
+	Step       uint8              `json:"step"`
+	Final      bool               `json:"final"`
+	Voters     []CertificateVoter `json:"voters"`
+}
+
+// BlockCertificate returns the certificate of the canonical block with committee members which votes finalized the block,
+// certificates of all blocks are available if the node is run with cert index enabled
+func (api *BlockchainApi) BlockCertificate(height uint64) (*BlockCertificate, error) {
+	header, cert := api.bc.GetCertificateByHeight(height)
+	if header == nil {
+		return nil, errors.Errorf("block %v is not found", height)
+	}
+	if cert == nil || len(cert.Signatures) == 0 {
+		return nil, errors.Errorf("certificate of block %v is not stored", height)
+	}
+	result := &BlockCertificate{
+		Height:     height,
+		Hash:       header.Hash(),
+		ParentHash: header.ParentHash(),
+		Round:      cert.Round,
+		Step:       cert.Step,
+		Final:      cert.Step == types.Final,
+	}
+	for _, vote := range cert.Votes(header.ParentHash()) {
+		result.Voters = append(result.Voters, CertificateVoter{
+			Address:     vote.VoterAddr(),
+			TurnOffline: vote.Header.TurnOffline,
+			Upgrade:     vote.Header.Upgrade,
+			Signature:   vote.Signature,
+		})
+	}
+	return result, nil
+}
+
 func (api *BlockchainApi) Transaction(hash common.Hash) *Transaction {
 	tx := api.pool.GetTx(hash)
 	var idx *types.TransactionIndex
@@ -133,6 +198,120 @@ func (api *BlockchainApi) Transaction(hash common.Hash) *Transaction {
 	return convertToTransaction(tx, blockHash, feePerByte, timestamp)
 }
 
+type TxReceipt struct {
+	TxHash    common.Hash      `json:"txHash"`
+	BlockHash common.Hash      `json:"blockHash"`
+	Type      string           `json:"type"`
+	Success   bool             `json:"success"`
+	Error     string           `json:"error,omitempty"`
+	Fee       decimal.Decimal  `json:"fee"`
+	FeeBurned decimal.Decimal  `json:"feeBurned"`
+	Changes   []*ReceiptChange `json:"changes"`
+	Events    []*TxEvent       `json:"events"`
+}
+
+type ReceiptChange struct {
+	Address       common.Address  `json:"address"`
+	BalanceBefore decimal.Decimal `json:"balanceBefore"`
+	BalanceAfter  decimal.Decimal `json:"balanceAfter"`
+	StakeBefore   decimal.Decimal `json:"stakeBefore"`
+	StakeAfter    decimal.Decimal `json:"stakeAfter"`
+	StateBefore   string          `json:"stateBefore"`
+	StateAfter    string          `json:"stateAfter"`
+}
+
+type TxEvent struct {
+	Name    string         `json:"name"`
+	Address common.Address `json:"address"`
+	Data    hexutil.Bytes  `json:"data,omitempty"`
+}
+
+// TxReceipt returns the outcome of the tx included into the block
+func (api *BlockchainApi) TxReceipt(hash common.Hash) (*TxReceipt, error) {
+	receipt := api.bc.GetTxReceipt(hash)
+	if receipt == nil {
+		return nil, errors.New("receipt is not found")
+	}
+	result := &TxReceipt{
+		TxHash:    receipt.TxHash,
+		BlockHash: receipt.BlockHash,
+		Success:   receipt.Success,
+		Error:     receipt.Error,
+		Fee:       blockchain.ConvertToFloat(receipt.Fee),
+		FeeBurned: blockchain.ConvertToFloat(receipt.FeeBurned),
+	}
+	if tx, _ := api.bc.GetTx(hash); tx != nil {
+		result.Type = txTypeMap[tx.Type]
+	}
+	for _, change := range receipt.Changes {
+		result.Changes = append(result.Changes, &ReceiptChange{
+			Address:       change.Address,
+			BalanceBefore: blockchain.ConvertToFloat(change.BalanceBefore),
+			BalanceAfter:  blockchain.ConvertToFloat(change.BalanceAfter),
+			StakeBefore:   blockchain.ConvertToFloat(change.StakeBefore),
+			StakeAfter:    blockchain.ConvertToFloat(change.StakeAfter),
+			StateBefore:   convertIdentityState(state.IdentityState(change.StateBefore)),
+			StateAfter:    convertIdentityState(state.IdentityState(change.StateAfter)),
+		})
+	}
+	for _, event := range receipt.Events {
+		result.Events = append(result.Events, &TxEvent{
+			Name:    event.Name,
+			Address: event.Address,
+			Data:    event.Data,
+		})
+	}
+	return result, nil
+}
+
+type DecodedTxPayload struct {
+	Hash    common.Hash   `json:"hash"`
+	Type    string        `json:"type"`
+	Schema  string        `json:"schema,omitempty"`
+	Payload hexutil.Bytes `json:"payload"`
+	Decoded interface{}   `json:"decoded,omitempty"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// DecodeTxPayload decodes the payload of the tx by the registered schema of its type, the value is the tx hash or
+// the raw tx. Answers are decoded per flip if flipsCount is set
+func (api *BlockchainApi) DecodeTxPayload(value hexutil.Bytes, flipsCount *uint) (*DecodedTxPayload, error) {
+	var tx *types.Transaction
+	if len(value) == common.HashLength {
+		hash := common.BytesToHash(value)
+		if tx = api.pool.GetTx(hash); tx == nil {
+			tx, _ = api.bc.GetTx(hash)
+		}
+		if tx == nil {
+			return nil, errors.New("transaction is not found")
+		}
+	} else {
+		tx = new(types.Transaction)
+		if err := rlp.DecodeBytes(value, tx); err != nil {
+			return nil, errors.Wrap(err, "cannot decode tx")
+		}
+	}
+	result := &DecodedTxPayload{
+		Hash:    tx.Hash(),
+		Type:    txTypeMap[tx.Type],
+		Payload: tx.Payload,
+	}
+	if len(tx.Payload) == 0 {
+		return result, nil
+	}
+	var opts attachments.DecodeOptions
+	if flipsCount != nil {
+		opts.FlipsCount = *flipsCount
+	}
+	schema, decoded, err := attachments.DecodePayload(tx.Type, tx.Payload, opts)
+	result.Schema = schema
+	result.Decoded = decoded
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result, nil
+}
+
 func (api *BlockchainApi) Mempool() []common.Hash {
 	pending := api.pool.GetPendingTransaction()
 
@@ -168,10 +347,64 @@ func (api *BlockchainApi) Syncing() Syncing {
 	}
 }
 
+type TimeSync struct {
+	Drift     float64 `json:"drift"`
+	Offset    float64 `json:"offset"`
+	WrongTime bool    `json:"wrongTime"`
+	LastCheck int64   `json:"lastCheck"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// TimeSync returns the last measured clock drift in seconds
+func (api *BlockchainApi) TimeSync() TimeSync {
+	status := api.pm.TimeSync().Status()
+	result := TimeSync{
+		Drift:     status.Drift.Seconds(),
+		Offset:    status.Offset.Seconds(),
+		WrongTime: status.WrongTime,
+		LastCheck: status.LastCheck.Unix(),
+	}
+	if status.Error != nil {
+		result.Error = status.Error.Error()
+	}
+	return result
+}
+
 type TransactionsArgs struct {
-	Address common.Address `json:"address"`
-	Count   int            `json:"count"`
-	Token   hexutil.Bytes  `json:"token"`
+	Address    common.Address `json:"address"`
+	Count      int            `json:"count"`
+	Token      hexutil.Bytes  `json:"token"`
+	Types      []string       `json:"types"`
+	FromHeight uint64         `json:"fromHeight"`
+	ToHeight   uint64         `json:"toHeight"`
+	FromTime   uint64         `json:"fromTime"`
+	ToTime     uint64         `json:"toTime"`
+}
+
+func parseTxTypes(names []string) ([]types.TxType, error) {
+	var result []types.TxType
+	for _, name := range names {
+		found := false
+		for txType, typeName := range txTypeMap {
+			if typeName == name {
+				result = append(result, txType)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.Errorf("unknown tx type %v", name)
+		}
+	}
+	return result, nil
+}
+
+// pendingTxToken = epoch + nonce of the next transaction
+func pendingTxToken(tx *types.Transaction) hexutil.Bytes {
+	token := make([]byte, 6)
+	binary.BigEndian.PutUint16(token, tx.Epoch)
+	binary.BigEndian.PutUint32(token[2:], tx.AccountNonce)
+	return token
 }
 
 type Transactions struct {
@@ -180,7 +413,14 @@ type Transactions struct {
 }
 
 // sorted by epoch \ nonce desc (the newest transactions are first)
-func (api *BlockchainApi) PendingTransactions(args TransactionsArgs) Transactions {
+func (api *BlockchainApi) PendingTransactions(args TransactionsArgs) (Transactions, error) {
+	txTypes, err := parseTxTypes(args.Types)
+	if err != nil {
+		return Transactions{}, err
+	}
+	if len(args.Token) > 0 && len(args.Token) != 6 {
+		return Transactions{}, errors.New("invalid token")
+	}
 	txs := api.pool.GetPendingByAddress(args.Address)
 
 	sort.SliceStable(txs, func(i, j int) bool {
@@ -194,27 +434,231 @@ func (api *BlockchainApi) PendingTransactions(args TransactionsArgs) Transaction
 	})
 
 	var list []*Transaction
+	var token *hexutil.Bytes
 	for _, item := range txs {
+		if len(txTypes) > 0 && !containsTxType(txTypes, item.Type) {
+			continue
+		}
+		itemToken := pendingTxToken(item)
+		if len(args.Token) > 0 && bytes.Compare(itemToken, args.Token) > 0 {
+			continue
+		}
+		if args.Count > 0 && len(list) == args.Count {
+			token = &itemToken
+			break
+		}
 		list = append(list, convertToTransaction(item, common.Hash{}, nil, 0))
 	}
 
 	return Transactions{
 		Transactions: list,
-		Token:        nil,
+		Token:        token,
+	}, nil
+}
+
+type MempoolAddressContent struct {
+	Local bool `json:"local"`
+	// Nonce is the state nonce of the sender, the next executable tx has nonce + 1
+	Nonce      uint32         `json:"nonce"`
+	Epoch      uint16         `json:"epoch"`
+	Executable []*Transaction `json:"executable"`
+	Pending    []*Transaction `json:"pending"`
+}
+
+// MempoolContent returns mempool txs grouped by sender and ordered by nonce, only txs of the address are returned
+// if it's specified
+func (api *BlockchainApi) MempoolContent(address *common.Address) map[common.Address]*MempoolAddressContent {
+	state := api.baseApi.getAppState().State
+	convert := func(txs []*types.Transaction) []*Transaction {
+		result := make([]*Transaction, 0, len(txs))
+		for _, tx := range txs {
+			result = append(result, convertToTransaction(tx, common.Hash{}, nil, 0))
+		}
+		return result
+	}
+	result := make(map[common.Address]*MempoolAddressContent)
+	for sender, content := range api.pool.Content() {
+		if address != nil && *address != sender {
+			continue
+		}
+		result[sender] = &MempoolAddressContent{
+			Local:      content.Local,
+			Nonce:      state.GetNonce(sender),
+			Epoch:      state.GetEpoch(sender),
+			Executable: convert(content.Executable),
+			Pending:    convert(content.Pending),
+		}
 	}
+	return result
+}
+
+type MempoolStats struct {
+	Txs        int `json:"txs"`
+	Executable int `json:"executable"`
+	Pending    int `json:"pending"`
+	Senders    int `json:"senders"`
+	Locals     int `json:"locals"`
+	LocalTxs   int `json:"localTxs"`
+	Deferred   int `json:"deferred"`
+	Evicted    int `json:"evicted"`
+	Size       int `json:"size"`
+}
+
+func (api *BlockchainApi) MempoolStats() MempoolStats {
+	stats := api.pool.Stats()
+	return MempoolStats{
+		Txs:        stats.Txs,
+		Executable: stats.Executable,
+		Pending:    stats.Pending,
+		Senders:    stats.Senders,
+		Locals:     stats.Locals,
+		LocalTxs:   stats.LocalTxs,
+		Deferred:   stats.Deferred,
+		Evicted:    stats.Evicted,
+		Size:       stats.Size,
+	}
+}
+
+// EvictTx removes the tx from the mempool, the tx is rejected until the end of its epoch, the request must be made
+// with the API key or JWT
+func (api *BlockchainApi) EvictTx(ctx context.Context, hash common.Hash) (*Transaction, error) {
+	if !rpc.IsAuthenticated(ctx) {
+		return nil, errors.New("authentication is required")
+	}
+	tx, err := api.pool.Evict(hash)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Tx is evicted from mempool", "ip", ctx.Value("remote"), "hash", hash.Hex())
+	return convertToTransaction(tx, common.Hash{}, nil, 0), nil
+}
+
+// Forks returns competing branches recently seen from peers with their fork points, lengths and resolution status
+func (api *BlockchainApi) Forks() []consensus.Fork {
+	return api.baseApi.engine.Forks()
+}
+
+type HardForks struct {
+	Height      uint64                `json:"height"`
+	Epoch       uint16                `json:"epoch"`
+	Ready       bool                  `json:"ready"`
+	Activations []hardfork.Activation `json:"activations"`
+}
+
+// HardForks returns hard fork activations of the chain config and whether the node is ready to follow them
+func (api *BlockchainApi) HardForks() HardForks {
+	height, epoch := api.bc.Head.Height(), api.baseApi.getAppState().State.Epoch()
+	result := HardForks{
+		Height:      height,
+		Epoch:       epoch,
+		Ready:       true,
+		Activations: api.forks.Activations(height, epoch),
+	}
+	for _, activation := range result.Activations {
+		result.Ready = result.Ready && activation.Ready
+	}
+	return result
+}
+
+// UpgradeVotes returns upgrade votes of recent block proposers and versions of observed peers
+// with the network adoption of configured hard forks
+func (api *BlockchainApi) UpgradeVotes() hardfork.UpgradeVotes {
+	return api.votes.Votes()
+}
+
+func containsTxType(txTypes []types.TxType, txType types.TxType) bool {
+	for _, t := range txTypes {
+		if t == txType {
+			return true
+		}
+	}
+	return false
 }
 
 func (api *BlockchainApi) FeePerByte() *big.Int {
 	return api.baseApi.getAppState().State.FeePerByte()
 }
 
+type FeeScheduleEntry struct {
+	Type   string `json:"type"`
+	TypeId uint16 `json:"typeId"`
+	// Multiplier is applied to the network fee per byte, 0 - the tx is free
+	Multiplier int64  `json:"multiplier"`
+	ExtraSize  int    `json:"extraSize"`
+	Note       string `json:"note,omitempty"`
+}
+
+type FeeSchedule struct {
+	FeePerByte              *big.Int           `json:"feePerByte"`
+	NetworkSize             int                `json:"networkSize"`
+	SignatureAdditionalSize int                `json:"signatureAdditionalSize"`
+	Entries                 []FeeScheduleEntry `json:"entries"`
+}
+
+// FeeSchedule returns the fee metering table of the current consensus rules,
+// the tx fee is (tx size + extra size) * fee per byte * multiplier
+func (api *BlockchainApi) FeeSchedule() FeeSchedule {
+	appState := api.baseApi.getAppState()
+	result := FeeSchedule{
+		FeePerByte:              appState.State.FeePerByte(),
+		NetworkSize:             appState.ValidatorsCache.NetworkSize(),
+		SignatureAdditionalSize: fee.SignatureAdditionalSize,
+	}
+	for txType, typeName := range txTypeMap {
+		txFee := fee.CurrentSchedule.Get(txType)
+		result.Entries = append(result.Entries, FeeScheduleEntry{
+			Type:       typeName,
+			TypeId:     txType,
+			Multiplier: txFee.Multiplier,
+			ExtraSize:  txFee.ExtraSize,
+			Note:       txFee.Note,
+		})
+	}
+	sort.Slice(result.Entries, func(i, j int) bool {
+		return result.Entries[i].TypeId < result.Entries[j].TypeId
+	})
+	return result
+}
+
 func (api *BlockchainApi) SendRawTx(ctx context.Context, bytesTx hexutil.Bytes) (common.Hash, error) {
 	var tx types.Transaction
 	if err := rlp.DecodeBytes(bytesTx, &tx); err != nil {
+		return common.Hash{}, errors.Wrap(err, "cannot decode tx")
+	}
+	if len(tx.Signature) == 0 {
+		return common.Hash{}, errors.New("tx is not signed")
+	}
+	sender, err := types.Sender(&tx)
+	if err != nil {
+		return common.Hash{}, errors.Wrap(validation.InvalidSignature, err.Error())
+	}
+	// the error is returned as is to keep its rpc code and retry data
+	if err := api.busy.Check(&tx); err != nil {
 		return common.Hash{}, err
 	}
+	hash, err := api.baseApi.sendInternalTx(ctx, &tx)
+	if err != nil {
+		return common.Hash{}, describeTxError(api.baseApi.getAppState(), sender, &tx, err)
+	}
+	return hash, nil
+}
 
-	return api.baseApi.sendInternalTx(ctx, &tx)
+// describeTxError adds the state values the tx has been checked against to the validation error
+func describeTxError(appState *appstate.AppState, sender common.Address, tx *types.Transaction, err error) error {
+	switch errors.Cause(err) {
+	case validation.InvalidEpoch:
+		return errors.Wrapf(err, "tx epoch: %v, current epoch: %v", tx.Epoch, appState.State.Epoch())
+	case validation.InvalidMaxFee, validation.BigFee:
+		txFee := fee.CalculateFee(appState.ValidatorsCache.NetworkSize(), appState.State.FeePerByte(), tx)
+		return errors.Wrapf(err, "tx max fee: %v, current fee: %v", blockchain.ConvertToFloat(tx.MaxFeeOrZero()),
+			blockchain.ConvertToFloat(txFee))
+	case validation.InsufficientFunds:
+		cost := new(big.Int).Add(tx.AmountOrZero(), tx.TipsOrZero())
+		cost.Add(cost, tx.MaxFeeOrZero())
+		return errors.Wrapf(err, "sender: %v, balance: %v, max cost: %v", sender.Hex(),
+			blockchain.ConvertToFloat(appState.State.GetBalance(sender)), blockchain.ConvertToFloat(cost))
+	}
+	return errors.Wrapf(err, "tx %v from %v", tx.Hash().Hex(), sender.Hex())
 }
 
 func (api *BlockchainApi) GetRawTx(args SendTxArgs) (hexutil.Bytes, error) {
@@ -234,9 +678,115 @@ func (api *BlockchainApi) GetRawTx(args SendTxArgs) (hexutil.Bytes, error) {
 	return data, nil
 }
 
-func (api *BlockchainApi) Transactions(args TransactionsArgs) Transactions {
+type BuildTxArgs struct {
+	Type    string          `json:"type"`
+	From    common.Address  `json:"from"`
+	To      *common.Address `json:"to"`
+	Amount  decimal.Decimal `json:"amount"`
+	MaxFee  decimal.Decimal `json:"maxFee"`
+	Tips    decimal.Decimal `json:"tips"`
+	Payload *hexutil.Bytes  `json:"payload"`
+	// Online is the status of onlineStatus tx
+	Online *bool `json:"online"`
+	// Key is the key of burn tx
+	Key string `json:"key"`
+	// Cid is the flip cid of submitFlip and deleteFlip txs or the profile cid of changeProfile tx
+	Cid  string `json:"cid"`
+	Pair uint8  `json:"pair"`
+	BaseTxArgs
+}
+
+type BuiltTx struct {
+	Type        string          `json:"type"`
+	Nonce       uint32          `json:"nonce"`
+	Epoch       uint16          `json:"epoch"`
+	MaxFee      decimal.Decimal `json:"maxFee"`
+	Payload     hexutil.Bytes   `json:"payload"`
+	Raw         hexutil.Bytes   `json:"raw"`
+	SigningHash common.Hash     `json:"signingHash"`
+}
+
+func buildTxPayload(txType types.TxType, args BuildTxArgs) ([]byte, error) {
+	if args.Payload != nil {
+		return *args.Payload, nil
+	}
+	decodeCid := func() ([]byte, error) {
+		c, err := cid.Decode(args.Cid)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid cid")
+		}
+		return c.Bytes(), nil
+	}
+	switch txType {
+	case types.OnlineStatusTx:
+		if args.Online == nil {
+			return nil, errors.New("online status is required")
+		}
+		return attachments.CreateOnlineStatusAttachment(*args.Online), nil
+	case types.BurnTx:
+		return attachments.CreateBurnAttachment(args.Key), nil
+	case types.ChangeProfileTx:
+		c, err := decodeCid()
+		if err != nil {
+			return nil, err
+		}
+		return attachments.CreateChangeProfileAttachment(c), nil
+	case types.SubmitFlipTx:
+		c, err := decodeCid()
+		if err != nil {
+			return nil, err
+		}
+		return attachments.CreateFlipSubmitAttachment(c, args.Pair), nil
+	case types.DeleteFlipTx:
+		c, err := decodeCid()
+		if err != nil {
+			return nil, err
+		}
+		return attachments.CreateDeleteFlipAttachment(c), nil
+	}
+	return nil, nil
+}
+
+// BuildTx returns the canonical encoding of the unsigned tx and the hash to be signed, nonce, epoch and max fee
+// are taken from the node state if not specified
+func (api *BlockchainApi) BuildTx(args BuildTxArgs) (BuiltTx, error) {
+	txTypes, err := parseTxTypes([]string{args.Type})
+	if err != nil {
+		return BuiltTx{}, err
+	}
+	payload, err := buildTxPayload(txTypes[0], args)
+	if err != nil {
+		return BuiltTx{}, err
+	}
+	tx := api.baseApi.getTx(args.From, args.To, txTypes[0], args.Amount, args.MaxFee, args.Tips, args.Nonce, args.Epoch, payload)
+	data, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return BuiltTx{}, err
+	}
+	return BuiltTx{
+		Type:        args.Type,
+		Nonce:       tx.AccountNonce,
+		Epoch:       tx.Epoch,
+		MaxFee:      blockchain.ConvertToFloat(tx.MaxFee),
+		Payload:     tx.Payload,
+		Raw:         data,
+		SigningHash: types.SigningHash(tx),
+	}, nil
+}
 
-	txs, nextToken := api.bc.ReadTxs(args.Address, args.Count, args.Token)
+func (api *BlockchainApi) Transactions(args TransactionsArgs) (Transactions, error) {
+	txTypes, err := parseTxTypes(args.Types)
+	if err != nil {
+		return Transactions{}, err
+	}
+
+	txs, nextToken := api.bc.ReadTxs(args.Address, args.Count, args.Token, &blockchain.TxsFilter{
+		Types:      txTypes,
+		FromHeight: args.FromHeight,
+		ToHeight:   args.ToHeight,
+		FromTime:   args.FromTime,
+		ToTime:     args.ToTime,
+	})
 
 	var list []*Transaction
 	for _, item := range txs {
@@ -252,7 +802,7 @@ func (api *BlockchainApi) Transactions(args TransactionsArgs) Transactions {
 	return Transactions{
 		Transactions: list,
 		Token:        token,
-	}
+	}, nil
 }
 
 func (api *BlockchainApi) BurntCoins() []BurntCoins {
@@ -267,6 +817,120 @@ func (api *BlockchainApi) BurntCoins() []BurntCoins {
 	return res
 }
 
+const defaultBurnStatsBlocks = 100
+
+type EpochEmission struct {
+	Epoch      uint16          `json:"epoch"`
+	Mining     decimal.Decimal `json:"mining"`
+	Validation decimal.Decimal `json:"validation"`
+	Burned     decimal.Decimal `json:"burned"`
+}
+
+type Supply struct {
+	// Since is the height the accumulated values are counted from
+	Since              uint64          `json:"since"`
+	Height             uint64          `json:"height"`
+	Total              decimal.Decimal `json:"total"`
+	Minted             decimal.Decimal `json:"minted"`
+	Burned             decimal.Decimal `json:"burned"`
+	MiningEmission     decimal.Decimal `json:"miningEmission"`
+	ValidationEmission decimal.Decimal `json:"validationEmission"`
+	// BurnAddress is the balance of the zero address which coins can't be spent from
+	BurnAddress decimal.Decimal `json:"burnAddress"`
+	Treasury    decimal.Decimal `json:"treasury"`
+	Epoch       *EpochEmission  `json:"epoch,omitempty"`
+}
+
+// Supply returns the total supply and accumulated emission, emission of the current epoch is returned if epoch isn't set
+func (api *BlockchainApi) Supply(epoch *uint16) (*Supply, error) {
+	totals := api.supply.Totals()
+	if totals == nil {
+		return nil, errors.New("supply hasn't been accounted yet")
+	}
+	appState := api.baseApi.getAppState()
+	if epoch == nil {
+		current := appState.State.Epoch()
+		epoch = &current
+	}
+	result := &Supply{
+		Since:              totals.Since,
+		Height:             totals.Height,
+		Total:              blockchain.ConvertToFloat(totals.Supply()),
+		Minted:             blockchain.ConvertToFloat(totals.Minted),
+		Burned:             blockchain.ConvertToFloat(totals.Burned()),
+		MiningEmission:     blockchain.ConvertToFloat(totals.MiningEmission),
+		ValidationEmission: blockchain.ConvertToFloat(totals.ValidationEmission),
+		BurnAddress:        blockchain.ConvertToFloat(appState.State.GetBalance(common.Address{})),
+		Treasury:           blockchain.ConvertToFloat(appState.State.GetBalance(appState.State.GodAddress())),
+	}
+	if emission := api.supply.EpochEmission(*epoch); emission != nil {
+		result.Epoch = &EpochEmission{
+			Epoch:      emission.Epoch,
+			Mining:     blockchain.ConvertToFloat(emission.Mining),
+			Validation: blockchain.ConvertToFloat(emission.Validation),
+			Burned:     blockchain.ConvertToFloat(emission.Burned),
+		}
+	}
+	return result, nil
+}
+
+type BlockBurn struct {
+	Height    uint64          `json:"height"`
+	Minted    decimal.Decimal `json:"minted"`
+	FeeBurned decimal.Decimal `json:"feeBurned"`
+	Burned    decimal.Decimal `json:"burned"`
+}
+
+type BurnStats struct {
+	Since   uint64          `json:"since"`
+	Height  uint64          `json:"height"`
+	Total   decimal.Decimal `json:"total"`
+	Fee     decimal.Decimal `json:"fee"`
+	Penalty decimal.Decimal `json:"penalty"`
+	Invite  decimal.Decimal `json:"invite"`
+	Killed  decimal.Decimal `json:"killed"`
+	BurnTx  decimal.Decimal `json:"burnTx"`
+	// Blocks are the latest blocks, the most recent first
+	Blocks []BlockBurn `json:"blocks"`
+}
+
+// BurnStats returns cumulative burned coins by reason and burn of the latest blocks, 100 blocks are returned by default
+func (api *BlockchainApi) BurnStats(blocks *int) (*BurnStats, error) {
+	totals := api.supply.Totals()
+	if totals == nil {
+		return nil, errors.New("supply hasn't been accounted yet")
+	}
+	count := defaultBurnStatsBlocks
+	if blocks != nil {
+		count = *blocks
+	}
+	result := &BurnStats{
+		Since:   totals.Since,
+		Height:  totals.Height,
+		Total:   blockchain.ConvertToFloat(totals.Burned()),
+		Fee:     blockchain.ConvertToFloat(totals.FeeBurned),
+		Penalty: blockchain.ConvertToFloat(totals.PenaltyBurned),
+		Invite:  blockchain.ConvertToFloat(totals.InviteBurned),
+		Killed:  blockchain.ConvertToFloat(totals.KilledBurned),
+		BurnTx:  blockchain.ConvertToFloat(totals.BurnTxBurned),
+		Blocks:  make([]BlockBurn, 0),
+	}
+	for _, item := range api.supply.RecentBlocks(count) {
+		result.Blocks = append(result.Blocks, BlockBurn{
+			Height:    item.Height,
+			Minted:    blockchain.ConvertToFloat(item.Minted),
+			FeeBurned: blockchain.ConvertToFloat(item.FeeBurned),
+			Burned:    blockchain.ConvertToFloat(item.Burned),
+		})
+	}
+	return result, nil
+}
+
+// AncientStatus returns the state of moving old blocks to the object store
+func (api *BlockchainApi) AncientStatus() ancient.Status {
+	return api.ancient.Status()
+}
+
 func convertToTransaction(tx *types.Transaction, blockHash common.Hash, feePerByte *big.Int, timestamp uint64) *Transaction {
 	sender, _ := types.Sender(tx)
 	return &Transaction{
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"context"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/rpc"
+)
+
+const ceremonyPhasesBufferSize = 20
+
+type CeremonyPhase struct {
+	Seq       uint64 `json:"seq"`
+	Phase     string `json:"phase"`
+	Epoch     uint16 `json:"epoch"`
+	Height    uint64 `json:"height"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// CeremonyEventsApi offers ceremony phase notifications so it's not required to poll ceremony intervals
+type CeremonyEventsApi struct {
+	bus eventbus.Bus
+}
+
+// NewCeremonyEventsApi creates a new CeremonyEventsApi instance
+func NewCeremonyEventsApi(bus eventbus.Bus) *CeremonyEventsApi {
+	return &CeremonyEventsApi{bus}
+}
+
+// CeremonyPhases creates a subscription to flip lottery, session and validation phases of the ceremony
+func (api *CeremonyEventsApi) CeremonyPhases(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+	phases := make(chan *CeremonyPhase, ceremonyPhasesBufferSize)
+	sub := api.bus.Subscribe(events.CeremonyPhaseEventID, func(e eventbus.Event) {
+		phase := e.(*events.CeremonyPhaseEvent)
+		select {
+		case phases <- &CeremonyPhase{
+			Seq:       phase.Seq,
+			Phase:     phase.Phase,
+			Epoch:     phase.Epoch,
+			Height:    phase.Height,
+			Timestamp: phase.Timestamp,
+		}:
+		default:
+		}
+	})
+	go func() {
+		defer api.bus.Unsubscribe(sub)
+		for {
+			select {
+			case phase := <-phases:
+				notifier.Notify(rpcSub.ID, phase)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/consensus"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/pkg/errors"
+)
+
+// ConsensusApi offers consensus debugging tools
+type ConsensusApi struct {
+	engine *consensus.Engine
+}
+
+// NewConsensusApi creates a new ConsensusApi instance
+func NewConsensusApi(engine *consensus.Engine) *ConsensusApi {
+	return &ConsensusApi{engine}
+}
+
+type ProposalTrace struct {
+	Hash     common.Hash    `json:"hash"`
+	Proposer common.Address `json:"proposer"`
+}
+
+type StepVotes struct {
+	Hash  common.Hash `json:"hash"`
+	Count int         `json:"count"`
+}
+
+type StepTrace struct {
+	Step      uint8        `json:"step"`
+	Start     int64        `json:"start"`
+	Duration  float64      `json:"duration"`
+	OwnVote   *common.Hash `json:"ownVote,omitempty"`
+	Threshold int          `json:"threshold"`
+	Votes     []StepVotes  `json:"votes"`
+	Result    *common.Hash `json:"result,omitempty"`
+	Timeout   bool         `json:"timeout"`
+}
+
+type RoundTrace struct {
+	Round         uint64           `json:"round"`
+	Head          common.Hash      `json:"head"`
+	Start         int64            `json:"start"`
+	Duration      float64          `json:"duration"`
+	OwnProposal   *common.Hash     `json:"ownProposal,omitempty"`
+	Proposer      *common.Address  `json:"proposer,omitempty"`
+	Proofs        []ProposalTrace  `json:"proofs"`
+	Blocks        []common.Hash    `json:"blocks"`
+	ReceivedBlock *common.Hash     `json:"receivedBlock,omitempty"`
+	Steps         []StepTrace      `json:"steps"`
+	Result        string           `json:"result"`
+	Block         *common.Hash     `json:"block,omitempty"`
+	Cert          []common.Address `json:"cert"`
+	Error         string           `json:"error,omitempty"`
+}
+
+func convertPubKey(pubKey []byte) *common.Address {
+	if len(pubKey) == 0 {
+		return nil
+	}
+	addr, err := crypto.PubKeyBytesToAddress(pubKey)
+	if err != nil {
+		return nil
+	}
+	return &addr
+}
+
+func optionalHash(hash common.Hash) *common.Hash {
+	if hash == (common.Hash{}) {
+		return nil
+	}
+	return &hash
+}
+
+// RoundTrace returns the trace of the recent consensus round: proposals, votes by step, timeouts and final certificate
+func (api *ConsensusApi) RoundTrace(height uint64) (*RoundTrace, error) {
+	trace := api.engine.RoundTrace(height)
+	if trace == nil {
+		return nil, errors.Errorf("trace of round %v is not found", height)
+	}
+	result := &RoundTrace{
+		Round:         trace.Round,
+		Head:          trace.Head,
+		Start:         trace.Start.Unix(),
+		Duration:      trace.Duration.Seconds(),
+		OwnProposal:   trace.OwnProposal,
+		Proposer:      convertPubKey(trace.Proposer),
+		Proofs:        make([]ProposalTrace, 0, len(trace.Proofs)),
+		Blocks:        trace.Blocks,
+		ReceivedBlock: trace.ReceivedBlock,
+		Steps:         make([]StepTrace, 0, len(trace.Steps)),
+		Result:        string(trace.Result),
+		Block:         optionalHash(trace.Block),
+		Cert:          trace.Cert,
+		Error:         trace.Error,
+	}
+	for _, proof := range trace.Proofs {
+		p := ProposalTrace{Hash: proof.Hash}
+		if addr := convertPubKey(proof.PubKey); addr != nil {
+			p.Proposer = *addr
+		}
+		result.Proofs = append(result.Proofs, p)
+	}
+	for _, step := range trace.Steps {
+		s := StepTrace{
+			Step:      step.Step,
+			Duration:  step.Duration.Seconds(),
+			OwnVote:   step.OwnVote,
+			Threshold: step.Threshold,
+			Votes:     make([]StepVotes, 0, len(step.Votes)),
+			Result:    optionalHash(step.Result),
+			Timeout:   step.Timeout,
+		}
+		if !step.Start.IsZero() {
+			s.Start = step.Start.Unix()
+		}
+		for hash, cnt := range step.Votes {
+			s.Votes = append(s.Votes, StepVotes{Hash: hash, Count: cnt})
+		}
+		result.Steps = append(result.Steps, s)
+	}
+	return result, nil
+}
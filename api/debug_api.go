@@ -0,0 +1,56 @@
+package api
+
+import (
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/mempool"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/protocol"
+	"github.com/pkg/errors"
+)
+
+// DebugApi exposes internal caches of the node for diagnostics
+type DebugApi struct {
+	appState *appstate.AppState
+	pm       *protocol.IdenaGossipHandler
+	txpool   *mempool.TxPool
+	bc       *blockchain.Blockchain
+}
+
+// NewDebugApi creates a new DebugApi instance
+func NewDebugApi(appState *appstate.AppState, pm *protocol.IdenaGossipHandler, txpool *mempool.TxPool, bc *blockchain.Blockchain) *DebugApi {
+	return &DebugApi{appState, pm, txpool, bc}
+}
+
+// NonceCacheStats returns the size and hit statistics of the pending nonce cache
+func (api *DebugApi) NonceCacheStats() state.NonceCacheStats {
+	return api.appState.NonceCache.Stats()
+}
+
+// StateCacheStats returns the size and hit rate of the state read cache shared by tx validation and RPC
+func (api *DebugApi) StateCacheStats() state.ReadCacheStats {
+	return api.appState.State.ReadCacheStats()
+}
+
+type TxPropagation struct {
+	*protocol.TxPropagation
+	InPool    bool         `json:"inPool"`
+	BlockHash *common.Hash `json:"blockHash"`
+}
+
+// TxPropagation returns peers the local tx was announced to and pull requests of the tx received from peers
+func (api *DebugApi) TxPropagation(hash common.Hash) (*TxPropagation, error) {
+	trace := api.pm.TxPropagation(hash)
+	if trace == nil {
+		return nil, errors.New("tx is not traced")
+	}
+	result := &TxPropagation{
+		TxPropagation: trace,
+		InPool:        api.txpool.GetTx(hash) != nil,
+	}
+	if idx := api.bc.GetTxIndex(hash); idx != nil {
+		result.BlockHash = &idx.BlockHash
+	}
+	return result, nil
+}
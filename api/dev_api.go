@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/core/ceremony"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// DevApi controls the chain clock of the dev network to force epoch and ceremony transitions
+type DevApi struct {
+	chain    *blockchain.Blockchain
+	baseApi  *BaseApi
+	ceremony *ceremony.ValidationCeremony
+}
+
+// NewDevApi creates a new DevApi instance
+func NewDevApi(chain *blockchain.Blockchain, baseApi *BaseApi, ceremony *ceremony.ValidationCeremony) *DevApi {
+	return &DevApi{chain, baseApi, ceremony}
+}
+
+type DevTime struct {
+	Now    time.Time `json:"now"`
+	Offset int64     `json:"offset"`
+}
+
+func (api *DevApi) time() DevTime {
+	return DevTime{
+		Now:    api.chain.Now(),
+		Offset: int64(api.chain.TimeOffset() / time.Second),
+	}
+}
+
+// Time returns the chain clock and its offset in seconds
+func (api *DevApi) Time() DevTime {
+	return api.time()
+}
+
+// IncreaseTime moves the chain clock forward, next blocks get timestamps of the new time
+func (api *DevApi) IncreaseTime(ctx context.Context, seconds uint64) (DevTime, error) {
+	if err := authorize(ctx, "increaseTime"); err != nil {
+		return DevTime{}, err
+	}
+	if err := api.chain.AdjustTime(time.Duration(seconds) * time.Second); err != nil {
+		return DevTime{}, err
+	}
+	return api.time(), nil
+}
+
+// NextPeriod moves the chain clock to the beginning of the next validation period, the transition happens on the next block
+func (api *DevApi) NextPeriod(ctx context.Context) (DevTime, error) {
+	if err := authorize(ctx, "nextPeriod"); err != nil {
+		return DevTime{}, err
+	}
+	appState := api.baseApi.getAppState()
+	cfg := api.chain.Config().Validation
+	networkSize := appState.ValidatorsCache.NetworkSize()
+	nextValidation := appState.State.NextValidationTime()
+	shortSessionBegin := api.ceremony.ShortSessionBeginTime()
+	if shortSessionBegin.IsZero() {
+		shortSessionBegin = nextValidation
+	}
+
+	var target time.Time
+	switch appState.State.ValidationPeriod() {
+	case state.NonePeriod:
+		target = nextValidation.Add(-cfg.GetFlipLotteryDuration())
+	case state.FlipLotteryPeriod:
+		target = nextValidation
+	case state.ShortSessionPeriod:
+		target = shortSessionBegin.Add(cfg.GetShortSessionDuration())
+	case state.LongSessionPeriod:
+		target = shortSessionBegin.Add(cfg.GetShortSessionDuration()).Add(cfg.GetLongSessionDuration(networkSize))
+	case state.AfterLongSessionPeriod:
+		target = shortSessionBegin.Add(cfg.GetShortSessionDuration()).Add(cfg.GetLongSessionDuration(networkSize)).
+			Add(cfg.GetAfterLongSessionDuration())
+	default:
+		return DevTime{}, errors.New("unknown validation period")
+	}
+	if d := target.Sub(api.chain.Now()); d > 0 {
+		if err := api.chain.AdjustTime(d + time.Second); err != nil {
+			return DevTime{}, err
+		}
+	}
+	return api.time(), nil
+}
@@ -11,12 +11,18 @@ import (
 	"github.com/idena-network/idena-go/blockchain/types"
 	"github.com/idena-network/idena-go/common"
 	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/core/addressbook"
 	"github.com/idena-network/idena-go/core/appstate"
 	"github.com/idena-network/idena-go/core/ceremony"
+	"github.com/idena-network/idena-go/core/identityproof"
+	"github.com/idena-network/idena-go/core/online"
 	"github.com/idena-network/idena-go/core/profile"
+	"github.com/idena-network/idena-go/core/standby"
 	"github.com/idena-network/idena-go/core/state"
 	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/keystore"
 	"github.com/idena-network/idena-go/rlp"
+	"github.com/idena-network/idena-go/rpc"
 	"github.com/ipfs/go-cid"
 	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
@@ -29,11 +35,17 @@ type DnaApi struct {
 	ceremony       *ceremony.ValidationCeremony
 	appVersion     string
 	profileManager *profile.Manager
+	simulator      *ceremony.Simulator
+	onlineKeeper   *online.StatusKeeper
+	standby        *standby.Guard
+	addressBook    *addressbook.Book
+	identityProofs *identityproof.Store
 }
 
 func NewDnaApi(baseApi *BaseApi, bc *blockchain.Blockchain, ceremony *ceremony.ValidationCeremony, appVersion string,
-	profileManager *profile.Manager) *DnaApi {
-	return &DnaApi{bc, baseApi, ceremony, appVersion, profileManager}
+	profileManager *profile.Manager, simulator *ceremony.Simulator, onlineKeeper *online.StatusKeeper, standby *standby.Guard,
+	addressBook *addressbook.Book, identityProofs *identityproof.Store) *DnaApi {
+	return &DnaApi{bc, baseApi, ceremony, appVersion, profileManager, simulator, onlineKeeper, standby, addressBook, identityProofs}
 }
 
 type State struct {
@@ -213,6 +225,17 @@ type Identity struct {
 	Invitees            []state.TxAddr  `json:"invitees"`
 	Penalty             decimal.Decimal `json:"penalty"`
 	LastValidationFlags []string        `json:"lastValidationFlags"`
+	// Profile is set if the identity profile has been loaded by the node
+	Profile *IdentityProfile `json:"profile,omitempty"`
+	// Label is the address book label of the identity
+	Label string `json:"label,omitempty"`
+}
+
+type IdentityProfile struct {
+	Nickname string `json:"nickname"`
+	Avatar   string `json:"avatar,omitempty"`
+	// Verified is set if the profile uses the common schema and is signed by the identity
+	Verified bool `json:"verified"`
 }
 
 func (api *DnaApi) Identities() []Identity {
@@ -240,11 +263,19 @@ func (api *DnaApi) Identities() []Identity {
 
 	for idx := range identities {
 		identities[idx].Online = getIdentityOnlineStatus(api.baseApi.getAppState(), identities[idx].Address)
+		identities[idx].Profile = api.cachedProfile(identities[idx].Address, false)
+		identities[idx].Label = api.addressBook.Label(identities[idx].Address)
 	}
 
 	return identities
 }
 
+// IdentityProof returns the Merkle proof of the identity state at the last block of the sealed epoch, the proof is
+// verified against the state root of that block header
+func (api *DnaApi) IdentityProof(address common.Address, epoch uint16) (*identityproof.Proof, error) {
+	return api.identityProofs.Proof(address, epoch)
+}
+
 func (api *DnaApi) Identity(address *common.Address) Identity {
 	var flipKeyWordPairs []int
 	coinbase := api.GetCoinbaseAddr()
@@ -255,9 +286,44 @@ func (api *DnaApi) Identity(address *common.Address) Identity {
 
 	converted := convertIdentity(api.baseApi.getAppState().State.Epoch(), *address, api.baseApi.getAppState().State.GetIdentity(*address), flipKeyWordPairs)
 	converted.Online = getIdentityOnlineStatus(api.baseApi.getAppState(), *address)
+	converted.Profile = api.cachedProfile(*address, true)
+	converted.Label = api.addressBook.Label(*address)
 	return converted
 }
 
+func (api *DnaApi) cachedProfile(address common.Address, prefetch bool) *IdentityProfile {
+	hash := api.baseApi.getAppState().State.GetProfileHash(address)
+	if len(hash) == 0 {
+		return nil
+	}
+	identityProfile, ok := api.profileManager.CachedProfile(hash)
+	if !ok {
+		if prefetch {
+			api.profileManager.Prefetch(hash)
+		}
+		return nil
+	}
+	result := &IdentityProfile{
+		Nickname: string(identityProfile.Nickname),
+	}
+	if info, verified, _ := profile.VerifiedInfo(identityProfile, address); info != nil {
+		result.Verified = verified
+		result.Avatar = cidString(info.Avatar)
+	}
+	return result
+}
+
+func cidString(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	c, err := cid.Cast(data)
+	if err != nil {
+		return ""
+	}
+	return c.String()
+}
+
 func getIdentityOnlineStatus(state *appstate.AppState, addr common.Address) bool {
 	isOnline := state.ValidatorsCache.IsOnlineIdentity(addr)
 	hasPendingStatusSwitch := state.State.HasStatusSwitchAddresses(addr)
@@ -268,28 +334,31 @@ func getIdentityOnlineStatus(state *appstate.AppState, addr common.Address) bool
 	}
 }
 
-func convertIdentity(currentEpoch uint16, address common.Address, data state.Identity, flipKeyWordPairs []int) Identity {
-	var s string
-	switch data.State {
+func convertIdentityState(identityState state.IdentityState) string {
+	switch identityState {
 	case state.Invite:
-		s = "Invite"
+		return "Invite"
 	case state.Candidate:
-		s = "Candidate"
+		return "Candidate"
 	case state.Newbie:
-		s = "Newbie"
+		return "Newbie"
 	case state.Verified:
-		s = "Verified"
+		return "Verified"
 	case state.Suspended:
-		s = "Suspended"
+		return "Suspended"
 	case state.Zombie:
-		s = "Zombie"
+		return "Zombie"
 	case state.Killed:
-		s = "Killed"
+		return "Killed"
 	case state.Human:
-		s = "Human"
+		return "Human"
 	default:
-		s = "Undefined"
+		return "Undefined"
 	}
+}
+
+func convertIdentity(currentEpoch uint16, address common.Address, data state.Identity, flipKeyWordPairs []int) Identity {
+	s := convertIdentityState(data.State)
 
 	var flags []string
 	if data.LastValidationStatus.HasFlag(state.AllFlipsNotQualified) {
@@ -412,6 +481,180 @@ func (api *DnaApi) CeremonyIntervals() CeremonyIntervals {
 	}
 }
 
+type ProjectRewardsArgs struct {
+	Address     *common.Address `json:"address"`
+	Flips       *int            `json:"flips"`
+	InviteeAges []uint16        `json:"inviteeAges"`
+	Newbie      *bool           `json:"newbie"`
+	Blocks      uint64          `json:"blocks"`
+}
+
+type ProjectedReward struct {
+	Balance decimal.Decimal `json:"balance"`
+	Stake   decimal.Decimal `json:"stake"`
+}
+
+type RewardProjection struct {
+	Epoch                 uint16          `json:"epoch"`
+	Blocks                uint64          `json:"blocks"`
+	TotalReward           decimal.Decimal `json:"totalReward"`
+	Age                   uint16          `json:"age"`
+	Stake                 decimal.Decimal `json:"stake"`
+	NormalizedAges        float32         `json:"normalizedAges"`
+	TotalFlips            float32         `json:"totalFlips"`
+	TotalInvitationWeight float32         `json:"totalInvitationWeight"`
+	Validation            ProjectedReward `json:"validation"`
+	Flips                 ProjectedReward `json:"flips"`
+	Invitations           ProjectedReward `json:"invitations"`
+	Total                 ProjectedReward `json:"total"`
+}
+
+func convertProjectedReward(reward blockchain.ProjectedReward) ProjectedReward {
+	return ProjectedReward{
+		Balance: blockchain.ConvertToFloat(reward.Balance),
+		Stake:   blockchain.ConvertToFloat(reward.Stake),
+	}
+}
+
+// ProjectRewards estimates rewards of the identity at the next validation, the coinbase address is used if the address is not specified
+func (api *DnaApi) ProjectRewards(args ProjectRewardsArgs) (RewardProjection, error) {
+	addr := api.baseApi.getCurrentCoinbase()
+	if args.Address != nil {
+		addr = *args.Address
+	}
+	projection, err := api.bc.ProjectRewards(addr, blockchain.RewardProjectionParams{
+		Flips:       args.Flips,
+		InviteeAges: args.InviteeAges,
+		Newbie:      args.Newbie,
+		Blocks:      args.Blocks,
+	})
+	if err != nil {
+		return RewardProjection{}, err
+	}
+	return RewardProjection{
+		Epoch:                 projection.Epoch,
+		Blocks:                projection.Blocks,
+		TotalReward:           blockchain.ConvertToFloat(projection.TotalReward),
+		Age:                   projection.Age,
+		Stake:                 blockchain.ConvertToFloat(api.baseApi.getAppState().State.GetStakeBalance(addr)),
+		NormalizedAges:        projection.NormalizedAges,
+		TotalFlips:            projection.TotalFlips,
+		TotalInvitationWeight: projection.TotalInvitationWeight,
+		Validation:            convertProjectedReward(projection.Validation),
+		Flips:                 convertProjectedReward(projection.Flips),
+		Invitations:           convertProjectedReward(projection.Invitations),
+		Total:                 convertProjectedReward(projection.Total),
+	}, nil
+}
+
+type SimulationStage struct {
+	Name     string  `json:"name"`
+	Duration float64 `json:"duration"`
+	Deadline float64 `json:"deadline"`
+	Failed   bool    `json:"failed"`
+	Error    string  `json:"error,omitempty"`
+	Warning  string  `json:"warning,omitempty"`
+}
+
+type CeremonySimulation struct {
+	StartTime int64             `json:"startTime"`
+	Duration  float64           `json:"duration"`
+	Failed    bool              `json:"failed"`
+	Stages    []SimulationStage `json:"stages"`
+}
+
+// SimulateCeremony runs validation session steps against synthetic flips and reports stages which would fail
+func (api *DnaApi) SimulateCeremony(ctx context.Context) (CeremonySimulation, error) {
+	if err := authorize(ctx, "simulateCeremony"); err != nil {
+		return CeremonySimulation{}, err
+	}
+	report, err := api.simulator.Run()
+	if err != nil {
+		return CeremonySimulation{}, err
+	}
+	result := CeremonySimulation{
+		StartTime: report.StartTime.Unix(),
+		Duration:  report.Duration.Seconds(),
+		Failed:    report.Failed(),
+	}
+	for _, stage := range report.Stages {
+		item := SimulationStage{
+			Name:     stage.Name,
+			Duration: stage.Duration.Seconds(),
+			Deadline: stage.Deadline.Seconds(),
+			Failed:   stage.Failed(),
+			Warning:  stage.Warning,
+		}
+		if stage.Err != nil {
+			item.Error = stage.Err.Error()
+		}
+		result.Stages = append(result.Stages, item)
+	}
+	return result, nil
+}
+
+type OnlineKeeperStatus struct {
+	Enabled     bool        `json:"enabled"`
+	Online      bool        `json:"online"`
+	Intent      bool        `json:"intent"`
+	NodeId      string      `json:"nodeId"`
+	LockOwner   string      `json:"lockOwner"`
+	LastAttempt int64       `json:"lastAttempt,omitempty"`
+	LastTx      common.Hash `json:"lastTx"`
+	LastError   string      `json:"lastError,omitempty"`
+}
+
+func (api *DnaApi) OnlineKeeperStatus() OnlineKeeperStatus {
+	status := api.onlineKeeper.Status()
+	result := OnlineKeeperStatus{
+		Enabled:   status.Enabled,
+		Online:    status.Online,
+		Intent:    status.Intent,
+		NodeId:    status.NodeId,
+		LockOwner: status.LockOwner,
+		LastTx:    status.LastTx,
+	}
+	if !status.LastAttempt.IsZero() {
+		result.LastAttempt = status.LastAttempt.Unix()
+	}
+	if status.LastError != nil {
+		result.LastError = status.LastError.Error()
+	}
+	return result
+}
+
+type StandbyStatus struct {
+	Standby    bool   `json:"standby"`
+	LockHeight uint64 `json:"lockHeight"`
+}
+
+func (api *DnaApi) StandbyStatus() StandbyStatus {
+	status := api.standby.Status()
+	return StandbyStatus{
+		Standby:    status.Standby,
+		LockHeight: status.LockHeight,
+	}
+}
+
+// Promote switches hot-standby node to active mode, the former active node must be stopped or demoted before
+func (api *DnaApi) Promote(ctx context.Context) (StandbyStatus, error) {
+	if err := authorize(ctx, "promote"); err != nil {
+		return StandbyStatus{}, err
+	}
+	if err := api.standby.Promote(api.bc.Head.Height()); err != nil {
+		return StandbyStatus{}, err
+	}
+	return api.StandbyStatus(), nil
+}
+
+func (api *DnaApi) Demote(ctx context.Context) (StandbyStatus, error) {
+	if err := authorize(ctx, "demote"); err != nil {
+		return StandbyStatus{}, err
+	}
+	api.standby.Demote()
+	return api.StandbyStatus(), nil
+}
+
 func (api *DnaApi) ExportKey(password string) (string, error) {
 	if password == "" {
 		return "", errors.New("password should not be empty")
@@ -466,6 +709,27 @@ type ChangeProfileResponse struct {
 type ProfileResponse struct {
 	Info     *hexutil.Bytes `json:"info"`
 	Nickname string         `json:"nickname"`
+	// Avatar, Contacts and Private are set if the profile uses the common signed schema,
+	// Private is decrypted for the own profile only
+	Avatar   string           `json:"avatar,omitempty"`
+	Contacts []ProfileContact `json:"contacts,omitempty"`
+	Private  *hexutil.Bytes   `json:"private,omitempty"`
+	Signed   bool             `json:"signed"`
+	Verified bool             `json:"verified"`
+}
+
+type ProfileContact struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type PublishProfileArgs struct {
+	Nickname string           `json:"nickname"`
+	Avatar   string           `json:"avatar"`
+	Contacts []ProfileContact `json:"contacts"`
+	// Private is encrypted with the node key before publishing
+	Private *hexutil.Bytes  `json:"private"`
+	MaxFee  decimal.Decimal `json:"maxFee"`
 }
 
 func (api *DnaApi) ChangeProfile(ctx context.Context, args ChangeProfileArgs) (ChangeProfileResponse, error) {
@@ -483,8 +747,39 @@ func (api *DnaApi) ChangeProfile(ctx context.Context, args ChangeProfileArgs) (C
 		return ChangeProfileResponse{}, errors.Wrap(err, "failed to add profile data")
 	}
 
+	return api.sendChangeProfileTx(ctx, profileHash, args.MaxFee)
+}
+
+// PublishProfile publishes the profile using the common schema signed by the coinbase key and sends tx to change profile
+func (api *DnaApi) PublishProfile(ctx context.Context, args PublishProfileArgs) (ChangeProfileResponse, error) {
+	var avatar []byte
+	if len(args.Avatar) > 0 {
+		c, err := cid.Decode(args.Avatar)
+		if err != nil {
+			return ChangeProfileResponse{}, errors.New("invalid avatar cid")
+		}
+		avatar = c.Bytes()
+	}
+	var contacts []profile.Contact
+	for _, contact := range args.Contacts {
+		contacts = append(contacts, profile.Contact{Kind: contact.Kind, Value: contact.Value})
+	}
+	var private []byte
+	if args.Private != nil {
+		private = *args.Private
+	}
+
+	profileHash, err := api.profileManager.PublishProfile([]byte(args.Nickname), avatar, contacts, private)
+	if err != nil {
+		return ChangeProfileResponse{}, errors.Wrap(err, "failed to add profile data")
+	}
+
+	return api.sendChangeProfileTx(ctx, profileHash, args.MaxFee)
+}
+
+func (api *DnaApi) sendChangeProfileTx(ctx context.Context, profileHash []byte, maxFee decimal.Decimal) (ChangeProfileResponse, error) {
 	txHash, err := api.baseApi.sendTx(ctx, api.baseApi.getCurrentCoinbase(), nil, types.ChangeProfileTx, decimal.Zero,
-		args.MaxFee, decimal.Zero, 0, 0, attachments.CreateChangeProfileAttachment(profileHash),
+		maxFee, decimal.Zero, 0, 0, attachments.CreateChangeProfileAttachment(profileHash),
 		nil)
 
 	if err != nil {
@@ -517,13 +812,68 @@ func (api *DnaApi) Profile(address *common.Address) (ProfileResponse, error) {
 		b := hexutil.Bytes(identityProfile.Info)
 		info = &b
 	}
-	return ProfileResponse{
+	result := ProfileResponse{
 		Nickname: string(identityProfile.Nickname),
 		Info:     info,
-	}, nil
+	}
+	signedInfo, verified, err := profile.VerifiedInfo(identityProfile, *address)
+	if err != nil {
+		return ProfileResponse{}, err
+	}
+	if signedInfo == nil {
+		return result, nil
+	}
+	result.Signed = true
+	result.Verified = verified
+	result.Avatar = cidString(signedInfo.Avatar)
+	for _, contact := range signedInfo.Contacts {
+		result.Contacts = append(result.Contacts, ProfileContact{Kind: contact.Kind, Value: contact.Value})
+	}
+	if *address == api.GetCoinbaseAddr() {
+		if private, err := api.profileManager.DecryptPrivate(signedInfo); err == nil && len(private) > 0 {
+			b := hexutil.Bytes(private)
+			result.Private = &b
+		}
+	}
+	return result, nil
+}
+
+// Sign signs the value by the node key like before if the address isn't set, dna_signatureAddress returns the signer.
+// If the address is set, the message prefixed like personal messages is signed by the coinbase which signing is
+// unlocked by dna_unlockSigning or by the unlocked account of the keystore, dna_verify returns the signer.
+func (api *DnaApi) Sign(value string, addr *common.Address) (hexutil.Bytes, error) {
+	if addr == nil {
+		return api.signNodeKey(value), nil
+	}
+	hash := prefixedSignatureHash(value)
+	if *addr == api.GetCoinbaseAddr() {
+		return api.baseApi.secStore.SignMessage(hash[:])
+	}
+	return api.baseApi.ks.SignHash(keystore.Account{Address: *addr}, hash[:])
 }
 
-func (api *DnaApi) Sign(value string) hexutil.Bytes {
+// UnlockSigning allows dna_sign to sign messages by the coinbase for timeout seconds, 0 - until dna_lockSigning, the
+// request must be made with the API key or JWT
+func (api *DnaApi) UnlockSigning(ctx context.Context, timeout time.Duration) error {
+	if !rpc.IsAuthenticated(ctx) {
+		return errors.New("authentication is required")
+	}
+	api.baseApi.secStore.UnlockSigning(timeout * time.Second)
+	return nil
+}
+
+func (api *DnaApi) LockSigning() {
+	api.baseApi.secStore.LockSigning()
+}
+
+// Verify returns the address of the key which signed the message by dna_sign
+func (api *DnaApi) Verify(signature hexutil.Bytes, message string) (common.Address, error) {
+	hash := prefixedSignatureHash(message)
+	return recoverAddress(hash, signature)
+}
+
+// signNodeKey signs the rlp hash of the value by the node key, it is verified by dna_signatureAddress
+func (api *DnaApi) signNodeKey(value string) hexutil.Bytes {
 	hash := signatureHash(value)
 	return api.baseApi.secStore.Sign(hash[:])
 }
@@ -534,8 +884,11 @@ type SignatureAddressArgs struct {
 }
 
 func (api *DnaApi) SignatureAddress(args SignatureAddressArgs) (common.Address, error) {
-	hash := signatureHash(args.Value)
-	pubKey, err := crypto.Ecrecover(hash[:], args.Signature)
+	return recoverAddress(signatureHash(args.Value), args.Signature)
+}
+
+func recoverAddress(hash common.Hash, signature []byte) (common.Address, error) {
+	pubKey, err := crypto.Ecrecover(hash[:], signature)
 	if err != nil {
 		return common.Address{}, err
 	}
@@ -546,6 +899,123 @@ func (api *DnaApi) SignatureAddress(args SignatureAddressArgs) (common.Address,
 	return addr, nil
 }
 
+const maxAttestationChallengeLength = 128
+
+type AttestationArgs struct {
+	// Challenge is an arbitrary value of the verifier included into the signed message to prevent replays
+	Challenge string `json:"challenge"`
+}
+
+type Attestation struct {
+	Network   uint32         `json:"network"`
+	Coinbase  common.Address `json:"coinbase"`
+	Version   string         `json:"version"`
+	Height    uint64         `json:"height"`
+	BlockHash common.Hash    `json:"blockHash"`
+	Timestamp int64          `json:"timestamp"`
+	Challenge string         `json:"challenge"`
+	Message   string         `json:"message"`
+	Signature hexutil.Bytes  `json:"signature"`
+}
+
+func (a *Attestation) message() string {
+	return fmt.Sprintf("idena-attestation:%v:%v:%v:%v:%v:%v:%v", a.Network, a.Coinbase.Hex(), a.Version, a.Height,
+		a.BlockHash.Hex(), a.Timestamp, a.Challenge)
+}
+
+// Attestation returns the message signed by the node key which states the coinbase, version and best block of the node,
+// the signature is verified by dna_signatureAddress
+func (api *DnaApi) Attestation(args AttestationArgs) (Attestation, error) {
+	if len(args.Challenge) > maxAttestationChallengeLength {
+		return Attestation{}, errors.Errorf("challenge is longer than %v bytes", maxAttestationChallengeLength)
+	}
+	head := api.bc.Head
+	result := Attestation{
+		Network:   api.bc.Config().Network,
+		Coinbase:  api.GetCoinbaseAddr(),
+		Version:   api.appVersion,
+		Height:    head.Height(),
+		BlockHash: head.Hash(),
+		Timestamp: time.Now().Unix(),
+		Challenge: args.Challenge,
+	}
+	result.Message = result.message()
+	result.Signature = api.signNodeKey(result.Message)
+	return result, nil
+}
+
+// VerifyAttestation checks that the attestation is signed by the key of the stated coinbase
+func (api *DnaApi) VerifyAttestation(attestation Attestation) (bool, error) {
+	message := attestation.message()
+	if attestation.Message != "" && attestation.Message != message {
+		return false, errors.New("message doesn't match attestation fields")
+	}
+	addr, err := api.SignatureAddress(SignatureAddressArgs{
+		Value:     message,
+		Signature: attestation.Signature,
+	})
+	if err != nil {
+		return false, err
+	}
+	return addr == attestation.Coinbase, nil
+}
+
+type ParticipationVote struct {
+	Step      uint8       `json:"step"`
+	VotedHash common.Hash `json:"votedHash"`
+	// Delay is the time in seconds between the round start and the vote
+	Delay float64 `json:"delay"`
+	Error string  `json:"error,omitempty"`
+}
+
+type CommitteeParticipation struct {
+	Round            uint64              `json:"round"`
+	Start            int64               `json:"start"`
+	Proposer         bool                `json:"proposer"`
+	Proposal         *common.Hash        `json:"proposal,omitempty"`
+	ProposalAccepted bool                `json:"proposalAccepted"`
+	Votes            []ParticipationVote `json:"votes"`
+	InCert           bool                `json:"inCert"`
+	Result           string              `json:"result"`
+	Block            *common.Hash        `json:"block,omitempty"`
+}
+
+// CommitteeHistory returns recent rounds the node identity has been selected to propose or vote in,
+// inCert shows if the own vote has been included into the block certificate
+func (api *DnaApi) CommitteeHistory(count int) []CommitteeParticipation {
+	history := api.baseApi.engine.CommitteeHistory(count)
+	result := make([]CommitteeParticipation, 0, len(history))
+	for _, item := range history {
+		participation := CommitteeParticipation{
+			Round:            item.Round,
+			Start:            item.Start.Unix(),
+			Proposer:         item.Proposer,
+			Proposal:         item.Proposal,
+			ProposalAccepted: item.ProposalAccepted,
+			Votes:            make([]ParticipationVote, 0, len(item.Votes)),
+			InCert:           item.InCert,
+			Result:           string(item.Result),
+			Block:            optionalHash(item.Block),
+		}
+		for _, vote := range item.Votes {
+			participation.Votes = append(participation.Votes, ParticipationVote{
+				Step:      vote.Step,
+				VotedHash: vote.VotedHash,
+				Delay:     vote.Time.Sub(item.Start).Seconds(),
+				Error:     vote.Error,
+			})
+		}
+		result = append(result, participation)
+	}
+	return result
+}
+
 func signatureHash(value string) common.Hash {
 	return rlp.Hash(value)
 }
+
+// prefixedSignatureHash is keccak256("\x19Idena Signed Message:\n" + len(message) + message), the prefix makes
+// signed messages distinct from txs and other signed data
+func prefixedSignatureHash(message string) common.Hash {
+	return crypto.Keccak256Hash([]byte(fmt.Sprintf("\x19Idena Signed Message:\n%d%s", len(message), message)))
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"context"
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/keystore"
+	"github.com/idena-network/idena-go/secstore"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestDnaApi(t *testing.T) (*DnaApi, *keystore.KeyStore, func()) {
+	dir, err := ioutil.TempDir("", "dna-api-test")
+	require.NoError(t, err)
+	ks := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+	secStore := secstore.NewSecStore()
+	key, _ := crypto.GenerateKey()
+	secStore.AddKey(crypto.FromECDSA(key))
+	api := NewDnaApi(NewBaseApi(nil, nil, ks, secStore), nil, nil, "", nil, nil, nil, nil, nil, nil)
+	return api, ks, func() {
+		secStore.Destroy()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestDnaApi_SignLegacy(t *testing.T) {
+	api, _, cleanup := newTestDnaApi(t)
+	defer cleanup()
+
+	signature, err := api.Sign("foo", nil)
+	require.NoError(t, err)
+	signer, err := api.SignatureAddress(SignatureAddressArgs{Value: "foo", Signature: signature})
+	require.NoError(t, err)
+	require.Equal(t, api.GetCoinbaseAddr(), signer)
+}
+
+func TestDnaApi_SignCoinbase(t *testing.T) {
+	api, _, cleanup := newTestDnaApi(t)
+	defer cleanup()
+	coinbase := api.GetCoinbaseAddr()
+
+	_, err := api.Sign("foo", &coinbase)
+	require.Equal(t, secstore.ErrSigningLocked, err)
+	require.Error(t, api.UnlockSigning(context.Background(), 0))
+	_, err = api.Sign("foo", &coinbase)
+	require.Equal(t, secstore.ErrSigningLocked, err)
+
+	api.baseApi.secStore.UnlockSigning(0)
+	signature, err := api.Sign("foo", &coinbase)
+	require.NoError(t, err)
+	signer, err := api.Verify(signature, "foo")
+	require.NoError(t, err)
+	require.Equal(t, coinbase, signer)
+
+	// prefixed and legacy signatures aren't interchangeable
+	signer, err = api.SignatureAddress(SignatureAddressArgs{Value: "foo", Signature: signature})
+	require.NoError(t, err)
+	require.NotEqual(t, coinbase, signer)
+
+	api.LockSigning()
+	_, err = api.Sign("foo", &coinbase)
+	require.Equal(t, secstore.ErrSigningLocked, err)
+}
+
+func TestDnaApi_SignKeystoreAccount(t *testing.T) {
+	api, ks, cleanup := newTestDnaApi(t)
+	defer cleanup()
+	account, err := ks.NewAccount("pass")
+	require.NoError(t, err)
+
+	_, err = api.Sign("foo", &account.Address)
+	require.Equal(t, keystore.ErrLocked, err)
+	unknown := common.Address{0x1}
+	_, err = api.Sign("foo", &unknown)
+	require.Error(t, err)
+
+	require.NoError(t, ks.TimedUnlock(account, "pass", time.Minute))
+	signature, err := api.Sign("foo", &account.Address)
+	require.NoError(t, err)
+	signer, err := api.Verify(signature, "foo")
+	require.NoError(t, err)
+	require.Equal(t, account.Address, signer)
+
+	signer, err = api.Verify(signature, "bar")
+	require.NoError(t, err)
+	require.NotEqual(t, account.Address, signer)
+	_, err = api.Verify(hexutil.Bytes{0x1}, "foo")
+	require.Error(t, err)
+}
+
+func TestDnaApi_Attestation(t *testing.T) {
+	api, _, cleanup := newTestDnaApi(t)
+	defer cleanup()
+	chain, _, _, _ := blockchain.NewTestBlockchain(false, nil)
+	api.bc = chain.Blockchain
+	api.appVersion = "0.0.1"
+
+	attestation, err := api.Attestation(AttestationArgs{Challenge: "foo"})
+	require.NoError(t, err)
+	require.Equal(t, api.GetCoinbaseAddr(), attestation.Coinbase)
+	require.Equal(t, chain.Head.Hash(), attestation.BlockHash)
+	verified, err := api.VerifyAttestation(attestation)
+	require.NoError(t, err)
+	require.True(t, verified)
+
+	// the message may be omitted by the verifier
+	stripped := attestation
+	stripped.Message = ""
+	verified, err = api.VerifyAttestation(stripped)
+	require.NoError(t, err)
+	require.True(t, verified)
+
+	tampered := attestation
+	tampered.Message = ""
+	tampered.Height++
+	verified, _ = api.VerifyAttestation(tampered)
+	require.False(t, verified)
+
+	tampered = attestation
+	tampered.Message = ""
+	tampered.Challenge = "bar"
+	verified, _ = api.VerifyAttestation(tampered)
+	require.False(t, verified)
+
+	tampered = attestation
+	tampered.Challenge = "bar"
+	_, err = api.VerifyAttestation(tampered)
+	require.Error(t, err)
+
+	_, err = api.Attestation(AttestationArgs{Challenge: strings.Repeat("a", maxAttestationChallengeLength+1)})
+	require.Error(t, err)
+	_, err = api.Attestation(AttestationArgs{Challenge: strings.Repeat("a", maxAttestationChallengeLength)})
+	require.NoError(t, err)
+}
+
+func TestDnaApi_AdminMethods(t *testing.T) {
+	api, _, cleanup := newTestDnaApi(t)
+	defer cleanup()
+
+	_, err := api.Promote(context.Background())
+	require.Error(t, err)
+	_, err = api.Demote(context.Background())
+	require.Error(t, err)
+	_, err = api.SimulateCeremony(context.Background())
+	require.Error(t, err)
+}
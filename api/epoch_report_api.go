@@ -0,0 +1,32 @@
+package api
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/core/epochreport"
+	"github.com/pkg/errors"
+)
+
+// EpochReportApi offers epoch results of the coinbase and watched identities
+type EpochReportApi struct {
+	baseApi *BaseApi
+	builder *epochreport.Builder
+}
+
+// NewEpochReportApi creates a new EpochReportApi instance
+func NewEpochReportApi(baseApi *BaseApi, builder *epochreport.Builder) *EpochReportApi {
+	return &EpochReportApi{baseApi, builder}
+}
+
+// EpochReport returns validation result, rewards, penalties and balance changes of the identity at the epoch,
+// the coinbase address is used if the address is not specified
+func (api *EpochReportApi) EpochReport(epoch uint16, address *common.Address) (*epochreport.Report, error) {
+	addr := api.baseApi.getCurrentCoinbase()
+	if address != nil {
+		addr = *address
+	}
+	report := api.builder.Report(epoch, addr)
+	if report == nil {
+		return nil, errors.Errorf("report of %v at epoch %v is not found", addr.Hex(), epoch)
+	}
+	return report, nil
+}
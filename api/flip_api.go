@@ -12,26 +12,40 @@ import (
 	"github.com/idena-network/idena-go/core/state"
 	"github.com/idena-network/idena-go/ipfs"
 	"github.com/idena-network/idena-go/log"
+	"github.com/idena-network/idena-go/rlp"
 	"github.com/ipfs/go-cid"
 	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
+	"strings"
 )
 
 type FlipApi struct {
-	baseApi   *BaseApi
-	fp        *flip.Flipper
-	ipfsProxy ipfs.Proxy
-	ceremony  *ceremony.ValidationCeremony
+	baseApi     *BaseApi
+	fp          *flip.Flipper
+	ipfsProxy   ipfs.Proxy
+	ceremony    *ceremony.ValidationCeremony
+	submissions *flip.SubmissionManager
 }
 
 // NewFlipApi creates a new FlipApi instance
-func NewFlipApi(baseApi *BaseApi, fp *flip.Flipper, ipfsProxy ipfs.Proxy, ceremony *ceremony.ValidationCeremony) *FlipApi {
-	return &FlipApi{baseApi, fp, ipfsProxy, ceremony}
+func NewFlipApi(baseApi *BaseApi, fp *flip.Flipper, ipfsProxy ipfs.Proxy, ceremony *ceremony.ValidationCeremony,
+	submissions *flip.SubmissionManager) *FlipApi {
+	return &FlipApi{baseApi, fp, ipfsProxy, ceremony, submissions}
 }
 
 type FlipSubmitResponse struct {
-	TxHash common.Hash `json:"txHash"`
-	Hash   string      `json:"hash"`
+	TxHash   common.Hash `json:"txHash"`
+	Hash     string      `json:"hash"`
+	Warnings []string    `json:"warnings,omitempty"`
+	// State is the submission state, publication and broadcast failures are retried in background
+	State     string `json:"state"`
+	LastError string `json:"lastError,omitempty"`
+}
+
+type FlipValidateResponse struct {
+	Valid    bool     `json:"valid"`
+	Errors   []string `json:"errors"`
+	Warnings []string `json:"warnings"`
 }
 
 type FlipSubmitArgs struct {
@@ -41,12 +55,10 @@ type FlipSubmitArgs struct {
 	PairId     uint8          `json:"pairId"`
 }
 
-func (api *FlipApi) Submit(args FlipSubmitArgs) (FlipSubmitResponse, error) {
+func (args FlipSubmitArgs) parts() (rawPublicPart, rawPrivatePart []byte, err error) {
 	if args.Hex == nil && args.PublicHex == nil {
-		return FlipSubmitResponse{}, errors.New("flip is empty")
+		return nil, nil, errors.New("flip is empty")
 	}
-
-	var rawPublicPart, rawPrivatePart []byte
 	if args.PublicHex != nil {
 		rawPublicPart = *args.PublicHex
 	} else {
@@ -55,6 +67,34 @@ func (api *FlipApi) Submit(args FlipSubmitArgs) (FlipSubmitResponse, error) {
 	if args.PrivateHex != nil {
 		rawPrivatePart = *args.PrivateHex
 	}
+	return rawPublicPart, rawPrivatePart, nil
+}
+
+// Validate runs strict local quality checks of the flip without submitting it, Submit rejects the flip only for errors
+// which make it invalid and reports the others as warnings
+func (api *FlipApi) Validate(args FlipSubmitArgs) (FlipValidateResponse, error) {
+	rawPublicPart, rawPrivatePart, err := args.parts()
+	if err != nil {
+		return FlipValidateResponse{}, err
+	}
+	result := api.fp.CheckFlip(rawPublicPart, rawPrivatePart, args.PairId, true)
+	return FlipValidateResponse{
+		Valid:    result.Valid(),
+		Errors:   append([]string{}, result.Errors...),
+		Warnings: append([]string{}, result.Warnings...),
+	}, nil
+}
+
+func (api *FlipApi) Submit(args FlipSubmitArgs) (FlipSubmitResponse, error) {
+	rawPublicPart, rawPrivatePart, err := args.parts()
+	if err != nil {
+		return FlipSubmitResponse{}, err
+	}
+
+	check := api.fp.CheckFlip(rawPublicPart, rawPrivatePart, args.PairId, false)
+	if !check.Valid() {
+		return FlipSubmitResponse{}, errors.Errorf("flip check failed: %v", strings.Join(check.Errors, "; "))
+	}
 
 	cid, encryptedPublicPart, encryptedPrivatePart, err := api.fp.PrepareFlip(rawPublicPart, rawPrivatePart)
 
@@ -78,18 +118,27 @@ func (api *FlipApi) Submit(args FlipSubmitArgs) (FlipSubmitResponse, error) {
 		PrivatePart: encryptedPrivatePart,
 	}
 
-	if err := api.fp.AddNewFlip(flip, true); err != nil {
+	submission, err := api.submissions.Submit(flip, check.ImageHashes)
+	if err != nil {
 		return FlipSubmitResponse{}, err
 	}
 
-	log.Info("Flip submitted", "hash", tx.Hash().Hex())
+	log.Info("Flip submitted", "hash", tx.Hash().Hex(), "state", submission.State, "warnings", len(check.Warnings))
 
 	return FlipSubmitResponse{
-		TxHash: tx.Hash(),
-		Hash:   cid.String(),
+		TxHash:    tx.Hash(),
+		Hash:      cid.String(),
+		Warnings:  check.Warnings,
+		State:     submission.State,
+		LastError: submission.LastError,
 	}, nil
 }
 
+// Submissions returns lifecycle states of recently submitted own flips
+func (api *FlipApi) Submissions() []flip.Submission {
+	return api.submissions.Submissions()
+}
+
 func (api *FlipApi) Delete(ctx context.Context, hash string) (common.Hash, error) {
 	c, err := cid.Decode(hash)
 	if err != nil {
@@ -161,7 +210,8 @@ func prepareHashes(flipper *flip.Flipper, flips [][]byte, shortSession bool) ([]
 	}
 
 	var result []FlipHashesResponse
-	for _, v := range flips {
+	readiness := flipper.FlipsReadiness(flips)
+	for i, v := range flips {
 		extraFlip := false
 		if shortSession && len(result) >= int(common.ShortSessionFlipsCount()) {
 			extraFlip = true
@@ -169,7 +219,7 @@ func prepareHashes(flipper *flip.Flipper, flips [][]byte, shortSession bool) ([]
 		cid, _ := cid.Parse(v)
 		result = append(result, FlipHashesResponse{
 			Hash:  cid.String(),
-			Ready: flipper.IsFlipReady(v),
+			Ready: readiness[i],
 			Extra: extraFlip,
 		})
 	}
@@ -266,6 +316,99 @@ func (api *FlipApi) SubmitLongAnswers(args SubmitAnswersArgs) (SubmitAnswersResp
 	}, nil
 }
 
+// AnswerSubmissions returns own answers of the current ceremony with delivery paths which accepted them
+func (api *FlipApi) AnswerSubmissions() []ceremony.AnswerSubmission {
+	return api.ceremony.AnswerSubmissions()
+}
+
+type AuditedAnswer struct {
+	Hash       string `json:"hash"`
+	Answer     string `json:"answer"`
+	WrongWords bool   `json:"wrongWords"`
+}
+
+type AuditedTx struct {
+	Type        string        `json:"type"`
+	Hash        common.Hash   `json:"hash"`
+	Raw         hexutil.Bytes `json:"raw"`
+	SentAt      int64         `json:"sentAt"`
+	Paths       []string      `json:"paths,omitempty"`
+	BlockHash   *common.Hash  `json:"blockHash,omitempty"`
+	BlockHeight uint64        `json:"blockHeight,omitempty"`
+	MinedAt     int64         `json:"minedAt,omitempty"`
+}
+
+type MyAnswersResponse struct {
+	Epoch        uint16          `json:"epoch"`
+	Address      common.Address  `json:"address"`
+	ShortAnswers []AuditedAnswer `json:"shortAnswers"`
+	LongAnswers  []AuditedAnswer `json:"longAnswers"`
+	AnswersHash  *common.Hash    `json:"answersHash,omitempty"`
+	Salt         hexutil.Bytes   `json:"salt,omitempty"`
+	// HashVerified is set if the short answers with the salt match the answers hash committed on chain
+	HashVerified bool        `json:"hashVerified"`
+	Txs          []AuditedTx `json:"txs"`
+}
+
+func auditedAnswers(flips [][]byte, bits []byte) []AuditedAnswer {
+	result := make([]AuditedAnswer, 0, len(flips))
+	if bits == nil || len(flips) == 0 {
+		return result
+	}
+	_, value, err := attachments.DecodePayload(types.SubmitLongAnswersTx, bits, attachments.DecodeOptions{FlipsCount: uint(len(flips))})
+	if err != nil {
+		return result
+	}
+	for i, answer := range value.(*attachments.DecodedAnswers).Answers {
+		c, _ := cid.Cast(flips[i])
+		result = append(result, AuditedAnswer{Hash: c.String(), Answer: answer.Answer, WrongWords: answer.WrongWords})
+	}
+	return result
+}
+
+// MyAnswers returns answers, flip hashes, the answers salt and receipts of ceremony txs sent by the node identity
+// in the epoch, the current epoch is used if it isn't set
+func (api *FlipApi) MyAnswers(epoch *uint16) (*MyAnswersResponse, error) {
+	e := api.baseApi.getAppState().State.Epoch()
+	if epoch != nil {
+		e = *epoch
+	}
+	audit := api.ceremony.AnswerAudit(e)
+	if audit == nil {
+		return nil, errors.Errorf("no answers are recorded for epoch %v", e)
+	}
+	result := &MyAnswersResponse{
+		Epoch:        audit.Epoch,
+		Address:      audit.Address,
+		ShortAnswers: auditedAnswers(audit.ShortFlips, audit.ShortAnswers),
+		LongAnswers:  auditedAnswers(audit.LongFlips, audit.LongAnswers),
+		Salt:         audit.Salt,
+		Txs:          make([]AuditedTx, 0, len(audit.Txs)),
+	}
+	if audit.AnswersHash != (common.Hash{}) {
+		answersHash := audit.AnswersHash
+		result.AnswersHash = &answersHash
+		result.HashVerified = common.Hash(rlp.Hash(append(append([]byte{}, audit.ShortAnswers...), audit.Salt...))) == answersHash
+	}
+	for _, item := range audit.Txs {
+		tx := AuditedTx{
+			Type:   txTypeMap[item.Type],
+			Hash:   item.Hash,
+			Raw:    item.Raw,
+			SentAt: int64(item.SentAt),
+			Paths:  item.Paths,
+		}
+		if item.BlockHeight > 0 {
+			blockHash := item.BlockHash
+			tx.BlockHash = &blockHash
+			tx.BlockHeight = item.BlockHeight
+			tx.MinedAt = int64(item.MinedAt)
+		}
+		result.Txs = append(result.Txs, tx)
+	}
+	return result, nil
+}
+
 type FlipWordsResponse struct {
 	Words [2]int `json:"words"`
 }
@@ -281,6 +424,59 @@ func (api *FlipApi) Words(hash string) (FlipWordsResponse, error) {
 	}, err
 }
 
+type FlipFetchStatus struct {
+	Hash        string `json:"hash"`
+	State       string `json:"state"`
+	Attempts    int    `json:"attempts"`
+	Size        int    `json:"size"`
+	Error       string `json:"error,omitempty"`
+	NextAttempt int64  `json:"nextAttempt,omitempty"`
+	LoadedAt    int64  `json:"loadedAt,omitempty"`
+	Pinned      bool   `json:"pinned"`
+}
+
+type FlipFetchStatusResponse struct {
+	Total  int               `json:"total"`
+	Loaded int               `json:"loaded"`
+	Failed int               `json:"failed"`
+	Flips  []FlipFetchStatus `json:"flips"`
+}
+
+func (api *FlipApi) FetchStatus() FlipFetchStatusResponse {
+	statuses := api.fp.FetchStatuses()
+	result := FlipFetchStatusResponse{
+		Total: len(statuses),
+		Flips: make([]FlipFetchStatus, 0, len(statuses)),
+	}
+	for _, status := range statuses {
+		c, _ := cid.Cast(status.Cid)
+		item := FlipFetchStatus{
+			Hash:     c.String(),
+			State:    status.State.String(),
+			Attempts: status.Attempts,
+			Size:     status.Size,
+			Error:    status.LastError,
+			Pinned:   status.Pinned,
+		}
+		switch status.State {
+		case flip.FetchLoaded:
+			result.Loaded++
+			item.LoadedAt = status.LoadedAt.Unix()
+		case flip.FetchFailed:
+			result.Failed++
+		case flip.FetchRetrying:
+			item.NextAttempt = status.NextAttempt.Unix()
+		}
+		result.Flips = append(result.Flips, item)
+	}
+	return result
+}
+
+// DecryptionStatus returns progress of decryption of flips loaded in the current epoch
+func (api *FlipApi) DecryptionStatus() flip.FlipDecryptionStatus {
+	return api.fp.DecryptionStatus()
+}
+
 func prepareAnswers(answers []FlipAnswer, flips [][]byte) *types.Answers {
 	findAnswer := func(hash []byte) *FlipAnswer {
 		for _, h := range answers {
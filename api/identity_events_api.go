@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/core/identity"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/rpc"
+)
+
+const identityChangesBufferSize = 100
+
+// IdentityEventsApi offers identity state change notifications so it's not required to poll every identity
+type IdentityEventsApi struct {
+	watcher *identity.Watcher
+	bus     eventbus.Bus
+}
+
+// NewIdentityEventsApi creates a new IdentityEventsApi instance
+func NewIdentityEventsApi(watcher *identity.Watcher, bus eventbus.Bus) *IdentityEventsApi {
+	return &IdentityEventsApi{watcher, bus}
+}
+
+// IdentityChanges creates a subscription to identity state changes and penalties
+func (api *IdentityEventsApi) IdentityChanges(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+	changes := make(chan *identity.Change, identityChangesBufferSize)
+	sub := api.bus.Subscribe(events.IdentityChangedEventID, func(e eventbus.Event) {
+		select {
+		case changes <- identity.NewChange(e.(*events.IdentityChangedEvent)):
+		default:
+		}
+	})
+	go func() {
+		defer api.bus.Unsubscribe(sub)
+		for {
+			select {
+			case change := <-changes:
+				notifier.Notify(rpcSub.ID, change)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// RecentIdentityChanges returns buffered identity changes with seq greater than fromSeq, it is an alternative
+// to the subscription for HTTP clients
+func (api *IdentityEventsApi) RecentIdentityChanges(fromSeq uint64) []*identity.Change {
+	result := api.watcher.Recent(fromSeq)
+	if result == nil {
+		return []*identity.Change{}
+	}
+	return result
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/core/invites"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/shopspring/decimal"
+)
+
+// InviteApi offers issuing, tracking and revoking invites of the coinbase identity
+type InviteApi struct {
+	baseApi *BaseApi
+	manager *invites.Manager
+}
+
+// NewInviteApi creates a new InviteApi instance
+func NewInviteApi(baseApi *BaseApi, manager *invites.Manager) *InviteApi {
+	return &InviteApi{baseApi, manager}
+}
+
+type InviteInfo struct {
+	Receiver  common.Address `json:"receiver"`
+	TxHash    common.Hash    `json:"txHash"`
+	Epoch     uint16         `json:"epoch"`
+	IssuedAt  int64          `json:"issuedAt"`
+	Status    string         `json:"status"`
+	ExpiresAt int64          `json:"expiresAt,omitempty"`
+	Key       string         `json:"key,omitempty"`
+	RevokeTx  *common.Hash   `json:"revokeTx,omitempty"`
+}
+
+type InvitesResponse struct {
+	Invites []InviteInfo `json:"invites"`
+	Unused  uint8        `json:"unused"`
+	Warning string       `json:"warning,omitempty"`
+}
+
+type RevokeInviteArgs struct {
+	To     common.Address  `json:"to"`
+	MaxFee decimal.Decimal `json:"maxFee"`
+	BaseTxArgs
+}
+
+// Issue sends invite to the address or to the generated key if the address is not specified, the key is stored by the node
+func (api *InviteApi) Issue(ctx context.Context, args SendInviteArgs) (Invite, error) {
+	receiver := args.To
+	var key *ecdsa.PrivateKey
+
+	if receiver == (common.Address{}) {
+		key, _ = crypto.GenerateKey()
+		receiver = crypto.PubkeyToAddress(key.PublicKey)
+	}
+
+	hash, err := api.baseApi.sendTx(ctx, api.baseApi.getCurrentCoinbase(), &receiver, types.InviteTx, args.Amount, decimal.Zero, decimal.Zero, args.Nonce, args.Epoch, nil, nil)
+	if err != nil {
+		return Invite{}, err
+	}
+
+	var rawKey []byte
+	var stringKey string
+	if key != nil {
+		rawKey = crypto.FromECDSA(key)
+		stringKey = hex.EncodeToString(rawKey)
+	}
+	if err := api.manager.Add(receiver, hash, rawKey); err != nil {
+		return Invite{}, err
+	}
+
+	return Invite{
+		Receiver: receiver,
+		Hash:     hash,
+		Key:      stringKey,
+	}, nil
+}
+
+// List returns invites issued by the coinbase identity with their activation status and expiry
+func (api *InviteApi) List() InvitesResponse {
+	summary := api.manager.Summary()
+	result := InvitesResponse{
+		Invites: make([]InviteInfo, 0, len(summary.Invites)),
+		Unused:  summary.Unused,
+		Warning: summary.Warning,
+	}
+	for _, invite := range summary.Invites {
+		info := InviteInfo{
+			Receiver:  invite.Receiver,
+			TxHash:    invite.TxHash,
+			Epoch:     invite.Epoch,
+			IssuedAt:  invite.IssuedAt,
+			Status:    invite.Status,
+			ExpiresAt: invite.ExpiresAt,
+		}
+		if len(invite.Key) > 0 {
+			info.Key = hex.EncodeToString(invite.Key)
+		}
+		if invite.RevokeTx != (common.Hash{}) {
+			revokeTx := invite.RevokeTx
+			info.RevokeTx = &revokeTx
+		}
+		result.Invites = append(result.Invites, info)
+	}
+	return result
+}
+
+// Revoke sends kill invitee tx for the invite which hasn't been validated yet
+func (api *InviteApi) Revoke(ctx context.Context, args RevokeInviteArgs) (common.Hash, error) {
+	if err := api.manager.CheckRevoke(args.To); err != nil {
+		return common.Hash{}, err
+	}
+	hash, err := api.baseApi.sendTx(ctx, api.baseApi.getCurrentCoinbase(), &args.To, types.KillInviteeTx, decimal.Zero,
+		args.MaxFee, decimal.Zero, args.Nonce, args.Epoch, nil, nil)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	api.manager.SetRevoked(args.To, hash)
+	return hash, nil
+}
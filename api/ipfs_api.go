@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"github.com/idena-network/idena-go/core/ipfsgc"
+	"github.com/idena-network/idena-go/ipfs"
+)
+
+// IpfsApi offers ipfs storage maintenance utils
+type IpfsApi struct {
+	gc *ipfsgc.GarbageCollector
+}
+
+// NewIpfsApi creates a new IpfsApi instance
+func NewIpfsApi(gc *ipfsgc.GarbageCollector) *IpfsApi {
+	return &IpfsApi{gc}
+}
+
+type GcResult struct {
+	StartTime     int64   `json:"startTime"`
+	Duration      float64 `json:"duration"`
+	Unpinned      int     `json:"unpinned"`
+	Protected     int     `json:"protected"`
+	RemovedBlocks int     `json:"removedBlocks"`
+	Error         string  `json:"error,omitempty"`
+}
+
+type GcStatus struct {
+	Enabled     bool           `json:"enabled"`
+	InProgress  bool           `json:"inProgress"`
+	NextRun     int64          `json:"nextRun,omitempty"`
+	TrackedPins map[string]int `json:"trackedPins"`
+	LastResult  *GcResult      `json:"lastResult,omitempty"`
+}
+
+func convertGcResult(result *ipfsgc.Result) *GcResult {
+	if result == nil {
+		return nil
+	}
+	converted := &GcResult{
+		StartTime:     result.StartTime.Unix(),
+		Duration:      result.Duration.Seconds(),
+		Unpinned:      result.Unpinned,
+		Protected:     result.Protected,
+		RemovedBlocks: result.RemovedBlocks,
+	}
+	if result.Err != nil {
+		converted.Error = result.Err.Error()
+	}
+	return converted
+}
+
+func convertDataType(dataType ipfs.DataType) string {
+	switch dataType {
+	case ipfs.Block:
+		return "block"
+	case ipfs.Flip:
+		return "flip"
+	case ipfs.Snapshot:
+		return "snapshot"
+	case ipfs.Profile:
+		return "profile"
+	default:
+		return "unknown"
+	}
+}
+
+func (api *IpfsApi) GcStatus() GcStatus {
+	status := api.gc.Status()
+	result := GcStatus{
+		Enabled:     status.Enabled,
+		InProgress:  status.InProgress,
+		TrackedPins: make(map[string]int),
+		LastResult:  convertGcResult(status.LastResult),
+	}
+	if !status.NextRun.IsZero() {
+		result.NextRun = status.NextRun.Unix()
+	}
+	for dataType, count := range status.TrackedPins {
+		result.TrackedPins[convertDataType(dataType)] += count
+	}
+	return result
+}
+
+// Gc unpins expired flips, snapshots and profiles and collects garbage immediately, the request must be made with the
+// API key or JWT
+func (api *IpfsApi) Gc(ctx context.Context) (*GcResult, error) {
+	if err := authorize(ctx, "ipfsGc"); err != nil {
+		return nil, err
+	}
+	result, err := api.gc.Run()
+	if result == nil {
+		return nil, err
+	}
+	return convertGcResult(result), nil
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"github.com/idena-network/idena-go/common/bandwidth"
 	"github.com/idena-network/idena-go/ipfs"
 	"github.com/idena-network/idena-go/protocol"
 )
@@ -9,11 +10,17 @@ import (
 type NetApi struct {
 	pm        *protocol.IdenaGossipHandler
 	ipfsProxy ipfs.Proxy
+	bandwidth *bandwidth.Scheduler
 }
 
 // NewNetApi creates a new NetApi instance
-func NewNetApi(pm *protocol.IdenaGossipHandler, ipfsProxy ipfs.Proxy) *NetApi {
-	return &NetApi{pm, ipfsProxy}
+func NewNetApi(pm *protocol.IdenaGossipHandler, ipfsProxy ipfs.Proxy, bandwidth *bandwidth.Scheduler) *NetApi {
+	return &NetApi{pm, ipfsProxy, bandwidth}
+}
+
+// Bandwidth returns the traffic and caps of the node in total and by subsystems
+func (api *NetApi) Bandwidth() bandwidth.Stats {
+	return api.bandwidth.Stats()
 }
 
 func (api *NetApi) PeersCount() int {
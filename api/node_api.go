@@ -0,0 +1,41 @@
+package api
+
+import (
+	"github.com/idena-network/idena-go/core/backpressure"
+	"github.com/idena-network/idena-go/core/health"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/core/storage"
+)
+
+// NodeApi reports the node health
+type NodeApi struct {
+	health    *health.Checker
+	storage   *storage.Manager
+	busy      *backpressure.Monitor
+	snapshots *state.SnapshotManager
+}
+
+// NewNodeApi creates a new NodeApi instance
+func NewNodeApi(health *health.Checker, storage *storage.Manager, busy *backpressure.Monitor, snapshots *state.SnapshotManager) *NodeApi {
+	return &NodeApi{health, storage, busy, snapshots}
+}
+
+// Health returns the report served by /health and /ready HTTP endpoints
+func (api *NodeApi) Health() *health.Report {
+	return api.health.Report()
+}
+
+// StorageStats returns disk usage of the chain database and the ipfs repo against the configured budget
+func (api *NodeApi) StorageStats() *storage.Stats {
+	return api.storage.Stats()
+}
+
+// Backpressure returns whether bcn_sendRawTx rejects txs with retry-after errors and why
+func (api *NodeApi) Backpressure() *backpressure.Status {
+	return api.busy.Status()
+}
+
+// SnapshotStatus returns the progress of the state snapshot built in background or the result of the last one
+func (api *NodeApi) SnapshotStatus() state.SnapshotStatus {
+	return api.snapshots.Status()
+}
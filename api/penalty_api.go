@@ -0,0 +1,20 @@
+package api
+
+import (
+	"github.com/idena-network/idena-go/core/penalty"
+)
+
+// PenaltyApi offers warnings about conditions which lead to penalties of the node identity
+type PenaltyApi struct {
+	monitor *penalty.Monitor
+}
+
+// NewPenaltyApi creates a new PenaltyApi instance
+func NewPenaltyApi(monitor *penalty.Monitor) *PenaltyApi {
+	return &PenaltyApi{monitor}
+}
+
+// PenaltyWarnings returns active warnings, critical ones first
+func (api *PenaltyApi) PenaltyWarnings() []penalty.Warning {
+	return api.monitor.Warnings()
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"context"
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/core/addressbook"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/pool"
+	"github.com/idena-network/idena-go/rlp"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"math/big"
+)
+
+// PoolApi offers tools for pool operators, pool delegators are maintained by the operator
+type PoolApi struct {
+	baseApi     *BaseApi
+	pool        *pool.Manager
+	addressBook *addressbook.Book
+}
+
+// NewPoolApi creates a new PoolApi instance
+func NewPoolApi(baseApi *BaseApi, pool *pool.Manager, addressBook *addressbook.Book) *PoolApi {
+	return &PoolApi{baseApi, pool, addressBook}
+}
+
+type Delegator struct {
+	Address common.Address  `json:"address"`
+	State   string          `json:"state"`
+	Stake   decimal.Decimal `json:"stake"`
+	Balance decimal.Decimal `json:"balance"`
+	Age     uint16          `json:"age"`
+	Online  bool            `json:"online"`
+	Label   string          `json:"label,omitempty"`
+}
+
+func (api *PoolApi) Delegators() []Delegator {
+	return api.delegators(api.baseApi.getAppState())
+}
+
+func (api *PoolApi) delegators(appState *appstate.AppState) []Delegator {
+	epoch := appState.State.Epoch()
+	result := make([]Delegator, 0)
+	for _, addr := range api.pool.Delegators() {
+		identity := appState.State.GetIdentity(addr)
+		age := uint16(0)
+		if identity.Birthday > 0 {
+			age = epoch - identity.Birthday
+		}
+		result = append(result, Delegator{
+			Address: addr,
+			State:   convertIdentityState(identity.State),
+			Stake:   blockchain.ConvertToFloat(identity.Stake),
+			Balance: blockchain.ConvertToFloat(appState.State.GetBalance(addr)),
+			Age:     age,
+			Online:  getIdentityOnlineStatus(appState, addr),
+			Label:   api.addressBook.Label(addr),
+		})
+	}
+	return result
+}
+
+func (api *PoolApi) AddDelegators(addresses []common.Address) int {
+	return api.pool.AddDelegators(addresses)
+}
+
+func (api *PoolApi) RemoveDelegators(addresses []common.Address) int {
+	return api.pool.RemoveDelegators(addresses)
+}
+
+type ComposeRewardsArgs struct {
+	Amount decimal.Decimal `json:"amount"`
+	MaxFee decimal.Decimal `json:"maxFee"`
+	// Equal disables splitting proportionally to delegator stakes
+	Equal bool `json:"equal"`
+	// Send adds composed transactions to the mempool
+	Send bool `json:"send"`
+	BaseTxArgs
+}
+
+type RewardTx struct {
+	To     common.Address  `json:"to"`
+	Amount decimal.Decimal `json:"amount"`
+	Hash   common.Hash     `json:"hash"`
+	Raw    hexutil.Bytes   `json:"raw"`
+}
+
+// ComposeRewards builds signed send transactions which distribute the amount between validated delegators, the
+// rounding remainder is sent to the first delegator of the split, see pool.SplitReward
+func (api *PoolApi) ComposeRewards(ctx context.Context, args ComposeRewardsArgs) ([]RewardTx, error) {
+	return api.composeRewards(ctx, api.baseApi.getAppState(), args)
+}
+
+func (api *PoolApi) composeRewards(ctx context.Context, appState *appstate.AppState, args ComposeRewardsArgs) ([]RewardTx, error) {
+	total := blockchain.ConvertToInt(args.Amount)
+	if total == nil || total.Sign() <= 0 {
+		return nil, errors.New("amount should be positive")
+	}
+	from := api.baseApi.getCurrentCoinbase()
+
+	var recipients []common.Address
+	var weights []*big.Int
+	for _, addr := range api.pool.Delegators() {
+		identity := appState.State.GetIdentity(addr)
+		if !identity.State.NewbieOrBetter() {
+			continue
+		}
+		recipients = append(recipients, addr)
+		if args.Equal {
+			weights = append(weights, big.NewInt(1))
+		} else {
+			weights = append(weights, identity.Stake)
+		}
+	}
+	if len(recipients) == 0 {
+		return nil, errors.New("pool has no validated delegators")
+	}
+
+	epoch := args.Epoch
+	if epoch == 0 {
+		epoch = appState.State.Epoch()
+	}
+	nonce := args.Nonce
+	if nonce == 0 {
+		nonce = appState.NonceCache.GetNonce(from, epoch) + 1
+	}
+
+	var result []RewardTx
+	for i, amount := range pool.SplitReward(total, weights) {
+		if amount.Sign() == 0 {
+			continue
+		}
+		to := recipients[i]
+		// ConvertToFloat rounds to 16 decimal places, the amount is converted exactly to keep the split total
+		floatAmount := decimal.NewFromBigInt(amount, -18)
+		tx, err := api.baseApi.signTransaction(from, buildTx(appState, from, &to, types.SendTx, floatAmount,
+			args.MaxFee, decimal.Zero, nonce, epoch, nil), nil)
+		if err != nil {
+			return nil, err
+		}
+		if args.Send {
+			if _, err := api.baseApi.sendInternalTx(ctx, tx); err != nil {
+				return result, errors.Wrapf(err, "cannot send reward to %v", to.Hex())
+			}
+		}
+		data, _ := rlp.EncodeToBytes(tx)
+		result = append(result, RewardTx{
+			To:     to,
+			Amount: floatAmount,
+			Hash:   tx.Hash(),
+			Raw:    data,
+		})
+		nonce++
+	}
+	return result, nil
+}
+
+type DelegatorStateChange struct {
+	Address   common.Address `json:"address"`
+	PrevState string         `json:"prevState"`
+	State     string         `json:"state"`
+	Height    uint64         `json:"height"`
+}
+
+// NewStateChangesFilter subscribes to delegator identity state changes, changes are polled by StateChanges
+func (api *PoolApi) NewStateChangesFilter() string {
+	return api.pool.NewStateChangesFilter()
+}
+
+func (api *PoolApi) StateChanges(id string) ([]DelegatorStateChange, error) {
+	changes, err := api.pool.StateChanges(id)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]DelegatorStateChange, 0, len(changes))
+	for _, change := range changes {
+		result = append(result, DelegatorStateChange{
+			Address:   change.Address,
+			PrevState: convertIdentityState(change.PrevState),
+			State:     convertIdentityState(change.State),
+			Height:    change.Height,
+		})
+	}
+	return result, nil
+}
+
+func (api *PoolApi) RemoveStateChangesFilter(id string) bool {
+	return api.pool.RemoveStateChangesFilter(id)
+}
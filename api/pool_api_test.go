@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/core/addressbook"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/pool"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/rlp"
+	"github.com/idena-network/idena-go/secstore"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tm-db"
+	"math/big"
+	"testing"
+)
+
+func newTestPoolApi(t *testing.T) (*PoolApi, *appstate.AppState, eventbus.Bus) {
+	memdb := db.NewMemDB()
+	bus := eventbus.New()
+	appState := appstate.NewAppState(memdb, bus)
+	require.NoError(t, appState.Initialize(0))
+	secStore := secstore.NewSecStore()
+	key, _ := crypto.GenerateKey()
+	secStore.AddKey(crypto.FromECDSA(key))
+	poolManager := pool.NewManager(memdb, appState, bus)
+	book := addressbook.NewBook(memdb, appState, secStore, poolManager)
+	return NewPoolApi(NewBaseApi(nil, nil, nil, secStore), poolManager, book), appState, bus
+}
+
+func TestPoolApi_Delegators(t *testing.T) {
+	require := require.New(t)
+	api, appState, _ := newTestPoolApi(t)
+	first, second := common.Address{0x1}, common.Address{0x2}
+	appState.State.SetState(first, state.Verified)
+	appState.State.SetBalance(first, big.NewInt(5e18))
+
+	require.Equal(2, api.AddDelegators([]common.Address{first, second}))
+	require.Zero(api.AddDelegators([]common.Address{first}))
+	delegators := api.delegators(appState)
+	require.Len(delegators, 2)
+	require.Equal(first, delegators[0].Address)
+	require.Equal("Verified", delegators[0].State)
+	require.True(decimal.New(5, 0).Equal(delegators[0].Balance))
+	require.Equal(addressbook.DelegatorLabel, delegators[0].Label)
+	require.Equal("Undefined", delegators[1].State)
+
+	require.Equal(1, api.RemoveDelegators([]common.Address{first}))
+	delegators = api.delegators(appState)
+	require.Len(delegators, 1)
+	require.Equal(second, delegators[0].Address)
+}
+
+func TestPoolApi_ComposeRewards(t *testing.T) {
+	require := require.New(t)
+	api, appState, _ := newTestPoolApi(t)
+	first, second, candidate := common.Address{0x1}, common.Address{0x2}, common.Address{0x3}
+	appState.State.SetState(first, state.Verified)
+	appState.State.AddStake(first, big.NewInt(1))
+	appState.State.SetState(second, state.Human)
+	appState.State.AddStake(second, big.NewInt(2))
+	appState.State.SetState(candidate, state.Candidate)
+
+	_, err := api.composeRewards(context.Background(), appState, ComposeRewardsArgs{Amount: decimal.New(1, 0)})
+	require.Error(err)
+	api.AddDelegators([]common.Address{first, second, candidate})
+	_, err = api.composeRewards(context.Background(), appState, ComposeRewardsArgs{})
+	require.Error(err)
+
+	check := func(args ComposeRewardsArgs, amounts ...string) {
+		txs, err := api.composeRewards(context.Background(), appState, args)
+		require.NoError(err)
+		require.Len(txs, len(amounts))
+		for i, rewardTx := range txs {
+			tx := new(types.Transaction)
+			require.NoError(rlp.DecodeBytes(rewardTx.Raw, tx))
+			sender, _ := types.Sender(tx)
+			require.Equal(api.baseApi.getCurrentCoinbase(), sender)
+			require.Equal([]common.Address{first, second}[i], *tx.To)
+			require.Equal(amounts[i], tx.Amount.String())
+			require.Equal(amounts[i], rewardTx.Amount.Shift(18).String())
+			require.Equal(tx.Hash(), rewardTx.Hash)
+			require.Equal(uint32(i+1), tx.AccountNonce)
+		}
+	}
+	// the remainder of the split goes to the first delegator
+	check(ComposeRewardsArgs{Amount: decimal.New(10, -18)}, "4", "6")
+	check(ComposeRewardsArgs{Amount: decimal.New(11, -18), Equal: true}, "6", "5")
+	check(ComposeRewardsArgs{Amount: decimal.New(10, 0)}, "3333333333333333334", "6666666666666666666")
+}
+
+func TestPoolApi_StateChanges(t *testing.T) {
+	require := require.New(t)
+	api, appState, bus := newTestPoolApi(t)
+	delegator := common.Address{0x1}
+	api.AddDelegators([]common.Address{delegator})
+	id := api.NewStateChangesFilter()
+
+	newBlock := func(height uint64) {
+		bus.Publish(&events.NewBlockEvent{Block: &types.Block{Header: &types.Header{
+			ProposedHeader: &types.ProposedHeader{Height: height},
+		}}})
+	}
+	newBlock(1)
+	appState.State.SetState(delegator, state.Newbie)
+	newBlock(2)
+
+	changes, err := api.StateChanges(id)
+	require.NoError(err)
+	require.Equal([]DelegatorStateChange{{Address: delegator, PrevState: "Undefined", State: "Newbie", Height: 2}}, changes)
+
+	require.True(api.RemoveStateChangesFilter(id))
+	_, err = api.StateChanges(id)
+	require.Equal(pool.FilterNotFound, err)
+}
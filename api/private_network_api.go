@@ -0,0 +1,37 @@
+package api
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/privatenet"
+)
+
+// PrivateNetworkApi operates the ceremony coordination of private networks
+type PrivateNetworkApi struct {
+	coordinator *privatenet.Coordinator
+}
+
+// NewPrivateNetworkApi creates a new PrivateNetworkApi instance
+func NewPrivateNetworkApi(coordinator *privatenet.Coordinator) *PrivateNetworkApi {
+	return &PrivateNetworkApi{coordinator}
+}
+
+// Status returns the god address state and configured invites
+func (api *PrivateNetworkApi) Status() (privatenet.Status, error) {
+	return api.coordinator.Status()
+}
+
+// Export returns the private network config with identities of the current state, the result can be passed to
+// nodes of the new network via --privatenet
+func (api *PrivateNetworkApi) Export(network *uint32) (*config.PrivateNetworkConfig, error) {
+	var id uint32
+	if network != nil {
+		id = *network
+	}
+	return api.coordinator.Export(id)
+}
+
+// SendInvites sends god address invites to receivers without identities
+func (api *PrivateNetworkApi) SendInvites(receivers []common.Address) []privatenet.InviteResult {
+	return api.coordinator.SendInvites(receivers, true)
+}
@@ -0,0 +1,25 @@
+package api
+
+import "github.com/idena-network/idena-go/core/relay"
+
+// RelayApi serves checkpoints and snapshot manifests relayed from the other network, they are not verified by the node
+type RelayApi struct {
+	relay *relay.Relay
+}
+
+// NewRelayApi creates a new RelayApi instance
+func NewRelayApi(relay *relay.Relay) *RelayApi {
+	return &RelayApi{relay}
+}
+
+func (api *RelayApi) Checkpoints() []*relay.Checkpoint {
+	return api.relay.Checkpoints()
+}
+
+func (api *RelayApi) Manifests() []*relay.Manifest {
+	return api.relay.Manifests()
+}
+
+func (api *RelayApi) Status() *relay.Status {
+	return api.relay.Status()
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/core/signstore"
+	"sort"
+)
+
+// UnsafeApi offers overrides of node safety protections, the namespace is not exposed unless it is explicitly enabled
+type UnsafeApi struct {
+	signStore *signstore.Store
+}
+
+// NewUnsafeApi creates a new UnsafeApi instance
+func NewUnsafeApi(signStore *signstore.Store) *UnsafeApi {
+	return &UnsafeApi{signStore}
+}
+
+type SignedMessage struct {
+	Attempt uint32      `json:"attempt"`
+	Step    uint8       `json:"step"`
+	Hash    common.Hash `json:"hash"`
+}
+
+// SignedMessages returns hashes of consensus messages signed at the round by attempts of the round, step 0 is the
+// block proposal
+func (api *UnsafeApi) SignedMessages(round uint64) []SignedMessage {
+	result := make([]SignedMessage, 0)
+	for attempt, steps := range api.signStore.Signed(round) {
+		for step, hash := range steps {
+			result = append(result, SignedMessage{
+				Attempt: attempt,
+				Step:    step,
+				Hash:    hash,
+			})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Attempt != result[j].Attempt {
+			return result[i].Attempt < result[j].Attempt
+		}
+		return result[i].Step < result[j].Step
+	})
+	return result
+}
+
+// ResetSignedMessages allows the node to sign conflicting consensus messages at the round, it may lead to double signing
+func (api *UnsafeApi) ResetSignedMessages(round uint64) int {
+	return api.signStore.Reset(round)
+}
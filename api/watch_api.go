@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/core/watchlist"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/rpc"
+	"github.com/shopspring/decimal"
+	"math"
+)
+
+const (
+	watchEventsBufferSize      = 100
+	maxBalanceHistoryCount     = 1000
+	defaultBalanceHistoryCount = 100
+)
+
+// WatchApi offers txs and balance changes of watched addresses without running the indexer
+type WatchApi struct {
+	manager *watchlist.Manager
+	bus     eventbus.Bus
+}
+
+// NewWatchApi creates a new WatchApi instance
+func NewWatchApi(manager *watchlist.Manager, bus eventbus.Bus) *WatchApi {
+	return &WatchApi{manager, bus}
+}
+
+func (api *WatchApi) Add(address common.Address) bool {
+	return api.manager.Add(address)
+}
+
+func (api *WatchApi) Remove(address common.Address) bool {
+	return api.manager.Remove(address)
+}
+
+func (api *WatchApi) List() []common.Address {
+	return api.manager.List()
+}
+
+// Events creates a subscription to txs and balance changes of watched addresses
+func (api *WatchApi) Events(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+	watchEvents := make(chan *watchlist.Event, watchEventsBufferSize)
+	sub := api.bus.Subscribe(events.WatchEventID, func(e eventbus.Event) {
+		select {
+		case watchEvents <- watchlist.NewEvent(e.(*events.WatchEvent)):
+		default:
+		}
+	})
+	go func() {
+		defer api.bus.Unsubscribe(sub)
+		for {
+			select {
+			case e := <-watchEvents:
+				notifier.Notify(rpcSub.ID, e)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// RecentEvents returns buffered events with seq greater than fromSeq, it is an alternative to the subscription
+// for HTTP clients
+func (api *WatchApi) RecentEvents(fromSeq uint64) []*watchlist.Event {
+	result := api.manager.Recent(fromSeq)
+	if result == nil {
+		return []*watchlist.Event{}
+	}
+	return result
+}
+
+type BalanceHistoryArgs struct {
+	Address common.Address `json:"address"`
+	Count   int            `json:"count"`
+	// Before is the height entries are returned below, the next page is requested with the returned next height
+	Before *uint64 `json:"before"`
+}
+
+type BalanceHistoryEntry struct {
+	Height    uint64          `json:"height"`
+	Timestamp uint64          `json:"timestamp"`
+	Balance   decimal.Decimal `json:"balance"`
+	Stake     decimal.Decimal `json:"stake"`
+	Nonce     uint32          `json:"nonce"`
+}
+
+type BalanceHistory struct {
+	Entries []BalanceHistoryEntry `json:"entries"`
+	Next    *uint64               `json:"next"`
+}
+
+// BalanceHistory returns balance, stake and nonce changes of the watched address in descending order of height
+func (api *WatchApi) BalanceHistory(args BalanceHistoryArgs) (BalanceHistory, error) {
+	count := args.Count
+	if count <= 0 {
+		count = defaultBalanceHistoryCount
+	}
+	if count > maxBalanceHistoryCount {
+		count = maxBalanceHistoryCount
+	}
+	before := uint64(math.MaxUint64)
+	if args.Before != nil {
+		before = *args.Before
+	}
+	entries, err := api.manager.BalanceHistory(args.Address, before, count)
+	if err != nil {
+		return BalanceHistory{}, err
+	}
+	result := BalanceHistory{Entries: []BalanceHistoryEntry{}}
+	for _, entry := range entries {
+		result.Entries = append(result.Entries, BalanceHistoryEntry{
+			Height:    entry.Height,
+			Timestamp: entry.Timestamp,
+			Balance:   blockchain.ConvertToFloat(entry.Balance),
+			Stake:     blockchain.ConvertToFloat(entry.Stake),
+			Nonce:     entry.Nonce,
+		})
+	}
+	if len(entries) == count {
+		next := entries[len(entries)-1].Height
+		result.Next = &next
+	}
+	return result, nil
+}
@@ -0,0 +1,31 @@
+package blockchain
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+)
+
+// AncientStore serves bodies and certificates of canonical blocks moved out of the datadir
+type AncientStore interface {
+	// Contains reports if the canonical block of the height has been moved to the store
+	Contains(height uint64) bool
+	// ReadBlock returns the encoded body and the certificate of the block, the error is returned if the block of the
+	// height in the store has another hash
+	ReadBlock(height uint64, hash common.Hash) (body []byte, cert *types.BlockCert, err error)
+}
+
+func (chain *Blockchain) ProvideAncientStore(store AncientStore) {
+	chain.ancient = store
+}
+
+func (chain *Blockchain) readAncientBlock(header *types.Header) ([]byte, *types.BlockCert, bool) {
+	if chain.ancient == nil || !chain.ancient.Contains(header.Height()) {
+		return nil, nil, false
+	}
+	body, cert, err := chain.ancient.ReadBlock(header.Height(), header.Hash())
+	if err != nil {
+		chain.log.Warn("Cannot read ancient block", "height", header.Height(), "err", err)
+		return nil, nil, false
+	}
+	return body, cert, true
+}
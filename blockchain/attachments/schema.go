@@ -0,0 +1,217 @@
+package attachments
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/rlp"
+	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
+	"sync"
+)
+
+// DecodeOptions is the context which isn't stored in payloads
+type DecodeOptions struct {
+	// FlipsCount is the number of flips the answers are given for, answers are returned as raw bits if it isn't set
+	FlipsCount uint
+}
+
+// PayloadSchema decodes payloads of the tx type into JSON friendly values
+type PayloadSchema struct {
+	Name   string
+	Decode func(payload []byte, opts DecodeOptions) (interface{}, error)
+}
+
+var (
+	schemasMutex sync.RWMutex
+	schemas      = make(map[types.TxType]PayloadSchema)
+)
+
+// RegisterPayloadSchema adds or replaces the schema of the tx type
+func RegisterPayloadSchema(txType types.TxType, schema PayloadSchema) {
+	schemasMutex.Lock()
+	defer schemasMutex.Unlock()
+	schemas[txType] = schema
+}
+
+// DecodePayload decodes the payload by the schema registered for the tx type
+func DecodePayload(txType types.TxType, payload []byte, opts DecodeOptions) (string, interface{}, error) {
+	schemasMutex.RLock()
+	schema, ok := schemas[txType]
+	schemasMutex.RUnlock()
+	if !ok {
+		return "", nil, errors.Errorf("unknown payload schema of tx type %v", txType)
+	}
+	value, err := schema.Decode(payload, opts)
+	if err != nil {
+		return schema.Name, nil, errors.Wrapf(err, "cannot decode %v payload", schema.Name)
+	}
+	return schema.Name, value, nil
+}
+
+type DecodedAnswer struct {
+	Index      uint   `json:"index"`
+	Answer     string `json:"answer"`
+	WrongWords bool   `json:"wrongWords"`
+}
+
+type DecodedAnswers struct {
+	Bits    hexutil.Bytes   `json:"bits"`
+	Answers []DecodedAnswer `json:"answers,omitempty"`
+}
+
+type DecodedShortAnswers struct {
+	DecodedAnswers
+	Proof hexutil.Bytes `json:"proof"`
+	Key   hexutil.Bytes `json:"key"`
+	Salt  hexutil.Bytes `json:"salt"`
+}
+
+type DecodedFlipSubmit struct {
+	Cid  string `json:"cid"`
+	Pair uint8  `json:"pair"`
+}
+
+type DecodedAnswersHash struct {
+	Hash common.Hash `json:"hash"`
+}
+
+type DecodedEvidence struct {
+	// Candidates are indexes of candidates which have been seen by the sender during the short session
+	Candidates []uint32 `json:"candidates"`
+}
+
+type DecodedOnlineStatus struct {
+	Online bool `json:"online"`
+}
+
+type DecodedBurn struct {
+	Key string `json:"key"`
+}
+
+type DecodedProfile struct {
+	Hash hexutil.Bytes `json:"hash"`
+	Cid  string        `json:"cid,omitempty"`
+}
+
+type DecodedDeleteFlip struct {
+	Cid string `json:"cid"`
+}
+
+var answerNames = map[types.Answer]string{
+	types.None:          "none",
+	types.Left:          "left",
+	types.Right:         "right",
+	types.Inappropriate: "inappropriate",
+}
+
+// maxAnswersFlipsCount limits decoded answers, long sessions of any realistic network have far fewer flips
+const maxAnswersFlipsCount = 10000
+
+func decodeAnswers(bits []byte, flipsCount uint) (DecodedAnswers, error) {
+	result := DecodedAnswers{Bits: bits}
+	if flipsCount == 0 {
+		return result, nil
+	}
+	if flipsCount > maxAnswersFlipsCount {
+		return result, errors.Errorf("flips count %v exceeds the max %v", flipsCount, maxAnswersFlipsCount)
+	}
+	answers := types.NewAnswersFromBits(flipsCount, bits)
+	for i := uint(0); i < flipsCount; i++ {
+		answer, wrongWords := answers.Answer(i)
+		result.Answers = append(result.Answers, DecodedAnswer{Index: i, Answer: answerNames[answer], WrongWords: wrongWords})
+	}
+	return result, nil
+}
+
+func cidString(data []byte) (string, error) {
+	c, err := cid.Cast(data)
+	if err != nil {
+		return "", err
+	}
+	return c.String(), nil
+}
+
+func init() {
+	RegisterPayloadSchema(types.SubmitFlipTx, PayloadSchema{Name: "flipSubmit", Decode: func(payload []byte, _ DecodeOptions) (interface{}, error) {
+		var attachment FlipSubmitAttachment
+		if err := rlp.DecodeBytes(payload, &attachment); err != nil {
+			return nil, err
+		}
+		c, err := cidString(attachment.Cid)
+		if err != nil {
+			return nil, err
+		}
+		return &DecodedFlipSubmit{Cid: c, Pair: attachment.Pair}, nil
+	}})
+	RegisterPayloadSchema(types.SubmitAnswersHashTx, PayloadSchema{Name: "answersHash", Decode: func(payload []byte, _ DecodeOptions) (interface{}, error) {
+		if len(payload) != common.HashLength {
+			return nil, errors.New("invalid hash length")
+		}
+		return &DecodedAnswersHash{Hash: common.BytesToHash(payload)}, nil
+	}})
+	RegisterPayloadSchema(types.SubmitShortAnswersTx, PayloadSchema{Name: "shortAnswers", Decode: func(payload []byte, opts DecodeOptions) (interface{}, error) {
+		var attachment ShortAnswerAttachment
+		if err := rlp.DecodeBytes(payload, &attachment); err != nil {
+			return nil, err
+		}
+		answers, err := decodeAnswers(attachment.Answers, opts.FlipsCount)
+		if err != nil {
+			return nil, err
+		}
+		return &DecodedShortAnswers{
+			DecodedAnswers: answers,
+			Proof:          attachment.Proof,
+			Key:            attachment.Key,
+			Salt:           attachment.Salt,
+		}, nil
+	}})
+	RegisterPayloadSchema(types.SubmitLongAnswersTx, PayloadSchema{Name: "longAnswers", Decode: func(payload []byte, opts DecodeOptions) (interface{}, error) {
+		answers, err := decodeAnswers(payload, opts.FlipsCount)
+		if err != nil {
+			return nil, err
+		}
+		return &answers, nil
+	}})
+	RegisterPayloadSchema(types.EvidenceTx, PayloadSchema{Name: "evidence", Decode: func(payload []byte, _ DecodeOptions) (interface{}, error) {
+		if len(payload) == 0 {
+			return nil, errors.New("empty bitmap")
+		}
+		bitmap := common.NewBitmap(uint32(len(payload) * 8))
+		bitmap.Read(payload)
+		return &DecodedEvidence{Candidates: bitmap.ToArray()}, nil
+	}})
+	RegisterPayloadSchema(types.OnlineStatusTx, PayloadSchema{Name: "onlineStatus", Decode: func(payload []byte, _ DecodeOptions) (interface{}, error) {
+		var attachment OnlineStatusAttachment
+		if err := rlp.DecodeBytes(payload, &attachment); err != nil {
+			return nil, err
+		}
+		return &DecodedOnlineStatus{Online: attachment.Online}, nil
+	}})
+	RegisterPayloadSchema(types.BurnTx, PayloadSchema{Name: "burn", Decode: func(payload []byte, _ DecodeOptions) (interface{}, error) {
+		var attachment BurnAttachment
+		if err := rlp.DecodeBytes(payload, &attachment); err != nil {
+			return nil, err
+		}
+		return &DecodedBurn{Key: attachment.Key}, nil
+	}})
+	RegisterPayloadSchema(types.ChangeProfileTx, PayloadSchema{Name: "changeProfile", Decode: func(payload []byte, _ DecodeOptions) (interface{}, error) {
+		var attachment ChangeProfileAttachment
+		if err := rlp.DecodeBytes(payload, &attachment); err != nil {
+			return nil, err
+		}
+		c, _ := cidString(attachment.Hash)
+		return &DecodedProfile{Hash: attachment.Hash, Cid: c}, nil
+	}})
+	RegisterPayloadSchema(types.DeleteFlipTx, PayloadSchema{Name: "deleteFlip", Decode: func(payload []byte, _ DecodeOptions) (interface{}, error) {
+		var attachment DeleteFlipAttachment
+		if err := rlp.DecodeBytes(payload, &attachment); err != nil {
+			return nil, err
+		}
+		c, err := cidString(attachment.Cid)
+		if err != nil {
+			return nil, err
+		}
+		return &DecodedDeleteFlip{Cid: c}, nil
+	}})
+}
@@ -0,0 +1,60 @@
+package attachments
+
+import (
+	"bytes"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDecodePayload(t *testing.T) {
+	hash, _ := multihash.Sum([]byte("flip"), multihash.SHA2_256, -1)
+	c := cid.NewCidV1(cid.Raw, hash)
+
+	name, value, err := DecodePayload(types.SubmitFlipTx, CreateFlipSubmitAttachment(c.Bytes(), 2), DecodeOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "flipSubmit", name)
+	require.Equal(t, &DecodedFlipSubmit{Cid: c.String(), Pair: 2}, value)
+
+	_, value, err = DecodePayload(types.OnlineStatusTx, CreateOnlineStatusAttachment(true), DecodeOptions{})
+	require.NoError(t, err)
+	require.Equal(t, &DecodedOnlineStatus{Online: true}, value)
+
+	answers := types.NewAnswers(3)
+	answers.Left(0)
+	answers.Inappropriate(2)
+	answers.WrongWords(2)
+	_, value, err = DecodePayload(types.SubmitLongAnswersTx, answers.Bytes(), DecodeOptions{FlipsCount: 3})
+	require.NoError(t, err)
+	require.Equal(t, []DecodedAnswer{
+		{Index: 0, Answer: "left"},
+		{Index: 1, Answer: "none"},
+		{Index: 2, Answer: "inappropriate", WrongWords: true},
+	}, value.(*DecodedAnswers).Answers)
+
+	_, value, err = DecodePayload(types.SubmitLongAnswersTx, answers.Bytes(), DecodeOptions{})
+	require.NoError(t, err)
+	require.Empty(t, value.(*DecodedAnswers).Answers)
+
+	_, _, err = DecodePayload(types.SubmitLongAnswersTx, answers.Bytes(), DecodeOptions{FlipsCount: 1 << 62})
+	require.Error(t, err)
+
+	bitmap := common.NewBitmap(100)
+	bitmap.Add(3)
+	bitmap.Add(42)
+	buf := new(bytes.Buffer)
+	bitmap.WriteTo(buf)
+	_, value, err = DecodePayload(types.EvidenceTx, buf.Bytes(), DecodeOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []uint32{3, 42}, value.(*DecodedEvidence).Candidates)
+
+	name, _, err = DecodePayload(types.BurnTx, []byte{0x1, 0x2}, DecodeOptions{})
+	require.Error(t, err)
+	require.Equal(t, "burn", name)
+
+	_, _, err = DecodePayload(types.SendTx, []byte{0x1}, DecodeOptions{})
+	require.Error(t, err)
+}
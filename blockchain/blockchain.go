@@ -19,7 +19,6 @@ import (
 	"github.com/idena-network/idena-go/core/state/snapshot"
 	"github.com/idena-network/idena-go/core/validators"
 	"github.com/idena-network/idena-go/crypto"
-	"github.com/idena-network/idena-go/crypto/vrf/p256"
 	"github.com/idena-network/idena-go/database"
 	"github.com/idena-network/idena-go/events"
 	"github.com/idena-network/idena-go/ipfs"
@@ -34,6 +33,7 @@ import (
 	math2 "math"
 	"math/big"
 	"sort"
+	"sync/atomic"
 	"time"
 )
 
@@ -46,13 +46,14 @@ const (
 	ProposerRole            uint8 = 0x1
 	EmptyBlockTimeIncrement       = time.Second * 20
 	MaxFutureBlockOffset          = time.Minute * 2
-	MinBlockDelay                 = time.Second * 10
+	MinBlockDelay                 = config.MinBlockDelay
 )
 
 var (
 	MaxHash             *big.Float
 	ParentHashIsInvalid = errors.New("parentHash is invalid")
 	BlockInsertionErr   = errors.New("can't insert block")
+	CheckpointMismatch  = errors.New("block conflicts with checkpoint")
 )
 
 type Blockchain struct {
@@ -74,6 +75,15 @@ type Blockchain struct {
 	bus             eventbus.Bus
 	applyNewEpochFn func(height uint64, appState *appstate.AppState, collector collector.StatsCollector) (int, *types.ValidationAuthors, bool)
 	isSyncing       bool
+	checkpoints     map[uint64]config.Checkpoint
+	// checkpointHeights are heights of checkpoints in ascending order
+	checkpointHeights []uint64
+	// matchedCheckpoint is the highest checkpoint height which header has been matched since the start
+	matchedCheckpoint uint64
+	vrf               *vrfVerifier
+	// timeOffset is the time travel of the dev network in nanoseconds
+	timeOffset int64
+	ancient    AncientStore
 }
 
 func init() {
@@ -88,18 +98,40 @@ func init() {
 
 func NewBlockchain(config *config.Config, db dbm.DB, txpool *mempool.TxPool, appState *appstate.AppState,
 	ipfs ipfs.Proxy, secStore *secstore.SecStore, bus eventbus.Bus, offlineDetector *OfflineDetector) *Blockchain {
+	checkpoints, checkpointHeights := indexCheckpoints(config.Sync)
 	return &Blockchain{
-		repo:            database.NewRepo(db),
-		config:          config,
-		log:             log.New(),
-		txpool:          txpool,
-		appState:        appState,
-		ipfs:            ipfs,
-		timing:          NewTiming(config.Validation),
-		bus:             bus,
-		secStore:        secStore,
-		offlineDetector: offlineDetector,
+		repo:              database.NewRepo(db),
+		config:            config,
+		log:               log.New(),
+		txpool:            txpool,
+		appState:          appState,
+		ipfs:              ipfs,
+		timing:            NewTiming(config.Validation),
+		bus:               bus,
+		secStore:          secStore,
+		offlineDetector:   offlineDetector,
+		checkpoints:       checkpoints,
+		checkpointHeights: checkpointHeights,
+		vrf:               newVrfVerifier(),
+	}
+}
+
+func indexCheckpoints(syncConfig *config.SyncConfig) (map[uint64]config.Checkpoint, []uint64) {
+	checkpoints := make(map[uint64]config.Checkpoint)
+	var heights []uint64
+	if syncConfig == nil {
+		return checkpoints, heights
+	}
+	for _, checkpoint := range syncConfig.Checkpoints {
+		if _, ok := checkpoints[checkpoint.Height]; !ok {
+			heights = append(heights, checkpoint.Height)
+		}
+		checkpoints[checkpoint.Height] = checkpoint
 	}
+	sort.Slice(heights, func(i, j int) bool {
+		return heights[i] < heights[j]
+	})
+	return checkpoints, heights
 }
 
 func (chain *Blockchain) ProvideApplyNewEpochFunc(fn func(height uint64, appState *appstate.AppState, collector collector.StatsCollector) (int, *types.ValidationAuthors, bool)) {
@@ -145,7 +177,11 @@ func (chain *Blockchain) InitializeChain() error {
 			return err
 		}
 	}
+	chain.restoreTimeOffset()
 	chain.PreliminaryHead = chain.repo.ReadPreliminaryHead()
+	if cnt := chain.repo.IndexSavedTxTypes(); cnt > 0 {
+		log.Info("Own transactions indexed by type", "cnt", cnt)
+	}
 	log.Info("Chain initialized", "block", chain.Head.Hash().Hex(), "height", chain.Head.Height())
 	log.Info("Coinbase address", "addr", chain.coinBaseAddress.Hex())
 	return nil
@@ -233,7 +269,7 @@ func (chain *Blockchain) GenerateGenesis(network types.Network) (*types.Block, e
 		},
 	}, Body: &types.Body{}}
 
-	if err := chain.insertBlock(block, new(state.IdentityStateDiff)); err != nil {
+	if err := chain.insertBlock(block, new(state.IdentityStateDiff), nil); err != nil {
 		return nil, err
 	}
 	chain.genesis = block.Header
@@ -248,7 +284,7 @@ func (chain *Blockchain) generateEmptyBlock(checkState *appstate.AppState, prevB
 			EmptyBlockHeader: &types.EmptyBlockHeader{
 				ParentHash: prevBlock.Hash(),
 				Height:     prevBlock.Height() + 1,
-				Time:       new(big.Int).SetInt64(prevTimestamp.Add(EmptyBlockTimeIncrement).Unix()),
+				Time:       new(big.Int).SetInt64(prevTimestamp.Add(chain.emptyBlockTimeIncrement()).Unix()),
 			},
 		},
 		Body: &types.Body{},
@@ -280,11 +316,11 @@ func (chain *Blockchain) AddBlock(block *types.Block, checkState *appstate.AppSt
 	}
 	statsCollector.EnableCollecting()
 	defer statsCollector.CompleteCollecting()
-	diff, err := chain.processBlock(block, statsCollector)
+	diff, receipts, err := chain.processBlock(block, statsCollector)
 	if err != nil {
 		return err
 	}
-	if err := chain.insertBlock(block, diff); err != nil {
+	if err := chain.insertBlock(block, diff, receipts); err != nil {
 		return err
 	}
 	if !chain.isSyncing {
@@ -299,30 +335,30 @@ func (chain *Blockchain) AddBlock(block *types.Block, checkState *appstate.AppSt
 }
 
 func (chain *Blockchain) processBlock(block *types.Block,
-	statsCollector collector.StatsCollector) (diff *state.IdentityStateDiff, err error) {
+	statsCollector collector.StatsCollector) (diff *state.IdentityStateDiff, receipts []*types.TxReceipt, err error) {
 
 	var root, identityRoot common.Hash
 	if block.IsEmpty() {
 		root, identityRoot, diff = chain.applyEmptyBlockOnState(chain.appState, block, statsCollector)
 	} else {
-		if root, identityRoot, diff, err = chain.applyBlockAndTxsOnState(chain.appState, block, chain.Head, statsCollector); err != nil {
+		if root, identityRoot, diff, receipts, err = chain.applyBlockAndTxsOnState(chain.appState, block, chain.Head, statsCollector); err != nil {
 			chain.appState.Reset()
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
 	if root != block.Root() || identityRoot != block.IdentityRoot() {
 		chain.appState.Reset()
-		return nil, errors.Errorf("Process block. Invalid block roots. Expected=%x & %x, actual=%x & %x", root, identityRoot, block.Root(), block.IdentityRoot())
+		return nil, nil, errors.Errorf("Process block. Invalid block roots. Expected=%x & %x, actual=%x & %x", root, identityRoot, block.Root(), block.IdentityRoot())
 	}
 
 	if err := chain.appState.Commit(block); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	chain.log.Trace("Applied block", "root", fmt.Sprintf("0x%x", block.Root()), "height", block.Height())
 
-	return diff, nil
+	return diff, receipts, nil
 }
 
 func (chain *Blockchain) applyBlockAndTxsOnState(
@@ -330,14 +366,14 @@ func (chain *Blockchain) applyBlockAndTxsOnState(
 	block *types.Block,
 	prevBlock *types.Header,
 	statsCollector collector.StatsCollector,
-) (root common.Hash, identityRoot common.Hash, diff *state.IdentityStateDiff, err error) {
+) (root common.Hash, identityRoot common.Hash, diff *state.IdentityStateDiff, receipts []*types.TxReceipt, err error) {
 	var totalFee, totalTips *big.Int
-	if totalFee, totalTips, err = chain.processTxs(appState, block, statsCollector); err != nil {
+	if totalFee, totalTips, receipts, err = chain.processTxs(appState, block, statsCollector); err != nil {
 		return
 	}
 
 	root, identityRoot, diff = chain.applyBlockOnState(appState, block, prevBlock, totalFee, totalTips, statsCollector)
-	return root, identityRoot, diff, nil
+	return root, identityRoot, diff, receipts, nil
 }
 
 func (chain *Blockchain) applyBlockOnState(appState *appstate.AppState, block *types.Block, prevBlock *types.Header, totalFee, totalTips *big.Int, statsCollector collector.StatsCollector) (root common.Hash, identityRoot common.Hash, diff *state.IdentityStateDiff) {
@@ -683,24 +719,27 @@ func (chain *Blockchain) rewardFinalCommittee(appState *appstate.AppState, block
 }
 
 func (chain *Blockchain) processTxs(appState *appstate.AppState, block *types.Block,
-	statsCollector collector.StatsCollector) (totalFee *big.Int, totalTips *big.Int, err error) {
+	statsCollector collector.StatsCollector) (totalFee *big.Int, totalTips *big.Int, receipts []*types.TxReceipt, err error) {
 	totalFee = new(big.Int)
 	totalTips = new(big.Int)
 	fee := new(big.Int)
+	receipts = make([]*types.TxReceipt, 0, len(block.Body.Transactions))
 	for i := 0; i < len(block.Body.Transactions); i++ {
 		tx := block.Body.Transactions[i]
 		if err := validation.ValidateTx(appState, tx, chain.config.Consensus.MinFeePerByte, validation.InBlockTx); err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
+		changes := captureReceiptChanges(appState.State, receiptAddresses(tx))
 		if fee, err = chain.ApplyTxOnState(appState, tx, statsCollector); err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
+		receipts = append(receipts, chain.buildReceipt(appState.State, tx, fee, changes))
 
 		totalFee.Add(totalFee, fee)
 		totalTips.Add(totalTips, tx.TipsOrZero())
 	}
 
-	return totalFee, totalTips, nil
+	return totalFee, totalTips, receipts, nil
 }
 
 func (chain *Blockchain) ApplyTxOnState(appState *appstate.AppState, tx *types.Transaction,
@@ -964,6 +1003,12 @@ func (chain *Blockchain) GetProposerSortition() (bool, common.Hash, []byte) {
 }
 
 func (chain *Blockchain) ProposeBlock() *types.BlockProposal {
+	block := chain.BuildProposedBlock()
+	return &types.BlockProposal{Block: block, Signature: chain.secStore.Sign(block.Hash().Bytes())}
+}
+
+// BuildProposedBlock builds the block to propose without signing the proposal
+func (chain *Blockchain) BuildProposedBlock() *types.Block {
 	head := chain.Head
 
 	txs := chain.txpool.BuildBlockTransactions()
@@ -977,8 +1022,8 @@ func (chain *Blockchain) ProposeBlock() *types.BlockProposal {
 	cid, _ = chain.ipfs.Cid(body.Bytes())
 
 	prevBlockTime := time.Unix(chain.Head.Time().Int64(), 0)
-	newBlockTime := prevBlockTime.Add(MinBlockDelay).Unix()
-	if localTime := time.Now().UTC().Unix(); localTime > newBlockTime {
+	newBlockTime := prevBlockTime.Add(chain.minBlockDelay()).Unix()
+	if localTime := chain.Now().Unix(); localTime > newBlockTime {
 		newBlockTime = localTime
 	}
 	var cidBytes []byte
@@ -1015,9 +1060,7 @@ func (chain *Blockchain) ProposeBlock() *types.BlockProposal {
 
 	block.Header.ProposedHeader.Root, block.Header.ProposedHeader.IdentityRoot, _ = chain.applyBlockOnState(checkState, block, chain.Head, totalFee, totalTips, nil)
 
-	proposal := &types.BlockProposal{Block: block, Signature: chain.secStore.Sign(block.Hash().Bytes())}
-
-	return proposal
+	return block
 }
 
 func calculateTxBloom(block *types.Block) []byte {
@@ -1121,7 +1164,7 @@ func (chain *Blockchain) insertHeader(header *types.Header) {
 	chain.repo.WriteCanonicalHash(header.Height(), header.Hash())
 }
 
-func (chain *Blockchain) insertBlock(block *types.Block, diff *state.IdentityStateDiff) error {
+func (chain *Blockchain) insertBlock(block *types.Block, diff *state.IdentityStateDiff, receipts []*types.TxReceipt) error {
 	_, err := chain.ipfs.Add(block.Body.Bytes(), chain.ipfs.ShouldPin(ipfs.Block))
 	if err != nil {
 		return errors.Wrap(BlockInsertionErr, err.Error())
@@ -1129,6 +1172,7 @@ func (chain *Blockchain) insertBlock(block *types.Block, diff *state.IdentitySta
 	chain.insertHeader(block.Header)
 	chain.WriteIdentityStateDiff(block.Height(), diff)
 	chain.WriteTxIndex(block.Hash(), block.Body.Transactions)
+	chain.writeTxReceipts(block.Hash(), receipts)
 	chain.HandleTxs(block.Header, block.Body.Transactions)
 	chain.setCurrentHead(block.Header)
 	return nil
@@ -1189,6 +1233,7 @@ func (chain *Blockchain) validateBlock(checkState *appstate.AppState, block *typ
 	}
 
 	var txs = types.Transactions(block.Body.Transactions)
+	types.RecoverSenders(txs)
 
 	if types.DeriveSha(txs) != block.Header.ProposedHeader.TxHash {
 		return errors.New("txHash is invalid")
@@ -1200,7 +1245,7 @@ func (chain *Blockchain) validateBlock(checkState *appstate.AppState, block *typ
 
 	var totalFee, totalTips *big.Int
 	var err error
-	if totalFee, totalTips, err = chain.processTxs(checkState, block, nil); err != nil {
+	if totalFee, totalTips, _, err = chain.processTxs(checkState, block, nil); err != nil {
 		return err
 	}
 
@@ -1296,15 +1341,15 @@ func validateBlockParentHash(block *types.Header, prevBlock *types.Header) error
 	return nil
 }
 
-func validateBlockTimestamp(block *types.Header, prevBlock *types.Header) error {
+func validateBlockTimestamp(block *types.Header, prevBlock *types.Header, now time.Time, minDelay time.Duration) error {
 	blockTime := time.Unix(block.Time().Int64(), 0)
 
-	if blockTime.Sub(time.Now().UTC()) > MaxFutureBlockOffset {
+	if blockTime.Sub(now) > MaxFutureBlockOffset {
 		return errors.New("block from future")
 	}
 	prevBlockTime := time.Unix(prevBlock.Time().Int64(), 0)
 
-	if blockTime.Sub(prevBlockTime) < MinBlockDelay {
+	if blockTime.Sub(prevBlockTime) < minDelay {
 		return errors.Errorf("block is too close to previous one, prev: %v, current: %v", prevBlockTime.Unix(), blockTime.Unix())
 	}
 
@@ -1312,18 +1357,60 @@ func validateBlockTimestamp(block *types.Header, prevBlock *types.Header) error
 }
 
 func (chain *Blockchain) ValidateProposerProof(proof []byte, hash common.Hash, pubKeyData []byte) error {
-	pubKey, err := crypto.UnmarshalPubkey(pubKeyData)
+	h, err := chain.vrf.verify(pubKeyData, chain.getProposerData(), proof)
 	if err != nil {
 		return err
 	}
-	verifier, err := p256.NewVRFVerifier(pubKey)
-	if err != nil {
-		return err
+	return chain.validateProposer(h, hash, pubKeyData)
+}
+
+type ProposerProof struct {
+	Proof  []byte
+	Hash   common.Hash
+	PubKey []byte
+}
+
+// ValidateProposerProofs validates proofs of the next round proposers verifying VRF proofs in parallel,
+// errors are in the order of proofs
+func (chain *Blockchain) ValidateProposerProofs(proofs []ProposerProof) []error {
+	seed := chain.getProposerData()
+	vrfProofs := make([]VrfProof, len(proofs))
+	for i, proof := range proofs {
+		vrfProofs[i] = VrfProof{PubKey: proof.PubKey, Seed: seed, Proof: proof.Proof}
 	}
+	result := make([]error, len(proofs))
+	for i, vrfResult := range chain.vrf.verifyBatch(vrfProofs) {
+		if vrfResult.Err != nil {
+			result[i] = vrfResult.Err
+			continue
+		}
+		result[i] = chain.validateProposer(vrfResult.Hash, proofs[i].Hash, proofs[i].PubKey)
+	}
+	return result
+}
 
-	h, err := verifier.ProofToHash(chain.getProposerData(), proof)
+// PreverifySeedProofs verifies seed proofs of proposed headers following the head in parallel, so their validation
+// takes the cached results
+func (chain *Blockchain) PreverifySeedProofs(headers []*types.Header) {
+	seed := getSeedData(chain.Head)
+	var proofs []VrfProof
+	for _, header := range headers {
+		if header == nil || header.ProposedHeader == nil || header.ParentHash() != chain.Head.Hash() {
+			continue
+		}
+		proofs = append(proofs, VrfProof{
+			PubKey: header.ProposedHeader.ProposerPubKey,
+			Seed:   seed,
+			Proof:  header.ProposedHeader.SeedProof,
+		})
+	}
+	if len(proofs) > 1 {
+		chain.vrf.verifyBatch(proofs)
+	}
+}
 
-	if h != hash {
+func (chain *Blockchain) validateProposer(vrfHash [32]byte, hash common.Hash, pubKeyData []byte) error {
+	if vrfHash != hash {
 		return errors.New("Hashes are not equal")
 	}
 
@@ -1335,7 +1422,10 @@ func (chain *Blockchain) ValidateProposerProof(proof []byte, hash common.Hash, p
 		return errors.New("Proposer is invalid")
 	}
 
-	proposerAddr := crypto.PubkeyToAddress(*pubKey)
+	proposerAddr, err := crypto.PubKeyBytesToAddress(pubKeyData)
+	if err != nil {
+		return err
+	}
 
 	if !checkIfProposer(proposerAddr, chain.appState) {
 		return errors.New("Proposer is not identity")
@@ -1355,6 +1445,11 @@ func (chain *Blockchain) WriteCertificate(hash common.Hash, cert *types.BlockCer
 	if !persistent {
 		chain.repo.WriteWeakCertificate(hash)
 	}
+	if chain.config.Blockchain.CertIndex {
+		if header := chain.repo.ReadBlockHeader(hash); header != nil {
+			chain.repo.WriteCertificateIndex(header.Height(), cert)
+		}
+	}
 }
 
 func (chain *Blockchain) GetBlock(hash common.Hash) *types.Block {
@@ -1368,6 +1463,14 @@ func (chain *Blockchain) GetBlock(hash common.Hash) *types.Block {
 			Body:   &types.Body{},
 		}
 	}
+	if bodyBytes, _, ok := chain.readAncientBlock(header); ok {
+		body := &types.Body{}
+		body.FromBytes(bodyBytes)
+		return &types.Block{
+			Header: header,
+			Body:   body,
+		}
+	}
 	if bodyBytes, err := chain.ipfs.Get(header.ProposedHeader.IpfsHash); err != nil {
 		return nil
 	} else {
@@ -1444,25 +1547,32 @@ func (chain *Blockchain) GetTx(hash common.Hash) (*types.Transaction, *types.Tra
 }
 
 func (chain *Blockchain) GetCommitteeSize(vc *validators.ValidatorsCache, final bool) int {
-	var cnt = vc.OnlineSize()
-	percent := chain.config.Consensus.CommitteePercent
+	return CommitteeSize(chain.config.Consensus, vc.OnlineSize(), final)
+}
+
+func (chain *Blockchain) GetCommitteeVotesThreshold(vc *validators.ValidatorsCache, final bool) int {
+	return CommitteeVotesThreshold(chain.config.Consensus, vc.OnlineSize(), final)
+}
+
+// CommitteeSize returns the size of the step committee for the online validators count
+func CommitteeSize(cfg *config.ConsensusConf, cnt int, final bool) int {
+	percent := cfg.CommitteePercent
 	if final {
-		percent = chain.config.Consensus.FinalCommitteePercent
+		percent = cfg.FinalCommitteePercent
 	}
 	if cnt <= 8 {
 		return cnt
 	}
 
 	size := int(float64(cnt) * percent)
-	if size > chain.config.Consensus.MaxCommitteeSize {
-		return chain.config.Consensus.MaxCommitteeSize
+	if size > cfg.MaxCommitteeSize {
+		return cfg.MaxCommitteeSize
 	}
 	return size
 }
 
-func (chain *Blockchain) GetCommitteeVotesThreshold(vc *validators.ValidatorsCache, final bool) int {
-
-	var cnt = vc.OnlineSize()
+// CommitteeVotesThreshold returns the number of step votes required for agreement
+func CommitteeVotesThreshold(cfg *config.ConsensusConf, cnt int, final bool) int {
 	switch cnt {
 	case 0, 1:
 		return 1
@@ -1475,8 +1585,8 @@ func (chain *Blockchain) GetCommitteeVotesThreshold(vc *validators.ValidatorsCac
 	case 8:
 		return 5
 	}
-	size := chain.GetCommitteeSize(vc, final)
-	return int(float64(size) * chain.config.Consensus.AgreementThreshold)
+	size := CommitteeSize(cfg, cnt, final)
+	return int(float64(size) * cfg.AgreementThreshold)
 }
 
 func (chain *Blockchain) Genesis() common.Hash {
@@ -1484,6 +1594,9 @@ func (chain *Blockchain) Genesis() common.Hash {
 }
 
 func (chain *Blockchain) ValidateSubChain(startHeight uint64, blocks []types.BlockBundle) error {
+	if checkpoint := chain.lastPassedCheckpoint(); checkpoint > startHeight {
+		return errors.Wrapf(CheckpointMismatch, "fork starts at %v before checkpoint %v", startHeight, checkpoint)
+	}
 	checkState, err := chain.appState.ForCheckWithOverwrite(startHeight)
 	if err != nil {
 		return err
@@ -1561,6 +1674,21 @@ func (chain *Blockchain) EnsureIntegrity() error {
 	return nil
 }
 
+// WriteCleanShutdownMarker marks the current head as flushed, the next start may skip recovery checks
+func (chain *Blockchain) WriteCleanShutdownMarker() {
+	chain.repo.WriteCleanShutdown(chain.Head.Hash())
+}
+
+// ConsumeCleanShutdownMarker reports whether the node was stopped gracefully at the current head and removes the marker
+func (chain *Blockchain) ConsumeCleanShutdownMarker() bool {
+	marker := chain.repo.ReadCleanShutdown()
+	if marker == (common.Hash{}) {
+		return false
+	}
+	chain.repo.RemoveCleanShutdown()
+	return chain.Head != nil && marker == chain.Head.Hash()
+}
+
 func (chain *Blockchain) StartSync() {
 	chain.isSyncing = true
 	chain.txpool.StartSync()
@@ -1599,7 +1727,11 @@ func (chain *Blockchain) ValidateHeader(header, prevBlock *types.Header) error {
 		return err
 	}
 
-	if err := validateBlockTimestamp(header, prevBlock); err != nil {
+	if err := validateBlockTimestamp(header, prevBlock, chain.Now(), chain.minBlockDelay()); err != nil {
+		return err
+	}
+
+	if err := chain.validateCheckpoint(header); err != nil {
 		return err
 	}
 
@@ -1613,17 +1745,13 @@ func (chain *Blockchain) ValidateHeader(header, prevBlock *types.Header) error {
 		return errors.New("invalid coinbase")
 	}
 
-	var seedData = getSeedData(prevBlock)
-	pubKey, err := crypto.UnmarshalPubkey(header.ProposedHeader.ProposerPubKey)
-	if err != nil {
-		return err
-	}
-	verifier, err := p256.NewVRFVerifier(pubKey)
-	if err != nil {
-		return err
+	// headers below the matched checkpoint are anchored by the checkpoint hash chain, seed proof check can be skipped
+	if header.Height() < chain.lastMatchedCheckpoint() {
+		return nil
 	}
 
-	hash, err := verifier.ProofToHash(seedData, header.ProposedHeader.SeedProof)
+	var seedData = getSeedData(prevBlock)
+	hash, err := chain.vrf.verify(header.ProposedHeader.ProposerPubKey, seedData, header.ProposedHeader.SeedProof)
 	if err != nil {
 		return err
 	}
@@ -1635,8 +1763,69 @@ func (chain *Blockchain) ValidateHeader(header, prevBlock *types.Header) error {
 	return nil
 }
 
+func (chain *Blockchain) validateCheckpoint(header *types.Header) error {
+	checkpoint, ok := chain.checkpoints[header.Height()]
+	if !ok {
+		return nil
+	}
+	if header.Hash() != checkpoint.Hash || header.IdentityRoot() != checkpoint.IdentityRoot {
+		return errors.Wrapf(CheckpointMismatch, "height %v, expected hash %v, identity root %v", header.Height(), checkpoint.Hash.Hex(), checkpoint.IdentityRoot.Hex())
+	}
+	for {
+		matched := atomic.LoadUint64(&chain.matchedCheckpoint)
+		if header.Height() <= matched || atomic.CompareAndSwapUint64(&chain.matchedCheckpoint, matched, header.Height()) {
+			return nil
+		}
+	}
+}
+
+// lastMatchedCheckpoint returns the highest checkpoint height which header has been matched since the start
+func (chain *Blockchain) lastMatchedCheckpoint() uint64 {
+	return atomic.LoadUint64(&chain.matchedCheckpoint)
+}
+
+// lastPassedCheckpoint returns the highest checkpoint height which is not above the current head
+func (chain *Blockchain) lastPassedCheckpoint() uint64 {
+	if chain.Head == nil {
+		return 0
+	}
+	i := sort.Search(len(chain.checkpointHeights), func(i int) bool {
+		return chain.checkpointHeights[i] > chain.Head.Height()
+	})
+	if i == 0 {
+		return 0
+	}
+	return chain.checkpointHeights[i-1]
+}
+
 func (chain *Blockchain) GetCertificate(hash common.Hash) *types.BlockCert {
-	return chain.repo.ReadCertificate(hash)
+	if cert := chain.repo.ReadCertificate(hash); cert != nil || chain.ancient == nil {
+		return cert
+	}
+	if header := chain.repo.ReadBlockHeader(hash); header != nil {
+		_, cert, _ := chain.readAncientBlock(header)
+		return cert
+	}
+	return nil
+}
+
+// GetCertificateByHeight returns the certificate of the canonical block from the index or from the certificates storage
+func (chain *Blockchain) GetCertificateByHeight(height uint64) (*types.Header, *types.BlockCert) {
+	header := chain.GetBlockHeaderByHeight(height)
+	if header == nil {
+		return nil, nil
+	}
+	if chain.config.Blockchain.CertIndex {
+		// voted hash doesn't match if the indexed certificate belongs to the block replaced by a fork
+		if cert := chain.repo.ReadCertificateIndex(height); cert != nil && cert.VotedHash == header.Hash() {
+			return header, cert
+		}
+	}
+	if cert := chain.repo.ReadCertificate(header.Hash()); cert != nil {
+		return header, cert
+	}
+	_, cert, _ := chain.readAncientBlock(header)
+	return header, cert
 }
 
 func (chain *Blockchain) GetIdentityDiff(height uint64) *state.IdentityStateDiff {
@@ -1724,8 +1913,42 @@ func (chain *Blockchain) handleOwnDeleteFlipTx(sender common.Address, tx *types.
 	})
 }
 
-func (chain *Blockchain) ReadTxs(address common.Address, count int, token []byte) ([]*types.SavedTransaction, []byte) {
-	return chain.repo.GetSavedTxs(address, count, token)
+// TxsFilter narrows own transactions, all bounds are inclusive and zero value means no bound
+type TxsFilter struct {
+	Types      []types.TxType
+	FromHeight uint64
+	ToHeight   uint64
+	FromTime   uint64
+	ToTime     uint64
+}
+
+func (chain *Blockchain) ReadTxs(address common.Address, count int, token []byte, filter *TxsFilter) ([]*types.SavedTransaction, []byte) {
+	savedTxFilter := &database.SavedTxFilter{}
+	if filter != nil {
+		savedTxFilter.Types = filter.Types
+		savedTxFilter.FromTime = filter.FromTime
+		savedTxFilter.ToTime = filter.ToTime
+		// block timestamps strictly increase, so the height range is the time range of its edge blocks
+		if filter.FromHeight > 0 {
+			header := chain.GetBlockHeaderByHeight(filter.FromHeight)
+			if header == nil {
+				return nil, nil
+			}
+			if t := header.Time().Uint64(); t > savedTxFilter.FromTime {
+				savedTxFilter.FromTime = t
+			}
+		}
+		if filter.ToHeight > 0 && filter.ToHeight < chain.Head.Height() {
+			header := chain.GetBlockHeaderByHeight(filter.ToHeight)
+			if header == nil {
+				return nil, nil
+			}
+			if t := header.Time().Uint64(); savedTxFilter.ToTime == 0 || t < savedTxFilter.ToTime {
+				savedTxFilter.ToTime = t
+			}
+		}
+	}
+	return chain.repo.GetSavedTxs(address, count, token, savedTxFilter)
 }
 
 func (chain *Blockchain) ReadTotalBurntCoins() []*types.BurntCoins {
@@ -384,7 +384,7 @@ func TestBlockchain_SaveTxs(t *testing.T) {
 		chain.HandleTxs(header, []*types.Transaction{item.tx})
 	}
 
-	data, token := chain.ReadTxs(addr, 5, nil)
+	data, token := chain.ReadTxs(addr, 5, nil, nil)
 
 	require.Equal(5, len(data))
 	require.Equal(uint32(2), data[0].Tx.AccountNonce)
@@ -395,7 +395,7 @@ func TestBlockchain_SaveTxs(t *testing.T) {
 	require.Equal(uint64(456), data[4].Timestamp)
 	require.NotNil(token)
 
-	data, token = chain.ReadTxs(addr, 4, token)
+	data, token = chain.ReadTxs(addr, 4, token, nil)
 
 	require.Equal(4, len(data))
 	require.Equal(uint32(10), data[0].Tx.AccountNonce)
@@ -404,7 +404,7 @@ func TestBlockchain_SaveTxs(t *testing.T) {
 	require.Equal(uint32(7), data[3].Tx.AccountNonce)
 	require.NotNil(token)
 
-	data, token = chain.ReadTxs(addr, 10, token)
+	data, token = chain.ReadTxs(addr, 10, token, nil)
 
 	require.Equal(6, len(data))
 	require.Equal(uint32(6), data[0].Tx.AccountNonce)
@@ -870,3 +870,41 @@ func Test_setNewIdentitiesAttributes(t *testing.T) {
 	require.Equal(uint8(1), s.State.GetInvites(common.Address{0x9}))
 	require.Equal(uint8(1), s.State.GetInvites(common.Address{0xa}))
 }
+
+func TestBlockchain_matchedCheckpoint(t *testing.T) {
+	require := require.New(t)
+	header := func(height uint64) *types.Header {
+		return &types.Header{
+			EmptyBlockHeader: &types.EmptyBlockHeader{Height: height, IdentityRoot: common.Hash{0x1}},
+		}
+	}
+	checkpoints, heights := indexCheckpoints(&config.SyncConfig{
+		Checkpoints: []config.Checkpoint{
+			{Height: 20, Hash: header(20).Hash(), IdentityRoot: common.Hash{0x1}},
+			{Height: 10, Hash: header(10).Hash(), IdentityRoot: common.Hash{0x1}},
+		},
+	})
+	require.Equal([]uint64{10, 20}, heights)
+	chain := &Blockchain{checkpoints: checkpoints, checkpointHeights: heights}
+
+	// the top checkpoint isn't trusted until it is reached
+	require.Zero(chain.lastMatchedCheckpoint())
+	require.NoError(chain.validateCheckpoint(header(5)))
+	require.Zero(chain.lastMatchedCheckpoint())
+
+	require.Error(chain.validateCheckpoint(&types.Header{
+		EmptyBlockHeader: &types.EmptyBlockHeader{Height: 10, IdentityRoot: common.Hash{0x2}},
+	}))
+	require.Zero(chain.lastMatchedCheckpoint())
+
+	require.NoError(chain.validateCheckpoint(header(10)))
+	require.Equal(uint64(10), chain.lastMatchedCheckpoint())
+	require.NoError(chain.validateCheckpoint(header(20)))
+	require.NoError(chain.validateCheckpoint(header(10)))
+	require.Equal(uint64(20), chain.lastMatchedCheckpoint())
+
+	chain.Head = header(15)
+	require.Equal(uint64(10), chain.lastPassedCheckpoint())
+	chain.Head = header(9)
+	require.Zero(chain.lastPassedCheckpoint())
+}
@@ -0,0 +1,68 @@
+package blockchain
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/config"
+	"github.com/pkg/errors"
+	"sync/atomic"
+	"time"
+)
+
+const Devnet types.Network = config.DevNetwork
+
+// Now returns the chain clock, it is shifted forward by time travel in the dev network
+func (chain *Blockchain) Now() time.Time {
+	return time.Now().UTC().Add(chain.TimeOffset())
+}
+
+func (chain *Blockchain) TimeOffset() time.Duration {
+	return time.Duration(atomic.LoadInt64(&chain.timeOffset))
+}
+
+// AdjustTime moves the chain clock forward, it is used to force epoch transitions in the dev network
+func (chain *Blockchain) AdjustTime(d time.Duration) error {
+	if chain.config.Network != Devnet {
+		return errors.New("time travel is available in the dev network only")
+	}
+	if d <= 0 {
+		return errors.New("time can be moved forward only")
+	}
+	atomic.AddInt64(&chain.timeOffset, int64(d))
+	return nil
+}
+
+func (chain *Blockchain) minBlockDelay() time.Duration {
+	if chain.config.Network == Devnet {
+		return chain.config.Consensus.MinBlockDistance
+	}
+	if chain.config.PrivateNetwork != nil {
+		// private networks may run shorter rounds configured by the chain config, timestamps have second precision
+		delay := chain.config.Consensus.ProposalDelay()
+		if delay > MinBlockDelay {
+			delay = MinBlockDelay
+		}
+		if delay < time.Second {
+			delay = time.Second
+		}
+		return delay
+	}
+	return MinBlockDelay
+}
+
+// emptyBlockTimeIncrement keeps the time of empty blocks of private networks with short rounds close to the clock
+func (chain *Blockchain) emptyBlockTimeIncrement() time.Duration {
+	if chain.config.PrivateNetwork != nil && chain.config.Consensus.MinBlockDistance < EmptyBlockTimeIncrement {
+		return chain.config.Consensus.MinBlockDistance.Truncate(time.Second)
+	}
+	return EmptyBlockTimeIncrement
+}
+
+// restoreTimeOffset keeps the chain clock monotonic after restart of the dev node which has travelled in time
+func (chain *Blockchain) restoreTimeOffset() {
+	if chain.config.Network != Devnet || chain.Head == nil {
+		return
+	}
+	if offset := time.Unix(chain.Head.Time().Int64(), 0).Sub(time.Now().UTC()); offset > 0 {
+		atomic.StoreInt64(&chain.timeOffset, int64(offset))
+	}
+}
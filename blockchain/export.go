@@ -0,0 +1,301 @@
+package blockchain
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/ipfs"
+	"github.com/idena-network/idena-go/rlp"
+	"github.com/idena-network/idena-go/stats/collector"
+	"github.com/pkg/errors"
+	"hash"
+	"hash/crc32"
+	"io"
+)
+
+// Chain export format, all integers are big endian:
+//
+//	file     = preamble record* end trailer
+//	preamble = magic "IDNACHN" | version uint8 | network uint32 | from uint64 | to uint64 | crc32c(preceding bytes) uint32
+//	record   = length uint32 (> 0) | payload | crc32c(payload) uint32
+//	payload  = rlp([header, body bytes, certificate or empty list])
+//	end      = uint32 0
+//	trailer  = blocks count uint64 | sha256 of all payloads
+//
+// Records are canonical blocks of heights from..to in ascending order.
+const (
+	ExportVersion      = 1
+	exportMagic        = "IDNACHN"
+	maxExportRecordLen = 64 * 1024 * 1024
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+type exportedBlock struct {
+	Header *types.Header
+	Body   []byte
+	Cert   *types.BlockCert `rlp:"nil"`
+}
+
+type ExportPreamble struct {
+	Version uint8
+	Network uint32
+	From    uint64
+	To      uint64
+}
+
+type ImportReport struct {
+	Preamble ExportPreamble
+	// Restored is the number of blocks of the local chain which headers, bodies, certificates and indexes have been
+	// rewritten from the file
+	Restored uint64
+	// Applied is the number of blocks added on top of the local head
+	Applied uint64
+	Head    uint64
+}
+
+// ExportChain writes canonical blocks of heights from..to with certificates to w, to is the head height if it's 0.
+// It returns the number of exported blocks.
+func (chain *Blockchain) ExportChain(w io.Writer, from, to uint64) (uint64, error) {
+	if from == 0 {
+		from = 1
+	}
+	if to == 0 || to > chain.Head.Height() {
+		to = chain.Head.Height()
+	}
+	if from > to {
+		return 0, errors.Errorf("invalid range %v..%v", from, to)
+	}
+	buf := bufio.NewWriter(w)
+	preamble := make([]byte, 0, len(exportMagic)+25)
+	preamble = append(preamble, exportMagic...)
+	preamble = append(preamble, ExportVersion)
+	preamble = appendUint32(preamble, chain.config.Network)
+	preamble = appendUint64(preamble, from)
+	preamble = appendUint64(preamble, to)
+	preamble = appendUint32(preamble, crc32.Checksum(preamble, crcTable))
+	if _, err := buf.Write(preamble); err != nil {
+		return 0, err
+	}
+
+	digest := sha256.New()
+	var count uint64
+	for height := from; height <= to; height++ {
+		block := chain.GetBlockByHeight(height)
+		if block == nil {
+			return count, errors.Errorf("block %v is not found", height)
+		}
+		_, cert := chain.GetCertificateByHeight(height)
+		payload, err := rlp.EncodeToBytes(&exportedBlock{
+			Header: block.Header,
+			Body:   block.Body.Bytes(),
+			Cert:   cert,
+		})
+		if err != nil {
+			return count, err
+		}
+		record := appendUint32(make([]byte, 0, len(payload)+8), uint32(len(payload)))
+		record = append(record, payload...)
+		record = appendUint32(record, crc32.Checksum(payload, crcTable))
+		if _, err := buf.Write(record); err != nil {
+			return count, err
+		}
+		digest.Write(payload)
+		count++
+	}
+	trailer := appendUint32(nil, 0)
+	trailer = appendUint64(trailer, count)
+	trailer = append(trailer, digest.Sum(nil)...)
+	if _, err := buf.Write(trailer); err != nil {
+		return count, err
+	}
+	return count, buf.Flush()
+}
+
+// ImportChain reads blocks exported by ExportChain. Blocks of the local canonical chain restore missing or corrupted
+// headers, canonical hashes, bodies, certificates and tx indexes, blocks above the head are validated and added to
+// the chain. Blocks are checked one by one, so blocks read before a corrupted record stay imported.
+func (chain *Blockchain) ImportChain(r io.Reader) (*ImportReport, error) {
+	reader := bufio.NewReader(r)
+	report := &ImportReport{}
+	preamble, err := readExportPreamble(reader)
+	if err != nil {
+		return report, err
+	}
+	report.Preamble = *preamble
+	if preamble.Network != chain.config.Network {
+		return report, errors.Errorf("file belongs to network %v", preamble.Network)
+	}
+
+	syncing := chain.isSyncing
+	chain.isSyncing = true
+	defer func() {
+		chain.isSyncing = syncing
+		report.Head = chain.Head.Height()
+	}()
+
+	digest := sha256.New()
+	var count uint64
+	var prev *types.Header
+	for {
+		payload, err := readExportRecord(reader, digest)
+		if err != nil {
+			return report, errors.Wrapf(err, "record %v", count)
+		}
+		if payload == nil {
+			break
+		}
+		count++
+		var exported exportedBlock
+		if err := rlp.DecodeBytes(payload, &exported); err != nil {
+			return report, errors.Wrapf(err, "record %v", count)
+		}
+		block, err := chain.checkExportedBlock(&exported, prev)
+		if err != nil {
+			return report, err
+		}
+		if err := chain.importBlock(block, exported.Cert, report); err != nil {
+			return report, errors.Wrapf(err, "block %v", block.Height())
+		}
+		prev = block.Header
+	}
+
+	var trailer [8 + sha256.Size]byte
+	if _, err := io.ReadFull(reader, trailer[:]); err != nil {
+		return report, errors.Wrap(err, "cannot read trailer")
+	}
+	if binary.BigEndian.Uint64(trailer[:8]) != count || !bytes.Equal(trailer[8:], digest.Sum(nil)) {
+		return report, errors.New("file checksum mismatch")
+	}
+	return report, nil
+}
+
+func (chain *Blockchain) checkExportedBlock(exported *exportedBlock, prev *types.Header) (*types.Block, error) {
+	header := exported.Header
+	if header == nil || (header.EmptyBlockHeader == nil) == (header.ProposedHeader == nil) {
+		return nil, errors.New("invalid header")
+	}
+	if prev != nil && (header.ParentHash() != prev.Hash() || header.Height() != prev.Height()+1) {
+		return nil, errors.Errorf("block %v doesn't follow the previous block", header.Height())
+	}
+	if exported.Cert != nil && exported.Cert.VotedHash != header.Hash() {
+		return nil, errors.Errorf("certificate of block %v votes for another hash", header.Height())
+	}
+	body := &types.Body{}
+	body.FromBytes(exported.Body)
+	if header.ProposedHeader != nil {
+		if types.DeriveSha(types.Transactions(body.Transactions)) != header.ProposedHeader.TxHash {
+			return nil, errors.Errorf("body of block %v doesn't match tx hash", header.Height())
+		}
+		cid, err := chain.ipfs.Cid(body.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		// empty bodies are proposed without ipfs hash
+		expected := header.ProposedHeader.IpfsHash
+		if len(expected) == 0 {
+			expected = ipfs.EmptyCid.Bytes()
+		}
+		if !bytes.Equal(cid.Bytes(), expected) {
+			return nil, errors.Errorf("body of block %v doesn't match ipfs hash", header.Height())
+		}
+	}
+	return &types.Block{Header: header, Body: body}, nil
+}
+
+func (chain *Blockchain) importBlock(block *types.Block, cert *types.BlockCert, report *ImportReport) error {
+	height := block.Height()
+	if height > chain.Head.Height()+1 {
+		return errors.Errorf("block doesn't connect to the head %v", chain.Head.Height())
+	}
+	if height == chain.Head.Height()+1 {
+		if err := chain.AddBlock(block, nil, collector.NewStatsCollector()); err != nil {
+			return err
+		}
+		if cert != nil {
+			chain.WriteCertificate(block.Hash(), cert, true)
+		}
+		report.Applied++
+		return nil
+	}
+	if local := chain.repo.ReadCanonicalHash(height); local != block.Hash() && chain.repo.ReadBlockHeader(local) != nil {
+		return errors.Errorf("block conflicts with the local block %v", local.Hex())
+	}
+	if !block.IsEmpty() {
+		if _, err := chain.ipfs.Add(block.Body.Bytes(), chain.ipfs.ShouldPin(ipfs.Block)); err != nil {
+			return err
+		}
+	}
+	chain.repo.WriteBlockHeader(block.Header)
+	chain.repo.WriteCanonicalHash(height, block.Hash())
+	chain.WriteTxIndex(block.Hash(), block.Body.Transactions)
+	if cert != nil && chain.repo.ReadCertificate(block.Hash()) == nil {
+		chain.WriteCertificate(block.Hash(), cert, true)
+	}
+	report.Restored++
+	return nil
+}
+
+func readExportPreamble(r io.Reader) (*ExportPreamble, error) {
+	data := make([]byte, len(exportMagic)+25)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, errors.Wrap(err, "cannot read preamble")
+	}
+	if string(data[:len(exportMagic)]) != exportMagic {
+		return nil, errors.New("not a chain export file")
+	}
+	body := data[:len(data)-4]
+	if crc32.Checksum(body, crcTable) != binary.BigEndian.Uint32(data[len(data)-4:]) {
+		return nil, errors.New("preamble checksum mismatch")
+	}
+	body = body[len(exportMagic):]
+	preamble := &ExportPreamble{
+		Version: body[0],
+		Network: binary.BigEndian.Uint32(body[1:5]),
+		From:    binary.BigEndian.Uint64(body[5:13]),
+		To:      binary.BigEndian.Uint64(body[13:21]),
+	}
+	if preamble.Version != ExportVersion {
+		return nil, errors.Errorf("unsupported export version %v", preamble.Version)
+	}
+	return preamble, nil
+}
+
+// readExportRecord returns the verified payload of the next record or nil at the end of records
+func readExportRecord(r io.Reader, digest hash.Hash) ([]byte, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(length[:])
+	if size == 0 {
+		return nil, nil
+	}
+	if size > maxExportRecordLen {
+		return nil, errors.Errorf("record length %v exceeds limit", size)
+	}
+	data := make([]byte, size+4)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	payload := data[:size]
+	if crc32.Checksum(payload, crcTable) != binary.BigEndian.Uint32(data[size:]) {
+		return nil, errors.New("record checksum mismatch")
+	}
+	digest.Write(payload)
+	return payload, nil
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	return append(b, buf[:]...)
+}
@@ -0,0 +1,59 @@
+package blockchain
+
+import (
+	"bytes"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestBlockchain_ExportImportChain(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	chain, _ := NewCustomTestBlockchain(0, 0, key)
+	empty, _ := chain.Copy()
+	chain.GenerateBlocks(10).GenerateEmptyBlocks(5).GenerateBlocks(5)
+
+	buf := new(bytes.Buffer)
+	count, err := chain.ExportChain(buf, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, chain.Head.Height(), count)
+	data := buf.Bytes()
+
+	report, err := empty.ImportChain(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), report.Restored)
+	require.Equal(t, count-1, report.Applied)
+	require.Equal(t, chain.Head.Hash(), empty.Head.Hash())
+	_, cert := empty.GetCertificateByHeight(10)
+	require.NotNil(t, cert)
+
+	empty.repo.WriteCanonicalHash(7, common.Hash{0x1})
+	empty.repo.RemoveCertificate(empty.GetBlockHeaderByHeight(8).Hash())
+	report, err = empty.ImportChain(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, count, report.Restored)
+	require.Zero(t, report.Applied)
+	verified, err := empty.VerifyIntegrity(true)
+	require.NoError(t, err)
+	require.True(t, verified.Ok())
+	_, cert = empty.GetCertificateByHeight(8)
+	require.NotNil(t, cert)
+
+	part := new(bytes.Buffer)
+	count, err = chain.ExportChain(part, 5, 8)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), count)
+	report, err = empty.ImportChain(part)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), report.Preamble.From)
+	require.Equal(t, uint64(4), report.Restored)
+
+	corrupted := append([]byte{}, data...)
+	corrupted[len(corrupted)/2] ^= 0xff
+	_, err = empty.ImportChain(bytes.NewReader(corrupted))
+	require.Error(t, err)
+
+	_, err = empty.ImportChain(bytes.NewReader(data[:len(data)-1]))
+	require.Error(t, err)
+}
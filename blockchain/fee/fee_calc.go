@@ -14,43 +14,98 @@ const (
 	submitFlipTxSize = 116
 )
 
+// TxFee describes how the fee of the tx type is metered
+type TxFee struct {
+	// Multiplier is applied to the network fee per byte, 0 - the tx is free
+	Multiplier int64
+	// ExtraSize is added to the tx size
+	ExtraSize int
+	// Note describes payload dependent rules
+	Note string
+	// multiplier overrides Multiplier depending on the tx payload
+	multiplier func(tx *types.Transaction) int64
+}
+
+func (f TxFee) multiplierFor(tx *types.Transaction) int64 {
+	if f.multiplier != nil {
+		return f.multiplier(tx)
+	}
+	return f.Multiplier
+}
+
+// Schedule is the fee metering table by tx type, types which are not listed are metered with DefaultTxFee
+type Schedule map[uint16]TxFee
+
+var (
+	DefaultTxFee = TxFee{Multiplier: 1}
+
+	free = TxFee{Multiplier: 0}
+
+	// CurrentSchedule is the fee schedule of the current consensus rules
+	CurrentSchedule = Schedule{
+		types.SubmitFlipTx:         free,
+		types.SubmitAnswersHashTx:  free,
+		types.SubmitShortAnswersTx: free,
+		types.SubmitLongAnswersTx:  free,
+		types.EvidenceTx:           free,
+		types.ActivationTx:         free,
+		types.InviteTx:             free,
+		types.OnlineStatusTx: {
+			Multiplier: 2,
+			Note:       "free when going offline",
+			multiplier: func(tx *types.Transaction) int64 {
+				attachment := attachments.ParseOnlineStatusAttachment(tx)
+				if attachment != nil && attachment.Online {
+					return 2
+				}
+				return 0
+			},
+		},
+		types.DeleteFlipTx: {
+			Multiplier: 1,
+			ExtraSize:  common.MaxFlipSize + submitFlipTxSize,
+			Note:       "metered as the size of the deleted flip",
+		},
+	}
+)
+
+func (s Schedule) Get(txType uint16) TxFee {
+	if f, ok := s[txType]; ok {
+		return f
+	}
+	return DefaultTxFee
+}
+
 func CalculateFee(networkSize int, feePerByte *big.Int, tx *types.Transaction) *big.Int {
-	txFeePerByte := getFeePerByteForTx(networkSize, feePerByte, tx)
+	return CurrentSchedule.CalculateFee(networkSize, feePerByte, tx)
+}
+
+func (s Schedule) CalculateFee(networkSize int, feePerByte *big.Int, tx *types.Transaction) *big.Int {
+	txFeePerByte := s.getFeePerByteForTx(networkSize, feePerByte, tx)
 	if txFeePerByte.Sign() == 0 {
 		return big.NewInt(0)
 	}
-	size := getTxSizeForFee(tx)
+	size := s.getTxSizeForFee(tx)
 	return new(big.Int).Mul(txFeePerByte, big.NewInt(int64(size)))
 }
 
-func getFeePerByteForTx(networkSize int, feePerByte *big.Int, tx *types.Transaction) *big.Int {
+func (s Schedule) getFeePerByteForTx(networkSize int, feePerByte *big.Int, tx *types.Transaction) *big.Int {
 	if networkSize == 0 || feePerByte == nil {
 		return big.NewInt(0)
 	}
-	if tx.Type == types.SubmitFlipTx || tx.Type == types.SubmitAnswersHashTx || tx.Type == types.SubmitShortAnswersTx ||
-		tx.Type == types.SubmitLongAnswersTx || tx.Type == types.EvidenceTx || tx.Type == types.ActivationTx ||
-		tx.Type == types.InviteTx {
-		return big.NewInt(0)
-	}
-	if tx.Type == types.OnlineStatusTx {
-		attachment := attachments.ParseOnlineStatusAttachment(tx)
-		if attachment != nil && attachment.Online {
-			return new(big.Int).Mul(big.NewInt(2), feePerByte)
-		}
+	multiplier := s.Get(tx.Type).multiplierFor(tx)
+	if multiplier == 0 {
 		return big.NewInt(0)
 	}
-	return feePerByte
+	return new(big.Int).Mul(big.NewInt(multiplier), feePerByte)
 }
 
-func getTxSizeForFee(tx *types.Transaction) int {
+func (s Schedule) getTxSizeForFee(tx *types.Transaction) int {
 	size := tx.Size()
 	if tx.Signature == nil {
 		size += SignatureAdditionalSize
 	}
-	if tx.Type == types.DeleteFlipTx {
-		size += common.MaxFlipSize + submitFlipTxSize
-	}
-	return size
+	return size + s.Get(tx.Type).ExtraSize
 }
 
 func CalculateCost(networkSize int, feePerByte *big.Int, tx *types.Transaction) *big.Int {
@@ -1,6 +1,7 @@
 package fee
 
 import (
+	"github.com/idena-network/idena-go/blockchain/attachments"
 	"github.com/idena-network/idena-go/blockchain/types"
 	"github.com/idena-network/idena-go/common"
 	"github.com/idena-network/idena-go/crypto"
@@ -57,3 +58,22 @@ func TestCalculateCostForInvitation(t *testing.T) {
 
 	require.Equal(t, 0, tx.AmountOrZero().Cmp(CalculateCost(networkSize, new(big.Int).Div(common.DnaBase, big.NewInt(100)), tx)))
 }
+
+func TestSchedule_CalculateFee(t *testing.T) {
+	feePerByte := big.NewInt(10)
+
+	online := &types.Transaction{Type: types.OnlineStatusTx, Payload: attachments.CreateOnlineStatusAttachment(true)}
+	offline := &types.Transaction{Type: types.OnlineStatusTx, Payload: attachments.CreateOnlineStatusAttachment(false)}
+	size := int64(online.Size() + SignatureAdditionalSize)
+	require.Equal(t, big.NewInt(2*10*size), CurrentSchedule.CalculateFee(1, feePerByte, online))
+	require.Zero(t, CurrentSchedule.CalculateFee(1, feePerByte, offline).Sign())
+
+	deleteFlip := &types.Transaction{Type: types.DeleteFlipTx}
+	size = int64(deleteFlip.Size() + SignatureAdditionalSize + common.MaxFlipSize + submitFlipTxSize)
+	require.Equal(t, big.NewInt(10*size), CurrentSchedule.CalculateFee(1, feePerByte, deleteFlip))
+
+	custom := Schedule{types.SendTx: {Multiplier: 3}}
+	send := &types.Transaction{Type: types.SendTx}
+	require.Equal(t, big.NewInt(3*10*int64(send.Size()+SignatureAdditionalSize)), custom.CalculateFee(1, feePerByte, send))
+	require.Equal(t, DefaultTxFee, custom.Get(types.BurnTx))
+}
@@ -0,0 +1,215 @@
+package blockchain
+
+import (
+	"fmt"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/pkg/errors"
+)
+
+type IntegrityIssueKind byte
+
+const (
+	CanonicalHashIssue IntegrityIssueKind = iota
+	HeaderIssue
+	BodyIssue
+	TxIndexIssue
+	PreliminaryHeadIssue
+	StateIssue
+)
+
+func (k IntegrityIssueKind) String() string {
+	switch k {
+	case CanonicalHashIssue:
+		return "canonical hash"
+	case HeaderIssue:
+		return "header"
+	case BodyIssue:
+		return "body"
+	case TxIndexIssue:
+		return "tx index"
+	case PreliminaryHeadIssue:
+		return "preliminary head"
+	case StateIssue:
+		return "state"
+	default:
+		return "unknown"
+	}
+}
+
+type IntegrityIssue struct {
+	Kind   IntegrityIssueKind
+	Height uint64
+	Hash   common.Hash
+	Reason string
+	// txs are kept to re-derive tx indexes without fetching the body again
+	txs types.Transactions
+}
+
+func (i *IntegrityIssue) String() string {
+	return fmt.Sprintf("[%v] height=%v hash=%v: %v", i.Kind, i.Height, i.Hash.Hex(), i.Reason)
+}
+
+func (i *IntegrityIssue) Repairable() bool {
+	return i.Kind != HeaderIssue && i.Kind != BodyIssue
+}
+
+type IntegrityReport struct {
+	Head          uint64
+	Lowest        uint64
+	CheckedBlocks uint64
+	CheckedTxs    uint64
+	Issues        []*IntegrityIssue
+}
+
+func (r *IntegrityReport) Ok() bool {
+	return len(r.Issues) == 0
+}
+
+func (r *IntegrityReport) addIssue(kind IntegrityIssueKind, header *types.Header, reason string) *IntegrityIssue {
+	issue := &IntegrityIssue{
+		Kind:   kind,
+		Height: header.Height(),
+		Hash:   header.Hash(),
+		Reason: reason,
+	}
+	r.Issues = append(r.Issues, issue)
+	return issue
+}
+
+// VerifyIntegrity walks the header chain from the head down to the lowest stored header and checks canonical hashes,
+// parent links, state roots and, if checkBodies is set, block bodies and tx indexes.
+func (chain *Blockchain) VerifyIntegrity(checkBodies bool) (*IntegrityReport, error) {
+	head := chain.repo.ReadHead()
+	if head == nil {
+		return nil, errors.New("head is not found")
+	}
+	if checkBodies && chain.ipfs == nil {
+		return nil, errors.New("ipfs is required to check block bodies")
+	}
+	report := &IntegrityReport{
+		Head: head.Height(),
+	}
+
+	chain.verifyState(head, report)
+	chain.verifyPreliminaryHead(head, report)
+
+	header := head
+	for {
+		report.CheckedBlocks++
+		report.Lowest = header.Height()
+		if hash := chain.repo.ReadCanonicalHash(header.Height()); hash != header.Hash() {
+			report.addIssue(CanonicalHashIssue, header, fmt.Sprintf("canonical hash %v doesn't match header chain", hash.Hex()))
+		}
+		if checkBodies {
+			chain.verifyBody(header, report)
+		}
+		if header.Height() <= 1 {
+			break
+		}
+		parent := chain.repo.ReadBlockHeader(header.ParentHash())
+		if parent == nil {
+			// fast synced or predefined chains start above the first block
+			if chain.repo.ReadCanonicalHash(header.Height()-1) != (common.Hash{}) {
+				report.addIssue(HeaderIssue, header, fmt.Sprintf("parent header %v is missing", header.ParentHash().Hex()))
+			}
+			break
+		}
+		if parent.Height()+1 != header.Height() {
+			report.addIssue(HeaderIssue, header, fmt.Sprintf("parent height %v is invalid", parent.Height()))
+			break
+		}
+		header = parent
+	}
+	return report, nil
+}
+
+func (chain *Blockchain) verifyState(head *types.Header, report *IntegrityReport) {
+	if err := chain.appState.Initialize(head.Height()); err != nil {
+		report.addIssue(StateIssue, head, err.Error())
+		return
+	}
+	if root := chain.appState.State.Root(); root != head.Root() {
+		report.addIssue(StateIssue, head, fmt.Sprintf("state root %v doesn't match header", root.Hex()))
+	}
+	if root := chain.appState.IdentityState.Root(); root != head.IdentityRoot() {
+		report.addIssue(StateIssue, head, fmt.Sprintf("identity state root %v doesn't match header", root.Hex()))
+	}
+}
+
+func (chain *Blockchain) verifyPreliminaryHead(head *types.Header, report *IntegrityReport) {
+	preliminaryHead := chain.repo.ReadPreliminaryHead()
+	if preliminaryHead == nil || preliminaryHead.Height() <= head.Height() {
+		return
+	}
+	header := preliminaryHead
+	for header.Height() > head.Height()+1 {
+		if header = chain.repo.ReadBlockHeader(header.ParentHash()); header == nil {
+			report.addIssue(PreliminaryHeadIssue, preliminaryHead, "preliminary chain is broken")
+			return
+		}
+	}
+	if header.ParentHash() != head.Hash() {
+		report.addIssue(PreliminaryHeadIssue, preliminaryHead, "preliminary chain doesn't connect to head")
+	}
+}
+
+func (chain *Blockchain) verifyBody(header *types.Header, report *IntegrityReport) {
+	if header.EmptyBlockHeader != nil {
+		return
+	}
+	bodyBytes, err := chain.ipfs.Get(header.ProposedHeader.IpfsHash)
+	if err != nil {
+		report.addIssue(BodyIssue, header, err.Error())
+		return
+	}
+	body := &types.Body{}
+	body.FromBytes(bodyBytes)
+	txs := types.Transactions(body.Transactions)
+	if types.DeriveSha(txs) != header.ProposedHeader.TxHash {
+		report.addIssue(BodyIssue, header, "tx hash doesn't match header")
+		return
+	}
+	for i, tx := range txs {
+		report.CheckedTxs++
+		idx := chain.repo.ReadTxIndex(tx.Hash())
+		if idx == nil || idx.BlockHash != header.Hash() || int(idx.Idx) != i {
+			issue := report.addIssue(TxIndexIssue, header, fmt.Sprintf("tx %v index is missing or invalid", tx.Hash().Hex()))
+			issue.txs = txs
+			return
+		}
+	}
+}
+
+// RepairIntegrity re-derives indexes reported by VerifyIntegrity and resets the chain to the last consistent state.
+// It returns the number of repaired issues.
+func (chain *Blockchain) RepairIntegrity(report *IntegrityReport) (int, error) {
+	repaired := 0
+	for _, issue := range report.Issues {
+		switch issue.Kind {
+		case CanonicalHashIssue:
+			chain.repo.WriteCanonicalHash(issue.Height, issue.Hash)
+		case TxIndexIssue:
+			chain.WriteTxIndex(issue.Hash, issue.txs)
+		case PreliminaryHeadIssue:
+			chain.repo.RemovePreliminaryHead(nil)
+		case StateIssue:
+			continue
+		default:
+			chain.log.Warn("Cannot repair issue, resync is required", "issue", issue.String())
+			continue
+		}
+		repaired++
+	}
+	for _, issue := range report.Issues {
+		if issue.Kind == StateIssue {
+			chain.setCurrentHead(chain.repo.ReadHead())
+			if err := chain.EnsureIntegrity(); err != nil {
+				return repaired, err
+			}
+			repaired++
+			break
+		}
+	}
+	return repaired, nil
+}
@@ -0,0 +1,33 @@
+package blockchain
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestBlockchain_VerifyIntegrity(t *testing.T) {
+	chain, _ := NewTestBlockchainWithBlocks(50, 10)
+
+	report, err := chain.VerifyIntegrity(true)
+	require.NoError(t, err)
+	require.True(t, report.Ok())
+	require.Equal(t, chain.Head.Height(), report.Head)
+	require.Equal(t, uint64(1), report.Lowest)
+
+	chain.repo.WriteCanonicalHash(30, common.Hash{0x1})
+
+	report, err = chain.VerifyIntegrity(true)
+	require.NoError(t, err)
+	require.Len(t, report.Issues, 1)
+	require.Equal(t, CanonicalHashIssue, report.Issues[0].Kind)
+	require.Equal(t, uint64(30), report.Issues[0].Height)
+
+	repaired, err := chain.RepairIntegrity(report)
+	require.NoError(t, err)
+	require.Equal(t, 1, repaired)
+
+	report, err = chain.VerifyIntegrity(true)
+	require.NoError(t, err)
+	require.True(t, report.Ok())
+}
@@ -0,0 +1,127 @@
+package offline
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/common/math"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/rlp"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"io/ioutil"
+	"math/big"
+	"strings"
+)
+
+// UnsignedTx is the description of transaction prepared on an online machine, nonce, epoch and max fee can't be
+// taken from the chain state while signing so they are required
+type UnsignedTx struct {
+	Type    types.TxType    `json:"type"`
+	To      *common.Address `json:"to"`
+	Amount  decimal.Decimal `json:"amount"`
+	MaxFee  decimal.Decimal `json:"maxFee"`
+	Tips    decimal.Decimal `json:"tips"`
+	Nonce   uint32          `json:"nonce"`
+	Epoch   uint16          `json:"epoch"`
+	Payload *hexutil.Bytes  `json:"payload"`
+}
+
+type SignedTx struct {
+	From common.Address `json:"from"`
+	Hash common.Hash    `json:"hash"`
+	Raw  hexutil.Bytes  `json:"raw"`
+}
+
+func toInt(amount decimal.Decimal) *big.Int {
+	if amount == (decimal.Decimal{}) {
+		return nil
+	}
+	return math.ToInt(amount.Mul(decimal.NewFromBigInt(common.DnaBase, 0)))
+}
+
+func (u *UnsignedTx) Build() (*types.Transaction, error) {
+	if u.Nonce == 0 {
+		return nil, errors.New("nonce is required")
+	}
+	if u.MaxFee.Sign() <= 0 {
+		return nil, errors.New("max fee is required")
+	}
+	tx := &types.Transaction{
+		AccountNonce: u.Nonce,
+		Epoch:        u.Epoch,
+		Type:         u.Type,
+		To:           u.To,
+		Amount:       toInt(u.Amount),
+		MaxFee:       toInt(u.MaxFee),
+		Tips:         toInt(u.Tips),
+	}
+	if u.Payload != nil {
+		tx.Payload = *u.Payload
+	}
+	return tx, nil
+}
+
+// ParseTx parses the unsigned tx JSON or the hex encoded RLP of unsigned tx returned by bcn_getRawTx
+func ParseTx(data []byte) (*types.Transaction, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var unsigned UnsignedTx
+		if err := json.Unmarshal(data, &unsigned); err != nil {
+			return nil, errors.Wrap(err, "cannot parse unsigned tx")
+		}
+		return unsigned.Build()
+	}
+	raw, err := hexutil.Decode(strings.Trim(string(data), "\""))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot decode raw tx")
+	}
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(raw, tx); err != nil {
+		return nil, errors.Wrap(err, "cannot decode raw tx")
+	}
+	if len(tx.Signature) > 0 {
+		return nil, errors.New("tx is already signed")
+	}
+	return tx, nil
+}
+
+func Sign(tx *types.Transaction, key *ecdsa.PrivateKey) (*SignedTx, error) {
+	signed, err := types.SignTx(tx, key)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := rlp.EncodeToBytes(signed)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedTx{
+		From: crypto.PubkeyToAddress(key.PublicKey),
+		Hash: signed.Hash(),
+		Raw:  raw,
+	}, nil
+}
+
+// LoadKey reads the node key file, the key exported by dna_exportKey is expected if the password is set
+func LoadKey(path string, password string) (*ecdsa.PrivateKey, error) {
+	if password == "" {
+		return crypto.LoadECDSA(path)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot decode key")
+	}
+	decrypted, err := crypto.Decrypt(encrypted, password)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot decrypt key")
+	}
+	return crypto.ToECDSA(decrypted)
+}
@@ -0,0 +1,71 @@
+package offline
+
+import (
+	"encoding/hex"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/rlp"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	tx, err := ParseTx([]byte(`{"type": 0, "to": "0x0100000000000000000000000000000000000000", "amount": "1.5", "maxFee": "0.1", "nonce": 3, "epoch": 2, "payload": "0x0102"}`))
+	require.NoError(t, err)
+	require.Equal(t, uint32(3), tx.AccountNonce)
+	require.Equal(t, uint16(2), tx.Epoch)
+	require.Equal(t, new(big.Int).Mul(big.NewInt(15), new(big.Int).Div(common.DnaBase, big.NewInt(10))), tx.Amount)
+	require.Equal(t, []byte{0x1, 0x2}, tx.Payload)
+
+	signed, err := Sign(tx, key)
+	require.NoError(t, err)
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), signed.From)
+
+	decoded := new(types.Transaction)
+	require.NoError(t, rlp.DecodeBytes(signed.Raw, decoded))
+	sender, err := types.Sender(decoded)
+	require.NoError(t, err)
+	require.Equal(t, signed.From, sender)
+	require.Equal(t, signed.Hash, decoded.Hash())
+
+	unsigned, _ := rlp.EncodeToBytes(tx)
+	parsed, err := ParseTx([]byte(`"` + hexutil.Encode(unsigned) + `"`))
+	require.NoError(t, err)
+	require.Equal(t, tx.Hash(), parsed.Hash())
+
+	_, err = ParseTx([]byte(signed.Raw.String()))
+	require.Error(t, err)
+	_, err = ParseTx([]byte(`{"type": 0, "maxFee": "0.1"}`))
+	require.Error(t, err)
+	_, err = ParseTx([]byte(`{"type": 0, "nonce": 1}`))
+	require.Error(t, err)
+}
+
+func TestLoadKey(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "offline")
+	defer os.RemoveAll(dir)
+	key, _ := crypto.GenerateKey()
+
+	plain := filepath.Join(dir, "nodekey")
+	require.NoError(t, crypto.SaveECDSA(plain, key))
+	loaded, err := LoadKey(plain, "")
+	require.NoError(t, err)
+	require.Equal(t, crypto.FromECDSA(key), crypto.FromECDSA(loaded))
+
+	encrypted, _ := crypto.Encrypt(crypto.FromECDSA(key), "secret")
+	exported := filepath.Join(dir, "exported")
+	require.NoError(t, ioutil.WriteFile(exported, []byte(hex.EncodeToString(encrypted)+"\n"), 0600))
+	loaded, err = LoadKey(exported, "secret")
+	require.NoError(t, err)
+	require.Equal(t, crypto.FromECDSA(key), crypto.FromECDSA(loaded))
+
+	_, err = LoadKey(exported, "wrong")
+	require.Error(t, err)
+}
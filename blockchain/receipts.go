@@ -0,0 +1,117 @@
+package blockchain
+
+import (
+	"github.com/idena-network/idena-go/blockchain/attachments"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/math"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/shopspring/decimal"
+	"math/big"
+)
+
+const (
+	IdentityActivatedEvent     = "IdentityActivated"
+	InviteIssuedEvent          = "InviteIssued"
+	IdentityKilledEvent        = "IdentityKilled"
+	FlipSubmittedEvent         = "FlipSubmitted"
+	FlipDeletedEvent           = "FlipDeleted"
+	OnlineStatusSwitchEvent    = "OnlineStatusSwitch"
+	GodAddressChangedEvent     = "GodAddressChanged"
+	ProfileChangedEvent        = "ProfileChanged"
+	ValidationTxSubmittedEvent = "ValidationTxSubmitted"
+	CoinsBurnedEvent           = "CoinsBurned"
+)
+
+// receiptAddresses returns accounts whose balance, stake or identity state the tx may change
+func receiptAddresses(tx *types.Transaction) []common.Address {
+	sender, _ := types.Sender(tx)
+	result := []common.Address{sender}
+	if tx.To != nil && *tx.To != sender {
+		result = append(result, *tx.To)
+	}
+	return result
+}
+
+func captureReceiptChanges(s *state.StateDB, addrs []common.Address) []*types.ReceiptChange {
+	result := make([]*types.ReceiptChange, 0, len(addrs))
+	for _, addr := range addrs {
+		result = append(result, &types.ReceiptChange{
+			Address:       addr,
+			BalanceBefore: new(big.Int).Set(s.GetBalance(addr)),
+			StakeBefore:   new(big.Int).Set(s.GetStakeBalance(addr)),
+			StateBefore:   uint8(s.GetIdentityState(addr)),
+		})
+	}
+	return result
+}
+
+// buildReceipt completes changes captured before the tx was applied. All txs included into the block are applied
+// successfully, otherwise the block is invalid
+func (chain *Blockchain) buildReceipt(s *state.StateDB, tx *types.Transaction, fee *big.Int,
+	changes []*types.ReceiptChange) *types.TxReceipt {
+	for _, change := range changes {
+		change.BalanceAfter = new(big.Int).Set(s.GetBalance(change.Address))
+		change.StakeAfter = new(big.Int).Set(s.GetStakeBalance(change.Address))
+		change.StateAfter = uint8(s.GetIdentityState(change.Address))
+	}
+	burned := decimal.NewFromBigInt(fee, 0).Mul(decimal.NewFromFloat32(chain.config.Consensus.FeeBurnRate))
+	return &types.TxReceipt{
+		TxHash:    tx.Hash(),
+		Success:   true,
+		Fee:       new(big.Int).Set(fee),
+		FeeBurned: math.ToInt(burned),
+		Changes:   changes,
+		Events:    txEvents(tx),
+	}
+}
+
+func txEvents(tx *types.Transaction) []*types.TxEvent {
+	sender, _ := types.Sender(tx)
+	event := func(name string, addr common.Address, data []byte) []*types.TxEvent {
+		return []*types.TxEvent{{Name: name, Address: addr, Data: data}}
+	}
+	switch tx.Type {
+	case types.ActivationTx:
+		return event(IdentityActivatedEvent, *tx.To, nil)
+	case types.InviteTx:
+		return event(InviteIssuedEvent, *tx.To, nil)
+	case types.KillTx:
+		return event(IdentityKilledEvent, sender, nil)
+	case types.KillInviteeTx:
+		return event(IdentityKilledEvent, *tx.To, nil)
+	case types.SubmitFlipTx:
+		if attachment := attachments.ParseFlipSubmitAttachment(tx); attachment != nil {
+			return event(FlipSubmittedEvent, sender, attachment.Cid)
+		}
+	case types.DeleteFlipTx:
+		if attachment := attachments.ParseDeleteFlipAttachment(tx); attachment != nil {
+			return event(FlipDeletedEvent, sender, attachment.Cid)
+		}
+	case types.OnlineStatusTx:
+		return event(OnlineStatusSwitchEvent, sender, nil)
+	case types.ChangeGodAddressTx:
+		return event(GodAddressChangedEvent, *tx.To, nil)
+	case types.ChangeProfileTx:
+		if attachment := attachments.ParseChangeProfileAttachment(tx); attachment != nil {
+			return event(ProfileChangedEvent, sender, attachment.Hash)
+		}
+	case types.SubmitAnswersHashTx, types.SubmitShortAnswersTx, types.SubmitLongAnswersTx, types.EvidenceTx:
+		return event(ValidationTxSubmittedEvent, sender, []byte{byte(tx.Type)})
+	case types.BurnTx:
+		return event(CoinsBurnedEvent, sender, tx.AmountOrZero().Bytes())
+	}
+	return nil
+}
+
+func (chain *Blockchain) writeTxReceipts(blockHash common.Hash, receipts []*types.TxReceipt) {
+	for _, receipt := range receipts {
+		receipt.BlockHash = blockHash
+		chain.repo.WriteTxReceipt(receipt)
+	}
+}
+
+// GetTxReceipt returns the receipt of the tx included into the block
+func (chain *Blockchain) GetTxReceipt(hash common.Hash) *types.TxReceipt {
+	return chain.repo.ReadTxReceipt(hash)
+}
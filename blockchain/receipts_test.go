@@ -0,0 +1,65 @@
+package blockchain
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestBlockchain_TxReceipts(t *testing.T) {
+	require := require.New(t)
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	recipient := common.Address{0x1}
+	balance := ConvertToInt(decimal.New(100, 0))
+	consensusCfg := config.GetDefaultConsensusConfig()
+	consensusCfg.Automine = true
+	cfg := &config.Config{
+		Network:   0x99,
+		Consensus: consensusCfg,
+		GenesisConf: &config.GenesisConf{
+			Alloc: map[common.Address]config.GenesisAllocation{
+				addr: {
+					State:   uint8(state.Verified),
+					Balance: balance,
+				},
+			},
+			GodAddress:        addr,
+			FirstCeremonyTime: 4070908800, //01.01.2099
+		},
+		Validation: &config.ValidationConfig{},
+		Blockchain: &config.BlockchainConfig{},
+	}
+	chain, appState := NewCustomTestBlockchainWithConfig(5, 0, key, cfg)
+
+	tx, _ := chain.secStore.SignTx(BuildTx(appState, addr, &recipient, types.SendTx, decimal.New(10, 0), decimal.New(2, 0), decimal.Zero, 0, 0, nil))
+	require.NoError(chain.txpool.Add(tx))
+	chain.GenerateBlocks(1)
+
+	receipt := chain.GetTxReceipt(tx.Hash())
+	require.NotNil(receipt)
+	require.True(receipt.Success)
+	require.Equal(chain.GetTxIndex(tx.Hash()).BlockHash, receipt.BlockHash)
+	require.True(receipt.Fee.Sign() > 0)
+	require.Equal(1, receipt.FeeBurned.Cmp(big.NewInt(0)))
+	require.Len(receipt.Changes, 2)
+
+	sent := ConvertToInt(decimal.New(10, 0))
+	senderChange := receipt.Changes[0]
+	require.Equal(addr, senderChange.Address)
+	require.Equal(new(big.Int).Sub(senderChange.BalanceBefore, new(big.Int).Add(sent, receipt.Fee)), senderChange.BalanceAfter)
+	require.Equal(uint8(state.Verified), senderChange.StateAfter)
+
+	recipientChange := receipt.Changes[1]
+	require.Equal(recipient, recipientChange.Address)
+	require.Equal(0, recipientChange.BalanceBefore.Sign())
+	require.Equal(sent, recipientChange.BalanceAfter)
+
+	require.Nil(chain.GetTxReceipt(common.Hash{0x1}))
+}
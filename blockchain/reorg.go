@@ -0,0 +1,57 @@
+package blockchain
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/events"
+)
+
+// GetBlocksAbove returns canonical blocks from height+1 to the head
+func (chain *Blockchain) GetBlocksAbove(height uint64) []*types.Block {
+	var result []*types.Block
+	for h := height + 1; h <= chain.Head.Height(); h++ {
+		if block := chain.GetBlockByHeight(h); block != nil {
+			result = append(result, block)
+		}
+	}
+	return result
+}
+
+// DroppedTxs returns txs of the old blocks which are not included into the new blocks
+func DroppedTxs(oldBlocks []*types.Block, newBlocks []*types.Block) []*types.Transaction {
+	included := make(map[common.Hash]struct{})
+	for _, block := range newBlocks {
+		if block.Body == nil {
+			continue
+		}
+		for _, tx := range block.Body.Transactions {
+			included[tx.Hash()] = struct{}{}
+		}
+	}
+	var result []*types.Transaction
+	for _, block := range oldBlocks {
+		if block.Body == nil {
+			continue
+		}
+		for _, tx := range block.Body.Transactions {
+			if _, ok := included[tx.Hash()]; !ok {
+				result = append(result, tx)
+			}
+		}
+	}
+	return result
+}
+
+// PublishReorg publishes the reorg event after the old blocks above the common height have been replaced by the new ones
+func (chain *Blockchain) PublishReorg(oldHead *types.Header, commonHeight uint64, oldBlocks []*types.Block, newBlocks []*types.Block) {
+	dropped := DroppedTxs(oldBlocks, newBlocks)
+	chain.log.Warn("Chain reorganization", "common height", commonHeight, "depth", oldHead.Height()-commonHeight,
+		"old head", oldHead.Hash().Hex(), "new head", chain.Head.Hash().Hex(), "dropped txs", len(dropped))
+	chain.bus.Publish(&events.ReorgEvent{
+		OldHead:      oldHead,
+		NewHead:      chain.Head,
+		CommonHeight: commonHeight,
+		Depth:        oldHead.Height() - commonHeight,
+		DroppedTxs:   dropped,
+	})
+}
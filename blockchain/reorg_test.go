@@ -0,0 +1,25 @@
+package blockchain
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDroppedTxs(t *testing.T) {
+	tx := func(nonce uint32) *types.Transaction {
+		return &types.Transaction{AccountNonce: nonce, Type: types.SendTx}
+	}
+	block := func(txs ...*types.Transaction) *types.Block {
+		return &types.Block{Header: &types.Header{}, Body: &types.Body{Transactions: txs}}
+	}
+	tx1, tx2, tx3, tx4 := tx(1), tx(2), tx(3), tx(4)
+
+	oldBlocks := []*types.Block{block(tx1, tx2), {Header: &types.Header{}}, block(tx3)}
+	newBlocks := []*types.Block{block(tx2), block(tx4)}
+
+	dropped := DroppedTxs(oldBlocks, newBlocks)
+	require.Equal(t, []*types.Transaction{tx1, tx3}, dropped)
+	require.Empty(t, DroppedTxs(newBlocks, newBlocks))
+	require.Empty(t, DroppedTxs(nil, newBlocks))
+}
@@ -0,0 +1,175 @@
+package blockchain
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/math"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"math/big"
+	"time"
+)
+
+const defaultBlockInterval = 20 * time.Second
+
+type RewardProjectionParams struct {
+	// Flips is the number of identity flips expected to be rewarded, flips submitted at the current epoch are used if nil
+	Flips *int
+	// InviteeAges are ages of invitees after the validation, current invitees are used if nil
+	InviteeAges []uint16
+	// Newbie defines if the identity is expected to be newbie after the validation, it is derived from the current state if nil
+	Newbie *bool
+	// Blocks is the expected number of blocks in the epoch, it is estimated by the next validation time if zero
+	Blocks uint64
+}
+
+type ProjectedReward struct {
+	Balance *big.Int
+	Stake   *big.Int
+}
+
+type RewardProjection struct {
+	Epoch                 uint16
+	Blocks                uint64
+	TotalReward           *big.Int
+	Age                   uint16
+	NormalizedAges        float32
+	TotalFlips            float32
+	TotalInvitationWeight float32
+	Validation            ProjectedReward
+	Flips                 ProjectedReward
+	Invitations           ProjectedReward
+	Total                 ProjectedReward
+}
+
+func isRewardableState(s state.IdentityState) bool {
+	return s == state.Candidate || s.NewbieOrBetter()
+}
+
+// ageAfterValidation returns the age the validation reward is calculated for, candidates get birthday at the validation
+func ageAfterValidation(epoch uint16, identity state.Identity) uint16 {
+	if identity.State == state.Candidate {
+		return 0
+	}
+	return epoch - identity.Birthday
+}
+
+// inviteeAgeAfterValidation returns the age the invitation reward coefficient is taken for
+func inviteeAgeAfterValidation(epoch uint16, identity state.Identity) uint16 {
+	return ageAfterValidation(epoch, identity) + 1
+}
+
+// ProjectRewards estimates validation, flip and invitation rewards of the identity at the next validation using
+// the formulas of the reward engine, it assumes all rewardable identities pass the validation
+func (chain *Blockchain) ProjectRewards(addr common.Address, params RewardProjectionParams) (*RewardProjection, error) {
+	st := chain.appState.State
+	identity := st.GetIdentity(addr)
+	if !isRewardableState(identity.State) && identity.State != state.Suspended && identity.State != state.Zombie {
+		return nil, errors.Errorf("identity in state %v doesn't get validation rewards", identity.State)
+	}
+	epoch := st.Epoch()
+	conf := chain.config.Consensus
+
+	result := &RewardProjection{
+		Epoch:  epoch,
+		Blocks: params.Blocks,
+		Age:    ageAfterValidation(epoch, identity),
+	}
+	if result.Blocks == 0 {
+		result.Blocks = chain.estimateEpochBlocks()
+	}
+
+	ownFlips := len(identity.Flips)
+	if params.Flips != nil {
+		ownFlips = *params.Flips
+	}
+	var inviteeAges []uint16
+	st.IterateOverIdentities(func(a common.Address, item state.Identity) {
+		if !isRewardableState(item.State) {
+			return
+		}
+		result.NormalizedAges += normalAge(ageAfterValidation(epoch, item))
+		if a != addr {
+			result.TotalFlips += float32(len(item.Flips))
+		}
+		if item.Inviter != nil && (item.State == state.Candidate || item.State == state.Newbie || item.State == state.Verified) {
+			if age := inviteeAgeAfterValidation(epoch, item); age <= 3 {
+				result.TotalInvitationWeight += getInvitationRewardCoef(age, conf)
+				if item.Inviter.Address == addr {
+					inviteeAges = append(inviteeAges, age)
+				}
+			}
+		}
+	})
+	if !isRewardableState(identity.State) {
+		result.NormalizedAges += normalAge(result.Age)
+	}
+	result.TotalFlips += float32(ownFlips)
+	if params.InviteeAges != nil {
+		for _, age := range inviteeAges {
+			result.TotalInvitationWeight -= getInvitationRewardCoef(age, conf)
+		}
+		inviteeAges = params.InviteeAges
+		for _, age := range inviteeAges {
+			result.TotalInvitationWeight += getInvitationRewardCoef(age, conf)
+		}
+	}
+
+	isNewbie := identity.State == state.Candidate || identity.State == state.Newbie
+	if params.Newbie != nil {
+		isNewbie = *params.Newbie
+	}
+
+	result.TotalReward = totalEpochReward(conf, result.Blocks)
+	totalReward := decimal.NewFromBigInt(result.TotalReward, 0)
+	project := func(share decimal.Decimal, weight float32) ProjectedReward {
+		balance, stake := splitReward(math.ToInt(share.Mul(decimal.NewFromFloat32(weight))), isNewbie, conf)
+		return ProjectedReward{balance, stake}
+	}
+
+	if result.NormalizedAges > 0 {
+		_, share := calcValidationRewardShare(totalReward, conf, result.NormalizedAges)
+		result.Validation = project(share, normalAge(result.Age))
+	}
+	if result.TotalFlips > 0 && ownFlips > 0 {
+		_, share := calcFlipRewardShare(totalReward, conf, result.TotalFlips)
+		result.Flips = project(share, float32(ownFlips))
+	}
+	ownWeight := float32(0)
+	for _, age := range inviteeAges {
+		ownWeight += getInvitationRewardCoef(age, conf)
+	}
+	if result.TotalInvitationWeight > 0 && ownWeight > 0 {
+		_, share := calcInvitationRewardShare(totalReward, conf, result.TotalInvitationWeight)
+		result.Invitations = project(share, ownWeight)
+	}
+
+	result.Total = ProjectedReward{big.NewInt(0), big.NewInt(0)}
+	for _, r := range []ProjectedReward{result.Validation, result.Flips, result.Invitations} {
+		if r.Balance != nil {
+			result.Total.Balance.Add(result.Total.Balance, r.Balance)
+			result.Total.Stake.Add(result.Total.Stake, r.Stake)
+		}
+	}
+	return result, nil
+}
+
+// estimateEpochBlocks estimates the epoch length by the average block interval of the epoch and the next validation time
+func (chain *Blockchain) estimateEpochBlocks() uint64 {
+	st := chain.appState.State
+	head := chain.Head
+	passed := head.Height() - st.EpochBlock()
+	interval := defaultBlockInterval
+	if passed > 0 {
+		if epochHeader := chain.GetBlockHeaderByHeight(st.EpochBlock()); epochHeader != nil {
+			if elapsed := head.Time().Int64() - epochHeader.Time().Int64(); elapsed > 0 {
+				interval = time.Duration(elapsed) * time.Second / time.Duration(passed)
+			}
+		}
+	}
+	remaining := time.Until(st.NextValidationTime())
+	if remaining <= 0 || interval <= 0 {
+		return passed
+	}
+	return passed + uint64(remaining/interval)
+}
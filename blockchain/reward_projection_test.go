@@ -0,0 +1,78 @@
+package blockchain
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tm-db"
+	"math/big"
+	"testing"
+)
+
+func requireAmount(t *testing.T, expected float64, reward ProjectedReward) {
+	sum := new(big.Int).Add(reward.Balance, reward.Stake)
+	require.InDelta(t, expected, float64(sum.Int64()), 1)
+}
+
+func TestBlockchain_ProjectRewards(t *testing.T) {
+	inviter := common.Address{0x1}
+	invitee := common.Address{0x2}
+	human := common.Address{0x3}
+	killed := common.Address{0x4}
+
+	conf := config.GetDefaultConsensusConfig()
+	conf.BlockReward = big.NewInt(5)
+	conf.FinalCommitteeReward = big.NewInt(5)
+
+	appState := appstate.NewAppState(db.NewMemDB(), eventbus.New())
+	appState.Initialize(0)
+	appState.State.SetGlobalEpoch(5)
+
+	appState.State.SetState(inviter, state.Verified)
+	appState.State.SetBirthday(inviter, 2)
+	appState.State.AddFlip(inviter, []byte{0x1}, 0)
+	appState.State.AddFlip(inviter, []byte{0x2}, 1)
+
+	appState.State.SetState(invitee, state.Newbie)
+	appState.State.SetBirthday(invitee, 5)
+	appState.State.SetInviter(invitee, inviter, common.Hash{})
+
+	appState.State.SetState(human, state.Human)
+	appState.State.SetBirthday(human, 1)
+	appState.State.AddFlip(human, []byte{0x3}, 0)
+
+	appState.State.SetState(killed, state.Killed)
+	appState.Commit(nil)
+
+	chain := &Blockchain{config: &config.Config{Consensus: conf}, appState: appState}
+
+	projection, err := chain.ProjectRewards(inviter, RewardProjectionParams{Blocks: 100})
+	require.NoError(t, err)
+	require.Equal(t, uint16(3), projection.Age)
+	require.Equal(t, big.NewInt(1000), projection.TotalReward)
+	require.Equal(t, normalAge(3)+normalAge(0)+normalAge(4), projection.NormalizedAges)
+	require.Equal(t, float32(3), projection.TotalFlips)
+	require.Equal(t, conf.FirstInvitationRewardCoef, projection.TotalInvitationWeight)
+
+	requireAmount(t, 240*float64(normalAge(3)/projection.NormalizedAges), projection.Validation)
+	requireAmount(t, 320*2.0/3, projection.Flips)
+	requireAmount(t, 320, projection.Invitations)
+
+	require.True(t, projection.Validation.Balance.Cmp(projection.Validation.Stake) > 0)
+
+	flips := 0
+	newbie := true
+	projection, err = chain.ProjectRewards(inviter, RewardProjectionParams{Blocks: 100, Flips: &flips, InviteeAges: []uint16{2}, Newbie: &newbie})
+	require.NoError(t, err)
+	require.Nil(t, projection.Flips.Balance)
+	require.Equal(t, float32(1), projection.TotalFlips)
+	require.Equal(t, conf.SecondInvitationRewardCoef, projection.TotalInvitationWeight)
+	requireAmount(t, 320, projection.Invitations)
+	require.True(t, projection.Invitations.Stake.Cmp(projection.Invitations.Balance) > 0)
+
+	_, err = chain.ProjectRewards(killed, RewardProjectionParams{Blocks: 100})
+	require.Error(t, err)
+}
@@ -22,8 +22,7 @@ import (
 func rewardValidIdentities(appState *appstate.AppState, config *config.ConsensusConf, authors *types.ValidationAuthors,
 	blocks uint64, seed types.Seed, statsCollector collector.StatsCollector) {
 
-	totalReward := big.NewInt(0).Add(config.BlockReward, config.FinalCommitteeReward)
-	totalReward = totalReward.Mul(totalReward, big.NewInt(int64(blocks)))
+	totalReward := totalEpochReward(config, blocks)
 
 	collector.SetAuthors(statsCollector, authors)
 	collector.SetTotalReward(statsCollector, totalReward)
@@ -40,8 +39,6 @@ func rewardValidIdentities(appState *appstate.AppState, config *config.Consensus
 
 func addSuccessfulValidationReward(appState *appstate.AppState, config *config.ConsensusConf,
 	authors *types.ValidationAuthors, totalReward decimal.Decimal, statsCollector collector.StatsCollector) {
-	successfulValidationRewardD := totalReward.Mul(decimal.NewFromFloat32(config.SuccessfulValidationRewardPercent))
-
 	epoch := appState.State.Epoch()
 
 	normalizedAges := float32(0)
@@ -57,7 +54,7 @@ func addSuccessfulValidationReward(appState *appstate.AppState, config *config.C
 		return
 	}
 
-	successfulValidationRewardShare := successfulValidationRewardD.Div(decimal.NewFromFloat32(normalizedAges))
+	successfulValidationRewardD, successfulValidationRewardShare := calcValidationRewardShare(totalReward, config, normalizedAges)
 	collector.SetTotalValidationReward(statsCollector, math.ToInt(successfulValidationRewardD),
 		math.ToInt(successfulValidationRewardShare))
 
@@ -82,8 +79,6 @@ func addSuccessfulValidationReward(appState *appstate.AppState, config *config.C
 
 func addFlipReward(appState *appstate.AppState, config *config.ConsensusConf, authors *types.ValidationAuthors,
 	totalReward decimal.Decimal, statsCollector collector.StatsCollector) {
-	flipRewardD := totalReward.Mul(decimal.NewFromFloat32(config.FlipRewardPercent))
-
 	totalFlips := float32(0)
 	for _, author := range authors.GoodAuthors {
 		if author.Missed {
@@ -94,7 +89,7 @@ func addFlipReward(appState *appstate.AppState, config *config.ConsensusConf, au
 	if totalFlips == 0 {
 		return
 	}
-	flipRewardShare := flipRewardD.Div(decimal.NewFromFloat32(totalFlips))
+	flipRewardD, flipRewardShare := calcFlipRewardShare(totalReward, config, totalFlips)
 	collector.SetTotalFlipsReward(statsCollector, math.ToInt(flipRewardD), math.ToInt(flipRewardShare))
 
 	for addr, author := range authors.GoodAuthors {
@@ -129,8 +124,6 @@ func getInvitationRewardCoef(age uint16, config *config.ConsensusConf) float32 {
 
 func addInvitationReward(appState *appstate.AppState, config *config.ConsensusConf, authors *types.ValidationAuthors,
 	totalReward decimal.Decimal, seed types.Seed, statsCollector collector.StatsCollector) {
-	invitationRewardD := totalReward.Mul(decimal.NewFromFloat32(config.ValidInvitationRewardPercent))
-
 	addAddress := func(data []common.Hash, elem common.Hash) []common.Hash {
 		index := sort.Search(len(data), func(i int) bool { return bytes.Compare(data[i][:], elem[:]) > 0 })
 		data = append(data, common.Hash{})
@@ -165,7 +158,7 @@ func addInvitationReward(appState *appstate.AppState, config *config.ConsensusCo
 	if totalWeight == 0 {
 		return
 	}
-	invitationRewardShare := invitationRewardD.Div(decimal.NewFromFloat32(totalWeight))
+	invitationRewardD, invitationRewardShare := calcInvitationRewardShare(totalReward, config, totalWeight)
 	collector.SetTotalInvitationsReward(statsCollector, math.ToInt(invitationRewardD), math.ToInt(invitationRewardShare))
 
 	addReward := func(addr common.Address, totalReward decimal.Decimal, isNewbie bool, age uint16, txHash *common.Hash,
@@ -228,6 +221,29 @@ func addZeroWalletFund(appState *appstate.AppState, config *config.ConsensusConf
 	collector.AddZeroWalletFund(statsCollector, zeroAddress, total)
 }
 
+func totalEpochReward(config *config.ConsensusConf, blocks uint64) *big.Int {
+	totalReward := big.NewInt(0).Add(config.BlockReward, config.FinalCommitteeReward)
+	return totalReward.Mul(totalReward, big.NewInt(int64(blocks)))
+}
+
+// calcValidationRewardShare returns the validation reward pool and the reward per unit of normalized age
+func calcValidationRewardShare(totalReward decimal.Decimal, config *config.ConsensusConf, normalizedAges float32) (pool, share decimal.Decimal) {
+	pool = totalReward.Mul(decimal.NewFromFloat32(config.SuccessfulValidationRewardPercent))
+	return pool, pool.Div(decimal.NewFromFloat32(normalizedAges))
+}
+
+// calcFlipRewardShare returns the flip reward pool and the reward per rewarded flip
+func calcFlipRewardShare(totalReward decimal.Decimal, config *config.ConsensusConf, totalFlips float32) (pool, share decimal.Decimal) {
+	pool = totalReward.Mul(decimal.NewFromFloat32(config.FlipRewardPercent))
+	return pool, pool.Div(decimal.NewFromFloat32(totalFlips))
+}
+
+// calcInvitationRewardShare returns the invitation reward pool and the reward per unit of invitation weight
+func calcInvitationRewardShare(totalReward decimal.Decimal, config *config.ConsensusConf, totalWeight float32) (pool, share decimal.Decimal) {
+	pool = totalReward.Mul(decimal.NewFromFloat32(config.ValidInvitationRewardPercent))
+	return pool, pool.Div(decimal.NewFromFloat32(totalWeight))
+}
+
 func normalAge(age uint16) float32 {
 	return float32(math2.Pow(float64(age)+1, float64(1)/3))
 }
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"math/big"
+)
+
+// TxReceipt is the outcome of the tx derived while the block is applied, it is generated for txs of any type
+type TxReceipt struct {
+	TxHash    common.Hash
+	BlockHash common.Hash
+	Success   bool
+	Error     string
+	Fee       *big.Int
+	FeeBurned *big.Int
+	Changes   []*ReceiptChange
+	Events    []*TxEvent
+}
+
+// ReceiptChange is the state of the account affected by the tx before and after applying it
+type ReceiptChange struct {
+	Address       common.Address
+	BalanceBefore *big.Int
+	BalanceAfter  *big.Int
+	StakeBefore   *big.Int
+	StakeAfter    *big.Int
+	StateBefore   uint8
+	StateAfter    uint8
+}
+
+// TxEvent is the non-balance effect of the tx, Data depends on the event
+type TxEvent struct {
+	Name    string
+	Address common.Address
+	Data    []byte
+}
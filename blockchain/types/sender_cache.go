@@ -0,0 +1,78 @@
+package types
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"runtime"
+	"sync"
+)
+
+// senderCacheSize is the number of recovered senders kept between the mempool and the block import
+const senderCacheSize = 100000
+
+// senderCache keeps senders recovered from signatures by tx hash, the hash covers the signature so the entry is valid
+// for any decoded copy of the tx. Two generations are kept, the older one is dropped when the current one is full.
+type senderCache struct {
+	mu       sync.RWMutex
+	current  map[common.Hash]common.Address
+	previous map[common.Hash]common.Address
+}
+
+var senders = newSenderCache()
+
+func newSenderCache() *senderCache {
+	return &senderCache{
+		current:  make(map[common.Hash]common.Address),
+		previous: make(map[common.Hash]common.Address),
+	}
+}
+
+func (c *senderCache) get(hash common.Hash) (common.Address, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if addr, ok := c.current[hash]; ok {
+		return addr, true
+	}
+	addr, ok := c.previous[hash]
+	return addr, ok
+}
+
+func (c *senderCache) add(hash common.Hash, addr common.Address) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.current) >= senderCacheSize/2 {
+		c.previous = c.current
+		c.current = make(map[common.Hash]common.Address, senderCacheSize/2)
+	}
+	c.current[hash] = addr
+}
+
+// RecoverSenders recovers senders of txs concurrently across CPU cores, results are cached in txs and
+// in the shared cache, so subsequent Sender calls are cheap. Invalid signatures are left for Sender to report.
+func RecoverSenders(txs []*Transaction) {
+	workers := runtime.NumCPU()
+	if workers > len(txs) {
+		workers = len(txs)
+	}
+	if workers <= 1 {
+		for _, tx := range txs {
+			Sender(tx)
+		}
+		return
+	}
+	jobs := make(chan *Transaction, len(txs))
+	for _, tx := range txs {
+		jobs <- tx
+	}
+	close(jobs)
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for tx := range jobs {
+				Sender(tx)
+			}
+		}()
+	}
+	wg.Wait()
+}
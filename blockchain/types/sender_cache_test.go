@@ -0,0 +1,62 @@
+package types
+
+import (
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/rlp"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestRecoverSenders(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+
+	var txs []*Transaction
+	for i := 0; i < 50; i++ {
+		tx, _ := SignTx(&Transaction{AccountNonce: uint32(i), To: &addr, Amount: big.NewInt(1)}, key)
+		txs = append(txs, tx)
+	}
+	txs = append(txs, &Transaction{AccountNonce: 100, Signature: []byte{0x1}})
+	RecoverSenders(txs)
+
+	for _, tx := range txs[:50] {
+		cached, ok := senders.get(tx.Hash())
+		require.True(t, ok)
+		require.Equal(t, addr, cached)
+
+		// decoded copy of the tx gets the sender from the shared cache
+		data, _ := rlp.EncodeToBytes(tx)
+		copied := new(Transaction)
+		require.NoError(t, rlp.DecodeBytes(data, copied))
+		sender, err := Sender(copied)
+		require.NoError(t, err)
+		require.Equal(t, addr, sender)
+	}
+	_, err := Sender(txs[50])
+	require.Error(t, err)
+	_, ok := senders.get(txs[50].Hash())
+	require.False(t, ok)
+}
+
+func BenchmarkRecoverSenders(b *testing.B) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	raw := make([][]byte, 1000)
+	for i := range raw {
+		tx, _ := SignTx(&Transaction{AccountNonce: uint32(i), To: &addr, Amount: big.NewInt(1)}, key)
+		raw[i], _ = rlp.EncodeToBytes(tx)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		senders = newSenderCache()
+		txs := make([]*Transaction, len(raw))
+		for j := range raw {
+			txs[j] = new(Transaction)
+			rlp.DecodeBytes(raw[j], txs[j])
+		}
+		b.StartTimer()
+		RecoverSenders(txs)
+	}
+}
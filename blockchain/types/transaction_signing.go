@@ -35,12 +35,18 @@ func Sender(tx *Transaction) (common.Address, error) {
 	if from := tx.from.Load(); from != nil {
 		return from.(common.Address), nil
 	}
+	hash := tx.Hash()
+	if addr, ok := senders.get(hash); ok {
+		tx.from.Store(addr)
+		return addr, nil
+	}
 
 	addr, err := recoverPlain(signatureHash(tx), tx.Signature)
 	if err != nil {
 		return common.Address{}, err
 	}
 	tx.from.Store(addr)
+	senders.add(hash, addr)
 	return addr, nil
 }
 
@@ -50,6 +56,11 @@ func SenderPubKey(tx *Transaction) ([]byte, error) {
 	return crypto.Ecrecover(signatureHash(tx).Bytes(), tx.Signature)
 }
 
+// SigningHash returns the hash to be signed by the sender of the transaction.
+func SigningHash(tx *Transaction) common.Hash {
+	return signatureHash(tx)
+}
+
 // Hash returns the hash to be signed by the sender.
 // It does not uniquely identify the transaction.
 func signatureHash(tx *Transaction) common.Hash {
@@ -30,3 +30,34 @@ func TestSignTx(t *testing.T) {
 		t.Errorf("exected from and address to be equal. Got %x want %x", from, addr)
 	}
 }
+
+func TestSigningHash(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+
+	tx := Transaction{
+		AccountNonce: 5,
+		Epoch:        2,
+		Type:         SendTx,
+		To:           &addr,
+		Amount:       big.NewInt(1),
+	}
+	hash := SigningHash(&tx)
+
+	// signature made by an external wallet over the signing hash is accepted
+	sig, err := crypto.Sign(hash[:], key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Signature = sig
+	from, err := Sender(&tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != addr {
+		t.Errorf("expected from and address to be equal. Got %x want %x", from, addr)
+	}
+	if hash != SigningHash(&tx) {
+		t.Error("signing hash should not depend on signature")
+	}
+}
@@ -328,6 +328,14 @@ func (h *Header) OfflineAddr() *common.Address {
 	}
 }
 
+func (h *Header) Upgrade() uint16 {
+	if h.EmptyBlockHeader != nil {
+		return 0
+	} else {
+		return h.ProposedHeader.Upgrade
+	}
+}
+
 func (h *ProposedHeader) Hash() common.Hash {
 	return rlp.Hash(h)
 }
@@ -438,6 +446,25 @@ func (s *FullBlockCert) Compress() *BlockCert {
 	return cert
 }
 
+// Votes restores votes of the certificate, voters are recovered from signatures
+func (c *BlockCert) Votes(parentHash common.Hash) []*Vote {
+	result := make([]*Vote, 0, len(c.Signatures))
+	for _, signature := range c.Signatures {
+		result = append(result, &Vote{
+			Header: &VoteHeader{
+				Round:       c.Round,
+				Step:        c.Step,
+				ParentHash:  parentHash,
+				VotedHash:   c.VotedHash,
+				TurnOffline: signature.TurnOffline,
+				Upgrade:     signature.Upgrade,
+			},
+			Signature: signature.Signature,
+		})
+	}
+	return result
+}
+
 func (p NewEpochPayload) Bytes() []byte {
 	enc, _ := rlp.EncodeToBytes(p)
 	return enc
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/crypto"
 	"github.com/stretchr/testify/require"
 	"testing"
 )
@@ -51,3 +53,32 @@ func TestBlockCert_Empty(t *testing.T) {
 	var cert *BlockCert
 	require.True(t, cert.Empty())
 }
+
+func TestBlockCert_Votes(t *testing.T) {
+	parentHash := common.Hash{0x1}
+	var cert FullBlockCert
+	var voters []common.Address
+	for i := 0; i < 3; i++ {
+		key, _ := crypto.GenerateKey()
+		header := &VoteHeader{
+			Round:      10,
+			Step:       Final,
+			ParentHash: parentHash,
+			VotedHash:  common.Hash{0x2},
+			Upgrade:    uint16(i),
+		}
+		hash := header.SignatureHash()
+		sig, err := crypto.Sign(hash[:], key)
+		require.NoError(t, err)
+		cert.Votes = append(cert.Votes, &Vote{Header: header, Signature: sig})
+		voters = append(voters, crypto.PubkeyToAddress(key.PublicKey))
+	}
+
+	votes := cert.Compress().Votes(parentHash)
+	require.Len(t, votes, len(voters))
+	for i, vote := range votes {
+		require.Equal(t, voters[i], vote.VoterAddr())
+		require.Equal(t, uint16(i), vote.Header.Upgrade)
+		require.Equal(t, uint64(10), vote.Header.Round)
+	}
+}
@@ -0,0 +1,122 @@
+package blockchain
+
+import (
+	"bytes"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/crypto/vrf/p256"
+	"runtime"
+	"sync"
+)
+
+const vrfCacheSize = 4096
+
+type VrfProof struct {
+	PubKey []byte
+	Seed   []byte
+	Proof  []byte
+}
+
+type VrfResult struct {
+	Hash [32]byte
+	Err  error
+}
+
+type vrfCacheEntry struct {
+	proof []byte
+	hash  [32]byte
+}
+
+// vrfVerifier verifies VRF proofs and caches verified ones by (pubkey, seed), the same proposer proof is received
+// from many peers and checked again on block validation
+type vrfVerifier struct {
+	mutex sync.Mutex
+	cache map[common.Hash]*vrfCacheEntry
+	order []common.Hash
+}
+
+func newVrfVerifier() *vrfVerifier {
+	return &vrfVerifier{
+		cache: make(map[common.Hash]*vrfCacheEntry),
+	}
+}
+
+func vrfCacheKey(pubKey []byte, seed []byte) common.Hash {
+	return crypto.Keccak256Hash(pubKey, seed)
+}
+
+func (v *vrfVerifier) cached(key common.Hash, proof []byte) ([32]byte, bool) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if entry, ok := v.cache[key]; ok && bytes.Equal(entry.proof, proof) {
+		return entry.hash, true
+	}
+	return [32]byte{}, false
+}
+
+func (v *vrfVerifier) store(key common.Hash, proof []byte, hash [32]byte) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if _, ok := v.cache[key]; !ok {
+		if len(v.order) >= vrfCacheSize {
+			delete(v.cache, v.order[0])
+			v.order = v.order[1:]
+		}
+		v.order = append(v.order, key)
+	}
+	v.cache[key] = &vrfCacheEntry{proof: append([]byte{}, proof...), hash: hash}
+}
+
+// verify returns the VRF output of the proof, nil verifier checks the proof without caching
+func (v *vrfVerifier) verify(pubKeyData []byte, seed []byte, proof []byte) ([32]byte, error) {
+	var key common.Hash
+	if v != nil {
+		key = vrfCacheKey(pubKeyData, seed)
+		if hash, ok := v.cached(key, proof); ok {
+			return hash, nil
+		}
+	}
+	pubKey, err := crypto.UnmarshalPubkey(pubKeyData)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	verifier, err := p256.NewVRFVerifier(pubKey)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	hash, err := verifier.ProofToHash(seed, proof)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	if v != nil {
+		v.store(key, proof, hash)
+	}
+	return hash, nil
+}
+
+// verifyBatch verifies proofs in parallel, results are in the order of proofs
+func (v *vrfVerifier) verifyBatch(proofs []VrfProof) []VrfResult {
+	results := make([]VrfResult, len(proofs))
+	workers := runtime.NumCPU()
+	if workers > len(proofs) {
+		workers = len(proofs)
+	}
+	indexes := make(chan int, len(proofs))
+	for i := range proofs {
+		indexes <- i
+	}
+	close(indexes)
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for idx := range indexes {
+				proof := proofs[idx]
+				results[idx].Hash, results[idx].Err = v.verify(proof.PubKey, proof.Seed, proof.Proof)
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
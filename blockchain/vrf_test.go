@@ -0,0 +1,46 @@
+package blockchain
+
+import (
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/crypto/vrf/p256"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestVrfVerifier_verifyBatch(t *testing.T) {
+	verifier := newVrfVerifier()
+	var proofs []VrfProof
+	var hashes [][32]byte
+	for i := 0; i < 5; i++ {
+		key, _ := crypto.GenerateKey()
+		signer, err := p256.NewVRFSigner(key)
+		require.NoError(t, err)
+		seed := []byte{byte(i)}
+		hash, proof := signer.Evaluate(seed)
+		proofs = append(proofs, VrfProof{PubKey: crypto.FromECDSAPub(&key.PublicKey), Seed: seed, Proof: proof})
+		hashes = append(hashes, hash)
+	}
+	invalid := proofs[0]
+	invalid.Seed = []byte{0xff}
+	proofs = append(proofs, invalid)
+
+	results := verifier.verifyBatch(proofs)
+	require.Len(t, results, len(proofs))
+	for i, hash := range hashes {
+		require.NoError(t, results[i].Err)
+		require.Equal(t, hash, results[i].Hash)
+	}
+	require.Error(t, results[len(results)-1].Err)
+	require.Len(t, verifier.cache, len(hashes))
+
+	hash, ok := verifier.cached(vrfCacheKey(proofs[1].PubKey, proofs[1].Seed), proofs[1].Proof)
+	require.True(t, ok)
+	require.Equal(t, hashes[1], hash)
+	_, ok = verifier.cached(vrfCacheKey(proofs[1].PubKey, proofs[1].Seed), proofs[2].Proof)
+	require.False(t, ok)
+
+	var noCache *vrfVerifier
+	hash, err := noCache.verify(proofs[2].PubKey, proofs[2].Seed, proofs[2].Proof)
+	require.NoError(t, err)
+	require.Equal(t, hashes[2], hash)
+}
@@ -0,0 +1,240 @@
+// Package client provides typed Go bindings over the node RPC API, results are decoded into the same structs
+// the server uses. The node has no contract API, so bindings cover blocks, identities, txs and subscriptions.
+package client
+
+import (
+	"context"
+	"github.com/idena-network/idena-go/api"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/core/backpressure"
+	"github.com/idena-network/idena-go/core/identity"
+	"github.com/idena-network/idena-go/core/identityproof"
+	"github.com/idena-network/idena-go/core/watchlist"
+	"github.com/idena-network/idena-go/rpc"
+	"github.com/pkg/errors"
+	"time"
+)
+
+type Options struct {
+	ApiKey string
+	// Retries is the number of repeated attempts of idempotent calls failed with transport errors or rejected by the
+	// busy node, the latter are repeated after the delay suggested by the node
+	Retries    int
+	RetryDelay time.Duration
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Retries:    3,
+		RetryDelay: 500 * time.Millisecond,
+	}
+}
+
+type Client struct {
+	rpc  *rpc.Client
+	opts Options
+}
+
+// Dial connects to the node by http, ws or ipc url, subscriptions require ws or ipc connection
+func Dial(ctx context.Context, url string, opts Options) (*Client, error) {
+	c, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(c, opts), nil
+}
+
+// NewClient wraps the connected rpc client, e.g. the in-process one created by rpc.DialInProc
+func NewClient(c *rpc.Client, opts Options) *Client {
+	if opts.ApiKey != "" {
+		c.SetApiKey(opts.ApiKey)
+	}
+	return &Client{c, opts}
+}
+
+func (c *Client) Close() {
+	c.rpc.Close()
+}
+
+// Call invokes the method retrying it on transport errors, it must be used only for idempotent methods
+func (c *Client) Call(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = c.rpc.CallContext(ctx, result, method, args...)
+		if err == nil || attempt >= c.opts.Retries {
+			return err
+		}
+		delay, ok := retryAfter(err)
+		if !ok {
+			if !retryable(err) {
+				return err
+			}
+			delay = c.opts.RetryDelay * time.Duration(attempt+1)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// retryAfter returns the delay suggested by the busy node
+func retryAfter(err error) (time.Duration, bool) {
+	rpcErr, ok := err.(rpc.Error)
+	if !ok || rpcErr.ErrorCode() != backpressure.ErrorCode {
+		return 0, false
+	}
+	dataErr, ok := err.(rpc.DataError)
+	if !ok {
+		return 0, false
+	}
+	data, _ := dataErr.ErrorData().(map[string]interface{})
+	seconds, _ := data["retryAfter"].(float64)
+	return time.Duration(seconds * float64(time.Second)), true
+}
+
+// retryable returns false for errors returned by the server and for cancelled calls
+func retryable(err error) bool {
+	if _, ok := err.(rpc.Error); ok {
+		return false
+	}
+	return err != context.Canceled && err != context.DeadlineExceeded && err != rpc.ErrClientQuit
+}
+
+func (c *Client) LastBlock(ctx context.Context) (*api.Block, error) {
+	var result *api.Block
+	err := c.Call(ctx, &result, "bcn_lastBlock")
+	return result, err
+}
+
+func (c *Client) BlockAt(ctx context.Context, height uint64) (*api.Block, error) {
+	var result *api.Block
+	err := c.Call(ctx, &result, "bcn_blockAt", height)
+	return result, err
+}
+
+func (c *Client) Block(ctx context.Context, hash common.Hash) (*api.Block, error) {
+	var result *api.Block
+	err := c.Call(ctx, &result, "bcn_block", hash)
+	return result, err
+}
+
+func (c *Client) Syncing(ctx context.Context) (api.Syncing, error) {
+	var result api.Syncing
+	err := c.Call(ctx, &result, "bcn_syncing")
+	return result, err
+}
+
+// Transaction returns nil if the tx is unknown
+func (c *Client) Transaction(ctx context.Context, hash common.Hash) (*api.Transaction, error) {
+	var result *api.Transaction
+	err := c.Call(ctx, &result, "bcn_transaction", hash)
+	return result, err
+}
+
+func (c *Client) TxReceipt(ctx context.Context, hash common.Hash) (*api.TxReceipt, error) {
+	var result *api.TxReceipt
+	err := c.Call(ctx, &result, "bcn_txReceipt", hash)
+	return result, err
+}
+
+func (c *Client) Transactions(ctx context.Context, args api.TransactionsArgs) (api.Transactions, error) {
+	var result api.Transactions
+	err := c.Call(ctx, &result, "bcn_transactions", args)
+	return result, err
+}
+
+// SendRawTx sends the signed tx, retries are safe since the same tx can't be applied twice
+func (c *Client) SendRawTx(ctx context.Context, tx hexutil.Bytes) (common.Hash, error) {
+	var result common.Hash
+	err := c.Call(ctx, &result, "bcn_sendRawTx", tx)
+	return result, err
+}
+
+// SendTransaction sends the tx signed by the node key, the call isn't retried since each call takes a new nonce
+func (c *Client) SendTransaction(ctx context.Context, args api.SendTxArgs) (common.Hash, error) {
+	var result common.Hash
+	err := c.rpc.CallContext(ctx, &result, "dna_sendTransaction", args)
+	return result, err
+}
+
+func (c *Client) CoinbaseAddr(ctx context.Context) (common.Address, error) {
+	var result common.Address
+	err := c.Call(ctx, &result, "dna_getCoinbaseAddr")
+	return result, err
+}
+
+func (c *Client) Balance(ctx context.Context, address common.Address) (api.Balance, error) {
+	var result api.Balance
+	err := c.Call(ctx, &result, "dna_getBalance", address)
+	return result, err
+}
+
+func (c *Client) Identity(ctx context.Context, address common.Address) (api.Identity, error) {
+	var result api.Identity
+	err := c.Call(ctx, &result, "dna_identity", address)
+	return result, err
+}
+
+func (c *Client) Identities(ctx context.Context) ([]api.Identity, error) {
+	var result []api.Identity
+	err := c.Call(ctx, &result, "dna_identities")
+	return result, err
+}
+
+// IdentityProof returns the proof of the identity state at the end of the sealed epoch, identityproof.Verify checks it
+func (c *Client) IdentityProof(ctx context.Context, address common.Address, epoch uint16) (*identityproof.Proof, error) {
+	var result *identityproof.Proof
+	err := c.Call(ctx, &result, "dna_identityProof", address, epoch)
+	return result, err
+}
+
+// Sign signs the prefixed message by the unlocked coinbase or keystore account
+func (c *Client) Sign(ctx context.Context, address common.Address, message string) (hexutil.Bytes, error) {
+	var result hexutil.Bytes
+	err := c.Call(ctx, &result, "dna_sign", message, address)
+	return result, err
+}
+
+// UnlockSigning allows Sign to sign messages by the coinbase for the timeout, 0 - until it is locked
+func (c *Client) UnlockSigning(ctx context.Context, timeout time.Duration) error {
+	return c.Call(ctx, nil, "dna_unlockSigning", int64(timeout/time.Second))
+}
+
+// Verify returns the signer of the message signed by Sign
+func (c *Client) Verify(ctx context.Context, signature hexutil.Bytes, message string) (common.Address, error) {
+	var result common.Address
+	err := c.Call(ctx, &result, "dna_verify", signature, message)
+	return result, err
+}
+
+func (c *Client) Epoch(ctx context.Context) (api.Epoch, error) {
+	var result api.Epoch
+	err := c.Call(ctx, &result, "dna_epoch")
+	return result, err
+}
+
+// CeremonyPhases subscribes to phases of the validation ceremony
+func (c *Client) CeremonyPhases(ctx context.Context, ch chan<- *api.CeremonyPhase) (*rpc.ClientSubscription, error) {
+	return c.subscribe(ctx, "dna", ch, "ceremonyPhases")
+}
+
+// IdentityChanges subscribes to identity state changes and penalties
+func (c *Client) IdentityChanges(ctx context.Context, ch chan<- *identity.Change) (*rpc.ClientSubscription, error) {
+	return c.subscribe(ctx, "dna", ch, "identityChanges")
+}
+
+// WatchEvents subscribes to txs and balance changes of addresses watched by the node
+func (c *Client) WatchEvents(ctx context.Context, ch chan<- *watchlist.Event) (*rpc.ClientSubscription, error) {
+	return c.subscribe(ctx, "watch", ch, "events")
+}
+
+func (c *Client) subscribe(ctx context.Context, namespace string, ch interface{}, name string) (*rpc.ClientSubscription, error) {
+	sub, err := c.rpc.Subscribe(ctx, namespace, ch, name)
+	if err == rpc.ErrNotificationsUnsupported {
+		return nil, errors.Wrap(err, "subscriptions require ws or ipc connection")
+	}
+	return sub, err
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"github.com/idena-network/idena-go/api"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/core/backpressure"
+	"github.com/idena-network/idena-go/rpc"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type BlockchainService struct{}
+
+func (BlockchainService) LastBlock() *api.Block {
+	return &api.Block{Hash: common.Hash{0x1}, Height: 10}
+}
+
+var sendAttempts int32
+
+// SendRawTx rejects the first attempt like the busy node does
+func (BlockchainService) SendRawTx(tx hexutil.Bytes) (common.Hash, error) {
+	if atomic.AddInt32(&sendAttempts, 1) == 1 {
+		return common.Hash{}, &backpressure.RetryError{Reasons: []string{backpressure.MempoolReason}}
+	}
+	return common.Hash{0x2}, nil
+}
+
+type DnaService struct{}
+
+func (DnaService) CeremonyPhases(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		notifier.Notify(rpcSub.ID, &api.CeremonyPhase{Seq: 1, Phase: "shortSession", Epoch: 2})
+	}()
+	return rpcSub, nil
+}
+
+func newTestServer(t *testing.T) *rpc.Server {
+	server := rpc.NewServer("key")
+	require.NoError(t, server.RegisterName("bcn", BlockchainService{}))
+	require.NoError(t, server.RegisterName("dna", DnaService{}))
+	return server
+}
+
+func TestClient_Call(t *testing.T) {
+	server := newTestServer(t)
+	var requests int32
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		server.ServeHTTP(w, r)
+	}))
+	defer httpServer.Close()
+
+	ctx := context.Background()
+	c, err := Dial(ctx, httpServer.URL, Options{ApiKey: "key", Retries: 2, RetryDelay: time.Millisecond})
+	require.NoError(t, err)
+	defer c.Close()
+
+	block, err := c.LastBlock(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), block.Height)
+	require.Equal(t, common.Hash{0x1}, block.Hash)
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// errors of the server aren't retried
+	wrongKey, err := Dial(ctx, httpServer.URL, Options{ApiKey: "wrong", Retries: 2, RetryDelay: time.Millisecond})
+	require.NoError(t, err)
+	_, err = wrongKey.LastBlock(ctx)
+	require.Error(t, err)
+	require.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	_, err = c.CeremonyPhases(ctx, make(chan *api.CeremonyPhase))
+	require.Error(t, err)
+}
+
+func TestClient_SendRawTx(t *testing.T) {
+	ctx := context.Background()
+	c := NewClient(rpc.DialInProc(newTestServer(t)), Options{ApiKey: "key"})
+	defer c.Close()
+	_, err := c.SendRawTx(ctx, hexutil.Bytes{0x1})
+	require.Error(t, err)
+	rpcErr, ok := err.(rpc.Error)
+	require.True(t, ok)
+	require.Equal(t, backpressure.ErrorCode, rpcErr.ErrorCode())
+	data := err.(rpc.DataError).ErrorData().(map[string]interface{})
+	require.Equal(t, []interface{}{backpressure.MempoolReason}, data["reasons"])
+
+	atomic.StoreInt32(&sendAttempts, 0)
+	retrying := NewClient(rpc.DialInProc(newTestServer(t)), Options{ApiKey: "key", Retries: 1})
+	defer retrying.Close()
+	hash, err := retrying.SendRawTx(ctx, hexutil.Bytes{0x1})
+	require.NoError(t, err)
+	require.Equal(t, common.Hash{0x2}, hash)
+	require.Equal(t, int32(2), atomic.LoadInt32(&sendAttempts))
+}
+
+func TestClient_CeremonyPhases(t *testing.T) {
+	c := NewClient(rpc.DialInProc(newTestServer(t)), Options{ApiKey: "key"})
+	defer c.Close()
+
+	phases := make(chan *api.CeremonyPhase, 1)
+	sub, err := c.CeremonyPhases(context.Background(), phases)
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+	select {
+	case phase := <-phases:
+		require.Equal(t, "shortSession", phase.Phase)
+		require.Equal(t, uint16(2), phase.Epoch)
+	case err := <-sub.Err():
+		t.Fatal(err)
+	case <-time.After(time.Second * 5):
+		t.Fatal("phase isn't received")
+	}
+}
@@ -0,0 +1,202 @@
+// Package bandwidth throttles network traffic of the node with the total and per-subsystem caps.
+package bandwidth
+
+import (
+	"github.com/idena-network/idena-go/config"
+	"github.com/rcrowley/go-metrics"
+	"io"
+	"sync"
+	"time"
+)
+
+type Subsystem string
+
+const (
+	Sync   Subsystem = "sync"
+	Flips  Subsystem = "flips"
+	Gossip Subsystem = "gossip"
+
+	kib = 1024
+)
+
+var subsystems = []Subsystem{Sync, Flips, Gossip}
+
+// limiter is a token bucket with a burst of one second of traffic, a request larger than the bucket is served
+// immediately and is paid back by the following requests
+type limiter struct {
+	mutex  sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func (l *limiter) setLimit(kibPerSec int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.rate = float64(kibPerSec * kib)
+	l.tokens = l.rate
+	l.last = time.Now()
+}
+
+func (l *limiter) limit() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return int(l.rate) / kib
+}
+
+func (l *limiter) reserve(n int) time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.rate <= 0 {
+		return 0
+	}
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.rate {
+		l.tokens = l.rate
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+type direction struct {
+	limiter limiter
+	meter   metrics.Meter
+}
+
+type channel struct {
+	upload   direction
+	download direction
+}
+
+func newChannel(limit config.BandwidthLimit) *channel {
+	c := &channel{
+		upload:   direction{meter: metrics.NewMeter()},
+		download: direction{meter: metrics.NewMeter()},
+	}
+	c.setLimit(limit)
+	return c
+}
+
+func (c *channel) setLimit(limit config.BandwidthLimit) {
+	c.upload.limiter.setLimit(limit.MaxUpload)
+	c.download.limiter.setLimit(limit.MaxDownload)
+}
+
+func (c *channel) usage() Usage {
+	return Usage{
+		Uploaded:     uint64(c.upload.meter.Count()),
+		Downloaded:   uint64(c.download.meter.Count()),
+		UploadRate:   c.upload.meter.Rate1() / kib,
+		DownloadRate: c.download.meter.Rate1() / kib,
+		MaxUpload:    c.upload.limiter.limit(),
+		MaxDownload:  c.download.limiter.limit(),
+	}
+}
+
+// Scheduler throttles traffic by the total and subsystem caps, the nil scheduler doesn't limit anything
+type Scheduler struct {
+	total      *channel
+	subsystems map[Subsystem]*channel
+}
+
+func NewScheduler(cfg *config.BandwidthConfig) *Scheduler {
+	return &Scheduler{
+		total: newChannel(cfg.Total),
+		subsystems: map[Subsystem]*channel{
+			Sync:   newChannel(cfg.Sync),
+			Flips:  newChannel(cfg.Flips),
+			Gossip: newChannel(cfg.Gossip),
+		},
+	}
+}
+
+// SetLimits applies new caps, the traffic already accounted is kept
+func (s *Scheduler) SetLimits(cfg *config.BandwidthConfig) {
+	s.total.setLimit(cfg.Total)
+	s.subsystems[Sync].setLimit(cfg.Sync)
+	s.subsystems[Flips].setLimit(cfg.Flips)
+	s.subsystems[Gossip].setLimit(cfg.Gossip)
+}
+
+// WaitUpload accounts n bytes to be sent and blocks until the caps allow sending them
+func (s *Scheduler) WaitUpload(subsystem Subsystem, n int) {
+	if s == nil || n <= 0 {
+		return
+	}
+	c := s.subsystems[subsystem]
+	wait(n, &s.total.upload, &c.upload)
+}
+
+// WaitDownload accounts n received bytes and blocks until the caps allow receiving more
+func (s *Scheduler) WaitDownload(subsystem Subsystem, n int) {
+	if s == nil || n <= 0 {
+		return
+	}
+	c := s.subsystems[subsystem]
+	wait(n, &s.total.download, &c.download)
+}
+
+func wait(n int, directions ...*direction) {
+	var delay time.Duration
+	for _, d := range directions {
+		d.meter.Mark(int64(n))
+		if dl := d.limiter.reserve(n); dl > delay {
+			delay = dl
+		}
+	}
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// Reader throttles reading from r as the download of the subsystem
+func (s *Scheduler) Reader(subsystem Subsystem, r io.Reader) io.Reader {
+	if s == nil {
+		return r
+	}
+	return &reader{r: r, s: s, subsystem: subsystem}
+}
+
+type reader struct {
+	r         io.Reader
+	s         *Scheduler
+	subsystem Subsystem
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.s.WaitDownload(r.subsystem, n)
+	return n, err
+}
+
+type Usage struct {
+	// Uploaded and Downloaded are total bytes since the start
+	Uploaded   uint64 `json:"uploaded"`
+	Downloaded uint64 `json:"downloaded"`
+	// UploadRate and DownloadRate are one minute average rates in KiB/s
+	UploadRate   float64 `json:"uploadRate"`
+	DownloadRate float64 `json:"downloadRate"`
+	MaxUpload    int     `json:"maxUpload"`
+	MaxDownload  int     `json:"maxDownload"`
+}
+
+type Stats struct {
+	Total      Usage               `json:"total"`
+	Subsystems map[Subsystem]Usage `json:"subsystems"`
+}
+
+func (s *Scheduler) Stats() Stats {
+	result := Stats{
+		Total:      s.total.usage(),
+		Subsystems: make(map[Subsystem]Usage, len(subsystems)),
+	}
+	for _, subsystem := range subsystems {
+		result.Subsystems[subsystem] = s.subsystems[subsystem].usage()
+	}
+	return result
+}
@@ -0,0 +1,41 @@
+package bandwidth
+
+import (
+	"github.com/idena-network/idena-go/config"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestLimiter_Reserve(t *testing.T) {
+	l := new(limiter)
+	require.Zero(t, l.reserve(1<<30))
+
+	l.setLimit(100)
+	require.Zero(t, l.reserve(50*kib))
+	require.Zero(t, l.reserve(50*kib))
+	delay := l.reserve(50 * kib)
+	require.True(t, delay > time.Millisecond*400 && delay <= time.Millisecond*500, delay)
+}
+
+func TestScheduler_Stats(t *testing.T) {
+	s := NewScheduler(&config.BandwidthConfig{Flips: config.BandwidthLimit{MaxUpload: 10}})
+	s.WaitUpload(Gossip, 100)
+	s.WaitUpload(Flips, 200)
+	s.WaitDownload(Sync, 300)
+
+	stats := s.Stats()
+	require.Equal(t, uint64(300), stats.Total.Uploaded)
+	require.Equal(t, uint64(300), stats.Total.Downloaded)
+	require.Equal(t, uint64(200), stats.Subsystems[Flips].Uploaded)
+	require.Equal(t, 10, stats.Subsystems[Flips].MaxUpload)
+	require.Zero(t, stats.Total.MaxUpload)
+
+	s.SetLimits(&config.BandwidthConfig{Total: config.BandwidthLimit{MaxDownload: 5}})
+	stats = s.Stats()
+	require.Equal(t, 5, stats.Total.MaxDownload)
+	require.Zero(t, stats.Subsystems[Flips].MaxUpload)
+
+	var nilScheduler *Scheduler
+	nilScheduler.WaitUpload(Sync, 100)
+}
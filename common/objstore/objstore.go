@@ -0,0 +1,18 @@
+// Package objstore stores node artifacts outside of the datadir in a local archive directory or an S3-compatible
+// object storage
+package objstore
+
+import "github.com/pkg/errors"
+
+var ErrNotFound = errors.New("object not found")
+
+// Store is the flat key-value object storage, keys are slash separated paths
+type Store interface {
+	Put(key string, data []byte) error
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+	// List returns sorted keys with the prefix
+	List(prefix string) ([]string, error)
+	// Name is used in logs and API responses
+	Name() string
+}
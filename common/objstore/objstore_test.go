@@ -0,0 +1,108 @@
+package objstore
+
+import (
+	"encoding/xml"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func testStore(t *testing.T, store Store) {
+	require := require.New(t)
+
+	_, err := store.Get("a/1")
+	require.Equal(ErrNotFound, err)
+
+	require.NoError(store.Put("a/1", []byte{0x1}))
+	require.NoError(store.Put("a/2", []byte{0x2}))
+	require.NoError(store.Put("b/1", []byte{0x3}))
+
+	data, err := store.Get("a/2")
+	require.NoError(err)
+	require.Equal([]byte{0x2}, data)
+
+	keys, err := store.List("a/")
+	require.NoError(err)
+	require.Equal([]string{"a/1", "a/2"}, keys)
+
+	require.NoError(store.Delete("a/1"))
+	require.NoError(store.Delete("a/1"))
+	keys, err = store.List("")
+	require.NoError(err)
+	require.Equal([]string{"a/2", "b/1"}, keys)
+}
+
+func TestFileStore(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "objstore")
+	defer os.RemoveAll(dir)
+	testStore(t, NewFileStore(dir))
+}
+
+type fakeS3 struct {
+	mutex   sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") || r.Header.Get("x-amz-content-sha256") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if r.URL.Path == "/bucket" {
+		var result listBucketResult
+		var keys []string
+		for key := range s.objects {
+			if strings.HasPrefix(key, r.URL.Query().Get("prefix")) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			result.Contents = append(result.Contents, struct{ Key string }{key})
+		}
+		xml.NewEncoder(w).Encode(result)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+	switch r.Method {
+	case http.MethodPut:
+		s.objects[key], _ = ioutil.ReadAll(r.Body)
+	case http.MethodGet:
+		data, ok := s.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	fake := &fakeS3{objects: make(map[string][]byte)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	store, err := NewS3Store(S3Options{Endpoint: server.URL, Bucket: "bucket", Prefix: "node/", AccessKey: "key", SecretKey: "secret"})
+	require.NoError(t, err)
+	testStore(t, store)
+	require.Len(t, fake.objects, 2)
+	require.Contains(t, fake.objects, "node/b/1")
+
+	unsigned, _ := NewS3Store(S3Options{Endpoint: server.URL, Bucket: "bucket"})
+	require.Error(t, unsigned.Put("a", []byte{0x1}))
+}
+
+func TestUriEncode(t *testing.T) {
+	require.Equal(t, "a-b_c.d~e%20f%2Fg%2B", uriEncode("a-b_c.d~e f/g+"))
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"github.com/idena-network/idena-go/common/objstore"
+	"path/filepath"
+	"time"
+)
+
+const defaultAncientDir = "ancient"
+
+// AncientConfig configures moving bodies and certificates of old canonical blocks out of the datadir, headers and
+// indexes are kept in the chain database
+type AncientConfig struct {
+	Enabled bool
+	// KeepEpochs is the number of past epochs kept in the datadir in addition to the current one
+	KeepEpochs uint16
+	// SegmentSize is the number of blocks stored in one object, it can't be changed once blocks are archived
+	SegmentSize uint64
+	Interval    time.Duration
+	// CacheSegments is the number of recently read segments kept in memory
+	CacheSegments int
+	// Dir is the filesystem archive, <datadir>/ancient if not set. It isn't used if S3 is set
+	Dir string
+	S3  *ObjectStoreConfig `json:",omitempty"`
+}
+
+func GetDefaultAncientConfig() *AncientConfig {
+	return &AncientConfig{
+		KeepEpochs:    3,
+		SegmentSize:   1000,
+		Interval:      time.Hour,
+		CacheSegments: 8,
+	}
+}
+
+func (c *AncientConfig) NewStore(dataDir string) (objstore.Store, error) {
+	if c.S3 != nil {
+		return c.S3.NewStore()
+	}
+	dir := c.Dir
+	if dir == "" {
+		dir = filepath.Join(dataDir, defaultAncientDir)
+	}
+	return objstore.NewFileStore(dir), nil
+}
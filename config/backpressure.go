@@ -0,0 +1,24 @@
+package config
+
+import "time"
+
+// BackpressureConfig sets when bcn_sendRawTx rejects txs with retry-after errors instead of accepting them into the
+// pool which is about to evict them, zero thresholds disable the check
+type BackpressureConfig struct {
+	Enabled bool
+	// MempoolThreshold is the share of the pool tx and byte limits starting from which the pool is saturated
+	MempoolThreshold float64
+	// MaxImportLag is the number of blocks the head can be behind the highest known block
+	MaxImportLag uint64
+	// RetryAfter is suggested to clients as the delay before the next attempt
+	RetryAfter time.Duration
+}
+
+func GetDefaultBackpressureConfig() *BackpressureConfig {
+	return &BackpressureConfig{
+		Enabled:          true,
+		MempoolThreshold: 0.9,
+		MaxImportLag:     10,
+		RetryAfter:       20 * time.Second,
+	}
+}
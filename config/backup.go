@@ -0,0 +1,58 @@
+package config
+
+import (
+	"github.com/idena-network/idena-go/common/objstore"
+	"github.com/pkg/errors"
+	"path/filepath"
+	"time"
+)
+
+const defaultBackupDir = "backups"
+
+// BackupConfig configures encrypted backups of the node key, the API key and pending ceremony artifacts
+type BackupConfig struct {
+	Enabled bool
+	// Interval between scheduled backups, backups are also made when the flip lottery and the long session start
+	Interval time.Duration
+	// Dir is the archive directory, <datadir>/backups if not set
+	Dir string
+	// Keep is the number of the latest backups kept in every store, 0 - all
+	Keep int
+	// PasswordFile contains the passphrase backups are encrypted with
+	PasswordFile string
+	// S3 is set to upload backups to the S3-compatible storage in addition to the archive directory
+	S3 *ObjectStoreConfig `json:",omitempty"`
+}
+
+func GetDefaultBackupConfig() *BackupConfig {
+	return &BackupConfig{
+		Interval: time.Hour * 6,
+		Keep:     10,
+	}
+}
+
+// Password returns the passphrase set by IDENA_BACKUP_PASSWORD or read from PasswordFile
+func (c *BackupConfig) Password() (string, error) {
+	password, ok, err := readSecret(BackupPasswordEnv, c.PasswordFile)
+	if !ok {
+		return "", errors.New("backup password is not set")
+	}
+	return password, err
+}
+
+// Stores returns the archive directory store followed by the S3 store if it is configured
+func (c *BackupConfig) Stores(dataDir string) ([]objstore.Store, error) {
+	dir := c.Dir
+	if dir == "" {
+		dir = filepath.Join(dataDir, defaultBackupDir)
+	}
+	stores := []objstore.Store{objstore.NewFileStore(dir)}
+	if c.S3 != nil {
+		store, err := c.S3.NewStore()
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid backup S3 config")
+		}
+		stores = append(stores, store)
+	}
+	return stores, nil
+}
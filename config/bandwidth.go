@@ -0,0 +1,22 @@
+package config
+
+// BandwidthLimit caps traffic in KiB/s, 0 - unlimited
+type BandwidthLimit struct {
+	MaxUpload   int
+	MaxDownload int
+}
+
+type BandwidthConfig struct {
+	// Total caps the traffic of all subsystems together
+	Total BandwidthLimit
+	// Sync caps blocks and manifests exchange
+	Sync BandwidthLimit
+	// Flips caps flips sent over gossip and loaded from IPFS
+	Flips BandwidthLimit
+	// Gossip caps transactions, votes, proposals and other gossip messages
+	Gossip BandwidthLimit
+}
+
+func GetDefaultBandwidthConfig() *BandwidthConfig {
+	return &BandwidthConfig{}
+}
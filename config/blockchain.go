@@ -4,4 +4,6 @@ type BlockchainConfig struct {
 	// distance between blocks with permanent certificates
 	StoreCertRange uint64
 	BurnTxRange    uint64
+	// CertIndex enables indexing certificates of all blocks by height, otherwise only permanent and recent certificates are kept
+	CertIndex bool
 }
@@ -12,8 +12,10 @@ import (
 	"github.com/pkg/errors"
 	"github.com/urfave/cli"
 	"io/ioutil"
+	"net"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -36,6 +38,32 @@ type Config struct {
 	OfflineDetection *OfflineDetectionConfig
 	Blockchain       *BlockchainConfig
 	Mempool          *Mempool
+	TimeSync         *TimeSyncConfig
+	FlipPrefetch     *FlipPrefetchConfig
+	FlipSubmission   *FlipSubmissionConfig
+	IpfsGc           *IpfsGcConfig
+	OnlineKeeper     *OnlineKeeperConfig
+	IdentityEvents   *IdentityEventsConfig
+	EpochReport      *EpochReportConfig
+	Watch            *WatchConfig
+	ForkMonitor      *ForkMonitorConfig
+	PenaltyMonitor   *PenaltyMonitorConfig
+	Invites          *InvitesConfig
+	HardForks        *HardForksConfig
+	Log              *LogConfig
+	Bandwidth        *BandwidthConfig
+	Mirror           *MirrorConfig
+	Dev              *DevConfig
+	Backup           *BackupConfig
+	Ancient          *AncientConfig
+	Health           *HealthConfig
+	Secrets          *SecretsConfig
+	Relay            *RelayConfig
+	Storage          *StorageConfig
+	IdentityProof    *IdentityProofConfig
+	Backpressure     *BackpressureConfig
+	// PrivateNetwork is set for nodes of private networks, nil - public network
+	PrivateNetwork *PrivateNetworkConfig `json:",omitempty"`
 }
 
 func (c *Config) ProvideNodeKey(key string, password string, withBackup bool) error {
@@ -81,6 +109,12 @@ func (c *Config) ProvideNodeKey(key string, password string, withBackup bool) er
 }
 
 func (c *Config) NodeKey() *ecdsa.PrivateKey {
+	if key, ok, err := c.ExternalNodeKey(); ok {
+		if err != nil {
+			log.Crit(fmt.Sprintf("Failed to load node key: %v", err))
+		}
+		return key
+	}
 	// Generate ephemeral key if no datadir is being used.
 	if c.DataDir == "" {
 		key, err := crypto.GenerateKey()
@@ -111,6 +145,15 @@ func (c *Config) NodeKey() *ecdsa.PrivateKey {
 	return key
 }
 
+// StoredNodeKey loads the node key of the datadir, nil is returned if the key doesn't exist
+func (c *Config) StoredNodeKey() *ecdsa.PrivateKey {
+	key, err := crypto.LoadECDSA(filepath.Join(c.DataDir, "keystore", datadirPrivateKey))
+	if err != nil {
+		return nil
+	}
+	return key
+}
+
 // NodeDB returns the path to the discovery node database.
 func (c *Config) NodeDB() string {
 	if c.DataDir == "" {
@@ -128,15 +171,22 @@ func (c *Config) KeyStoreDataDir() (string, error) {
 	return instanceDir, nil
 }
 
+// SetApiKey sets the key provided by the environment or the secret file which overrides the configured one like on
+// config reload, otherwise the configured, saved or generated key is used and saved to the datadir
 func (c *Config) SetApiKey() error {
+	if key, ok, err := c.ExternalApiKey(); err != nil {
+		return err
+	} else if ok {
+		c.RPC.APIKey = key
+		return nil
+	}
 	shouldSaveKey := true
 	if c.RPC.APIKey == "" {
 		apiKeyFile := filepath.Join(c.DataDir, apiKeyFileName)
 		data, _ := ioutil.ReadFile(apiKeyFile)
 		key := string(data)
 		if key == "" {
-			randomKey, _ := crypto.GenerateKey()
-			key = hex.EncodeToString(crypto.FromECDSA(randomKey)[:16])
+			key = generateApiKey()
 		} else {
 			shouldSaveKey = false
 		}
@@ -144,17 +194,26 @@ func (c *Config) SetApiKey() error {
 	}
 
 	if shouldSaveKey {
-		f, err := os.OpenFile(filepath.Join(c.DataDir, apiKeyFileName), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
-		_, err = f.WriteString(c.RPC.APIKey)
-		return err
+		return c.saveApiKey()
 	}
 	return nil
 }
 
+func generateApiKey() string {
+	randomKey, _ := crypto.GenerateKey()
+	return hex.EncodeToString(crypto.FromECDSA(randomKey)[:16])
+}
+
+func (c *Config) saveApiKey() error {
+	f, err := os.OpenFile(filepath.Join(c.DataDir, apiKeyFileName), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(c.RPC.APIKey)
+	return err
+}
+
 func MakeMobileConfig(path string, cfg string) (*Config, error) {
 	conf := getDefaultConfig(filepath.Join(path, DefaultDataDir))
 
@@ -178,7 +237,9 @@ func MakeConfig(ctx *cli.Context) (*Config, error) {
 		return nil, err
 	}
 
-	applyFlags(ctx, cfg)
+	if err := applyFlags(ctx, cfg); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
@@ -191,6 +252,7 @@ func applyProfile(ctx *cli.Context, cfg *Config) {
 		cfg.IpfsConf.GracePeriod = "30s"
 		cfg.IpfsConf.ReproviderInterval = "0"
 		cfg.IpfsConf.Routing = "dhtclient"
+		cfg.FlipPrefetch.Parallelism = LowPowerFlipPrefetchParallelism
 	} else {
 		if cfg.IpfsConf.LowWater == 0 {
 			cfg.IpfsConf.LowWater = 30
@@ -236,6 +298,7 @@ func getDefaultConfig(dataDir string) *Config {
 			MaxInboundPeers:  DefaultMaxInboundPeers,
 			MaxOutboundPeers: DefaultMaxOutboundPeers,
 			CollectMetrics:   false,
+			DnsSeedsRefresh:  time.Minute * 30,
 		},
 		Consensus: GetDefaultConsensusConfig(),
 		RPC:       rpc.GetDefaultRPCConfig(DefaultRpcHost, DefaultRpcPort),
@@ -246,51 +309,442 @@ func getDefaultConfig(dataDir string) *Config {
 		IpfsConf:   ipfsConfig,
 		Validation: &ValidationConfig{},
 		Sync: &SyncConfig{
-			FastSync:      true,
-			ForceFullSync: DefaultForceFullSync,
+			FastSync:        true,
+			ForceFullSync:   DefaultForceFullSync,
+			Checkpoints:     append([]Checkpoint{}, DefaultCheckpoints...),
+			ManifestSources: DefaultManifestSources,
 		},
 		OfflineDetection: GetDefaultOfflineDetectionConfig(),
 		Blockchain: &BlockchainConfig{
 			StoreCertRange: DefaultStoreCertRange,
 			BurnTxRange:    DefaultBurntTxRange,
 		},
-		Mempool: GetDefaultMempoolConfig(),
+		Mempool:        GetDefaultMempoolConfig(),
+		TimeSync:       GetDefaultTimeSyncConfig(),
+		FlipPrefetch:   GetDefaultFlipPrefetchConfig(),
+		FlipSubmission: GetDefaultFlipSubmissionConfig(),
+		IpfsGc:         GetDefaultIpfsGcConfig(),
+		OnlineKeeper:   GetDefaultOnlineKeeperConfig(),
+		IdentityEvents: GetDefaultIdentityEventsConfig(),
+		EpochReport:    GetDefaultEpochReportConfig(),
+		Watch:          GetDefaultWatchConfig(),
+		ForkMonitor:    GetDefaultForkMonitorConfig(),
+		PenaltyMonitor: GetDefaultPenaltyMonitorConfig(),
+		Invites:        GetDefaultInvitesConfig(),
+		HardForks:      GetDefaultHardForksConfig(),
+		Log:            GetDefaultLogConfig(),
+		Bandwidth:      GetDefaultBandwidthConfig(),
+		Mirror:         GetDefaultMirrorConfig(),
+		Dev:            GetDefaultDevConfig(),
+		Backup:         GetDefaultBackupConfig(),
+		Ancient:        GetDefaultAncientConfig(),
+		Health:         GetDefaultHealthConfig(),
+		Secrets:        &SecretsConfig{},
+		Relay:          GetDefaultRelayConfig(),
+		Storage:        GetDefaultStorageConfig(),
+		IdentityProof:  GetDefaultIdentityProofConfig(),
+		Backpressure:   GetDefaultBackpressureConfig(),
 	}
 }
 
-func applyFlags(ctx *cli.Context, cfg *Config) {
+func applyFlags(ctx *cli.Context, cfg *Config) error {
 	if ctx.IsSet(DataDirFlag.Name) {
 		cfg.DataDir = ctx.String(DataDirFlag.Name)
 	}
 	applyProfile(ctx, cfg)
+	applyLogFlags(ctx, cfg)
 	applyP2PFlags(ctx, cfg)
+	applyBandwidthFlags(ctx, cfg)
+	applyMirrorFlags(ctx, cfg)
 	applyConsensusFlags(ctx, cfg)
 	applyRpcFlags(ctx, cfg)
+	applySecretsFlags(ctx, cfg)
 	applyGenesisFlags(ctx, cfg)
 	applyIpfsFlags(ctx, cfg)
 	applyValidationFlags(ctx, cfg)
-	applySyncFlags(ctx, cfg)
+	applyTimeSyncFlags(ctx, cfg)
+	applyFlipPrefetchFlags(ctx, cfg)
+	applyIpfsGcFlags(ctx, cfg)
+	applyOnlineKeeperFlags(ctx, cfg)
+	applyForkMonitorFlags(ctx, cfg)
+	applyPenaltyMonitorFlags(ctx, cfg)
+	applyBlockchainFlags(ctx, cfg)
+	applyBackupFlags(ctx, cfg)
+	applyAncientFlags(ctx, cfg)
+	applyRelayFlags(ctx, cfg)
+	applyStorageFlags(ctx, cfg)
+	if err := applyMempoolFlags(ctx, cfg); err != nil {
+		return err
+	}
+	if err := applyIdentityEventsFlags(ctx, cfg); err != nil {
+		return err
+	}
+	if err := applyEpochReportFlags(ctx, cfg); err != nil {
+		return err
+	}
+	if err := applyWatchFlags(ctx, cfg); err != nil {
+		return err
+	}
+	if err := applyHardForksFlags(ctx, cfg); err != nil {
+		return err
+	}
+	if err := applySyncFlags(ctx, cfg); err != nil {
+		return err
+	}
+	if err := applyPrivateNetworkFlags(ctx, cfg); err != nil {
+		return err
+	}
+	if err := applyDevFlags(ctx, cfg); err != nil {
+		return err
+	}
+	return cfg.Consensus.ValidateTimings(cfg.Dev.Enabled || cfg.PrivateNetwork != nil)
+}
+
+func applyPrivateNetworkFlags(ctx *cli.Context, cfg *Config) error {
+	if ctx.IsSet(PrivateNetworkFlag.Name) {
+		privateNetwork, err := LoadPrivateNetworkConfig(ctx.String(PrivateNetworkFlag.Name))
+		if err != nil {
+			return err
+		}
+		cfg.PrivateNetwork = privateNetwork
+	}
+	if cfg.PrivateNetwork != nil {
+		return applyPrivateNetwork(cfg)
+	}
+	return nil
+}
+
+func applyDevFlags(ctx *cli.Context, cfg *Config) error {
+	if ctx.IsSet(DevFlag.Name) {
+		cfg.Dev.Enabled = ctx.Bool(DevFlag.Name)
+	}
+	if ctx.IsSet(DevBlockTimeFlag.Name) {
+		cfg.Dev.BlockTime = ctx.Duration(DevBlockTimeFlag.Name)
+	}
+	if ctx.IsSet(DevEpochFlag.Name) {
+		cfg.Dev.EpochDuration = ctx.Duration(DevEpochFlag.Name)
+	}
+	if ctx.IsSet(DevAllocFlag.Name) {
+		if cfg.GenesisConf.Alloc == nil {
+			cfg.GenesisConf.Alloc = make(map[common.Address]GenesisAllocation)
+		}
+		for _, value := range ctx.StringSlice(DevAllocFlag.Name) {
+			addr, balance, err := ParseDevAlloc(value)
+			if err != nil {
+				return err
+			}
+			cfg.GenesisConf.Alloc[addr] = GenesisAllocation{Balance: balance}
+		}
+	}
+	if cfg.Dev.Enabled {
+		applyDevMode(cfg)
+	}
+	return nil
+}
+
+func applyFlipPrefetchFlags(ctx *cli.Context, cfg *Config) {
+	if ctx.IsSet(FlipPrefetchParallelismFlag.Name) {
+		cfg.FlipPrefetch.Parallelism = ctx.Int(FlipPrefetchParallelismFlag.Name)
+	}
+	if ctx.IsSet(FlipPrefetchBandwidthFlag.Name) {
+		cfg.FlipPrefetch.BandwidthLimit = ctx.Int64(FlipPrefetchBandwidthFlag.Name) * 1024
+	}
+}
+
+func applyOnlineKeeperFlags(ctx *cli.Context, cfg *Config) {
+	if ctx.IsSet(OnlineKeeperFlag.Name) {
+		cfg.OnlineKeeper.Enabled = ctx.Bool(OnlineKeeperFlag.Name)
+	}
+	if ctx.IsSet(OnlineKeeperLockFlag.Name) {
+		cfg.OnlineKeeper.LockFile = ctx.String(OnlineKeeperLockFlag.Name)
+	}
+}
+
+func applyBackupFlags(ctx *cli.Context, cfg *Config) {
+	if ctx.IsSet(BackupFlag.Name) {
+		cfg.Backup.Enabled = ctx.Bool(BackupFlag.Name)
+	}
+	if ctx.IsSet(BackupDirFlag.Name) {
+		cfg.Backup.Dir = ctx.String(BackupDirFlag.Name)
+	}
+	if ctx.IsSet(BackupPasswordFileFlag.Name) {
+		cfg.Backup.PasswordFile = ctx.String(BackupPasswordFileFlag.Name)
+	}
+}
+
+func applyAncientFlags(ctx *cli.Context, cfg *Config) {
+	if ctx.IsSet(AncientFlag.Name) {
+		cfg.Ancient.Enabled = ctx.Bool(AncientFlag.Name)
+	}
+	if ctx.IsSet(AncientKeepEpochsFlag.Name) {
+		cfg.Ancient.KeepEpochs = uint16(ctx.Uint(AncientKeepEpochsFlag.Name))
+	}
+	if ctx.IsSet(AncientDirFlag.Name) {
+		cfg.Ancient.Dir = ctx.String(AncientDirFlag.Name)
+	}
+}
+
+func applyBlockchainFlags(ctx *cli.Context, cfg *Config) {
+	if ctx.IsSet(CertIndexFlag.Name) {
+		cfg.Blockchain.CertIndex = ctx.Bool(CertIndexFlag.Name)
+	}
+}
+
+func applyForkMonitorFlags(ctx *cli.Context, cfg *Config) {
+	if ctx.IsSet(ForkAlertThresholdFlag.Name) {
+		cfg.ForkMonitor.AlertThreshold = ctx.Uint64(ForkAlertThresholdFlag.Name)
+	}
+	if ctx.IsSet(ForkWebhookFlag.Name) {
+		cfg.ForkMonitor.Webhook = ctx.String(ForkWebhookFlag.Name)
+	}
+}
+
+func applyRelayFlags(ctx *cli.Context, cfg *Config) {
+	if ctx.IsSet(RelayFlag.Name) {
+		cfg.Relay.Source = ctx.String(RelayFlag.Name)
+	}
+	if ctx.IsSet(RelayMirrorFlag.Name) {
+		cfg.Relay.Mirror = ctx.String(RelayMirrorFlag.Name)
+	}
+	if ctx.IsSet(RelayDataFlag.Name) {
+		cfg.Relay.Data = splitList(ctx.String(RelayDataFlag.Name))
+	}
+}
+
+func applyStorageFlags(ctx *cli.Context, cfg *Config) {
+	if ctx.IsSet(StorageBudgetFlag.Name) {
+		cfg.Storage.Budget = ctx.Uint64(StorageBudgetFlag.Name) * 1024 * 1024
+	}
+}
+
+func applyPenaltyMonitorFlags(ctx *cli.Context, cfg *Config) {
+	if ctx.IsSet(PenaltyWebhookFlag.Name) {
+		cfg.PenaltyMonitor.Webhook = ctx.String(PenaltyWebhookFlag.Name)
+	}
+	if ctx.IsSet(PenaltyOfflineThresholdFlag.Name) {
+		cfg.PenaltyMonitor.OfflineThreshold = ctx.Duration(PenaltyOfflineThresholdFlag.Name)
+	}
+}
+
+func applyIdentityEventsFlags(ctx *cli.Context, cfg *Config) error {
+	if ctx.IsSet(IdentityWebhookFlag.Name) {
+		cfg.IdentityEvents.Webhooks = ctx.StringSlice(IdentityWebhookFlag.Name)
+	}
+	if ctx.IsSet(IdentityWebhookSecretFlag.Name) {
+		cfg.IdentityEvents.WebhookSecret = ctx.String(IdentityWebhookSecretFlag.Name)
+	}
+	if ctx.IsSet(IdentityWatchFlag.Name) {
+		for _, value := range ctx.StringSlice(IdentityWatchFlag.Name) {
+			if !common.IsHexAddress(value) {
+				return errors.Errorf("invalid watched identity address %v", value)
+			}
+			cfg.IdentityEvents.Addresses = append(cfg.IdentityEvents.Addresses, common.HexToAddress(value))
+		}
+	}
+	return nil
+}
+
+func applyEpochReportFlags(ctx *cli.Context, cfg *Config) error {
+	if ctx.IsSet(EpochReportWatchFlag.Name) {
+		for _, value := range ctx.StringSlice(EpochReportWatchFlag.Name) {
+			if !common.IsHexAddress(value) {
+				return errors.Errorf("invalid epoch report address %v", value)
+			}
+			cfg.EpochReport.Addresses = append(cfg.EpochReport.Addresses, common.HexToAddress(value))
+		}
+	}
+	return nil
+}
+
+func applyWatchFlags(ctx *cli.Context, cfg *Config) error {
+	if ctx.IsSet(WatchAddressFlag.Name) {
+		for _, value := range ctx.StringSlice(WatchAddressFlag.Name) {
+			if !common.IsHexAddress(value) {
+				return errors.Errorf("invalid watched address %v", value)
+			}
+			cfg.Watch.Addresses = append(cfg.Watch.Addresses, common.HexToAddress(value))
+		}
+	}
+	return nil
+}
+
+func applyLogFlags(ctx *cli.Context, cfg *Config) {
+	if ctx.IsSet(VerbosityFlag.Name) {
+		cfg.Log.Verbosity = ctx.Int(VerbosityFlag.Name)
+	}
+	if ctx.IsSet(LogVmoduleFlag.Name) {
+		cfg.Log.Vmodule = ctx.String(LogVmoduleFlag.Name)
+	}
+}
+
+func applyHardForksFlags(ctx *cli.Context, cfg *Config) error {
+	if ctx.IsSet(ChainConfigFlag.Name) {
+		cfg.HardForks.ChainConfig = ctx.String(ChainConfigFlag.Name)
+	}
+	if cfg.HardForks.ChainConfig != "" {
+		if err := loadChainConfig(cfg.HardForks.ChainConfig, cfg); err != nil {
+			return err
+		}
+	}
+	return cfg.HardForks.Validate()
+}
+
+func applyMempoolFlags(ctx *cli.Context, cfg *Config) error {
+	if ctx.IsSet(TxOrderingFlag.Name) {
+		cfg.Mempool.TxOrdering = ctx.String(TxOrderingFlag.Name)
+	}
+	if ctx.IsSet(TxLocalsFlag.Name) {
+		for _, value := range ctx.StringSlice(TxLocalsFlag.Name) {
+			if !common.IsHexAddress(value) {
+				return errors.Errorf("invalid local address %v", value)
+			}
+			cfg.Mempool.Locals = append(cfg.Mempool.Locals, common.HexToAddress(value))
+		}
+	}
+	if ctx.IsSet(TxRpcLocalsFlag.Name) {
+		cfg.Mempool.RpcLocalTxs = ctx.Int(TxRpcLocalsFlag.Name)
+	}
+	if ctx.IsSet(TxPoolMaxSizeFlag.Name) {
+		cfg.Mempool.TxPoolMaxSize = ctx.Int(TxPoolMaxSizeFlag.Name)
+	}
+	if ctx.IsSet(TxPoolMaxBytesFlag.Name) {
+		cfg.Mempool.TxPoolMaxBytes = ctx.Int(TxPoolMaxBytesFlag.Name)
+	}
+	if ctx.IsSet(TxPoolAddrPendingFlag.Name) {
+		cfg.Mempool.TxPoolAddrQueueLimit = ctx.Int(TxPoolAddrPendingFlag.Name)
+	}
+	if ctx.IsSet(TxPoolAddrExecutableFlag.Name) {
+		cfg.Mempool.TxPoolAddrExecutableLimit = ctx.Int(TxPoolAddrExecutableFlag.Name)
+	}
+	if ctx.IsSet(TxMaxPayloadSizeFlag.Name) {
+		cfg.Mempool.TxMaxPayloadSize = ctx.Int(TxMaxPayloadSizeFlag.Name)
+	}
+	if ctx.IsSet(TxLifetimeFlag.Name) {
+		cfg.Mempool.TxLifetime = ctx.Duration(TxLifetimeFlag.Name)
+	}
+	if ctx.IsSet(TxRebroadcastFlag.Name) {
+		cfg.Mempool.RebroadcastInterval = ctx.Duration(TxRebroadcastFlag.Name)
+	}
+	// txs of public RPC callers must not bypass mempool limits
+	if !isLoopbackHost(cfg.RPC.HTTPHost) {
+		cfg.Mempool.RpcLocalTxs = 0
+	}
+	return nil
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func applyIpfsGcFlags(ctx *cli.Context, cfg *Config) {
+	if ctx.IsSet(IpfsGcIntervalFlag.Name) {
+		cfg.IpfsGc.Interval = ctx.Duration(IpfsGcIntervalFlag.Name)
+		cfg.IpfsGc.Enabled = cfg.IpfsGc.Interval > 0
+	}
+	if ctx.IsSet(IpfsGcRetentionFlag.Name) {
+		cfg.IpfsGc.RetentionEpochs = uint16(ctx.Uint(IpfsGcRetentionFlag.Name))
+	}
+}
+
+func applyTimeSyncFlags(ctx *cli.Context, cfg *Config) {
+	if ctx.IsSet(TimeSyncApplyOffsetFlag.Name) {
+		cfg.TimeSync.ApplyOffset = ctx.Bool(TimeSyncApplyOffsetFlag.Name)
+	}
+	if ctx.IsSet(TimeSyncDriftThresholdFlag.Name) {
+		cfg.TimeSync.DriftThreshold = ctx.Duration(TimeSyncDriftThresholdFlag.Name)
+	}
 }
 
-func applySyncFlags(ctx *cli.Context, cfg *Config) {
+func applySyncFlags(ctx *cli.Context, cfg *Config) error {
 	if ctx.IsSet(FastSyncFlag.Name) {
 		cfg.Sync.FastSync = ctx.Bool(FastSyncFlag.Name)
 	}
 	if ctx.IsSet(ForceFullSyncFlag.Name) {
 		cfg.Sync.ForceFullSync = ctx.Uint64(ForceFullSyncFlag.Name)
 	}
+	if ctx.IsSet(SyncCheckpointFlag.Name) {
+		for _, value := range ctx.StringSlice(SyncCheckpointFlag.Name) {
+			checkpoint, err := ParseCheckpoint(value)
+			if err != nil {
+				return err
+			}
+			cfg.Sync.Checkpoints = append(cfg.Sync.Checkpoints, checkpoint)
+		}
+	}
+	if ctx.IsSet(ManifestSourcesFlag.Name) {
+		cfg.Sync.ManifestSources = ctx.Int(ManifestSourcesFlag.Name)
+	}
+	if ctx.IsSet(ManifestPinFlag.Name) {
+		for _, value := range ctx.StringSlice(ManifestPinFlag.Name) {
+			pin, err := ParseManifestPin(value)
+			if err != nil {
+				return err
+			}
+			cfg.Sync.ManifestPins = append(cfg.Sync.ManifestPins, pin)
+		}
+	}
+	if ctx.IsSet(HTTPMirrorFlag.Name) {
+		cfg.Sync.HTTPMirror = strings.TrimRight(ctx.String(HTTPMirrorFlag.Name), "/")
+	}
+	return nil
+}
+
+func applyMirrorFlags(ctx *cli.Context, cfg *Config) {
+	if ctx.IsSet(MirrorFlag.Name) {
+		cfg.Mirror.Enabled = ctx.Bool(MirrorFlag.Name)
+	}
+	if ctx.IsSet(MirrorHostFlag.Name) {
+		cfg.Mirror.HTTPHost = ctx.String(MirrorHostFlag.Name)
+	}
+	if ctx.IsSet(MirrorPortFlag.Name) {
+		cfg.Mirror.HTTPPort = ctx.Int(MirrorPortFlag.Name)
+	}
+}
+
+func applyBandwidthFlags(ctx *cli.Context, cfg *Config) {
+	if ctx.IsSet(MaxUploadFlag.Name) {
+		cfg.Bandwidth.Total.MaxUpload = ctx.Int(MaxUploadFlag.Name)
+	}
+	if ctx.IsSet(MaxDownloadFlag.Name) {
+		cfg.Bandwidth.Total.MaxDownload = ctx.Int(MaxDownloadFlag.Name)
+	}
 }
 
 func applyP2PFlags(ctx *cli.Context, cfg *Config) {
 	if ctx.IsSet(MaxNetworkDelayFlag.Name) {
 		cfg.P2P.MaxDelay = ctx.Int(MaxNetworkDelayFlag.Name)
 	}
+	if ctx.IsSet(P2PStaticFlag.Name) {
+		cfg.P2P.StaticPeers = append(cfg.P2P.StaticPeers, ctx.StringSlice(P2PStaticFlag.Name)...)
+	}
+	if ctx.IsSet(P2PTrustedFlag.Name) {
+		cfg.P2P.TrustedPeers = append(cfg.P2P.TrustedPeers, ctx.StringSlice(P2PTrustedFlag.Name)...)
+	}
+	if ctx.IsSet(P2PDnsSeedFlag.Name) {
+		cfg.P2P.DnsSeeds = append(cfg.P2P.DnsSeeds, ctx.StringSlice(P2PDnsSeedFlag.Name)...)
+	}
 }
 
 func applyConsensusFlags(ctx *cli.Context, cfg *Config) {
 	if ctx.IsSet(AutomineFlag.Name) {
 		cfg.Consensus.Automine = ctx.Bool(AutomineFlag.Name)
 	}
+	if ctx.IsSet(StandbyFlag.Name) {
+		cfg.Consensus.Standby = ctx.Bool(StandbyFlag.Name)
+	}
+}
+
+func applySecretsFlags(ctx *cli.Context, cfg *Config) {
+	if ctx.IsSet(NodeKeyFileFlag.Name) {
+		cfg.Secrets.NodeKeyFile = ctx.String(NodeKeyFileFlag.Name)
+	}
+	if ctx.IsSet(ApiKeyFileFlag.Name) {
+		cfg.Secrets.ApiKeyFile = ctx.String(ApiKeyFileFlag.Name)
+	}
 }
 
 func applyRpcFlags(ctx *cli.Context, cfg *Config) {
@@ -303,6 +757,44 @@ func applyRpcFlags(ctx *cli.Context, cfg *Config) {
 	if ctx.IsSet(ApiKeyFlag.Name) {
 		cfg.RPC.APIKey = ctx.String(ApiKeyFlag.Name)
 	}
+	if ctx.IsSet(RpcAllowFlag.Name) {
+		cfg.RPC.Allow = splitList(ctx.String(RpcAllowFlag.Name))
+	}
+	if ctx.IsSet(RpcCorsFlag.Name) {
+		cfg.RPC.HTTPCors = splitList(ctx.String(RpcCorsFlag.Name))
+	}
+	if ctx.IsSet(RpcTrustedProxiesFlag.Name) {
+		cfg.RPC.TrustedProxies = splitList(ctx.String(RpcTrustedProxiesFlag.Name))
+	}
+	if ctx.IsSet(RpcTlsCertFlag.Name) {
+		cfg.RPC.TLSCertFile = ctx.String(RpcTlsCertFlag.Name)
+	}
+	if ctx.IsSet(RpcTlsKeyFlag.Name) {
+		cfg.RPC.TLSKeyFile = ctx.String(RpcTlsKeyFlag.Name)
+	}
+	if ctx.IsSet(RpcSlowCallFlag.Name) {
+		cfg.RPC.SlowCallThreshold = ctx.Duration(RpcSlowCallFlag.Name)
+	}
+	if ctx.IsSet(RpcRateLimitFlag.Name) {
+		if cfg.RPC.RateLimit == nil {
+			cfg.RPC.RateLimit = &rpc.RateLimitConfig{}
+		}
+		cfg.RPC.RateLimit.PerIP = ctx.Float64(RpcRateLimitFlag.Name)
+	}
+	if ctx.IsSet(RpcJwtSecretFlag.Name) {
+		cfg.RPC.JWTSecret = ctx.String(RpcJwtSecretFlag.Name)
+	}
+}
+
+// splitList splits comma separated flag value
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
 }
 
 func applyGenesisFlags(ctx *cli.Context, cfg *Config) {
@@ -326,10 +818,18 @@ func applyIpfsFlags(ctx *cli.Context, cfg *Config) {
 	if ctx.IsSet(IpfsBootNodeFlag.Name) {
 		cfg.IpfsConf.BootNodes = []string{ctx.String(IpfsBootNodeFlag.Name)}
 	}
+	if ctx.IsSet(IpfsExternalFlag.Name) {
+		cfg.IpfsConf.External = ctx.String(IpfsExternalFlag.Name)
+	}
 }
 
 func applyValidationFlags(ctx *cli.Context, cfg *Config) {
-
+	if ctx.IsSet(CeremonySimulateFlag.Name) {
+		cfg.Validation.Simulate = ctx.Bool(CeremonySimulateFlag.Name)
+	}
+	if ctx.IsSet(AnswerRelayFlag.Name) {
+		cfg.Validation.AnswerRelays = ctx.StringSlice(AnswerRelayFlag.Name)
+	}
 }
 
 func loadConfig(configPath string, conf *Config) error {
@@ -1,20 +1,29 @@
 package config
 
 import (
+	"github.com/pkg/errors"
 	"math/big"
 	"time"
 )
 
+// MinBlockDelay is the min interval between block timestamps of public networks
+const MinBlockDelay = time.Second * 10
+
 type ConsensusConf struct {
-	MaxSteps                          uint8
-	AgreementThreshold                float64
-	CommitteePercent                  float64
-	FinalCommitteePercent             float64
-	WaitBlockDelay                    time.Duration
-	WaitSortitionProofDelay           time.Duration
-	EstimatedBaVariance               time.Duration
-	WaitForStepDelay                  time.Duration
-	Automine                          bool
+	MaxSteps                uint8
+	AgreementThreshold      float64
+	CommitteePercent        float64
+	FinalCommitteePercent   float64
+	WaitBlockDelay          time.Duration
+	WaitSortitionProofDelay time.Duration
+	EstimatedBaVariance     time.Duration
+	WaitForStepDelay        time.Duration
+	Automine                bool
+	// ProposeEmptyBlocks makes the proposer build blocks while the mempool is empty, the round ends with the empty
+	// block otherwise
+	ProposeEmptyBlocks bool
+	// Standby keeps the node in sync without proposing, voting and answering until it is promoted
+	Standby                           bool
 	BlockReward                       *big.Int
 	StakeRewardRate                   float32
 	StakeRewardRateForNewbie          float32
@@ -50,6 +59,7 @@ func GetDefaultConsensusConfig() *ConsensusConf {
 		WaitSortitionProofDelay:           time.Second * 5,
 		EstimatedBaVariance:               time.Second * 5,
 		WaitForStepDelay:                  time.Second * 20,
+		ProposeEmptyBlocks:                true,
 		BlockReward:                       big.NewInt(1e+18),
 		StakeRewardRate:                   0.2,
 		StakeRewardRateForNewbie:          0.8,
@@ -75,3 +85,70 @@ func GetDefaultConsensusConfig() *ConsensusConf {
 		InvitesPercent:                    0.5,
 	}
 }
+
+// ConsensusTimings overrides consensus timeouts of the chain, zero values keep the configured ones. All nodes of the
+// network should use the same timings, blocks of nodes with shorter ones are rejected.
+type ConsensusTimings struct {
+	// MinBlockDistance is the min interval between round starts
+	MinBlockDistance time.Duration
+	// ProposalTimeout is the time the block of the highest priority proposer is waited for
+	ProposalTimeout time.Duration
+	// SortitionProofDelay is the time proposer proofs are collected
+	SortitionProofDelay time.Duration
+	// BaVariance is the estimated spread of round starts between nodes
+	BaVariance time.Duration
+	// VoteTimeout is the time votes of each agreement step are waited for
+	VoteTimeout time.Duration
+	// ProposeEmptyBlocks enables or disables proposing blocks without transactions
+	ProposeEmptyBlocks *bool
+}
+
+type timingBounds struct {
+	name     string
+	value    time.Duration
+	min, max time.Duration
+}
+
+func (c *ConsensusConf) applyTimings(timings *ConsensusTimings) {
+	set := func(target *time.Duration, value time.Duration) {
+		if value > 0 {
+			*target = value
+		}
+	}
+	set(&c.MinBlockDistance, timings.MinBlockDistance)
+	set(&c.WaitBlockDelay, timings.ProposalTimeout)
+	set(&c.WaitSortitionProofDelay, timings.SortitionProofDelay)
+	set(&c.EstimatedBaVariance, timings.BaVariance)
+	set(&c.WaitForStepDelay, timings.VoteTimeout)
+	if timings.ProposeEmptyBlocks != nil {
+		c.ProposeEmptyBlocks = *timings.ProposeEmptyBlocks
+	}
+}
+
+// ProposalDelay returns the min interval between the head and the block proposed by the node
+func (c *ConsensusConf) ProposalDelay() time.Duration {
+	return c.MinBlockDistance - c.EstimatedBaVariance - c.WaitSortitionProofDelay
+}
+
+// ValidateTimings checks timeouts are in safe bounds, blocks of public networks can't be proposed earlier than
+// MinBlockDelay after the previous one
+func (c *ConsensusConf) ValidateTimings(private bool) error {
+	for _, item := range []timingBounds{
+		{"min block distance", c.MinBlockDistance, time.Second, time.Minute * 10},
+		{"proposal timeout", c.WaitBlockDelay, time.Second, time.Minute * 5},
+		{"sortition proof delay", c.WaitSortitionProofDelay, time.Millisecond * 100, time.Minute},
+		{"BA variance", c.EstimatedBaVariance, time.Millisecond * 100, time.Minute},
+		{"vote timeout", c.WaitForStepDelay, time.Second, time.Minute * 5},
+	} {
+		if item.value < item.min || item.value > item.max {
+			return errors.Errorf("%v %v is out of range [%v, %v]", item.name, item.value, item.min, item.max)
+		}
+	}
+	if c.ProposalDelay() <= 0 {
+		return errors.New("min block distance should exceed the sum of BA variance and sortition proof delay")
+	}
+	if !private && c.ProposalDelay() < MinBlockDelay {
+		return errors.Errorf("blocks of public networks can't be proposed earlier than %v after the previous one", MinBlockDelay)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConsensusConf_ValidateTimings(t *testing.T) {
+	require := require.New(t)
+	cfg := GetDefaultConsensusConfig()
+	require.NoError(cfg.ValidateTimings(false))
+
+	cfg.MinBlockDistance = time.Second * 3
+	cfg.EstimatedBaVariance = time.Millisecond * 500
+	cfg.WaitSortitionProofDelay = time.Millisecond * 500
+	require.Error(cfg.ValidateTimings(false))
+	require.NoError(cfg.ValidateTimings(true))
+	require.Equal(time.Second*2, cfg.ProposalDelay())
+
+	cfg.EstimatedBaVariance = time.Second * 3
+	require.Error(cfg.ValidateTimings(true))
+
+	cfg.EstimatedBaVariance = time.Millisecond * 500
+	cfg.WaitForStepDelay = time.Millisecond * 10
+	require.Error(cfg.ValidateTimings(true))
+}
+
+func TestLoadChainConfig(t *testing.T) {
+	require := require.New(t)
+	dir, _ := ioutil.TempDir("", "chainconfig")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "chain.json")
+	require.NoError(ioutil.WriteFile(path, []byte(`{"Activations":[{"Name":"fork","Height":10}],
+		"Consensus":{"MinBlockDistance":3000000000,"VoteTimeout":2000000000,"ProposeEmptyBlocks":false}}`), 0644))
+
+	cfg := getDefaultConfig(DefaultDataDir)
+	require.NoError(loadChainConfig(path, cfg))
+	require.Len(cfg.HardForks.Activations, 1)
+	require.Equal(time.Second*3, cfg.Consensus.MinBlockDistance)
+	require.Equal(time.Second*2, cfg.Consensus.WaitForStepDelay)
+	require.Equal(time.Minute, cfg.Consensus.WaitBlockDelay)
+	require.False(cfg.Consensus.ProposeEmptyBlocks)
+	require.Error(cfg.Consensus.ValidateTimings(false))
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/pkg/errors"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const (
+	DevNetwork  = 0x3
+	DevSwarmKey = "a49d9dbff3bd65a59a7b9e4aeb119e6aedd92bec3f20688fbb99fcd3477d3653"
+)
+
+// DevCoinbaseBalance is the genesis balance of the dev node's coinbase, 1M iDNA
+var DevCoinbaseBalance = new(big.Int).Mul(big.NewInt(1e6), common.DnaBase)
+
+// DevConfig runs the single node network which produces blocks without peers, the node coinbase is the god address
+type DevConfig struct {
+	Enabled bool
+	// BlockTime is the min interval between blocks
+	BlockTime time.Duration
+	// EpochDuration is the interval between ceremonies
+	EpochDuration time.Duration
+}
+
+func GetDefaultDevConfig() *DevConfig {
+	return &DevConfig{
+		BlockTime:     time.Second,
+		EpochDuration: time.Hour,
+	}
+}
+
+// applyDevMode isolates the node from public networks and shrinks consensus and ceremony timeouts
+func applyDevMode(cfg *Config) {
+	if cfg.Dev.BlockTime < time.Second {
+		cfg.Dev.BlockTime = time.Second
+	}
+	cfg.Network = DevNetwork
+	cfg.IpfsConf.BootNodes = []string{}
+	cfg.IpfsConf.SwarmKey = DevSwarmKey
+	cfg.P2P.DnsSeeds = nil
+	cfg.Sync.FastSync = false
+
+	cfg.Consensus.Automine = true
+	cfg.Consensus.MinBlockDistance = cfg.Dev.BlockTime
+	cfg.Consensus.WaitSortitionProofDelay = time.Millisecond * 200
+	cfg.Consensus.EstimatedBaVariance = time.Millisecond * 200
+	cfg.Consensus.WaitBlockDelay = time.Second * 2
+	cfg.Consensus.WaitForStepDelay = time.Second * 2
+
+	cfg.Validation.ValidationInterval = cfg.Dev.EpochDuration
+	cfg.Validation.FlipLotteryDuration = time.Second * 30
+	cfg.Validation.ShortSessionDuration = time.Minute
+	cfg.Validation.LongSessionDuration = time.Minute * 2
+	cfg.Validation.AfterLongSessionDuration = time.Second * 30
+	if cfg.GenesisConf.FirstCeremonyTime == 0 || cfg.GenesisConf.FirstCeremonyTime == DefaultCeremonyTime {
+		cfg.GenesisConf.FirstCeremonyTime = time.Now().Add(cfg.Dev.EpochDuration).Unix()
+	}
+}
+
+// SetDevCoinbase makes the coinbase the god address of the dev network and funds it at genesis
+func (c *Config) SetDevCoinbase(coinbase common.Address) {
+	c.GenesisConf.GodAddress = coinbase
+	if c.GenesisConf.Alloc == nil {
+		c.GenesisConf.Alloc = make(map[common.Address]GenesisAllocation)
+	}
+	if _, ok := c.GenesisConf.Alloc[coinbase]; !ok {
+		c.GenesisConf.Alloc[coinbase] = GenesisAllocation{Balance: DevCoinbaseBalance}
+	}
+}
+
+// ParseDevAlloc parses the pre-funded account in format address:balance, the balance is in iDNA
+func ParseDevAlloc(value string) (common.Address, *big.Int, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 || !common.IsHexAddress(parts[0]) {
+		return common.Address{}, nil, errors.Errorf("invalid dev allocation %q, expected address:balance", value)
+	}
+	amount, ok := new(big.Float).SetString(parts[1])
+	if !ok || amount.Sign() < 0 {
+		return common.Address{}, nil, errors.Errorf("invalid dev allocation balance %q", parts[1])
+	}
+	balance, _ := new(big.Float).Mul(amount, new(big.Float).SetInt(common.DnaBase)).Int(nil)
+	return common.HexToAddress(parts[0]), balance, nil
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestParseDevAlloc(t *testing.T) {
+	addr := common.Address{0x1}
+
+	parsed, balance, err := ParseDevAlloc(addr.Hex() + ":1.5")
+	require.NoError(t, err)
+	require.Equal(t, addr, parsed)
+	require.Equal(t, new(big.Int).Div(new(big.Int).Mul(big.NewInt(3), common.DnaBase), big.NewInt(2)), balance)
+
+	_, _, err = ParseDevAlloc(addr.Hex())
+	require.Error(t, err)
+
+	_, _, err = ParseDevAlloc("0x01:100")
+	require.Error(t, err)
+
+	_, _, err = ParseDevAlloc(addr.Hex() + ":-1")
+	require.Error(t, err)
+}
+
+func TestApplyDevMode(t *testing.T) {
+	cfg := getDefaultConfig(DefaultDataDir)
+	cfg.Dev.Enabled = true
+	cfg.Dev.BlockTime = time.Millisecond
+	applyDevMode(cfg)
+
+	require.Equal(t, uint32(DevNetwork), cfg.Network)
+	require.Empty(t, cfg.IpfsConf.BootNodes)
+	require.Equal(t, time.Second, cfg.Consensus.MinBlockDistance)
+	require.Equal(t, time.Hour, cfg.Validation.ValidationInterval)
+	require.True(t, cfg.GenesisConf.FirstCeremonyTime > time.Now().Unix())
+
+	coinbase := common.Address{0x2}
+	cfg.SetDevCoinbase(coinbase)
+	require.Equal(t, coinbase, cfg.GenesisConf.GodAddress)
+	require.Equal(t, DevCoinbaseBalance, cfg.GenesisConf.Alloc[coinbase].Balance)
+}
@@ -0,0 +1,15 @@
+package config
+
+import "github.com/idena-network/idena-go/common"
+
+type EpochReportConfig struct {
+	Enabled bool
+	// Addresses are identities reports are generated for in addition to the coinbase
+	Addresses []common.Address
+}
+
+func GetDefaultEpochReportConfig() *EpochReportConfig {
+	return &EpochReportConfig{
+		Enabled: true,
+	}
+}
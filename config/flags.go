@@ -17,9 +17,12 @@ const (
 	DefaultMaxInboundPeers  = 12
 	DefaultMaxOutboundPeers = 6
 	DefaultBurntTxRange     = 180
+	DefaultManifestSources  = 2
 
 	LowPowerMaxInboundPeers  = 6
 	LowPowerMaxOutboundPeers = 3
+
+	LowPowerFlipPrefetchParallelism = 2
 )
 
 var (
@@ -55,6 +58,43 @@ var (
 		Name:  "automine",
 		Usage: "Mine blocks alone without peers",
 	}
+	DevFlag = cli.BoolFlag{
+		Name:  "dev",
+		Usage: "Run the single node dev network with instant blocks and short epochs",
+	}
+	DevBlockTimeFlag = cli.DurationFlag{
+		Name:  "dev.blocktime",
+		Usage: "Min interval between blocks of the dev network",
+	}
+	DevEpochFlag = cli.DurationFlag{
+		Name:  "dev.epoch",
+		Usage: "Interval between ceremonies of the dev network",
+	}
+	DevAllocFlag = cli.StringSliceFlag{
+		Name:  "dev.alloc",
+		Usage: "Pre-funded account of the dev network in format address:balance, balance is in iDNA (can be repeated)",
+	}
+	PrivateNetworkFlag = cli.StringFlag{
+		Name:  "privatenet",
+		Usage: "Private network config file with genesis identities, god address and ceremony timings",
+	}
+	PrivateNetworkIdFlag = cli.UintFlag{
+		Name:  "network",
+		Usage: "Network id of the private network",
+		Value: 100,
+	}
+	PrivateNetworkGodFlag = cli.StringFlag{
+		Name:  "god",
+		Usage: "God address of the private network",
+	}
+	PrivateNetworkOutFlag = cli.StringFlag{
+		Name:  "out",
+		Usage: "File to write the private network config to, stdout is used if not set",
+	}
+	StandbyFlag = cli.BoolFlag{
+		Name:  "standby",
+		Usage: "Run as hot-standby node which doesn't vote and answer until promoted",
+	}
 	IpfsBootNodeFlag = cli.StringFlag{
 		Name:  "ipfsbootnode",
 		Usage: "Ipfs bootstrap node (overrides existing)",
@@ -63,6 +103,10 @@ var (
 		Name:  "ipfsport",
 		Usage: "Ipfs port",
 	}
+	IpfsExternalFlag = cli.StringFlag{
+		Name:  "ipfs.external",
+		Usage: "Use external IPFS daemon HTTP API instead of embedded node (multiaddr, e.g. /ip4/127.0.0.1/tcp/5001)",
+	}
 	NoDiscoveryFlag = cli.BoolFlag{
 		Name:  "nodiscovery",
 		Usage: "NoDiscovery can be used to disable the peer discovery mechanism.",
@@ -92,6 +136,174 @@ var (
 		Name:  "forcefullsync",
 		Usage: "Force full sync on last blocks",
 	}
+	SyncCheckpointFlag = cli.StringSliceFlag{
+		Name:  "sync.checkpoint",
+		Usage: "Trusted sync checkpoint in format height:hash:identityRoot (can be repeated)",
+	}
+	ManifestSourcesFlag = cli.IntFlag{
+		Name:  "sync.manifestsources",
+		Usage: "Min number of peers which should announce the same snapshot manifest to use it for fast sync",
+	}
+	ManifestPinFlag = cli.StringSliceFlag{
+		Name:  "sync.manifestpin",
+		Usage: "Pinned snapshot manifest state root in format height:root (can be repeated)",
+	}
+	HTTPMirrorFlag = cli.StringFlag{
+		Name:  "sync.httpmirror",
+		Usage: "Url of the HTTP mirror to load block bodies and snapshots from, data is verified by hashes",
+	}
+	MirrorFlag = cli.BoolFlag{
+		Name:  "mirror",
+		Usage: "Serve canonical block bodies and the last snapshot over HTTP for mirroring",
+	}
+	MirrorHostFlag = cli.StringFlag{
+		Name:  "mirror.host",
+		Usage: "HTTP mirror endpoint host",
+	}
+	MirrorPortFlag = cli.IntFlag{
+		Name:  "mirror.port",
+		Usage: "HTTP mirror endpoint port",
+	}
+	TimeSyncApplyOffsetFlag = cli.BoolFlag{
+		Name:  "timesync.applyoffset",
+		Usage: "Apply measured NTP clock drift to ceremony timers",
+	}
+	TimeSyncDriftThresholdFlag = cli.DurationFlag{
+		Name:  "timesync.threshold",
+		Usage: "Clock drift which is reported as wrong time",
+	}
+	CeremonySimulateFlag = cli.BoolFlag{
+		Name:  "ceremony.simulate",
+		Usage: "Run validation ceremony simulation against synthetic flips on start",
+	}
+	AnswerRelayFlag = cli.StringSliceFlag{
+		Name:  "validation.relay",
+		Usage: "RPC endpoint of a trusted node which receives own ceremony answers directly (can be repeated)",
+	}
+	FlipPrefetchParallelismFlag = cli.IntFlag{
+		Name:  "flips.parallelism",
+		Usage: "Number of flips loaded from ipfs in parallel",
+	}
+	FlipPrefetchBandwidthFlag = cli.Int64Flag{
+		Name:  "flips.bandwidth",
+		Usage: "Flips loading bandwidth limit in KB/s (0 - unlimited)",
+	}
+	IpfsGcIntervalFlag = cli.DurationFlag{
+		Name:  "ipfs.gcinterval",
+		Usage: "Interval of ipfs garbage collection, disabled by default since it drops unpinned block bodies and flips (0 - disabled)",
+	}
+	IpfsGcRetentionFlag = cli.UintFlag{
+		Name:  "ipfs.gcretention",
+		Usage: "Number of past epochs which data is kept pinned in ipfs",
+	}
+	OnlineKeeperFlag = cli.BoolFlag{
+		Name:  "online.keeper",
+		Usage: "Automatically send online status tx if the identity was marked offline",
+	}
+	OnlineKeeperLockFlag = cli.StringFlag{
+		Name:  "online.lockfile",
+		Usage: "Lock file shared with hot-standby nodes to prevent double submission of online status tx",
+	}
+	TxOrderingFlag = cli.StringFlag{
+		Name:  "mempool.ordering",
+		Usage: "Block proposal tx ordering: nonce (default), fee, fifo or fair",
+	}
+	TxLocalsFlag = cli.StringSliceFlag{
+		Name:  "mempool.locals",
+		Usage: "Address which txs get reserved block space and bypass mempool limits (can be repeated)",
+	}
+	TxRpcLocalsFlag = cli.IntFlag{
+		Name:  "mempool.rpclocals",
+		Usage: "Max number of txs sent via loopback RPC which are treated as local (0 - disabled)",
+	}
+	TxPoolMaxSizeFlag = cli.IntFlag{
+		Name:  "mempool.maxsize",
+		Usage: "Max number of non-priority txs in the mempool (-1 - unlimited)",
+	}
+	TxPoolMaxBytesFlag = cli.IntFlag{
+		Name:  "mempool.maxbytes",
+		Usage: "Max total size of txs in the mempool in bytes (0 - unlimited)",
+	}
+	TxPoolAddrPendingFlag = cli.IntFlag{
+		Name:  "mempool.addrpending",
+		Usage: "Max number of pending txs per sender",
+	}
+	TxPoolAddrExecutableFlag = cli.IntFlag{
+		Name:  "mempool.addrexecutable",
+		Usage: "Max number of executable txs per sender",
+	}
+	TxMaxPayloadSizeFlag = cli.IntFlag{
+		Name:  "mempool.maxpayload",
+		Usage: "Max payload size of non-priority txs in bytes (0 - unlimited)",
+	}
+	TxLifetimeFlag = cli.DurationFlag{
+		Name:  "mempool.txlifetime",
+		Usage: "Time after which pending txs are removed from the mempool, disabled by default (0 - disabled)",
+	}
+	TxRebroadcastFlag = cli.DurationFlag{
+		Name:  "mempool.rebroadcast",
+		Usage: "First delay of repeated announcement of local txs, doubled after each attempt (0 - disabled)",
+	}
+	IdentityWebhookFlag = cli.StringSliceFlag{
+		Name:  "identity.webhook",
+		Usage: "URL identity state change events are posted to (can be repeated)",
+	}
+	IdentityWebhookSecretFlag = cli.StringFlag{
+		Name:  "identity.webhooksecret",
+		Usage: "Secret to sign identity webhook payloads with HMAC-SHA256",
+	}
+	IdentityWatchFlag = cli.StringSliceFlag{
+		Name:  "identity.watch",
+		Usage: "Address of identity to send state change events for, all identities if not set (can be repeated)",
+	}
+	EpochReportWatchFlag = cli.StringSliceFlag{
+		Name:  "epochreport.watch",
+		Usage: "Address of identity to generate epoch reports for in addition to the coinbase (can be repeated)",
+	}
+	WatchAddressFlag = cli.StringSliceFlag{
+		Name:  "watch.address",
+		Usage: "Address to add to the watch list (can be repeated)",
+	}
+	ForkAlertThresholdFlag = cli.Uint64Flag{
+		Name:  "forks.alertthreshold",
+		Usage: "Fork length in blocks which is reported as alert (0 - disabled)",
+	}
+	ForkWebhookFlag = cli.StringFlag{
+		Name:  "forks.webhook",
+		Usage: "URL fork alerts are posted to",
+	}
+	PenaltyWebhookFlag = cli.StringFlag{
+		Name:  "penalty.webhook",
+		Usage: "URL penalty warnings are posted to",
+	}
+	PenaltyOfflineThresholdFlag = cli.DurationFlag{
+		Name:  "penalty.offlinethreshold",
+		Usage: "Time the online identity may stay unsynced or without peers before the warning is raised",
+	}
+	P2PStaticFlag = cli.StringSliceFlag{
+		Name:  "p2p.static",
+		Usage: "Multiaddress of the peer which is always redialed (can be repeated)",
+	}
+	P2PTrustedFlag = cli.StringSliceFlag{
+		Name:  "p2p.trusted",
+		Usage: "Multiaddress of the peer which is always accepted (can be repeated)",
+	}
+	MaxUploadFlag = cli.IntFlag{
+		Name:  "net.maxupload",
+		Usage: "Max upload rate of the node in KiB/s (0 - unlimited)",
+	}
+	MaxDownloadFlag = cli.IntFlag{
+		Name:  "net.maxdownload",
+		Usage: "Max download rate of the node in KiB/s (0 - unlimited)",
+	}
+	P2PDnsSeedFlag = cli.StringSliceFlag{
+		Name:  "p2p.dnsseed",
+		Usage: "Url of the signed DNS peer list idenatree://<public key>@<domain> (can be repeated)",
+	}
+	ChainConfigFlag = cli.StringFlag{
+		Name:  "chainconfig",
+		Usage: "Path to the JSON file with hard fork activations",
+	}
 	ProfileFlag = cli.StringFlag{
 		Name:  "profile",
 		Usage: "Configuration profile",
@@ -104,11 +316,137 @@ var (
 		Name:  "apikey",
 		Usage: "Set RPC api key",
 	}
+	ApiKeyFileFlag = cli.StringFlag{
+		Name:  "apikey.file",
+		Usage: "File with RPC api key, the key isn't generated in datadir if it is set (" + ApiKeyEnv + " overrides it)",
+	}
+	NodeKeyFileFlag = cli.StringFlag{
+		Name:  "nodekey.file",
+		Usage: "File with hex encoded node key or the exported key (" + NodeKeyEnv + " overrides it)",
+	}
+	RpcAllowFlag = cli.StringFlag{
+		Name:  "rpc.allow",
+		Usage: "Comma separated list of allowed RPC methods, trailing * matches any suffix (e.g. bcn_*,dna_identity)",
+	}
+	RpcRateLimitFlag = cli.Float64Flag{
+		Name:  "rpc.ratelimit",
+		Usage: "Max number of RPC requests per second from one IP (0 - unlimited)",
+	}
+	RpcJwtSecretFlag = cli.StringFlag{
+		Name:  "rpc.jwtsecret",
+		Usage: "Secret to verify HS256 JWT passed in Authorization header as an alternative to api key",
+	}
+	RpcCorsFlag = cli.StringFlag{
+		Name:  "rpc.cors",
+		Usage: "Comma separated list of origins allowed to send cross-origin RPC requests",
+	}
+	RpcTrustedProxiesFlag = cli.StringFlag{
+		Name:  "rpc.trustedproxies",
+		Usage: "Comma separated list of reverse proxy IPs or CIDRs which X-Forwarded-For header is trusted",
+	}
+	RpcTlsCertFlag = cli.StringFlag{
+		Name:  "rpc.tlscert",
+		Usage: "TLS certificate file for RPC server, reloaded on change",
+	}
+	RpcTlsKeyFlag = cli.StringFlag{
+		Name:  "rpc.tlskey",
+		Usage: "TLS key file for RPC server, reloaded on change",
+	}
+	RelayFlag = cli.StringFlag{
+		Name:  "relay",
+		Usage: "RPC url of the node of the other network to relay checkpoints from",
+	}
+	RelayMirrorFlag = cli.StringFlag{
+		Name:  "relay.mirror",
+		Usage: "HTTP mirror url of the other network to relay snapshot manifests from",
+	}
+	RelayDataFlag = cli.StringFlag{
+		Name:  "relay.data",
+		Usage: "Comma separated list of relayed data: checkpoints, manifests",
+	}
+	StorageBudgetFlag = cli.Uint64Flag{
+		Name:  "storage.budget",
+		Usage: "Disk space in MB the chain database and ipfs repo may use, 0 disables the budget",
+	}
+	RpcSlowCallFlag = cli.DurationFlag{
+		Name:  "rpc.slowcall",
+		Usage: "Log RPC calls slower than the threshold, 0 disables the log",
+	}
 	LogFileSizeFlag = cli.IntFlag{
 		Name:  "logfilesize",
 		Usage: "Set log file size in KB",
 		Value: 1024 * 10,
 	}
+	CertIndexFlag = cli.BoolFlag{
+		Name:  "certindex",
+		Usage: "Keep certificates of all blocks indexed by height",
+	}
+	DbCheckBodiesFlag = cli.BoolFlag{
+		Name:  "bodies",
+		Usage: "Check block bodies and tx indexes (starts ipfs)",
+	}
+	TxKeyFileFlag = cli.StringFlag{
+		Name:  "key",
+		Usage: "Path to the node key file or the key exported by dna_exportKey",
+	}
+	TxKeyPasswordFlag = cli.StringFlag{
+		Name:   "password",
+		Usage:  "Password of the exported key",
+		EnvVar: "IDENA_KEY_PASSWORD",
+	}
+	TxOutFlag = cli.StringFlag{
+		Name:  "out",
+		Usage: "File to write the signed tx to, stdout is used if not set",
+	}
+	AncientFlag = cli.BoolFlag{
+		Name:  "ancient",
+		Usage: "Move bodies and certificates of old blocks to the ancient store",
+	}
+	AncientKeepEpochsFlag = cli.UintFlag{
+		Name:  "ancient.keepepochs",
+		Usage: "Number of past epochs which blocks are kept in the datadir",
+	}
+	AncientDirFlag = cli.StringFlag{
+		Name:  "ancient.dir",
+		Usage: "Ancient store directory",
+	}
+	BackupFlag = cli.BoolFlag{
+		Name:  "backup",
+		Usage: "Enable scheduled encrypted backups of the node key, the API key and ceremony artifacts",
+	}
+	BackupDirFlag = cli.StringFlag{
+		Name:  "backup.dir",
+		Usage: "Backup archive directory",
+	}
+	BackupPasswordFileFlag = cli.StringFlag{
+		Name:  "backup.passwordfile",
+		Usage: "File with the passphrase backups are encrypted with",
+	}
+	BackupForceFlag = cli.BoolFlag{
+		Name:  "force",
+		Usage: "Replace the existing node key, the replaced key is kept in the keystore",
+	}
+	ExportFromFlag = cli.Uint64Flag{
+		Name:  "from",
+		Usage: "First exported block height",
+		Value: 1,
+	}
+	ExportToFlag = cli.Uint64Flag{
+		Name:  "to",
+		Usage: "Last exported block height, the head is used if not set",
+	}
+	SimulateSeedFlag = cli.Int64Flag{
+		Name:  "seed",
+		Usage: "Override the seed of the simulation scenario",
+	}
+	SimulateOutFlag = cli.StringFlag{
+		Name:  "out",
+		Usage: "File to write the simulation result to, stdout is used if not set",
+	}
+	LogVmoduleFlag = cli.StringFlag{
+		Name:  "log.vmodule",
+		Usage: "Per module verbosity: comma-separated list of <pattern>=<level> (e.g. consensus/*=4)",
+	}
 	LogColoring = cli.BoolFlag{
 		Name:  "logcoloring",
 		Usage: "Use log coloring",
@@ -0,0 +1,24 @@
+package config
+
+import "time"
+
+type FlipPrefetchConfig struct {
+	Parallelism int
+	// BandwidthLimit is an approximate limit of flips downloading in bytes per second, 0 means unlimited
+	BandwidthLimit int64
+	RetryMinDelay  time.Duration
+	RetryMaxDelay  time.Duration
+	Pin            bool
+	// PinTTL is how long loaded flips stay pinned, 0 means until the end of the epoch
+	PinTTL time.Duration
+}
+
+func GetDefaultFlipPrefetchConfig() *FlipPrefetchConfig {
+	return &FlipPrefetchConfig{
+		Parallelism:   4,
+		RetryMinDelay: 500 * time.Millisecond,
+		RetryMaxDelay: 30 * time.Second,
+		Pin:           true,
+		PinTTL:        6 * time.Hour,
+	}
+}
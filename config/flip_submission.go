@@ -0,0 +1,21 @@
+package config
+
+import "time"
+
+type FlipSubmissionConfig struct {
+	RetryMinDelay time.Duration
+	RetryMaxDelay time.Duration
+	// MaxAttempts is the number of failed publication or broadcast attempts after which the submission is failed
+	MaxAttempts int
+	// HistorySize is the number of recent submissions kept in memory
+	HistorySize int
+}
+
+func GetDefaultFlipSubmissionConfig() *FlipSubmissionConfig {
+	return &FlipSubmissionConfig{
+		RetryMinDelay: 5 * time.Second,
+		RetryMaxDelay: 5 * time.Minute,
+		MaxAttempts:   30,
+		HistorySize:   100,
+	}
+}
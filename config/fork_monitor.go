@@ -0,0 +1,21 @@
+package config
+
+import "time"
+
+type ForkMonitorConfig struct {
+	// AlertThreshold is the fork length in blocks starting from which the fork is reported as alert, 0 - disabled
+	AlertThreshold uint64
+	// Webhook is URL fork alerts are POSTed to
+	Webhook string
+	Timeout time.Duration
+	// HistorySize is the number of recent forks kept in memory
+	HistorySize int
+}
+
+func GetDefaultForkMonitorConfig() *ForkMonitorConfig {
+	return &ForkMonitorConfig{
+		AlertThreshold: 10,
+		Timeout:        10 * time.Second,
+		HistorySize:    100,
+	}
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"encoding/json"
+	"github.com/coreos/go-semver/semver"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"time"
+)
+
+// HardFork is the protocol change activated at the block height or at the start of the epoch
+type HardFork struct {
+	Name string
+	// Height is the first block the change is applied to, 0 - activated by epoch
+	Height uint64
+	// Epoch is the first epoch the change is applied in, 0 - activated by height
+	Epoch uint16
+	// MinVersion is the min node version implementing the change
+	MinVersion  string
+	Description string
+	// Upgrade is the version proposers signal in block headers when they are ready for the change, 0 - not voted
+	Upgrade uint16
+	// Threshold is the share of recent blocks signaling the upgrade the change is expected to be activated at
+	Threshold float64
+}
+
+type HardForksConfig struct {
+	Activations []HardFork
+	// ChainConfig is the path to the JSON file with activations replacing the configured ones and consensus timings
+	ChainConfig string
+	// WarnBlocks is the number of blocks before the activation the node starts to warn if it isn't ready for it
+	WarnBlocks uint64
+	// VotesWindow is the number of recent blocks upgrade votes are counted in
+	VotesWindow uint64
+	// PeerVersionTTL is the time the version of the disconnected peer is kept in upgrade votes
+	PeerVersionTTL time.Duration
+}
+
+func GetDefaultHardForksConfig() *HardForksConfig {
+	return &HardForksConfig{
+		WarnBlocks:     4320,
+		VotesWindow:    4320,
+		PeerVersionTTL: 24 * time.Hour,
+	}
+}
+
+func (c *HardForksConfig) Validate() error {
+	names := make(map[string]bool, len(c.Activations))
+	for _, fork := range c.Activations {
+		if fork.Name == "" {
+			return errors.New("hard fork name is empty")
+		}
+		if names[fork.Name] {
+			return errors.Errorf("duplicate hard fork %v", fork.Name)
+		}
+		names[fork.Name] = true
+		if (fork.Height == 0) == (fork.Epoch == 0) {
+			return errors.Errorf("hard fork %v should be activated either by height or by epoch", fork.Name)
+		}
+		if fork.Threshold < 0 || fork.Threshold > 1 {
+			return errors.Errorf("threshold of hard fork %v should be in range [0, 1]", fork.Name)
+		}
+		if fork.MinVersion != "" {
+			if _, err := semver.NewVersion(fork.MinVersion); err != nil {
+				return errors.Wrapf(err, "invalid min version of hard fork %v", fork.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// Get returns the activation of the hard fork, nil if the hard fork isn't scheduled
+func (c *HardForksConfig) Get(name string) *HardFork {
+	for i := range c.Activations {
+		if c.Activations[i].Name == name {
+			return &c.Activations[i]
+		}
+	}
+	return nil
+}
+
+// IsActive reports whether the change should be applied to the block of the height created in the epoch
+func (c *HardForksConfig) IsActive(name string, height uint64, epoch uint16) bool {
+	fork := c.Get(name)
+	return fork != nil && fork.IsActive(height, epoch)
+}
+
+func (f *HardFork) IsActive(height uint64, epoch uint16) bool {
+	if f.Height > 0 {
+		return height >= f.Height
+	}
+	return epoch >= f.Epoch
+}
+
+func loadChainConfig(path string, cfg *Config) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "chain config cannot be read, path: %v", path)
+	}
+	var chainConfig struct {
+		Activations []HardFork
+		Consensus   *ConsensusTimings
+	}
+	if err := json.Unmarshal(data, &chainConfig); err != nil {
+		return errors.Wrapf(err, "cannot parse chain config, path: %v", path)
+	}
+	cfg.HardForks.Activations = chainConfig.Activations
+	if chainConfig.Consensus != nil {
+		cfg.Consensus.applyTimings(chainConfig.Consensus)
+	}
+	return nil
+}
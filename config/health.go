@@ -0,0 +1,27 @@
+package config
+
+import "time"
+
+// HealthConfig sets thresholds of the /health and /ready HTTP endpoints, zero values disable the check
+type HealthConfig struct {
+	// MinPeers is the number of connected peers required for readiness
+	MinPeers int
+	// MinIpfsPeers is the number of connected ipfs peers required for readiness
+	MinIpfsPeers int
+	// MaxBlockAge is the age of the head block above which the node isn't ready
+	MaxBlockAge time.Duration
+	// StaleBlockAge is the age of the head block above which the node isn't healthy unless it's syncing
+	StaleBlockAge time.Duration
+	// CeremonyLeadTime is the time before the validation starting from which the candidate node has to be ready
+	CeremonyLeadTime time.Duration
+}
+
+func GetDefaultHealthConfig() *HealthConfig {
+	return &HealthConfig{
+		MinPeers:         1,
+		MinIpfsPeers:     1,
+		MaxBlockAge:      3 * time.Minute,
+		StaleBlockAge:    30 * time.Minute,
+		CeremonyLeadTime: 30 * time.Minute,
+	}
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"time"
+)
+
+type IdentityEventsConfig struct {
+	// Webhooks are URLs identity state change events are POSTed to
+	Webhooks []string
+	// WebhookSecret signs webhook payloads, the HMAC-SHA256 signature is sent in X-Idena-Signature header
+	WebhookSecret string
+	// Addresses limits events to the listed identities, empty list means all identities
+	Addresses  []common.Address
+	Timeout    time.Duration
+	MaxRetries int
+	// RetryDelay is the delay before the first retry, it is doubled after every failed attempt
+	RetryDelay time.Duration
+	QueueSize  int
+	// RecentSize is the number of recent events kept for polling via RPC
+	RecentSize int
+}
+
+func GetDefaultIdentityEventsConfig() *IdentityEventsConfig {
+	return &IdentityEventsConfig{
+		Timeout:    10 * time.Second,
+		MaxRetries: 5,
+		RetryDelay: 2 * time.Second,
+		QueueSize:  1000,
+		RecentSize: 1000,
+	}
+}
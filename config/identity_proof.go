@@ -0,0 +1,14 @@
+package config
+
+type IdentityProofConfig struct {
+	Enabled bool
+	// KeepEpochs is the number of the latest sealed epochs proofs are stored for
+	KeepEpochs uint16
+}
+
+func GetDefaultIdentityProofConfig() *IdentityProofConfig {
+	return &IdentityProofConfig{
+		Enabled:    true,
+		KeepEpochs: 3,
+	}
+}
@@ -0,0 +1,19 @@
+package config
+
+import "time"
+
+type InvitesConfig struct {
+	CheckInterval time.Duration
+	// WarnLeadTime is the time before the validation starting from which unused and not activated invites are reported
+	WarnLeadTime time.Duration
+	// KeepEpochs is the number of epochs issued invites are kept after the epoch they were issued in
+	KeepEpochs uint16
+}
+
+func GetDefaultInvitesConfig() *InvitesConfig {
+	return &InvitesConfig{
+		CheckInterval: 10 * time.Minute,
+		WarnLeadTime:  24 * time.Hour,
+		KeepEpochs:    2,
+	}
+}
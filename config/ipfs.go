@@ -14,6 +14,8 @@ type IpfsConfig struct {
 	Profile            string
 	BlockPinThreshold  float32
 	FlipPinThreshold   float32
+	// External is a multiaddr of external IPFS daemon HTTP API, embedded node is used if empty
+	External string
 }
 
 func GetDefaultIpfsConfig() *IpfsConfig {
@@ -0,0 +1,20 @@
+package config
+
+import "time"
+
+type IpfsGcConfig struct {
+	// Enabled is off by default since the full repo gc drops unpinned block bodies and flips the node still serves
+	// to peers and the ancient archiver
+	Enabled  bool
+	Interval time.Duration
+	// RetentionEpochs is how many past epochs keep their flips, snapshots and profiles pinned
+	RetentionEpochs uint16
+}
+
+func GetDefaultIpfsGcConfig() *IpfsGcConfig {
+	return &IpfsGcConfig{
+		Enabled:         false,
+		Interval:        6 * time.Hour,
+		RetentionEpochs: 2,
+	}
+}
@@ -0,0 +1,14 @@
+package config
+
+type LogConfig struct {
+	// Verbosity is the log level: 0 - crit, 1 - error, 2 - warn, 3 - info, 4 - debug, 5 - trace
+	Verbosity int
+	// Vmodule overrides the verbosity by file or package patterns, e.g. "consensus/*=4,mempool=5"
+	Vmodule string
+}
+
+func GetDefaultLogConfig() *LogConfig {
+	return &LogConfig{
+		Verbosity: 3,
+	}
+}
@@ -1,6 +1,10 @@
 package config
 
-import "time"
+import (
+	"github.com/idena-network/idena-go/common"
+	"math/big"
+	"time"
+)
 
 type Mempool struct {
 	TxPoolQueueSlots      int
@@ -8,7 +12,47 @@ type Mempool struct {
 
 	TxPoolAddrQueueLimit      int
 	TxPoolAddrExecutableLimit int
-	TxLifetime                time.Duration
+	// TxPoolMaxSize is the max number of non-priority txs in the pool, it is derived from slots and address limits if zero
+	TxPoolMaxSize int
+	// TxPoolMaxBytes is the max total size of txs in the pool, 0 - unlimited
+	TxPoolMaxBytes int
+	// TxMaxPayloadSize is the max payload size of non-priority txs, 0 - unlimited
+	TxMaxPayloadSize int
+	// TxLifetime is the time after which pending txs of non-local senders are expired, 0 - txs don't expire, it is off
+	// by default so pending txs are kept as before
+	TxLifetime time.Duration
+	// TxExpiryInterval is the interval of pending txs expiry checks
+	TxExpiryInterval time.Duration
+	// TxOrdering is the name of the block proposal tx ordering strategy: nonce (default), fee, fifo or fair
+	TxOrdering string
+	// Locals are addresses which txs are treated as local in addition to the node address
+	Locals []common.Address
+	// RpcLocalTxs is the max number of txs sent via RPC which are treated as local while they stay in the pool,
+	// 0 - RPC txs aren't local, it is forced to 0 if RPC listens on a non-loopback interface
+	RpcLocalTxs int
+	// LocalsBlockSpace is the block body size in bytes reserved for local txs
+	LocalsBlockSpace int
+	Admission        *TxAdmission
+	// NonceCacheSize is the max number of addresses tracked by the nonce cache, 0 - unlimited
+	NonceCacheSize int
+	// RebroadcastInterval is the delay of the first repeated announcement of local txs, it doubles after each attempt,
+	// 0 - local txs aren't rebroadcast
+	RebroadcastInterval time.Duration
+	// RebroadcastMaxInterval limits the delay between repeated announcements
+	RebroadcastMaxInterval time.Duration
+}
+
+// TxAdmission is the rule set applied to txs before they enter the pool, RPC and local txs included, only txs of the
+// node coinbase are exempted, zero values disable rules
+type TxAdmission struct {
+	// MaxTxsPerSender limits the number of txs of a sender in the pool
+	MaxTxsPerSender int
+	// AllowedTxTypes limits accepted tx types
+	AllowedTxTypes []uint16
+	// MinMaxFee is the minimal tx max fee by tx type
+	MinMaxFee map[uint16]*big.Int
+	// MaxPayloadSize limits tx payload size in bytes
+	MaxPayloadSize int
 }
 
 func GetDefaultMempoolConfig() *Mempool {
@@ -18,6 +62,14 @@ func GetDefaultMempoolConfig() *Mempool {
 
 		TxPoolAddrQueueLimit:      32,
 		TxPoolAddrExecutableLimit: 32,
-		TxLifetime:                time.Hour * 3,
+		TxLifetime:                0,
+		TxExpiryInterval:          time.Minute,
+		TxOrdering:                "nonce",
+		LocalsBlockSpace:          100 * 1024,
+		RpcLocalTxs:               64,
+		Admission:                 &TxAdmission{},
+		NonceCacheSize:            100000,
+		RebroadcastInterval:       time.Minute * 5,
+		RebroadcastMaxInterval:    time.Hour,
 	}
 }
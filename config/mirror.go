@@ -0,0 +1,22 @@
+package config
+
+import "fmt"
+
+// MirrorConfig configures the read-only HTTP endpoint serving canonical block bodies and the last snapshot
+// so they can be mirrored by CDNs
+type MirrorConfig struct {
+	Enabled  bool
+	HTTPHost string
+	HTTPPort int
+}
+
+func GetDefaultMirrorConfig() *MirrorConfig {
+	return &MirrorConfig{
+		HTTPHost: "localhost",
+		HTTPPort: 9015,
+	}
+}
+
+func (c *MirrorConfig) Endpoint() string {
+	return fmt.Sprintf("%s:%d", c.HTTPHost, c.HTTPPort)
+}
@@ -0,0 +1,25 @@
+package config
+
+import "github.com/idena-network/idena-go/common/objstore"
+
+// ObjectStoreConfig addresses the bucket of the S3-compatible storage
+type ObjectStoreConfig struct {
+	Endpoint string
+	Region   string
+	Bucket   string
+	// Prefix is prepended to keys of all objects stored by the node
+	Prefix    string
+	AccessKey string
+	SecretKey string
+}
+
+func (c *ObjectStoreConfig) NewStore() (objstore.Store, error) {
+	return objstore.NewS3Store(objstore.S3Options{
+		Endpoint:  c.Endpoint,
+		Region:    c.Region,
+		Bucket:    c.Bucket,
+		Prefix:    c.Prefix,
+		AccessKey: c.AccessKey,
+		SecretKey: c.SecretKey,
+	})
+}
@@ -0,0 +1,18 @@
+package config
+
+import "time"
+
+type OnlineKeeperConfig struct {
+	Enabled bool
+	// LockFile is a path on storage shared with hot-standby nodes, it prevents double submission of online status tx
+	LockFile      string
+	LockTTL       time.Duration
+	RetryInterval time.Duration
+}
+
+func GetDefaultOnlineKeeperConfig() *OnlineKeeperConfig {
+	return &OnlineKeeperConfig{
+		LockTTL:       10 * time.Minute,
+		RetryInterval: 5 * time.Minute,
+	}
+}
@@ -1,8 +1,18 @@
 package config
 
+import "time"
+
 type P2P struct {
 	MaxInboundPeers  int
 	MaxOutboundPeers int
 	MaxDelay         int
 	CollectMetrics   bool
+	// StaticPeers are multiaddresses of peers which are always redialed, they don't occupy peer slots and aren't banned
+	StaticPeers []string
+	// TrustedPeers are multiaddresses of peers which are always accepted, they don't occupy peer slots and aren't banned
+	TrustedPeers []string
+	// DnsSeeds are urls of signed DNS peer lists (idenatree://<public key>@<domain>) used for bootstrap in addition to boot nodes
+	DnsSeeds []string
+	// DnsSeedsRefresh is the interval of DNS peer lists resolving
+	DnsSeedsRefresh time.Duration
 }
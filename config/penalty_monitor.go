@@ -0,0 +1,26 @@
+package config
+
+import "time"
+
+type PenaltyMonitorConfig struct {
+	CheckInterval time.Duration
+	// OfflineThreshold is the time the online identity may stay unsynced or without peers before the warning is raised
+	OfflineThreshold time.Duration
+	// ValidationLeadTime is the time before the validation starting from which the node readiness is checked
+	ValidationLeadTime time.Duration
+	// FlipsLeadTime is the time before the validation starting from which missing required flips are reported
+	FlipsLeadTime time.Duration
+	// Webhook is URL new warnings are POSTed to
+	Webhook string
+	Timeout time.Duration
+}
+
+func GetDefaultPenaltyMonitorConfig() *PenaltyMonitorConfig {
+	return &PenaltyMonitorConfig{
+		CheckInterval:      time.Minute,
+		OfflineThreshold:   10 * time.Minute,
+		ValidationLeadTime: time.Hour,
+		FlipsLeadTime:      24 * time.Hour,
+		Timeout:            10 * time.Second,
+	}
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/idena-network/idena-go/common"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"math/big"
+	"time"
+)
+
+// identityStates are names of identity states which can be assigned at genesis, values match core/state.IdentityState
+var identityStates = map[string]uint8{
+	"Undefined": 0,
+	"Candidate": 2,
+	"Verified":  3,
+	"Newbie":    7,
+	"Human":     8,
+}
+
+// PrivateNetworkConfig describes the ceremony coordination of a private network, the same file is used by all
+// nodes of the network, the node which owns the god address sends the listed invites automatically
+type PrivateNetworkConfig struct {
+	Network           uint32
+	SwarmKey          string
+	BootNodes         []string
+	GodAddress        common.Address
+	GodAddressInvites uint16
+	FirstCeremonyTime int64
+	Identities        []PrivateIdentity
+	Epoch             EpochDurations
+	// Invites are receivers of god address invites, unused invites are resent every epoch until activated
+	Invites []common.Address
+}
+
+// PrivateIdentity is the genesis account of the private network, balances are in iDNA
+type PrivateIdentity struct {
+	Address common.Address
+	State   string
+	Balance string `json:",omitempty"`
+	Stake   string `json:",omitempty"`
+}
+
+// EpochDurations overrides ceremony timings, zero values keep defaults
+type EpochDurations struct {
+	ValidationInterval       time.Duration
+	FlipLotteryDuration      time.Duration
+	ShortSessionDuration     time.Duration
+	LongSessionDuration      time.Duration
+	AfterLongSessionDuration time.Duration
+}
+
+// NewPrivateNetworkConfig creates the network with the random swarm key, the god address is the only human
+func NewPrivateNetworkConfig(network uint32, godAddress common.Address) (*PrivateNetworkConfig, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return &PrivateNetworkConfig{
+		Network:           network,
+		SwarmKey:          hex.EncodeToString(key),
+		GodAddress:        godAddress,
+		GodAddressInvites: 100,
+		FirstCeremonyTime: time.Now().Add(time.Hour * 24).Unix(),
+		Identities: []PrivateIdentity{
+			{Address: godAddress, State: "Human", Balance: "1000", Stake: "100"},
+		},
+		Epoch: EpochDurations{
+			ValidationInterval:   time.Hour * 24,
+			FlipLotteryDuration:  FlipLottery,
+			ShortSessionDuration: ShortSession,
+			LongSessionDuration:  time.Minute * 30,
+		},
+	}, nil
+}
+
+func LoadPrivateNetworkConfig(file string) (*PrivateNetworkConfig, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read private network config")
+	}
+	result := new(PrivateNetworkConfig)
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, errors.Wrap(err, "cannot parse private network config")
+	}
+	if err := result.Validate(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *PrivateNetworkConfig) Save(file string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, data, 0644)
+}
+
+func (c *PrivateNetworkConfig) Validate() error {
+	if c.Network <= DevNetwork {
+		return errors.Errorf("network id %v is reserved", c.Network)
+	}
+	if key, err := hex.DecodeString(c.SwarmKey); err != nil || len(key) != 32 {
+		return errors.New("swarm key should be 32 bytes in hex")
+	}
+	if c.GodAddress == (common.Address{}) {
+		return errors.New("god address is required")
+	}
+	if _, err := c.Alloc(); err != nil {
+		return err
+	}
+	if c.Epoch.ValidationInterval > 0 && c.Epoch.ValidationInterval < c.Epoch.FlipLotteryDuration+c.Epoch.ShortSessionDuration+
+		c.Epoch.LongSessionDuration+c.Epoch.AfterLongSessionDuration {
+		return errors.New("validation interval is shorter than the ceremony")
+	}
+	return nil
+}
+
+// Alloc converts identities to genesis allocations
+func (c *PrivateNetworkConfig) Alloc() (map[common.Address]GenesisAllocation, error) {
+	alloc := make(map[common.Address]GenesisAllocation, len(c.Identities))
+	for _, identity := range c.Identities {
+		if _, ok := alloc[identity.Address]; ok {
+			return nil, errors.Errorf("duplicate identity %v", identity.Address.Hex())
+		}
+		identityState, ok := identityStates[identity.State]
+		if identity.State == "" {
+			ok = true
+		}
+		if !ok {
+			return nil, errors.Errorf("invalid state %q of identity %v", identity.State, identity.Address.Hex())
+		}
+		balance, err := parseDnaAmount(identity.Balance)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid balance of identity %v", identity.Address.Hex())
+		}
+		stake, err := parseDnaAmount(identity.Stake)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid stake of identity %v", identity.Address.Hex())
+		}
+		alloc[identity.Address] = GenesisAllocation{Balance: balance, Stake: stake, State: identityState}
+	}
+	return alloc, nil
+}
+
+// IdentityStateName returns the name of the identity state used in private network configs, empty if the state
+// can't be assigned at genesis
+func IdentityStateName(value uint8) string {
+	for name, state := range identityStates {
+		if state == value {
+			return name
+		}
+	}
+	return ""
+}
+
+func parseDnaAmount(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	amount, ok := new(big.Float).SetString(value)
+	if !ok || amount.Sign() < 0 {
+		return nil, errors.Errorf("invalid amount %q", value)
+	}
+	result, _ := new(big.Float).Mul(amount, new(big.Float).SetInt(common.DnaBase)).Int(nil)
+	return result, nil
+}
+
+// applyPrivateNetwork isolates the node in the private network and replaces genesis and ceremony timings
+func applyPrivateNetwork(cfg *Config) error {
+	c := cfg.PrivateNetwork
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	alloc, _ := c.Alloc()
+
+	cfg.Network = c.Network
+	cfg.IpfsConf.SwarmKey = c.SwarmKey
+	cfg.IpfsConf.BootNodes = append([]string{}, c.BootNodes...)
+	cfg.P2P.DnsSeeds = nil
+	cfg.Sync.FastSync = false
+	cfg.Sync.Checkpoints = nil
+
+	cfg.GenesisConf.Alloc = alloc
+	cfg.GenesisConf.GodAddress = c.GodAddress
+	cfg.GenesisConf.GodAddressInvites = c.GodAddressInvites
+	if c.FirstCeremonyTime > 0 {
+		cfg.GenesisConf.FirstCeremonyTime = c.FirstCeremonyTime
+	}
+
+	cfg.Validation.ValidationInterval = c.Epoch.ValidationInterval
+	cfg.Validation.FlipLotteryDuration = c.Epoch.FlipLotteryDuration
+	cfg.Validation.ShortSessionDuration = c.Epoch.ShortSessionDuration
+	cfg.Validation.LongSessionDuration = c.Epoch.LongSessionDuration
+	cfg.Validation.AfterLongSessionDuration = c.Epoch.AfterLongSessionDuration
+	return nil
+}
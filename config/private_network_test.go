@@ -0,0 +1,79 @@
+package config
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPrivateNetworkConfig_SaveLoad(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "privatenet")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "privatenet.json")
+
+	god := common.Address{0x1}
+	cfg, err := NewPrivateNetworkConfig(100, god)
+	require.NoError(t, err)
+	cfg.Identities = append(cfg.Identities, PrivateIdentity{Address: common.Address{0x2}, State: "Verified", Balance: "0.5"})
+	cfg.Invites = []common.Address{{0x3}}
+	require.NoError(t, cfg.Save(file))
+
+	loaded, err := LoadPrivateNetworkConfig(file)
+	require.NoError(t, err)
+	require.Equal(t, cfg, loaded)
+
+	alloc, err := loaded.Alloc()
+	require.NoError(t, err)
+	require.Len(t, alloc, 2)
+	require.Equal(t, uint8(8), alloc[god].State)
+	require.Equal(t, new(big.Int).Mul(big.NewInt(100), common.DnaBase), alloc[god].Stake)
+	require.Equal(t, new(big.Int).Div(common.DnaBase, big.NewInt(2)), alloc[common.Address{0x2}].Balance)
+	require.Nil(t, alloc[common.Address{0x2}].Stake)
+}
+
+func TestPrivateNetworkConfig_Validate(t *testing.T) {
+	cfg, _ := NewPrivateNetworkConfig(100, common.Address{0x1})
+	require.NoError(t, cfg.Validate())
+
+	invalid := *cfg
+	invalid.Network = DevNetwork
+	require.Error(t, invalid.Validate())
+
+	invalid = *cfg
+	invalid.SwarmKey = "01"
+	require.Error(t, invalid.Validate())
+
+	invalid = *cfg
+	invalid.Identities = []PrivateIdentity{{Address: common.Address{0x2}, State: "Suspended"}}
+	require.Error(t, invalid.Validate())
+
+	invalid = *cfg
+	invalid.Identities = []PrivateIdentity{{Address: common.Address{0x2}}, {Address: common.Address{0x2}}}
+	require.Error(t, invalid.Validate())
+
+	invalid = *cfg
+	invalid.Epoch.ValidationInterval = time.Minute
+	require.Error(t, invalid.Validate())
+}
+
+func TestApplyPrivateNetwork(t *testing.T) {
+	cfg := getDefaultConfig(DefaultDataDir)
+	cfg.PrivateNetwork, _ = NewPrivateNetworkConfig(100, common.Address{0x1})
+	cfg.PrivateNetwork.BootNodes = []string{"/ip4/127.0.0.1/tcp/40405/ipfs/peer"}
+	require.NoError(t, applyPrivateNetwork(cfg))
+
+	require.Equal(t, uint32(100), cfg.Network)
+	require.Equal(t, cfg.PrivateNetwork.SwarmKey, cfg.IpfsConf.SwarmKey)
+	require.Equal(t, cfg.PrivateNetwork.BootNodes, cfg.IpfsConf.BootNodes)
+	require.False(t, cfg.Sync.FastSync)
+	require.Equal(t, common.Address{0x1}, cfg.GenesisConf.GodAddress)
+	require.Equal(t, uint16(100), cfg.GenesisConf.GodAddressInvites)
+	require.Equal(t, cfg.PrivateNetwork.FirstCeremonyTime, cfg.GenesisConf.FirstCeremonyTime)
+	require.Equal(t, time.Minute*30, cfg.Validation.LongSessionDuration)
+	require.Len(t, cfg.GenesisConf.Alloc, 1)
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+	"time"
+)
+
+const (
+	RelayCheckpoints = "checkpoints"
+	RelayManifests   = "manifests"
+)
+
+// RelayConfig connects the node to the other network for federated test setups. Relayed data is served to clients
+// only, it is never used by the sync or the consensus of the node
+type RelayConfig struct {
+	// Source is the RPC url of the node of the other network checkpoints are taken from, empty url disables the relay
+	Source       string
+	SourceApiKey string `toml:",omitempty"`
+	// Mirror is the HTTP mirror url of the other network snapshot manifests are taken from
+	Mirror string
+	// Data is the relayed subset: checkpoints, manifests
+	Data     []string
+	Interval time.Duration
+	Timeout  time.Duration
+	// CheckpointDepth is the number of blocks below the source head the checkpoint is taken at
+	CheckpointDepth uint64
+	// HistorySize is the number of recent records of each kind kept in memory
+	HistorySize int
+}
+
+func GetDefaultRelayConfig() *RelayConfig {
+	return &RelayConfig{
+		Data:            []string{RelayCheckpoints, RelayManifests},
+		Interval:        time.Minute,
+		Timeout:         10 * time.Second,
+		CheckpointDepth: 10,
+		HistorySize:     100,
+	}
+}
+
+func (c *RelayConfig) Enabled() bool {
+	return c != nil && (c.Source != "" || c.Mirror != "")
+}
+
+// Relays returns true if the data kind is in the relayed subset
+func (c *RelayConfig) Relays(kind string) bool {
+	for _, item := range c.Data {
+		if item == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *RelayConfig) Validate() error {
+	for _, item := range c.Data {
+		switch item {
+		case RelayCheckpoints:
+			if c.Source == "" {
+				return errors.New("relay source is required to relay checkpoints")
+			}
+		case RelayManifests:
+			if c.Mirror == "" {
+				return errors.New("relay mirror is required to relay manifests")
+			}
+		default:
+			return errors.Errorf("unknown relayed data %q, expected %v or %v", item, RelayCheckpoints, RelayManifests)
+		}
+	}
+	return nil
+}
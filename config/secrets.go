@@ -0,0 +1,123 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Environment variables secrets are read from, they take precedence over secret files
+const (
+	NodeKeyEnv         = "IDENA_NODE_KEY"
+	NodeKeyPasswordEnv = "IDENA_NODE_KEY_PASSWORD"
+	ApiKeyEnv          = "IDENA_API_KEY"
+	JWTSecretEnv       = "IDENA_RPC_JWT_SECRET"
+	BackupPasswordEnv  = "IDENA_BACKUP_PASSWORD"
+)
+
+// SecretsConfig points to secrets mounted outside of the datadir, nothing is generated or written to the datadir
+// for secrets provided this way
+type SecretsConfig struct {
+	// NodeKeyFile contains the hex encoded node key or the key exported by dna_exportKey
+	NodeKeyFile string
+	// NodeKeyPasswordFile contains the password of the exported node key
+	NodeKeyPasswordFile string
+	// ApiKeyFile contains the RPC API key, the file is read again on config reload to rotate the key
+	ApiKeyFile    string
+	JWTSecretFile string
+}
+
+func (c *Config) secrets() *SecretsConfig {
+	if c.Secrets == nil {
+		return &SecretsConfig{}
+	}
+	return c.Secrets
+}
+
+// readSecret returns the trimmed value of the environment variable or the file, ok is false if neither is set
+func readSecret(env string, file string) (value string, ok bool, err error) {
+	if value, ok := os.LookupEnv(env); ok && value != "" {
+		return strings.TrimSpace(value), true, nil
+	}
+	if file == "" {
+		return "", false, nil
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", true, errors.Wrapf(err, "cannot read secret file %v", file)
+	}
+	value = strings.TrimSpace(string(data))
+	if value == "" {
+		return "", true, errors.Errorf("secret file %v is empty", file)
+	}
+	return value, true, nil
+}
+
+// ExternalNodeKey loads the node key provided by the environment or the secret file, ok is false if the key isn't
+// provided this way
+func (c *Config) ExternalNodeKey() (key *ecdsa.PrivateKey, ok bool, err error) {
+	value, ok, err := readSecret(NodeKeyEnv, c.secrets().NodeKeyFile)
+	if !ok || err != nil {
+		return nil, ok, err
+	}
+	keyBytes, err := hex.DecodeString(strings.TrimPrefix(value, "0x"))
+	if err != nil {
+		return nil, true, errors.Wrap(err, "node key isn't hex encoded")
+	}
+	password, encrypted, err := readSecret(NodeKeyPasswordEnv, c.secrets().NodeKeyPasswordFile)
+	if err != nil {
+		return nil, true, err
+	}
+	if encrypted {
+		if keyBytes, err = crypto.Decrypt(keyBytes, password); err != nil {
+			return nil, true, errors.Wrap(err, "cannot decrypt node key")
+		}
+	}
+	key, err = crypto.ToECDSA(keyBytes)
+	if err != nil {
+		return nil, true, errors.Wrap(err, "node key is not valid ECDSA key")
+	}
+	return key, true, nil
+}
+
+// ExternalApiKey returns the RPC API key provided by the environment or the secret file
+func (c *Config) ExternalApiKey() (string, bool, error) {
+	return readSecret(ApiKeyEnv, c.secrets().ApiKeyFile)
+}
+
+// LoadSecrets sets the API key and the JWT secret, secrets provided by the environment or secret files override
+// the config file
+func (c *Config) LoadSecrets() error {
+	if secret, ok, err := readSecret(JWTSecretEnv, c.secrets().JWTSecretFile); err != nil {
+		return err
+	} else if ok {
+		c.RPC.JWTSecret = secret
+	}
+	return errors.Wrap(c.SetApiKey(), "cannot set API key")
+}
+
+// RotateApiKey replaces the API key with the given one. If key isn't set, the key provided by the environment or the
+// secret file is read again or the random key is generated. Keys are saved to the datadir only if the external key
+// isn't configured
+func (c *Config) RotateApiKey(key string) (string, error) {
+	external, ok, err := c.ExternalApiKey()
+	if err != nil && key == "" {
+		return "", err
+	}
+	if ok {
+		if key == "" {
+			key = external
+		}
+		c.RPC.APIKey = key
+		return key, nil
+	}
+	if key == "" {
+		key = generateApiKey()
+	}
+	c.RPC.APIKey = key
+	return key, c.saveApiKey()
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"encoding/hex"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfig_ExternalSecrets(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "secrets")
+	defer os.RemoveAll(dir)
+	cfg := getDefaultConfig(filepath.Join(dir, "datadir"))
+	require.NoError(t, os.MkdirAll(cfg.DataDir, 0700))
+
+	key, _ := crypto.GenerateKey()
+	encrypted, err := crypto.Encrypt(crypto.FromECDSA(key), "pass")
+	require.NoError(t, err)
+	cfg.Secrets.NodeKeyFile = filepath.Join(dir, "nodekey")
+	cfg.Secrets.NodeKeyPasswordFile = filepath.Join(dir, "password")
+	require.NoError(t, ioutil.WriteFile(cfg.Secrets.NodeKeyFile, []byte(hex.EncodeToString(encrypted)+"\n"), 0600))
+	require.NoError(t, ioutil.WriteFile(cfg.Secrets.NodeKeyPasswordFile, []byte("pass"), 0600))
+	loaded, ok, err := cfg.ExternalNodeKey()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, crypto.FromECDSA(key), crypto.FromECDSA(loaded))
+	require.Equal(t, crypto.FromECDSA(key), crypto.FromECDSA(cfg.NodeKey()))
+	require.Nil(t, cfg.StoredNodeKey())
+
+	cfg.Secrets.ApiKeyFile = filepath.Join(dir, "apikey")
+	require.NoError(t, ioutil.WriteFile(cfg.Secrets.ApiKeyFile, []byte("first\n"), 0600))
+	require.NoError(t, cfg.LoadSecrets())
+	require.Equal(t, "first", cfg.RPC.APIKey)
+	_, err = os.Stat(filepath.Join(cfg.DataDir, apiKeyFileName))
+	require.True(t, os.IsNotExist(err))
+
+	require.NoError(t, ioutil.WriteFile(cfg.Secrets.ApiKeyFile, []byte("second"), 0600))
+	rotated, err := cfg.RotateApiKey("")
+	require.NoError(t, err)
+	require.Equal(t, "second", rotated)
+
+	os.Setenv(ApiKeyEnv, "env")
+	defer os.Unsetenv(ApiKeyEnv)
+	rotated, err = cfg.RotateApiKey("")
+	require.NoError(t, err)
+	require.Equal(t, "env", rotated)
+	require.Equal(t, "env", cfg.RPC.APIKey)
+}
+
+func TestConfig_RotateApiKey(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "secrets")
+	defer os.RemoveAll(dir)
+	cfg := getDefaultConfig(dir)
+	require.NoError(t, cfg.SetApiKey())
+	initial := cfg.RPC.APIKey
+	require.NotEmpty(t, initial)
+
+	rotated, err := cfg.RotateApiKey("")
+	require.NoError(t, err)
+	require.NotEqual(t, initial, rotated)
+	saved, _ := ioutil.ReadFile(filepath.Join(dir, apiKeyFileName))
+	require.Equal(t, rotated, string(saved))
+
+	rotated, err = cfg.RotateApiKey("manual")
+	require.NoError(t, err)
+	require.Equal(t, "manual", rotated)
+	saved, _ = ioutil.ReadFile(filepath.Join(dir, apiKeyFileName))
+	require.Equal(t, "manual", string(saved))
+}
+
+func TestConfig_ExternalApiKeyPrecedence(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "secrets")
+	defer os.RemoveAll(dir)
+	cfg := getDefaultConfig(dir)
+	cfg.RPC.APIKey = "config"
+	cfg.Secrets.ApiKeyFile = filepath.Join(dir, "apikey")
+	require.NoError(t, ioutil.WriteFile(cfg.Secrets.ApiKeyFile, []byte("file"), 0600))
+
+	require.NoError(t, cfg.LoadSecrets())
+	require.Equal(t, "file", cfg.RPC.APIKey)
+	_, err := os.Stat(filepath.Join(dir, apiKeyFileName))
+	require.True(t, os.IsNotExist(err))
+
+	// the reloaded config has the same key as the node started with it
+	reloaded := getDefaultConfig(dir)
+	reloaded.RPC.APIKey = "config"
+	reloaded.Secrets.ApiKeyFile = cfg.Secrets.ApiKeyFile
+	key, ok, err := reloaded.ExternalApiKey()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, cfg.RPC.APIKey, key)
+
+	os.Setenv(ApiKeyEnv, "env")
+	defer os.Unsetenv(ApiKeyEnv)
+	require.NoError(t, cfg.SetApiKey())
+	require.Equal(t, "env", cfg.RPC.APIKey)
+
+	os.Unsetenv(ApiKeyEnv)
+	cfg.Secrets.ApiKeyFile = ""
+	cfg.RPC.APIKey = "config"
+	require.NoError(t, cfg.SetApiKey())
+	require.Equal(t, "config", cfg.RPC.APIKey)
+	saved, _ := ioutil.ReadFile(filepath.Join(dir, apiKeyFileName))
+	require.Equal(t, "config", string(saved))
+}
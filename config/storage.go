@@ -0,0 +1,25 @@
+package config
+
+import "time"
+
+// StorageConfig sets the disk budget of the node data, it is shared by the chain database and the ipfs repo
+type StorageConfig struct {
+	// Budget is the disk space in bytes the node data may use, 0 disables budgeting
+	Budget uint64
+	// CompactThreshold is the budget share above which ipfs garbage collection and database compaction are triggered
+	CompactThreshold float64
+	// RefuseThreshold is the budget share above which optional pinning of network data is refused
+	RefuseThreshold float64
+	CheckInterval   time.Duration
+	// CompactCooldown is the minimal time between triggered compactions
+	CompactCooldown time.Duration
+}
+
+func GetDefaultStorageConfig() *StorageConfig {
+	return &StorageConfig{
+		CompactThreshold: 0.85,
+		RefuseThreshold:  0.95,
+		CheckInterval:    5 * time.Minute,
+		CompactCooldown:  time.Hour,
+	}
+}
@@ -1,6 +1,94 @@
 package config
 
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+)
+
+// DefaultCheckpoints are weak-subjectivity checkpoints shipped with the node, operator-supplied ones are appended
+var DefaultCheckpoints []Checkpoint
+
 type SyncConfig struct {
 	FastSync      bool
 	ForceFullSync uint64
+	Checkpoints   []Checkpoint
+	// ManifestSources is the min number of peers which should announce the same snapshot manifest to use it for fast sync
+	ManifestSources int
+	// ManifestPins are operator-pinned state roots of snapshot manifests by height
+	ManifestPins []ManifestPin
+	// HTTPMirror is the url of the HTTP mirror which is tried before IPFS to load block bodies and snapshots
+	HTTPMirror string
+}
+
+type ManifestPin struct {
+	Height uint64
+	Root   common.Hash
+}
+
+type Checkpoint struct {
+	Height       uint64
+	Hash         common.Hash
+	IdentityRoot common.Hash
+}
+
+// ParseCheckpoint parses checkpoint in format height:hash:identityRoot
+func ParseCheckpoint(value string) (Checkpoint, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return Checkpoint{}, errors.Errorf("invalid checkpoint %q, expected height:hash:identityRoot", value)
+	}
+	height, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil || height == 0 {
+		return Checkpoint{}, errors.Errorf("invalid checkpoint height %q", parts[0])
+	}
+	hash, err := parseHash(parts[1])
+	if err != nil {
+		return Checkpoint{}, errors.Wrap(err, "invalid checkpoint hash")
+	}
+	identityRoot, err := parseHash(parts[2])
+	if err != nil {
+		return Checkpoint{}, errors.Wrap(err, "invalid checkpoint identity root")
+	}
+	return Checkpoint{
+		Height:       height,
+		Hash:         hash,
+		IdentityRoot: identityRoot,
+	}, nil
+}
+
+// ParseManifestPin parses pinned snapshot manifest in format height:root
+func ParseManifestPin(value string) (ManifestPin, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		return ManifestPin{}, errors.Errorf("invalid manifest pin %q, expected height:root", value)
+	}
+	height, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil || height == 0 {
+		return ManifestPin{}, errors.Errorf("invalid manifest pin height %q", parts[0])
+	}
+	root, err := parseHash(parts[1])
+	if err != nil {
+		return ManifestPin{}, errors.Wrap(err, "invalid manifest pin root")
+	}
+	return ManifestPin{
+		Height: height,
+		Root:   root,
+	}, nil
+}
+
+func parseHash(value string) (common.Hash, error) {
+	if !strings.HasPrefix(value, "0x") {
+		value = "0x" + value
+	}
+	b, err := hexutil.Decode(value)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(b) != common.HashLength {
+		return common.Hash{}, errors.Errorf("expected %v bytes, got %v", common.HashLength, len(b))
+	}
+	return common.BytesToHash(b), nil
 }
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseCheckpoint(t *testing.T) {
+	hash := common.Hash{0x1}
+	identityRoot := common.Hash{0x2}
+
+	checkpoint, err := ParseCheckpoint("100:" + hash.Hex() + ":" + identityRoot.Hex()[2:])
+	require.NoError(t, err)
+	require.Equal(t, Checkpoint{Height: 100, Hash: hash, IdentityRoot: identityRoot}, checkpoint)
+
+	_, err = ParseCheckpoint("100:" + hash.Hex())
+	require.Error(t, err)
+
+	_, err = ParseCheckpoint("abc:" + hash.Hex() + ":" + identityRoot.Hex())
+	require.Error(t, err)
+
+	_, err = ParseCheckpoint("100:0x01:" + identityRoot.Hex())
+	require.Error(t, err)
+}
+
+func TestParseManifestPin(t *testing.T) {
+	root := common.Hash{0x1}
+
+	pin, err := ParseManifestPin("100:" + root.Hex()[2:])
+	require.NoError(t, err)
+	require.Equal(t, ManifestPin{Height: 100, Root: root}, pin)
+
+	_, err = ParseManifestPin("100")
+	require.Error(t, err)
+
+	_, err = ParseManifestPin("0:" + root.Hex())
+	require.Error(t, err)
+}
@@ -0,0 +1,20 @@
+package config
+
+import "time"
+
+type TimeSyncConfig struct {
+	NtpServer     string
+	CheckInterval time.Duration
+	// DriftThreshold is the clock drift reported as wrong time, 10s like the previous ntp check
+	DriftThreshold time.Duration
+	// ApplyOffset shifts ceremony timers by measured clock drift
+	ApplyOffset bool
+}
+
+func GetDefaultTimeSyncConfig() *TimeSyncConfig {
+	return &TimeSyncConfig{
+		NtpServer:      "pool.ntp.org",
+		CheckInterval:  time.Minute,
+		DriftThreshold: 10 * time.Second,
+	}
+}
@@ -9,6 +9,8 @@ const (
 	FlipLottery      = 5 * time.Minute
 	ShortSession     = 2 * time.Minute
 	AfterLongSession = 1 * time.Minute
+
+	DefaultAnswerDirectPeers = 6
 )
 
 type ValidationConfig struct {
@@ -22,6 +24,13 @@ type ValidationConfig struct {
 	LongSessionDuration time.Duration
 	// Do not use directly
 	AfterLongSessionDuration time.Duration
+	// Simulate runs local ceremony simulation on node start
+	Simulate bool
+	// AnswerRelays are RPC endpoints of trusted nodes which receive own answers directly besides the gossip
+	AnswerRelays []string
+	// AnswerDirectPeers is the number of peers which receive own answers as full transactions, negative disables
+	// the path. Do not use directly
+	AnswerDirectPeers int
 }
 
 func (cfg *ValidationConfig) GetNextValidationTime(validationTime time.Time, networkSize int) time.Time {
@@ -58,3 +67,13 @@ func (cfg *ValidationConfig) GetAfterLongSessionDuration() time.Duration {
 	}
 	return AfterLongSession
 }
+
+func (cfg *ValidationConfig) GetAnswerDirectPeers() int {
+	if cfg.AnswerDirectPeers < 0 {
+		return 0
+	}
+	if cfg.AnswerDirectPeers > 0 {
+		return cfg.AnswerDirectPeers
+	}
+	return DefaultAnswerDirectPeers
+}
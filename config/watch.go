@@ -0,0 +1,19 @@
+package config
+
+import "github.com/idena-network/idena-go/common"
+
+type WatchConfig struct {
+	// Addresses are added to the persistent watch list at start
+	Addresses []common.Address
+	// HistorySize is the max number of balance history entries kept per address, 0 - unlimited
+	HistorySize int
+	// RecentSize is the number of recent events kept in memory for polling clients
+	RecentSize int
+}
+
+func GetDefaultWatchConfig() *WatchConfig {
+	return &WatchConfig{
+		HistorySize: 10000,
+		RecentSize:  1000,
+	}
+}
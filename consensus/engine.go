@@ -10,6 +10,8 @@ import (
 	"github.com/idena-network/idena-go/config"
 	"github.com/idena-network/idena-go/core/appstate"
 	"github.com/idena-network/idena-go/core/mempool"
+	"github.com/idena-network/idena-go/core/signstore"
+	"github.com/idena-network/idena-go/core/standby"
 	"github.com/idena-network/idena-go/crypto"
 	"github.com/idena-network/idena-go/log"
 	"github.com/idena-network/idena-go/pengings"
@@ -20,6 +22,7 @@ import (
 	"github.com/shopspring/decimal"
 	math2 "math"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -55,13 +58,29 @@ type Engine struct {
 	offlineDetector   *blockchain.OfflineDetector
 	prevRoundDuration time.Duration
 	avgTimeDiffs      []decimal.Decimal
-	timeDrift         time.Duration
 	synced            bool
 	nextBlockDetector *nextBlockDetector
 	statsCollector    collector.StatsCollector
+	standby           *standby.Guard
+	signStore         *signstore.Store
+	// attempt is the number of retries of attemptRound, messages of retries are recorded separately by signStore
+	attemptRound  uint64
+	attempt       uint32
+	tracer        *roundTracer
+	participation *participationTracker
+	forkMonitor   *ForkMonitor
 
 	appStateCache      *appStateCache
 	appStateCacheMutex sync.Mutex
+
+	stop     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+	started  int32
+
+	// miningPaused stops proposing and voting, importPaused stops syncing and consensus rounds
+	miningPaused int32
+	importPaused int32
 }
 
 func NewEngine(chain *blockchain.Blockchain, gossipHandler *protocol.IdenaGossipHandler, proposals *pengings.Proposals, config *config.ConsensusConf,
@@ -69,7 +88,7 @@ func NewEngine(chain *blockchain.Blockchain, gossipHandler *protocol.IdenaGossip
 	votes *pengings.Votes,
 	txpool *mempool.TxPool, secStore *secstore.SecStore, downloader *protocol.Downloader,
 	offlineDetector *blockchain.OfflineDetector,
-	statsCollector collector.StatsCollector) *Engine {
+	statsCollector collector.StatsCollector, standby *standby.Guard, signStore *signstore.Store, forkMonitor *ForkMonitor) *Engine {
 	return &Engine{
 		chain:             chain,
 		pm:                gossipHandler,
@@ -81,10 +100,17 @@ func NewEngine(chain *blockchain.Blockchain, gossipHandler *protocol.IdenaGossip
 		txpool:            txpool,
 		downloader:        downloader,
 		secStore:          secStore,
-		forkResolver:      NewForkResolver([]ForkDetector{proposals, downloader}, downloader, chain, statsCollector),
+		forkResolver:      NewForkResolver([]ForkDetector{proposals, downloader}, downloader, chain, statsCollector, forkMonitor),
 		offlineDetector:   offlineDetector,
 		nextBlockDetector: newNextBlockDetector(gossipHandler, downloader, chain),
 		statsCollector:    statsCollector,
+		standby:           standby,
+		signStore:         signStore,
+		tracer:            newRoundTracer(MaxStoredRoundTraces),
+		participation:     newParticipationTracker(MaxStoredParticipations),
+		forkMonitor:       forkMonitor,
+		stop:              make(chan struct{}),
+		stopped:           make(chan struct{}),
 	}
 }
 
@@ -93,8 +119,55 @@ func (engine *Engine) Start() {
 	engine.addr = engine.secStore.GetAddress()
 	log.Info("Start consensus protocol", "pubKey", hexutil.Encode(engine.pubKey))
 	engine.forkResolver.Start()
+	atomic.StoreInt32(&engine.started, 1)
 	go engine.loop()
-	go engine.ntpTimeDriftUpdate()
+}
+
+// Stop asks consensus loop to exit after the current round, stops the fork resolver and waits until both are done or
+// timeout expires
+func (engine *Engine) Stop(timeout time.Duration) bool {
+	engine.stopOnce.Do(func() {
+		close(engine.stop)
+	})
+	if atomic.LoadInt32(&engine.started) == 0 {
+		return engine.forkResolver.Stop(timeout)
+	}
+	start := time.Now()
+	select {
+	case <-engine.stopped:
+	case <-time.After(timeout):
+		return false
+	}
+	return engine.forkResolver.Stop(timeout - time.Since(start))
+}
+
+func (engine *Engine) canSign(round uint64) bool {
+	return !engine.MiningPaused() && engine.standby.CanSign(round)
+}
+
+// SetMiningPaused stops or resumes proposing blocks and voting, the online status of the identity is not changed
+func (engine *Engine) SetMiningPaused(paused bool) {
+	atomic.StoreInt32(&engine.miningPaused, boolToInt32(paused))
+}
+
+func (engine *Engine) MiningPaused() bool {
+	return atomic.LoadInt32(&engine.miningPaused) == 1
+}
+
+// SetImportPaused stops or resumes syncing and consensus rounds after the current round
+func (engine *Engine) SetImportPaused(paused bool) {
+	atomic.StoreInt32(&engine.importPaused, boolToInt32(paused))
+}
+
+func (engine *Engine) ImportPaused() bool {
+	return atomic.LoadInt32(&engine.importPaused) == 1
+}
+
+func boolToInt32(value bool) int32 {
+	if value {
+		return 1
+	}
+	return 0
 }
 
 func (engine *Engine) GetProcess() string {
@@ -127,13 +200,14 @@ func (engine *Engine) alignTime() {
 		return
 	}
 
-	now := time.Now().UTC()
+	now := engine.chain.Now()
 	var offset time.Duration
+	timeDrift := engine.pm.TimeSync().Drift()
 	if len(engine.avgTimeDiffs) > 0 {
 		f, _ := decimal.Avg(engine.avgTimeDiffs[0], engine.avgTimeDiffs[1:]...).Float64()
 		offset = time.Duration(f * float64(time.Second))
-		if (offset < 0 && engine.timeDrift < 0 || offset > 0 && engine.timeDrift > 0) && math2.Abs(float64(engine.timeDrift-offset)) < float64(time.Second*2) {
-			offset = (offset + engine.timeDrift) / 2
+		if (offset < 0 && timeDrift < 0 || offset > 0 && timeDrift > 0) && math2.Abs(float64(timeDrift-offset)) < float64(time.Second*2) {
+			offset = (offset + timeDrift) / 2
 		} else {
 			offset = 0
 		}
@@ -142,7 +216,7 @@ func (engine *Engine) alignTime() {
 	headTime := time.Unix(engine.chain.Head.Time().Int64(), 0)
 
 	if correctedNow.After(headTime) {
-		maxDelay := engine.config.MinBlockDistance - engine.config.EstimatedBaVariance - engine.config.WaitSortitionProofDelay
+		maxDelay := engine.config.ProposalDelay()
 		diff := engine.config.MinBlockDistance - correctedNow.Sub(headTime)
 		diff = time.Duration(math.MinInt(int(diff), int(maxDelay)))
 		if diff > 0 {
@@ -159,7 +233,20 @@ func (engine *Engine) calculateTimeDiff(round uint64, roundStart time.Time) {
 }
 
 func (engine *Engine) loop() {
+	defer close(engine.stopped)
 	for {
+		select {
+		case <-engine.stop:
+			engine.log.Info("Consensus loop is stopped", "head", engine.chain.Head.Height())
+			return
+		default:
+		}
+		if engine.ImportPaused() {
+			engine.process = "Block import is paused"
+			engine.synced = false
+			time.Sleep(time.Second)
+			continue
+		}
 		if err := engine.chain.EnsureIntegrity(); err != nil {
 			engine.log.Error("Failed to recover blockchain", "err", err)
 			time.Sleep(time.Second * 30)
@@ -186,11 +273,14 @@ func (engine *Engine) loop() {
 
 		round := head.Height() + 1
 		engine.completeRound(round - 1)
+		engine.startAttempt(round)
 
 		engine.alignTime()
 
 		engine.prevRoundDuration = 0
 		roundStart := time.Now().UTC()
+		engine.tracer.start(round, head.Hash())
+		engine.participation.start(round)
 
 		engine.log.Info("Start loop", "round", round, "head", head.Hash().Hex(), "peers",
 			engine.pm.PeersCount(), "online-nodes", engine.appState.ValidatorsCache.OnlineSize(),
@@ -201,10 +291,15 @@ func (engine *Engine) loop() {
 		isProposer, proposerHash, proposerProof := engine.chain.GetProposerSortition()
 
 		var block *types.Block
-		if isProposer {
+		if isProposer && engine.canSign(round) {
 			engine.process = "Propose block"
 			block = engine.proposeBlock(proposerHash, proposerProof)
 			if block != nil {
+				engine.participation.propose(round, block.Hash())
+				engine.tracer.update(round, func(trace *RoundTrace) {
+					hash := block.Hash()
+					trace.OwnProposal = &hash
+				})
 				engine.log.Info("Selected as proposer", "block", block.Hash().Hex(), "round", round, "thresholdVrf", engine.appState.State.VrfProposerThreshold())
 			}
 		}
@@ -216,6 +311,12 @@ func (engine *Engine) loop() {
 		proposer := engine.fmtProposer(proposerPubKey)
 
 		engine.log.Info("Selected proposer", "proposer", proposer)
+		engine.tracer.update(round, func(trace *RoundTrace) {
+			trace.Proposer = proposerPubKey
+			for _, proof := range engine.proposals.GetProofs(round) {
+				trace.Proofs = append(trace.Proofs, ProposalTrace{Hash: proof.Hash, PubKey: proof.PubKey})
+			}
+		})
 		emptyBlock := engine.chain.GenerateEmptyBlock()
 		if proposerPubKey == nil {
 			block = emptyBlock
@@ -226,13 +327,22 @@ func (engine *Engine) loop() {
 
 			if block == nil {
 				block = emptyBlock
+			} else {
+				engine.tracer.update(round, func(trace *RoundTrace) {
+					hash := block.Hash()
+					trace.ReceivedBlock = &hash
+				})
 			}
 		}
+		engine.tracer.update(round, func(trace *RoundTrace) {
+			trace.Blocks = engine.proposals.GetBlockHashes(round)
+		})
 
 		blockHash := engine.reduction(round, block)
 		blockHash, cert, err := engine.binaryBa(blockHash)
 		if err != nil {
 			engine.log.Info("Binary Ba is failed", "err", err)
+			engine.completeTrace(round, RoundFailed, common.Hash{}, nil, err)
 
 			if err == ForkDetected {
 				if err = engine.forkResolver.ApplyFork(); err != nil {
@@ -250,34 +360,60 @@ func (engine *Engine) loop() {
 		if blockHash == emptyBlock.Hash() {
 			if err := engine.chain.AddBlock(emptyBlock, nil, engine.statsCollector); err != nil {
 				engine.log.Error("Add empty block", "err", err)
+				engine.completeTrace(round, RoundFailed, blockHash, certVoters(cert), err)
 				continue
 			}
 
 			engine.chain.WriteCertificate(blockHash, cert.Compress(), engine.chain.IsPermanentCert(emptyBlock.Header))
 			engine.log.Info("Reached consensus on empty block")
+			engine.completeTrace(round, RoundEmpty, blockHash, certVoters(cert), nil)
 		} else {
 			block, err := engine.getBlockByHash(round, blockHash)
 			if err == nil {
 				if err := engine.chain.AddBlock(block, nil, engine.statsCollector); err != nil {
 					engine.log.Error("Add block", "err", err)
+					engine.completeTrace(round, RoundFailed, blockHash, certVoters(cert), err)
 					continue
 				}
+				result := RoundTentative
 				if hash == blockHash {
 					engine.log.Info("Reached FINAL", "block", blockHash.Hex(), "txs", len(block.Body.Transactions))
 					engine.chain.WriteFinalConsensus(blockHash)
 					cert = finalCert
+					result = RoundFinal
 				} else {
 					engine.log.Info("Reached TENTATIVE", "block", blockHash.Hex(), "txs", len(block.Body.Transactions))
 				}
 				engine.chain.WriteCertificate(blockHash, cert.Compress(), engine.chain.IsPermanentCert(block.Header))
+				engine.completeTrace(round, result, blockHash, certVoters(cert), nil)
 			} else {
 				engine.log.Warn("Confirmed block is not found", "block", blockHash.Hex())
+				engine.completeTrace(round, RoundFailed, blockHash, certVoters(cert), err)
 			}
 		}
 		engine.prevRoundDuration = time.Now().UTC().Sub(roundStart)
 	}
 }
 
+// startAttempt starts the next attempt of the round, the counter is reset for a new round
+func (engine *Engine) startAttempt(round uint64) {
+	if engine.attemptRound == round {
+		engine.attempt++
+		engine.log.Info("Round is retried", "round", round, "attempt", engine.attempt)
+		return
+	}
+	engine.attemptRound, engine.attempt = round, 0
+}
+
+// record checks the message against messages signed at the current attempt of the round
+func (engine *Engine) record(round uint64, step uint8, hash common.Hash) error {
+	var attempt uint32
+	if round == engine.attemptRound {
+		attempt = engine.attempt
+	}
+	return engine.signStore.Record(round, attempt, step, hash)
+}
+
 func (engine *Engine) fmtProposer(proposerPubKey []byte) string {
 	var proposer string
 	if proposer = hexutil.Encode(proposerPubKey); len(proposerPubKey) == 0 {
@@ -306,7 +442,16 @@ func (engine *Engine) completeRound(round uint64) {
 }
 
 func (engine *Engine) proposeBlock(hash common.Hash, proof []byte) *types.Block {
-	proposal := engine.chain.ProposeBlock()
+	block := engine.chain.BuildProposedBlock()
+	if len(block.Body.Transactions) == 0 && !engine.config.ProposeEmptyBlocks {
+		engine.log.Debug("Proposal without transactions is skipped", "round", block.Height())
+		return nil
+	}
+	if err := engine.record(block.Height(), signstore.ProposalStep, block.Hash()); err != nil {
+		engine.log.Error("Block proposal is not signed", "err", err)
+		return nil
+	}
+	proposal := &types.BlockProposal{Block: block, Signature: engine.secStore.Sign(block.Hash().Bytes())}
 
 	engine.log.Info("Proposed block", "block", proposal.Hash().Hex(), "txs", len(proposal.Body.Transactions))
 
@@ -423,6 +568,9 @@ func (engine *Engine) binaryBa(blockHash common.Hash) (common.Hash, *types.FullB
 }
 
 func (engine *Engine) vote(round uint64, step uint8, block common.Hash) {
+	if !engine.canSign(round) {
+		return
+	}
 	committeeSize := engine.chain.GetCommitteeSize(engine.appState.ValidatorsCache, step == types.Final)
 	stepValidators := engine.appState.ValidatorsCache.GetOnlineValidators(engine.chain.Head.Seed(), round, step, committeeSize)
 	if stepValidators == nil {
@@ -440,8 +588,15 @@ func (engine *Engine) vote(round uint64, step uint8, block common.Hash) {
 		if b, err := engine.proposals.GetBlockByHash(round, block); err == nil {
 			vote.Header.TurnOffline = engine.offlineDetector.VoteForOffline(b)
 		}
+		if err := engine.record(round, step, vote.Header.SignatureHash()); err != nil {
+			engine.log.Error("Vote is not signed", "err", err)
+			engine.participation.vote(round, step, block, err)
+			return
+		}
 		vote.Signature = engine.secStore.Sign(vote.Header.SignatureHash().Bytes())
 		engine.pm.SendVote(&vote)
+		engine.tracer.vote(round, step, block)
+		engine.participation.vote(round, step, block, nil)
 
 		engine.log.Info("Voted for", "step", step, "block", block.Hex())
 
@@ -451,12 +606,22 @@ func (engine *Engine) vote(round uint64, step uint8, block common.Hash) {
 	}
 }
 
-func (engine *Engine) countVotes(round uint64, step uint8, parentHash common.Hash, necessaryVotesCount int, timeout time.Duration) (common.Hash, *types.FullBlockCert, error) {
+func (engine *Engine) countVotes(round uint64, step uint8, parentHash common.Hash, necessaryVotesCount int, timeout time.Duration) (resultHash common.Hash, resultCert *types.FullBlockCert, err error) {
 
 	engine.log.Debug("Start count votes", "step", step, "min-votes", necessaryVotesCount)
 	defer engine.log.Debug("Finish count votes", "step", step)
 
 	byBlock := make(map[common.Hash]map[common.Address]*types.Vote)
+	engine.tracer.startStep(round, step, necessaryVotesCount)
+	defer func() {
+		votes := make(map[common.Hash]int, len(byBlock))
+		for hash, voters := range byBlock {
+			if len(voters) > 0 {
+				votes[hash] = len(voters)
+			}
+		}
+		engine.tracer.completeStep(round, step, votes, resultHash, err != nil)
+	}()
 	validators := engine.appState.ValidatorsCache.GetOnlineValidators(engine.chain.Head.Seed(), round, step, engine.chain.GetCommitteeSize(engine.appState.ValidatorsCache, step == types.Final))
 	if validators == nil {
 		return common.Hash{}, nil, errors.Errorf("validators were not setup, step=%v", step)
@@ -539,13 +704,35 @@ func (engine *Engine) getBlockByHash(round uint64, hash common.Hash) (*types.Blo
 	return nil, errors.New("Block is not found")
 }
 
-func (engine *Engine) ntpTimeDriftUpdate() {
-	for {
-		if drift, err := protocol.SntpDrift(3); err == nil {
-			engine.timeDrift = drift
-		}
-		time.Sleep(time.Minute)
+func (engine *Engine) completeTrace(round uint64, result RoundResult, block common.Hash, cert []common.Address, err error) {
+	engine.tracer.complete(round, result, block, cert, err)
+	engine.participation.complete(round, result, block, cert, engine.addr)
+}
+
+// CommitteeHistory returns recent rounds the node identity has been selected to propose or vote in
+func (engine *Engine) CommitteeHistory(count int) []CommitteeParticipation {
+	return engine.participation.history(count)
+}
+
+// RoundTrace returns the trace of the recent consensus round or nil if it is not stored
+func (engine *Engine) RoundTrace(round uint64) *RoundTrace {
+	return engine.tracer.get(round)
+}
+
+func certVoters(cert *types.FullBlockCert) []common.Address {
+	if cert == nil {
+		return nil
 	}
+	result := make([]common.Address, 0, len(cert.Votes))
+	for _, vote := range cert.Votes {
+		result = append(result, vote.VoterAddr())
+	}
+	return result
+}
+
+// Forks returns competing branches recently loaded from peers
+func (engine *Engine) Forks() []Fork {
+	return engine.forkMonitor.Forks()
 }
 
 func (engine *Engine) Synced() bool {
@@ -0,0 +1,40 @@
+package consensus
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/core/signstore"
+	"github.com/idena-network/idena-go/log"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	db "github.com/tendermint/tm-db"
+	"testing"
+)
+
+func TestEngine_RetriedRound(t *testing.T) {
+	require := require.New(t)
+	memdb := db.NewMemDB()
+	engine := &Engine{log: log.New(), signStore: signstore.NewStore(memdb)}
+
+	block, emptyBlock := common.Hash{0x1}, common.Hash{0x2}
+
+	engine.startAttempt(10)
+	require.NoError(engine.record(10, signstore.ProposalStep, block))
+	require.NoError(engine.record(10, 1, block))
+	require.Equal(signstore.ConflictingMessage, errors.Cause(engine.record(10, 1, emptyBlock)))
+
+	// binary BA has failed and the round is started again, votes of the new attempt may differ
+	engine.startAttempt(10)
+	require.Equal(uint32(1), engine.attempt)
+	require.NoError(engine.record(10, signstore.ProposalStep, emptyBlock))
+	require.NoError(engine.record(10, 1, emptyBlock))
+	require.Equal(signstore.ConflictingMessage, errors.Cause(engine.record(10, 1, block)))
+
+	engine.startAttempt(11)
+	require.Zero(engine.attempt)
+	require.NoError(engine.record(11, 1, block))
+
+	// the restarted node counts attempts from scratch, so it doesn't sign conflicting messages of the interrupted round
+	engine = &Engine{log: log.New(), signStore: signstore.NewStore(memdb)}
+	engine.startAttempt(10)
+	require.Equal(signstore.ConflictingMessage, errors.Cause(engine.record(10, 1, emptyBlock)))
+}
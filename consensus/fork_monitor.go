@@ -0,0 +1,197 @@
+package consensus
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/log"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/pkg/errors"
+	"github.com/rcrowley/go-metrics"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	ForkStatusDetected = "detected"
+	ForkStatusRejected = "rejected"
+	ForkStatusApplied  = "applied"
+)
+
+// Fork describes the competing branch seen from peers, the branch is identified by its first block after the common height
+type Fork struct {
+	Hash         common.Hash `json:"hash"`
+	CommonHeight uint64      `json:"commonHeight"`
+	Length       uint64      `json:"length"`
+	Head         common.Hash `json:"head"`
+	HeadHeight   uint64      `json:"headHeight"`
+	LocalHeight  uint64      `json:"localHeight"`
+	Peers        []string    `json:"peers"`
+	FirstSeen    int64       `json:"firstSeen"`
+	LastSeen     int64       `json:"lastSeen"`
+	Status       string      `json:"status"`
+	Reason       string      `json:"reason,omitempty"`
+	Alerted      bool        `json:"alerted"`
+}
+
+// ForkMonitor records forks loaded by the fork resolver and alerts when a fork exceeds the configured length
+type ForkMonitor struct {
+	cfg    *config.ForkMonitorConfig
+	client *http.Client
+	log    log.Logger
+	mutex  sync.Mutex
+	forks  []*Fork
+
+	detected  metrics.Counter
+	maxLength metrics.Gauge
+}
+
+func NewForkMonitor(cfg *config.ForkMonitorConfig) *ForkMonitor {
+	return &ForkMonitor{
+		cfg:       cfg,
+		client:    &http.Client{Timeout: cfg.Timeout},
+		log:       log.New("component", "forkmonitor"),
+		detected:  metrics.GetOrRegisterCounter("forks.detected", metrics.DefaultRegistry),
+		maxLength: metrics.GetOrRegisterGauge("forks.max_length", metrics.DefaultRegistry),
+	}
+}
+
+func (m *ForkMonitor) find(hash common.Hash) *Fork {
+	for _, fork := range m.forks {
+		if fork.Hash == hash {
+			return fork
+		}
+	}
+	return nil
+}
+
+// Record registers the fork blocks loaded from the peer, blocks should be sorted by height
+func (m *ForkMonitor) Record(peerId peer.ID, blocks []types.BlockBundle, localHeight uint64) *Fork {
+	if m == nil || len(blocks) == 0 {
+		return nil
+	}
+	first, last := blocks[0].Block, blocks[len(blocks)-1].Block
+	now := time.Now().Unix()
+
+	m.mutex.Lock()
+	fork := m.find(first.Hash())
+	if fork == nil {
+		fork = &Fork{
+			Hash:         first.Hash(),
+			CommonHeight: first.Height() - 1,
+			FirstSeen:    now,
+			Status:       ForkStatusDetected,
+		}
+		m.forks = append(m.forks, fork)
+		if m.cfg.HistorySize > 0 && len(m.forks) > m.cfg.HistorySize {
+			m.forks = m.forks[len(m.forks)-m.cfg.HistorySize:]
+		}
+		m.detected.Inc(1)
+	}
+	if last.Height() >= fork.HeadHeight {
+		fork.Head = last.Hash()
+		fork.HeadHeight = last.Height()
+		fork.Length = last.Height() - fork.CommonHeight
+	}
+	fork.LocalHeight = localHeight
+	fork.LastSeen = now
+	if !containsPeer(fork.Peers, peerId.Pretty()) {
+		fork.Peers = append(fork.Peers, peerId.Pretty())
+	}
+	m.updateMaxLength()
+	alert := m.cfg.AlertThreshold > 0 && fork.Length >= m.cfg.AlertThreshold && !fork.Alerted
+	if alert {
+		fork.Alerted = true
+	}
+	result := *fork
+	m.mutex.Unlock()
+
+	m.log.Info("Fork recorded", "common height", result.CommonHeight, "length", result.Length, "peer", peerId)
+	if alert {
+		m.alert(result)
+	}
+	return &result
+}
+
+// SetStatus sets the status of the recorded fork after the fork resolver has made a decision
+func (m *ForkMonitor) SetStatus(hash common.Hash, status string, reason error) {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if fork := m.find(hash); fork != nil {
+		fork.Status = status
+		fork.Reason = ""
+		if reason != nil {
+			fork.Reason = reason.Error()
+		}
+	}
+}
+
+func (m *ForkMonitor) updateMaxLength() {
+	max := uint64(0)
+	for _, fork := range m.forks {
+		if fork.Length > max {
+			max = fork.Length
+		}
+	}
+	m.maxLength.Update(int64(max))
+}
+
+// Forks returns recorded forks, the most recent first
+func (m *ForkMonitor) Forks() []Fork {
+	if m == nil {
+		return nil
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	result := make([]Fork, 0, len(m.forks))
+	for i := len(m.forks) - 1; i >= 0; i-- {
+		fork := *m.forks[i]
+		fork.Peers = append([]string{}, fork.Peers...)
+		result = append(result, fork)
+	}
+	return result
+}
+
+func (m *ForkMonitor) alert(fork Fork) {
+	m.log.Warn("Long fork detected", "common height", fork.CommonHeight, "length", fork.Length,
+		"head", fork.Head.Hex(), "peers", len(fork.Peers))
+	if m.cfg.Webhook == "" {
+		return
+	}
+	go func() {
+		if err := m.post(fork); err != nil {
+			m.log.Warn("Failed to deliver fork alert", "url", m.cfg.Webhook, "err", err)
+		}
+	}()
+}
+
+func (m *ForkMonitor) post(fork Fork) error {
+	body, err := json.Marshal(fork)
+	if err != nil {
+		return err
+	}
+	resp, err := m.client.Post(m.cfg.Webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("unexpected status %v", resp.StatusCode)
+	}
+	return nil
+}
+
+func containsPeer(peers []string, id string) bool {
+	for _, p := range peers {
+		if p == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package consensus
+
+import (
+	"encoding/json"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/config"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func forkBlocks(from, to uint64, seed byte) []types.BlockBundle {
+	var result []types.BlockBundle
+	for h := from; h <= to; h++ {
+		result = append(result, types.BlockBundle{Block: &types.Block{Header: &types.Header{
+			EmptyBlockHeader: &types.EmptyBlockHeader{Height: h, Time: big.NewInt(0), BlockSeed: types.Seed{seed}},
+		}}})
+	}
+	return result
+}
+
+func TestForkMonitor_Record(t *testing.T) {
+	alerts := make(chan Fork, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var fork Fork
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&fork))
+		alerts <- fork
+	}))
+	defer server.Close()
+
+	cfg := config.GetDefaultForkMonitorConfig()
+	cfg.AlertThreshold = 5
+	cfg.Webhook = server.URL
+	monitor := NewForkMonitor(cfg)
+
+	blocks := forkBlocks(11, 13, 1)
+	monitor.Record("peer1", blocks, 13)
+	forks := monitor.Forks()
+	require.Len(t, forks, 1)
+	require.Equal(t, uint64(10), forks[0].CommonHeight)
+	require.Equal(t, uint64(3), forks[0].Length)
+	require.Equal(t, ForkStatusDetected, forks[0].Status)
+	require.False(t, forks[0].Alerted)
+
+	// the same branch has grown and is seen from another peer
+	monitor.Record("peer2", forkBlocks(11, 16, 1), 15)
+	monitor.SetStatus(blocks[0].Block.Hash(), ForkStatusRejected, errors.New("fork has worse seed"))
+	forks = monitor.Forks()
+	require.Len(t, forks, 1)
+	require.Equal(t, uint64(6), forks[0].Length)
+	require.Len(t, forks[0].Peers, 2)
+	require.True(t, forks[0].Alerted)
+	require.Equal(t, ForkStatusRejected, forks[0].Status)
+	require.Equal(t, "fork has worse seed", forks[0].Reason)
+
+	select {
+	case fork := <-alerts:
+		require.Equal(t, uint64(6), fork.Length)
+	case <-time.After(5 * time.Second):
+		t.Fatal("alert is not delivered")
+	}
+
+	// alert is sent once per fork
+	monitor.Record("peer3", forkBlocks(11, 17, 1), 15)
+	monitor.Record("peer1", forkBlocks(21, 22, 2), 25)
+	forks = monitor.Forks()
+	require.Len(t, forks, 2)
+	require.Equal(t, uint64(20), forks[0].CommonHeight)
+	require.NotEqual(t, common.Hash{}, forks[0].Head)
+	select {
+	case <-alerts:
+		t.Fatal("alert is sent twice")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/pkg/errors"
 	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -22,6 +24,12 @@ type ForkResolver struct {
 	triedPeers     mapset.Set
 	applicableFork *applicableFork
 	statsCollector collector.StatsCollector
+	forkMonitor    *ForkMonitor
+
+	stop     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+	started  int32
 }
 
 type applicableFork struct {
@@ -30,7 +38,7 @@ type applicableFork struct {
 }
 
 func NewForkResolver(forkDetectors []ForkDetector, downloader *protocol.Downloader, chain *blockchain.Blockchain,
-	statsCollector collector.StatsCollector) *ForkResolver {
+	statsCollector collector.StatsCollector, forkMonitor *ForkMonitor) *ForkResolver {
 	return &ForkResolver{
 		forkDetectors:  forkDetectors,
 		downloader:     downloader,
@@ -38,6 +46,9 @@ func NewForkResolver(forkDetectors []ForkDetector, downloader *protocol.Download
 		log:            log.New(),
 		triedPeers:     mapset.NewSet(),
 		statsCollector: statsCollector,
+		forkMonitor:    forkMonitor,
+		stop:           make(chan struct{}),
+		stopped:        make(chan struct{}),
 	}
 }
 
@@ -86,9 +97,12 @@ func (resolver *ForkResolver) processBlocks(blocks chan types.BlockBundle, peerI
 
 	resolver.log.Info("common block is found", "peerId", peerId)
 	forkBlocks = sortBlocks(forkBlocks)
+	forkHash := forkBlocks[0].Block.Hash()
+	resolver.forkMonitor.Record(peerId, forkBlocks, resolver.chain.Head.Height())
 	if err := resolver.checkForkSize(forkBlocks); err == nil {
 		commonHeight := forkBlocks[0].Block.Height() - 1
 		if err := resolver.chain.ValidateSubChain(commonHeight, forkBlocks); err != nil {
+			resolver.forkMonitor.SetStatus(forkHash, ForkStatusRejected, err)
 			return errors.Errorf("unacceptable fork, peerId=%v, err=%v", peerId, err)
 		} else {
 			resolver.log.Info("applicable fork is detected", "peerId", peerId, "commonHeight", commonHeight, "size", len(forkBlocks))
@@ -98,6 +112,7 @@ func (resolver *ForkResolver) processBlocks(blocks chan types.BlockBundle, peerI
 			}
 		}
 	} else {
+		resolver.forkMonitor.SetStatus(forkHash, ForkStatusRejected, err)
 		return errors.Errorf("fork is smaller, peerId=%v, err=%v", peerId, err)
 	}
 	return nil
@@ -155,14 +170,25 @@ func (resolver *ForkResolver) applyFork(commonHeight uint64, fork []types.BlockB
 		}
 	}()
 
+	oldHead := resolver.chain.Head
+	oldBlocks := resolver.chain.GetBlocksAbove(commonHeight)
 	if err := resolver.chain.ResetTo(commonHeight); err != nil {
 		return err
 	}
+	var applied []*types.Block
+	// txs of replaced blocks should be returned to the mempool even if the fork has been applied partially
+	defer func() {
+		resolver.chain.PublishReorg(oldHead, commonHeight, oldBlocks, applied)
+	}()
 	for _, bundle := range fork {
 		if err := resolver.chain.AddBlock(bundle.Block, nil, resolver.statsCollector); err != nil {
 			return err
 		}
 		resolver.chain.WriteCertificate(bundle.Block.Hash(), bundle.Cert, false)
+		applied = append(applied, bundle.Block)
+	}
+	if len(fork) > 0 {
+		resolver.forkMonitor.SetStatus(fork[0].Block.Hash(), ForkStatusApplied, nil)
 	}
 
 	return nil
@@ -176,9 +202,15 @@ func (resolver *ForkResolver) ApplyFork() error {
 }
 
 func (resolver *ForkResolver) Start() {
+	atomic.StoreInt32(&resolver.started, 1)
 	go func() {
+		defer close(resolver.stopped)
 		for {
-			time.Sleep(time.Second * 30)
+			select {
+			case <-resolver.stop:
+				return
+			case <-time.After(time.Second * 30):
+			}
 			if resolver.HasLoadedFork() {
 				continue
 			}
@@ -187,13 +219,33 @@ func (resolver *ForkResolver) Start() {
 	}()
 	go func() {
 		for {
-			time.Sleep(time.Minute * 10)
+			select {
+			case <-resolver.stop:
+				return
+			case <-time.After(time.Minute * 10):
+			}
 			resolver.triedPeers.Clear()
 			resolver.log.Debug("Tried peers has been cleared")
 		}
 	}()
 }
 
+// Stop stops fork loading and waits until the fork being loaded is verified or timeout expires
+func (resolver *ForkResolver) Stop(timeout time.Duration) bool {
+	resolver.stopOnce.Do(func() {
+		close(resolver.stop)
+	})
+	if atomic.LoadInt32(&resolver.started) == 0 {
+		return true
+	}
+	select {
+	case <-resolver.stopped:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
 func (resolver *ForkResolver) HasLoadedFork() bool {
 	return resolver.applicableFork != nil
 }
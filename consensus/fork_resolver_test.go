@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestForkResolver_ResolveFork(t *testing.T) {
@@ -28,7 +29,7 @@ func TestForkResolver_ResolveFork(t *testing.T) {
 	require.NotEqual(t, forkHashes, initialHashes)
 
 	//small fork
-	resolver := NewForkResolver([]ForkDetector{}, nil, chain.Blockchain, collector.NewStatsCollector())
+	resolver := NewForkResolver([]ForkDetector{}, nil, chain.Blockchain, collector.NewStatsCollector(), nil)
 	forkBlocks := chain2.ReadBlockForForkedPeer(initialHashes)
 	require.Len(t, forkBlocks, 21)
 	blocks := make(chan types.BlockBundle, 21)
@@ -71,7 +72,7 @@ func TestForkResolver_ResolveFork2(t *testing.T) {
 	chain2.GenerateBlocks(50)
 
 	//missed certificate
-	resolver := NewForkResolver([]ForkDetector{}, &protocol.Downloader{}, chain.Blockchain, collector.NewStatsCollector())
+	resolver := NewForkResolver([]ForkDetector{}, &protocol.Downloader{}, chain.Blockchain, collector.NewStatsCollector(), nil)
 	initialHashes := chain.GetTopBlockHashes(100)
 	forkBlocks := chain2.ReadBlockForForkedPeer(initialHashes)
 	blocks := make(chan types.BlockBundle, 22)
@@ -125,3 +126,15 @@ func TestForkResolver_ResolveFork2(t *testing.T) {
 	err = resolver.processBlocks(blocks, "test-peer")
 	require.Error(t, err)
 }
+
+func TestForkResolver_Stop(t *testing.T) {
+	resolver := NewForkResolver([]ForkDetector{}, &protocol.Downloader{}, nil, collector.NewStatsCollector(), nil)
+	start := time.Now()
+	require.True(t, resolver.Stop(time.Minute))
+	require.True(t, (&protocol.Downloader{}).Stop(time.Minute))
+	require.True(t, time.Since(start) < time.Second)
+
+	resolver = NewForkResolver([]ForkDetector{}, &protocol.Downloader{}, nil, collector.NewStatsCollector(), nil)
+	resolver.Start()
+	require.True(t, resolver.Stop(time.Second))
+}
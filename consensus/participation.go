@@ -0,0 +1,164 @@
+package consensus
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/rcrowley/go-metrics"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	MaxStoredParticipations = 1000
+)
+
+type ParticipationVote struct {
+	Step      uint8
+	VotedHash common.Hash
+	Time      time.Time
+	Error     string
+}
+
+// CommitteeParticipation describes the work of the node identity in the round it has been selected
+// into the proposer set or a voting committee
+type CommitteeParticipation struct {
+	Round    uint64
+	Start    time.Time
+	Proposer bool
+	Proposal *common.Hash
+	Votes    []ParticipationVote
+	Result   RoundResult
+	Block    common.Hash
+	// ProposalAccepted is set if the own proposal has become the block of the round
+	ProposalAccepted bool
+	// InCert is set if the own vote has been included into the certificate of the round block
+	InCert bool
+}
+
+func (p *CommitteeParticipation) copy() CommitteeParticipation {
+	result := *p
+	result.Votes = append([]ParticipationVote{}, p.Votes...)
+	return result
+}
+
+// participationTracker keeps rounds the node identity has participated in and counts consensus work metrics
+type participationTracker struct {
+	mutex sync.RWMutex
+	items []*CommitteeParticipation
+	// round and roundStart are the current round and its start time
+	round      uint64
+	roundStart time.Time
+
+	proposerSelected metrics.Counter
+	proposalAccepted metrics.Counter
+	votes            metrics.Counter
+	failedVotes      metrics.Counter
+	certVotes        metrics.Counter
+}
+
+func newParticipationTracker(size int) *participationTracker {
+	return &participationTracker{
+		items:            make([]*CommitteeParticipation, size),
+		proposerSelected: metrics.GetOrRegisterCounter("consensus.proposer_selected", metrics.DefaultRegistry),
+		proposalAccepted: metrics.GetOrRegisterCounter("consensus.proposal_accepted", metrics.DefaultRegistry),
+		votes:            metrics.GetOrRegisterCounter("consensus.votes", metrics.DefaultRegistry),
+		failedVotes:      metrics.GetOrRegisterCounter("consensus.votes_failed", metrics.DefaultRegistry),
+		certVotes:        metrics.GetOrRegisterCounter("consensus.cert_votes", metrics.DefaultRegistry),
+	}
+}
+
+func (t *participationTracker) get(round uint64, create bool) *CommitteeParticipation {
+	idx := round % uint64(len(t.items))
+	item := t.items[idx]
+	if item != nil && item.Round == round {
+		return item
+	}
+	if !create {
+		return nil
+	}
+	item = &CommitteeParticipation{
+		Round:  round,
+		Start:  time.Now().UTC(),
+		Result: RoundInProgress,
+	}
+	if round == t.round {
+		item.Start = t.roundStart
+	}
+	t.items[idx] = item
+	return item
+}
+
+func (t *participationTracker) start(round uint64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.round = round
+	t.roundStart = time.Now().UTC()
+}
+
+func (t *participationTracker) propose(round uint64, block common.Hash) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	item := t.get(round, true)
+	item.Proposer = true
+	item.Proposal = &block
+	t.proposerSelected.Inc(1)
+}
+
+func (t *participationTracker) vote(round uint64, step uint8, block common.Hash, err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	vote := ParticipationVote{
+		Step:      step,
+		VotedHash: block,
+		Time:      time.Now().UTC(),
+	}
+	if err != nil {
+		vote.Error = err.Error()
+		t.failedVotes.Inc(1)
+	} else {
+		t.votes.Inc(1)
+	}
+	item := t.get(round, true)
+	item.Votes = append(item.Votes, vote)
+}
+
+func (t *participationTracker) complete(round uint64, result RoundResult, block common.Hash, cert []common.Address, addr common.Address) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	item := t.get(round, false)
+	if item == nil {
+		return
+	}
+	item.Result = result
+	item.Block = block
+	if item.Proposal != nil && *item.Proposal == block && result != RoundFailed {
+		item.ProposalAccepted = true
+		t.proposalAccepted.Inc(1)
+	}
+	for _, voter := range cert {
+		if voter == addr {
+			item.InCert = true
+			t.certVotes.Inc(1)
+			break
+		}
+	}
+}
+
+// history returns at most count recent participations, the most recent first
+func (t *participationTracker) history(count int) []CommitteeParticipation {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	var result []CommitteeParticipation
+	for _, item := range t.items {
+		if item != nil {
+			result = append(result, item.copy())
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Round > result[j].Round
+	})
+	if count > 0 && len(result) > count {
+		result = result[:count]
+	}
+	return result
+}
@@ -0,0 +1,51 @@
+package consensus
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParticipationTracker(t *testing.T) {
+	require := require.New(t)
+	tracker := newParticipationTracker(3)
+	addr := common.Address{0x1}
+
+	tracker.start(10)
+	tracker.propose(10, common.Hash{0x2})
+	tracker.vote(10, 1, common.Hash{0x2}, nil)
+	tracker.complete(10, RoundFinal, common.Hash{0x2}, []common.Address{{0x3}, addr}, addr)
+
+	// rounds without own participation are not tracked
+	tracker.start(11)
+	tracker.complete(11, RoundEmpty, common.Hash{0x4}, []common.Address{{0x3}}, addr)
+
+	tracker.start(12)
+	tracker.vote(12, 1, common.Hash{0x5}, errors.New("already signed"))
+	tracker.vote(12, 2, common.Hash{0x5}, nil)
+	tracker.complete(12, RoundTentative, common.Hash{0x6}, []common.Address{{0x3}}, addr)
+
+	history := tracker.history(0)
+	require.Len(history, 2)
+	require.Equal(uint64(12), history[0].Round)
+	require.False(history[0].Proposer)
+	require.False(history[0].InCert)
+	require.Len(history[0].Votes, 2)
+	require.Equal("already signed", history[0].Votes[0].Error)
+
+	require.Equal(uint64(10), history[1].Round)
+	require.True(history[1].Proposer)
+	require.True(history[1].ProposalAccepted)
+	require.True(history[1].InCert)
+	require.Equal(RoundFinal, history[1].Result)
+	require.False(history[1].Votes[0].Time.Before(history[1].Start))
+
+	require.Len(tracker.history(1), 1)
+
+	// old rounds are overwritten
+	tracker.vote(13, 1, common.Hash{}, nil)
+	history = tracker.history(0)
+	require.Len(history, 2)
+	require.Equal(uint64(13), history[0].Round)
+}
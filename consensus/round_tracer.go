@@ -0,0 +1,165 @@
+package consensus
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"sync"
+	"time"
+)
+
+const (
+	MaxStoredRoundTraces = 200
+)
+
+type RoundResult string
+
+const (
+	RoundInProgress RoundResult = "inProgress"
+	RoundFinal      RoundResult = "final"
+	RoundTentative  RoundResult = "tentative"
+	RoundEmpty      RoundResult = "empty"
+	RoundFailed     RoundResult = "failed"
+)
+
+type ProposalTrace struct {
+	Hash   common.Hash
+	PubKey []byte
+}
+
+type StepTrace struct {
+	Step      uint8
+	Start     time.Time
+	Duration  time.Duration
+	OwnVote   *common.Hash
+	Threshold int
+	// Votes is the number of valid votes by voted hash
+	Votes   map[common.Hash]int
+	Result  common.Hash
+	Timeout bool
+}
+
+type RoundTrace struct {
+	Round       uint64
+	Head        common.Hash
+	Start       time.Time
+	Duration    time.Duration
+	OwnProposal *common.Hash
+	Proposer    []byte
+	Proofs      []ProposalTrace
+	Blocks      []common.Hash
+	// ReceivedBlock is the block of the highest priority proposer which has been received in time
+	ReceivedBlock *common.Hash
+	Steps         []*StepTrace
+	Result        RoundResult
+	Block         common.Hash
+	Cert          []common.Address
+	Error         string
+}
+
+func (trace *RoundTrace) step(step uint8) *StepTrace {
+	for _, s := range trace.Steps {
+		if s.Step == step {
+			return s
+		}
+	}
+	s := &StepTrace{
+		Step: step,
+	}
+	trace.Steps = append(trace.Steps, s)
+	return s
+}
+
+func (trace *RoundTrace) copy() *RoundTrace {
+	result := *trace
+	result.Proofs = append([]ProposalTrace{}, trace.Proofs...)
+	result.Blocks = append([]common.Hash{}, trace.Blocks...)
+	result.Cert = append([]common.Address{}, trace.Cert...)
+	result.Steps = make([]*StepTrace, 0, len(trace.Steps))
+	for _, s := range trace.Steps {
+		step := *s
+		step.Votes = make(map[common.Hash]int, len(s.Votes))
+		for hash, cnt := range s.Votes {
+			step.Votes[hash] = cnt
+		}
+		result.Steps = append(result.Steps, &step)
+	}
+	return &result
+}
+
+// roundTracer keeps traces of recent consensus rounds in a ring buffer
+type roundTracer struct {
+	mutex  sync.RWMutex
+	traces []*RoundTrace
+}
+
+func newRoundTracer(size int) *roundTracer {
+	return &roundTracer{
+		traces: make([]*RoundTrace, size),
+	}
+}
+
+func (t *roundTracer) start(round uint64, head common.Hash) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.traces[round%uint64(len(t.traces))] = &RoundTrace{
+		Round:  round,
+		Head:   head,
+		Start:  time.Now().UTC(),
+		Result: RoundInProgress,
+	}
+}
+
+func (t *roundTracer) update(round uint64, f func(trace *RoundTrace)) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	trace := t.traces[round%uint64(len(t.traces))]
+	if trace == nil || trace.Round != round {
+		return
+	}
+	f(trace)
+}
+
+func (t *roundTracer) vote(round uint64, step uint8, hash common.Hash) {
+	t.update(round, func(trace *RoundTrace) {
+		trace.step(step).OwnVote = &hash
+	})
+}
+
+func (t *roundTracer) startStep(round uint64, step uint8, threshold int) {
+	t.update(round, func(trace *RoundTrace) {
+		s := trace.step(step)
+		s.Start = time.Now().UTC()
+		s.Threshold = threshold
+	})
+}
+
+func (t *roundTracer) completeStep(round uint64, step uint8, votes map[common.Hash]int, result common.Hash, timeout bool) {
+	t.update(round, func(trace *RoundTrace) {
+		s := trace.step(step)
+		s.Duration = time.Now().UTC().Sub(s.Start)
+		s.Votes = votes
+		s.Result = result
+		s.Timeout = timeout
+	})
+}
+
+func (t *roundTracer) complete(round uint64, result RoundResult, block common.Hash, cert []common.Address, err error) {
+	t.update(round, func(trace *RoundTrace) {
+		trace.Duration = time.Now().UTC().Sub(trace.Start)
+		trace.Result = result
+		trace.Block = block
+		trace.Cert = cert
+		if err != nil {
+			trace.Error = err.Error()
+		}
+	})
+}
+
+func (t *roundTracer) get(round uint64) *RoundTrace {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	trace := t.traces[round%uint64(len(t.traces))]
+	if trace == nil || trace.Round != round {
+		return nil
+	}
+	return trace.copy()
+}
@@ -0,0 +1,47 @@
+package consensus
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRoundTracer(t *testing.T) {
+	require := require.New(t)
+	tracer := newRoundTracer(3)
+
+	tracer.start(10, common.Hash{0x1})
+	tracer.vote(10, 1, common.Hash{0x2})
+	tracer.startStep(10, 1, 5)
+	tracer.completeStep(10, 1, map[common.Hash]int{{0x2}: 6}, common.Hash{0x2}, false)
+	tracer.startStep(10, 2, 5)
+	tracer.completeStep(10, 2, map[common.Hash]int{}, common.Hash{}, true)
+	tracer.complete(10, RoundFinal, common.Hash{0x2}, []common.Address{{0x3}}, nil)
+
+	trace := tracer.get(10)
+	require.Equal(RoundFinal, trace.Result)
+	require.Len(trace.Steps, 2)
+	require.Equal(common.Hash{0x2}, *trace.Steps[0].OwnVote)
+	require.Equal(6, trace.Steps[0].Votes[common.Hash{0x2}])
+	require.True(trace.Steps[1].Timeout)
+	require.Equal([]common.Address{{0x3}}, trace.Cert)
+
+	// returned trace is a copy
+	trace.Steps[0].Votes[common.Hash{0x2}] = 1
+	require.Equal(6, tracer.get(10).Steps[0].Votes[common.Hash{0x2}])
+
+	tracer.start(11, common.Hash{})
+	tracer.start(12, common.Hash{})
+	tracer.complete(12, RoundFailed, common.Hash{}, nil, errors.New("no consensus"))
+	require.Equal("no consensus", tracer.get(12).Error)
+	require.NotNil(tracer.get(10))
+
+	tracer.start(13, common.Hash{})
+	require.Nil(tracer.get(10))
+	require.NotNil(tracer.get(13))
+
+	// updates of overwritten rounds are ignored
+	tracer.complete(10, RoundEmpty, common.Hash{}, nil, nil)
+	require.Equal(RoundInProgress, tracer.get(13).Result)
+}
@@ -0,0 +1,406 @@
+package sim
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"time"
+)
+
+type commit struct {
+	hash common.Hash
+	time time.Duration
+}
+
+type block struct {
+	hash   common.Hash
+	parent common.Hash
+	time   time.Duration
+}
+
+type wait struct {
+	round uint64
+	step  uint8
+	done  func(hash common.Hash, ok bool)
+}
+
+// node runs rounds as callbacks of the virtual clock, epoch is changed on every round start and stop to drop timers
+// of the previous activity
+type node struct {
+	id    int
+	sim   *Simulator
+	chain []commit
+	down  bool
+	epoch int
+
+	proofs    map[uint64][]*message
+	blocks    map[common.Hash]*block
+	votes     map[uint64]map[uint8][]*message
+	wait      *wait
+	blockWait *common.Hash
+	maxSeen   uint64
+
+	round      uint64
+	roundStart time.Duration
+	steps      int
+}
+
+func newNode(id int, sim *Simulator) *node {
+	return &node{
+		id:     id,
+		sim:    sim,
+		proofs: make(map[uint64][]*message),
+		blocks: make(map[common.Hash]*block),
+		votes:  make(map[uint64]map[uint8][]*message),
+	}
+}
+
+func (n *node) height() uint64 {
+	return uint64(len(n.chain))
+}
+
+func (n *node) hashAt(height uint64) common.Hash {
+	if height == 0 {
+		return n.sim.seed
+	}
+	return n.chain[height-1].hash
+}
+
+func (n *node) head() commit {
+	if len(n.chain) == 0 {
+		return commit{hash: n.sim.seed}
+	}
+	return n.chain[len(n.chain)-1]
+}
+
+func (n *node) emptyHash() common.Hash {
+	return emptyBlockHash(n.head().hash, n.height()+1)
+}
+
+func (n *node) after(d time.Duration, do func()) {
+	epoch := n.epoch
+	n.sim.after(d, func() {
+		if n.down || n.epoch != epoch {
+			return
+		}
+		do()
+	})
+}
+
+func (n *node) stop() {
+	n.down = true
+	n.epoch++
+	n.wait = nil
+	n.blockWait = nil
+}
+
+func (n *node) restart() {
+	n.down = false
+	n.startRound()
+}
+
+func (n *node) startRound() {
+	if n.down {
+		return
+	}
+	n.epoch++
+	n.wait = nil
+	n.blockWait = nil
+	n.catchUp()
+	round := n.height() + 1
+	if round > uint64(n.sim.scenario.Rounds) {
+		return
+	}
+	if round != n.round {
+		n.round = round
+		n.roundStart = n.sim.now
+		delete(n.proofs, round-2)
+		delete(n.votes, round-2)
+	}
+	n.steps = 0
+
+	// the engine aligns round starts to the min block distance from the head timestamp
+	delay := n.sim.cfg.MinBlockDistance - (n.sim.now - n.head().time)
+	if max := n.sim.cfg.ProposalDelay(); delay > max {
+		delay = max
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	n.after(delay, n.propose)
+}
+
+// catchUp adopts blocks of reachable peers which are ahead like the downloader does, the node switches to the longer
+// chain of a peer if its own chain diverged
+func (n *node) catchUp() {
+	n.switchFork()
+	for {
+		var source *node
+		for _, peer := range n.sim.nodes {
+			if peer.id != n.id && !peer.down && peer.height() > n.height() && n.sim.reachable(n.id, peer.id, n.sim.now) &&
+				peer.hashAt(n.height()) == n.head().hash {
+				source = peer
+				break
+			}
+		}
+		if source == nil {
+			return
+		}
+		round := n.height() + 1
+		c := source.chain[n.height()]
+		n.chain = append(n.chain, c)
+		var duration time.Duration
+		if n.round == round {
+			duration = n.sim.now - n.roundStart
+		}
+		n.sim.record(round, NodeRound{Node: n.id, Outcome: OutcomeSynced, Hash: c.hash, Duration: Duration(duration)})
+	}
+}
+
+func (n *node) switchFork() {
+	var longest *node
+	for _, peer := range n.sim.nodes {
+		if peer.id == n.id || peer.down || !n.sim.reachable(n.id, peer.id, n.sim.now) {
+			continue
+		}
+		if peer.height() > n.height() && peer.hashAt(n.height()) != n.head().hash &&
+			(longest == nil || peer.height() > longest.height()) {
+			longest = peer
+		}
+	}
+	if longest == nil {
+		return
+	}
+	ancestor := n.height()
+	for ancestor > 0 && longest.hashAt(ancestor) != n.hashAt(ancestor) {
+		ancestor--
+	}
+	n.chain = n.chain[:ancestor]
+	n.sim.reorgs++
+}
+
+func (n *node) propose() {
+	round, parent := n.height()+1, n.head().hash
+	if score, ok := n.sim.proposerScore(parent, round, n.id); ok {
+		hash := blockHash(parent, round, n.id)
+		n.blocks[hash] = &block{hash: hash, parent: parent, time: n.sim.now}
+		proof := &message{kind: msgProof, round: round, from: n.id, parent: parent, hash: hash, score: score}
+		n.proofs[round] = append(n.proofs[round], proof)
+		n.sim.broadcast(n.id, proof)
+		n.sim.broadcast(n.id, &message{kind: msgBlock, round: round, from: n.id, parent: parent, hash: hash, time: n.sim.now})
+	}
+	n.after(n.sim.cfg.EstimatedBaVariance+n.sim.cfg.WaitSortitionProofDelay, n.selectProposal)
+}
+
+func (n *node) selectProposal() {
+	round, parent := n.height()+1, n.head().hash
+	var best *message
+	for _, proof := range n.proofs[round] {
+		if proof.parent == parent && (best == nil || scoreLess(best.score, proof.score)) {
+			best = proof
+		}
+	}
+	if best == nil {
+		n.reduction(n.emptyHash())
+		return
+	}
+	if _, ok := n.blocks[best.hash]; ok {
+		n.reduction(best.hash)
+		return
+	}
+	hash := best.hash
+	n.blockWait = &hash
+	n.after(n.sim.cfg.WaitBlockDelay, func() {
+		if n.blockWait != nil {
+			n.blockWait = nil
+			n.reduction(n.emptyHash())
+		}
+	})
+}
+
+func (n *node) receive(msg *message) {
+	if msg.round > n.maxSeen {
+		n.maxSeen = msg.round
+	}
+	switch msg.kind {
+	case msgProof:
+		n.proofs[msg.round] = append(n.proofs[msg.round], msg)
+	case msgBlock:
+		n.blocks[msg.hash] = &block{hash: msg.hash, parent: msg.parent, time: msg.time}
+		if n.blockWait != nil && *n.blockWait == msg.hash {
+			n.blockWait = nil
+			n.reduction(msg.hash)
+		}
+	case msgVote:
+		n.addVote(msg)
+	}
+}
+
+func (n *node) vote(step uint8, hash common.Hash) {
+	round, parent := n.height()+1, n.head().hash
+	if !n.sim.committee(parent, round, step)[n.id] {
+		return
+	}
+	vote := &message{kind: msgVote, round: round, step: step, from: n.id, parent: parent, hash: hash}
+	n.sim.broadcast(n.id, vote)
+	n.addVote(vote)
+}
+
+func (n *node) addVote(vote *message) {
+	steps, ok := n.votes[vote.round]
+	if !ok {
+		steps = make(map[uint8][]*message)
+		n.votes[vote.round] = steps
+	}
+	for _, v := range steps[vote.step] {
+		if v.from == vote.from {
+			return
+		}
+	}
+	steps[vote.step] = append(steps[vote.step], vote)
+	if n.wait != nil && n.wait.round == vote.round && n.wait.step == vote.step {
+		n.countVotes()
+	}
+}
+
+// count waits for the step agreement, done is called with false if the threshold isn't reached in time
+func (n *node) count(step uint8, done func(hash common.Hash, ok bool)) {
+	w := &wait{round: n.height() + 1, step: step, done: done}
+	n.wait = w
+	n.after(n.sim.cfg.WaitForStepDelay, func() {
+		if n.wait == w {
+			n.wait = nil
+			done(common.Hash{}, false)
+		}
+	})
+	n.countVotes()
+}
+
+func (n *node) countVotes() {
+	w := n.wait
+	parent := n.head().hash
+	members := n.sim.committee(parent, w.round, w.step)
+	threshold := n.sim.threshold(w.step)
+	counts := make(map[common.Hash]int)
+	for _, v := range n.votes[w.round][w.step] {
+		if v.parent != parent || !members[v.from] {
+			continue
+		}
+		counts[v.hash]++
+		if counts[v.hash] >= threshold {
+			n.wait = nil
+			w.done(v.hash, true)
+			return
+		}
+	}
+}
+
+func (n *node) reduction(hash common.Hash) {
+	empty := n.emptyHash()
+	n.vote(types.ReductionOne, hash)
+	n.count(types.ReductionOne, func(hash common.Hash, ok bool) {
+		if !ok {
+			hash = empty
+		}
+		n.vote(types.ReductionTwo, hash)
+		n.count(types.ReductionTwo, func(hash common.Hash, ok bool) {
+			if !ok {
+				hash = empty
+			}
+			n.binaryBa(hash, 1, hash)
+		})
+	})
+}
+
+func (n *node) binaryBa(blockHash common.Hash, step uint8, hash common.Hash) {
+	round := n.height() + 1
+	empty := n.emptyHash()
+	if step >= n.sim.cfg.MaxSteps {
+		n.fail("no consensus")
+		return
+	}
+	n.steps = int(step)
+	n.vote(step, hash)
+	n.count(step, func(hash common.Hash, ok bool) {
+		if !ok {
+			hash = blockHash
+		} else if hash != empty {
+			for i := uint8(1); i <= 2; i++ {
+				n.vote(step+i, hash)
+			}
+			if step == 1 {
+				n.vote(types.Final, hash)
+			}
+			n.finalStep(hash)
+			return
+		}
+		next := step + 1
+		n.steps = int(next)
+		n.vote(next, hash)
+		n.count(next, func(hash common.Hash, ok bool) {
+			if !ok {
+				hash = empty
+			} else if hash == empty {
+				for i := uint8(1); i <= 2; i++ {
+					n.vote(next+i, hash)
+				}
+				n.commit(hash, OutcomeEmpty)
+				return
+			}
+			if n.maxSeen > round {
+				n.fail("detected future block")
+				return
+			}
+			n.binaryBa(blockHash, next+1, hash)
+		})
+	})
+}
+
+func (n *node) finalStep(hash common.Hash) {
+	n.count(types.Final, func(final common.Hash, ok bool) {
+		outcome := OutcomeTentative
+		if ok && final == hash {
+			outcome = OutcomeFinal
+		}
+		n.commit(hash, outcome)
+	})
+}
+
+func (n *node) commit(hash common.Hash, outcome string) {
+	round := n.height() + 1
+	// the empty block timestamp follows the head one
+	t := n.head().time + time.Second
+	if outcome != OutcomeEmpty {
+		b := n.findBlock(hash)
+		if b == nil {
+			n.fail("block is not found")
+			return
+		}
+		t = b.time
+	}
+	n.chain = append(n.chain, commit{hash: hash, time: t})
+	n.sim.record(round, NodeRound{Node: n.id, Outcome: outcome, Hash: hash, Steps: n.steps, Duration: Duration(n.sim.now - n.roundStart)})
+	n.startRound()
+}
+
+// findBlock returns the received block or requests it from reachable peers
+func (n *node) findBlock(hash common.Hash) *block {
+	if b, ok := n.blocks[hash]; ok {
+		return b
+	}
+	for _, peer := range n.sim.nodes {
+		if peer.id == n.id || peer.down || !n.sim.reachable(n.id, peer.id, n.sim.now) {
+			continue
+		}
+		if b, ok := peer.blocks[hash]; ok {
+			n.blocks[hash] = b
+			return b
+		}
+	}
+	return nil
+}
+
+func (n *node) fail(reason string) {
+	round := n.height() + 1
+	n.sim.record(round, NodeRound{Node: n.id, Outcome: OutcomeFailed, Steps: n.steps, Duration: Duration(n.sim.now - n.roundStart), Reason: reason})
+	n.startRound()
+}
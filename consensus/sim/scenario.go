@@ -0,0 +1,172 @@
+package sim
+
+import (
+	"encoding/json"
+	"github.com/idena-network/idena-go/config"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"time"
+)
+
+const (
+	defaultRounds    = 10
+	defaultLatency   = 100 * time.Millisecond
+	defaultProposers = 3
+)
+
+// Duration is time.Duration encoded as a string, e.g. "1m30s"
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return errors.Errorf("duration should be a string, e.g. \"500ms\": %s", data)
+	}
+	value, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(value)
+	return nil
+}
+
+// Partition splits the network while it is active, nodes can reach only nodes of the same group, nodes which aren't
+// listed are isolated. Zero To keeps the partition until the end of the run.
+type Partition struct {
+	From   Duration `json:"from"`
+	To     Duration `json:"to"`
+	Groups [][]int  `json:"groups"`
+}
+
+// Outage stops the node, it stays in the committees since its identity remains online. Zero To keeps the node stopped
+// until the end of the run.
+type Outage struct {
+	Node int      `json:"node"`
+	From Duration `json:"from"`
+	To   Duration `json:"to"`
+}
+
+// Timings override timeouts of the default consensus config, they have the same meaning as config.ConsensusTimings
+type Timings struct {
+	MinBlockDistance    Duration `json:"minBlockDistance"`
+	ProposalTimeout     Duration `json:"proposalTimeout"`
+	SortitionProofDelay Duration `json:"sortitionProofDelay"`
+	BaVariance          Duration `json:"baVariance"`
+	VoteTimeout         Duration `json:"voteTimeout"`
+	MaxSteps            uint8    `json:"maxSteps"`
+}
+
+// Expectation is checked against the result by the simulate command, nil MaxFailed doesn't limit failed rounds
+type Expectation struct {
+	// MinHeight is the min height of every node which isn't stopped at the end of the run
+	MinHeight  uint64 `json:"minHeight"`
+	MinFinal   int    `json:"minFinal"`
+	MaxFailed  *int   `json:"maxFailed,omitempty"`
+	AllowForks bool   `json:"allowForks"`
+}
+
+type Scenario struct {
+	Name   string `json:"name"`
+	Seed   int64  `json:"seed"`
+	Nodes  int    `json:"nodes"`
+	Rounds int    `json:"rounds"`
+	// MaxTime limits the virtual time of the run, it is 10 min per round by default
+	MaxTime Duration `json:"maxTime"`
+	Latency Duration `json:"latency"`
+	Jitter  Duration `json:"jitter"`
+	// Drop is the probability of a message loss
+	Drop float64 `json:"drop"`
+	// Proposers is the expected number of proposers per round
+	Proposers  float64      `json:"proposers"`
+	Partitions []Partition  `json:"partitions"`
+	Outages    []Outage     `json:"outages"`
+	Timings    *Timings     `json:"timings,omitempty"`
+	Expect     *Expectation `json:"expect,omitempty"`
+}
+
+func LoadScenario(file string) (*Scenario, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	scenario := &Scenario{}
+	if err := json.Unmarshal(data, scenario); err != nil {
+		return nil, errors.Wrap(err, "invalid scenario")
+	}
+	return scenario, scenario.Validate()
+}
+
+func (s *Scenario) Validate() error {
+	if s.Nodes <= 0 {
+		return errors.New("scenario should have at least one node")
+	}
+	if s.Rounds < 0 || s.Drop < 0 || s.Drop > 1 || s.Proposers < 0 || s.Latency < 0 || s.Jitter < 0 {
+		return errors.New("rounds, drop, proposers, latency and jitter should be non-negative, drop can't exceed 1")
+	}
+	validNode := func(node int) bool {
+		return node >= 0 && node < s.Nodes
+	}
+	for i, p := range s.Partitions {
+		if p.To != 0 && p.To <= p.From {
+			return errors.Errorf("partition %v ends before it starts", i)
+		}
+		for _, group := range p.Groups {
+			for _, node := range group {
+				if !validNode(node) {
+					return errors.Errorf("partition %v has unknown node %v", i, node)
+				}
+			}
+		}
+	}
+	for i, o := range s.Outages {
+		if !validNode(o.Node) {
+			return errors.Errorf("outage %v has unknown node %v", i, o.Node)
+		}
+		if o.To != 0 && o.To <= o.From {
+			return errors.Errorf("outage %v ends before it starts", i)
+		}
+	}
+	return nil
+}
+
+func (s *Scenario) withDefaults() *Scenario {
+	result := *s
+	if result.Rounds == 0 {
+		result.Rounds = defaultRounds
+	}
+	if result.Latency == 0 {
+		result.Latency = Duration(defaultLatency)
+	}
+	if result.Proposers == 0 {
+		result.Proposers = defaultProposers
+	}
+	if result.MaxTime == 0 {
+		result.MaxTime = Duration(time.Duration(result.Rounds) * 10 * time.Minute)
+	}
+	return &result
+}
+
+func (s *Scenario) consensusConfig() *config.ConsensusConf {
+	cfg := config.GetDefaultConsensusConfig()
+	if s.Timings == nil {
+		return cfg
+	}
+	set := func(target *time.Duration, value Duration) {
+		if value > 0 {
+			*target = time.Duration(value)
+		}
+	}
+	set(&cfg.MinBlockDistance, s.Timings.MinBlockDistance)
+	set(&cfg.WaitBlockDelay, s.Timings.ProposalTimeout)
+	set(&cfg.WaitSortitionProofDelay, s.Timings.SortitionProofDelay)
+	set(&cfg.EstimatedBaVariance, s.Timings.BaVariance)
+	set(&cfg.WaitForStepDelay, s.Timings.VoteTimeout)
+	if s.Timings.MaxSteps > 0 {
+		cfg.MaxSteps = s.Timings.MaxSteps
+	}
+	return cfg
+}
@@ -0,0 +1,368 @@
+// Package sim runs the consensus rounds of N in-process nodes on a virtual clock over a simulated network with
+// latency, jitter, message drops, partitions and node outages, so edge cases are reproduced deterministically from
+// the scenario seed. Nodes follow the round of the engine: proposer proofs and blocks, two reduction steps, binary BA
+// steps and the final step with the engine timeouts, committee sizes and vote thresholds. Blocks aren't executed and
+// the validation ceremony isn't run, outages reproduce nodes dropping out around it.
+package sim
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/pkg/errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Outcomes of node rounds, they match results of the engine round tracer, synced rounds are adopted from peers
+const (
+	OutcomeFinal     = "final"
+	OutcomeTentative = "tentative"
+	OutcomeEmpty     = "empty"
+	OutcomeFailed    = "failed"
+	OutcomeSynced    = "synced"
+)
+
+const (
+	msgProof = iota
+	msgBlock
+	msgVote
+)
+
+type message struct {
+	kind   int
+	round  uint64
+	step   uint8
+	from   int
+	parent common.Hash
+	hash   common.Hash
+	score  common.Hash
+	time   time.Duration
+}
+
+type event struct {
+	at  time.Duration
+	seq uint64
+	do  func()
+}
+
+type eventQueue []*event
+
+func (q eventQueue) Len() int { return len(q) }
+func (q eventQueue) Less(i, j int) bool {
+	if q[i].at != q[j].at {
+		return q[i].at < q[j].at
+	}
+	return q[i].seq < q[j].seq
+}
+func (q eventQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *eventQueue) Push(x interface{}) { *q = append(*q, x.(*event)) }
+func (q *eventQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+type NodeRound struct {
+	Node     int         `json:"node"`
+	Outcome  string      `json:"outcome"`
+	Hash     common.Hash `json:"hash,omitempty"`
+	Steps    int         `json:"steps"`
+	Duration Duration    `json:"duration"`
+	Reason   string      `json:"reason,omitempty"`
+}
+
+type RoundResult struct {
+	Round    uint64         `json:"round"`
+	Outcomes map[string]int `json:"outcomes"`
+	// Forked is set if nodes committed different blocks
+	Forked   bool        `json:"forked"`
+	MaxSteps int         `json:"maxSteps"`
+	Nodes    []NodeRound `json:"nodes"`
+
+	hashes map[common.Hash]struct{}
+}
+
+type Result struct {
+	Scenario string   `json:"scenario"`
+	Seed     int64    `json:"seed"`
+	Heights  []uint64 `json:"heights"`
+	// Stopped are nodes which are down at the end of the run
+	Stopped []int          `json:"stopped"`
+	Rounds  []*RoundResult `json:"rounds"`
+	Forks   int            `json:"forks"`
+	// Reorgs is the number of switches of nodes to the longer chain of a peer
+	Reorgs   int      `json:"reorgs"`
+	Messages int      `json:"messages"`
+	Dropped  int      `json:"dropped"`
+	Time     Duration `json:"time"`
+	// Timeout is set if the run was stopped by the max time
+	Timeout bool `json:"timeout"`
+}
+
+// Check returns the first unmet expectation
+func (r *Result) Check(expect *Expectation) error {
+	if expect == nil {
+		return nil
+	}
+	isStopped := make(map[int]bool, len(r.Stopped))
+	for _, node := range r.Stopped {
+		isStopped[node] = true
+	}
+	for node, height := range r.Heights {
+		if !isStopped[node] && height < expect.MinHeight {
+			return errors.Errorf("node %v height %v is less than %v", node, height, expect.MinHeight)
+		}
+	}
+	final, failed := 0, 0
+	for _, round := range r.Rounds {
+		if round.Outcomes[OutcomeFinal] > 0 {
+			final++
+		}
+		failed += round.Outcomes[OutcomeFailed]
+	}
+	if final < expect.MinFinal {
+		return errors.Errorf("%v final rounds are less than %v", final, expect.MinFinal)
+	}
+	if expect.MaxFailed != nil && failed > *expect.MaxFailed {
+		return errors.Errorf("%v failed rounds exceed %v", failed, *expect.MaxFailed)
+	}
+	if r.Forks > 0 && !expect.AllowForks {
+		return errors.Errorf("%v forks detected", r.Forks)
+	}
+	return nil
+}
+
+type Simulator struct {
+	scenario *Scenario
+	cfg      *config.ConsensusConf
+	rng      *rand.Rand
+	now      time.Duration
+	queue    eventQueue
+	seq      uint64
+	nodes    []*node
+	seed     common.Hash
+
+	committees map[common.Hash]map[int]bool
+	rounds     map[uint64]*RoundResult
+	messages   int
+	dropped    int
+	reorgs     int
+}
+
+func NewSimulator(scenario *Scenario) (*Simulator, error) {
+	if err := scenario.Validate(); err != nil {
+		return nil, err
+	}
+	scenario = scenario.withDefaults()
+	seed := make([]byte, 8)
+	binary.LittleEndian.PutUint64(seed, uint64(scenario.Seed))
+	sim := &Simulator{
+		scenario:   scenario,
+		cfg:        scenario.consensusConfig(),
+		rng:        rand.New(rand.NewSource(scenario.Seed)),
+		seed:       crypto.Keccak256Hash(seed),
+		committees: make(map[common.Hash]map[int]bool),
+		rounds:     make(map[uint64]*RoundResult),
+	}
+	for i := 0; i < scenario.Nodes; i++ {
+		sim.nodes = append(sim.nodes, newNode(i, sim))
+	}
+	return sim, nil
+}
+
+// Run runs the scenario until every running node reaches the last round or the max time is exceeded
+func Run(scenario *Scenario) (*Result, error) {
+	sim, err := NewSimulator(scenario)
+	if err != nil {
+		return nil, err
+	}
+	return sim.Run(), nil
+}
+
+func (sim *Simulator) Run() *Result {
+	for _, n := range sim.nodes {
+		sim.at(0, n.startRound)
+	}
+	for _, o := range sim.scenario.Outages {
+		n := sim.nodes[o.Node]
+		sim.at(time.Duration(o.From), n.stop)
+		if o.To > 0 {
+			sim.at(time.Duration(o.To), n.restart)
+		}
+	}
+	timeout := false
+	for sim.queue.Len() > 0 {
+		ev := heap.Pop(&sim.queue).(*event)
+		if ev.at > time.Duration(sim.scenario.MaxTime) {
+			timeout = true
+			break
+		}
+		sim.now = ev.at
+		ev.do()
+	}
+	return sim.result(timeout)
+}
+
+func (sim *Simulator) at(at time.Duration, do func()) {
+	sim.seq++
+	heap.Push(&sim.queue, &event{at: at, seq: sim.seq, do: do})
+}
+
+func (sim *Simulator) after(d time.Duration, do func()) {
+	sim.at(sim.now+d, do)
+}
+
+func (sim *Simulator) reachable(from, to int, at time.Duration) bool {
+	for _, p := range sim.scenario.Partitions {
+		if at < time.Duration(p.From) || p.To > 0 && at >= time.Duration(p.To) {
+			continue
+		}
+		if groupOf(p.Groups, from) < 0 || groupOf(p.Groups, from) != groupOf(p.Groups, to) {
+			return false
+		}
+	}
+	return true
+}
+
+func groupOf(groups [][]int, node int) int {
+	for i, group := range groups {
+		for _, n := range group {
+			if n == node {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func (sim *Simulator) broadcast(from int, msg *message) {
+	for _, n := range sim.nodes {
+		if n.id == from {
+			continue
+		}
+		sim.messages++
+		if sim.scenario.Drop > 0 && sim.rng.Float64() < sim.scenario.Drop || !sim.reachable(from, n.id, sim.now) {
+			sim.dropped++
+			continue
+		}
+		delay := time.Duration(sim.scenario.Latency)
+		if sim.scenario.Jitter > 0 {
+			delay += time.Duration(sim.rng.Int63n(int64(sim.scenario.Jitter)))
+		}
+		receiver := n
+		sim.after(delay, func() {
+			if receiver.down {
+				sim.dropped++
+				return
+			}
+			receiver.receive(msg)
+		})
+	}
+}
+
+// committee returns members of the step committee, like the validators cache it sorts online identities by the hash
+// of the head, round, step and identity
+func (sim *Simulator) committee(parent common.Hash, round uint64, step uint8) map[int]bool {
+	key := crypto.Keccak256Hash(parent.Bytes(), common.ToBytes(round), []byte{step})
+	if members, ok := sim.committees[key]; ok {
+		return members
+	}
+	size := blockchain.CommitteeSize(sim.cfg, len(sim.nodes), step == types.Final)
+	members := make(map[int]bool, size)
+	scores := make([]common.Hash, len(sim.nodes))
+	for i := range sim.nodes {
+		scores[i] = crypto.Keccak256Hash(key.Bytes(), common.ToBytes(uint64(i)))
+	}
+	for len(members) < size {
+		best := -1
+		for i := range sim.nodes {
+			if !members[i] && (best < 0 || scoreLess(scores[i], scores[best])) {
+				best = i
+			}
+		}
+		members[best] = true
+	}
+	sim.committees[key] = members
+	return members
+}
+
+func scoreLess(a, b common.Hash) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+func (sim *Simulator) threshold(step uint8) int {
+	return blockchain.CommitteeVotesThreshold(sim.cfg, len(sim.nodes), step == types.Final)
+}
+
+// proposerScore returns the sortition output of the node and whether it is a proposer of the round
+func (sim *Simulator) proposerScore(parent common.Hash, round uint64, id int) (common.Hash, bool) {
+	score := crypto.Keccak256Hash(sim.seed.Bytes(), parent.Bytes(), common.ToBytes(round), common.ToBytes(uint64(id)))
+	value := float64(binary.BigEndian.Uint64(score[:8])) / math.MaxUint64
+	return score, value < sim.scenario.Proposers/float64(len(sim.nodes))
+}
+
+func blockHash(parent common.Hash, round uint64, proposer int) common.Hash {
+	return crypto.Keccak256Hash(parent.Bytes(), common.ToBytes(round), common.ToBytes(uint64(proposer)))
+}
+
+func emptyBlockHash(parent common.Hash, round uint64) common.Hash {
+	return crypto.Keccak256Hash([]byte("empty"), parent.Bytes(), common.ToBytes(round))
+}
+
+func (sim *Simulator) record(round uint64, result NodeRound) {
+	r, ok := sim.rounds[round]
+	if !ok {
+		r = &RoundResult{Round: round, Outcomes: make(map[string]int), hashes: make(map[common.Hash]struct{})}
+		sim.rounds[round] = r
+	}
+	r.Outcomes[result.Outcome]++
+	r.Nodes = append(r.Nodes, result)
+	if result.Steps > r.MaxSteps {
+		r.MaxSteps = result.Steps
+	}
+	if result.Outcome != OutcomeFailed {
+		r.hashes[result.Hash] = struct{}{}
+		r.Forked = len(r.hashes) > 1
+	}
+}
+
+func (sim *Simulator) result(timeout bool) *Result {
+	result := &Result{
+		Scenario: sim.scenario.Name,
+		Seed:     sim.scenario.Seed,
+		Messages: sim.messages,
+		Dropped:  sim.dropped,
+		Reorgs:   sim.reorgs,
+		Time:     Duration(sim.now),
+		Timeout:  timeout,
+	}
+	for _, n := range sim.nodes {
+		result.Heights = append(result.Heights, n.height())
+		if n.down {
+			result.Stopped = append(result.Stopped, n.id)
+		}
+	}
+	for round := uint64(1); round <= uint64(len(sim.rounds)); round++ {
+		r, ok := sim.rounds[round]
+		if !ok {
+			break
+		}
+		if r.Forked {
+			result.Forks++
+		}
+		result.Rounds = append(result.Rounds, r)
+	}
+	return result
+}
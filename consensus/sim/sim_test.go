@@ -0,0 +1,65 @@
+package sim
+
+import (
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScenarios(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, file := range files {
+		scenario, err := LoadScenario(file)
+		require.NoError(t, err, file)
+		result, err := Run(scenario)
+		require.NoError(t, err)
+		require.NoError(t, result.Check(scenario.Expect), scenario.Name)
+	}
+}
+
+func TestRun_Deterministic(t *testing.T) {
+	scenario := &Scenario{
+		Seed:    42,
+		Nodes:   30,
+		Rounds:  4,
+		Latency: Duration(300 * time.Millisecond),
+		Jitter:  Duration(900 * time.Millisecond),
+		Drop:    0.2,
+	}
+	first, err := Run(scenario)
+	require.NoError(t, err)
+	second, err := Run(scenario)
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+
+	scenario.Seed = 43
+	other, err := Run(scenario)
+	require.NoError(t, err)
+	require.NotEqual(t, first.Messages-first.Dropped, other.Messages-other.Dropped)
+}
+
+func TestRun_NoConnectivity(t *testing.T) {
+	scenario := &Scenario{
+		Nodes:   4,
+		Rounds:  1,
+		Drop:    1,
+		MaxTime: Duration(time.Hour),
+		Timings: &Timings{MaxSteps: 10},
+	}
+	result, err := Run(scenario)
+	require.NoError(t, err)
+	require.True(t, result.Timeout)
+	require.Equal(t, make([]uint64, 4), result.Heights)
+	require.Zero(t, result.Rounds[0].Outcomes[OutcomeFinal])
+	require.NotZero(t, result.Rounds[0].Outcomes[OutcomeFailed])
+}
+
+func TestScenario_Validate(t *testing.T) {
+	require.Error(t, (&Scenario{}).Validate())
+	require.Error(t, (&Scenario{Nodes: 3, Outages: []Outage{{Node: 3}}}).Validate())
+	require.Error(t, (&Scenario{Nodes: 3, Partitions: []Partition{{From: 10, To: 5}}}).Validate())
+	require.NoError(t, (&Scenario{Nodes: 3, Outages: []Outage{{Node: 2, From: 5}}}).Validate())
+}
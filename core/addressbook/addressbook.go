@@ -0,0 +1,155 @@
+package addressbook
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/pool"
+	"github.com/idena-network/idena-go/database"
+	"github.com/idena-network/idena-go/secstore"
+	"github.com/pkg/errors"
+	dbm "github.com/tendermint/tm-db"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxLabelLength = 64
+	maxNoteLength  = 256
+)
+
+// Labels of known addresses which are set if the operator hasn't labeled them
+const (
+	OwnLabel        = "own"
+	GodAddressLabel = "godAddress"
+	DelegatorLabel  = "poolDelegator"
+)
+
+type Entry struct {
+	Address   common.Address `json:"address"`
+	Label     string         `json:"label"`
+	Note      string         `json:"note,omitempty"`
+	UpdatedAt uint64         `json:"updatedAt"`
+}
+
+// Book keeps labels of addresses set by the operator and labels known addresses of the node
+type Book struct {
+	repo     *database.Repo
+	appState *appstate.AppState
+	secStore *secstore.SecStore
+	pool     *pool.Manager
+
+	mutex  sync.RWMutex
+	labels map[common.Address]*database.AddressLabel
+}
+
+func NewBook(db dbm.DB, appState *appstate.AppState, secStore *secstore.SecStore, pool *pool.Manager) *Book {
+	b := &Book{
+		repo:     database.NewRepo(db),
+		appState: appState,
+		secStore: secStore,
+		pool:     pool,
+		labels:   make(map[common.Address]*database.AddressLabel),
+	}
+	for _, label := range b.repo.ReadAddressLabels() {
+		b.labels[label.Address] = label
+	}
+	return b
+}
+
+// Set adds or updates the label of the address
+func (b *Book) Set(addr common.Address, label string, note string) (*Entry, error) {
+	label, note = strings.TrimSpace(label), strings.TrimSpace(note)
+	if label == "" {
+		return nil, errors.New("label is empty")
+	}
+	if len(label) > maxLabelLength {
+		return nil, errors.Errorf("label is longer than %v bytes", maxLabelLength)
+	}
+	if len(note) > maxNoteLength {
+		return nil, errors.Errorf("note is longer than %v bytes", maxNoteLength)
+	}
+	item := &database.AddressLabel{
+		Address:   addr,
+		Label:     label,
+		Note:      note,
+		UpdatedAt: uint64(time.Now().Unix()),
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.repo.WriteAddressLabel(item)
+	b.labels[addr] = item
+	return toEntry(item), nil
+}
+
+// Remove deletes the label of the address, false is returned if the address isn't labeled
+func (b *Book) Remove(addr common.Address) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if _, ok := b.labels[addr]; !ok {
+		return false
+	}
+	b.repo.DeleteAddressLabel(addr)
+	delete(b.labels, addr)
+	return true
+}
+
+// Get returns the entry of the labeled address or nil
+func (b *Book) Get(addr common.Address) *Entry {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	if item, ok := b.labels[addr]; ok {
+		return toEntry(item)
+	}
+	return nil
+}
+
+// List returns labeled addresses sorted by label
+func (b *Book) List() []*Entry {
+	b.mutex.RLock()
+	result := make([]*Entry, 0, len(b.labels))
+	for _, item := range b.labels {
+		result = append(result, toEntry(item))
+	}
+	b.mutex.RUnlock()
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Label != result[j].Label {
+			return result[i].Label < result[j].Label
+		}
+		return result[i].Address.Hex() < result[j].Address.Hex()
+	})
+	return result
+}
+
+// Label returns the label set by the operator or the label of the known address, empty string for unknown addresses
+func (b *Book) Label(addr common.Address) string {
+	if b == nil {
+		return ""
+	}
+	b.mutex.RLock()
+	item, ok := b.labels[addr]
+	b.mutex.RUnlock()
+	if ok {
+		return item.Label
+	}
+	if addr == b.secStore.GetAddress() {
+		return OwnLabel
+	}
+	if addr == b.appState.State.GodAddress() {
+		return GodAddressLabel
+	}
+	if b.pool != nil && b.pool.IsDelegator(addr) {
+		return DelegatorLabel
+	}
+	return ""
+}
+
+func toEntry(item *database.AddressLabel) *Entry {
+	return &Entry{
+		Address:   item.Address,
+		Label:     item.Label,
+		Note:      item.Note,
+		UpdatedAt: item.UpdatedAt,
+	}
+}
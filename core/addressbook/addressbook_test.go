@@ -0,0 +1,70 @@
+package addressbook
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/pool"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/secstore"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tm-db"
+	"strings"
+	"testing"
+)
+
+func TestBook(t *testing.T) {
+	require := require.New(t)
+	memdb := db.NewMemDB()
+	bus := eventbus.New()
+	appState := appstate.NewAppState(memdb, bus)
+	appState.Initialize(0)
+	god := common.Address{0x9}
+	appState.State.SetGodAddress(god)
+
+	key, _ := crypto.GenerateKey()
+	secStore := secstore.NewSecStore()
+	secStore.AddKey(crypto.FromECDSA(key))
+	own := secStore.GetAddress()
+
+	poolManager := pool.NewManager(memdb, appState, bus)
+	delegator := common.Address{0x2}
+	poolManager.AddDelegators([]common.Address{delegator})
+
+	book := NewBook(memdb, appState, secStore, poolManager)
+	require.Equal(OwnLabel, book.Label(own))
+	require.Equal(GodAddressLabel, book.Label(god))
+	require.Equal(DelegatorLabel, book.Label(delegator))
+	require.Empty(book.Label(common.Address{0x3}))
+
+	_, err := book.Set(common.Address{0x3}, " ", "")
+	require.Error(err)
+	_, err = book.Set(common.Address{0x3}, strings.Repeat("a", maxLabelLength+1), "")
+	require.Error(err)
+
+	entry, err := book.Set(delegator, " alice ", "pool member")
+	require.NoError(err)
+	require.Equal("alice", entry.Label)
+	maxAddr := common.Address{}
+	for i := range maxAddr {
+		maxAddr[i] = 0xFF
+	}
+	_, err = book.Set(maxAddr, "bob", "")
+	require.NoError(err)
+	require.Equal("alice", book.Label(delegator))
+
+	// labels are persisted
+	book = NewBook(memdb, appState, secStore, poolManager)
+	list := book.List()
+	require.Len(list, 2)
+	require.Equal("alice", list[0].Label)
+	require.Equal("pool member", list[0].Note)
+	require.Equal(maxAddr, list[1].Address)
+	require.NotNil(book.Get(maxAddr))
+
+	require.True(book.Remove(delegator))
+	require.False(book.Remove(delegator))
+	require.Nil(book.Get(delegator))
+	require.Equal(DelegatorLabel, book.Label(delegator))
+	require.Len(NewBook(memdb, appState, secStore, poolManager).List(), 1)
+}
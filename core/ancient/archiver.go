@@ -0,0 +1,328 @@
+// Package ancient moves bodies and certificates of canonical blocks older than configured number of epochs to the
+// object store and reads them back for the blockchain when they are requested
+package ancient
+
+import (
+	"container/list"
+	"fmt"
+	"github.com/golang/snappy"
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/objstore"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/database"
+	"github.com/idena-network/idena-go/ipfs"
+	"github.com/idena-network/idena-go/log"
+	"github.com/idena-network/idena-go/rlp"
+	"github.com/pkg/errors"
+	dbm "github.com/tendermint/tm-db"
+	"sync"
+	"time"
+)
+
+type ancientBlock struct {
+	Hash common.Hash
+	Body []byte
+	// Cert is the encoded certificate, empty if the block has no stored certificate
+	Cert []byte
+}
+
+// segment keeps blocks of heights From..From+len(Blocks)-1, heights without canonical blocks have empty entries
+type segment struct {
+	From   uint64
+	Blocks []ancientBlock
+}
+
+type Status struct {
+	Enabled  bool   `json:"enabled"`
+	Store    string `json:"store"`
+	Archived uint64 `json:"archived"`
+	// Cutoff is the first height which blocks are kept in the datadir by the current epoch
+	Cutoff       uint64 `json:"cutoff"`
+	CacheHits    uint64 `json:"cacheHits"`
+	CacheMisses  uint64 `json:"cacheMisses"`
+	LastError    string `json:"lastError,omitempty"`
+	LastRun      int64  `json:"lastRun,omitempty"`
+	SegmentSize  uint64 `json:"segmentSize"`
+	CachedBlocks int    `json:"cachedBlocks"`
+}
+
+type Archiver struct {
+	cfg     *config.AncientConfig
+	network uint32
+	chain   *blockchain.Blockchain
+	repo    *database.Repo
+	ipfs    ipfs.Proxy
+	store   objstore.Store
+	log     log.Logger
+	// segmentSize is the saved size of archived segments, the configured one applies only to the new archive
+	segmentSize uint64
+
+	mutex     sync.Mutex
+	state     database.AncientState
+	cache     *list.List
+	cached    map[uint64]*list.Element
+	hits      uint64
+	misses    uint64
+	lastRun   time.Time
+	lastError error
+	runMutex  sync.Mutex
+}
+
+func NewArchiver(cfg *config.Config, db dbm.DB, chain *blockchain.Blockchain, ipfsProxy ipfs.Proxy) (*Archiver, error) {
+	store, err := cfg.Ancient.NewStore(cfg.DataDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid ancient store config")
+	}
+	a := &Archiver{
+		cfg:     cfg.Ancient,
+		network: cfg.Network,
+		chain:   chain,
+		repo:    database.NewRepo(db),
+		ipfs:    ipfsProxy,
+		store:   store,
+		log:     log.New("component", "ancient"),
+		cache:   list.New(),
+		cached:  make(map[uint64]*list.Element),
+	}
+	if state := a.repo.ReadAncientState(); state != nil {
+		a.state = *state
+	}
+	if a.state.SegmentSize == 0 {
+		a.state.SegmentSize = cfg.Ancient.SegmentSize
+	} else if a.state.SegmentSize != cfg.Ancient.SegmentSize {
+		a.log.Warn("Ancient segment size differs from the archived one, the archived size is used",
+			"configured", cfg.Ancient.SegmentSize, "archived", a.state.SegmentSize)
+	}
+	a.segmentSize = a.state.SegmentSize
+	return a, nil
+}
+
+// Start schedules moving blocks, blocks which have been already moved are served regardless of the config
+func (a *Archiver) Start() {
+	if !a.cfg.Enabled || a.cfg.Interval <= 0 || a.segmentSize == 0 {
+		return
+	}
+	go a.loop()
+}
+
+func (a *Archiver) loop() {
+	for {
+		if err := a.Run(); err != nil {
+			a.log.Warn("Failed to move blocks to the ancient store", "err", err)
+		}
+		time.Sleep(a.cfg.Interval)
+	}
+}
+
+func (a *Archiver) segmentKey(from uint64) string {
+	return fmt.Sprintf("ancient/%v/blocks-%012d", a.network, from)
+}
+
+// Run moves all full segments below the cutoff height
+func (a *Archiver) Run() error {
+	a.runMutex.Lock()
+	defer a.runMutex.Unlock()
+	err := a.run()
+	a.mutex.Lock()
+	a.lastRun = time.Now()
+	a.lastError = err
+	a.mutex.Unlock()
+	return err
+}
+
+func (a *Archiver) run() error {
+	head := a.chain.Head
+	if head == nil {
+		return nil
+	}
+	a.mutex.Lock()
+	state := a.state
+	state.EpochStarts = append([]uint64{}, a.state.EpochStarts...)
+	a.mutex.Unlock()
+
+	for height := state.Scanned + 1; height <= head.Height(); height++ {
+		if header := a.chain.GetBlockHeaderByHeight(height); header != nil && header.Flags().HasFlag(types.ValidationFinished) {
+			state.EpochStarts = append(state.EpochStarts, height+1)
+		}
+		state.Scanned = height
+	}
+	a.saveState(state)
+
+	cutoff := cutoffHeight(state.EpochStarts, a.cfg.KeepEpochs)
+	moved := 0
+	for state.Archived+a.segmentSize < cutoff {
+		seg, err := a.collectSegment(state.Archived + 1)
+		if err != nil {
+			return err
+		}
+		data, err := rlp.EncodeToBytes(seg)
+		if err != nil {
+			return err
+		}
+		if err := a.store.Put(a.segmentKey(seg.From), snappy.Encode(nil, data)); err != nil {
+			return errors.Wrap(err, a.store.Name())
+		}
+		state.Archived = seg.From + uint64(len(seg.Blocks)) - 1
+		// blocks are read from the store from this point, so the datadir copies can be removed safely
+		a.saveState(state)
+		a.removeHot(seg)
+		moved += len(seg.Blocks)
+	}
+	if moved > 0 {
+		a.log.Info("Blocks moved to the ancient store", "blocks", moved, "archived", state.Archived, "store", a.store.Name())
+	}
+	return nil
+}
+
+// cutoffHeight returns the start of the oldest epoch kept in the datadir: the current epoch and keepEpochs before it
+func cutoffHeight(epochStarts []uint64, keepEpochs uint16) uint64 {
+	index := len(epochStarts) - 1 - int(keepEpochs)
+	if index < 0 {
+		return 0
+	}
+	return epochStarts[index]
+}
+
+func (a *Archiver) saveState(state database.AncientState) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.state = state
+	a.repo.WriteAncientState(&state)
+}
+
+func (a *Archiver) collectSegment(from uint64) (*segment, error) {
+	seg := &segment{From: from, Blocks: make([]ancientBlock, 0, a.segmentSize)}
+	for height := from; height < from+a.segmentSize; height++ {
+		header := a.chain.GetBlockHeaderByHeight(height)
+		if header == nil {
+			seg.Blocks = append(seg.Blocks, ancientBlock{})
+			continue
+		}
+		block := ancientBlock{Hash: header.Hash()}
+		if header.ProposedHeader != nil {
+			body, err := a.ipfs.Get(header.ProposedHeader.IpfsHash)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot read body of block %v", height)
+			}
+			block.Body = body
+		}
+		if _, cert := a.chain.GetCertificateByHeight(height); cert != nil {
+			data, err := rlp.EncodeToBytes(cert)
+			if err != nil {
+				return nil, err
+			}
+			block.Cert = data
+		}
+		seg.Blocks = append(seg.Blocks, block)
+	}
+	return seg, nil
+}
+
+func (a *Archiver) removeHot(seg *segment) {
+	for i, block := range seg.Blocks {
+		if block.Hash == (common.Hash{}) {
+			continue
+		}
+		height := seg.From + uint64(i)
+		if header := a.repo.ReadBlockHeader(block.Hash); header != nil && header.ProposedHeader != nil {
+			if err := a.ipfs.Unpin(header.ProposedHeader.IpfsHash); err != nil {
+				a.log.Debug("Cannot unpin ancient block body", "height", height, "err", err)
+			}
+		}
+		a.repo.RemoveCertificate(block.Hash)
+		a.repo.RemoveCertificateIndex(height)
+	}
+}
+
+func (a *Archiver) Contains(height uint64) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return height > 0 && height <= a.state.Archived
+}
+
+func (a *Archiver) ReadBlock(height uint64, hash common.Hash) ([]byte, *types.BlockCert, error) {
+	if a.segmentSize == 0 {
+		return nil, nil, errors.New("segment size is not set")
+	}
+	from := (height-1)/a.segmentSize*a.segmentSize + 1
+	seg, err := a.segment(from)
+	if err != nil {
+		return nil, nil, err
+	}
+	index := height - seg.From
+	if index >= uint64(len(seg.Blocks)) || seg.Blocks[index].Hash != hash {
+		return nil, nil, errors.Errorf("block %v is not found in the ancient store", hash.Hex())
+	}
+	block := seg.Blocks[index]
+	var cert *types.BlockCert
+	if len(block.Cert) > 0 {
+		cert = new(types.BlockCert)
+		if err := rlp.DecodeBytes(block.Cert, cert); err != nil {
+			return nil, nil, errors.Wrap(err, "invalid ancient certificate")
+		}
+	}
+	return block.Body, cert, nil
+}
+
+func (a *Archiver) segment(from uint64) (*segment, error) {
+	a.mutex.Lock()
+	if elem, ok := a.cached[from]; ok {
+		a.cache.MoveToFront(elem)
+		a.hits++
+		a.mutex.Unlock()
+		return elem.Value.(*segment), nil
+	}
+	a.misses++
+	a.mutex.Unlock()
+
+	data, err := a.store.Get(a.segmentKey(from))
+	if err != nil {
+		return nil, errors.Wrap(err, a.store.Name())
+	}
+	decoded, err := snappy.Decode(nil, data)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid ancient segment")
+	}
+	seg := new(segment)
+	if err := rlp.DecodeBytes(decoded, seg); err != nil {
+		return nil, errors.Wrap(err, "invalid ancient segment")
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if _, ok := a.cached[from]; !ok && a.cfg.CacheSegments > 0 {
+		a.cached[from] = a.cache.PushFront(seg)
+		for a.cache.Len() > a.cfg.CacheSegments {
+			oldest := a.cache.Back()
+			a.cache.Remove(oldest)
+			delete(a.cached, oldest.Value.(*segment).From)
+		}
+	}
+	return seg, nil
+}
+
+func (a *Archiver) Status() Status {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	status := Status{
+		Enabled:     a.cfg.Enabled,
+		Store:       a.store.Name(),
+		Archived:    a.state.Archived,
+		Cutoff:      cutoffHeight(a.state.EpochStarts, a.cfg.KeepEpochs),
+		CacheHits:   a.hits,
+		CacheMisses: a.misses,
+		SegmentSize: a.segmentSize,
+	}
+	for elem := a.cache.Front(); elem != nil; elem = elem.Next() {
+		status.CachedBlocks += len(elem.Value.(*segment).Blocks)
+	}
+	if !a.lastRun.IsZero() {
+		status.LastRun = a.lastRun.Unix()
+	}
+	if a.lastError != nil {
+		status.LastError = a.lastError.Error()
+	}
+	return status
+}
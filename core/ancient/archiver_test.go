@@ -0,0 +1,112 @@
+package ancient
+
+import (
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/mempool"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/database"
+	"github.com/idena-network/idena-go/ipfs"
+	"github.com/idena-network/idena-go/secstore"
+	"github.com/idena-network/idena-go/stats/collector"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tm-db"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+)
+
+func TestArchiver_Run(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "ancient")
+	defer os.RemoveAll(dir)
+
+	key, _ := crypto.GenerateKey()
+	secStore := secstore.NewSecStore()
+	secStore.AddKey(crypto.FromECDSA(key))
+	consensusCfg := config.GetDefaultConsensusConfig()
+	consensusCfg.Automine = true
+	cfg := &config.Config{
+		Network:   0x99,
+		DataDir:   dir,
+		Consensus: consensusCfg,
+		GenesisConf: &config.GenesisConf{
+			GodAddress:        secStore.GetAddress(),
+			FirstCeremonyTime: 4070908800,
+		},
+		Validation:       &config.ValidationConfig{},
+		Blockchain:       &config.BlockchainConfig{},
+		OfflineDetection: config.GetDefaultOfflineDetectionConfig(),
+		Ancient:          config.GetDefaultAncientConfig(),
+	}
+	cfg.Ancient.KeepEpochs = 1
+	cfg.Ancient.SegmentSize = 4
+
+	memDb := db.NewMemDB()
+	bus := eventbus.New()
+	appState := appstate.NewAppState(memDb, bus)
+	txPool := mempool.NewTxPool(appState, bus, config.GetDefaultMempoolConfig(), consensusCfg.MinFeePerByte)
+	ipfsProxy := ipfs.NewMemoryIpfsProxy()
+	chain := blockchain.NewBlockchain(cfg, memDb, txPool, appState, ipfsProxy, secStore, bus,
+		blockchain.NewOfflineDetector(cfg, memDb, appState, secStore, bus))
+	require.NoError(t, chain.InitializeChain())
+	appState.Initialize(chain.Head.Height())
+	for i := 0; i < 20; i++ {
+		block := chain.ProposeBlock()
+		block.Block.Header.ProposedHeader.Time = new(big.Int).Add(chain.Head.Time(), big.NewInt(20))
+		require.NoError(t, chain.AddBlock(block.Block, nil, collector.NewStatsCollector()))
+		vote := &types.Vote{Header: &types.VoteHeader{Round: chain.Head.Height(), Step: 1, VotedHash: chain.Head.Hash()}}
+		vote.Signature = secStore.Sign(vote.Header.SignatureHash().Bytes())
+		cert := types.FullBlockCert{Votes: []*types.Vote{vote}}
+		chain.WriteCertificate(chain.Head.Hash(), cert.Compress(), true)
+	}
+	repo := database.NewRepo(memDb)
+	repo.WriteAncientState(&database.AncientState{EpochStarts: []uint64{1, 5, 9, 13, 17}})
+
+	expected := chain.GetBlockByHeight(3)
+	_, expectedCert := chain.GetCertificateByHeight(3)
+	require.NotNil(t, expected)
+	require.NotNil(t, expectedCert)
+
+	archiver, err := NewArchiver(cfg, memDb, chain, ipfsProxy)
+	require.NoError(t, err)
+	chain.ProvideAncientStore(archiver)
+	require.NoError(t, archiver.Run())
+
+	status := archiver.Status()
+	require.Equal(t, uint64(12), status.Archived)
+	require.Equal(t, uint64(13), status.Cutoff)
+	require.True(t, archiver.Contains(12))
+	require.False(t, archiver.Contains(13))
+
+	require.Nil(t, repo.ReadCertificate(expected.Hash()))
+	require.NotNil(t, repo.ReadCertificate(chain.GetBlockHeaderByHeight(13).Hash()))
+
+	block := chain.GetBlockByHeight(3)
+	require.NotNil(t, block)
+	require.Equal(t, expected.Hash(), block.Hash())
+	require.Equal(t, expected.Body.Bytes(), block.Body.Bytes())
+	_, cert := chain.GetCertificateByHeight(3)
+	require.Equal(t, expectedCert, cert)
+	require.Equal(t, expectedCert, chain.GetCertificate(expected.Hash()))
+
+	_, _, err = archiver.ReadBlock(3, chain.GetBlockHeaderByHeight(4).Hash())
+	require.Error(t, err)
+	require.Equal(t, uint64(1), archiver.Status().CacheMisses)
+
+	reopened, err := NewArchiver(cfg, memDb, chain, ipfsProxy)
+	require.NoError(t, err)
+	require.Equal(t, uint64(12), reopened.Status().Archived)
+
+	cfg.Ancient.SegmentSize = 10
+	resized, err := NewArchiver(cfg, memDb, chain, ipfsProxy)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), resized.Status().SegmentSize)
+	data, resizedCert, err := resized.ReadBlock(7, chain.GetBlockHeaderByHeight(7).Hash())
+	require.NoError(t, err)
+	require.Equal(t, chain.GetBlockByHeight(7).Body.Bytes(), data)
+	require.NotNil(t, resizedCert)
+}
@@ -109,6 +109,9 @@ func (s *AppState) Initialize(height uint64) error {
 	if err != nil {
 		return err
 	}
+	if s.NonceCache != nil {
+		cache.InheritLimit(s.NonceCache)
+	}
 	s.NonceCache = cache
 
 	return nil
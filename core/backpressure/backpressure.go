@@ -0,0 +1,142 @@
+// Package backpressure tells RPC clients to slow down while the node can't keep the txs they send: the mempool is
+// close to its limits, the pool of txs deferred during sync is about to drop the oldest ones or block import lags
+// behind the network.
+package backpressure
+
+import (
+	"fmt"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/mempool"
+	"math"
+	"strings"
+)
+
+// ErrorCode is the rpc error code of rejected txs, the error data is RetryError
+const ErrorCode = -32803
+
+const (
+	MempoolReason   = "mempool"
+	DeferredReason  = "deferred"
+	ImportLagReason = "importLag"
+)
+
+type pool interface {
+	Usage() mempool.Usage
+}
+
+type syncer interface {
+	IsSyncing() bool
+	SyncProgress() (head uint64, top uint64)
+}
+
+type Status struct {
+	Busy    bool     `json:"busy"`
+	Reasons []string `json:"reasons"`
+	// RetryAfter is the suggested delay in seconds before the next attempt, it is zero if the node isn't busy
+	RetryAfter int           `json:"retryAfter"`
+	Syncing    bool          `json:"syncing"`
+	Head       uint64        `json:"head"`
+	Highest    uint64        `json:"highestBlock"`
+	ImportLag  uint64        `json:"importLag"`
+	Mempool    mempool.Usage `json:"mempool"`
+}
+
+// RetryError is returned for txs rejected while the node is busy
+type RetryError struct {
+	Reasons    []string `json:"reasons"`
+	RetryAfter int      `json:"retryAfter"`
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("node is busy (%v), retry after %vs", strings.Join(e.Reasons, ", "), e.RetryAfter)
+}
+
+func (e *RetryError) ErrorCode() int {
+	return ErrorCode
+}
+
+func (e *RetryError) ErrorData() interface{} {
+	return e
+}
+
+type conditions struct {
+	Syncing bool
+	Head    uint64
+	Highest uint64
+	Mempool mempool.Usage
+}
+
+type Monitor struct {
+	cfg    *config.BackpressureConfig
+	pool   pool
+	syncer syncer
+}
+
+func NewMonitor(cfg *config.BackpressureConfig, pool pool, syncer syncer) *Monitor {
+	return &Monitor{
+		cfg:    cfg,
+		pool:   pool,
+		syncer: syncer,
+	}
+}
+
+func (m *Monitor) collect() conditions {
+	cond := conditions{
+		Syncing: m.syncer.IsSyncing(),
+		Mempool: m.pool.Usage(),
+	}
+	cond.Head, cond.Highest = m.syncer.SyncProgress()
+	if cond.Highest < cond.Head {
+		cond.Highest = cond.Head
+	}
+	return cond
+}
+
+func (m *Monitor) Status() *Status {
+	return evaluate(m.collect(), m.cfg)
+}
+
+// Check returns RetryError if the node is busy, ceremony txs are always accepted since they can't be postponed
+func (m *Monitor) Check(tx *types.Transaction) error {
+	if !m.cfg.Enabled || mempool.IsPriorityTx(tx.Type) {
+		return nil
+	}
+	status := m.Status()
+	if !status.Busy {
+		return nil
+	}
+	return &RetryError{Reasons: status.Reasons, RetryAfter: status.RetryAfter}
+}
+
+func evaluate(c conditions, cfg *config.BackpressureConfig) *Status {
+	status := &Status{
+		Reasons:   []string{},
+		Syncing:   c.Syncing,
+		Head:      c.Head,
+		Highest:   c.Highest,
+		ImportLag: c.Highest - c.Head,
+		Mempool:   c.Mempool,
+	}
+	if !cfg.Enabled {
+		return status
+	}
+	saturated := func(value, limit int) bool {
+		return cfg.MempoolThreshold > 0 && limit > 0 && float64(value) >= cfg.MempoolThreshold*float64(limit)
+	}
+	if saturated(c.Mempool.Txs, c.Mempool.MaxTxs) || saturated(c.Mempool.Bytes, c.Mempool.MaxBytes) {
+		status.Reasons = append(status.Reasons, MempoolReason)
+	}
+	// txs received while syncing are deferred, the oldest ones are dropped above the limit
+	if c.Syncing && saturated(c.Mempool.Deferred, c.Mempool.MaxDeferred) {
+		status.Reasons = append(status.Reasons, DeferredReason)
+	}
+	if cfg.MaxImportLag > 0 && status.ImportLag > cfg.MaxImportLag {
+		status.Reasons = append(status.Reasons, ImportLagReason)
+	}
+	status.Busy = len(status.Reasons) > 0
+	if status.Busy {
+		status.RetryAfter = int(math.Ceil(cfg.RetryAfter.Seconds()))
+	}
+	return status
+}
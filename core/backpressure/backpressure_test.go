@@ -0,0 +1,95 @@
+package backpressure
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/mempool"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type testPool struct {
+	usage mempool.Usage
+}
+
+func (p *testPool) Usage() mempool.Usage {
+	return p.usage
+}
+
+type testSyncer struct {
+	syncing   bool
+	head, top uint64
+}
+
+func (s *testSyncer) IsSyncing() bool {
+	return s.syncing
+}
+
+func (s *testSyncer) SyncProgress() (uint64, uint64) {
+	return s.head, s.top
+}
+
+func TestEvaluate(t *testing.T) {
+	cfg := config.GetDefaultBackpressureConfig()
+	require := require.New(t)
+
+	ok := conditions{
+		Head:    100,
+		Highest: 100,
+		Mempool: mempool.Usage{Txs: 10, MaxTxs: 100, Bytes: 1000, MaxBytes: 10000, Deferred: 95, MaxDeferred: 100},
+	}
+	status := evaluate(ok, cfg)
+	require.False(status.Busy)
+	require.Empty(status.Reasons)
+	require.Zero(status.RetryAfter)
+
+	c := ok
+	c.Mempool.Txs = 90
+	status = evaluate(c, cfg)
+	require.True(status.Busy)
+	require.Equal([]string{MempoolReason}, status.Reasons)
+	require.Equal(20, status.RetryAfter)
+
+	c = ok
+	c.Mempool.Bytes = 9500
+	c.Syncing = true
+	c.Highest = 111
+	status = evaluate(c, cfg)
+	require.Equal([]string{MempoolReason, DeferredReason, ImportLagReason}, status.Reasons)
+	require.Equal(uint64(11), status.ImportLag)
+
+	c = ok
+	c.Mempool.MaxTxs = 0
+	c.Mempool.Txs = 1000
+	c.Highest = 110
+	require.False(evaluate(c, cfg).Busy)
+
+	disabled := *cfg
+	disabled.Enabled = false
+	c.Mempool.MaxTxs = 100
+	require.False(evaluate(c, &disabled).Busy)
+}
+
+func TestMonitor_Check(t *testing.T) {
+	cfg := config.GetDefaultBackpressureConfig()
+	cfg.RetryAfter = 1500 * time.Millisecond
+	pool := &testPool{mempool.Usage{Txs: 100, MaxTxs: 100}}
+	monitor := NewMonitor(cfg, pool, &testSyncer{head: 5, top: 3})
+
+	status := monitor.Status()
+	require.Equal(t, uint64(5), status.Highest)
+	require.Zero(t, status.ImportLag)
+
+	err := monitor.Check(&types.Transaction{Type: types.SendTx})
+	require.IsType(t, &RetryError{}, err)
+	retryErr := err.(*RetryError)
+	require.Equal(t, ErrorCode, retryErr.ErrorCode())
+	require.Equal(t, 2, retryErr.RetryAfter)
+	require.Equal(t, "node is busy (mempool), retry after 2s", retryErr.Error())
+
+	require.NoError(t, monitor.Check(&types.Transaction{Type: types.SubmitShortAnswersTx}))
+
+	pool.usage.Txs = 10
+	require.NoError(t, monitor.Check(&types.Transaction{Type: types.SendTx}))
+}
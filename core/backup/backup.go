@@ -0,0 +1,267 @@
+// Package backup makes encrypted backups of the node key, the API key and pending ceremony artifacts so the identity
+// epoch isn't lost if the datadir disk fails right before the validation
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/common/objstore"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/database"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/log"
+	"github.com/idena-network/idena-go/secstore"
+	"github.com/pkg/errors"
+	dbm "github.com/tendermint/tm-db"
+	"sync"
+	"time"
+)
+
+const (
+	archiveVersion = 1
+	keyPrefix      = "backup-"
+)
+
+// ceremonyTxTypes are own tx types the ceremony resends if they are missing in the epoch db
+var ceremonyTxTypes = []types.TxType{types.SubmitAnswersHashTx, types.SubmitShortAnswersTx, types.SubmitLongAnswersTx, types.EvidenceTx}
+
+type OwnTx struct {
+	Type types.TxType
+	Raw  []byte
+}
+
+// CeremonyArtifacts are own ceremony data of the epoch db and the answer audit which can't be recovered from the chain
+type CeremonyArtifacts struct {
+	Epoch        uint16
+	ShortAnswers []byte                `json:",omitempty"`
+	OwnTxs       []OwnTx               `json:",omitempty"`
+	Audit        *database.AnswerAudit `json:",omitempty"`
+}
+
+type Archive struct {
+	Version uint8
+	Created int64
+	Network uint32
+	Address common.Address
+	// NodeKey is the node key exported with the backup password
+	NodeKey  string
+	ApiKey   string
+	Ceremony *CeremonyArtifacts `json:",omitempty"`
+}
+
+type Result struct {
+	Key     string    `json:"key"`
+	Created time.Time `json:"created"`
+	Size    int       `json:"size"`
+	Epoch   uint16    `json:"epoch"`
+	// Stores are names of stores the backup has been written to
+	Stores []string `json:"stores"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+type Manager struct {
+	cfg      *config.Config
+	db       dbm.DB
+	appState *appstate.AppState
+	secStore *secstore.SecStore
+	bus      eventbus.Bus
+	log      log.Logger
+
+	mutex      sync.Mutex
+	last       *Result
+	lastPeriod state.ValidationPeriod
+}
+
+func NewManager(cfg *config.Config, db dbm.DB, appState *appstate.AppState, secStore *secstore.SecStore, bus eventbus.Bus) *Manager {
+	return &Manager{
+		cfg:      cfg,
+		db:       db,
+		appState: appState,
+		secStore: secStore,
+		bus:      bus,
+		log:      log.New("component", "backup"),
+	}
+}
+
+func (m *Manager) Start() {
+	if !m.cfg.Backup.Enabled {
+		return
+	}
+	if _, err := m.cfg.Backup.Password(); err != nil {
+		m.log.Error("Scheduled backups are disabled", "err", err)
+		return
+	}
+	m.lastPeriod = m.appState.State.ValidationPeriod()
+	_ = m.bus.Subscribe(events.AddBlockEventID,
+		func(e eventbus.Event) {
+			m.processBlock()
+		})
+	if m.cfg.Backup.Interval > 0 {
+		go m.loop()
+	}
+}
+
+func (m *Manager) loop() {
+	m.scheduled("startup")
+	ticker := time.NewTicker(m.cfg.Backup.Interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		m.scheduled("interval")
+	}
+}
+
+// processBlock makes backups when the flip lottery starts (flips and keys are ready) and when the long session starts
+// (short answers are committed)
+func (m *Manager) processBlock() {
+	period := m.appState.State.ValidationPeriod()
+	m.mutex.Lock()
+	changed := period != m.lastPeriod
+	m.lastPeriod = period
+	m.mutex.Unlock()
+	if changed && (period == state.FlipLotteryPeriod || period == state.LongSessionPeriod) {
+		go m.scheduled("ceremony")
+	}
+}
+
+func (m *Manager) scheduled(reason string) {
+	result, err := m.BackupNow()
+	if err != nil {
+		m.log.Error("Backup failed", "reason", reason, "err", err)
+		return
+	}
+	for _, storeErr := range result.Errors {
+		m.log.Warn("Backup is not stored", "key", result.Key, "err", storeErr)
+	}
+	m.log.Info("Backup created", "reason", reason, "key", result.Key, "stores", len(result.Stores))
+}
+
+// BackupNow writes the backup to all configured stores, it fails only if the backup isn't stored anywhere
+func (m *Manager) BackupNow() (*Result, error) {
+	password, err := m.cfg.Backup.Password()
+	if err != nil {
+		return nil, err
+	}
+	stores, err := m.cfg.Backup.Stores(m.cfg.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := m.createArchive(password)
+	if err != nil {
+		return nil, err
+	}
+	data, err := Encrypt(archive, password)
+	if err != nil {
+		return nil, err
+	}
+
+	created := time.Unix(archive.Created, 0).UTC()
+	result := &Result{
+		Key:     fmt.Sprintf("%v%v-%v.bak", keyPrefix, created.Format("20060102T150405Z"), m.appState.State.Epoch()),
+		Created: created,
+		Size:    len(data),
+		Epoch:   m.appState.State.Epoch(),
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for _, store := range stores {
+		if err := store.Put(result.Key, data); err != nil {
+			result.Errors = append(result.Errors, errors.Wrap(err, store.Name()).Error())
+			continue
+		}
+		result.Stores = append(result.Stores, store.Name())
+		if err := m.prune(store); err != nil {
+			m.log.Warn("Failed to remove old backups", "store", store.Name(), "err", err)
+		}
+	}
+	if len(result.Stores) == 0 {
+		return nil, errors.Errorf("backup is not stored: %v", result.Errors)
+	}
+	m.last = result
+	return result, nil
+}
+
+// Last returns the result of the last successful backup made since the node start
+func (m *Manager) Last() *Result {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.last
+}
+
+func (m *Manager) prune(store objstore.Store) error {
+	if m.cfg.Backup.Keep <= 0 {
+		return nil
+	}
+	keys, err := store.List(keyPrefix)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(keys)-m.cfg.Backup.Keep; i++ {
+		if err := store.Delete(keys[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Manager) createArchive(password string) (*Archive, error) {
+	nodeKey, err := m.secStore.ExportKey(password)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot export node key")
+	}
+	return &Archive{
+		Version:  archiveVersion,
+		Created:  time.Now().Unix(),
+		Network:  m.cfg.Network,
+		Address:  m.secStore.GetAddress(),
+		NodeKey:  nodeKey,
+		ApiKey:   m.cfg.RPC.APIKey,
+		Ceremony: readCeremonyArtifacts(m.db, m.appState.State.Epoch(), m.secStore.GetAddress()),
+	}, nil
+}
+
+func readCeremonyArtifacts(db dbm.DB, epoch uint16, addr common.Address) *CeremonyArtifacts {
+	edb := database.NewEpochDb(db, epoch)
+	artifacts := &CeremonyArtifacts{
+		Epoch:        epoch,
+		ShortAnswers: edb.ReadOwnShortAnswersBits(),
+		Audit:        database.NewRepo(db).ReadAnswerAudit(epoch, addr),
+	}
+	for _, txType := range ceremonyTxTypes {
+		if raw := edb.ReadOwnTx(txType); raw != nil {
+			artifacts.OwnTxs = append(artifacts.OwnTxs, OwnTx{Type: txType, Raw: raw})
+		}
+	}
+	if artifacts.ShortAnswers == nil && artifacts.Audit == nil && len(artifacts.OwnTxs) == 0 {
+		return nil
+	}
+	return artifacts
+}
+
+func Encrypt(archive *Archive, password string) ([]byte, error) {
+	data, err := json.Marshal(archive)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Encrypt(data, password)
+}
+
+func Decrypt(data []byte, password string) (*Archive, error) {
+	decrypted, err := crypto.Decrypt(data, password)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot decrypt backup")
+	}
+	archive := new(Archive)
+	if err := json.Unmarshal(decrypted, archive); err != nil {
+		return nil, errors.Wrap(err, "invalid backup")
+	}
+	if archive.Version != archiveVersion {
+		return nil, errors.Errorf("unsupported backup version %v", archive.Version)
+	}
+	return archive, nil
+}
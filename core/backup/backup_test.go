@@ -0,0 +1,77 @@
+package backup
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/database"
+	"github.com/idena-network/idena-go/rpc"
+	"github.com/idena-network/idena-go/secstore"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tm-db"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestRestore(t *testing.T) {
+	require := require.New(t)
+	dir, _ := ioutil.TempDir("", "backup")
+	defer os.RemoveAll(dir)
+
+	key, _ := crypto.GenerateKey()
+	secStore := secstore.NewSecStore()
+	secStore.AddKey(crypto.FromECDSA(key))
+	addr := secStore.GetAddress()
+
+	source := db.NewMemDB()
+	edb := database.NewEpochDb(source, 3)
+	edb.WriteOwnShortAnswers(types.NewAnswersFromBits(0, []byte{0x5}))
+	edb.WriteOwnTx(types.SubmitAnswersHashTx, []byte{0x1, 0x2})
+	database.NewRepo(source).WriteAnswerAudit(&database.AnswerAudit{Epoch: 3, Address: addr, Salt: []byte{0x3}})
+
+	nodeKey, err := secStore.ExportKey("password")
+	require.NoError(err)
+	archive := &Archive{
+		Version:  archiveVersion,
+		Address:  addr,
+		NodeKey:  nodeKey,
+		ApiKey:   "apikey",
+		Ceremony: readCeremonyArtifacts(source, 3, addr),
+	}
+	require.NotNil(archive.Ceremony)
+	require.Nil(readCeremonyArtifacts(source, 4, addr))
+
+	data, err := Encrypt(archive, "password")
+	require.NoError(err)
+	_, err = Decrypt(data, "wrong")
+	require.Error(err)
+	decrypted, err := Decrypt(data, "password")
+	require.NoError(err)
+
+	cfg := &config.Config{DataDir: dir, RPC: &rpc.Config{}}
+	target := db.NewMemDB()
+	require.NoError(Restore(cfg, target, decrypted, "password", false))
+
+	require.Equal(addr, crypto.PubkeyToAddress(cfg.StoredNodeKey().PublicKey))
+	require.Equal("apikey", cfg.RPC.APIKey)
+	restored := database.NewEpochDb(target, 3)
+	require.Equal([]byte{0x5}, restored.ReadOwnShortAnswersBits())
+	require.Equal([]byte{0x1, 0x2}, restored.ReadOwnTx(types.SubmitAnswersHashTx))
+	require.Equal([]byte{0x3}, database.NewRepo(target).ReadAnswerAudit(3, addr).Salt)
+
+	// restoring the same identity is idempotent, another identity requires force
+	require.NoError(Restore(cfg, target, decrypted, "password", false))
+	otherKey, _ := crypto.GenerateKey()
+	other := secstore.NewSecStore()
+	other.AddKey(crypto.FromECDSA(otherKey))
+	otherExported, _ := other.ExportKey("password")
+	otherArchive := &Archive{Version: archiveVersion, Address: other.GetAddress(), NodeKey: otherExported}
+	require.Error(Restore(cfg, target, otherArchive, "password", false))
+	require.NoError(Restore(cfg, target, otherArchive, "password", true))
+	require.Equal(other.GetAddress(), crypto.PubkeyToAddress(cfg.StoredNodeKey().PublicKey))
+
+	archive.Address = common.Address{0x1}
+	require.Error(Restore(cfg, target, archive, "password", true))
+}
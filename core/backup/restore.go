@@ -0,0 +1,102 @@
+package backup
+
+import (
+	"encoding/hex"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common/objstore"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/database"
+	"github.com/pkg/errors"
+	dbm "github.com/tendermint/tm-db"
+)
+
+// Latest loads the latest backup of the stores, stores are checked in order
+func Latest(stores []objstore.Store) (key string, data []byte, err error) {
+	for _, store := range stores {
+		keys, listErr := store.List(keyPrefix)
+		if listErr != nil {
+			err = errors.Wrap(listErr, store.Name())
+			continue
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		key = keys[len(keys)-1]
+		data, err = store.Get(key)
+		if err == nil {
+			return key, data, nil
+		}
+	}
+	if err == nil {
+		err = errors.New("no backups found")
+	}
+	return "", nil, err
+}
+
+// Restore writes the node key, the API key and ceremony artifacts of the backup to the datadir. Keys of another
+// identity are replaced only if force is set, existing ceremony artifacts are never overwritten
+func Restore(cfg *config.Config, db dbm.DB, archive *Archive, password string, force bool) error {
+	if archive.Network != cfg.Network && !force {
+		return errors.Errorf("backup is made for network %v, node network is %v", archive.Network, cfg.Network)
+	}
+	if err := restoreNodeKey(cfg, archive, password, force); err != nil {
+		return err
+	}
+	if archive.ApiKey != "" && cfg.RPC.APIKey == "" {
+		cfg.RPC.APIKey = archive.ApiKey
+		if err := cfg.SetApiKey(); err != nil {
+			return errors.Wrap(err, "cannot restore API key")
+		}
+	}
+	if archive.Ceremony != nil {
+		restoreCeremonyArtifacts(db, archive.Ceremony)
+	}
+	return nil
+}
+
+func restoreNodeKey(cfg *config.Config, archive *Archive, password string, force bool) error {
+	keyBytes, err := hex.DecodeString(archive.NodeKey)
+	if err != nil {
+		return errors.Wrap(err, "invalid node key")
+	}
+	decrypted, err := crypto.Decrypt(keyBytes, password)
+	if err != nil {
+		return errors.Wrap(err, "cannot decrypt node key")
+	}
+	key, err := crypto.ToECDSA(decrypted)
+	if err != nil {
+		return errors.Wrap(err, "invalid node key")
+	}
+	if crypto.PubkeyToAddress(key.PublicKey) != archive.Address {
+		return errors.New("node key doesn't match the backup address")
+	}
+	current := cfg.StoredNodeKey()
+	if current != nil {
+		if crypto.PubkeyToAddress(current.PublicKey) == archive.Address {
+			return nil
+		}
+		if !force {
+			return errors.Errorf("node key of %v already exists", crypto.PubkeyToAddress(current.PublicKey).Hex())
+		}
+	}
+	return cfg.ProvideNodeKey(archive.NodeKey, password, current != nil)
+}
+
+func restoreCeremonyArtifacts(db dbm.DB, artifacts *CeremonyArtifacts) {
+	edb := database.NewEpochDb(db, artifacts.Epoch)
+	if artifacts.ShortAnswers != nil && edb.ReadOwnShortAnswersBits() == nil {
+		edb.WriteOwnShortAnswers(types.NewAnswersFromBits(0, artifacts.ShortAnswers))
+	}
+	for _, tx := range artifacts.OwnTxs {
+		if edb.ReadOwnTx(tx.Type) == nil {
+			edb.WriteOwnTx(tx.Type, tx.Raw)
+		}
+	}
+	if audit := artifacts.Audit; audit != nil {
+		repo := database.NewRepo(db)
+		if repo.ReadAnswerAudit(audit.Epoch, audit.Address) == nil {
+			repo.WriteAnswerAudit(audit)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package ceremony
+
+import (
+	"github.com/idena-network/idena-go/blockchain/attachments"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/database"
+	"github.com/idena-network/idena-go/rlp"
+	"time"
+)
+
+// updateAnswerAudit applies the change to the audit trail of the current ceremony
+func (vc *ValidationCeremony) updateAnswerAudit(update func(audit *database.AnswerAudit)) {
+	vc.auditMutex.Lock()
+	defer vc.auditMutex.Unlock()
+	addr := vc.secStore.GetAddress()
+	audit := vc.repo.ReadAnswerAudit(vc.epoch, addr)
+	if audit == nil {
+		audit = &database.AnswerAudit{Epoch: vc.epoch, Address: addr}
+	}
+	update(audit)
+	vc.repo.WriteAnswerAudit(audit)
+}
+
+// auditSubmission records the own ceremony tx accepted for delivery with the answers and salt it commits to
+func (vc *ValidationCeremony) auditSubmission(tx *types.Transaction) {
+	raw, _ := rlp.EncodeToBytes(tx)
+	hash := tx.Hash()
+	vc.updateAnswerAudit(func(audit *database.AnswerAudit) {
+		audit.ShortFlips = vc.shortFlipsToSolve
+		audit.LongFlips = vc.longFlipsToSolve
+		switch tx.Type {
+		case types.SubmitAnswersHashTx:
+			audit.AnswersHash = common.BytesToHash(tx.Payload)
+			audit.ShortAnswers = vc.epochDb.ReadOwnShortAnswersBits()
+			audit.Salt = getShortAnswersSalt(vc.epoch, vc.secStore)
+		case types.SubmitShortAnswersTx:
+			if attachment := attachments.ParseShortAnswerAttachment(tx); attachment != nil {
+				audit.ShortAnswers = attachment.Answers
+				audit.Salt = attachment.Salt
+			}
+		case types.SubmitLongAnswersTx:
+			audit.LongAnswers = tx.Payload
+		}
+		for _, item := range audit.Txs {
+			if item.Hash == hash {
+				return
+			}
+		}
+		audit.Txs = append(audit.Txs, &database.AuditedTx{
+			Type:   tx.Type,
+			Hash:   hash,
+			Raw:    raw,
+			SentAt: uint64(time.Now().Unix()),
+		})
+	})
+}
+
+// auditMined records the block which includes the own ceremony tx and paths it has been delivered through
+func (vc *ValidationCeremony) auditMined(tx *types.Transaction, block *types.Block) {
+	hash := tx.Hash()
+	paths := vc.submitter.paths(hash)
+	vc.updateAnswerAudit(func(audit *database.AnswerAudit) {
+		for _, item := range audit.Txs {
+			if item.Hash == hash && item.BlockHeight == 0 {
+				item.BlockHash = block.Hash()
+				item.BlockHeight = block.Height()
+				item.MinedAt = block.Header.Time().Uint64()
+				item.Paths = paths
+			}
+		}
+	})
+}
+
+// AnswerAudit returns the audit trail of own submissions of the ceremony of the epoch
+func (vc *ValidationCeremony) AnswerAudit(epoch uint16) *database.AnswerAudit {
+	vc.auditMutex.Lock()
+	defer vc.auditMutex.Unlock()
+	return vc.repo.ReadAnswerAudit(epoch, vc.secStore.GetAddress())
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/idena-network/idena-go/core/appstate"
 	"github.com/idena-network/idena-go/core/flip"
 	"github.com/idena-network/idena-go/core/mempool"
+	"github.com/idena-network/idena-go/core/standby"
 	"github.com/idena-network/idena-go/core/state"
 	"github.com/idena-network/idena-go/crypto"
 	"github.com/idena-network/idena-go/crypto/sha3"
@@ -74,12 +75,15 @@ type ValidationCeremony struct {
 	keysPool                 *mempool.KeysPool
 	chain                    *blockchain.Blockchain
 	syncer                   protocol.Syncer
+	submitter                *answerSubmitter
 	blockHandlers            map[state.ValidationPeriod]blockHandler
 	validationStats          *statsTypes.ValidationStats
 	flipKeyWordPairs         []int
 	flipKeyWordProof         []byte
 	epoch                    uint16
 	config                   *config.Config
+	timeSync                 *protocol.TimeSync
+	standby                  *standby.Guard
 	applyEpochMutex          sync.Mutex
 	flipAuthorMap            map[common.Hash]common.Address
 	flipAuthorMapLock        sync.Mutex
@@ -88,6 +92,8 @@ type ValidationCeremony struct {
 	validationStartMutex     sync.Mutex
 	candidatesPerAuthor      map[int][]int
 	authorsPerCandidate      map[int][]int
+	repo                     *database.Repo
+	auditMutex               sync.Mutex
 }
 
 type epochApplyingCache struct {
@@ -107,7 +113,7 @@ type cacheValue struct {
 type blockHandler func(block *types.Block)
 
 func NewValidationCeremony(appState *appstate.AppState, bus eventbus.Bus, flipper *flip.Flipper, secStore *secstore.SecStore, db dbm.DB, mempool *mempool.TxPool,
-	chain *blockchain.Blockchain, syncer protocol.Syncer, keysPool *mempool.KeysPool, config *config.Config) *ValidationCeremony {
+	chain *blockchain.Blockchain, syncer protocol.Syncer, txSender DirectTxSender, keysPool *mempool.KeysPool, timeSync *protocol.TimeSync, standby *standby.Guard, config *config.Config) *ValidationCeremony {
 
 	vc := &ValidationCeremony{
 		flipper:            flipper,
@@ -116,11 +122,15 @@ func NewValidationCeremony(appState *appstate.AppState, bus eventbus.Bus, flippe
 		secStore:           secStore,
 		log:                log.New(),
 		db:                 db,
+		repo:               database.NewRepo(db),
 		mempool:            mempool,
 		keysPool:           keysPool,
 		epochApplyingCache: make(map[uint64]epochApplyingCache),
 		chain:              chain,
 		syncer:             syncer,
+		submitter:          newAnswerSubmitter(config.Validation.AnswerRelays, config.Validation.GetAnswerDirectPeers(), txSender),
+		timeSync:           timeSync,
+		standby:            standby,
 		config:             config,
 	}
 
@@ -245,7 +255,7 @@ func (vc *ValidationCeremony) startValidationShortSessionTimer() {
 	if vc.validationStartCtxCancel != nil {
 		return
 	}
-	t := time.Now().UTC()
+	t := vc.now()
 	validationTime := vc.appState.State.NextValidationTime()
 	if t.Before(validationTime) {
 		ctx, cancel := context.WithCancel(context.Background())
@@ -257,7 +267,7 @@ func (vc *ValidationCeremony) startValidationShortSessionTimer() {
 			for {
 				select {
 				case <-ticker.C:
-					if time.Now().UTC().After(validationTime) {
+					if vc.now().After(validationTime) {
 						if appState, err := vc.appState.Readonly(vc.chain.Head.Height()); err == nil {
 							vc.startShortSession(appState)
 							vc.log.Info("Timer triggered")
@@ -312,6 +322,7 @@ func (vc *ValidationCeremony) completeEpoch() {
 	vc.epochApplyingCache = make(map[uint64]epochApplyingCache)
 	vc.candidatesPerAuthor = nil
 	vc.authorsPerCandidate = nil
+	vc.submitter.reset()
 }
 
 func (vc *ValidationCeremony) handleBlock(block *types.Block) {
@@ -342,6 +353,7 @@ func (vc *ValidationCeremony) handleShortSessionPeriod(block *types.Block) {
 	vc.broadcastPrivateFlipKeysPackage(vc.appState)
 	vc.broadcastPublicFipKey(vc.appState)
 	vc.processCeremonyTxs(block)
+	vc.resubmitAnswers()
 }
 
 func (vc *ValidationCeremony) startShortSession(appState *appstate.AppState) {
@@ -370,6 +382,7 @@ func (vc *ValidationCeremony) handleLongSessionPeriod(block *types.Block) {
 	vc.broadcastPublicFipKey(vc.appState)
 	vc.processCeremonyTxs(block)
 	vc.broadcastEvidenceMap()
+	vc.resubmitAnswers()
 }
 
 func (vc *ValidationCeremony) handleAfterLongSessionPeriod(block *types.Block) {
@@ -418,6 +431,10 @@ func (vc *ValidationCeremony) calculateCeremonyCandidates() {
 
 func (vc *ValidationCeremony) shouldInteractWithNetwork() bool {
 
+	if vc.standby.IsStandby() {
+		return false
+	}
+
 	if !vc.syncer.IsSyncing() {
 		return true
 	}
@@ -431,7 +448,15 @@ func (vc *ValidationCeremony) shouldInteractWithNetwork() bool {
 	headTime := common.TimestampToTime(vc.chain.Head.Time())
 
 	// if head's timestamp is close to now() we should interact with network
-	return time.Now().UTC().Sub(headTime) < ceremonyDuration
+	return vc.now().Sub(headTime) < ceremonyDuration
+}
+
+// now returns current time corrected by measured clock drift if it is enabled
+func (vc *ValidationCeremony) now() time.Time {
+	if vc.timeSync == nil {
+		return vc.chain.Now()
+	}
+	return vc.timeSync.Now().Add(vc.chain.TimeOffset())
 }
 
 func (vc *ValidationCeremony) broadcastPublicFipKey(appState *appstate.AppState) {
@@ -570,13 +595,21 @@ func getFlipsToSolve(self common.Address, participants []*candidate, flipsPerCan
 }
 
 func (vc *ValidationCeremony) processCeremonyTxs(block *types.Block) {
+	coinbase := vc.secStore.GetAddress()
 	for _, tx := range block.Body.Transactions {
 		sender, _ := types.Sender(tx)
+		if sender == coinbase {
+			vc.submitter.confirm(tx.Hash())
+			switch tx.Type {
+			case types.SubmitAnswersHashTx, types.SubmitShortAnswersTx, types.SubmitLongAnswersTx, types.EvidenceTx:
+				vc.auditMined(tx, block)
+			}
+		}
 
 		switch tx.Type {
 		case types.SubmitAnswersHashTx:
 			if !vc.epochDb.HasAnswerHash(sender) {
-				vc.epochDb.WriteAnswerHash(sender, common.BytesToHash(tx.Payload), time.Now().UTC())
+				vc.epochDb.WriteAnswerHash(sender, common.BytesToHash(tx.Payload), vc.now())
 			}
 		case types.SubmitShortAnswersTx:
 			attachment := attachments.ParseShortAnswerAttachment(tx)
@@ -659,6 +692,9 @@ func (vc *ValidationCeremony) broadcastEvidenceMap() {
 }
 
 func (vc *ValidationCeremony) sendTx(txType uint16, payload []byte) (common.Hash, error) {
+	if vc.standby.IsStandby() {
+		return common.Hash{}, standby.StandbyMode
+	}
 	vc.mutex.Lock()
 	defer vc.mutex.Unlock()
 
@@ -689,11 +725,27 @@ func (vc *ValidationCeremony) sendTx(txType uint16, payload []byte) (common.Hash
 	} else {
 		vc.epochDb.WriteSuccessfulOwnTx(signedTx.Hash())
 	}
+	if err == nil || vc.epochDb.HasSuccessfulOwnTx(signedTx.Hash()) {
+		vc.submitter.submit(signedTx, err)
+		vc.auditSubmission(signedTx)
+	}
 	vc.logInfoWithInteraction("Broadcast ceremony tx", "type", txType, "hash", signedTx.Hash().Hex())
 
 	return signedTx.Hash(), err
 }
 
+func (vc *ValidationCeremony) resubmitAnswers() {
+	if !vc.shouldInteractWithNetwork() || !vc.isCandidate() {
+		return
+	}
+	vc.submitter.resubmit()
+}
+
+// AnswerSubmissions returns delivery paths of own ceremony transactions of the current epoch
+func (vc *ValidationCeremony) AnswerSubmissions() []AnswerSubmission {
+	return vc.submitter.list()
+}
+
 func applyOnState(appState *appstate.AppState, statsCollector collector.StatsCollector, addr common.Address, value cacheValue) (identitiesCount int) {
 	appState.State.SetState(addr, value.state)
 	appState.State.AddQualifiedFlipsCount(addr, value.shortQualifiedFlipsCount)
@@ -1215,6 +1267,10 @@ func (vc *ValidationCeremony) ShortSessionStarted() bool {
 	return vc.shortSessionStarted
 }
 
+func (vc *ValidationCeremony) ShortAnswersSent() bool {
+	return vc.shortAnswersSent
+}
+
 func (vc *ValidationCeremony) calculatePrivateFlipKeysIndexes() {
 	if !vc.isCandidate() {
 		return
@@ -1231,4 +1287,5 @@ func (vc *ValidationCeremony) calculatePrivateFlipKeysIndexes() {
 		}
 	}
 	vc.keysPool.InitializePrivateKeyIndexes(m)
+	vc.flipper.RetryDecryption()
 }
@@ -0,0 +1,110 @@
+package ceremony
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/events"
+	"sync"
+)
+
+const (
+	FlipLotteryStartedPhase   = "FlipLotteryStarted"
+	ShortSessionStartedPhase  = "ShortSessionStarted"
+	ShortSessionFinishedPhase = "ShortSessionFinished"
+	LongSessionStartedPhase   = "LongSessionStarted"
+	LongSessionFinishedPhase  = "LongSessionFinished"
+	ValidationFinishedPhase   = "ValidationFinished"
+	ResultsSealedPhase        = "ResultsSealed"
+)
+
+// resultsSealConfirmations is the number of blocks on top of the validation block after which its results
+// are not expected to be replaced by the fork
+const resultsSealConfirmations = 3
+
+// PhaseTracker publishes ceremony phase events by flags of applied blocks, so subscribers don't have to compute
+// phases from ceremony intervals
+type PhaseTracker struct {
+	appState *appstate.AppState
+	bus      eventbus.Bus
+
+	mutex sync.Mutex
+	seq   uint64
+	// sealing is the validation block which results are not sealed yet
+	sealing *events.CeremonyPhaseEvent
+}
+
+func NewPhaseTracker(appState *appstate.AppState, bus eventbus.Bus) *PhaseTracker {
+	return &PhaseTracker{
+		appState: appState,
+		bus:      bus,
+	}
+}
+
+func (t *PhaseTracker) Start() {
+	// block event is published synchronously after the block is committed, so the head state is the state of the block
+	_ = t.bus.Subscribe(events.AddBlockEventID,
+		func(e eventbus.Event) {
+			block := e.(*events.NewBlockEvent).Block
+			for _, phase := range t.processBlock(block.Header, t.appState.State.Epoch()) {
+				t.bus.Publish(phase)
+			}
+		})
+}
+
+// blockPhases returns phases the block with the flags starts or finishes in their order
+func blockPhases(flags types.BlockFlag) []string {
+	var result []string
+	if flags.HasFlag(types.FlipLotteryStarted) {
+		result = append(result, FlipLotteryStartedPhase)
+	}
+	if flags.HasFlag(types.ShortSessionStarted) {
+		result = append(result, ShortSessionStartedPhase)
+	}
+	if flags.HasFlag(types.LongSessionStarted) {
+		result = append(result, ShortSessionFinishedPhase, LongSessionStartedPhase)
+	}
+	if flags.HasFlag(types.AfterLongSessionStarted) {
+		result = append(result, LongSessionFinishedPhase)
+	}
+	if flags.HasFlag(types.ValidationFinished) {
+		result = append(result, ValidationFinishedPhase)
+	}
+	return result
+}
+
+// processBlock returns phase events of the block, epoch is the epoch of the state after the block
+func (t *PhaseTracker) processBlock(header *types.Header, epoch uint16) []*events.CeremonyPhaseEvent {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	var result []*events.CeremonyPhaseEvent
+	newEvent := func(phase string, epoch uint16, height uint64) *events.CeremonyPhaseEvent {
+		t.seq++
+		return &events.CeremonyPhaseEvent{
+			Seq:       t.seq,
+			Phase:     phase,
+			Epoch:     epoch,
+			Height:    height,
+			Timestamp: header.Time().Int64(),
+		}
+	}
+	height := header.Height()
+	if t.sealing != nil && height < t.sealing.Height {
+		// the validation block has been replaced by the fork
+		t.sealing = nil
+	}
+	for _, phase := range blockPhases(header.Flags()) {
+		e := newEvent(phase, epoch, height)
+		if phase == ValidationFinishedPhase {
+			// the block has already switched the state to the next epoch
+			e.Epoch = epoch - 1
+			t.sealing = e
+		}
+		result = append(result, e)
+	}
+	if t.sealing != nil && height >= t.sealing.Height+resultsSealConfirmations {
+		result = append(result, newEvent(ResultsSealedPhase, t.sealing.Epoch, t.sealing.Height))
+		t.sealing = nil
+	}
+	return result
+}
@@ -0,0 +1,56 @@
+package ceremony
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestPhaseTracker_processBlock(t *testing.T) {
+	require := require.New(t)
+	tracker := NewPhaseTracker(nil, nil)
+	header := func(height uint64, flags types.BlockFlag) *types.Header {
+		return &types.Header{
+			ProposedHeader: &types.ProposedHeader{
+				Height: height,
+				Time:   big.NewInt(int64(height * 20)),
+				Flags:  flags,
+			},
+		}
+	}
+	phases := func(height uint64, flags types.BlockFlag, epoch uint16) []string {
+		var result []string
+		for _, e := range tracker.processBlock(header(height, flags), epoch) {
+			result = append(result, e.Phase)
+		}
+		return result
+	}
+
+	require.Nil(phases(1, 0, 5))
+	require.Equal([]string{FlipLotteryStartedPhase}, phases(2, types.FlipLotteryStarted, 5))
+	require.Equal([]string{ShortSessionStartedPhase}, phases(3, types.ShortSessionStarted|types.IdentityUpdate, 5))
+	require.Equal([]string{ShortSessionFinishedPhase, LongSessionStartedPhase}, phases(4, types.LongSessionStarted, 5))
+	require.Equal([]string{LongSessionFinishedPhase}, phases(5, types.AfterLongSessionStarted, 5))
+
+	validation := tracker.processBlock(header(6, types.ValidationFinished|types.IdentityUpdate), 6)
+	require.Len(validation, 1)
+	require.Equal(ValidationFinishedPhase, validation[0].Phase)
+	require.Equal(uint16(5), validation[0].Epoch)
+	require.Equal(int64(120), validation[0].Timestamp)
+
+	require.Nil(phases(7, 0, 6))
+	require.Nil(phases(8, 0, 6))
+	sealed := tracker.processBlock(header(9, 0), 6)
+	require.Len(sealed, 1)
+	require.Equal(ResultsSealedPhase, sealed[0].Phase)
+	require.Equal(uint16(5), sealed[0].Epoch)
+	require.Equal(uint64(6), sealed[0].Height)
+	require.Equal(validation[0].Seq+1, sealed[0].Seq)
+	require.Nil(phases(10, 0, 6))
+
+	// the validation block replaced by the fork is sealed after the new one only
+	require.Equal([]string{ValidationFinishedPhase}, phases(11, types.ValidationFinished, 7))
+	require.Nil(phases(10, 0, 6))
+	require.Nil(phases(13, 0, 6))
+}
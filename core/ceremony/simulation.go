@@ -0,0 +1,317 @@
+package ceremony
+
+import (
+	"crypto/rand"
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/core/flip"
+	"github.com/idena-network/idena-go/core/mempool"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/crypto/ecies"
+	"github.com/idena-network/idena-go/ipfs"
+	"github.com/idena-network/idena-go/log"
+	"github.com/idena-network/idena-go/protocol"
+	"github.com/idena-network/idena-go/rlp"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"sync"
+	"time"
+)
+
+const (
+	simulatedFlipPartSize = 100 * 1024
+	simulatedNetworkSize  = 100
+)
+
+var SimulationInProgress = errors.New("ceremony simulation is already in progress")
+
+type SimulationStage struct {
+	Name     string
+	Duration time.Duration
+	// Deadline is the time the stage has during the real ceremony, zero means no limit
+	Deadline time.Duration
+	Err      error
+	// Warning describes a problem which is expected outside of the real ceremony
+	Warning string
+}
+
+func (s *SimulationStage) Failed() bool {
+	return s.Err != nil || s.Deadline > 0 && s.Duration > s.Deadline
+}
+
+type SimulationReport struct {
+	StartTime time.Time
+	Duration  time.Duration
+	Stages    []*SimulationStage
+}
+
+func (r *SimulationReport) Failed() bool {
+	for _, s := range r.Stages {
+		if s.Failed() {
+			return true
+		}
+	}
+	return false
+}
+
+type simulatedFlip struct {
+	cid        []byte
+	publicKey  *ecies.PrivateKey
+	privateKey *ecies.PrivateKey
+}
+
+// Simulator runs validation session steps locally against synthetic flips without broadcasting anything
+type Simulator struct {
+	vc         *ValidationCeremony
+	ipfsProxy  ipfs.Proxy
+	timeSync   *protocol.TimeSync
+	log        log.Logger
+	mutex      sync.Mutex
+	inProgress bool
+	last       *SimulationReport
+}
+
+func NewSimulator(vc *ValidationCeremony, ipfsProxy ipfs.Proxy, timeSync *protocol.TimeSync) *Simulator {
+	return &Simulator{
+		vc:        vc,
+		ipfsProxy: ipfsProxy,
+		timeSync:  timeSync,
+		log:       log.New("component", "ceremony-simulator"),
+	}
+}
+
+func (s *Simulator) LastReport() *SimulationReport {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.last
+}
+
+func (s *Simulator) Run() (*SimulationReport, error) {
+	s.mutex.Lock()
+	if s.inProgress {
+		s.mutex.Unlock()
+		return nil, SimulationInProgress
+	}
+	s.inProgress = true
+	s.mutex.Unlock()
+
+	report := s.run()
+
+	s.mutex.Lock()
+	s.inProgress = false
+	s.last = report
+	s.mutex.Unlock()
+
+	for _, stage := range report.Stages {
+		if stage.Failed() {
+			s.log.Warn("Ceremony simulation stage failed", "stage", stage.Name, "duration", stage.Duration, "deadline", stage.Deadline, "err", stage.Err)
+		} else {
+			s.log.Info("Ceremony simulation stage passed", "stage", stage.Name, "duration", stage.Duration, "warning", stage.Warning)
+		}
+	}
+	return report, nil
+}
+
+func (s *Simulator) run() *SimulationReport {
+	report := &SimulationReport{
+		StartTime: time.Now().UTC(),
+	}
+	conf := s.vc.config.Validation
+	networkSize := s.vc.appState.ValidatorsCache.NetworkSize()
+	if networkSize == 0 {
+		networkSize = simulatedNetworkSize
+	}
+	shortFlipsCount := int(common.ShortSessionFlipsCount())
+	longFlipsCount := int(common.LongSessionFlipsCount(networkSize))
+
+	stage := func(name string, deadline time.Duration, fn func(stage *SimulationStage) error) bool {
+		item := &SimulationStage{
+			Name:     name,
+			Deadline: deadline,
+		}
+		start := time.Now()
+		item.Err = fn(item)
+		item.Duration = time.Since(start)
+		report.Stages = append(report.Stages, item)
+		return item.Err == nil
+	}
+
+	stage("clock", 0, func(stage *SimulationStage) error {
+		if s.timeSync == nil {
+			return nil
+		}
+		status := s.timeSync.Status()
+		if status.Error != nil {
+			stage.Warning = "cannot measure clock drift: " + status.Error.Error()
+			return nil
+		}
+		if status.WrongTime {
+			return errors.Errorf("system clock is off by %v", status.Drift)
+		}
+		return nil
+	})
+
+	var flips []*simulatedFlip
+	defer func() {
+		s.removeFlips(flips)
+	}()
+	ok := stage("flip upload", 0, func(stage *SimulationStage) error {
+		var err error
+		flips, err = s.uploadFlips(shortFlipsCount + longFlipsCount)
+		return err
+	})
+	if !ok {
+		report.Duration = time.Since(report.StartTime)
+		return report
+	}
+
+	loaded := make(map[string]*flip.IpfsFlip)
+	stage("flip download", conf.GetFlipLotteryDuration(), func(stage *SimulationStage) error {
+		for _, f := range flips {
+			data, err := s.ipfsProxy.Get(f.cid)
+			if err != nil {
+				return errors.Wrap(err, "cannot get flip")
+			}
+			ipfsFlip := new(flip.IpfsFlip)
+			if err := rlp.DecodeBytes(data, ipfsFlip); err != nil {
+				return errors.Wrap(err, "cannot decode flip")
+			}
+			loaded[string(f.cid)] = ipfsFlip
+		}
+		return nil
+	})
+
+	stage("key distribution", conf.GetFlipLotteryDuration(), func(stage *SimulationStage) error {
+		pubKeys := make([][]byte, 0, common.LongSessionTesters)
+		for i := 0; i < common.LongSessionTesters; i++ {
+			key, err := crypto.GenerateKey()
+			if err != nil {
+				return err
+			}
+			pubKeys = append(pubKeys, crypto.FromECDSAPub(&key.PublicKey))
+		}
+		publicKey, privateKey := s.vc.flipper.GetFlipPublicEncryptionKey(), s.vc.flipper.GetFlipPrivateEncryptionKey()
+		keysPackage := &types.PrivateFlipKeysPackage{
+			Data:  mempool.EncryptPrivateKeysPackage(publicKey, privateKey, pubKeys),
+			Epoch: s.vc.appState.State.Epoch(),
+		}
+		_, err := s.vc.secStore.SignFlipKeysPackage(keysPackage)
+		return err
+	})
+
+	stage("short session", conf.GetShortSessionDuration(), func(stage *SimulationStage) error {
+		if err := s.decryptFlips(flips[:shortFlipsCount], loaded); err != nil {
+			return err
+		}
+		answers := types.NewAnswers(uint(shortFlipsCount))
+		salt := getShortAnswersSalt(s.vc.epoch, s.vc.secStore)
+		hash := rlp.Hash(append(answers.Bytes(), salt...))
+		return s.validateTx(stage, types.SubmitAnswersHashTx, hash[:])
+	})
+
+	stage("long session", conf.GetLongSessionDuration(networkSize), func(stage *SimulationStage) error {
+		if err := s.decryptFlips(flips[shortFlipsCount:], loaded); err != nil {
+			return err
+		}
+		answers := types.NewAnswers(uint(longFlipsCount))
+		return s.validateTx(stage, types.SubmitLongAnswersTx, answers.Bytes())
+	})
+
+	report.Duration = time.Since(report.StartTime)
+	return report
+}
+
+// uploadFlips adds synthetic flips to ipfs, flips uploaded before an error are returned to be removed
+func (s *Simulator) uploadFlips(count int) ([]*simulatedFlip, error) {
+	var result []*simulatedFlip
+	for i := 0; i < count; i++ {
+		publicKey, err := generateSimulationKey()
+		if err != nil {
+			return result, err
+		}
+		privateKey, err := generateSimulationKey()
+		if err != nil {
+			return result, err
+		}
+		publicPart, privatePart := make([]byte, simulatedFlipPartSize), make([]byte, simulatedFlipPartSize)
+		rand.Read(publicPart)
+		rand.Read(privatePart)
+
+		encryptedPublic, err := ecies.Encrypt(rand.Reader, &publicKey.PublicKey, publicPart, nil, nil)
+		if err != nil {
+			return result, err
+		}
+		encryptedPrivate, err := ecies.Encrypt(rand.Reader, &privateKey.PublicKey, privatePart, nil, nil)
+		if err != nil {
+			return result, err
+		}
+		data, _ := rlp.EncodeToBytes(&flip.IpfsFlip{
+			PubKey:      s.vc.secStore.GetPubKey(),
+			PublicPart:  encryptedPublic,
+			PrivatePart: encryptedPrivate,
+		})
+		c, err := s.ipfsProxy.Add(data, false)
+		if err != nil {
+			return result, errors.Wrap(err, "cannot add flip to ipfs")
+		}
+		result = append(result, &simulatedFlip{
+			cid:        c.Bytes(),
+			publicKey:  publicKey,
+			privateKey: privateKey,
+		})
+	}
+	return result, nil
+}
+
+// removeFlips unpins and removes synthetic flips so they aren't kept in the repo until the next gc
+func (s *Simulator) removeFlips(flips []*simulatedFlip) {
+	for _, f := range flips {
+		// flips are added unpinned, unpin fails unless something has pinned them meanwhile
+		_ = s.ipfsProxy.Unpin(f.cid)
+		if err := s.ipfsProxy.Remove(f.cid); err != nil {
+			s.log.Warn("Cannot remove simulated flip", "err", err)
+		}
+	}
+}
+
+func (s *Simulator) decryptFlips(flips []*simulatedFlip, loaded map[string]*flip.IpfsFlip) error {
+	for _, f := range flips {
+		ipfsFlip, ok := loaded[string(f.cid)]
+		if !ok {
+			return errors.New("flip is not loaded")
+		}
+		if _, err := f.publicKey.Decrypt(ipfsFlip.PublicPart, nil, nil); err != nil {
+			return errors.Wrap(err, "cannot decrypt flip public part")
+		}
+		if _, err := f.privateKey.Decrypt(ipfsFlip.PrivatePart, nil, nil); err != nil {
+			return errors.Wrap(err, "cannot decrypt flip private part")
+		}
+	}
+	return nil
+}
+
+// validateTx signs ceremony tx and validates it against mempool without adding it
+func (s *Simulator) validateTx(stage *SimulationStage, txType types.TxType, payload []byte) error {
+	appState, err := s.vc.appState.Readonly(s.vc.chain.Head.Height())
+	if err != nil {
+		return err
+	}
+	tx := blockchain.BuildTx(appState, s.vc.secStore.GetAddress(), nil, txType, decimal.Zero, decimal.Zero, decimal.Zero, 0, 0, payload)
+	signedTx, err := s.vc.secStore.SignTx(tx)
+	if err != nil {
+		return err
+	}
+	if err := s.vc.mempool.Validate(signedTx); err != nil {
+		stage.Warning = "tx is not accepted now: " + err.Error()
+	}
+	return nil
+}
+
+func generateSimulationKey() (*ecies.PrivateKey, error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	return ecies.ImportECDSA(key), nil
+}
@@ -0,0 +1,41 @@
+package ceremony
+
+import (
+	"errors"
+	"github.com/idena-network/idena-go/ipfs"
+	"github.com/idena-network/idena-go/log"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestSimulationReport_Failed(t *testing.T) {
+	report := &SimulationReport{
+		Stages: []*SimulationStage{
+			{Name: "clock"},
+			{Name: "flip download", Duration: time.Second, Deadline: time.Minute},
+			{Name: "flip upload", Duration: time.Hour, Warning: "slow"},
+		},
+	}
+	require.False(t, report.Failed())
+
+	report.Stages = append(report.Stages, &SimulationStage{Name: "short session", Duration: 3 * time.Minute, Deadline: 2 * time.Minute})
+	require.True(t, report.Failed())
+
+	report.Stages[3].Duration = time.Minute
+	require.False(t, report.Failed())
+
+	report.Stages[0].Err = errors.New("wrong time")
+	require.True(t, report.Failed())
+}
+
+func TestSimulator_removeFlips(t *testing.T) {
+	proxy := ipfs.NewMemoryIpfsProxy()
+	simulator := &Simulator{ipfsProxy: proxy, log: log.New()}
+	c, err := proxy.Add([]byte{0x1, 0x2}, false)
+	require.NoError(t, err)
+
+	simulator.removeFlips([]*simulatedFlip{{cid: c.Bytes()}})
+	_, err = proxy.Get(c.Bytes())
+	require.Error(t, err)
+}
@@ -0,0 +1,230 @@
+package ceremony
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/core/mempool"
+	"github.com/idena-network/idena-go/log"
+	"github.com/idena-network/idena-go/rlp"
+	"github.com/pkg/errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	GossipPath      = "gossip"
+	PeersPath       = "peers"
+	RelayPathPrefix = "relay:"
+
+	relayTimeout = time.Second * 10
+)
+
+// DirectTxSender delivers full transactions to peers bypassing the push-pull
+type DirectTxSender interface {
+	SendTxDirect(tx *types.Transaction, count int) int
+}
+
+type SubmissionAttempt struct {
+	Path  string    `json:"path"`
+	Time  time.Time `json:"time"`
+	Error string    `json:"error,omitempty"`
+}
+
+// AnswerSubmission tracks delivery of the own ceremony transaction through all paths
+type AnswerSubmission struct {
+	TxType    uint16              `json:"txType"`
+	Hash      common.Hash         `json:"hash"`
+	Attempts  []SubmissionAttempt `json:"attempts"`
+	Succeeded []string            `json:"succeeded"`
+	Mined     bool                `json:"mined"`
+
+	tx *types.Transaction
+}
+
+// answerSubmitter sends own answers and evidence through redundant paths to survive transient network partitions:
+// the gossip, full transactions to random peers and RPC endpoints of trusted relay nodes
+type answerSubmitter struct {
+	relays      []string
+	directPeers int
+	sender      DirectTxSender
+	client      *http.Client
+	log         log.Logger
+	mutex       sync.Mutex
+	submissions []*AnswerSubmission
+}
+
+func newAnswerSubmitter(relays []string, directPeers int, sender DirectTxSender) *answerSubmitter {
+	return &answerSubmitter{
+		relays:      relays,
+		directPeers: directPeers,
+		sender:      sender,
+		client:      &http.Client{Timeout: relayTimeout},
+		log:         log.New("component", "answerSubmitter"),
+	}
+}
+
+func (s *answerSubmitter) submission(tx *types.Transaction) *AnswerSubmission {
+	hash := tx.Hash()
+	for _, item := range s.submissions {
+		if item.Hash == hash {
+			return item
+		}
+	}
+	item := &AnswerSubmission{TxType: tx.Type, Hash: hash, tx: tx}
+	s.submissions = append(s.submissions, item)
+	return item
+}
+
+func (s *answerSubmitter) record(tx *types.Transaction, path string, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	item := s.submission(tx)
+	attempt := SubmissionAttempt{Path: path, Time: time.Now().UTC()}
+	if err != nil {
+		attempt.Error = err.Error()
+	} else {
+		found := false
+		for _, p := range item.Succeeded {
+			if p == path {
+				found = true
+				break
+			}
+		}
+		if !found {
+			item.Succeeded = append(item.Succeeded, path)
+		}
+	}
+	item.Attempts = append(item.Attempts, attempt)
+}
+
+// submit records the gossip result and sends the transaction through the backup paths
+func (s *answerSubmitter) submit(tx *types.Transaction, gossipErr error) {
+	if gossipErr == mempool.DuplicateTxError {
+		gossipErr = nil
+	}
+	s.record(tx, GossipPath, gossipErr)
+	s.sendBackup(tx)
+}
+
+func (s *answerSubmitter) sendBackup(tx *types.Transaction) {
+	if s.sender != nil && s.directPeers > 0 {
+		var err error
+		if s.sender.SendTxDirect(tx, s.directPeers) == 0 {
+			err = errors.New("no peers")
+		}
+		s.record(tx, PeersPath, err)
+	}
+	for _, url := range s.relays {
+		go func(url string) {
+			err := s.sendToRelay(url, tx)
+			if err != nil {
+				s.log.Warn("Failed to submit ceremony tx to relay", "url", url, "hash", tx.Hash().Hex(), "err", err)
+			}
+			s.record(tx, RelayPathPrefix+url, err)
+		}(url)
+	}
+}
+
+// resubmit repeats backup paths of transactions which are not mined yet
+func (s *answerSubmitter) resubmit() {
+	s.mutex.Lock()
+	var pending []*types.Transaction
+	for _, item := range s.submissions {
+		if !item.Mined {
+			pending = append(pending, item.tx)
+		}
+	}
+	s.mutex.Unlock()
+	for _, tx := range pending {
+		s.sendBackup(tx)
+	}
+}
+
+func (s *answerSubmitter) confirm(hash common.Hash) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, item := range s.submissions {
+		if item.Hash == hash && !item.Mined {
+			item.Mined = true
+			s.log.Info("Ceremony tx mined", "type", item.TxType, "hash", hash.Hex(), "paths", strings.Join(item.Succeeded, ","))
+		}
+	}
+}
+
+// paths returns delivery paths which accepted the transaction
+func (s *answerSubmitter) paths(hash common.Hash) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, item := range s.submissions {
+		if item.Hash == hash {
+			return append([]string(nil), item.Succeeded...)
+		}
+	}
+	return nil
+}
+
+func (s *answerSubmitter) reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.submissions = nil
+}
+
+func (s *answerSubmitter) list() []AnswerSubmission {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	result := make([]AnswerSubmission, 0, len(s.submissions))
+	for _, item := range s.submissions {
+		copied := *item
+		copied.Attempts = append([]SubmissionAttempt(nil), item.Attempts...)
+		copied.Succeeded = append([]string(nil), item.Succeeded...)
+		result = append(result, copied)
+	}
+	return result
+}
+
+type relayRequest struct {
+	JsonRPC string        `json:"jsonrpc"`
+	Id      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type relayResponse struct {
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (s *answerSubmitter) sendToRelay(url string, tx *types.Transaction) error {
+	txBytes, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return err
+	}
+	body, _ := json.Marshal(&relayRequest{
+		JsonRPC: "2.0",
+		Id:      1,
+		Method:  "bcn_sendRawTx",
+		Params:  []interface{}{hexutil.Bytes(txBytes)},
+	})
+	resp, err := s.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("relay responded with status %v", resp.Status)
+	}
+	var result relayResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return errors.Wrap(err, "invalid relay response")
+	}
+	if result.Error != nil && result.Error.Message != mempool.DuplicateTxError.Error() {
+		return errors.New(result.Error.Message)
+	}
+	return nil
+}
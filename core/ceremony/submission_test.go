@@ -0,0 +1,66 @@
+package ceremony
+
+import (
+	"encoding/json"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/core/mempool"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingSender struct {
+	sent int32
+}
+
+func (s *countingSender) SendTxDirect(tx *types.Transaction, count int) int {
+	atomic.AddInt32(&s.sent, 1)
+	return count
+}
+
+func TestAnswerSubmitter(t *testing.T) {
+	var relayed int32
+	relay := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req relayRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		require.Equal(t, "bcn_sendRawTx", req.Method)
+		if atomic.AddInt32(&relayed, 1) > 1 {
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"message":"` + mempool.DuplicateTxError.Error() + `"}}`))
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x01"}`))
+	}))
+	defer relay.Close()
+
+	sender := &countingSender{}
+	submitter := newAnswerSubmitter([]string{relay.URL, "http://127.0.0.1:1"}, 3, sender)
+	tx := &types.Transaction{Type: types.SubmitAnswersHashTx, AccountNonce: 1}
+
+	submitter.submit(tx, errors.New("gossip failed"))
+	require.Eventually(t, func() bool {
+		return len(submitter.list()[0].Attempts) == 4
+	}, time.Second*5, time.Millisecond*10)
+
+	submission := submitter.list()[0]
+	require.Equal(t, tx.Hash(), submission.Hash)
+	require.ElementsMatch(t, []string{PeersPath, RelayPathPrefix + relay.URL}, submission.Succeeded)
+
+	submitter.resubmit()
+	require.Eventually(t, func() bool {
+		return len(submitter.list()[0].Attempts) == 7
+	}, time.Second*5, time.Millisecond*10)
+	require.Equal(t, int32(2), atomic.LoadInt32(&sender.sent))
+	require.Len(t, submitter.list()[0].Succeeded, 2)
+
+	submitter.confirm(tx.Hash())
+	submitter.resubmit()
+	require.Equal(t, int32(2), atomic.LoadInt32(&sender.sent))
+	require.True(t, submitter.list()[0].Mined)
+
+	submitter.reset()
+	require.Empty(t, submitter.list())
+}
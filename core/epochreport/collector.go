@@ -0,0 +1,74 @@
+package epochreport
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/stats/collector"
+	statsTypes "github.com/idena-network/idena-go/stats/types"
+	"math/big"
+)
+
+// reportCollector passes stats to the wrapped collector and feeds the report builder
+type reportCollector struct {
+	collector.StatsCollector
+	builder *Builder
+}
+
+// Wrap returns the collector which feeds the builder in addition to the given one
+func (b *Builder) Wrap(c collector.StatsCollector) collector.StatsCollector {
+	if !b.cfg.Enabled {
+		return c
+	}
+	if c == nil {
+		c = collector.NewStatsCollector()
+	}
+	return &reportCollector{c, b}
+}
+
+func (c *reportCollector) EnableCollecting() {
+	c.StatsCollector.EnableCollecting()
+	c.builder.begin()
+}
+
+func (c *reportCollector) SetValidation(validation *statsTypes.ValidationStats) {
+	c.StatsCollector.SetValidation(validation)
+	c.builder.setValidation(validation)
+}
+
+func (c *reportCollector) SetAuthors(authors *types.ValidationAuthors) {
+	c.StatsCollector.SetAuthors(authors)
+	c.builder.setAuthors(authors)
+}
+
+func (c *reportCollector) AddValidationReward(addr common.Address, age uint16, balance *big.Int, stake *big.Int) {
+	c.StatsCollector.AddValidationReward(addr, age, balance, stake)
+	c.builder.addReward(addr, ValidationReward, balance, stake)
+}
+
+func (c *reportCollector) AddFlipsReward(addr common.Address, balance *big.Int, stake *big.Int, rewardedStrongFlipCids [][]byte,
+	rewardedWeakFlipCids [][]byte) {
+	c.StatsCollector.AddFlipsReward(addr, balance, stake, rewardedStrongFlipCids, rewardedWeakFlipCids)
+	c.builder.addReward(addr, FlipsReward, balance, stake)
+}
+
+func (c *reportCollector) AddInvitationsReward(addr common.Address, balance *big.Int, stake *big.Int, age uint16, txHash *common.Hash,
+	isSavedInviteWinner bool) {
+	c.StatsCollector.AddInvitationsReward(addr, balance, stake, age, txHash, isSavedInviteWinner)
+	c.builder.addReward(addr, InvitationsReward, balance, stake)
+}
+
+func (c *reportCollector) AddProposerReward(addr common.Address, balance *big.Int, stake *big.Int) {
+	c.StatsCollector.AddProposerReward(addr, balance, stake)
+	c.builder.addReward(addr, ProposerReward, balance, stake)
+}
+
+func (c *reportCollector) AddFinalCommitteeReward(addr common.Address, balance *big.Int, stake *big.Int) {
+	c.StatsCollector.AddFinalCommitteeReward(addr, balance, stake)
+	c.builder.addReward(addr, FinalCommitteeReward, balance, stake)
+}
+
+func (c *reportCollector) BeforeSetPenalty(addr common.Address, appState *appstate.AppState) {
+	c.StatsCollector.BeforeSetPenalty(addr, appState)
+	c.builder.addPenalty(addr)
+}
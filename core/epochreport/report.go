@@ -0,0 +1,353 @@
+package epochreport
+
+import (
+	"encoding/json"
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/identity"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/database"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/log"
+	"github.com/idena-network/idena-go/secstore"
+	statsTypes "github.com/idena-network/idena-go/stats/types"
+	"github.com/shopspring/decimal"
+	dbm "github.com/tendermint/tm-db"
+	"math/big"
+	"sync"
+)
+
+const (
+	ValidationReward     = "validation"
+	FlipsReward          = "flips"
+	InvitationsReward    = "invitations"
+	ProposerReward       = "proposer"
+	FinalCommitteeReward = "finalCommittee"
+)
+
+type Reward struct {
+	Balance decimal.Decimal `json:"balance"`
+	Stake   decimal.Decimal `json:"stake"`
+}
+
+type Penalty struct {
+	Height uint64          `json:"height"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+type Validation struct {
+	Height      uint64  `json:"height"`
+	Failed      bool    `json:"failed"`
+	Approved    bool    `json:"approved"`
+	Missed      bool    `json:"missed"`
+	ShortPoints float32 `json:"shortPoints"`
+	ShortFlips  uint32  `json:"shortFlips"`
+	ShortScore  float32 `json:"shortScore"`
+	LongPoints  float32 `json:"longPoints"`
+	LongFlips   uint32  `json:"longFlips"`
+	LongScore   float32 `json:"longScore"`
+	PrevState   string  `json:"prevState"`
+	State       string  `json:"state"`
+}
+
+type Flips struct {
+	Strong    int   `json:"strong"`
+	Weak      int   `json:"weak"`
+	BadAuthor bool  `json:"badAuthor"`
+	BadReason *byte `json:"badReason,omitempty"`
+}
+
+// Report summarizes the epoch of the identity, it is in progress until the validation block of the epoch is added
+type Report struct {
+	Epoch         uint16             `json:"epoch"`
+	Address       common.Address     `json:"address"`
+	Complete      bool               `json:"complete"`
+	Validation    *Validation        `json:"validation,omitempty"`
+	Flips         *Flips             `json:"flips,omitempty"`
+	Rewards       map[string]*Reward `json:"rewards"`
+	TotalReward   Reward             `json:"totalReward"`
+	Penalties     []Penalty          `json:"penalties"`
+	BalanceBefore decimal.Decimal    `json:"balanceBefore"`
+	StakeBefore   decimal.Decimal    `json:"stakeBefore"`
+	BalanceAfter  decimal.Decimal    `json:"balanceAfter"`
+	StakeAfter    decimal.Decimal    `json:"stakeAfter"`
+}
+
+func (r *Report) addReward(kind string, balance, stake *big.Int) {
+	reward, ok := r.Rewards[kind]
+	if !ok {
+		reward = &Reward{}
+		r.Rewards[kind] = reward
+	}
+	b, s := blockchain.ConvertToFloat(balance), blockchain.ConvertToFloat(stake)
+	reward.Balance = reward.Balance.Add(b)
+	reward.Stake = reward.Stake.Add(s)
+	r.TotalReward.Balance = r.TotalReward.Balance.Add(b)
+	r.TotalReward.Stake = r.TotalReward.Stake.Add(s)
+}
+
+type reward struct {
+	addr           common.Address
+	kind           string
+	balance, stake *big.Int
+}
+
+type account struct {
+	state          state.IdentityState
+	balance, stake *big.Int
+}
+
+// blockData is collected while the block is being applied, it is merged into reports if the block is added
+type blockData struct {
+	epoch      uint16
+	before     map[common.Address]account
+	rewards    []reward
+	penalized  []common.Address
+	validation *statsTypes.ValidationStats
+	authors    *types.ValidationAuthors
+}
+
+// Builder collects epoch results of the coinbase and watched identities via stats collector hooks
+type Builder struct {
+	cfg      *config.EpochReportConfig
+	repo     *database.Repo
+	appState *appstate.AppState
+	secStore *secstore.SecStore
+	bus      eventbus.Bus
+	log      log.Logger
+
+	mutex   sync.Mutex
+	pending *blockData
+}
+
+func NewBuilder(cfg *config.EpochReportConfig, db dbm.DB, appState *appstate.AppState, secStore *secstore.SecStore, bus eventbus.Bus) *Builder {
+	return &Builder{
+		cfg:      cfg,
+		repo:     database.NewRepo(db),
+		appState: appState,
+		secStore: secStore,
+		bus:      bus,
+		log:      log.New("component", "epoch-report"),
+	}
+}
+
+func (b *Builder) Start() {
+	if !b.cfg.Enabled {
+		return
+	}
+	_ = b.bus.Subscribe(events.AddBlockEventID,
+		func(e eventbus.Event) {
+			b.complete(e.(*events.NewBlockEvent).Block)
+		})
+}
+
+func (b *Builder) addresses() []common.Address {
+	result := []common.Address{b.secStore.GetAddress()}
+	for _, addr := range b.cfg.Addresses {
+		if addr != result[0] {
+			result = append(result, addr)
+		}
+	}
+	return result
+}
+
+func (b *Builder) isTracked(addr common.Address) bool {
+	if b.pending == nil {
+		return false
+	}
+	_, ok := b.pending.before[addr]
+	return ok
+}
+
+// begin is called before the block is applied on the head state
+func (b *Builder) begin() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.pending = &blockData{
+		epoch:  b.appState.State.Epoch(),
+		before: make(map[common.Address]account),
+	}
+	for _, addr := range b.addresses() {
+		b.pending.before[addr] = b.readAccount(addr)
+	}
+}
+
+func (b *Builder) readAccount(addr common.Address) account {
+	return account{
+		state:   b.appState.State.GetIdentityState(addr),
+		balance: b.appState.State.GetBalance(addr),
+		stake:   b.appState.State.GetStakeBalance(addr),
+	}
+}
+
+func (b *Builder) addReward(addr common.Address, kind string, balance, stake *big.Int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.isTracked(addr) {
+		b.pending.rewards = append(b.pending.rewards, reward{addr, kind, copyInt(balance), copyInt(stake)})
+	}
+}
+
+func copyInt(value *big.Int) *big.Int {
+	if value == nil {
+		return nil
+	}
+	return new(big.Int).Set(value)
+}
+
+func (b *Builder) addPenalty(addr common.Address) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.isTracked(addr) {
+		b.pending.penalized = append(b.pending.penalized, addr)
+	}
+}
+
+func (b *Builder) setValidation(validation *statsTypes.ValidationStats) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.pending != nil {
+		b.pending.validation = validation
+	}
+}
+
+func (b *Builder) setAuthors(authors *types.ValidationAuthors) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.pending != nil {
+		b.pending.authors = authors
+	}
+}
+
+// complete merges data of the added block into reports of the epoch
+func (b *Builder) complete(block *types.Block) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	data := b.pending
+	b.pending = nil
+	if data == nil {
+		return
+	}
+	for addr, before := range data.before {
+		report := b.read(data.epoch, addr)
+		changed := report == nil
+		if report == nil {
+			report = &Report{
+				Epoch:         data.epoch,
+				Address:       addr,
+				Rewards:       make(map[string]*Reward),
+				BalanceBefore: blockchain.ConvertToFloat(before.balance),
+				StakeBefore:   blockchain.ConvertToFloat(before.stake),
+			}
+		}
+		for _, r := range data.rewards {
+			if r.addr == addr {
+				report.addReward(r.kind, r.balance, r.stake)
+				changed = true
+			}
+		}
+		for _, penalized := range data.penalized {
+			if penalized == addr {
+				report.Penalties = append(report.Penalties, Penalty{
+					Height: block.Height(),
+					Amount: blockchain.ConvertToFloat(b.appState.State.GetPenalty(addr)),
+				})
+				changed = true
+			}
+		}
+		if data.validation != nil {
+			report.Validation = buildValidation(block.Height(), data.validation, addr, before.state, b.appState.State.GetIdentityState(addr))
+			report.Flips = buildFlips(data.authors, addr)
+			report.Complete = true
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+		report.BalanceAfter = blockchain.ConvertToFloat(b.appState.State.GetBalance(addr))
+		report.StakeAfter = blockchain.ConvertToFloat(b.appState.State.GetStakeBalance(addr))
+		b.write(report)
+	}
+}
+
+func buildValidation(height uint64, stats *statsTypes.ValidationStats, addr common.Address, prevState, newState state.IdentityState) *Validation {
+	result := &Validation{
+		Height:    height,
+		Failed:    stats.Failed,
+		PrevState: identity.StateName(prevState),
+		State:     identity.StateName(newState),
+	}
+	if identity, ok := stats.IdentitiesPerAddr[addr]; ok {
+		result.Approved = identity.Approved
+		result.Missed = identity.Missed
+		result.ShortPoints, result.ShortFlips = identity.ShortPoint, identity.ShortFlips
+		result.LongPoints, result.LongFlips = identity.LongPoint, identity.LongFlips
+		if identity.ShortFlips > 0 {
+			result.ShortScore = identity.ShortPoint / float32(identity.ShortFlips)
+		}
+		if identity.LongFlips > 0 {
+			result.LongScore = identity.LongPoint / float32(identity.LongFlips)
+		}
+	}
+	return result
+}
+
+func buildFlips(authors *types.ValidationAuthors, addr common.Address) *Flips {
+	if authors == nil {
+		return nil
+	}
+	result := &Flips{}
+	if reason, ok := authors.BadAuthors[addr]; ok {
+		result.BadAuthor = true
+		result.BadReason = &reason
+	}
+	if good, ok := authors.GoodAuthors[addr]; ok {
+		result.Strong = len(good.StrongFlipCids)
+		result.Weak = len(good.WeakFlipCids)
+	}
+	if !result.BadAuthor && result.Strong == 0 && result.Weak == 0 {
+		return nil
+	}
+	return result
+}
+
+func (b *Builder) read(epoch uint16, addr common.Address) *Report {
+	data := b.repo.ReadEpochReport(epoch, addr)
+	if data == nil {
+		return nil
+	}
+	report := new(Report)
+	if err := json.Unmarshal(data, report); err != nil {
+		b.log.Error("Cannot parse epoch report", "epoch", epoch, "addr", addr.Hex(), "err", err)
+		return nil
+	}
+	if report.Rewards == nil {
+		report.Rewards = make(map[string]*Reward)
+	}
+	return report
+}
+
+func (b *Builder) write(report *Report) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		b.log.Error("Cannot encode epoch report", "err", err)
+		return
+	}
+	b.repo.WriteEpochReport(report.Epoch, report.Address, data)
+}
+
+// Report returns the report of the identity, the report of the current epoch is in progress and shows the current balance
+func (b *Builder) Report(epoch uint16, addr common.Address) *Report {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	report := b.read(epoch, addr)
+	if report != nil && !report.Complete {
+		report.BalanceAfter = blockchain.ConvertToFloat(b.appState.State.GetBalance(addr))
+		report.StakeAfter = blockchain.ConvertToFloat(b.appState.State.GetStakeBalance(addr))
+	}
+	return report
+}
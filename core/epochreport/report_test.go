@@ -0,0 +1,63 @@
+package epochreport
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/core/state"
+	statsTypes "github.com/idena-network/idena-go/stats/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestReport_addReward(t *testing.T) {
+	require := require.New(t)
+	report := &Report{Rewards: make(map[string]*Reward)}
+	dna := func(amount int64) *big.Int {
+		return new(big.Int).Mul(big.NewInt(amount), common.DnaBase)
+	}
+	report.addReward(ProposerReward, dna(4), dna(1))
+	report.addReward(ProposerReward, dna(8), dna(2))
+	report.addReward(ValidationReward, dna(80), dna(20))
+
+	require.True(decimal.NewFromInt(12).Equal(report.Rewards[ProposerReward].Balance))
+	require.True(decimal.NewFromInt(3).Equal(report.Rewards[ProposerReward].Stake))
+	require.True(decimal.NewFromInt(92).Equal(report.TotalReward.Balance))
+	require.True(decimal.NewFromInt(23).Equal(report.TotalReward.Stake))
+}
+
+func TestBuildValidation(t *testing.T) {
+	require := require.New(t)
+	addr := common.Address{0x1}
+	stats := statsTypes.NewValidationStats()
+	stats.IdentitiesPerAddr[addr] = &statsTypes.IdentityStats{
+		ShortPoint: 5, ShortFlips: 6, LongPoint: 20, LongFlips: 25, Approved: true,
+	}
+
+	validation := buildValidation(100, stats, addr, state.Newbie, state.Verified)
+	require.Equal(uint64(100), validation.Height)
+	require.Equal("Newbie", validation.PrevState)
+	require.Equal("Verified", validation.State)
+	require.InDelta(0.8333, validation.ShortScore, 0.0001)
+	require.InDelta(0.8, validation.LongScore, 0.0001)
+	require.True(validation.Approved)
+
+	validation = buildValidation(100, stats, common.Address{0x2}, state.Candidate, state.Killed)
+	require.False(validation.Approved)
+	require.Zero(validation.ShortFlips)
+
+	authors := &types.ValidationAuthors{
+		BadAuthors: map[common.Address]types.BadAuthorReason{addr: types.WrongWordsBadAuthor},
+		GoodAuthors: map[common.Address]*types.ValidationResult{
+			{0x2}: {StrongFlipCids: [][]byte{{0x1}, {0x2}}, WeakFlipCids: [][]byte{{0x3}}},
+		},
+	}
+	flips := buildFlips(authors, addr)
+	require.True(flips.BadAuthor)
+	require.Equal(types.WrongWordsBadAuthor, *flips.BadReason)
+	flips = buildFlips(authors, common.Address{0x2})
+	require.Equal(2, flips.Strong)
+	require.Equal(1, flips.Weak)
+	require.Nil(buildFlips(authors, common.Address{0x3}))
+}
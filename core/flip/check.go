@@ -0,0 +1,270 @@
+package flip
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/database"
+	"github.com/idena-network/idena-go/rlp"
+	"github.com/pkg/errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"math/bits"
+)
+
+const (
+	flipImagesCount = 4
+	flipOrdersCount = 2
+	// maxImageHashDistance is the max hamming distance between perceptual hashes of similar images
+	maxImageHashDistance = 5
+	// maxOwnImageHashes limits stored hashes of own flip images, it covers recent epochs
+	maxOwnImageHashes = 400
+	minImageSide      = 32
+)
+
+// CheckResult describes problems found in the flip before submission, the flip with errors should not be submitted
+type CheckResult struct {
+	Errors   []string
+	Warnings []string
+	// ImageHashes are perceptual hashes of flip images
+	ImageHashes []uint64
+}
+
+func (r *CheckResult) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+func (r *CheckResult) error(format string, args ...interface{}) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
+
+func (r *CheckResult) warning(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// decodeFlipContent extracts images and orders from raw flip parts. The public part is the RLP list of images,
+// the private part is the RLP list of remaining images and orders. The legacy single part flip contains all images and orders.
+func decodeFlipContent(publicPart, privatePart []byte) (images [][]byte, orders [][]uint64, err error) {
+	var public []rlp.RawValue
+	if err := rlp.DecodeBytes(publicPart, &public); err != nil || len(public) == 0 {
+		return nil, nil, errors.New("public part is not a valid RLP list")
+	}
+	if err := rlp.DecodeBytes(public[0], &images); err != nil {
+		return nil, nil, errors.New("public part doesn't contain images")
+	}
+	if len(privatePart) == 0 {
+		if len(public) > 1 {
+			if err := rlp.DecodeBytes(public[1], &orders); err != nil {
+				return nil, nil, errors.New("invalid orders")
+			}
+		}
+		return images, orders, nil
+	}
+	var private []rlp.RawValue
+	if err := rlp.DecodeBytes(privatePart, &private); err != nil || len(private) < 2 {
+		return nil, nil, errors.New("private part is not a valid RLP list of images and orders")
+	}
+	var privateImages [][]byte
+	if err := rlp.DecodeBytes(private[0], &privateImages); err != nil {
+		return nil, nil, errors.New("private part doesn't contain images")
+	}
+	if err := rlp.DecodeBytes(private[1], &orders); err != nil {
+		return nil, nil, errors.New("invalid orders")
+	}
+	return append(images, privateImages...), orders, nil
+}
+
+// imageHash calculates the average hash of the image downscaled to 8x8 gray pixels
+func imageHash(img image.Image) uint64 {
+	b := img.Bounds()
+	var gray [64]uint64
+	var total uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			x0, x1 := b.Min.X+x*b.Dx()/8, b.Min.X+(x+1)*b.Dx()/8
+			y0, y1 := b.Min.Y+y*b.Dy()/8, b.Min.Y+(y+1)*b.Dy()/8
+			var sum, count uint64
+			for py := y0; py < y1; py++ {
+				for px := x0; px < x1; px++ {
+					r, g, b, _ := img.At(px, py).RGBA()
+					sum += (299*uint64(r) + 587*uint64(g) + 114*uint64(b)) / 1000
+					count++
+				}
+			}
+			if count > 0 {
+				gray[y*8+x] = sum / count
+			}
+			total += gray[y*8+x]
+		}
+	}
+	avg := total / 64
+	var hash uint64
+	for i, v := range gray {
+		if v > avg {
+			hash |= 1 << uint(i)
+		}
+	}
+	return hash
+}
+
+func similar(a, b uint64) bool {
+	return bits.OnesCount64(a^b) <= maxImageHashDistance
+}
+
+func isPermutation(order []uint64, n int) bool {
+	if len(order) != n {
+		return false
+	}
+	seen := make(map[uint64]bool, n)
+	for _, idx := range order {
+		if idx >= uint64(n) || seen[idx] {
+			return false
+		}
+		seen[idx] = true
+	}
+	return true
+}
+
+// problem reports the error in the strict mode of flip_validate and the warning otherwise, such problems don't make
+// the flip invalid for the network, e.g. images of formats the node can't decode
+func (r *CheckResult) problem(strict bool, format string, args ...interface{}) {
+	if strict {
+		r.error(format, args...)
+	} else {
+		r.warning(format, args...)
+	}
+}
+
+func checkFlipContent(publicPart, privatePart []byte, ownHashes []uint64, strict bool) *CheckResult {
+	result := &CheckResult{}
+	if size := len(publicPart) + len(privatePart); size > common.MaxFlipSize {
+		result.error("flip is too big, max expected size %v, actual %v", common.MaxFlipSize, size)
+	} else if size > common.MaxFlipSize*9/10 {
+		result.warning("flip size %v is close to the limit %v, encrypted flip may exceed it", size, common.MaxFlipSize)
+	}
+
+	images, orders, err := decodeFlipContent(publicPart, privatePart)
+	if err != nil {
+		result.error("%v", err)
+		return result
+	}
+	if len(images) != flipImagesCount {
+		result.error("flip should contain %v images, actual %v", flipImagesCount, len(images))
+	}
+	for i, data := range images {
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			result.problem(strict, "image %v cannot be decoded: %v", i, err)
+			continue
+		}
+		if b := img.Bounds(); b.Dx() < minImageSide || b.Dy() < minImageSide {
+			result.warning("image %v is too small: %vx%v", i, b.Dx(), b.Dy())
+		}
+		hash := imageHash(img)
+		if hash == 0 || hash == ^uint64(0) {
+			result.warning("image %v is blank", i)
+			result.ImageHashes = append(result.ImageHashes, hash)
+			continue
+		}
+		for j, prev := range result.ImageHashes {
+			if similar(hash, prev) {
+				result.warning("image %v looks like image %v", i, j)
+				break
+			}
+		}
+		for _, own := range ownHashes {
+			if similar(hash, own) {
+				result.problem(strict, "image %v looks like an image of a previously submitted flip", i)
+				break
+			}
+		}
+		result.ImageHashes = append(result.ImageHashes, hash)
+	}
+
+	if len(orders) != flipOrdersCount {
+		result.error("flip should contain %v orders, actual %v", flipOrdersCount, len(orders))
+		return result
+	}
+	for i, order := range orders {
+		if !isPermutation(order, len(images)) {
+			result.error("order %v is not a permutation of images", i)
+		}
+	}
+	if isPermutation(orders[0], len(images)) && sameOrder(orders[0], orders[1]) {
+		result.error("orders are equal")
+	}
+	return result
+}
+
+func sameOrder(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckFlip runs local quality checks of the raw flip before submission: image decoding, size limits, similarity to
+// images of own previous flips, orders and key words pair sanity. Undecodable images and images of own mined flips
+// are errors only in the strict mode.
+func (fp *Flipper) CheckFlip(publicPart, privatePart []byte, pairId uint8, strict bool) *CheckResult {
+	var ownHashes []uint64
+	for _, own := range database.NewRepo(fp.db).ReadOwnFlipImages() {
+		ownHashes = append(ownHashes, own.Hashes...)
+	}
+	result := checkFlipContent(publicPart, privatePart, ownHashes, strict)
+	identity := fp.appState.State.GetIdentity(fp.secStore.GetAddress())
+	if pairsCount := identity.GetTotalWordPairsCount(); pairsCount > 0 && int(pairId) >= pairsCount {
+		result.error("pair %v is out of range, available key word pairs: %v", pairId, pairsCount)
+	}
+	for _, f := range identity.Flips {
+		if f.Pair == pairId {
+			result.warning("pair %v is already used by another flip", pairId)
+			break
+		}
+	}
+	return result
+}
+
+// rememberFlipImages stores hashes of images of the mined flip to detect reused images in next flips
+func (fp *Flipper) rememberFlipImages(flipCid []byte, hashes []uint64) {
+	if len(hashes) == 0 {
+		return
+	}
+	repo := database.NewRepo(fp.db)
+	fp.mutex.Lock()
+	defer fp.mutex.Unlock()
+	stored := []*database.OwnFlipImages{{Cid: flipCid, Hashes: hashes}}
+	count := len(hashes)
+	flips := repo.ReadOwnFlipImages()
+	for i := len(flips) - 1; i >= 0; i-- {
+		if bytes.Equal(flips[i].Cid, flipCid) {
+			continue
+		}
+		if count += len(flips[i].Hashes); count > maxOwnImageHashes {
+			break
+		}
+		stored = append([]*database.OwnFlipImages{flips[i]}, stored...)
+	}
+	repo.WriteOwnFlipImages(stored)
+}
+
+// forgetFlipImages removes hashes of images of the deleted flip, so its images may be submitted again
+func (fp *Flipper) forgetFlipImages(flipCid []byte) {
+	repo := database.NewRepo(fp.db)
+	fp.mutex.Lock()
+	defer fp.mutex.Unlock()
+	flips := repo.ReadOwnFlipImages()
+	for i, own := range flips {
+		if bytes.Equal(own.Cid, flipCid) {
+			repo.WriteOwnFlipImages(append(flips[:i], flips[i+1:]...))
+			return
+		}
+	}
+}
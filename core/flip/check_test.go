@@ -0,0 +1,70 @@
+package flip
+
+import (
+	"bytes"
+	"github.com/idena-network/idena-go/rlp"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func testImage(t *testing.T, seed int) []byte {
+	img := image.NewGray(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			img.SetGray(x, y, color.Gray{Y: uint8((x*seed + y*(seed+3)) % 2 * 255)})
+			if (x/8+y/8+seed)%(seed+2) == 0 {
+				img.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+	buf := new(bytes.Buffer)
+	require.NoError(t, png.Encode(buf, img))
+	return buf.Bytes()
+}
+
+func encodeFlip(t *testing.T, images [][]byte, orders [][]uint64) (public, private []byte) {
+	public, err := rlp.EncodeToBytes([]interface{}{images[:2]})
+	require.NoError(t, err)
+	private, err = rlp.EncodeToBytes([]interface{}{images[2:], orders})
+	require.NoError(t, err)
+	return public, private
+}
+
+func TestCheckFlipContent(t *testing.T) {
+	images := [][]byte{testImage(t, 1), testImage(t, 2), testImage(t, 3), testImage(t, 4)}
+	orders := [][]uint64{{0, 1, 2, 3}, {3, 2, 1, 0}}
+
+	public, private := encodeFlip(t, images, orders)
+	result := checkFlipContent(public, private, nil, true)
+	require.True(t, result.Valid(), result.Errors)
+	require.Len(t, result.ImageHashes, 4)
+
+	ownHashes := result.ImageHashes[:1]
+	result = checkFlipContent(public, private, ownHashes, true)
+	require.False(t, result.Valid())
+	require.Len(t, result.Errors, 1)
+	// images of own flips are only reported on submit
+	result = checkFlipContent(public, private, ownHashes, false)
+	require.True(t, result.Valid())
+	require.Equal(t, []string{"image 0 looks like an image of a previously submitted flip"}, result.Warnings)
+
+	public, private = encodeFlip(t, images, [][]uint64{{0, 1, 2, 3}, {0, 1, 2, 3}})
+	require.Equal(t, []string{"orders are equal"}, checkFlipContent(public, private, nil, true).Errors)
+
+	public, private = encodeFlip(t, images, [][]uint64{{0, 1, 2, 2}, {3, 2, 1, 0}})
+	require.Equal(t, []string{"order 0 is not a permutation of images"}, checkFlipContent(public, private, nil, true).Errors)
+
+	broken := append([][]byte{{0x1, 0x2}}, images[1:]...)
+	public, private = encodeFlip(t, broken, orders)
+	require.False(t, checkFlipContent(public, private, nil, true).Valid())
+	// the image of the format the node can't decode may still be valid for the network
+	result = checkFlipContent(public, private, nil, false)
+	require.True(t, result.Valid(), result.Errors)
+	require.Len(t, result.Warnings, 1)
+	require.Len(t, result.ImageHashes, 3)
+
+	require.False(t, checkFlipContent([]byte{0x1}, nil, nil, true).Valid())
+}
@@ -0,0 +1,236 @@
+package flip
+
+import (
+	"bytes"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/crypto/ecies"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/rlp"
+	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+const decryptionQueueSize = 1000
+
+var (
+	errFlipMissing       = errors.New("flip is missing")
+	errPublicKeyMissing  = errors.New("flip public key is missing")
+	errPrivateKeyMissing = errors.New("flip private key is missing")
+)
+
+type decryptedFlip struct {
+	publicPart  []byte
+	privatePart []byte
+}
+
+// waitingFlip is the loaded flip which can't be decrypted until flip keys of its author arrive
+type waitingFlip struct {
+	key    []byte
+	author common.Address
+}
+
+type FlipDecryptionStatus struct {
+	Workers     int `json:"workers"`
+	Loaded      int `json:"loaded"`
+	Decrypted   int `json:"decrypted"`
+	WaitingKeys int `json:"waitingKeys"`
+	Failed      int `json:"failed"`
+	Queued      int `json:"queued"`
+}
+
+// startDecryption runs the pool of decryption workers sized to the number of CPUs, flips are decrypted as soon as
+// they are loaded and retried when flip keys of their authors arrive
+func (fp *Flipper) startDecryption() {
+	fp.decryptionWorkers = runtime.NumCPU()
+	fp.decryptionQueue = make(chan func(), decryptionQueueSize)
+	for i := 0; i < fp.decryptionWorkers; i++ {
+		go func() {
+			for job := range fp.decryptionQueue {
+				atomic.AddInt32(&fp.queuedDecryptions, -1)
+				job()
+			}
+		}()
+	}
+	if fp.bus == nil {
+		return
+	}
+	// handlers are called under locks of the keys pool, so retries are scheduled asynchronously
+	fp.bus.Subscribe(events.NewFlipKeyID, func(e eventbus.Event) {
+		if sender, err := types.SenderFlipKey(e.(*events.NewFlipKeyEvent).Key); err == nil {
+			go fp.retryDecryption(&sender)
+		}
+	})
+	fp.bus.Subscribe(events.NewFlipKeysPackageID, func(e eventbus.Event) {
+		if sender, err := types.SenderFlipKeysPackage(e.(*events.NewFlipKeysPackageEvent).Key); err == nil {
+			go fp.retryDecryption(&sender)
+		}
+	})
+}
+
+func (fp *Flipper) schedule(job func()) {
+	atomic.AddInt32(&fp.queuedDecryptions, 1)
+	fp.decryptionQueue <- job
+}
+
+func (fp *Flipper) scheduleDecryption(key []byte) {
+	fp.schedule(func() {
+		fp.decrypt(key)
+	})
+}
+
+// retryDecryption schedules flips waiting for keys of the author, all waiting flips if the author is nil
+func (fp *Flipper) retryDecryption(author *common.Address) {
+	fp.mutex.Lock()
+	var keys [][]byte
+	for _, item := range fp.waitingKeys {
+		if author == nil || item.author == *author {
+			keys = append(keys, item.key)
+		}
+	}
+	fp.mutex.Unlock()
+	for _, key := range keys {
+		fp.scheduleDecryption(key)
+	}
+}
+
+// RetryDecryption schedules all flips waiting for keys, it should be called when private key indexes are initialized
+func (fp *Flipper) RetryDecryption() {
+	go fp.retryDecryption(nil)
+}
+
+// decrypt returns decrypted parts of the loaded flip, the result is cached until the flipper is cleared
+func (fp *Flipper) decrypt(key []byte) (*decryptedFlip, error) {
+	hash := common.Hash(rlp.Hash(key))
+
+	fp.mutex.Lock()
+	ipfsFlip := fp.flips[hash]
+	decrypted := fp.decrypted[hash]
+	fp.mutex.Unlock()
+
+	if decrypted != nil {
+		return decrypted, nil
+	}
+	if ipfsFlip == nil {
+		return nil, errFlipMissing
+	}
+
+	publicPart, privatePart, err := fp.decryptFlip(ipfsFlip)
+
+	fp.mutex.Lock()
+	defer fp.mutex.Unlock()
+	if fp.flips[hash] != ipfsFlip {
+		// flips have been cleared during decryption
+		return nil, errFlipMissing
+	}
+	if err != nil {
+		if err == errPublicKeyMissing || err == errPrivateKeyMissing {
+			author, _ := crypto.PubKeyBytesToAddress(ipfsFlip.PubKey)
+			fp.waitingKeys[hash] = waitingFlip{key: key, author: author}
+		} else {
+			fp.decryptFailures[hash] = struct{}{}
+		}
+		return nil, err
+	}
+	delete(fp.waitingKeys, hash)
+	delete(fp.decryptFailures, hash)
+	decrypted = &decryptedFlip{publicPart: publicPart, privatePart: privatePart}
+	fp.decrypted[hash] = decrypted
+	return decrypted, nil
+}
+
+func (fp *Flipper) decryptFlip(ipfsFlip *IpfsFlip) ([]byte, []byte, error) {
+	var publicEncryptionKey *ecies.PrivateKey
+	var privateEncryptionKey *ecies.PrivateKey
+	if bytes.Compare(ipfsFlip.PubKey, fp.secStore.GetPubKey()) == 0 {
+		publicEncryptionKey, privateEncryptionKey = fp.GetFlipPublicEncryptionKey(), fp.GetFlipPrivateEncryptionKey()
+	} else {
+		addr, _ := crypto.PubKeyBytesToAddress(ipfsFlip.PubKey)
+		publicEncryptionKey = fp.keyspool.GetPublicFlipKey(addr)
+		if publicEncryptionKey == nil {
+			return nil, nil, errPublicKeyMissing
+		}
+		if len(ipfsFlip.PrivatePart) > 0 {
+			privateEncryptionKey = fp.keyspool.GetPrivateFlipKey(addr)
+		}
+	}
+
+	decryptedPublicPart, err := publicEncryptionKey.Decrypt(ipfsFlip.PublicPart, nil, nil)
+
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "cannot decrypt flip public part")
+	}
+
+	var decryptedPrivatePart []byte
+	if len(ipfsFlip.PrivatePart) > 0 {
+		if privateEncryptionKey == nil {
+			return nil, nil, errPrivateKeyMissing
+		}
+
+		decryptedPrivatePart, err = privateEncryptionKey.Decrypt(ipfsFlip.PrivatePart, nil, nil)
+
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "cannot decrypt flip private part")
+		}
+	}
+
+	return decryptedPublicPart, decryptedPrivatePart, nil
+}
+
+// FlipsReadiness checks readiness of the flips using the decryption workers, flips are checked in parallel
+func (fp *Flipper) FlipsReadiness(keys [][]byte) []bool {
+	result := make([]bool, len(keys))
+	wg := sync.WaitGroup{}
+	wg.Add(len(keys))
+	for i, key := range keys {
+		i, key := i, key
+		fp.schedule(func() {
+			defer wg.Done()
+			result[i] = fp.IsFlipReady(key)
+		})
+	}
+	wg.Wait()
+	return result
+}
+
+func (fp *Flipper) IsFlipReady(key []byte) bool {
+	hash := common.Hash(rlp.Hash(key))
+
+	fp.mutex.Lock()
+	flip := fp.flips[hash]
+	isReady := fp.decrypted[hash] != nil
+	fp.mutex.Unlock()
+
+	if flip == nil {
+		return false
+	}
+
+	if !isReady {
+		if _, err := fp.decrypt(key); err == nil {
+			isReady = true
+		} else {
+			c, _ := cid.Cast(key)
+			fp.log.Warn("flip is not ready", "err", err, "cid", c.String())
+		}
+	}
+
+	return isReady
+}
+
+func (fp *Flipper) DecryptionStatus() FlipDecryptionStatus {
+	fp.mutex.Lock()
+	defer fp.mutex.Unlock()
+	return FlipDecryptionStatus{
+		Workers:     fp.decryptionWorkers,
+		Loaded:      len(fp.flips),
+		Decrypted:   len(fp.decrypted),
+		WaitingKeys: len(fp.waitingKeys),
+		Failed:      len(fp.decryptFailures),
+		Queued:      int(atomic.LoadInt32(&fp.queuedDecryptions)),
+	}
+}
@@ -0,0 +1,71 @@
+package flip
+
+import (
+	"crypto/rand"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/mempool"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/crypto/ecies"
+	"github.com/idena-network/idena-go/rlp"
+	"github.com/idena-network/idena-go/secstore"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+	"testing"
+)
+
+func TestFlipper_FlipsReadiness(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	secStore := secstore.NewSecStore()
+	secStore.AddKey(crypto.FromECDSA(key))
+	db := dbm.NewMemDB()
+	fp := NewFlipper(db, nil, mempool.NewKeysPool(db, nil, nil, secStore), nil, secStore, nil, nil, &config.FlipPrefetchConfig{})
+
+	publicKey, _ := crypto.GenerateKey()
+	privateKey, _ := crypto.GenerateKey()
+	fp.flipPublicKey, fp.flipPrivateKey = ecies.ImportECDSA(publicKey), ecies.ImportECDSA(privateKey)
+
+	encrypt := func(key *ecies.PrivateKey, data []byte) []byte {
+		encrypted, err := ecies.Encrypt(rand.Reader, &key.PublicKey, data, nil, nil)
+		require.NoError(t, err)
+		return encrypted
+	}
+
+	var keys [][]byte
+	for i := 0; i < 20; i++ {
+		keys = append(keys, []byte{byte(i)})
+		fp.flips[common.Hash(rlp.Hash(keys[i]))] = &IpfsFlip{
+			PubKey:      secStore.GetPubKey(),
+			PublicPart:  encrypt(fp.flipPublicKey, []byte{0x1, byte(i)}),
+			PrivatePart: encrypt(fp.flipPrivateKey, []byte{0x2, byte(i)}),
+		}
+	}
+	author, _ := crypto.GenerateKey()
+	foreignKey := []byte{0xff}
+	fp.flips[common.Hash(rlp.Hash(foreignKey))] = &IpfsFlip{
+		PubKey:     crypto.FromECDSAPub(&author.PublicKey),
+		PublicPart: []byte{0x1},
+	}
+
+	readiness := fp.FlipsReadiness(append(append(keys, foreignKey), []byte{0xfe}))
+	for i := range keys {
+		require.True(t, readiness[i])
+	}
+	require.False(t, readiness[len(keys)])
+	require.False(t, readiness[len(keys)+1])
+
+	publicPart, privatePart, err := fp.GetFlip(keys[3])
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x1, 0x3}, publicPart)
+	require.Equal(t, []byte{0x2, 0x3}, privatePart)
+
+	_, _, err = fp.GetFlip(foreignKey)
+	require.Equal(t, errPublicKeyMissing, err)
+
+	status := fp.DecryptionStatus()
+	require.Equal(t, len(keys)+1, status.Loaded)
+	require.Equal(t, len(keys), status.Decrypted)
+	require.Equal(t, 1, status.WaitingKeys)
+	require.Equal(t, 0, status.Failed)
+	require.Equal(t, 0, status.Queued)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/idena-network/idena-go/blockchain/types"
 	"github.com/idena-network/idena-go/common"
 	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/config"
 	"github.com/idena-network/idena-go/core/appstate"
 	"github.com/idena-network/idena-go/core/mempool"
 	"github.com/idena-network/idena-go/crypto"
@@ -24,6 +25,7 @@ import (
 	"github.com/pkg/errors"
 	dbm "github.com/tendermint/tm-db"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -41,7 +43,9 @@ type Flipper struct {
 	mutex            sync.Mutex
 	secStore         *secstore.SecStore
 	flips            map[common.Hash]*IpfsFlip
-	flipReadiness    map[common.Hash]bool
+	decrypted        map[common.Hash]*decryptedFlip
+	waitingKeys      map[common.Hash]waitingFlip
+	decryptFailures  map[common.Hash]struct{}
 	appState         *appstate.AppState
 	txpool           *mempool.TxPool
 	loadingCtx       context.Context
@@ -51,6 +55,18 @@ type Flipper struct {
 	flipsCache       *cache.Cache
 	flipPublicKey    *ecies.PrivateKey
 	flipPrivateKey   *ecies.PrivateKey
+	prefetchCfg      *config.FlipPrefetchConfig
+	bandwidth        *bandwidthLimiter
+	statusMutex      sync.Mutex
+	fetchStatuses    map[string]*FlipFetchStatus
+	pinsMutex        sync.Mutex
+	pinnedFlips      map[string]time.Time
+	// lowStorage is set while the disk budget is almost exhausted, loaded flips aren't pinned
+	lowStorage int32
+
+	decryptionWorkers int
+	decryptionQueue   chan func()
+	queuedDecryptions int32
 }
 type IpfsFlip struct {
 	PubKey      []byte
@@ -63,7 +79,7 @@ type IpfsFlipOld struct {
 	PubKey []byte
 }
 
-func NewFlipper(db dbm.DB, ipfsProxy ipfs.Proxy, keyspool *mempool.KeysPool, txpool *mempool.TxPool, secStore *secstore.SecStore, appState *appstate.AppState, bus eventbus.Bus) *Flipper {
+func NewFlipper(db dbm.DB, ipfsProxy ipfs.Proxy, keyspool *mempool.KeysPool, txpool *mempool.TxPool, secStore *secstore.SecStore, appState *appstate.AppState, bus eventbus.Bus, prefetchCfg *config.FlipPrefetchConfig) *Flipper {
 	ctx, cancel := context.WithCancel(context.Background())
 	fp := &Flipper{
 		db:               db,
@@ -73,15 +89,32 @@ func NewFlipper(db dbm.DB, ipfsProxy ipfs.Proxy, keyspool *mempool.KeysPool, txp
 		txpool:           txpool,
 		secStore:         secStore,
 		flips:            make(map[common.Hash]*IpfsFlip),
-		flipReadiness:    make(map[common.Hash]bool),
+		decrypted:        make(map[common.Hash]*decryptedFlip),
+		waitingKeys:      make(map[common.Hash]waitingFlip),
+		decryptFailures:  make(map[common.Hash]struct{}),
 		appState:         appState,
 		loadingCtx:       ctx,
 		cancelLoadingCtx: cancel,
 		bus:              bus,
 		flipsQueue:       make(chan *types.Flip, 1000),
 		flipsCache:       cache.New(time.Minute, time.Minute*2),
+		prefetchCfg:      prefetchCfg,
+		bandwidth:        &bandwidthLimiter{bytesPerSec: prefetchCfg.BandwidthLimit},
+		fetchStatuses:    make(map[string]*FlipFetchStatus),
+		pinnedFlips:      make(map[string]time.Time),
+	}
+	if bus != nil {
+		_ = bus.Subscribe(events.StorageLevelEventID, func(e eventbus.Event) {
+			var value int32
+			if e.(*events.StorageLevelEvent).Critical {
+				value = 1
+			}
+			atomic.StoreInt32(&fp.lowStorage, value)
+		})
 	}
+	fp.startDecryption()
 	go fp.writeLoop()
+	go fp.unpinLoop()
 	return fp
 }
 
@@ -104,9 +137,29 @@ func (fp *Flipper) addNewFlip(flip *types.Flip, local bool) error {
 	fp.mutex.Lock()
 	defer fp.mutex.Unlock()
 
+	data, c, err := fp.validateFlip(flip)
+	if err != nil {
+		return err
+	}
+
+	if err := fp.publishFlip(flip, data, c, local); err != nil {
+		return err
+	}
+
+	if local {
+		log.Info("Sending new flip tx", "hash", flip.Tx.Hash().Hex(), "nonce", flip.Tx.AccountNonce, "epoch", flip.Tx.Epoch)
+	}
+
+	if err := fp.txpool.Add(flip.Tx); err != nil && err != mempool.DuplicateTxError {
+		return err
+	}
+	return err
+}
+
+func (fp *Flipper) validateFlip(flip *types.Flip) ([]byte, cid.Cid, error) {
 	pubKey, err := types.SenderPubKey(flip.Tx)
 	if err != nil {
-		return errors.Errorf("flip tx has invalid pubkey, tx: %v", flip.Tx.Hash())
+		return nil, cid.Cid{}, errors.Errorf("flip tx has invalid pubkey, tx: %v", flip.Tx.Hash())
 	}
 	ipf := IpfsFlip{
 		PublicPart:  flip.PublicPart,
@@ -117,54 +170,53 @@ func (fp *Flipper) addNewFlip(flip *types.Flip, local bool) error {
 	data, _ := rlp.EncodeToBytes(ipf)
 
 	if len(data) > common.MaxFlipSize {
-		return errors.Errorf("flip is too big, max expected size %v, actual %v", common.MaxFlipSize, len(data))
+		return nil, cid.Cid{}, errors.Errorf("flip is too big, max expected size %v, actual %v", common.MaxFlipSize, len(data))
 	}
 
 	c, err := fp.ipfsProxy.Cid(data)
 
 	if err != nil {
-		return err
+		return nil, cid.Cid{}, err
 	}
 
 	if fp.epochDb.HasFlipCid(c.Bytes()) {
-		return DuplicateFlipError
+		return nil, cid.Cid{}, DuplicateFlipError
 	}
 
 	attachment := attachments.ParseFlipSubmitAttachment(flip.Tx)
 
 	if attachment == nil {
-		return errors.New("flip tx payload is invalid")
+		return nil, cid.Cid{}, errors.New("flip tx payload is invalid")
 	}
 
 	if bytes.Compare(c.Bytes(), attachment.Cid) != 0 {
-		return errors.Errorf("tx cid and flip cid mismatch, tx: %v", flip.Tx.Hash())
+		return nil, cid.Cid{}, errors.Errorf("tx cid and flip cid mismatch, tx: %v", flip.Tx.Hash())
 	}
 
 	if err := fp.txpool.Validate(flip.Tx); err != nil && err != mempool.DuplicateTxError {
 		log.Warn("Flip Tx is not valid", "hash", flip.Tx.Hash().Hex(), "err", err)
-		return err
+		return nil, cid.Cid{}, err
 	}
+	return data, c, nil
+}
 
-	_, err = fp.ipfsProxy.Add(data, fp.ipfsProxy.ShouldPin(ipfs.Flip) || local)
-
-	if err != nil {
+// publishFlip adds the validated flip to IPFS and marks its cid as known
+func (fp *Flipper) publishFlip(flip *types.Flip, data []byte, c cid.Cid, local bool) error {
+	pin := fp.ipfsProxy.ShouldPin(ipfs.Flip) || local
+	if _, err := fp.ipfsProxy.Add(data, pin); err != nil {
 		return err
 	}
 
+	if pin {
+		fp.bus.Publish(&events.IpfsPinnedEvent{Cid: c.Bytes(), DataType: ipfs.Flip})
+	}
+
 	fp.flipsCache.Add(string(c.Bytes()), flip, cache.DefaultExpiration)
 
 	fp.bus.Publish(&events.NewFlipEvent{Flip: flip})
 
 	fp.epochDb.WriteFlipCid(c.Bytes())
-
-	if local {
-		log.Info("Sending new flip tx", "hash", flip.Tx.Hash().Hex(), "nonce", flip.Tx.AccountNonce, "epoch", flip.Tx.Epoch)
-	}
-
-	if err := fp.txpool.Add(flip.Tx); err != nil && err != mempool.DuplicateTxError {
-		return err
-	}
-	return err
+	return nil
 }
 
 func (fp *Flipper) AddNewFlip(flip *types.Flip, local bool) error {
@@ -215,47 +267,11 @@ func (fp *Flipper) PrepareFlip(flipPublicPart []byte, flipPrivatePart []byte) (c
 }
 
 func (fp *Flipper) GetFlip(key []byte) (publicPart []byte, privatePart []byte, err error) {
-
-	fp.mutex.Lock()
-	ipfsFlip := fp.flips[common.Hash(rlp.Hash(key))]
-	fp.mutex.Unlock()
-
-	if ipfsFlip == nil {
-		return nil, nil, errors.New("flip is missing")
-	}
-
-	var publicEncryptionKey *ecies.PrivateKey
-	var privateEncryptionKey *ecies.PrivateKey
-	if bytes.Compare(ipfsFlip.PubKey, fp.secStore.GetPubKey()) == 0 {
-		publicEncryptionKey, privateEncryptionKey = fp.GetFlipPublicEncryptionKey(), fp.GetFlipPrivateEncryptionKey()
-	} else {
-		addr, _ := crypto.PubKeyBytesToAddress(ipfsFlip.PubKey)
-		publicEncryptionKey, privateEncryptionKey = fp.keyspool.GetPublicFlipKey(addr), fp.keyspool.GetPrivateFlipKey(addr)
-		if publicEncryptionKey == nil {
-			return nil, nil, errors.New("flip public key is missing")
-		}
-	}
-
-	decryptedPublicPart, err := publicEncryptionKey.Decrypt(ipfsFlip.PublicPart, nil, nil)
-
+	decrypted, err := fp.decrypt(key)
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "cannot decrypt flip public part")
+		return nil, nil, err
 	}
-
-	var decryptedPrivatePart []byte
-	if len(ipfsFlip.PrivatePart) > 0 {
-		if privateEncryptionKey == nil {
-			return nil, nil, errors.New("flip private key is missing")
-		}
-
-		decryptedPrivatePart, err = privateEncryptionKey.Decrypt(ipfsFlip.PrivatePart, nil, nil)
-
-		if err != nil {
-			return nil, nil, errors.Wrap(err, "cannot decrypt flip private part")
-		}
-	}
-
-	return decryptedPublicPart, decryptedPrivatePart, nil
+	return decrypted.publicPart, decrypted.privatePart, nil
 }
 
 func (fp *Flipper) GetFlipPublicEncryptionKey() *ecies.PrivateKey {
@@ -299,49 +315,6 @@ func (fp *Flipper) generateFlipEncryptionKey(public bool) *ecies.PrivateKey {
 	return ecies.ImportECDSA(flipKey)
 }
 
-func (fp *Flipper) Load(cids [][]byte) {
-	ctx := fp.loadingCtx
-
-	for len(cids) > 0 {
-
-		select {
-		case <-ctx.Done():
-			return
-		default:
-		}
-
-		key := cids[0]
-		cids = cids[1:]
-
-		cid, _ := cid.Cast(key)
-
-		data, err := fp.ipfsProxy.Get(key)
-
-		if err != nil {
-			fp.log.Warn("Can't get flip by cid", "cid", cid.String(), "err", err)
-			cids = append(cids, key)
-			continue
-		}
-
-		ipfsFlip := new(IpfsFlip)
-		if err := rlp.Decode(bytes.NewReader(data), ipfsFlip); err != nil {
-			oldIpfsFlip := new(IpfsFlipOld)
-			if err2 := rlp.Decode(bytes.NewReader(data), oldIpfsFlip); err2 != nil {
-				fp.log.Warn("Can't decode flip", "cid", cid.String(), "err", err)
-				continue
-			} else {
-				ipfsFlip.PublicPart = oldIpfsFlip.Data
-				ipfsFlip.PubKey = oldIpfsFlip.PubKey
-			}
-		}
-		fp.mutex.Lock()
-		fp.flips[common.Hash(rlp.Hash(key))] = ipfsFlip
-		fp.mutex.Unlock()
-	}
-	fp.log.Info("All flips were loaded")
-	fp.hasFlips = true
-}
-
 func (fp *Flipper) Clear() {
 	fp.mutex.Lock()
 	defer fp.mutex.Unlock()
@@ -349,44 +322,26 @@ func (fp *Flipper) Clear() {
 	fp.cancelLoadingCtx()
 	fp.hasFlips = false
 	fp.flips = make(map[common.Hash]*IpfsFlip)
-	fp.flipReadiness = make(map[common.Hash]bool)
+	fp.decrypted = make(map[common.Hash]*decryptedFlip)
+	fp.waitingKeys = make(map[common.Hash]waitingFlip)
+	fp.decryptFailures = make(map[common.Hash]struct{})
 	fp.Initialize()
 	fp.flipPrivateKey = nil
 	fp.flipPublicKey = nil
 	fp.loadingCtx, fp.cancelLoadingCtx = context.WithCancel(context.Background())
+
+	fp.statusMutex.Lock()
+	fp.fetchStatuses = make(map[string]*FlipFetchStatus)
+	fp.statusMutex.Unlock()
+	if fp.prefetchCfg.PinTTL == 0 {
+		go fp.unpinLoadedFlips(time.Now())
+	}
 }
 
 func (fp *Flipper) HasFlips() bool {
 	return fp.hasFlips
 }
 
-func (fp *Flipper) IsFlipReady(key []byte) bool {
-	hash := common.Hash(rlp.Hash(key))
-
-	fp.mutex.Lock()
-	flip := fp.flips[hash]
-	isReady := fp.flipReadiness[hash]
-	fp.mutex.Unlock()
-
-	if flip == nil {
-		return false
-	}
-
-	if !isReady {
-		if _, _, err := fp.GetFlip(key); err == nil {
-			fp.mutex.Lock()
-			isReady = true
-			fp.flipReadiness[hash] = true
-			fp.mutex.Unlock()
-		} else {
-			c, _ := cid.Cast(key)
-			log.Warn("flip is not ready", "err", err, "cid", c.String())
-		}
-	}
-
-	return isReady
-}
-
 func (fp *Flipper) UnpinFlip(flipCid []byte) {
 	fp.ipfsProxy.Unpin(flipCid)
 }
@@ -396,17 +351,7 @@ func (fp *Flipper) GetRawFlip(flipCid []byte) (*IpfsFlip, error) {
 	if err != nil {
 		return nil, err
 	}
-	ipfsFlip := new(IpfsFlip)
-	if err := rlp.Decode(bytes.NewReader(data), ipfsFlip); err != nil {
-		oldIpfsFlip := new(IpfsFlipOld)
-		if err2 := rlp.Decode(bytes.NewReader(data), oldIpfsFlip); err2 != nil {
-			return nil, err
-		} else {
-			ipfsFlip.PublicPart = oldIpfsFlip.Data
-			ipfsFlip.PubKey = oldIpfsFlip.PubKey
-		}
-	}
-	return ipfsFlip, nil
+	return decodeIpfsFlip(data)
 }
 
 func (fp *Flipper) Has(c []byte) bool {
@@ -0,0 +1,301 @@
+package flip
+
+import (
+	"bytes"
+	"context"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/ipfs"
+	"github.com/idena-network/idena-go/rlp"
+	"github.com/ipfs/go-cid"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type FetchState byte
+
+const (
+	FetchPending FetchState = iota
+	FetchLoading
+	FetchLoaded
+	FetchRetrying
+	FetchFailed
+)
+
+func (s FetchState) String() string {
+	switch s {
+	case FetchPending:
+		return "pending"
+	case FetchLoading:
+		return "loading"
+	case FetchLoaded:
+		return "loaded"
+	case FetchRetrying:
+		return "retrying"
+	case FetchFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+type FlipFetchStatus struct {
+	Cid         []byte
+	State       FetchState
+	Attempts    int
+	Size        int
+	LastError   string
+	NextAttempt time.Time
+	LoadedAt    time.Time
+	Pinned      bool
+}
+
+// defaultFlipSizeEstimate is reserved for the first download since the flip size is unknown before it is loaded
+const defaultFlipSizeEstimate = 100 * 1024
+
+// bandwidthLimiter delays downloads so that the average loading speed doesn't exceed the limit. The estimated size
+// is reserved before the download starts and the difference with the actual size is settled when it completes.
+type bandwidthLimiter struct {
+	mutex       sync.Mutex
+	bytesPerSec int64
+	next        time.Time
+	estimate    int
+}
+
+func (l *bandwidthLimiter) duration(size int) time.Duration {
+	return time.Duration(int64(size) * int64(time.Second) / l.bytesPerSec)
+}
+
+// reserve waits until the estimated size of the next download fits the limit, it returns the reserved size
+// and false if ctx is cancelled
+func (l *bandwidthLimiter) reserve(ctx context.Context) (int, bool) {
+	if l.bytesPerSec <= 0 {
+		return 0, true
+	}
+	l.mutex.Lock()
+	size := l.estimate
+	if size <= 0 {
+		size = defaultFlipSizeEstimate
+	}
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.duration(size))
+	l.mutex.Unlock()
+	return size, sleep(ctx, delay)
+}
+
+// settle corrects the reservation by the actual size of the completed download
+func (l *bandwidthLimiter) settle(reserved int, actual int) {
+	if l.bytesPerSec <= 0 {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.next = l.next.Add(l.duration(actual - reserved))
+	if actual > 0 {
+		l.estimate = actual
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (fp *Flipper) retryDelay(attempt int) time.Duration {
+	delay := fp.prefetchCfg.RetryMinDelay
+	for i := 1; i < attempt && delay < fp.prefetchCfg.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > fp.prefetchCfg.RetryMaxDelay {
+		delay = fp.prefetchCfg.RetryMaxDelay
+	}
+	return delay
+}
+
+// Load downloads flips using configured number of workers and retries failed downloads with backoff until loading is cancelled
+func (fp *Flipper) Load(cids [][]byte) {
+	ctx := fp.loadingCtx
+
+	queue := make(chan []byte, len(cids))
+	for _, key := range cids {
+		fp.updateFetchStatus(key, func(status *FlipFetchStatus) {
+			status.State = FetchPending
+		})
+		queue <- key
+	}
+	close(queue)
+
+	parallelism := fp.prefetchCfg.Parallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	wg := sync.WaitGroup{}
+	wg.Add(parallelism)
+	for i := 0; i < parallelism; i++ {
+		go func() {
+			defer wg.Done()
+			for key := range queue {
+				fp.loadFlip(ctx, key)
+			}
+		}()
+	}
+	wg.Wait()
+
+	select {
+	case <-ctx.Done():
+		return
+	default:
+	}
+	fp.log.Info("All flips were loaded")
+	fp.hasFlips = true
+}
+
+func (fp *Flipper) loadFlip(ctx context.Context, key []byte) {
+	c, _ := cid.Cast(key)
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		fp.updateFetchStatus(key, func(status *FlipFetchStatus) {
+			status.State = FetchLoading
+			status.Attempts = attempt
+		})
+
+		reserved, ok := fp.bandwidth.reserve(ctx)
+		if !ok {
+			return
+		}
+		data, err := fp.ipfsProxy.Get(key)
+		fp.bandwidth.settle(reserved, len(data))
+		if err != nil {
+			delay := fp.retryDelay(attempt)
+			fp.log.Warn("Can't get flip by cid", "cid", c.String(), "err", err, "attempt", attempt)
+			fp.updateFetchStatus(key, func(status *FlipFetchStatus) {
+				status.State = FetchRetrying
+				status.LastError = err.Error()
+				status.NextAttempt = time.Now().Add(delay)
+			})
+			if !sleep(ctx, delay) {
+				return
+			}
+			continue
+		}
+		ipfsFlip, err := decodeIpfsFlip(data)
+		if err != nil {
+			fp.log.Warn("Can't decode flip", "cid", c.String(), "err", err)
+			fp.updateFetchStatus(key, func(status *FlipFetchStatus) {
+				status.State = FetchFailed
+				status.LastError = err.Error()
+			})
+			return
+		}
+		fp.mutex.Lock()
+		fp.flips[common.Hash(rlp.Hash(key))] = ipfsFlip
+		fp.mutex.Unlock()
+		fp.scheduleDecryption(key)
+
+		pinned := bytes.Compare(ipfsFlip.PubKey, fp.secStore.GetPubKey()) != 0 && fp.pinLoadedFlip(key)
+		fp.updateFetchStatus(key, func(status *FlipFetchStatus) {
+			status.State = FetchLoaded
+			status.Size = len(data)
+			status.LastError = ""
+			status.LoadedAt = time.Now().UTC()
+			status.Pinned = pinned
+		})
+		return
+	}
+}
+
+func decodeIpfsFlip(data []byte) (*IpfsFlip, error) {
+	ipfsFlip := new(IpfsFlip)
+	if err := rlp.Decode(bytes.NewReader(data), ipfsFlip); err != nil {
+		oldIpfsFlip := new(IpfsFlipOld)
+		if err2 := rlp.Decode(bytes.NewReader(data), oldIpfsFlip); err2 != nil {
+			return nil, err
+		}
+		ipfsFlip.PublicPart = oldIpfsFlip.Data
+		ipfsFlip.PubKey = oldIpfsFlip.PubKey
+	}
+	return ipfsFlip, nil
+}
+
+func (fp *Flipper) pinLoadedFlip(key []byte) bool {
+	if !fp.prefetchCfg.Pin || atomic.LoadInt32(&fp.lowStorage) == 1 {
+		return false
+	}
+	if err := fp.ipfsProxy.Pin(key); err != nil {
+		c, _ := cid.Cast(key)
+		fp.log.Warn("Can't pin flip", "cid", c.String(), "err", err)
+		return false
+	}
+	fp.pinsMutex.Lock()
+	fp.pinnedFlips[string(key)] = time.Now()
+	fp.pinsMutex.Unlock()
+	fp.bus.Publish(&events.IpfsPinnedEvent{Cid: key, DataType: ipfs.Flip})
+	return true
+}
+
+func (fp *Flipper) unpinLoop() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for range ticker.C {
+		if fp.prefetchCfg.PinTTL > 0 {
+			fp.unpinLoadedFlips(time.Now().Add(-fp.prefetchCfg.PinTTL))
+		}
+	}
+}
+
+// unpinLoadedFlips unpins loaded flips which have been pinned before the given time
+func (fp *Flipper) unpinLoadedFlips(before time.Time) {
+	fp.pinsMutex.Lock()
+	var toUnpin [][]byte
+	for key, pinnedAt := range fp.pinnedFlips {
+		if pinnedAt.Before(before) {
+			toUnpin = append(toUnpin, []byte(key))
+			delete(fp.pinnedFlips, key)
+		}
+	}
+	fp.pinsMutex.Unlock()
+
+	for _, key := range toUnpin {
+		fp.ipfsProxy.Unpin(key)
+	}
+}
+
+func (fp *Flipper) updateFetchStatus(key []byte, update func(status *FlipFetchStatus)) {
+	fp.statusMutex.Lock()
+	defer fp.statusMutex.Unlock()
+	status, ok := fp.fetchStatuses[string(key)]
+	if !ok {
+		status = &FlipFetchStatus{Cid: key}
+		fp.fetchStatuses[string(key)] = status
+	}
+	update(status)
+}
+
+// FetchStatuses returns loading statuses of flips requested in the current epoch
+func (fp *Flipper) FetchStatuses() []FlipFetchStatus {
+	fp.statusMutex.Lock()
+	defer fp.statusMutex.Unlock()
+	result := make([]FlipFetchStatus, 0, len(fp.fetchStatuses))
+	for _, status := range fp.fetchStatuses {
+		result = append(result, *status)
+	}
+	return result
+}
@@ -0,0 +1,50 @@
+package flip
+
+import (
+	"context"
+	"github.com/idena-network/idena-go/config"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestFlipper_retryDelay(t *testing.T) {
+	fp := &Flipper{prefetchCfg: &config.FlipPrefetchConfig{
+		RetryMinDelay: time.Second,
+		RetryMaxDelay: 10 * time.Second,
+	}}
+	require.Equal(t, time.Second, fp.retryDelay(1))
+	require.Equal(t, 2*time.Second, fp.retryDelay(2))
+	require.Equal(t, 8*time.Second, fp.retryDelay(4))
+	require.Equal(t, 10*time.Second, fp.retryDelay(5))
+	require.Equal(t, 10*time.Second, fp.retryDelay(100))
+}
+
+func TestBandwidthLimiter_reserve(t *testing.T) {
+	limiter := &bandwidthLimiter{bytesPerSec: 1000, estimate: 100}
+	start := time.Now()
+	reserved, ok := limiter.reserve(context.Background())
+	require.True(t, ok)
+	require.Equal(t, 100, reserved)
+	require.True(t, time.Since(start) < 50*time.Millisecond)
+	limiter.settle(reserved, 200)
+	require.Equal(t, 200, limiter.estimate)
+
+	// the next download waits for the actual size of the previous one
+	reserved, ok = limiter.reserve(context.Background())
+	require.True(t, ok)
+	require.Equal(t, 200, reserved)
+	require.True(t, time.Since(start) >= 200*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, ok = limiter.reserve(ctx)
+	require.False(t, ok)
+
+	unlimited := &bandwidthLimiter{}
+	start = time.Now()
+	_, ok = unlimited.reserve(context.Background())
+	require.True(t, ok)
+	unlimited.settle(0, 1000000)
+	require.True(t, time.Since(start) < 50*time.Millisecond)
+}
@@ -0,0 +1,336 @@
+package flip
+
+import (
+	"github.com/idena-network/idena-go/blockchain/attachments"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/mempool"
+	"github.com/idena-network/idena-go/database"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/log"
+	"github.com/ipfs/go-cid"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// SubmissionPublishing means the flip is waiting to be added to IPFS
+	SubmissionPublishing = "publishing"
+	// SubmissionBroadcasting means the flip is published and its tx is waiting to be added to the mempool
+	SubmissionBroadcasting = "broadcasting"
+	// SubmissionPending means the flip tx is in the mempool
+	SubmissionPending   = "pending"
+	SubmissionConfirmed = "confirmed"
+	SubmissionFailed    = "failed"
+)
+
+type Submission struct {
+	Cid         string      `json:"cid"`
+	TxHash      common.Hash `json:"txHash"`
+	Epoch       uint16      `json:"epoch"`
+	State       string      `json:"state"`
+	Attempts    int         `json:"attempts"`
+	LastError   string      `json:"lastError,omitempty"`
+	Created     int64       `json:"created"`
+	Updated     int64       `json:"updated"`
+	NextAttempt int64       `json:"nextAttempt,omitempty"`
+	Height      uint64      `json:"height,omitempty"`
+
+	flip *types.Flip
+	// imageHashes are remembered once the flip tx is mined
+	imageHashes []uint64
+	data        []byte
+	cid         cid.Cid
+	next        time.Time
+	inProgress  bool
+}
+
+func (s *Submission) terminal() bool {
+	return s.State == SubmissionConfirmed || s.State == SubmissionFailed
+}
+
+// SubmissionManager publishes own flips to IPFS and broadcasts their txs retrying failures with backoff,
+// and tracks flips until their txs are included into the chain
+type SubmissionManager struct {
+	cfg   *config.FlipSubmissionConfig
+	fp    *Flipper
+	bus   eventbus.Bus
+	log   log.Logger
+	mutex sync.Mutex
+	items map[common.Hash]*Submission
+
+	stop     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+	started  int32
+}
+
+func NewSubmissionManager(cfg *config.FlipSubmissionConfig, fp *Flipper, bus eventbus.Bus) *SubmissionManager {
+	return &SubmissionManager{
+		cfg:     cfg,
+		fp:      fp,
+		bus:     bus,
+		log:     log.New("component", "flip-submission"),
+		items:   make(map[common.Hash]*Submission),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+func (m *SubmissionManager) Start() {
+	_ = m.bus.Subscribe(events.AddBlockEventID,
+		func(e eventbus.Event) {
+			m.processBlock(e.(*events.NewBlockEvent).Block)
+		})
+	atomic.StoreInt32(&m.started, 1)
+	go m.loop()
+}
+
+// Stop stops retrying submissions and waits until the current attempt is completed or timeout expires
+func (m *SubmissionManager) Stop(timeout time.Duration) bool {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+	if atomic.LoadInt32(&m.started) == 0 {
+		return true
+	}
+	select {
+	case <-m.stopped:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func (m *SubmissionManager) loop() {
+	defer close(m.stopped)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.retry(time.Now())
+		}
+	}
+}
+
+// Submit validates the own flip and starts its publication, validation errors are returned immediately
+// while publication and broadcast failures are retried
+func (m *SubmissionManager) Submit(flip *types.Flip, imageHashes []uint64) (Submission, error) {
+	m.fp.mutex.Lock()
+	data, c, err := m.fp.validateFlip(flip)
+	m.fp.mutex.Unlock()
+	if err != nil {
+		return Submission{}, err
+	}
+	now := time.Now()
+	submission := &Submission{
+		Cid:         c.String(),
+		TxHash:      flip.Tx.Hash(),
+		Epoch:       flip.Tx.Epoch,
+		State:       SubmissionPublishing,
+		Created:     now.Unix(),
+		Updated:     now.Unix(),
+		flip:        flip,
+		imageHashes: imageHashes,
+		data:        data,
+		cid:         c,
+	}
+	m.mutex.Lock()
+	m.items[submission.TxHash] = submission
+	m.trim()
+	submission.inProgress = true
+	m.mutex.Unlock()
+
+	m.attempt(submission)
+	return m.get(submission.TxHash), nil
+}
+
+func (m *SubmissionManager) trim() {
+	if m.cfg.HistorySize <= 0 || len(m.items) <= m.cfg.HistorySize {
+		return
+	}
+	var terminal []*Submission
+	for _, s := range m.items {
+		if s.terminal() {
+			terminal = append(terminal, s)
+		}
+	}
+	sort.Slice(terminal, func(i, j int) bool {
+		return terminal[i].Created < terminal[j].Created
+	})
+	for i := 0; i < len(terminal) && len(m.items) > m.cfg.HistorySize; i++ {
+		delete(m.items, terminal[i].TxHash)
+	}
+}
+
+func (m *SubmissionManager) retryDelay(attempt int) time.Duration {
+	delay := m.cfg.RetryMinDelay
+	for i := 1; i < attempt && delay < m.cfg.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > m.cfg.RetryMaxDelay {
+		delay = m.cfg.RetryMaxDelay
+	}
+	return delay
+}
+
+// attempt runs the next step of the submission, the caller should mark the submission as in progress
+func (m *SubmissionManager) attempt(s *Submission) {
+	m.mutex.Lock()
+	state := s.State
+	m.mutex.Unlock()
+
+	var err error
+	switch state {
+	case SubmissionPublishing:
+		m.fp.mutex.Lock()
+		err = m.fp.publishFlip(s.flip, s.data, s.cid, true)
+		m.fp.mutex.Unlock()
+		if err == nil {
+			state = SubmissionBroadcasting
+			m.log.Info("Flip published", "cid", s.Cid)
+		} else {
+			m.log.Warn("Failed to publish flip", "cid", s.Cid, "err", err)
+		}
+	}
+	if err == nil && state == SubmissionBroadcasting {
+		if err = m.fp.txpool.Add(s.flip.Tx); err == nil || err == mempool.DuplicateTxError {
+			err = nil
+			state = SubmissionPending
+			m.log.Info("Flip tx sent", "hash", s.TxHash.Hex(), "nonce", s.flip.Tx.AccountNonce)
+		} else {
+			m.log.Warn("Failed to send flip tx", "hash", s.TxHash.Hex(), "err", err)
+		}
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	s.inProgress = false
+	if s.terminal() {
+		return
+	}
+	now := time.Now()
+	s.Updated = now.Unix()
+	s.State = state
+	if err == nil {
+		s.LastError = ""
+		s.Attempts = 0
+		s.next = time.Time{}
+		s.NextAttempt = 0
+		return
+	}
+	s.LastError = err.Error()
+	s.Attempts++
+	if m.cfg.MaxAttempts > 0 && s.Attempts >= m.cfg.MaxAttempts {
+		s.State = SubmissionFailed
+		s.NextAttempt = 0
+		m.log.Error("Flip submission failed", "cid", s.Cid, "attempts", s.Attempts, "err", err)
+		return
+	}
+	s.next = now.Add(m.retryDelay(s.Attempts))
+	s.NextAttempt = s.next.Unix()
+}
+
+func (m *SubmissionManager) retry(now time.Time) {
+	epoch := m.fp.appState.State.Epoch()
+	var due []*Submission
+	m.mutex.Lock()
+	for _, s := range m.items {
+		if s.terminal() || s.inProgress {
+			continue
+		}
+		if s.Epoch != epoch {
+			s.State = SubmissionFailed
+			s.LastError = "epoch is over before the flip tx has been mined"
+			s.Updated = now.Unix()
+			continue
+		}
+		if s.State == SubmissionPending {
+			if m.fp.txpool.GetTx(s.TxHash) != nil {
+				continue
+			}
+			// the tx has been evicted or dropped from the mempool
+			s.State = SubmissionBroadcasting
+		}
+		if now.Before(s.next) {
+			continue
+		}
+		s.inProgress = true
+		due = append(due, s)
+	}
+	m.mutex.Unlock()
+
+	for _, s := range due {
+		m.attempt(s)
+	}
+}
+
+func (m *SubmissionManager) processBlock(block *types.Block) {
+	coinbase := m.fp.secStore.GetAddress()
+	for _, tx := range block.Body.Transactions {
+		if tx.Type != types.DeleteFlipTx {
+			continue
+		}
+		if sender, _ := types.Sender(tx); sender != coinbase {
+			continue
+		}
+		if attachment := attachments.ParseDeleteFlipAttachment(tx); attachment != nil {
+			m.fp.forgetFlipImages(attachment.Cid)
+		}
+	}
+
+	var mined []*database.OwnFlipImages
+	m.mutex.Lock()
+	defer func() {
+		m.mutex.Unlock()
+		for _, own := range mined {
+			m.fp.rememberFlipImages(own.Cid, own.Hashes)
+		}
+	}()
+	if len(m.items) == 0 {
+		return
+	}
+	for _, tx := range block.Body.Transactions {
+		s, ok := m.items[tx.Hash()]
+		if !ok || s.State == SubmissionConfirmed {
+			continue
+		}
+		s.State = SubmissionConfirmed
+		s.Height = block.Height()
+		s.LastError = ""
+		s.NextAttempt = 0
+		s.Updated = time.Now().Unix()
+		mined = append(mined, &database.OwnFlipImages{Cid: s.cid.Bytes(), Hashes: s.imageHashes})
+		m.log.Info("Flip tx mined", "cid", s.Cid, "height", s.Height)
+	}
+}
+
+func (m *SubmissionManager) get(hash common.Hash) Submission {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if s, ok := m.items[hash]; ok {
+		return *s
+	}
+	return Submission{}
+}
+
+// Submissions returns tracked flip submissions, the most recent first
+func (m *SubmissionManager) Submissions() []Submission {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	result := make([]Submission, 0, len(m.items))
+	for _, s := range m.items {
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Created > result[j].Created
+	})
+	return result
+}
@@ -0,0 +1,104 @@
+package flip
+
+import (
+	"crypto/ecdsa"
+	"github.com/idena-network/idena-go/blockchain/attachments"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/mempool"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/database"
+	"github.com/idena-network/idena-go/ipfs"
+	"github.com/idena-network/idena-go/secstore"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestSubmissionManager_retryDelay(t *testing.T) {
+	m := NewSubmissionManager(&config.FlipSubmissionConfig{RetryMinDelay: time.Second, RetryMaxDelay: 10 * time.Second}, nil, nil)
+	require.Equal(t, time.Second, m.retryDelay(1))
+	require.Equal(t, 4*time.Second, m.retryDelay(3))
+	require.Equal(t, 10*time.Second, m.retryDelay(10))
+}
+
+func TestSubmissionManager_Stop(t *testing.T) {
+	m := NewSubmissionManager(config.GetDefaultFlipSubmissionConfig(), nil, eventbus.New())
+	start := time.Now()
+	require.True(t, m.Stop(time.Minute))
+	require.True(t, time.Since(start) < time.Second)
+
+	m = NewSubmissionManager(config.GetDefaultFlipSubmissionConfig(), nil, eventbus.New())
+	m.Start()
+	require.True(t, m.Stop(time.Second))
+}
+
+func TestSubmissionManager_processBlock(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	secStore := secstore.NewSecStore()
+	secStore.AddKey(crypto.FromECDSA(key))
+	db := dbm.NewMemDB()
+	fp := NewFlipper(db, nil, mempool.NewKeysPool(db, nil, nil, secStore), nil, secStore, nil, nil, &config.FlipPrefetchConfig{})
+	m := NewSubmissionManager(config.GetDefaultFlipSubmissionConfig(), fp, nil)
+	flipCid, _ := ipfs.NewMemoryIpfsProxy().Cid([]byte{0x1})
+
+	tx := &types.Transaction{AccountNonce: 1, Type: types.SubmitFlipTx}
+	other := &types.Transaction{AccountNonce: 2}
+	m.items[tx.Hash()] = &Submission{TxHash: tx.Hash(), State: SubmissionPending, LastError: "err", cid: flipCid,
+		imageHashes: []uint64{1, 2}}
+	newBlock := func(txs ...*types.Transaction) *types.Block {
+		return &types.Block{
+			Header: &types.Header{ProposedHeader: &types.ProposedHeader{Height: 5}},
+			Body:   &types.Body{Transactions: txs},
+		}
+	}
+
+	require.Empty(t, database.NewRepo(db).ReadOwnFlipImages())
+	m.processBlock(newBlock(other, tx))
+
+	submissions := m.Submissions()
+	require.Len(t, submissions, 1)
+	require.Equal(t, SubmissionConfirmed, submissions[0].State)
+	require.Equal(t, uint64(5), submissions[0].Height)
+	require.Empty(t, submissions[0].LastError)
+	// image hashes are remembered once the flip is mined
+	require.Equal(t, []*database.OwnFlipImages{{Cid: flipCid.Bytes(), Hashes: []uint64{1, 2}}},
+		database.NewRepo(db).ReadOwnFlipImages())
+
+	deleteTx := func(key *ecdsa.PrivateKey) *types.Transaction {
+		tx, err := types.SignTx(&types.Transaction{Type: types.DeleteFlipTx, Amount: big.NewInt(0),
+			Payload: attachments.CreateDeleteFlipAttachment(flipCid.Bytes())}, key)
+		require.NoError(t, err)
+		return tx
+	}
+	otherKey, _ := crypto.GenerateKey()
+	m.processBlock(newBlock(deleteTx(otherKey)))
+	require.Len(t, database.NewRepo(db).ReadOwnFlipImages(), 1)
+	m.processBlock(newBlock(deleteTx(key)))
+	require.Empty(t, database.NewRepo(db).ReadOwnFlipImages())
+}
+
+func TestFlipper_rememberFlipImages(t *testing.T) {
+	db := dbm.NewMemDB()
+	fp := &Flipper{db: db}
+	hashes := make([]uint64, maxOwnImageHashes/2)
+	fp.rememberFlipImages([]byte{0x1}, hashes)
+	fp.rememberFlipImages([]byte{0x2}, hashes)
+	fp.rememberFlipImages([]byte{0x2}, hashes[:4])
+	fp.rememberFlipImages([]byte{0x3}, hashes)
+
+	flips := database.NewRepo(db).ReadOwnFlipImages()
+	require.Len(t, flips, 2)
+	require.Equal(t, []byte{0x2}, flips[0].Cid)
+	require.Len(t, flips[0].Hashes, 4)
+	require.Equal(t, []byte{0x3}, flips[1].Cid)
+
+	fp.forgetFlipImages([]byte{0x4})
+	fp.forgetFlipImages([]byte{0x2})
+	flips = database.NewRepo(db).ReadOwnFlipImages()
+	require.Len(t, flips, 1)
+	require.Equal(t, []byte{0x3}, flips[0].Cid)
+}
@@ -0,0 +1,130 @@
+package hardfork
+
+import (
+	"fmt"
+	"github.com/coreos/go-semver/semver"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/log"
+	"sync"
+)
+
+// supported lists hard forks implemented by this node version, protocol changes are gated by their names
+var supported = map[string]bool{}
+
+type Activation struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Height      uint64 `json:"height,omitempty"`
+	Epoch       uint16 `json:"epoch,omitempty"`
+	MinVersion  string `json:"minVersion,omitempty"`
+	Active      bool   `json:"active"`
+	// BlocksLeft is the number of blocks before the height activation
+	BlocksLeft uint64 `json:"blocksLeft,omitempty"`
+	// EpochsLeft is the number of epochs before the epoch activation
+	EpochsLeft uint16 `json:"epochsLeft,omitempty"`
+	Supported  bool   `json:"supported"`
+	Ready      bool   `json:"ready"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// Rules gates protocol changes by hard fork activations loaded from the chain config
+type Rules struct {
+	cfg        *config.HardForksConfig
+	appVersion string
+	log        log.Logger
+
+	mutex  sync.Mutex
+	warned map[string]bool
+}
+
+func NewRules(cfg *config.HardForksConfig, appVersion string) *Rules {
+	return &Rules{
+		cfg:        cfg,
+		appVersion: appVersion,
+		log:        log.New("component", "hardfork"),
+		warned:     make(map[string]bool),
+	}
+}
+
+// IsActive reports whether the hard fork rules should be applied to the block of the height created in the epoch
+func (r *Rules) IsActive(name string, height uint64, epoch uint16) bool {
+	return r.cfg.IsActive(name, height, epoch)
+}
+
+// Start subscribes to new blocks to warn about coming activations the node isn't ready for
+func (r *Rules) Start(bus eventbus.Bus, epoch func() uint16) {
+	_ = bus.Subscribe(events.AddBlockEventID,
+		func(e eventbus.Event) {
+			r.check(e.(*events.NewBlockEvent).Block, epoch())
+		})
+}
+
+func (r *Rules) check(block *types.Block, epoch uint16) {
+	for _, activation := range r.Activations(block.Height(), epoch) {
+		if activation.Ready {
+			continue
+		}
+		soon := activation.Active || activation.EpochsLeft == 1 || activation.Height > 0 && activation.BlocksLeft <= r.cfg.WarnBlocks
+		if !soon {
+			continue
+		}
+		r.mutex.Lock()
+		warned := r.warned[activation.Name]
+		r.warned[activation.Name] = true
+		r.mutex.Unlock()
+		if !warned {
+			r.log.Error("Node is not ready for the hard fork, update the node", "fork", activation.Name,
+				"active", activation.Active, "blocksLeft", activation.BlocksLeft, "epochsLeft", activation.EpochsLeft, "reason", activation.Reason)
+		}
+	}
+}
+
+// Activations returns configured hard forks with their state at the height and epoch
+func (r *Rules) Activations(height uint64, epoch uint16) []Activation {
+	result := make([]Activation, 0, len(r.cfg.Activations))
+	for _, fork := range r.cfg.Activations {
+		result = append(result, r.activation(fork, height, epoch))
+	}
+	return result
+}
+
+func (r *Rules) activation(fork config.HardFork, height uint64, epoch uint16) Activation {
+	activation := Activation{
+		Name:        fork.Name,
+		Description: fork.Description,
+		Height:      fork.Height,
+		Epoch:       fork.Epoch,
+		MinVersion:  fork.MinVersion,
+		Active:      fork.IsActive(height, epoch),
+		Supported:   supported[fork.Name],
+	}
+	if !activation.Active {
+		if fork.Height > 0 {
+			activation.BlocksLeft = fork.Height - height
+		} else {
+			activation.EpochsLeft = fork.Epoch - epoch
+		}
+	}
+	activation.Ready, activation.Reason = r.ready(fork, activation.Supported)
+	return activation
+}
+
+func (r *Rules) ready(fork config.HardFork, supported bool) (bool, string) {
+	if !supported {
+		return false, "hard fork is not implemented by the node"
+	}
+	if fork.MinVersion == "" {
+		return true, ""
+	}
+	current, err := semver.NewVersion(r.appVersion)
+	if err != nil {
+		return false, fmt.Sprintf("invalid node version %v", r.appVersion)
+	}
+	if current.LessThan(*semver.New(fork.MinVersion)) {
+		return false, fmt.Sprintf("node version %v is lower than required %v", r.appVersion, fork.MinVersion)
+	}
+	return true, ""
+}
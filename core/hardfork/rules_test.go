@@ -0,0 +1,53 @@
+package hardfork
+
+import (
+	"github.com/idena-network/idena-go/config"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRules_Activations(t *testing.T) {
+	supported["byHeight"] = true
+	supported["byEpoch"] = true
+	defer func() {
+		delete(supported, "byHeight")
+		delete(supported, "byEpoch")
+	}()
+	cfg := &config.HardForksConfig{
+		Activations: []config.HardFork{
+			{Name: "byHeight", Height: 100, MinVersion: "0.2.0"},
+			{Name: "byEpoch", Epoch: 5},
+			{Name: "unknown", Height: 50},
+		},
+	}
+	require.NoError(t, cfg.Validate())
+	rules := NewRules(cfg, "0.2.1")
+
+	require.False(t, rules.IsActive("byHeight", 99, 10))
+	require.True(t, rules.IsActive("byHeight", 100, 0))
+	require.False(t, rules.IsActive("byEpoch", 1000, 4))
+	require.True(t, rules.IsActive("byEpoch", 0, 5))
+	require.False(t, rules.IsActive("missing", 1000, 100))
+
+	activations := rules.Activations(60, 3)
+	require.Len(t, activations, 3)
+	require.Equal(t, uint64(40), activations[0].BlocksLeft)
+	require.True(t, activations[0].Ready)
+	require.Equal(t, uint16(2), activations[1].EpochsLeft)
+	require.True(t, activations[1].Ready)
+	require.True(t, activations[2].Active)
+	require.False(t, activations[2].Supported)
+	require.False(t, activations[2].Ready)
+
+	outdated := NewRules(cfg, "0.1.9").Activations(60, 3)
+	require.False(t, outdated[0].Ready)
+	require.NotEmpty(t, outdated[0].Reason)
+}
+
+func TestHardForksConfig_Validate(t *testing.T) {
+	require.Error(t, (&config.HardForksConfig{Activations: []config.HardFork{{Name: "a"}}}).Validate())
+	require.Error(t, (&config.HardForksConfig{Activations: []config.HardFork{{Name: "a", Height: 1, Epoch: 1}}}).Validate())
+	require.Error(t, (&config.HardForksConfig{Activations: []config.HardFork{{Name: "a", Height: 1}, {Name: "a", Epoch: 1}}}).Validate())
+	require.Error(t, (&config.HardForksConfig{Activations: []config.HardFork{{Name: "a", Height: 1, MinVersion: "x"}}}).Validate())
+	require.NoError(t, (&config.HardForksConfig{Activations: []config.HardFork{{Name: "a", Height: 1, MinVersion: "1.0.0"}}}).Validate())
+}
@@ -0,0 +1,262 @@
+package hardfork
+
+import (
+	"github.com/coreos/go-semver/semver"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/log"
+	"sort"
+	"sync"
+	"time"
+)
+
+const peerVersionsInterval = time.Minute
+
+type headerReader interface {
+	GetBlockHeaderByHeight(height uint64) *types.Header
+}
+
+type UpgradeVote struct {
+	Upgrade uint16  `json:"upgrade"`
+	Blocks  uint64  `json:"blocks"`
+	Share   float64 `json:"share"`
+}
+
+type VersionShare struct {
+	Version string  `json:"version"`
+	Peers   int     `json:"peers"`
+	Share   float64 `json:"share"`
+}
+
+// ForkAdoption is the readiness of the network for the hard fork
+type ForkAdoption struct {
+	Name      string  `json:"name"`
+	Upgrade   uint16  `json:"upgrade,omitempty"`
+	Threshold float64 `json:"threshold,omitempty"`
+	// BlockShare is the share of recent blocks signaling the upgrade
+	BlockShare float64 `json:"blockShare"`
+	// PeerShare is the share of observed peers running the version not lower than the min version of the hard fork
+	PeerShare        float64 `json:"peerShare"`
+	ThresholdReached bool    `json:"thresholdReached"`
+	// ReachedAt is the height the threshold has been reached at
+	ReachedAt uint64 `json:"reachedAt,omitempty"`
+}
+
+type UpgradeVotes struct {
+	Height   uint64         `json:"height"`
+	Blocks   uint64         `json:"blocks"`
+	Peers    int            `json:"peers"`
+	Votes    []UpgradeVote  `json:"votes"`
+	Versions []VersionShare `json:"versions"`
+	Forks    []ForkAdoption `json:"forks"`
+}
+
+// voteWindow keeps upgrade votes of the recent blocks indexed by height
+type voteWindow struct {
+	heights  []uint64
+	upgrades []uint16
+}
+
+func newVoteWindow(size uint64) *voteWindow {
+	if size == 0 {
+		size = 1
+	}
+	return &voteWindow{
+		heights:  make([]uint64, size),
+		upgrades: make([]uint16, size),
+	}
+}
+
+func (w *voteWindow) add(height uint64, upgrade uint16) {
+	idx := height % uint64(len(w.heights))
+	w.heights[idx] = height
+	w.upgrades[idx] = upgrade
+}
+
+// count returns the number of blocks of the window ending at the head and the number of blocks per upgrade vote
+func (w *voteWindow) count(head uint64) (uint64, map[uint16]uint64) {
+	size := uint64(len(w.heights))
+	votes := make(map[uint16]uint64)
+	var total uint64
+	for i, height := range w.heights {
+		if height == 0 || height > head || head-height >= size {
+			continue
+		}
+		total++
+		if upgrade := w.upgrades[i]; upgrade > 0 {
+			votes[upgrade]++
+		}
+	}
+	return total, votes
+}
+
+type peerVersion struct {
+	version  string
+	lastSeen time.Time
+}
+
+// VoteTracker aggregates upgrade votes of block proposers and versions of observed peers
+type VoteTracker struct {
+	cfg          *config.HardForksConfig
+	chain        headerReader
+	peerVersions func() map[string]string
+	log          log.Logger
+
+	mutex   sync.Mutex
+	head    uint64
+	blocks  *voteWindow
+	peers   map[string]peerVersion
+	reached map[string]uint64
+}
+
+func NewVoteTracker(cfg *config.HardForksConfig, chain headerReader, peerVersions func() map[string]string) *VoteTracker {
+	return &VoteTracker{
+		cfg:          cfg,
+		chain:        chain,
+		peerVersions: peerVersions,
+		log:          log.New("component", "upgrade-votes"),
+		blocks:       newVoteWindow(cfg.VotesWindow),
+		peers:        make(map[string]peerVersion),
+		reached:      make(map[string]uint64),
+	}
+}
+
+// Start loads votes of the recent blocks up to the head and follows new blocks and peers
+func (t *VoteTracker) Start(bus eventbus.Bus, head uint64) {
+	t.mutex.Lock()
+	t.head = head
+	t.mutex.Unlock()
+	_ = bus.Subscribe(events.AddBlockEventID,
+		func(e eventbus.Event) {
+			t.addHeader(e.(*events.NewBlockEvent).Block.Header)
+		})
+	go func() {
+		t.load(head)
+		ticker := time.NewTicker(peerVersionsInterval)
+		defer ticker.Stop()
+		for {
+			t.observePeers(time.Now())
+			<-ticker.C
+		}
+	}()
+}
+
+func (t *VoteTracker) load(head uint64) {
+	from := uint64(1)
+	if head > t.cfg.VotesWindow {
+		from = head - t.cfg.VotesWindow + 1
+	}
+	for height := from; height <= head; height++ {
+		if header := t.chain.GetBlockHeaderByHeight(height); header != nil {
+			t.mutex.Lock()
+			t.blocks.add(height, header.Upgrade())
+			t.mutex.Unlock()
+		}
+	}
+	t.mutex.Lock()
+	t.updateReached()
+	t.mutex.Unlock()
+}
+
+func (t *VoteTracker) addHeader(header *types.Header) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.blocks.add(header.Height(), header.Upgrade())
+	t.head = header.Height()
+	t.updateReached()
+}
+
+func (t *VoteTracker) observePeers(now time.Time) {
+	versions := t.peerVersions()
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for id, version := range versions {
+		t.peers[id] = peerVersion{version: version, lastSeen: now}
+	}
+	for id, p := range t.peers {
+		if now.Sub(p.lastSeen) > t.cfg.PeerVersionTTL {
+			delete(t.peers, id)
+		}
+	}
+}
+
+func (t *VoteTracker) updateReached() {
+	total, votes := t.blocks.count(t.head)
+	for _, fork := range t.cfg.Activations {
+		if fork.Upgrade == 0 || fork.Threshold == 0 {
+			continue
+		}
+		if _, ok := t.reached[fork.Name]; ok {
+			continue
+		}
+		if share(votes[fork.Upgrade], total) >= fork.Threshold {
+			t.reached[fork.Name] = t.head
+			t.log.Info("Upgrade threshold reached", "fork", fork.Name, "upgrade", fork.Upgrade, "height", t.head)
+		}
+	}
+}
+
+func share(count, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total)
+}
+
+// Votes returns aggregated upgrade votes of the recent blocks and versions of observed peers
+func (t *VoteTracker) Votes() UpgradeVotes {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	total, votes := t.blocks.count(t.head)
+	result := UpgradeVotes{
+		Height: t.head,
+		Blocks: total,
+		Peers:  len(t.peers),
+	}
+	for upgrade, count := range votes {
+		result.Votes = append(result.Votes, UpgradeVote{Upgrade: upgrade, Blocks: count, Share: share(count, total)})
+	}
+	sort.Slice(result.Votes, func(i, j int) bool {
+		return result.Votes[i].Upgrade < result.Votes[j].Upgrade
+	})
+
+	peersByVersion := make(map[string]int)
+	for _, p := range t.peers {
+		peersByVersion[p.version]++
+	}
+	for version, count := range peersByVersion {
+		result.Versions = append(result.Versions, VersionShare{Version: version, Peers: count, Share: share(uint64(count), uint64(len(t.peers)))})
+	}
+	sort.Slice(result.Versions, func(i, j int) bool {
+		return result.Versions[i].Peers > result.Versions[j].Peers
+	})
+
+	for _, fork := range t.cfg.Activations {
+		adoption := ForkAdoption{
+			Name:       fork.Name,
+			Upgrade:    fork.Upgrade,
+			Threshold:  fork.Threshold,
+			BlockShare: share(votes[fork.Upgrade], total),
+			PeerShare:  share(uint64(t.peersReady(fork.MinVersion)), uint64(len(t.peers))),
+		}
+		adoption.ReachedAt, adoption.ThresholdReached = t.reached[fork.Name]
+		result.Forks = append(result.Forks, adoption)
+	}
+	return result
+}
+
+func (t *VoteTracker) peersReady(minVersion string) int {
+	if minVersion == "" {
+		return len(t.peers)
+	}
+	min := semver.New(minVersion)
+	count := 0
+	for _, p := range t.peers {
+		if v, err := semver.NewVersion(p.version); err == nil && !v.LessThan(*min) {
+			count++
+		}
+	}
+	return count
+}
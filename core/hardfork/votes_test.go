@@ -0,0 +1,47 @@
+package hardfork
+
+import (
+	"github.com/idena-network/idena-go/config"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestVoteTracker_Votes(t *testing.T) {
+	cfg := &config.HardForksConfig{
+		Activations: []config.HardFork{
+			{Name: "voted", Height: 1000, Upgrade: 2, Threshold: 0.6, MinVersion: "0.3.0"},
+		},
+		VotesWindow:    10,
+		PeerVersionTTL: time.Hour,
+	}
+	now := time.Now()
+	versions := map[string]string{"a": "0.3.0", "b": "0.2.5", "c": "0.3.1"}
+	tracker := NewVoteTracker(cfg, nil, func() map[string]string { return versions })
+	tracker.observePeers(now)
+
+	for height := uint64(1); height <= 15; height++ {
+		var upgrade uint16
+		if height > 8 {
+			upgrade = 2
+		}
+		tracker.mutex.Lock()
+		tracker.blocks.add(height, upgrade)
+		tracker.head = height
+		tracker.updateReached()
+		tracker.mutex.Unlock()
+	}
+
+	votes := tracker.Votes()
+	require.Equal(t, uint64(10), votes.Blocks)
+	require.Equal(t, []UpgradeVote{{Upgrade: 2, Blocks: 7, Share: 0.7}}, votes.Votes)
+	require.Equal(t, 3, votes.Peers)
+	require.Len(t, votes.Forks, 1)
+	require.True(t, votes.Forks[0].ThresholdReached)
+	require.Equal(t, uint64(14), votes.Forks[0].ReachedAt)
+	require.InDelta(t, 2.0/3, votes.Forks[0].PeerShare, 1e-9)
+
+	versions = map[string]string{"a": "0.3.0"}
+	tracker.observePeers(now.Add(2 * time.Hour))
+	require.Equal(t, 1, tracker.Votes().Peers)
+}
@@ -0,0 +1,203 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/ipfs"
+	"github.com/idena-network/idena-go/secstore"
+	"net/http"
+	"time"
+)
+
+const (
+	SyncCheck      = "sync"
+	PeersCheck     = "peers"
+	BlockAgeCheck  = "blockAge"
+	HeadCheck      = "head"
+	IpfsCheck      = "ipfs"
+	IpfsPeersCheck = "ipfsPeers"
+	CeremonyCheck  = "ceremony"
+)
+
+type Check struct {
+	Name string `json:"name"`
+	Ok   bool   `json:"ok"`
+	// Liveness is set for checks which fail /health, others fail /ready only
+	Liveness bool   `json:"liveness"`
+	Message  string `json:"message,omitempty"`
+}
+
+type Report struct {
+	Healthy      bool    `json:"healthy"`
+	Ready        bool    `json:"ready"`
+	Syncing      bool    `json:"syncing"`
+	Head         uint64  `json:"head"`
+	HighestBlock uint64  `json:"highestBlock"`
+	BlockAge     float64 `json:"blockAge"`
+	Peers        int     `json:"peers"`
+	IpfsPeers    int     `json:"ipfsPeers"`
+	Period       string  `json:"period"`
+	Checks       []Check `json:"checks"`
+}
+
+type syncer interface {
+	IsSyncing() bool
+	SyncProgress() (head uint64, top uint64)
+}
+
+type conditions struct {
+	Syncing         bool
+	Head            uint64
+	Highest         uint64
+	BlockAge        time.Duration
+	Peers           int
+	IpfsRunning     bool
+	IpfsPeers       int
+	Period          state.ValidationPeriod
+	Candidate       bool
+	UntilValidation time.Duration
+}
+
+// Checker reports the node state for load balancers and orchestrators
+type Checker struct {
+	cfg      *config.HealthConfig
+	chain    *blockchain.Blockchain
+	appState *appstate.AppState
+	secStore *secstore.SecStore
+	ipfs     ipfs.Proxy
+	syncer   syncer
+	peers    func() int
+}
+
+func NewChecker(cfg *config.HealthConfig, chain *blockchain.Blockchain, appState *appstate.AppState,
+	secStore *secstore.SecStore, ipfsProxy ipfs.Proxy, syncer syncer, peers func() int) *Checker {
+	return &Checker{
+		cfg:      cfg,
+		chain:    chain,
+		appState: appState,
+		secStore: secStore,
+		ipfs:     ipfsProxy,
+		syncer:   syncer,
+		peers:    peers,
+	}
+}
+
+func (c *Checker) collect(now time.Time) conditions {
+	s := c.appState.State
+	cond := conditions{
+		Syncing:         c.syncer.IsSyncing(),
+		Peers:           c.peers(),
+		Period:          s.ValidationPeriod(),
+		Candidate:       state.IsCeremonyCandidate(s.GetIdentity(c.secStore.GetAddress())),
+		UntilValidation: s.NextValidationTime().Sub(now),
+	}
+	if head := c.chain.Head; head != nil {
+		cond.Head = head.Height()
+		cond.BlockAge = now.Sub(time.Unix(head.Time().Int64(), 0))
+	}
+	cond.Highest = cond.Head
+	if _, top := c.syncer.SyncProgress(); top > cond.Head {
+		cond.Highest = top
+	}
+	if host := c.ipfs.Host(); host != nil {
+		cond.IpfsRunning = true
+		cond.IpfsPeers = len(host.Network().Peers())
+	}
+	return cond
+}
+
+// Report evaluates checks against the current node state
+func (c *Checker) Report() *Report {
+	return evaluate(c.collect(time.Now()), c.cfg)
+}
+
+func evaluate(c conditions, cfg *config.HealthConfig) *Report {
+	report := &Report{
+		Syncing:      c.Syncing,
+		Head:         c.Head,
+		HighestBlock: c.Highest,
+		BlockAge:     c.BlockAge.Seconds(),
+		Peers:        c.Peers,
+		IpfsPeers:    c.IpfsPeers,
+		Period:       periodName(c.Period),
+	}
+	add := func(name string, ok bool, liveness bool, message string) {
+		check := Check{Name: name, Ok: ok, Liveness: liveness}
+		if !ok {
+			check.Message = message
+		}
+		report.Checks = append(report.Checks, check)
+	}
+	blockAge := c.BlockAge.Round(time.Second)
+	fresh := cfg.MaxBlockAge <= 0 || c.BlockAge <= cfg.MaxBlockAge
+	connected := c.Peers >= cfg.MinPeers
+
+	add(SyncCheck, !c.Syncing, false, fmt.Sprintf("syncing %v of %v", c.Head, c.Highest))
+	add(PeersCheck, connected, false, fmt.Sprintf("%v peers, %v required", c.Peers, cfg.MinPeers))
+	add(BlockAgeCheck, fresh, false, fmt.Sprintf("last block is %v old", blockAge))
+	// the syncing node makes progress even if its head is old
+	stale := cfg.StaleBlockAge > 0 && c.BlockAge > cfg.StaleBlockAge && !c.Syncing
+	add(HeadCheck, !stale, true, fmt.Sprintf("head hasn't changed for %v", blockAge))
+	add(IpfsCheck, c.IpfsRunning, true, "ipfs isn't running")
+	add(IpfsPeersCheck, c.IpfsPeers >= cfg.MinIpfsPeers, false, fmt.Sprintf("%v ipfs peers, %v required", c.IpfsPeers, cfg.MinIpfsPeers))
+
+	ceremonySoon := c.Period != state.NonePeriod || c.UntilValidation <= cfg.CeremonyLeadTime
+	ceremonyReady := !c.Candidate || !ceremonySoon || !c.Syncing && fresh && connected
+	add(CeremonyCheck, ceremonyReady, false, "candidate isn't synced or connected for the validation")
+
+	report.Healthy, report.Ready = true, true
+	for _, check := range report.Checks {
+		if check.Ok {
+			continue
+		}
+		report.Ready = false
+		if check.Liveness {
+			report.Healthy = false
+		}
+	}
+	return report
+}
+
+func periodName(period state.ValidationPeriod) string {
+	switch period {
+	case state.NonePeriod:
+		return "None"
+	case state.FlipLotteryPeriod:
+		return "FlipLottery"
+	case state.ShortSessionPeriod:
+		return "ShortSession"
+	case state.LongSessionPeriod:
+		return "LongSession"
+	case state.AfterLongSessionPeriod:
+		return "AfterLongSession"
+	default:
+		return "Unknown"
+	}
+}
+
+// HealthHandler responds 200 unless liveness checks fail
+func (c *Checker) HealthHandler() http.Handler {
+	return c.handler(func(report *Report) bool { return report.Healthy })
+}
+
+// ReadyHandler responds 200 if all checks pass
+func (c *Checker) ReadyHandler() http.Handler {
+	return c.handler(func(report *Report) bool { return report.Ready })
+}
+
+func (c *Checker) handler(ok func(report *Report) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := c.Report()
+		w.Header().Set("content-type", "application/json")
+		if ok(report) {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
@@ -0,0 +1,81 @@
+package health
+
+import (
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func failed(report *Report) []string {
+	var result []string
+	for _, check := range report.Checks {
+		if !check.Ok {
+			result = append(result, check.Name)
+		}
+	}
+	return result
+}
+
+func TestEvaluate(t *testing.T) {
+	cfg := config.GetDefaultHealthConfig()
+	require := require.New(t)
+
+	ok := conditions{
+		Head:            100,
+		Highest:         100,
+		BlockAge:        20 * time.Second,
+		Peers:           5,
+		IpfsRunning:     true,
+		IpfsPeers:       10,
+		Candidate:       true,
+		UntilValidation: 24 * time.Hour,
+	}
+	report := evaluate(ok, cfg)
+	require.True(report.Healthy)
+	require.True(report.Ready)
+	require.Empty(failed(report))
+	require.Equal("None", report.Period)
+
+	c := ok
+	c.Syncing = true
+	c.Highest = 200
+	c.BlockAge = time.Hour
+	report = evaluate(c, cfg)
+	require.True(report.Healthy)
+	require.False(report.Ready)
+	require.Equal([]string{SyncCheck, BlockAgeCheck}, failed(report))
+
+	c.Syncing = false
+	report = evaluate(c, cfg)
+	require.False(report.Healthy)
+	require.Equal([]string{BlockAgeCheck, HeadCheck}, failed(report))
+
+	c = ok
+	c.Peers = 0
+	c.IpfsRunning = false
+	c.IpfsPeers = 0
+	report = evaluate(c, cfg)
+	require.False(report.Healthy)
+	require.Equal([]string{PeersCheck, IpfsCheck, IpfsPeersCheck}, failed(report))
+
+	c = ok
+	c.Peers = 0
+	c.UntilValidation = 10 * time.Minute
+	require.Equal([]string{PeersCheck, CeremonyCheck}, failed(evaluate(c, cfg)))
+	c.Candidate = false
+	require.Equal([]string{PeersCheck}, failed(evaluate(c, cfg)))
+
+	c = ok
+	c.Period = state.LongSessionPeriod
+	c.Syncing = true
+	report = evaluate(c, cfg)
+	require.Equal("LongSession", report.Period)
+	require.Equal([]string{SyncCheck, CeremonyCheck}, failed(report))
+
+	zero := &config.HealthConfig{}
+	c = ok
+	c.Peers, c.IpfsPeers, c.BlockAge = 0, 0, 24*time.Hour
+	require.True(evaluate(c, zero).Ready)
+}
@@ -0,0 +1,262 @@
+package identity
+
+import (
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/log"
+	"github.com/shopspring/decimal"
+	"math/big"
+	"sync"
+)
+
+const (
+	StateChanged = "stateChanged"
+	Killed       = "killed"
+	Penalized    = "penalized"
+)
+
+var stateNames = map[state.IdentityState]string{
+	state.Undefined: "Undefined",
+	state.Invite:    "Invite",
+	state.Candidate: "Candidate",
+	state.Verified:  "Verified",
+	state.Suspended: "Suspended",
+	state.Killed:    "Killed",
+	state.Zombie:    "Zombie",
+	state.Newbie:    "Newbie",
+	state.Human:     "Human",
+}
+
+func StateName(s state.IdentityState) string {
+	if name, ok := stateNames[s]; ok {
+		return name
+	}
+	return stateNames[state.Undefined]
+}
+
+// Change is the public representation of identity change which is sent to webhooks and RPC subscribers
+type Change struct {
+	Seq       uint64           `json:"seq"`
+	Kind      string           `json:"kind"`
+	Address   common.Address   `json:"address"`
+	Height    uint64           `json:"height"`
+	Epoch     uint16           `json:"epoch"`
+	PrevState string           `json:"prevState"`
+	State     string           `json:"state"`
+	Penalty   *decimal.Decimal `json:"penalty,omitempty"`
+}
+
+func NewChange(e *events.IdentityChangedEvent) *Change {
+	change := &Change{
+		Seq:       e.Seq,
+		Kind:      StateChanged,
+		Address:   e.Address,
+		Height:    e.Height,
+		Epoch:     e.Epoch,
+		PrevState: StateName(state.IdentityState(e.PrevState)),
+		State:     StateName(state.IdentityState(e.State)),
+	}
+	if state.IdentityState(e.State) == state.Killed {
+		change.Kind = Killed
+	}
+	if e.Penalty != nil {
+		change.Kind = Penalized
+		penalty := blockchain.ConvertToFloat(e.Penalty)
+		change.Penalty = &penalty
+	}
+	return change
+}
+
+type snapshot struct {
+	state   state.IdentityState
+	penalty *big.Int
+}
+
+// Watcher detects identity state transitions and penalties block by block, publishes them to the bus
+// and delivers to configured webhooks
+type Watcher struct {
+	cfg      *config.IdentityEventsConfig
+	appState *appstate.AppState
+	bus      eventbus.Bus
+	webhooks []*webhook
+	log      log.Logger
+
+	mutex      sync.Mutex
+	identities map[common.Address]snapshot
+	watched    map[common.Address]struct{}
+	seq        uint64
+	recent     []*events.IdentityChangedEvent
+}
+
+func NewWatcher(cfg *config.IdentityEventsConfig, appState *appstate.AppState, bus eventbus.Bus) *Watcher {
+	w := &Watcher{
+		cfg:        cfg,
+		appState:   appState,
+		bus:        bus,
+		log:        log.New("component", "identity-watcher"),
+		identities: make(map[common.Address]snapshot),
+	}
+	if len(cfg.Addresses) > 0 {
+		w.watched = make(map[common.Address]struct{})
+		for _, addr := range cfg.Addresses {
+			w.watched[addr] = struct{}{}
+		}
+	}
+	for _, url := range cfg.Webhooks {
+		w.webhooks = append(w.webhooks, newWebhook(url, cfg, w.log))
+	}
+	return w
+}
+
+func (w *Watcher) Start() {
+	w.loadIdentities()
+	for _, hook := range w.webhooks {
+		go hook.loop()
+	}
+	// block event is published synchronously after the block is committed, so the head state is the state of the block
+	_ = w.bus.Subscribe(events.AddBlockEventID,
+		func(e eventbus.Event) {
+			w.processBlock(e.(*events.NewBlockEvent).Block)
+		})
+	_ = w.bus.Subscribe(events.FastSyncCompleted,
+		func(e eventbus.Event) {
+			w.loadIdentities()
+		})
+}
+
+func (w *Watcher) isWatched(addr common.Address) bool {
+	if w.watched == nil {
+		return true
+	}
+	_, ok := w.watched[addr]
+	return ok
+}
+
+func (w *Watcher) loadIdentities() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.identities = make(map[common.Address]snapshot)
+	w.appState.State.IterateOverIdentities(func(addr common.Address, identity state.Identity) {
+		if w.isWatched(addr) {
+			w.identities[addr] = snapshot{identity.State, identity.Penalty}
+		}
+	})
+}
+
+// touchedAddresses returns identities which state can be changed by a regular block
+func touchedAddresses(block *types.Block) []common.Address {
+	result := []common.Address{block.Header.Coinbase()}
+	if block.Header.Flags().HasFlag(types.OfflineCommit) {
+		if addr := block.Header.OfflineAddr(); addr != nil {
+			result = append(result, *addr)
+		}
+	}
+	if block.Body == nil {
+		return result
+	}
+	for _, tx := range block.Body.Transactions {
+		sender, _ := types.Sender(tx)
+		result = append(result, sender)
+		if tx.To != nil {
+			result = append(result, *tx.To)
+		}
+	}
+	return result
+}
+
+func (w *Watcher) processBlock(block *types.Block) {
+	for _, e := range w.detectChanges(block) {
+		w.bus.Publish(e)
+		change := NewChange(e)
+		for _, hook := range w.webhooks {
+			hook.enqueue(change)
+		}
+	}
+}
+
+func (w *Watcher) detectChanges(block *types.Block) []*events.IdentityChangedEvent {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	height, epoch := block.Height(), w.appState.State.Epoch()
+	var changed []*events.IdentityChangedEvent
+	check := func(addr common.Address, current snapshot) {
+		if !w.isWatched(addr) {
+			return
+		}
+		prev, ok := w.identities[addr]
+		if current.state == state.Undefined {
+			delete(w.identities, addr)
+			return
+		}
+		w.identities[addr] = current
+		if !ok {
+			prev.state = state.Undefined
+		}
+		if e := detectChange(addr, prev, current); e != nil {
+			e.Height, e.Epoch = height, epoch
+			changed = append(changed, e)
+		}
+	}
+	if block.Header.Flags().HasFlag(types.ValidationFinished) {
+		seen := make(map[common.Address]struct{}, len(w.identities))
+		w.appState.State.IterateOverIdentities(func(addr common.Address, identity state.Identity) {
+			seen[addr] = struct{}{}
+			check(addr, snapshot{identity.State, identity.Penalty})
+		})
+		for addr := range w.identities {
+			if _, ok := seen[addr]; !ok {
+				delete(w.identities, addr)
+			}
+		}
+	} else {
+		for _, addr := range touchedAddresses(block) {
+			check(addr, snapshot{w.appState.State.GetIdentityState(addr), w.appState.State.GetPenalty(addr)})
+		}
+	}
+	for _, e := range changed {
+		w.seq++
+		e.Seq = w.seq
+		w.recent = append(w.recent, e)
+		if len(w.recent) > w.cfg.RecentSize {
+			w.recent = w.recent[len(w.recent)-w.cfg.RecentSize:]
+		}
+	}
+	return changed
+}
+
+// detectChange returns an event if identity state has changed or a penalty has been set or increased
+func detectChange(addr common.Address, prev, current snapshot) *events.IdentityChangedEvent {
+	penalized := current.penalty != nil && current.penalty.Sign() > 0 &&
+		(prev.penalty == nil || current.penalty.Cmp(prev.penalty) > 0)
+	if prev.state == current.state && !penalized {
+		return nil
+	}
+	e := &events.IdentityChangedEvent{
+		Address:   addr,
+		PrevState: uint8(prev.state),
+		State:     uint8(current.state),
+	}
+	if penalized {
+		e.Penalty = new(big.Int).Set(current.penalty)
+	}
+	return e
+}
+
+// Recent returns buffered changes with seq greater than fromSeq
+func (w *Watcher) Recent(fromSeq uint64) []*Change {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	var result []*Change
+	for _, e := range w.recent {
+		if e.Seq > fromSeq {
+			result = append(result, NewChange(e))
+		}
+	}
+	return result
+}
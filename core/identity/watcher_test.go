@@ -0,0 +1,33 @@
+package identity
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/events"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestDetectChange(t *testing.T) {
+	require := require.New(t)
+	addr := common.Address{0x1}
+
+	require.Nil(detectChange(addr, snapshot{state: state.Verified}, snapshot{state: state.Verified}))
+	require.Nil(detectChange(addr, snapshot{state: state.Verified, penalty: big.NewInt(10)},
+		snapshot{state: state.Verified, penalty: big.NewInt(5)}))
+
+	e := detectChange(addr, snapshot{state: state.Candidate}, snapshot{state: state.Newbie})
+	require.Equal(uint8(state.Candidate), e.PrevState)
+	require.Equal(uint8(state.Newbie), e.State)
+	require.Nil(e.Penalty)
+	require.Equal(StateChanged, NewChange(e).Kind)
+
+	e = detectChange(addr, snapshot{state: state.Verified}, snapshot{state: state.Verified, penalty: big.NewInt(10)})
+	require.Equal(big.NewInt(10), e.Penalty)
+	change := NewChange(e)
+	require.Equal(Penalized, change.Kind)
+	require.Equal("Verified", change.State)
+
+	require.Equal(Killed, NewChange(&events.IdentityChangedEvent{PrevState: uint8(state.Verified), State: uint8(state.Killed)}).Kind)
+}
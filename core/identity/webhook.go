@@ -0,0 +1,100 @@
+package identity
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/log"
+	"github.com/pkg/errors"
+	"net/http"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Idena-Signature"
+	maxRetryDelay   = 5 * time.Minute
+)
+
+// webhook delivers changes to the URL one by one keeping their order, failed deliveries are retried with exponential backoff
+type webhook struct {
+	url        string
+	secret     []byte
+	client     *http.Client
+	maxRetries int
+	retryDelay time.Duration
+	queue      chan *Change
+	log        log.Logger
+}
+
+func newWebhook(url string, cfg *config.IdentityEventsConfig, logger log.Logger) *webhook {
+	hook := &webhook{
+		url:        url,
+		client:     &http.Client{Timeout: cfg.Timeout},
+		maxRetries: cfg.MaxRetries,
+		retryDelay: cfg.RetryDelay,
+		queue:      make(chan *Change, cfg.QueueSize),
+		log:        logger,
+	}
+	if cfg.WebhookSecret != "" {
+		hook.secret = []byte(cfg.WebhookSecret)
+	}
+	return hook
+}
+
+func (h *webhook) enqueue(change *Change) {
+	select {
+	case h.queue <- change:
+	default:
+		h.log.Warn("Identity webhook queue is full, event is dropped", "url", h.url, "seq", change.Seq)
+	}
+}
+
+func (h *webhook) loop() {
+	for change := range h.queue {
+		if err := h.deliver(change); err != nil {
+			h.log.Warn("Failed to deliver identity event", "url", h.url, "seq", change.Seq, "err", err)
+		}
+	}
+}
+
+func (h *webhook) deliver(change *Change) error {
+	body, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	delay := h.retryDelay
+	for attempt := 0; ; attempt++ {
+		if err = h.post(body); err == nil || attempt >= h.maxRetries {
+			return err
+		}
+		time.Sleep(delay)
+		if delay *= 2; delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+func (h *webhook) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.secret != nil {
+		mac := hmac.New(sha256.New, h.secret)
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("unexpected status %v", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package identity
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/log"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhook_deliver(t *testing.T) {
+	require := require.New(t)
+	attempts := 0
+	var received *Change
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write(body)
+		require.Equal(hex.EncodeToString(mac.Sum(nil)), r.Header.Get(SignatureHeader))
+		received = new(Change)
+		require.NoError(json.Unmarshal(body, received))
+	}))
+	defer server.Close()
+
+	cfg := config.GetDefaultIdentityEventsConfig()
+	cfg.WebhookSecret = "secret"
+	cfg.RetryDelay = time.Millisecond
+	cfg.MaxRetries = 2
+	hook := newWebhook(server.URL, cfg, log.New())
+
+	require.NoError(hook.deliver(&Change{Seq: 1, Kind: Killed, State: "Killed"}))
+	require.Equal(3, attempts)
+	require.Equal(uint64(1), received.Seq)
+	require.Equal(Killed, received.Kind)
+
+	attempts = -10
+	require.Error(hook.deliver(&Change{Seq: 2}))
+	require.Equal(-7, attempts)
+}
@@ -0,0 +1,204 @@
+// Package identityproof stores Merkle proofs of identities when the epoch is sealed, proofs of the epoch are built
+// against the state root of its last block, so anyone having the block header verifies identity states without
+// trusting the node which served the proof.
+package identityproof
+
+import (
+	"bytes"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/identity"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/database"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/log"
+	"github.com/idena-network/idena-go/rlp"
+	"github.com/pkg/errors"
+	"github.com/tendermint/iavl"
+	"github.com/tendermint/tendermint/crypto/merkle"
+	dbm "github.com/tendermint/tm-db"
+	"sync"
+)
+
+// Proof proves the identity state at the last block of the epoch, Root must be compared with the state root of the
+// trusted header at Height
+type Proof struct {
+	Epoch     uint16         `json:"epoch"`
+	Address   common.Address `json:"address"`
+	Height    uint64         `json:"height"`
+	BlockHash common.Hash    `json:"blockHash"`
+	Root      common.Hash    `json:"root"`
+	State     string         `json:"state"`
+	Key       hexutil.Bytes  `json:"key"`
+	// Value is the RLP encoded state.Identity
+	Value hexutil.Bytes `json:"value"`
+	// Proof is the amino encoded iavl value proof, it is decoded by iavl.ValueOpDecoder
+	Proof hexutil.Bytes `json:"proof"`
+}
+
+// Verify checks the proof of the identity value against the proof root and the state of the decoded value
+func Verify(proof *Proof) error {
+	if !bytes.Equal(proof.Key, state.IdentityKey(proof.Address)) {
+		return errors.New("key doesn't match the address")
+	}
+	op, err := iavl.ValueOpDecoder(merkle.ProofOp{Type: iavl.ProofOpIAVLValue, Key: proof.Key, Data: proof.Proof})
+	if err != nil {
+		return err
+	}
+	roots, err := op.Run([][]byte{proof.Value})
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(roots[0], proof.Root.Bytes()) {
+		return errors.New("proof doesn't match the root")
+	}
+	var data state.Identity
+	if err := rlp.DecodeBytes(proof.Value, &data); err != nil {
+		return errors.Wrap(err, "invalid identity value")
+	}
+	if identity.StateName(data.State) != proof.State {
+		return errors.New("state doesn't match the value")
+	}
+	return nil
+}
+
+type Store struct {
+	cfg      *config.IdentityProofConfig
+	repo     *database.Repo
+	appState *appstate.AppState
+	bus      eventbus.Bus
+	log      log.Logger
+
+	mutex   sync.Mutex
+	sealing map[uint16]bool
+}
+
+func NewStore(cfg *config.IdentityProofConfig, db dbm.DB, appState *appstate.AppState, bus eventbus.Bus) *Store {
+	return &Store{
+		cfg:      cfg,
+		repo:     database.NewRepo(db),
+		appState: appState,
+		bus:      bus,
+		log:      log.New("component", "identityproof"),
+		sealing:  make(map[uint16]bool),
+	}
+}
+
+func (s *Store) Start() {
+	if !s.cfg.Enabled {
+		return
+	}
+	_ = s.bus.Subscribe(events.AddBlockEventID,
+		func(e eventbus.Event) {
+			if e.(*events.NewBlockEvent).Block.Header.Flags().HasFlag(types.ValidationFinished) {
+				go s.sealPrevious()
+			}
+		})
+	// proofs are missing if the node was stopped while they were built
+	go s.sealPrevious()
+}
+
+// sealPrevious builds proofs of the previous epoch, the state of its last block is kept only for the recent blocks
+func (s *Store) sealPrevious() {
+	epoch, epochBlock := s.appState.State.Epoch(), s.appState.State.EpochBlock()
+	if epoch == 0 || epochBlock == 0 {
+		return
+	}
+	if err := s.seal(epoch-1, epochBlock-1); err != nil {
+		s.log.Warn("Identity proofs are not built", "epoch", epoch-1, "err", err)
+	}
+}
+
+func (s *Store) seal(epoch uint16, height uint64) error {
+	s.mutex.Lock()
+	if s.sealing[epoch] || s.repo.ReadIdentityProofEpoch(epoch) != nil {
+		s.mutex.Unlock()
+		return nil
+	}
+	s.sealing[epoch] = true
+	s.mutex.Unlock()
+	defer func() {
+		s.mutex.Lock()
+		delete(s.sealing, epoch)
+		s.mutex.Unlock()
+	}()
+
+	header := s.repo.ReadBlockHeader(s.repo.ReadCanonicalHash(height))
+	if header == nil {
+		return errors.Errorf("header at %v is not found", height)
+	}
+	st, err := s.appState.State.Readonly(int64(height))
+	if err != nil {
+		return errors.Wrapf(err, "state at %v is not available", height)
+	}
+	if st.Root() != header.Root() {
+		return errors.Errorf("state root at %v doesn't match the header", height)
+	}
+	proofs := make(map[common.Address]*database.IdentityProof)
+	st.IterateIdentities(func(key []byte, _ []byte) bool {
+		addr := common.BytesToAddress(key[len(key)-common.AddressLength:])
+		var value []byte
+		var proof *iavl.RangeProof
+		value, proof, err = st.IdentityWithProof(addr)
+		if err != nil {
+			return true
+		}
+		proofs[addr] = &database.IdentityProof{
+			Value: value,
+			Proof: iavl.NewValueOp(state.IdentityKey(addr), proof).ProofOp().Data,
+		}
+		return false
+	})
+	if err != nil {
+		return err
+	}
+	s.repo.WriteIdentityProofs(&database.IdentityProofEpoch{
+		Epoch:      epoch,
+		Height:     height,
+		BlockHash:  header.Hash(),
+		Root:       header.Root(),
+		Identities: uint32(len(proofs)),
+	}, proofs)
+	if s.cfg.KeepEpochs > 0 && epoch >= s.cfg.KeepEpochs {
+		s.repo.DeleteIdentityProofsBefore(epoch + 1 - s.cfg.KeepEpochs)
+	}
+	s.log.Info("Identity proofs are built", "epoch", epoch, "height", height, "identities", len(proofs))
+	return nil
+}
+
+// Proof returns the proof of the identity at the end of the sealed epoch
+func (s *Store) Proof(addr common.Address, epoch uint16) (*Proof, error) {
+	if !s.cfg.Enabled {
+		return nil, errors.New("identity proofs are disabled")
+	}
+	sealed := s.repo.ReadIdentityProofEpoch(epoch)
+	if sealed == nil {
+		if epoch >= s.appState.State.Epoch() {
+			return nil, errors.Errorf("epoch %v is not sealed yet", epoch)
+		}
+		return nil, errors.Errorf("proofs of epoch %v are not available", epoch)
+	}
+	stored := s.repo.ReadIdentityProof(epoch, addr)
+	if stored == nil {
+		return nil, errors.Errorf("identity %v is not found at epoch %v", addr.Hex(), epoch)
+	}
+	var data state.Identity
+	if err := rlp.DecodeBytes(stored.Value, &data); err != nil {
+		return nil, errors.Wrap(err, "invalid stored identity")
+	}
+	return &Proof{
+		Epoch:     epoch,
+		Address:   addr,
+		Height:    sealed.Height,
+		BlockHash: sealed.BlockHash,
+		Root:      sealed.Root,
+		State:     identity.StateName(data.State),
+		Key:       state.IdentityKey(addr),
+		Value:     stored.Value,
+		Proof:     stored.Proof,
+	}, nil
+}
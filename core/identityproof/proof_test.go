@@ -0,0 +1,102 @@
+package identityproof
+
+import (
+	"fmt"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/stretchr/testify/require"
+	db "github.com/tendermint/tm-db"
+	"testing"
+)
+
+func TestStore_Proof(t *testing.T) {
+	memdb := db.NewMemDB()
+	appState := appstate.NewAppState(memdb, eventbus.New())
+	require.NoError(t, appState.Initialize(0))
+	store := NewStore(config.GetDefaultIdentityProofConfig(), memdb, appState, eventbus.New())
+
+	human, newbie, unknown := common.Address{0x1}, common.Address{0x2}, common.Address{0x3}
+	appState.State.SetState(human, state.Human)
+	appState.State.SetBirthday(human, 3)
+	appState.State.SetState(newbie, state.Newbie)
+	require.NoError(t, appState.Commit(nil))
+	header := &types.Header{ProposedHeader: &types.ProposedHeader{Height: 1, Root: appState.State.Root()}}
+	store.repo.WriteBlockHeader(header)
+	store.repo.WriteCanonicalHash(1, header.Hash())
+
+	_, err := store.Proof(human, 0)
+	require.Error(t, err)
+
+	// the state of the sealed epoch changes after the validation
+	appState.State.SetState(newbie, state.Verified)
+	appState.State.IncEpoch()
+	require.NoError(t, appState.Commit(nil))
+
+	require.NoError(t, store.seal(0, 1))
+	require.NoError(t, store.seal(0, 1))
+
+	proof, err := store.Proof(newbie, 0)
+	require.NoError(t, err)
+	require.Equal(t, "Newbie", proof.State)
+	require.Equal(t, header.Hash(), proof.BlockHash)
+	require.Equal(t, header.Root(), proof.Root)
+	require.NoError(t, Verify(proof))
+
+	proof, err = store.Proof(human, 0)
+	require.NoError(t, err)
+	require.Equal(t, "Human", proof.State)
+	require.NoError(t, Verify(proof))
+
+	forged := *proof
+	forged.State = "Verified"
+	require.Error(t, Verify(&forged))
+
+	forged = *proof
+	forged.Address = newbie
+	forged.Key = state.IdentityKey(newbie)
+	require.Error(t, Verify(&forged))
+
+	forged = *proof
+	forged.Root = common.Hash{0x1}
+	require.Error(t, Verify(&forged))
+
+	_, err = store.Proof(unknown, 0)
+	require.Error(t, err)
+	_, err = store.Proof(human, 1)
+	require.EqualError(t, err, "epoch 1 is not sealed yet")
+}
+
+func TestStore_KeepEpochs(t *testing.T) {
+	memdb := db.NewMemDB()
+	appState := appstate.NewAppState(memdb, eventbus.New())
+	require.NoError(t, appState.Initialize(0))
+	cfg := config.GetDefaultIdentityProofConfig()
+	cfg.KeepEpochs = 2
+	store := NewStore(cfg, memdb, appState, eventbus.New())
+
+	addr := common.Address{0x1}
+	for epoch := uint16(0); epoch < 4; epoch++ {
+		appState.State.SetState(addr, state.Verified)
+		appState.State.SetBirthday(addr, epoch)
+		require.NoError(t, appState.Commit(nil))
+		height := uint64(epoch) + 1
+		header := &types.Header{ProposedHeader: &types.ProposedHeader{Height: height, Root: appState.State.Root()}}
+		store.repo.WriteBlockHeader(header)
+		store.repo.WriteCanonicalHash(height, header.Hash())
+		require.NoError(t, store.seal(epoch, height))
+		appState.State.IncEpoch()
+	}
+	for epoch := uint16(0); epoch < 2; epoch++ {
+		_, err := store.Proof(addr, epoch)
+		require.EqualError(t, err, fmt.Sprintf("proofs of epoch %v are not available", epoch))
+	}
+	for epoch := uint16(2); epoch < 4; epoch++ {
+		proof, err := store.Proof(addr, epoch)
+		require.NoError(t, err)
+		require.NoError(t, Verify(proof))
+	}
+}
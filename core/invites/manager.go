@@ -0,0 +1,289 @@
+package invites
+
+import (
+	"crypto/rand"
+	"fmt"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/crypto/ecies"
+	"github.com/idena-network/idena-go/database"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/log"
+	"github.com/idena-network/idena-go/secstore"
+	"github.com/pkg/errors"
+	dbm "github.com/tendermint/tm-db"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// StatusPending means the invite tx hasn't been mined yet
+	StatusPending = "pending"
+	// StatusIssued means the invite is waiting to be activated
+	StatusIssued = "issued"
+	// StatusActivated means the invitee is a candidate waiting for the validation
+	StatusActivated = "activated"
+	// StatusValidated means the invitee has passed the validation
+	StatusValidated = "validated"
+	StatusRevoked   = "revoked"
+	// StatusExpired means the invite hasn't been activated or the invitee hasn't been validated before the epoch end
+	StatusExpired = "expired"
+)
+
+type Invite struct {
+	Receiver common.Address
+	TxHash   common.Hash
+	Epoch    uint16
+	IssuedAt int64
+	Status   string
+	// ExpiresAt is the time the invite is burned if it isn't activated or the invitee isn't validated, 0 - not applicable
+	ExpiresAt int64
+	// Key is the invite private key, it is set for invites issued with generated keys
+	Key      []byte
+	RevokeTx common.Hash
+}
+
+type Summary struct {
+	Invites []Invite
+	// Unused is the number of invites the identity can still issue in the current epoch
+	Unused uint8
+	// Warning is set if unused or not activated invites are going to be burned soon
+	Warning string
+}
+
+// Manager tracks invites issued by the node identity and warns before unused invites are burned at the epoch end
+type Manager struct {
+	cfg      *config.InvitesConfig
+	repo     *database.Repo
+	appState *appstate.AppState
+	secStore *secstore.SecStore
+	bus      eventbus.Bus
+	log      log.Logger
+
+	mutex       sync.Mutex
+	warnedEpoch uint16
+	warned      bool
+}
+
+func NewManager(cfg *config.InvitesConfig, db dbm.DB, appState *appstate.AppState, secStore *secstore.SecStore, bus eventbus.Bus) *Manager {
+	return &Manager{
+		cfg:      cfg,
+		repo:     database.NewRepo(db),
+		appState: appState,
+		secStore: secStore,
+		bus:      bus,
+		log:      log.New("component", "invites"),
+	}
+}
+
+func (m *Manager) Start() {
+	_ = m.bus.Subscribe(events.AddBlockEventID,
+		func(e eventbus.Event) {
+			m.processBlock(e.(*events.NewBlockEvent).Block)
+		})
+	go m.loop()
+}
+
+func (m *Manager) loop() {
+	ticker := time.NewTicker(m.cfg.CheckInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		m.check()
+	}
+}
+
+// Add records the invite sent by the node identity, the key is stored encrypted with the node key
+func (m *Manager) Add(receiver common.Address, txHash common.Hash, key []byte) error {
+	invite := &database.IssuedInvite{
+		Receiver: receiver,
+		TxHash:   txHash,
+		Epoch:    m.appState.State.Epoch(),
+		IssuedAt: uint64(time.Now().Unix()),
+	}
+	if len(key) > 0 {
+		pubKey, err := crypto.UnmarshalPubkey(m.secStore.GetPubKey())
+		if err != nil {
+			return err
+		}
+		encrypted, err := ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(pubKey), key, nil, nil)
+		if err != nil {
+			return errors.Wrap(err, "failed to encrypt invite key")
+		}
+		invite.Key = encrypted
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.repo.WriteIssuedInvite(invite)
+	return nil
+}
+
+// CheckRevoke returns an error if the invite of the receiver can't be revoked
+func (m *Manager) CheckRevoke(receiver common.Address) error {
+	inviter := m.appState.State.GetInviter(receiver)
+	if inviter == nil || inviter.Address != m.secStore.GetAddress() {
+		return errors.New("receiver is not invited by the coinbase address")
+	}
+	switch identityState := m.appState.State.GetIdentityState(receiver); identityState {
+	case state.Invite, state.Candidate:
+		return nil
+	default:
+		return errors.Errorf("invite can't be revoked, invitee state %v", identityState)
+	}
+}
+
+// SetRevoked records the tx sent to revoke the invite
+func (m *Manager) SetRevoked(receiver common.Address, txHash common.Hash) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	invite := m.repo.ReadIssuedInvite(receiver)
+	if invite == nil {
+		invite = &database.IssuedInvite{Receiver: receiver, Epoch: m.appState.State.Epoch()}
+	}
+	invite.RevokeTx = txHash
+	m.repo.WriteIssuedInvite(invite)
+}
+
+func (m *Manager) processBlock(block *types.Block) {
+	addr := m.secStore.GetAddress()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for _, tx := range block.Body.Transactions {
+		if tx.Type != types.InviteTx || tx.To == nil {
+			continue
+		}
+		if sender, _ := types.Sender(tx); sender != addr {
+			continue
+		}
+		if m.repo.ReadIssuedInvite(*tx.To) != nil {
+			continue
+		}
+		// the invite has been sent by other means than the invites API
+		m.repo.WriteIssuedInvite(&database.IssuedInvite{
+			Receiver: *tx.To,
+			TxHash:   tx.Hash(),
+			Epoch:    tx.Epoch,
+			IssuedAt: block.Header.Time().Uint64(),
+		})
+	}
+	if block.Header.Flags().HasFlag(types.ValidationFinished) {
+		m.cleanup()
+	}
+}
+
+func (m *Manager) cleanup() {
+	epoch := m.appState.State.Epoch()
+	for _, invite := range m.repo.ReadIssuedInvites() {
+		if invite.Epoch+m.cfg.KeepEpochs < epoch {
+			m.repo.DeleteIssuedInvite(invite.Receiver)
+		}
+	}
+}
+
+func inviteStatus(identityState state.IdentityState, inviteEpoch, currentEpoch uint16, revoked bool) string {
+	switch identityState {
+	case state.Invite:
+		return StatusIssued
+	case state.Candidate:
+		return StatusActivated
+	case state.Newbie, state.Verified, state.Human, state.Suspended, state.Zombie:
+		return StatusValidated
+	}
+	if revoked {
+		return StatusRevoked
+	}
+	if identityState == state.Undefined && inviteEpoch == currentEpoch {
+		return StatusPending
+	}
+	return StatusExpired
+}
+
+// Summary returns invites issued by the node identity, the most recent first
+func (m *Manager) Summary() Summary {
+	s := m.appState.State
+	addr := m.secStore.GetAddress()
+	epoch := s.Epoch()
+	nextValidation := s.NextValidationTime().Unix()
+
+	m.mutex.Lock()
+	stored := m.repo.ReadIssuedInvites()
+	m.mutex.Unlock()
+
+	known := make(map[common.Address]bool, len(stored))
+	for _, invite := range stored {
+		known[invite.Receiver] = true
+	}
+	for _, invitee := range s.GetInvitees(addr) {
+		if !known[invitee.Address] {
+			stored = append(stored, &database.IssuedInvite{Receiver: invitee.Address, TxHash: invitee.TxHash, Epoch: epoch})
+		}
+	}
+
+	result := Summary{
+		Invites: make([]Invite, 0, len(stored)),
+		Unused:  s.GetInvites(addr),
+	}
+	for _, item := range stored {
+		invite := Invite{
+			Receiver: item.Receiver,
+			TxHash:   item.TxHash,
+			Epoch:    item.Epoch,
+			IssuedAt: int64(item.IssuedAt),
+			Status:   inviteStatus(s.GetIdentityState(item.Receiver), item.Epoch, epoch, item.RevokeTx != common.Hash{}),
+			RevokeTx: item.RevokeTx,
+		}
+		if invite.Status == StatusPending || invite.Status == StatusIssued || invite.Status == StatusActivated {
+			invite.ExpiresAt = nextValidation
+		}
+		if len(item.Key) > 0 {
+			if key, err := m.secStore.DecryptMessage(item.Key); err == nil {
+				invite.Key = key
+			}
+		}
+		result.Invites = append(result.Invites, invite)
+	}
+	sort.Slice(result.Invites, func(i, j int) bool {
+		return result.Invites[i].IssuedAt > result.Invites[j].IssuedAt
+	})
+	result.Warning = m.warning(result, time.Until(time.Unix(nextValidation, 0)))
+	return result
+}
+
+func (m *Manager) warning(summary Summary, untilValidation time.Duration) string {
+	if m.appState.State.ValidationPeriod() != state.NonePeriod || untilValidation > m.cfg.WarnLeadTime {
+		return ""
+	}
+	notActivated := 0
+	for _, invite := range summary.Invites {
+		if invite.Status == StatusIssued || invite.Status == StatusPending {
+			notActivated++
+		}
+	}
+	if summary.Unused == 0 && notActivated == 0 {
+		return ""
+	}
+	return fmt.Sprintf("validation starts in %v: %v unused invites and %v not activated invites are going to be burned",
+		untilValidation.Round(time.Minute), summary.Unused, notActivated)
+}
+
+func (m *Manager) check() {
+	epoch := m.appState.State.Epoch()
+	summary := m.Summary()
+	m.mutex.Lock()
+	if m.warnedEpoch != epoch {
+		m.warnedEpoch, m.warned = epoch, false
+	}
+	alert := summary.Warning != "" && !m.warned
+	if alert {
+		m.warned = true
+	}
+	m.mutex.Unlock()
+	if alert {
+		m.log.Warn("Invites are going to be burned", "details", summary.Warning)
+	}
+}
@@ -0,0 +1,145 @@
+package invites
+
+import (
+	"crypto/ecdsa"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/secstore"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tm-db"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func newTestManager(t *testing.T) (*Manager, *appstate.AppState, *ecdsa.PrivateKey, db.DB) {
+	memdb := db.NewMemDB()
+	bus := eventbus.New()
+	appState := appstate.NewAppState(memdb, bus)
+	require.NoError(t, appState.Initialize(0))
+	appState.State.SetNextValidationTime(time.Now().Add(48 * time.Hour))
+	key, _ := crypto.GenerateKey()
+	secStore := secstore.NewSecStore()
+	secStore.AddKey(crypto.FromECDSA(key))
+	return NewManager(config.GetDefaultInvitesConfig(), memdb, appState, secStore, bus), appState, key, memdb
+}
+
+func newInviteBlock(t *testing.T, key *ecdsa.PrivateKey, receiver common.Address, flags types.BlockFlag) *types.Block {
+	tx, err := types.SignTx(&types.Transaction{Type: types.InviteTx, To: &receiver, Amount: big.NewInt(0)}, key)
+	require.NoError(t, err)
+	return &types.Block{
+		Header: &types.Header{ProposedHeader: &types.ProposedHeader{Height: 1, Time: big.NewInt(100), Flags: flags}},
+		Body:   &types.Body{Transactions: []*types.Transaction{tx}},
+	}
+}
+
+func TestManager_Issue(t *testing.T) {
+	require := require.New(t)
+	m, appState, key, _ := newTestManager(t)
+	coinbase := m.secStore.GetAddress()
+	appState.State.SetInvites(coinbase, 2)
+
+	receiver, inviteKey := common.Address{0x1}, []byte{0x1, 0x2, 0x3}
+	require.NoError(m.Add(receiver, common.Hash{0x1}, inviteKey))
+	summary := m.Summary()
+	require.Equal(uint8(2), summary.Unused)
+	require.Len(summary.Invites, 1)
+	invite := summary.Invites[0]
+	require.Equal(receiver, invite.Receiver)
+	require.Equal(common.Hash{0x1}, invite.TxHash)
+	require.Equal(StatusPending, invite.Status)
+	require.Equal(inviteKey, invite.Key)
+	require.Equal(appState.State.NextValidationTime().Unix(), invite.ExpiresAt)
+	stored := m.repo.ReadIssuedInvite(receiver)
+	require.NotEqual(inviteKey, stored.Key)
+
+	// the invite mined without the API is recorded, the one of another identity is ignored
+	other, _ := crypto.GenerateKey()
+	m.processBlock(newInviteBlock(t, other, common.Address{0x2}, 0))
+	m.processBlock(newInviteBlock(t, key, common.Address{0x3}, 0))
+	m.processBlock(newInviteBlock(t, key, receiver, 0))
+	summary = m.Summary()
+	require.Len(summary.Invites, 2)
+	require.Equal(receiver, summary.Invites[0].Receiver)
+	require.Equal(common.Address{0x3}, summary.Invites[1].Receiver)
+	require.Equal(int64(100), summary.Invites[1].IssuedAt)
+	require.Empty(summary.Invites[1].Key)
+	require.Equal(common.Hash{0x1}, m.repo.ReadIssuedInvite(receiver).TxHash)
+
+	appState.State.SetState(receiver, state.Invite)
+	appState.State.SetState(common.Address{0x3}, state.Candidate)
+	summary = m.Summary()
+	require.Equal(StatusIssued, summary.Invites[0].Status)
+	require.Equal(StatusActivated, summary.Invites[1].Status)
+}
+
+func TestManager_Revoke(t *testing.T) {
+	require := require.New(t)
+	m, appState, _, _ := newTestManager(t)
+	coinbase := m.secStore.GetAddress()
+	receiver := common.Address{0x1}
+	require.NoError(m.Add(receiver, common.Hash{0x1}, nil))
+
+	require.Error(m.CheckRevoke(receiver))
+	appState.State.SetInviter(receiver, common.Address{0x9}, common.Hash{0x1})
+	require.Error(m.CheckRevoke(receiver))
+	appState.State.SetInviter(receiver, coinbase, common.Hash{0x1})
+	appState.State.SetState(receiver, state.Invite)
+	require.NoError(m.CheckRevoke(receiver))
+	appState.State.SetState(receiver, state.Newbie)
+	require.Error(m.CheckRevoke(receiver))
+	appState.State.SetState(receiver, state.Candidate)
+	require.NoError(m.CheckRevoke(receiver))
+
+	m.SetRevoked(receiver, common.Hash{0x2})
+	appState.State.SetState(receiver, state.Killed)
+	invite := m.Summary().Invites[0]
+	require.Equal(StatusRevoked, invite.Status)
+	require.Equal(common.Hash{0x2}, invite.RevokeTx)
+	require.Equal(common.Hash{0x1}, invite.TxHash)
+	require.Zero(invite.ExpiresAt)
+
+	// the revoke tx of the invite issued without the API is recorded too
+	m.SetRevoked(common.Address{0x2}, common.Hash{0x3})
+	require.Equal(common.Hash{0x3}, m.repo.ReadIssuedInvite(common.Address{0x2}).RevokeTx)
+}
+
+func TestManager_Persistence(t *testing.T) {
+	require := require.New(t)
+	m, appState, key, memdb := newTestManager(t)
+	inviteKey := []byte{0x1}
+	require.NoError(m.Add(common.Address{0x1}, common.Hash{0x1}, inviteKey))
+
+	// invites and their keys are restored by the manager of the same db
+	m = NewManager(m.cfg, memdb, appState, m.secStore, m.bus)
+	invites := m.Summary().Invites
+	require.Len(invites, 1)
+	require.Equal(common.Address{0x1}, invites[0].Receiver)
+	require.Equal(inviteKey, invites[0].Key)
+
+	// invites are kept for KeepEpochs after the epoch they were issued in
+	appState.State.SetGlobalEpoch(m.cfg.KeepEpochs)
+	require.NoError(m.Add(common.Address{0x2}, common.Hash{0x2}, nil))
+	m.processBlock(newInviteBlock(t, key, common.Address{0x2}, types.ValidationFinished))
+	require.Len(m.repo.ReadIssuedInvites(), 2)
+	appState.State.SetGlobalEpoch(m.cfg.KeepEpochs + 1)
+	m.processBlock(newInviteBlock(t, key, common.Address{0x2}, types.ValidationFinished))
+	stored := m.repo.ReadIssuedInvites()
+	require.Len(stored, 1)
+	require.Equal(common.Address{0x2}, stored[0].Receiver)
+}
+
+func TestInviteStatus(t *testing.T) {
+	require.Equal(t, StatusPending, inviteStatus(state.Undefined, 5, 5, false))
+	require.Equal(t, StatusIssued, inviteStatus(state.Invite, 5, 5, false))
+	require.Equal(t, StatusActivated, inviteStatus(state.Candidate, 5, 5, true))
+	require.Equal(t, StatusValidated, inviteStatus(state.Newbie, 4, 5, false))
+	require.Equal(t, StatusRevoked, inviteStatus(state.Killed, 5, 5, true))
+	require.Equal(t, StatusExpired, inviteStatus(state.Undefined, 4, 5, false))
+	require.Equal(t, StatusExpired, inviteStatus(state.Killed, 4, 5, false))
+}
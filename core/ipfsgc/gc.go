@@ -0,0 +1,199 @@
+package ipfsgc
+
+import (
+	"context"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/database"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/ipfs"
+	"github.com/idena-network/idena-go/log"
+	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
+	dbm "github.com/tendermint/tm-db"
+	"sync"
+	"time"
+)
+
+var (
+	GcInProgress = errors.New("ipfs garbage collection is already in progress")
+	GcDisabled   = errors.New("ipfs garbage collection is disabled")
+)
+
+type Result struct {
+	StartTime     time.Time
+	Duration      time.Duration
+	Unpinned      int
+	Protected     int
+	RemovedBlocks int
+	Err           error
+}
+
+type Status struct {
+	Enabled    bool
+	InProgress bool
+	NextRun    time.Time
+	// TrackedPins is the number of tracked pins by data type
+	TrackedPins map[ipfs.DataType]int
+	LastResult  *Result
+}
+
+// GarbageCollector tracks pins of flips, snapshots and profiles and periodically unpins data which is not referenced
+// by the state for more than configured number of epochs, then collects unpinned blocks
+type GarbageCollector struct {
+	cfg        *config.IpfsGcConfig
+	repo       *database.Repo
+	appState   *appstate.AppState
+	ipfsProxy  ipfs.Proxy
+	log        log.Logger
+	mutex      sync.Mutex
+	inProgress bool
+	nextRun    time.Time
+	last       *Result
+}
+
+func NewGarbageCollector(cfg *config.IpfsGcConfig, db dbm.DB, appState *appstate.AppState, ipfsProxy ipfs.Proxy, bus eventbus.Bus) *GarbageCollector {
+	gc := &GarbageCollector{
+		cfg:       cfg,
+		repo:      database.NewRepo(db),
+		appState:  appState,
+		ipfsProxy: ipfsProxy,
+		log:       log.New("component", "ipfs-gc"),
+	}
+	_ = bus.Subscribe(events.IpfsPinnedEventID,
+		func(e eventbus.Event) {
+			pinnedEvent := e.(*events.IpfsPinnedEvent)
+			gc.track(pinnedEvent.Cid, pinnedEvent.DataType)
+		})
+	return gc
+}
+
+func (gc *GarbageCollector) Start() {
+	if !gc.cfg.Enabled || gc.cfg.Interval <= 0 {
+		return
+	}
+	go gc.loop()
+}
+
+func (gc *GarbageCollector) loop() {
+	for {
+		gc.mutex.Lock()
+		gc.nextRun = time.Now().Add(gc.cfg.Interval)
+		gc.mutex.Unlock()
+
+		time.Sleep(gc.cfg.Interval)
+
+		if gc.appState.State.ValidationPeriod() != state.NonePeriod {
+			gc.log.Debug("Skip ipfs garbage collection during validation")
+			continue
+		}
+		if _, err := gc.Run(); err != nil && err != GcInProgress {
+			gc.log.Warn("Ipfs garbage collection failed", "err", err)
+		}
+	}
+}
+
+func (gc *GarbageCollector) track(c []byte, dataType ipfs.DataType) {
+	gc.repo.WriteIpfsPin(&database.IpfsPin{
+		Cid:      c,
+		DataType: dataType,
+		Epoch:    gc.appState.State.Epoch(),
+		PinnedAt: uint64(time.Now().Unix()),
+	})
+}
+
+// referencedCids collects data which is referenced by the current state and must stay pinned
+func (gc *GarbageCollector) referencedCids() map[string]struct{} {
+	result := make(map[string]struct{})
+	gc.appState.State.IterateOverIdentities(func(addr common.Address, identity state.Identity) {
+		if len(identity.ProfileHash) > 0 {
+			result[string(identity.ProfileHash)] = struct{}{}
+		}
+		for _, f := range identity.Flips {
+			result[string(f.Cid)] = struct{}{}
+		}
+	})
+	if snapshotCid, _, _, _ := gc.repo.LastSnapshotManifest(); snapshotCid != nil {
+		result[string(snapshotCid)] = struct{}{}
+	}
+	return result
+}
+
+// Run unpins expired data and collects garbage immediately
+func (gc *GarbageCollector) Run() (*Result, error) {
+	if !gc.cfg.Enabled {
+		return nil, GcDisabled
+	}
+	gc.mutex.Lock()
+	if gc.inProgress {
+		gc.mutex.Unlock()
+		return nil, GcInProgress
+	}
+	gc.inProgress = true
+	gc.mutex.Unlock()
+
+	result := gc.run()
+
+	gc.mutex.Lock()
+	gc.inProgress = false
+	gc.last = result
+	gc.mutex.Unlock()
+
+	gc.log.Info("Ipfs garbage collection completed", "unpinned", result.Unpinned, "protected", result.Protected,
+		"removed", result.RemovedBlocks, "duration", result.Duration, "err", result.Err)
+	return result, result.Err
+}
+
+func (gc *GarbageCollector) run() *Result {
+	result := &Result{
+		StartTime: time.Now().UTC(),
+	}
+	epoch := gc.appState.State.Epoch()
+	referenced := gc.referencedCids()
+
+	for _, pin := range gc.repo.ReadIpfsPins() {
+		if _, ok := referenced[string(pin.Cid)]; ok {
+			if pin.Epoch != epoch {
+				pin.Epoch = epoch
+				gc.repo.WriteIpfsPin(pin)
+			}
+			result.Protected++
+			continue
+		}
+		if epoch < pin.Epoch+gc.cfg.RetentionEpochs {
+			result.Protected++
+			continue
+		}
+		if err := gc.ipfsProxy.Unpin(pin.Cid); err != nil {
+			c, _ := cid.Cast(pin.Cid)
+			gc.log.Debug("Cannot unpin ipfs data", "cid", c.String(), "err", err)
+		}
+		gc.repo.DeleteIpfsPin(pin.Cid)
+		result.Unpinned++
+	}
+
+	result.RemovedBlocks, result.Err = gc.ipfsProxy.GarbageCollect(context.Background())
+	result.Duration = time.Since(result.StartTime)
+	return result
+}
+
+func (gc *GarbageCollector) Status() *Status {
+	status := &Status{
+		Enabled:     gc.cfg.Enabled && gc.cfg.Interval > 0,
+		TrackedPins: make(map[ipfs.DataType]int),
+	}
+	for _, pin := range gc.repo.ReadIpfsPins() {
+		status.TrackedPins[pin.DataType]++
+	}
+	gc.mutex.Lock()
+	defer gc.mutex.Unlock()
+	status.InProgress = gc.inProgress
+	status.LastResult = gc.last
+	if status.Enabled {
+		status.NextRun = gc.nextRun
+	}
+	return status
+}
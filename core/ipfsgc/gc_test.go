@@ -0,0 +1,55 @@
+package ipfsgc
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/ipfs"
+	"github.com/stretchr/testify/require"
+	db "github.com/tendermint/tm-db"
+	"testing"
+)
+
+func TestGarbageCollector_Run(t *testing.T) {
+	require := require.New(t)
+
+	bus := eventbus.New()
+	memdb := db.NewMemDB()
+	appState := appstate.NewAppState(memdb, bus)
+	appState.Initialize(0)
+	appState.State.SetGlobalEpoch(1)
+	appState.Commit(nil)
+
+	cfg := config.GetDefaultIpfsGcConfig()
+	gc := NewGarbageCollector(cfg, memdb, appState, ipfs.NewMemoryIpfsProxy(), bus)
+
+	flipCid, profileCid, referencedCid := []byte{0x1, 0x1}, []byte{0x1, 0x2}, []byte{0x1, 0x3}
+	bus.Publish(&events.IpfsPinnedEvent{Cid: flipCid, DataType: ipfs.Flip})
+	bus.Publish(&events.IpfsPinnedEvent{Cid: profileCid, DataType: ipfs.Profile})
+	bus.Publish(&events.IpfsPinnedEvent{Cid: referencedCid, DataType: ipfs.Profile})
+
+	require.Equal(map[ipfs.DataType]int{ipfs.Flip: 1, ipfs.Profile: 2}, gc.Status().TrackedPins)
+
+	_, err := gc.Run()
+	require.Equal(GcDisabled, err)
+	require.Len(gc.repo.ReadIpfsPins(), 3)
+	cfg.Enabled = true
+
+	result, err := gc.Run()
+	require.NoError(err)
+	require.Equal(0, result.Unpinned)
+	require.Equal(3, result.Protected)
+
+	appState.State.SetProfileHash(common.Address{0x1}, referencedCid)
+	appState.State.SetGlobalEpoch(1 + cfg.RetentionEpochs)
+	appState.Commit(nil)
+
+	result, err = gc.Run()
+	require.NoError(err)
+	require.Equal(2, result.Unpinned)
+	require.Equal(1, result.Protected)
+	require.Equal(map[ipfs.DataType]int{ipfs.Profile: 1}, gc.Status().TrackedPins)
+	require.Equal(result, gc.Status().LastResult)
+}
@@ -0,0 +1,67 @@
+package mempool
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/config"
+	"github.com/pkg/errors"
+)
+
+var AdmissionRejected = errors.New("tx is rejected by admission policy")
+
+type AdmissionContext struct {
+	Sender common.Address
+	// SenderTxs is the number of sender txs in the pool
+	SenderTxs int
+	Local     bool
+	// Own is set for txs of the node coinbase
+	Own bool
+	// Rebroadcast is set when tx which is already in the pool is going to be sent to peers
+	Rebroadcast bool
+}
+
+// AdmissionFilter decides whether tx can enter the pool and be relayed to peers
+type AdmissionFilter interface {
+	Admit(tx *types.Transaction, ctx *AdmissionContext) error
+}
+
+// ruleFilter applies config-driven admission rules to local txs as well, only txs of the node and ceremony txs are
+// always admitted
+type ruleFilter struct {
+	cfg          *config.TxAdmission
+	allowedTypes map[types.TxType]struct{}
+}
+
+func newRuleFilter(cfg *config.TxAdmission) *ruleFilter {
+	f := &ruleFilter{
+		cfg: cfg,
+	}
+	if len(cfg.AllowedTxTypes) > 0 {
+		f.allowedTypes = make(map[types.TxType]struct{}, len(cfg.AllowedTxTypes))
+		for _, txType := range cfg.AllowedTxTypes {
+			f.allowedTypes[txType] = struct{}{}
+		}
+	}
+	return f
+}
+
+func (f *ruleFilter) Admit(tx *types.Transaction, ctx *AdmissionContext) error {
+	if ctx.Own || priorityTypes[tx.Type] {
+		return nil
+	}
+	if f.allowedTypes != nil {
+		if _, ok := f.allowedTypes[tx.Type]; !ok {
+			return errors.Wrapf(AdmissionRejected, "tx type %v is not allowed", tx.Type)
+		}
+	}
+	if f.cfg.MaxPayloadSize > 0 && len(tx.Payload) > f.cfg.MaxPayloadSize {
+		return errors.Wrapf(AdmissionRejected, "payload size %v exceeds %v", len(tx.Payload), f.cfg.MaxPayloadSize)
+	}
+	if minFee, ok := f.cfg.MinMaxFee[tx.Type]; ok && minFee != nil && tx.MaxFeeOrZero().Cmp(minFee) < 0 {
+		return errors.Wrapf(AdmissionRejected, "max fee is less than %v", minFee)
+	}
+	if !ctx.Rebroadcast && f.cfg.MaxTxsPerSender > 0 && ctx.SenderTxs >= f.cfg.MaxTxsPerSender {
+		return errors.Wrapf(AdmissionRejected, "sender has %v txs in the pool", ctx.SenderTxs)
+	}
+	return nil
+}
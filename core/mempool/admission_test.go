@@ -0,0 +1,76 @@
+package mempool
+
+import (
+	"crypto/ecdsa"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestRuleFilter_Admit(t *testing.T) {
+	require := require.New(t)
+	f := newRuleFilter(&config.TxAdmission{
+		MaxTxsPerSender: 2,
+		AllowedTxTypes:  []uint16{types.SendTx, types.OnlineStatusTx},
+		MinMaxFee:       map[uint16]*big.Int{types.SendTx: big.NewInt(10)},
+		MaxPayloadSize:  3,
+	})
+	isRejected := func(tx *types.Transaction, ctx *AdmissionContext) bool {
+		return errors.Cause(f.Admit(tx, ctx)) == AdmissionRejected
+	}
+
+	tx := &types.Transaction{Type: types.SendTx, MaxFee: big.NewInt(10)}
+	require.False(isRejected(tx, &AdmissionContext{SenderTxs: 1}))
+	require.True(isRejected(tx, &AdmissionContext{SenderTxs: 2}))
+	require.False(isRejected(tx, &AdmissionContext{SenderTxs: 2, Rebroadcast: true}))
+	require.True(isRejected(tx, &AdmissionContext{SenderTxs: 2, Local: true}))
+	require.False(isRejected(tx, &AdmissionContext{SenderTxs: 2, Local: true, Own: true}))
+
+	require.True(isRejected(&types.Transaction{Type: types.SendTx, MaxFee: big.NewInt(9)}, &AdmissionContext{}))
+	require.True(isRejected(&types.Transaction{Type: types.SendTx, MaxFee: big.NewInt(10), Payload: []byte{1, 2, 3, 4}}, &AdmissionContext{}))
+	require.False(isRejected(&types.Transaction{Type: types.OnlineStatusTx}, &AdmissionContext{}))
+	require.True(isRejected(&types.Transaction{Type: types.SubmitFlipTx}, &AdmissionContext{}))
+	require.False(isRejected(&types.Transaction{Type: types.SubmitAnswersHashTx}, &AdmissionContext{}))
+}
+
+func TestTxPool_AdmissionOfLocalTxs(t *testing.T) {
+	pool := getPool()
+	pool.filters = []AdmissionFilter{newRuleFilter(&config.TxAdmission{MaxPayloadSize: 3})}
+
+	rpcKey, _ := crypto.GenerateKey()
+	coinbaseKey, _ := crypto.GenerateKey()
+	for _, key := range []*ecdsa.PrivateKey{rpcKey, coinbaseKey} {
+		pool.appState.State.SetBalance(crypto.PubkeyToAddress(key.PublicKey), new(big.Int).Mul(big.NewInt(100), common.DnaBase))
+	}
+	pool.appState.Commit(nil)
+	pool.appState.Initialize(1)
+	pool.Initialize(&types.Header{
+		EmptyBlockHeader: &types.EmptyBlockHeader{
+			Height: 1,
+		},
+	}, crypto.PubkeyToAddress(coinbaseKey.PublicKey))
+	getTx := func(key *ecdsa.PrivateKey, payload []byte) *types.Transaction {
+		address := crypto.PubkeyToAddress(key.PublicKey)
+		tx, _ := types.SignTx(&types.Transaction{
+			AccountNonce: 1,
+			To:           &address,
+			Type:         types.SendTx,
+			Amount:       big.NewInt(1),
+			Payload:      payload,
+		}, key)
+		return tx
+	}
+
+	// RPC txs are local but pass admission rules
+	err := pool.AddLocal(getTx(rpcKey, []byte{1, 2, 3, 4}))
+	require.Equal(t, AdmissionRejected, errors.Cause(err))
+	require.Zero(t, pool.Stats().LocalTxs)
+	require.NoError(t, pool.AddLocal(getTx(rpcKey, []byte{1, 2, 3})))
+
+	require.NoError(t, pool.AddLocal(getTx(coinbaseKey, []byte{1, 2, 3, 4})))
+}
@@ -30,6 +30,10 @@ func (pool *AsyncTxPool) GetPendingTransaction() []*types.Transaction {
 	return pool.txPool.GetPendingTransaction()
 }
 
+func (pool *AsyncTxPool) CheckAdmission(tx *types.Transaction) error {
+	return pool.txPool.CheckAdmission(tx)
+}
+
 func (pool *AsyncTxPool) loop() {
 	for {
 
@@ -0,0 +1,158 @@
+package mempool
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/pkg/errors"
+	"sort"
+)
+
+// AddressContent contains txs of the sender ordered by nonce, executable txs are ready to be included into a block,
+// pending txs wait for the nonce gap to be filled or for the next epoch
+type AddressContent struct {
+	Local      bool
+	Executable []*types.Transaction
+	Pending    []*types.Transaction
+}
+
+type Stats struct {
+	Txs        int
+	Executable int
+	Pending    int
+	Senders    int
+	Locals     int
+	LocalTxs   int
+	Deferred   int
+	Evicted    int
+	Size       int
+}
+
+// Usage is the pool fill against its limits, zero max values mean the limit isn't set
+type Usage struct {
+	Txs      int `json:"txs"`
+	MaxTxs   int `json:"maxTxs"`
+	Bytes    int `json:"bytes"`
+	MaxBytes int `json:"maxBytes"`
+	// Deferred are txs received while syncing, the oldest ones are dropped above MaxDeferred
+	Deferred    int `json:"deferred"`
+	MaxDeferred int `json:"maxDeferred"`
+}
+
+func sortByNonce(txs []*types.Transaction) {
+	sort.SliceStable(txs, func(i, j int) bool {
+		if txs[i].Epoch != txs[j].Epoch {
+			return txs[i].Epoch < txs[j].Epoch
+		}
+		return txs[i].AccountNonce < txs[j].AccountNonce
+	})
+}
+
+func (pool *TxPool) Content() map[common.Address]*AddressContent {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	result := make(map[common.Address]*AddressContent)
+	get := func(sender common.Address) *AddressContent {
+		content, ok := result[sender]
+		if !ok {
+			content = &AddressContent{Local: pool.isLocal(sender)}
+			result[sender] = content
+		}
+		return content
+	}
+	for sender, executable := range pool.executableTxs {
+		content := get(sender)
+		content.Executable = append(content.Executable, executable.txs...)
+		sortByNonce(content.Executable)
+	}
+	for sender, pending := range pool.pendingTxs {
+		content := get(sender)
+		content.Pending = pending.Sorted()
+	}
+	return result
+}
+
+func (pool *TxPool) Stats() Stats {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	senders := make(map[common.Address]struct{})
+	stats := Stats{
+		LocalTxs: len(pool.localTxs),
+		Deferred: len(pool.deferredTxs),
+		Evicted:  len(pool.evicted),
+	}
+	for sender, executable := range pool.executableTxs {
+		stats.Executable += len(executable.txs)
+		senders[sender] = struct{}{}
+	}
+	for sender, pending := range pool.pendingTxs {
+		stats.Pending += len(pending.txs)
+		senders[sender] = struct{}{}
+	}
+	for sender := range senders {
+		if pool.isLocal(sender) {
+			stats.Locals++
+		}
+	}
+	stats.Size = pool.size
+	stats.Txs = stats.Executable + stats.Pending
+	stats.Senders = len(senders)
+	return stats
+}
+
+func (pool *TxPool) Usage() Usage {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	pool.all.mutex.RLock()
+	txs := len(pool.all.txs)
+	pool.all.mutex.RUnlock()
+	usage := Usage{
+		Txs:         txs,
+		Bytes:       pool.size,
+		MaxBytes:    pool.cfg.TxPoolMaxBytes,
+		Deferred:    len(pool.deferredTxs),
+		MaxDeferred: MaxDeferredTxs,
+	}
+	if maxTxs := pool.maxSize(); maxTxs > 0 {
+		usage.MaxTxs = maxTxs
+	}
+	return usage
+}
+
+// IsPriorityTx returns true for ceremony txs which bypass the pool limits
+func IsPriorityTx(txType types.TxType) bool {
+	return priorityTypes[txType]
+}
+
+// Evict removes the tx from the pool and rejects it until the end of its epoch, executable txs of the sender with
+// greater nonces become pending since they can't be included into a block anymore
+func (pool *TxPool) Evict(hash common.Hash) (*types.Transaction, error) {
+	tx := pool.GetTx(hash)
+	if tx == nil {
+		return nil, errors.New("tx is not found in the mempool")
+	}
+	sender, _ := types.Sender(tx)
+	pool.Remove(tx)
+
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	pool.evicted[hash] = tx.Epoch
+	executable, ok := pool.executableTxs[sender]
+	if !ok {
+		return tx, nil
+	}
+	var kept []*types.Transaction
+	for _, item := range executable.txs {
+		if item.Epoch == tx.Epoch && item.AccountNonce > tx.AccountNonce {
+			if err := pool.putToPending(item, pool.isLocalTx(item)); err != nil {
+				pool.forget(item)
+			}
+			continue
+		}
+		kept = append(kept, item)
+	}
+	executable.txs = kept
+	if executable.Empty() {
+		delete(pool.executableTxs, sender)
+	}
+	return tx, nil
+}
@@ -231,31 +231,37 @@ func (p *KeysPool) getPublicFlipKey(address common.Address) *ecies.PrivateKey {
 	return ecies.ImportECDSA(ecdsaKey)
 }
 
+// GetPrivateFlipKey decrypts the private flip key of the author from his keys package, the package is decrypted
+// outside the lock, so keys of different authors may be applied in parallel
 func (p *KeysPool) GetPrivateFlipKey(address common.Address) *ecies.PrivateKey {
 	p.privateKeysMutex.Lock()
-	defer p.privateKeysMutex.Unlock()
 
 	if data, ok := p.encryptedPrivateKeysCache[address]; ok {
+		p.privateKeysMutex.Unlock()
 		return data
 	}
 
 	publicFlipKey := p.getPublicFlipKey(address)
 	if publicFlipKey == nil {
+		p.privateKeysMutex.Unlock()
 		log.Warn("GetPrivateFlipKey: public flip key is missing", "address", address.Hex())
 		return nil
 	}
 
 	keysPackage, ok := p.flipKeyPackages[address]
 	if !ok {
+		p.privateKeysMutex.Unlock()
 		log.Warn("GetPrivateFlipKey: package is missing", "address", address.Hex())
 		return nil
 	}
 
 	idx, ok := p.privateKeyIndexes[address]
 	if !ok {
+		p.privateKeysMutex.Unlock()
 		log.Warn("GetPrivateFlipKey: indexes are missing", "address", address.Hex())
 		return nil
 	}
+	p.privateKeysMutex.Unlock()
 
 	encryptedFlipKey, err := getEncryptedKeyFromPackage(publicFlipKey, keysPackage.Data, idx)
 	if err != nil {
@@ -276,7 +282,12 @@ func (p *KeysPool) GetPrivateFlipKey(address common.Address) *ecies.PrivateKey {
 	}
 
 	result := ecies.ImportECDSA(ecdsaKey)
-	p.encryptedPrivateKeysCache[address] = result
+	p.privateKeysMutex.Lock()
+	// the pool may be cleared while the package is decrypted
+	if p.flipKeyPackages[address] == keysPackage {
+		p.encryptedPrivateKeysCache[address] = result
+	}
+	p.privateKeysMutex.Unlock()
 	return result
 }
 
@@ -0,0 +1,84 @@
+package mempool
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/events"
+	"time"
+)
+
+const rebroadcastCheckInterval = time.Second * 10
+
+// rebroadcastState is the schedule of repeated announcements of the local tx
+type rebroadcastState struct {
+	attempts int
+	next     time.Time
+}
+
+func (pool *TxPool) scheduleRebroadcast(hash common.Hash, now time.Time) {
+	if pool.cfg.RebroadcastInterval <= 0 {
+		return
+	}
+	pool.rebroadcasts[hash] = &rebroadcastState{next: now.Add(pool.rebroadcastDelay(0))}
+}
+
+// rebroadcastDelay returns the delay before the announcement following the given number of attempts
+func (pool *TxPool) rebroadcastDelay(attempts int) time.Duration {
+	delay := pool.cfg.RebroadcastInterval
+	for i := 0; i < attempts; i++ {
+		delay *= 2
+		if pool.cfg.RebroadcastMaxInterval > 0 && delay >= pool.cfg.RebroadcastMaxInterval {
+			return pool.cfg.RebroadcastMaxInterval
+		}
+	}
+	return delay
+}
+
+func (pool *TxPool) rebroadcastLoop() {
+	for {
+		select {
+		case <-pool.stop:
+			return
+		case <-time.After(rebroadcastCheckInterval):
+		}
+		if txs := pool.rebroadcast(time.Now()); len(txs) > 0 {
+			pool.log.Debug("Local txs have been rebroadcast", "count", len(txs))
+		}
+	}
+}
+
+// rebroadcast announces local txs which schedules are due to all peers, the delay doubles after each attempt until
+// the tx leaves the pool
+func (pool *TxPool) rebroadcast(now time.Time) []*types.Transaction {
+	pool.mutex.Lock()
+	if pool.isSyncing {
+		pool.mutex.Unlock()
+		return nil
+	}
+	var result []*types.Transaction
+	for hash, schedule := range pool.rebroadcasts {
+		if schedule.next.After(now) {
+			continue
+		}
+		tx, ok := pool.all.Get(hash)
+		if !ok {
+			delete(pool.rebroadcasts, hash)
+			continue
+		}
+		schedule.attempts++
+		schedule.next = now.Add(pool.rebroadcastDelay(schedule.attempts))
+		result = append(result, tx)
+	}
+	pool.mutex.Unlock()
+
+	for _, tx := range result {
+		sender, _ := types.Sender(tx)
+		pool.bus.Publish(&events.NewTxEvent{
+			Tx:          tx,
+			Own:         sender == pool.coinbase,
+			Local:       true,
+			Rebroadcast: true,
+		})
+	}
+	return result
+}
@@ -0,0 +1,91 @@
+package mempool
+
+import (
+	"crypto/ecdsa"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/events"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestTxPool_rebroadcastDelay(t *testing.T) {
+	pool := getPool()
+	pool.cfg.RebroadcastInterval = time.Minute
+	pool.cfg.RebroadcastMaxInterval = time.Minute * 5
+
+	require.Equal(t, time.Minute, pool.rebroadcastDelay(0))
+	require.Equal(t, time.Minute*2, pool.rebroadcastDelay(1))
+	require.Equal(t, time.Minute*4, pool.rebroadcastDelay(2))
+	require.Equal(t, time.Minute*5, pool.rebroadcastDelay(3))
+	require.Equal(t, time.Minute*5, pool.rebroadcastDelay(10))
+}
+
+func TestTxPool_rebroadcast(t *testing.T) {
+	pool := getPool()
+	pool.cfg.RebroadcastInterval = time.Minute
+	pool.cfg.RebroadcastMaxInterval = time.Hour
+
+	var rebroadcasts []*events.NewTxEvent
+	pool.bus.Subscribe(events.NewTxEventID, func(e eventbus.Event) {
+		if event := e.(*events.NewTxEvent); event.Rebroadcast {
+			rebroadcasts = append(rebroadcasts, event)
+		}
+	})
+
+	localKey, _ := crypto.GenerateKey()
+	regularKey, _ := crypto.GenerateKey()
+	for _, key := range []*ecdsa.PrivateKey{localKey, regularKey} {
+		pool.appState.State.SetBalance(crypto.PubkeyToAddress(key.PublicKey), new(big.Int).Mul(big.NewInt(100), common.DnaBase))
+	}
+	pool.appState.Commit(nil)
+	pool.appState.Initialize(1)
+	pool.head = &types.Header{
+		EmptyBlockHeader: &types.EmptyBlockHeader{
+			Height: 1,
+		},
+	}
+	getTx := func(key *ecdsa.PrivateKey) *types.Transaction {
+		address := crypto.PubkeyToAddress(key.PublicKey)
+		tx, _ := types.SignTx(&types.Transaction{
+			AccountNonce: 1,
+			To:           &address,
+			Type:         types.SendTx,
+			Amount:       big.NewInt(1),
+		}, key)
+		return tx
+	}
+	localTx := getTx(localKey)
+	require.NoError(t, pool.AddLocal(localTx))
+	require.NoError(t, pool.Add(getTx(regularKey)))
+	require.Len(t, pool.rebroadcasts, 1)
+
+	now := time.Now()
+	require.Empty(t, pool.rebroadcast(now))
+
+	now = now.Add(time.Minute + time.Second)
+	txs := pool.rebroadcast(now)
+	require.Len(t, txs, 1)
+	require.Equal(t, localTx.Hash(), txs[0].Hash())
+	require.Len(t, rebroadcasts, 1)
+	require.True(t, rebroadcasts[0].Local)
+	require.Equal(t, now.Add(time.Minute*2), pool.rebroadcasts[localTx.Hash()].next)
+
+	require.Empty(t, pool.rebroadcast(now.Add(time.Minute)))
+	now = now.Add(time.Minute * 2)
+	require.Len(t, pool.rebroadcast(now), 1)
+	require.Equal(t, now.Add(time.Minute*4), pool.rebroadcasts[localTx.Hash()].next)
+
+	pool.isSyncing = true
+	require.Empty(t, pool.rebroadcast(now.Add(time.Hour)))
+	pool.isSyncing = false
+
+	pool.Remove(localTx)
+	require.Empty(t, pool.rebroadcasts)
+	require.Empty(t, pool.rebroadcast(now.Add(time.Hour)))
+	require.Len(t, rebroadcasts, 2)
+}
@@ -0,0 +1,181 @@
+package mempool
+
+import (
+	"container/heap"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"sort"
+	"sync"
+)
+
+const (
+	NonceOrdering = "nonce"
+	FeeOrdering   = "fee"
+	FifoOrdering  = "fifo"
+	FairOrdering  = "fair"
+)
+
+// TxOrdering defines the order executable transactions are tried to be included into the proposed block.
+// Transactions are passed sorted by nonce and the order of transactions of the same sender must be kept.
+type TxOrdering interface {
+	Order(txs []*types.Transaction, arrivals map[common.Hash]uint64) []*types.Transaction
+}
+
+var (
+	orderingsMutex sync.RWMutex
+	orderings      = map[string]func() TxOrdering{
+		NonceOrdering: func() TxOrdering { return &nonceOrdering{} },
+		FeeOrdering:   func() TxOrdering { return &feeOrdering{} },
+		FifoOrdering:  func() TxOrdering { return &fifoOrdering{} },
+		FairOrdering:  func() TxOrdering { return &fairOrdering{} },
+	}
+)
+
+// RegisterTxOrdering makes a custom ordering strategy selectable by name via config
+func RegisterTxOrdering(name string, factory func() TxOrdering) {
+	orderingsMutex.Lock()
+	defer orderingsMutex.Unlock()
+	orderings[name] = factory
+}
+
+func HasTxOrdering(name string) bool {
+	orderingsMutex.RLock()
+	defer orderingsMutex.RUnlock()
+	_, ok := orderings[name]
+	return ok
+}
+
+func newTxOrdering(name string) TxOrdering {
+	orderingsMutex.RLock()
+	defer orderingsMutex.RUnlock()
+	if factory, ok := orderings[name]; ok {
+		return factory()
+	}
+	return &nonceOrdering{}
+}
+
+// groupBySender splits transactions by sender keeping their order, senders are ordered by their first transaction arrival
+func groupBySender(txs []*types.Transaction, arrivals map[common.Hash]uint64) [][]*types.Transaction {
+	bySender := make(map[common.Address][]*types.Transaction)
+	var senders []common.Address
+	for _, tx := range txs {
+		sender, _ := types.Sender(tx)
+		if _, ok := bySender[sender]; !ok {
+			senders = append(senders, sender)
+		}
+		bySender[sender] = append(bySender[sender], tx)
+	}
+	firstArrival := func(sender common.Address) uint64 {
+		result := ^uint64(0)
+		for _, tx := range bySender[sender] {
+			if arrival := arrivals[tx.Hash()]; arrival < result {
+				result = arrival
+			}
+		}
+		return result
+	}
+	sort.SliceStable(senders, func(i, j int) bool {
+		return firstArrival(senders[i]) < firstArrival(senders[j])
+	})
+	result := make([][]*types.Transaction, 0, len(senders))
+	for _, sender := range senders {
+		result = append(result, bySender[sender])
+	}
+	return result
+}
+
+type senderQueues struct {
+	queues [][]*types.Transaction
+	less   func(a, b *types.Transaction) bool
+}
+
+func (q *senderQueues) Len() int { return len(q.queues) }
+func (q *senderQueues) Less(i, j int) bool {
+	return q.less(q.queues[i][0], q.queues[j][0])
+}
+func (q *senderQueues) Swap(i, j int) { q.queues[i], q.queues[j] = q.queues[j], q.queues[i] }
+func (q *senderQueues) Push(x interface{}) {
+	q.queues = append(q.queues, x.([]*types.Transaction))
+}
+func (q *senderQueues) Pop() interface{} {
+	old := q.queues
+	n := len(old)
+	item := old[n-1]
+	q.queues = old[:n-1]
+	return item
+}
+
+// mergeBySender repeatedly takes the best next transaction among senders, so nonce order of every sender is kept
+func mergeBySender(txs []*types.Transaction, arrivals map[common.Hash]uint64, less func(a, b *types.Transaction) bool) []*types.Transaction {
+	queues := &senderQueues{
+		queues: groupBySender(txs, arrivals),
+		less:   less,
+	}
+	heap.Init(queues)
+	result := make([]*types.Transaction, 0, len(txs))
+	for queues.Len() > 0 {
+		queue := queues.queues[0]
+		result = append(result, queue[0])
+		if len(queue) == 1 {
+			heap.Pop(queues)
+			continue
+		}
+		queues.queues[0] = queue[1:]
+		heap.Fix(queues, 0)
+	}
+	return result
+}
+
+// nonceOrdering keeps transactions sorted by nonce levels as the pool did before orderings were configurable, first
+// transactions of all senders go before second ones, it is the default ordering
+type nonceOrdering struct{}
+
+func (o *nonceOrdering) Order(txs []*types.Transaction, arrivals map[common.Hash]uint64) []*types.Transaction {
+	return txs
+}
+
+// feeOrdering prefers transactions with higher tips and max fee, transactions with equal fees are taken by nonce
+// levels like nonceOrdering does, so every sender progresses in each block
+type feeOrdering struct{}
+
+func (o *feeOrdering) Order(txs []*types.Transaction, arrivals map[common.Hash]uint64) []*types.Transaction {
+	return mergeBySender(txs, arrivals, func(a, b *types.Transaction) bool {
+		if cmp := a.TipsOrZero().Cmp(b.TipsOrZero()); cmp != 0 {
+			return cmp > 0
+		}
+		if cmp := a.MaxFeeOrZero().Cmp(b.MaxFeeOrZero()); cmp != 0 {
+			return cmp > 0
+		}
+		if a.AccountNonce != b.AccountNonce {
+			return a.AccountNonce < b.AccountNonce
+		}
+		return arrivals[a.Hash()] < arrivals[b.Hash()]
+	})
+}
+
+// fifoOrdering includes transactions in order they have been received by the node
+type fifoOrdering struct{}
+
+func (o *fifoOrdering) Order(txs []*types.Transaction, arrivals map[common.Hash]uint64) []*types.Transaction {
+	return mergeBySender(txs, arrivals, func(a, b *types.Transaction) bool {
+		return arrivals[a.Hash()] < arrivals[b.Hash()]
+	})
+}
+
+// fairOrdering takes one transaction of every sender by turns, so a sender with many transactions can't starve others
+type fairOrdering struct{}
+
+func (o *fairOrdering) Order(txs []*types.Transaction, arrivals map[common.Hash]uint64) []*types.Transaction {
+	queues := groupBySender(txs, arrivals)
+	result := make([]*types.Transaction, 0, len(txs))
+	for len(result) < len(txs) {
+		for i, queue := range queues {
+			if len(queue) == 0 {
+				continue
+			}
+			result = append(result, queue[0])
+			queues[i] = queue[1:]
+		}
+	}
+	return result
+}
@@ -0,0 +1,44 @@
+package mempool
+
+import (
+	"crypto/ecdsa"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestTxOrdering(t *testing.T) {
+	require := require.New(t)
+	key1, _ := crypto.GenerateKey()
+	key2, _ := crypto.GenerateKey()
+
+	newTx := func(key *ecdsa.PrivateKey, nonce uint32, tips int64) *types.Transaction {
+		tx, _ := types.SignTx(&types.Transaction{
+			AccountNonce: nonce,
+			Type:         types.SendTx,
+			Tips:         big.NewInt(tips),
+		}, key)
+		return tx
+	}
+
+	a1, a2, a3 := newTx(key1, 1, 0), newTx(key1, 2, 10), newTx(key1, 3, 0)
+	b1, b2 := newTx(key2, 1, 5), newTx(key2, 2, 1)
+
+	txs := []*types.Transaction{a1, b1, a2, b2, a3}
+	arrivals := map[common.Hash]uint64{
+		a1.Hash(): 1, a2.Hash(): 2, a3.Hash(): 3, b1.Hash(): 4, b2.Hash(): 5,
+	}
+
+	require.Equal(txs, newTxOrdering(NonceOrdering).Order(txs, arrivals))
+	require.Equal(txs, newTxOrdering("").Order(txs, arrivals))
+	require.Equal([]*types.Transaction{b1, b2, a1, a2, a3}, newTxOrdering(FeeOrdering).Order(txs, arrivals))
+	require.Equal([]*types.Transaction{a1, a2, a3, b1, b2}, newTxOrdering(FifoOrdering).Order(txs, arrivals))
+	require.Equal([]*types.Transaction{a1, b1, a2, b2, a3}, newTxOrdering(FairOrdering).Order(txs, arrivals))
+
+	require.False(HasTxOrdering("custom"))
+	RegisterTxOrdering("custom", func() TxOrdering { return &fifoOrdering{} })
+	require.True(HasTxOrdering("custom"))
+}
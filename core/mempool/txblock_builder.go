@@ -13,6 +13,8 @@ type buildingContext struct {
 	sortedPriorityTxs  []*types.Transaction
 	sortedTxsPerSender map[common.Address][]*types.Transaction
 	curNoncesPerSender map[common.Address]uint32
+	localTxs           []*types.Transaction
+	localsSpace        int
 	blockTxs           []*types.Transaction
 	blockSize          int
 }
@@ -23,6 +25,8 @@ func newBuildingContext(
 	sortedPriorityTxs []*types.Transaction,
 	sortedTxsPerSender map[common.Address][]*types.Transaction,
 	curNoncesPerSender map[common.Address]uint32,
+	localTxs []*types.Transaction,
+	localsSpace int,
 ) *buildingContext {
 
 	ctx := &buildingContext{
@@ -31,6 +35,8 @@ func newBuildingContext(
 		sortedPriorityTxs:  sortedPriorityTxs,
 		sortedTxsPerSender: sortedTxsPerSender,
 		curNoncesPerSender: curNoncesPerSender,
+		localTxs:           localTxs,
+		localsSpace:        localsSpace,
 	}
 	return ctx
 }
@@ -80,6 +86,27 @@ func (ctx *buildingContext) addNextPriorityTxToBlock() {
 	ctx.sortedTxsPerSender[sender] = ctx.sortedTxsPerSender[sender][i:]
 }
 
+// addLocalTxsToBlock adds local txs into the reserved block space, the rest of local txs compete with regular ones
+func (ctx *buildingContext) addLocalTxsToBlock() {
+	localsSize := 0
+	for _, tx := range ctx.localTxs {
+		if !ctx.checkFee(tx) {
+			continue
+		}
+		sender, _ := types.Sender(tx)
+		if ctx.curNoncesPerSender[sender]+1 != tx.AccountNonce {
+			continue
+		}
+		if localsSize+tx.Size() > ctx.localsSpace || ctx.blockSize+tx.Size() > BlockBodySize {
+			return
+		}
+		ctx.blockTxs = append(ctx.blockTxs, tx)
+		ctx.blockSize += tx.Size()
+		localsSize += tx.Size()
+		ctx.curNoncesPerSender[sender] = tx.AccountNonce
+	}
+}
+
 func (ctx *buildingContext) addTxsToBlock() {
 	txs := ctx.sortedTxs
 	for _, tx := range txs {
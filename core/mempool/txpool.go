@@ -14,6 +14,7 @@ import (
 	"math/big"
 	"sort"
 	"sync"
+	"time"
 )
 
 const (
@@ -24,6 +25,8 @@ const (
 var (
 	DuplicateTxError = errors.New("tx with same hash already exists")
 	MempoolFullError = errors.New("mempool is full")
+	EvictedTxError   = errors.New("tx has been evicted by the node operator")
+	StoppedPoolError = errors.New("mempool is stopped")
 	priorityTypes    = map[types.TxType]bool{
 		types.SubmitAnswersHashTx:  true,
 		types.SubmitShortAnswersTx: true,
@@ -35,6 +38,7 @@ var (
 type TransactionPool interface {
 	Add(tx *types.Transaction) error
 	GetPendingTransaction() []*types.Transaction
+	CheckAdmission(tx *types.Transaction) error
 }
 
 type TxPool struct {
@@ -53,6 +57,25 @@ type TxPool struct {
 	isSyncing        bool //indicates about blockchain's syncing
 	coinbase         common.Address
 	minFeePerByte    *big.Int
+	ordering         TxOrdering
+	// arrivals keeps the sequence number of tx arrival by hash
+	arrivals   map[common.Hash]uint64
+	arrivalSeq uint64
+	// locals are senders which txs bypass mempool limits and get reserved block space
+	locals map[common.Address]struct{}
+	// localTxs are hashes of txs sent via RPC which are treated as local while they stay in the pool
+	localTxs map[common.Hash]struct{}
+	filters  []AdmissionFilter
+	// evicted are hashes of txs removed by the operator with their epochs, they are rejected until the epoch ends
+	evicted map[common.Hash]uint16
+	// added keeps the time of tx arrival by hash, it is used to expire stale pending txs
+	added map[common.Hash]time.Time
+	// size is the total size of pool txs in bytes
+	size int
+	// rebroadcasts are schedules of repeated announcements of local txs by hash
+	rebroadcasts map[common.Hash]*rebroadcastState
+	stop         chan struct{}
+	stopped      bool
 }
 
 func NewTxPool(appState *appstate.AppState, bus eventbus.Bus, cfg *config.Mempool, minFeePerByte *big.Int) *TxPool {
@@ -67,6 +90,20 @@ func NewTxPool(appState *appstate.AppState, bus eventbus.Bus, cfg *config.Mempoo
 		log:              log.New(),
 		bus:              bus,
 		minFeePerByte:    minFeePerByte,
+		ordering:         newTxOrdering(cfg.TxOrdering),
+		arrivals:         make(map[common.Hash]uint64),
+		locals:           make(map[common.Address]struct{}),
+		localTxs:         make(map[common.Hash]struct{}),
+		evicted:          make(map[common.Hash]uint16),
+		added:            make(map[common.Hash]time.Time),
+		rebroadcasts:     make(map[common.Hash]*rebroadcastState),
+		stop:             make(chan struct{}),
+	}
+	for _, addr := range cfg.Locals {
+		pool.locals[addr] = struct{}{}
+	}
+	if cfg.Admission != nil {
+		pool.filters = append(pool.filters, newRuleFilter(cfg.Admission))
 	}
 
 	_ = pool.bus.Subscribe(events.AddBlockEventID,
@@ -74,6 +111,16 @@ func NewTxPool(appState *appstate.AppState, bus eventbus.Bus, cfg *config.Mempoo
 			newBlockEvent := e.(*events.NewBlockEvent)
 			pool.head = newBlockEvent.Block.Header
 		})
+	if cfg.TxLifetime > 0 && cfg.TxExpiryInterval > 0 {
+		go pool.expireLoop()
+	}
+	if cfg.RebroadcastInterval > 0 {
+		go pool.rebroadcastLoop()
+	}
+	_ = pool.bus.Subscribe(events.ReorgEventID,
+		func(e eventbus.Event) {
+			pool.resubmit(e.(*events.ReorgEvent).DroppedTxs)
+		})
 	_ = pool.bus.Subscribe(events.FastSyncCompleted, func(event eventbus.Event) {
 		pool.appState.NonceCache.Lock()
 		pool.appState.NonceCache.ReloadFallback(pool.appState.State)
@@ -85,6 +132,7 @@ func NewTxPool(appState *appstate.AppState, bus eventbus.Bus, cfg *config.Mempoo
 func (pool *TxPool) Initialize(head *types.Header, coinbase common.Address) {
 	pool.head = head
 	pool.coinbase = coinbase
+	pool.appState.NonceCache.SetLimit(pool.cfg.NonceCacheSize, coinbase)
 }
 
 func (pool *TxPool) addDeferredTx(tx *types.Transaction) {
@@ -107,9 +155,16 @@ func (pool *TxPool) Validate(tx *types.Transaction) error {
 	pool.mutex.Lock()
 	defer pool.mutex.Unlock()
 
+	if _, ok := pool.evicted[tx.Hash()]; ok {
+		return EvictedTxError
+	}
+
 	if err := pool.checkLimits(tx); err != nil {
 		return err
 	}
+	if err := pool.admit(tx, false); err != nil {
+		return err
+	}
 	appState, err := pool.appState.Readonly(pool.head.Height())
 
 	if err != nil {
@@ -122,9 +177,83 @@ func (pool *TxPool) checkLimits(tx *types.Transaction) error {
 	if priorityTypes[tx.Type] {
 		return pool.checkPriorityTxLimits(tx)
 	}
+	if pool.isLocalTx(tx) {
+		return nil
+	}
 	return pool.checkRegularTxLimits(tx)
 }
 
+// SetLimits applies pool size and payload limits and local senders of cfg, txs already in the pool are kept
+func (pool *TxPool) SetLimits(cfg *config.Mempool) {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	pool.cfg.TxPoolQueueSlots = cfg.TxPoolQueueSlots
+	pool.cfg.TxPoolExecutableSlots = cfg.TxPoolExecutableSlots
+	pool.cfg.TxPoolMaxSize = cfg.TxPoolMaxSize
+	pool.cfg.TxPoolMaxBytes = cfg.TxPoolMaxBytes
+	pool.cfg.TxMaxPayloadSize = cfg.TxMaxPayloadSize
+	pool.cfg.Locals = cfg.Locals
+	pool.locals = make(map[common.Address]struct{}, len(cfg.Locals))
+	for _, addr := range cfg.Locals {
+		pool.locals[addr] = struct{}{}
+	}
+}
+
+// AddAdmissionFilter adds custom filter which is applied to txs before they enter the pool and before rebroadcast
+func (pool *TxPool) AddAdmissionFilter(filter AdmissionFilter) {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	pool.filters = append(pool.filters, filter)
+}
+
+func (pool *TxPool) admit(tx *types.Transaction, rebroadcast bool) error {
+	if len(pool.filters) == 0 {
+		return nil
+	}
+	sender, _ := types.Sender(tx)
+	ctx := &AdmissionContext{
+		Sender:      sender,
+		Local:       pool.isLocalTx(tx),
+		Own:         sender == pool.coinbase,
+		Rebroadcast: rebroadcast,
+	}
+	if executable, ok := pool.executableTxs[sender]; ok {
+		ctx.SenderTxs += len(executable.txs)
+	}
+	if pending, ok := pool.pendingTxs[sender]; ok {
+		ctx.SenderTxs += len(pending.txs)
+	}
+	for _, filter := range pool.filters {
+		if err := filter.Admit(tx, ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckAdmission checks if the pool tx can be relayed to peers
+func (pool *TxPool) CheckAdmission(tx *types.Transaction) error {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	return pool.admit(tx, true)
+}
+
+func (pool *TxPool) isLocal(sender common.Address) bool {
+	if sender == pool.coinbase {
+		return true
+	}
+	_, ok := pool.locals[sender]
+	return ok
+}
+
+func (pool *TxPool) isLocalTx(tx *types.Transaction) bool {
+	if sender, _ := types.Sender(tx); pool.isLocal(sender) {
+		return true
+	}
+	_, ok := pool.localTxs[tx.Hash()]
+	return ok
+}
+
 func (pool *TxPool) checkPriorityTxLimits(tx *types.Transaction) error {
 	sender, _ := types.Sender(tx)
 	if executable, ok := pool.executableTxs[sender]; ok {
@@ -144,17 +273,27 @@ func (pool *TxPool) checkPriorityTxLimits(tx *types.Transaction) error {
 	return nil
 }
 
-func (pool *TxPool) checkRegularTxLimits(tx *types.Transaction) error {
-	var totalLimit = 0
+func (pool *TxPool) maxSize() int {
+	if pool.cfg.TxPoolMaxSize != 0 {
+		return pool.cfg.TxPoolMaxSize
+	}
 	if pool.cfg.TxPoolExecutableSlots < 0 || pool.cfg.TxPoolQueueSlots < 0 {
-		totalLimit = -1
-	} else {
-		totalLimit = pool.cfg.TxPoolExecutableSlots*pool.cfg.TxPoolAddrExecutableLimit +
-			pool.cfg.TxPoolQueueSlots*pool.cfg.TxPoolAddrQueueLimit
+		return -1
 	}
-	if totalLimit > 0 && len(pool.all.txs) >= totalLimit {
+	return pool.cfg.TxPoolExecutableSlots*pool.cfg.TxPoolAddrExecutableLimit +
+		pool.cfg.TxPoolQueueSlots*pool.cfg.TxPoolAddrQueueLimit
+}
+
+func (pool *TxPool) checkRegularTxLimits(tx *types.Transaction) error {
+	if pool.cfg.TxMaxPayloadSize > 0 && len(tx.Payload) > pool.cfg.TxMaxPayloadSize {
+		return errors.Errorf("tx payload size %v exceeds limit %v", len(tx.Payload), pool.cfg.TxMaxPayloadSize)
+	}
+	if totalLimit := pool.maxSize(); totalLimit > 0 && len(pool.all.txs) >= totalLimit {
 		return errors.New("tx queue max size reached")
 	}
+	if pool.cfg.TxPoolMaxBytes > 0 && pool.size+tx.Size() > pool.cfg.TxPoolMaxBytes {
+		return errors.New("tx queue max size in bytes reached")
+	}
 	sender, _ := types.Sender(tx)
 
 	if byAddr, ok := pool.executableTxs[sender]; ok {
@@ -184,6 +323,7 @@ func (pool *TxPool) AddTxs(txs []*types.Transaction) {
 		pool.log.Warn("txpool: failed to create readonly appState", "err", err)
 		return
 	}
+	types.RecoverSenders(txs)
 	for _, tx := range txs {
 
 		sender, _ := types.Sender(tx)
@@ -197,6 +337,49 @@ func (pool *TxPool) AddTxs(txs []*types.Transaction) {
 	}
 }
 
+// resubmit returns txs dropped by the chain reorganization to the pool, txs which became invalid are skipped
+func (pool *TxPool) resubmit(txs []*types.Transaction) {
+	if len(txs) == 0 {
+		return
+	}
+	appState, err := pool.appState.Readonly(pool.head.Height())
+	if err != nil {
+		pool.log.Warn("txpool: failed to create readonly appState", "err", err)
+		return
+	}
+	resubmitted := 0
+	for _, tx := range txs {
+		if err := pool.add(tx, appState); err == nil {
+			resubmitted++
+		}
+	}
+	pool.log.Info("Resubmitted txs dropped by reorg", "dropped", len(txs), "resubmitted", resubmitted)
+}
+
+// AddLocal adds tx submitted via RPC, the tx is treated as local while it stays in the pool if the number of such txs
+// doesn't exceed RpcLocalTxs, the sender doesn't become local
+func (pool *TxPool) AddLocal(tx *types.Transaction) error {
+	hash := tx.Hash()
+	pool.mutex.Lock()
+	_, marked := pool.localTxs[hash]
+	mark := !marked && len(pool.localTxs) < pool.cfg.RpcLocalTxs
+	if mark {
+		pool.localTxs[hash] = struct{}{}
+	}
+	pool.mutex.Unlock()
+
+	err := pool.Add(tx)
+	if mark {
+		// deferred and rejected txs don't keep the mark
+		if _, ok := pool.all.Get(hash); err != nil || !ok {
+			pool.mutex.Lock()
+			delete(pool.localTxs, hash)
+			pool.mutex.Unlock()
+		}
+	}
+	return err
+}
+
 func (pool *TxPool) Add(tx *types.Transaction) error {
 
 	sender, _ := types.Sender(tx)
@@ -219,36 +402,54 @@ func (pool *TxPool) add(tx *types.Transaction, appState *appstate.AppState) erro
 		return DuplicateTxError
 	}
 
+	sender, _ := types.Sender(tx)
+
+	// validation reads the own readonly state only, so txs of concurrent callers are validated without the pool lock
+	if err := pool.validate(tx, appState, validation.InboundTx); err != nil {
+		if sender == pool.coinbase {
+			log.Warn("Tx is not valid", "hash", tx.Hash().Hex(), "err", err)
+		}
+		return err
+	}
+
 	pool.mutex.Lock()
 	defer pool.mutex.Unlock()
 
+	if pool.stopped {
+		return StoppedPoolError
+	}
+
+	// the same tx may have been added by a concurrent caller while it was validated
+	if _, ok := pool.all.Get(tx.Hash()); ok {
+		return DuplicateTxError
+	}
+
+	if _, ok := pool.evicted[tx.Hash()]; ok {
+		return EvictedTxError
+	}
+
 	if err := pool.checkLimits(tx); err != nil {
 		log.Warn("Tx limits", "hash", tx.Hash().Hex(), "err", err)
 		return err
 	}
 
-	sender, _ := types.Sender(tx)
-
-	if err := pool.validate(tx, appState, validation.InboundTx); err != nil {
-		if sender == pool.coinbase {
-			log.Warn("Tx is not valid", "hash", tx.Hash().Hex(), "err", err)
-		}
+	if err := pool.admit(tx, false); err != nil {
 		return err
 	}
 
 	return pool.put(tx)
 }
 
-func (pool *TxPool) putToPending(tx *types.Transaction) error {
+func (pool *TxPool) putToPending(tx *types.Transaction, local bool) error {
 	sender, _ := types.Sender(tx)
 	set, ok := pool.pendingTxs[sender]
 	if !ok {
-		if pool.cfg.TxPoolQueueSlots > 0 && len(pool.pendingTxs) >= pool.cfg.TxPoolQueueSlots {
+		if !local && pool.cfg.TxPoolQueueSlots > 0 && len(pool.pendingTxs) >= pool.cfg.TxPoolQueueSlots {
 			return MempoolFullError
 		}
 		set = newTxMap(pool.cfg.TxPoolAddrQueueLimit)
 	}
-	err := set.Add(tx)
+	err := set.add(tx, local)
 	if err == nil {
 		pool.pendingTxs[sender] = set
 	}
@@ -265,6 +466,7 @@ func (pool *TxPool) put(tx *types.Transaction) error {
 	}
 
 	isExecutable := true
+	local := pool.isLocalTx(tx)
 
 	if executable.Empty() {
 		globalEpoch := pool.appState.State.Epoch()
@@ -279,14 +481,14 @@ func (pool *TxPool) put(tx *types.Transaction) error {
 	}
 	var err error
 	if isExecutable {
-		err = executable.Add(tx)
+		err = executable.add(tx, local)
 		if err != nil {
-			err = pool.putToPending(tx)
+			err = pool.putToPending(tx, local)
 		} else {
 			pool.executableTxs[sender] = executable
 		}
 	} else {
-		err = pool.putToPending(tx)
+		err = pool.putToPending(tx, local)
 	}
 
 	if err != nil {
@@ -294,12 +496,20 @@ func (pool *TxPool) put(tx *types.Transaction) error {
 	}
 
 	pool.all.Add(tx)
+	pool.arrivalSeq++
+	pool.arrivals[tx.Hash()] = pool.arrivalSeq
+	pool.added[tx.Hash()] = time.Now()
+	pool.size += tx.Size()
+	if local {
+		pool.scheduleRebroadcast(tx.Hash(), time.Now())
+	}
 
 	pool.appState.NonceCache.SetNonce(sender, tx.Epoch, tx.AccountNonce)
 
 	pool.bus.Publish(&events.NewTxEvent{
-		Tx:  tx,
-		Own: sender == pool.coinbase,
+		Tx:    tx,
+		Own:   sender == pool.coinbase,
+		Local: local,
 	})
 	return nil
 }
@@ -343,6 +553,7 @@ func (pool *TxPool) GetTx(hash common.Hash) *types.Transaction {
 func (pool *TxPool) BuildBlockTransactions() []*types.Transaction {
 	ctx := pool.createBuildingContext()
 	ctx.addPriorityTxsToBlock()
+	ctx.addLocalTxsToBlock()
 	ctx.addTxsToBlock()
 	return ctx.blockTxs
 }
@@ -350,7 +561,7 @@ func (pool *TxPool) BuildBlockTransactions() []*types.Transaction {
 func (pool *TxPool) Remove(transaction *types.Transaction) {
 	pool.mutex.Lock()
 	defer pool.mutex.Unlock()
-	pool.all.Remove(transaction.Hash())
+	pool.forget(transaction)
 
 	sender, _ := types.Sender(transaction)
 
@@ -369,6 +580,62 @@ func (pool *TxPool) Remove(transaction *types.Transaction) {
 	}
 }
 
+// forget removes the tx from the pool index, the caller removes it from executable and pending queues
+func (pool *TxPool) forget(tx *types.Transaction) {
+	hash := tx.Hash()
+	if _, ok := pool.all.Get(hash); !ok {
+		return
+	}
+	pool.all.Remove(hash)
+	delete(pool.arrivals, hash)
+	delete(pool.added, hash)
+	delete(pool.rebroadcasts, hash)
+	delete(pool.localTxs, hash)
+	pool.size -= tx.Size()
+}
+
+func (pool *TxPool) expireLoop() {
+	for {
+		select {
+		case <-pool.stop:
+			return
+		case <-time.After(pool.cfg.TxExpiryInterval):
+		}
+		if expired := pool.expire(time.Now()); expired > 0 {
+			pool.log.Info("Expired pending txs have been removed", "count", expired)
+		}
+	}
+}
+
+// expire removes pending txs which stay in the pool longer than TxLifetime, local and priority txs are kept
+func (pool *TxPool) expire(now time.Time) int {
+	if pool.cfg.TxLifetime <= 0 {
+		return 0
+	}
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	expired := 0
+	for sender, pending := range pool.pendingTxs {
+		if pool.isLocal(sender) {
+			continue
+		}
+		for _, tx := range pending.List() {
+			if priorityTypes[tx.Type] || pool.isLocalTx(tx) {
+				continue
+			}
+			if added, ok := pool.added[tx.Hash()]; ok && now.Sub(added) > pool.cfg.TxLifetime {
+				pending.Remove(tx.Hash())
+				pool.forget(tx)
+				expired++
+			}
+		}
+		if pending.Empty() {
+			delete(pool.pendingTxs, sender)
+		}
+	}
+	return expired
+}
+
 func (pool *TxPool) movePendingTxsToExecutable() {
 	pool.mutex.Lock()
 	defer pool.mutex.Unlock()
@@ -415,6 +682,14 @@ func (pool *TxPool) ResetTo(block *types.Block) {
 
 	globalEpoch := pool.appState.State.Epoch()
 
+	pool.mutex.Lock()
+	for hash, epoch := range pool.evicted {
+		if epoch < globalEpoch {
+			delete(pool.evicted, hash)
+		}
+	}
+	pool.mutex.Unlock()
+
 	pool.appState.NonceCache.Lock()
 
 	pool.appState.NonceCache.Clear()
@@ -491,6 +766,8 @@ func (pool *TxPool) ResetTo(block *types.Block) {
 func (pool *TxPool) createBuildingContext() *buildingContext {
 	curNoncesPerSender := make(map[common.Address]uint32)
 	var txs []*types.Transaction
+	arrivals := make(map[common.Hash]uint64)
+	locals := make(map[common.Hash]struct{})
 	pool.mutex.Lock()
 	globalEpoch := pool.appState.State.Epoch()
 	withPriorityTx := false
@@ -500,7 +777,11 @@ func (pool *TxPool) createBuildingContext() *buildingContext {
 				continue
 			}
 			txs = append(txs, tx)
+			arrivals[tx.Hash()] = pool.arrivals[tx.Hash()]
 			withPriorityTx = withPriorityTx || priorityTypes[tx.Type]
+			if pool.isLocalTx(tx) {
+				locals[tx.Hash()] = struct{}{}
+			}
 		}
 		if pool.appState.State.GetEpoch(sender) < globalEpoch {
 			curNoncesPerSender[sender] = 0
@@ -513,6 +794,16 @@ func (pool *TxPool) createBuildingContext() *buildingContext {
 	sort.SliceStable(txs, func(i, j int) bool {
 		return txs[i].AccountNonce < txs[j].AccountNonce
 	})
+	txs = pool.ordering.Order(txs, arrivals)
+
+	var localTxs []*types.Transaction
+	if len(locals) > 0 {
+		for _, tx := range txs {
+			if _, ok := locals[tx.Hash()]; ok {
+				localTxs = append(localTxs, tx)
+			}
+		}
+	}
 
 	var priorityTxs []*types.Transaction
 	var sortedTxsPerSender map[common.Address][]*types.Transaction
@@ -527,7 +818,22 @@ func (pool *TxPool) createBuildingContext() *buildingContext {
 		}
 	}
 
-	return newBuildingContext(pool.appState, txs, priorityTxs, sortedTxsPerSender, curNoncesPerSender)
+	return newBuildingContext(pool.appState, txs, priorityTxs, sortedTxsPerSender, curNoncesPerSender, localTxs, pool.cfg.LocalsBlockSpace)
+}
+
+// Stop stops background loops and waits for txs being added and nonce cache updates in progress, txs are rejected after
+// the stop
+func (pool *TxPool) Stop() {
+	pool.mutex.Lock()
+	if !pool.stopped {
+		pool.stopped = true
+		close(pool.stop)
+	}
+	pool.mutex.Unlock()
+	if cache := pool.appState.NonceCache; cache != nil {
+		cache.Lock()
+		cache.UnLock()
+	}
 }
 
 func (pool *TxPool) StartSync() {
@@ -564,11 +870,15 @@ func (m *txMap) Get(hash common.Hash) (*types.Transaction, bool) {
 }
 
 func (m *txMap) Add(tx *types.Transaction) error {
+	return m.add(tx, false)
+}
+
+func (m *txMap) add(tx *types.Transaction, ignoreLimit bool) error {
 
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	if _, ok := priorityTypes[tx.Type]; !ok && m.Full() {
+	if _, ok := priorityTypes[tx.Type]; !ok && !ignoreLimit && m.Full() {
 		return setIsFullErr
 	}
 	m.txs[tx.Hash()] = tx
@@ -639,8 +949,12 @@ func newSortedTxs(maxTxs int) *sortedTxs {
 }
 
 func (s *sortedTxs) Add(tx *types.Transaction) error {
+	return s.add(tx, false)
+}
+
+func (s *sortedTxs) add(tx *types.Transaction, ignoreLimit bool) error {
 
-	if _, ok := priorityTypes[tx.Type]; !ok && s.Full() {
+	if _, ok := priorityTypes[tx.Type]; !ok && !ignoreLimit && s.Full() {
 		return setIsFullErr
 	}
 	if len(s.txs) > 0 && (tx.AccountNonce != s.txs[len(s.txs)-1].AccountNonce+1 || tx.Epoch != s.txs[len(s.txs)-1].Epoch) {
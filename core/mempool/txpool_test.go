@@ -12,7 +12,10 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/tendermint/tm-db"
 	"math/big"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestTxPool_addDeferredTx(t *testing.T) {
@@ -257,3 +260,294 @@ func TestTxMap_Sorted(t *testing.T) {
 	sorted = txMap.Sorted()
 	require.Equal(t, uint32(4), sorted[3].AccountNonce)
 }
+
+func TestTxPool_AddLocal(t *testing.T) {
+	pool := getPool()
+	pool.cfg.TxPoolAddrExecutableLimit = 2
+	pool.cfg.TxPoolAddrQueueLimit = 2
+	pool.cfg.RpcLocalTxs = 4
+
+	regularKey, _ := crypto.GenerateKey()
+	rpcKey, _ := crypto.GenerateKey()
+	nonLocalKey, _ := crypto.GenerateKey()
+	for _, key := range []*ecdsa.PrivateKey{regularKey, rpcKey, nonLocalKey} {
+		pool.appState.State.SetBalance(crypto.PubkeyToAddress(key.PublicKey), new(big.Int).Mul(big.NewInt(100), common.DnaBase))
+	}
+	pool.appState.Commit(nil)
+	pool.appState.Initialize(1)
+	pool.head = &types.Header{
+		EmptyBlockHeader: &types.EmptyBlockHeader{
+			Height: 1,
+		},
+	}
+	getTx := func(key *ecdsa.PrivateKey, nonce uint32) *types.Transaction {
+		address := crypto.PubkeyToAddress(key.PublicKey)
+		tx, _ := types.SignTx(&types.Transaction{
+			AccountNonce: nonce,
+			To:           &address,
+			Type:         types.SendTx,
+			Amount:       big.NewInt(1),
+		}, key)
+		return tx
+	}
+
+	var rpcTxs []*types.Transaction
+	for nonce := uint32(1); nonce <= 4; nonce++ {
+		require.NoError(t, pool.Add(getTx(regularKey, nonce)))
+		tx := getTx(rpcKey, nonce)
+		require.NoError(t, pool.AddLocal(tx))
+		rpcTxs = append(rpcTxs, tx)
+	}
+	require.Equal(t, MempoolFullError, pool.Add(getTx(regularKey, 5)))
+
+	// the sender of RPC txs doesn't become local, its raw txs above the bound pass regular limits
+	require.NoError(t, pool.AddLocal(getTx(rpcKey, 5)))
+	require.NoError(t, pool.Add(getTx(rpcKey, 6)))
+	require.Equal(t, MempoolFullError, pool.AddLocal(getTx(rpcKey, 7)))
+	stats := pool.Stats()
+	require.Zero(t, stats.Locals)
+	require.Equal(t, 4, stats.LocalTxs)
+	require.False(t, pool.Content()[crypto.PubkeyToAddress(rpcKey.PublicKey)].Local)
+
+	// the mark is dropped with the tx
+	pool.Remove(rpcTxs[0])
+	require.Equal(t, 3, pool.Stats().LocalTxs)
+
+	// RPC txs aren't local if disabled
+	pool.cfg.RpcLocalTxs = 0
+	for nonce := uint32(1); nonce <= 4; nonce++ {
+		require.NoError(t, pool.AddLocal(getTx(nonLocalKey, nonce)))
+	}
+	require.Equal(t, MempoolFullError, pool.AddLocal(getTx(nonLocalKey, 5)))
+	require.Equal(t, 3, pool.Stats().LocalTxs)
+}
+
+func TestTxPool_Evict(t *testing.T) {
+	pool := getPool()
+	key, _ := crypto.GenerateKey()
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	pool.appState.State.SetBalance(address, new(big.Int).Mul(big.NewInt(100), common.DnaBase))
+	pool.appState.Commit(nil)
+	pool.appState.Initialize(1)
+	pool.head = &types.Header{
+		EmptyBlockHeader: &types.EmptyBlockHeader{
+			Height: 1,
+		},
+	}
+	var txs []*types.Transaction
+	for nonce := uint32(1); nonce <= 3; nonce++ {
+		tx, _ := types.SignTx(&types.Transaction{
+			AccountNonce: nonce,
+			To:           &address,
+			Type:         types.SendTx,
+			Amount:       big.NewInt(1),
+		}, key)
+		require.NoError(t, pool.AddLocal(tx))
+		txs = append(txs, tx)
+	}
+	stats := pool.Stats()
+	require.Equal(t, 3, stats.Executable)
+	require.Equal(t, 3, stats.LocalTxs)
+
+	evicted, err := pool.Evict(txs[1].Hash())
+	require.NoError(t, err)
+	require.Equal(t, txs[1].Hash(), evicted.Hash())
+	_, err = pool.Evict(txs[1].Hash())
+	require.Error(t, err)
+
+	content := pool.Content()[address]
+	require.Equal(t, []*types.Transaction{txs[0]}, content.Executable)
+	require.Equal(t, []*types.Transaction{txs[2]}, content.Pending)
+
+	stats = pool.Stats()
+	require.Equal(t, 2, stats.Txs)
+	require.Equal(t, 1, stats.Evicted)
+	require.Equal(t, EvictedTxError, pool.Add(txs[1]))
+}
+
+func TestTxPool_LimitsAndExpiry(t *testing.T) {
+	pool := getPool()
+	regularKey, _ := crypto.GenerateKey()
+	localKey, _ := crypto.GenerateKey()
+	for _, key := range []*ecdsa.PrivateKey{regularKey, localKey} {
+		pool.appState.State.SetBalance(crypto.PubkeyToAddress(key.PublicKey), new(big.Int).Mul(big.NewInt(100), common.DnaBase))
+	}
+	pool.appState.Commit(nil)
+	pool.appState.Initialize(1)
+	pool.head = &types.Header{
+		EmptyBlockHeader: &types.EmptyBlockHeader{
+			Height: 1,
+		},
+	}
+	getTx := func(key *ecdsa.PrivateKey, nonce uint32, payload []byte) *types.Transaction {
+		address := crypto.PubkeyToAddress(key.PublicKey)
+		tx, _ := types.SignTx(&types.Transaction{
+			AccountNonce: nonce,
+			To:           &address,
+			Type:         types.SendTx,
+			Amount:       big.NewInt(1),
+			Payload:      payload,
+		}, key)
+		return tx
+	}
+
+	pool.cfg.TxMaxPayloadSize = 10
+	require.Error(t, pool.Add(getTx(regularKey, 1, make([]byte, 11))))
+
+	tx1 := getTx(regularKey, 1, nil)
+	require.NoError(t, pool.Add(tx1))
+	require.Equal(t, tx1.Size(), pool.Stats().Size)
+
+	pool.cfg.TxPoolMaxBytes = tx1.Size() * 2
+	// nonce gap makes txs pending
+	pending := getTx(regularKey, 3, nil)
+	require.NoError(t, pool.Add(pending))
+	require.Error(t, pool.Add(getTx(regularKey, 4, nil)))
+	localPending := getTx(localKey, 3, nil)
+	require.NoError(t, pool.AddLocal(localPending))
+
+	pool.cfg.TxPoolMaxBytes = 0
+	pool.cfg.TxPoolMaxSize = 3
+	require.Equal(t, "tx queue max size reached", pool.Add(getTx(regularKey, 4, nil)).Error())
+
+	require.Zero(t, pool.expire(time.Now().Add(time.Hour)))
+	pool.cfg.TxLifetime = time.Hour * 3
+	require.Zero(t, pool.expire(time.Now()))
+	require.Equal(t, 1, pool.expire(time.Now().Add(pool.cfg.TxLifetime+time.Second)))
+	require.Nil(t, pool.GetTx(pending.Hash()))
+	require.NotNil(t, pool.GetTx(tx1.Hash()))
+	require.NotNil(t, pool.GetTx(localPending.Hash()))
+	require.Equal(t, tx1.Size()+localPending.Size(), pool.Stats().Size)
+}
+
+// BenchmarkTxPool_Add measures parallel admission of txs of distinct senders into the pool holding 10k pending txs
+func BenchmarkTxPool_Add(b *testing.B) {
+	const (
+		senders      = 1000
+		txsPerSender = 10
+	)
+	pool := getPool()
+	pool.cfg.TxPoolQueueSlots = 0
+	pool.cfg.TxPoolExecutableSlots = 0
+	pool.cfg.TxPoolAddrQueueLimit = b.N + txsPerSender
+	pool.cfg.TxPoolAddrExecutableLimit = b.N + txsPerSender
+	pool.cfg.TxPoolMaxSize = senders*txsPerSender + b.N
+
+	keys := make([]*ecdsa.PrivateKey, senders)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		pool.appState.State.SetBalance(crypto.PubkeyToAddress(keys[i].PublicKey), new(big.Int).Mul(big.NewInt(100), common.DnaBase))
+	}
+	pool.appState.Commit(nil)
+	pool.appState.Initialize(1)
+	pool.head = &types.Header{
+		EmptyBlockHeader: &types.EmptyBlockHeader{
+			Height: 1,
+		},
+	}
+	getTx := func(key *ecdsa.PrivateKey, nonce uint32) *types.Transaction {
+		address := crypto.PubkeyToAddress(key.PublicKey)
+		tx, _ := types.SignTx(&types.Transaction{
+			AccountNonce: nonce,
+			To:           &address,
+			Type:         types.SendTx,
+			Amount:       big.NewInt(1),
+		}, key)
+		return tx
+	}
+	for _, key := range keys {
+		for nonce := uint32(1); nonce <= txsPerSender; nonce++ {
+			require.NoError(b, pool.Add(getTx(key, nonce)))
+		}
+	}
+	require.Equal(b, senders*txsPerSender, pool.Stats().Txs)
+
+	txs := make([]*types.Transaction, b.N)
+	for i := range txs {
+		txs[i] = getTx(keys[i%senders], uint32(txsPerSender+1+i/senders))
+		types.Sender(txs[i])
+	}
+	var next int64 = -1
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			pool.Add(txs[atomic.AddInt64(&next, 1)])
+		}
+	})
+}
+
+func TestTxPool_ConcurrentAdd(t *testing.T) {
+	pool := getPool()
+	key, _ := crypto.GenerateKey()
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	pool.appState.State.SetBalance(address, new(big.Int).Mul(big.NewInt(100), common.DnaBase))
+	pool.appState.Commit(nil)
+	pool.appState.Initialize(1)
+	pool.head = &types.Header{
+		EmptyBlockHeader: &types.EmptyBlockHeader{
+			Height: 1,
+		},
+	}
+	tx, _ := types.SignTx(&types.Transaction{
+		AccountNonce: 1,
+		To:           &address,
+		Type:         types.SendTx,
+		Amount:       big.NewInt(1),
+	}, key)
+
+	const callers = 10
+	var added, duplicates int32
+	var wg sync.WaitGroup
+	// callers pass the lock-free duplicate check and validation while the pool is locked
+	pool.mutex.Lock()
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			switch pool.Add(tx) {
+			case nil:
+				atomic.AddInt32(&added, 1)
+			case DuplicateTxError:
+				atomic.AddInt32(&duplicates, 1)
+			}
+		}()
+	}
+	time.Sleep(time.Millisecond * 100)
+	pool.mutex.Unlock()
+	wg.Wait()
+
+	require.Equal(t, int32(1), added)
+	require.Equal(t, int32(callers-1), duplicates)
+	stats := pool.Stats()
+	require.Equal(t, 1, stats.Txs)
+	require.Equal(t, tx.Size(), stats.Size)
+}
+
+func TestTxPool_Stop(t *testing.T) {
+	pool := getPool()
+	key, _ := crypto.GenerateKey()
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	pool.appState.State.SetBalance(address, new(big.Int).Mul(big.NewInt(100), common.DnaBase))
+	pool.appState.Commit(nil)
+	pool.appState.Initialize(1)
+	pool.head = &types.Header{
+		EmptyBlockHeader: &types.EmptyBlockHeader{
+			Height: 1,
+		},
+	}
+	getTx := func(nonce uint32) *types.Transaction {
+		tx, _ := types.SignTx(&types.Transaction{
+			AccountNonce: nonce,
+			To:           &address,
+			Type:         types.SendTx,
+			Amount:       big.NewInt(1),
+		}, key)
+		return tx
+	}
+	require.NoError(t, pool.Add(getTx(1)))
+
+	pool.Stop()
+	pool.Stop()
+	require.Equal(t, StoppedPoolError, pool.Add(getTx(2)))
+	require.Equal(t, 1, pool.Stats().Txs)
+}
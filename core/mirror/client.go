@@ -0,0 +1,130 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/core/state/snapshot"
+	"github.com/idena-network/idena-go/ipfs"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const (
+	maxBodySize = 50 * 1024 * 1024
+	// maxSnapshotSize bounds the snapshot loaded from the mirror since the manifest has no size, larger responses are
+	// rejected before the snapshot is verified by its cid
+	maxSnapshotSize = 2 * 1024 * 1024 * 1024
+	// idleTimeout cancels the snapshot loading if no data is received
+	idleTimeout = time.Minute
+)
+
+// Client loads block bodies and snapshots from the HTTP mirror
+type Client struct {
+	url  string
+	ipfs ipfs.Proxy
+	http *http.Client
+}
+
+func NewClient(url string, ipfsProxy ipfs.Proxy) *Client {
+	return &Client{
+		url:  url,
+		ipfs: ipfsProxy,
+		http: &http.Client{},
+	}
+}
+
+func (c *Client) get(ctx context.Context, path string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, c.url+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errors.Errorf("mirror responded with status %v", resp.Status)
+	}
+	return resp, nil
+}
+
+// Body loads the body of the block, the body is verified by the ipfs hash of the header
+func (c *Client) Body(header *types.Header) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+	resp, err := c.get(ctx, blocksPath+header.Hash().Hex()+bodySuffix)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, err
+	}
+	expected := header.IpfsHash()
+	if len(expected) == 0 && len(data) == 0 {
+		return data, nil
+	}
+	actual, err := c.ipfs.Cid(data)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(actual.Bytes(), expected) {
+		return nil, errors.New("block body doesn't match ipfs hash of the header")
+	}
+	return data, nil
+}
+
+// LoadSnapshot writes the snapshot of the manifest to the writer, the caller must verify the snapshot by its cid
+func (c *Client) LoadSnapshot(manifest *snapshot.Manifest, to io.Writer, onLoading func(size, loaded int64)) error {
+	return c.loadSnapshot(manifest, to, onLoading, maxSnapshotSize)
+}
+
+func (c *Client) loadSnapshot(manifest *snapshot.Manifest, to io.Writer, onLoading func(size, loaded int64), limit int64) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	idle := time.AfterFunc(idleTimeout, cancel)
+	defer idle.Stop()
+
+	resp, err := c.get(ctx, snapshotPath+hexutil.Encode(manifest.Cid))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.ContentLength > limit {
+		return errors.Errorf("snapshot size %v exceeds the limit %v", resp.ContentLength, limit)
+	}
+	reader := &progressReader{r: io.LimitReader(resp.Body, limit+1), size: resp.ContentLength, onRead: func(size, loaded int64) {
+		idle.Reset(idleTimeout)
+		onLoading(size, loaded)
+	}}
+	written, err := io.Copy(to, reader)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("cannot load snapshot from %v", c.url))
+	}
+	if written > limit {
+		return errors.Errorf("snapshot size exceeds the limit %v", limit)
+	}
+	return nil
+}
+
+type progressReader struct {
+	r      io.Reader
+	size   int64
+	loaded int64
+	onRead func(size, loaded int64)
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.loaded += int64(n)
+	r.onRead(r.size, r.loaded)
+	return n, err
+}
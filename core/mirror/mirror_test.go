@@ -0,0 +1,80 @@
+package mirror
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/core/state/snapshot"
+	"github.com/idena-network/idena-go/database"
+	"github.com/idena-network/idena-go/ipfs"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestServerAndClient(t *testing.T) {
+	db := dbm.NewMemDB()
+	proxy := ipfs.NewMemoryIpfsProxy()
+	repo := database.NewRepo(db)
+
+	body := (&types.Body{Transactions: []*types.Transaction{{AccountNonce: 1}}}).Bytes()
+	bodyCid, err := proxy.Add(body, true)
+	require.NoError(t, err)
+	header := &types.Header{ProposedHeader: &types.ProposedHeader{Height: 2, IpfsHash: bodyCid.Bytes()}}
+	repo.WriteBlockHeader(header)
+	repo.WriteCanonicalHash(2, header.Hash())
+	forked := &types.Header{ProposedHeader: &types.ProposedHeader{Height: 2, IpfsHash: bodyCid.Bytes(), Time: big.NewInt(1)}}
+	repo.WriteBlockHeader(forked)
+
+	dir, err := ioutil.TempDir("", "mirror")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	snapshotFile := filepath.Join(dir, "2.tar")
+	require.NoError(t, ioutil.WriteFile(snapshotFile, []byte("snapshot"), 0600))
+	snapshotCid := []byte{0x1, 0x55, 0x1, 0x2}
+	repo.WriteLastSnapshotManifest(snapshotCid, common.Hash{0x1}, 2, snapshotFile)
+
+	server := httptest.NewServer(NewServer(db, proxy))
+	defer server.Close()
+	client := NewClient(server.URL, proxy)
+
+	data, err := client.Body(header)
+	require.NoError(t, err)
+	require.Equal(t, body, data)
+
+	_, err = client.Body(forked)
+	require.Error(t, err)
+
+	tampered := &types.Header{ProposedHeader: &types.ProposedHeader{Height: 2, IpfsHash: []byte{0x1}}}
+	_, err = client.Body(tampered)
+	require.Error(t, err)
+
+	resp, err := http.Get(server.URL + manifestPath)
+	require.NoError(t, err)
+	manifest := new(Manifest)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(manifest))
+	resp.Body.Close()
+	require.Equal(t, uint64(2), manifest.Height)
+	require.Equal(t, hexutil.Bytes(snapshotCid), manifest.Cid)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, client.LoadSnapshot(&snapshot.Manifest{Cid: snapshotCid}, buf, func(size, loaded int64) {}))
+	require.Equal(t, "snapshot", buf.String())
+	require.Error(t, client.LoadSnapshot(&snapshot.Manifest{Cid: []byte{0x2}}, buf, func(size, loaded int64) {}))
+	buf.Reset()
+	require.Error(t, client.loadSnapshot(&snapshot.Manifest{Cid: snapshotCid}, buf, func(size, loaded int64) {}, 3))
+	require.Empty(t, buf.String())
+
+	resp, err = http.Get(server.URL + snapshotPath + hexutil.Encode(snapshotCid))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, immutableCache, resp.Header.Get("Cache-Control"))
+}
@@ -0,0 +1,153 @@
+// Package mirror serves content-addressed chain data over plain HTTP so it can be cached by CDNs and loads it back
+// during sync. The data is trusted only after it is verified against hashes taken from validated headers and manifests.
+package mirror
+
+import (
+	"encoding/json"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/database"
+	"github.com/idena-network/idena-go/ipfs"
+	"github.com/idena-network/idena-go/log"
+	"github.com/ipfs/go-cid"
+	dbm "github.com/tendermint/tm-db"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	immutableCache = "public, max-age=31536000, immutable"
+	manifestCache  = "public, max-age=60"
+
+	manifestPath = "/v1/manifest"
+	blocksPath   = "/v1/blocks/"
+	bodySuffix   = "/body"
+	snapshotPath = "/v1/snapshots/"
+)
+
+type Manifest struct {
+	Height uint64        `json:"height"`
+	Root   common.Hash   `json:"root"`
+	Cid    hexutil.Bytes `json:"cid"`
+}
+
+// Server is the read-only HTTP endpoint of canonical block bodies and the last snapshot
+type Server struct {
+	repo     *database.Repo
+	ipfs     ipfs.Proxy
+	log      log.Logger
+	listener net.Listener
+}
+
+func NewServer(db dbm.DB, ipfsProxy ipfs.Proxy) *Server {
+	return &Server{
+		repo: database.NewRepo(db),
+		ipfs: ipfsProxy,
+		log:  log.New("component", "mirror"),
+	}
+}
+
+func (s *Server) Start(endpoint string) error {
+	listener, err := net.Listen("tcp", endpoint)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	server := &http.Server{
+		Handler:      s,
+		ReadTimeout:  time.Second * 30,
+		WriteTimeout: time.Hour,
+	}
+	go server.Serve(listener)
+	s.log.Info("HTTP mirror opened", "url", "http://"+endpoint)
+	return nil
+}
+
+func (s *Server) Stop() {
+	if s.listener != nil {
+		s.listener.Close()
+		s.listener = nil
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	switch path := r.URL.Path; {
+	case path == manifestPath:
+		s.serveManifest(w)
+	case strings.HasPrefix(path, blocksPath) && strings.HasSuffix(path, bodySuffix):
+		s.serveBody(w, r, strings.TrimSuffix(strings.TrimPrefix(path, blocksPath), bodySuffix))
+	case strings.HasPrefix(path, snapshotPath):
+		s.serveSnapshot(w, r, strings.TrimPrefix(path, snapshotPath))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) serveManifest(w http.ResponseWriter) {
+	snapshotCid, root, height, _ := s.repo.LastSnapshotManifest()
+	if snapshotCid == nil {
+		http.Error(w, "snapshot is not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", manifestCache)
+	json.NewEncoder(w).Encode(&Manifest{Height: height, Root: root, Cid: snapshotCid})
+}
+
+func (s *Server) serveBody(w http.ResponseWriter, r *http.Request, hashHex string) {
+	hash := common.HexToHash(hashHex)
+	header := s.repo.ReadBlockHeader(hash)
+	if header == nil || s.repo.ReadCanonicalHash(header.Height()) != hash {
+		http.Error(w, "canonical block is not found", http.StatusNotFound)
+		return
+	}
+	var data []byte
+	if header.ProposedHeader != nil {
+		var err error
+		if data, err = s.ipfs.Get(header.ProposedHeader.IpfsHash); err != nil {
+			s.log.Warn("Cannot read block body", "hash", hash.Hex(), "err", err)
+			http.Error(w, "block body is not available", http.StatusServiceUnavailable)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Cache-Control", immutableCache)
+	w.Header().Set("ETag", `"`+hash.Hex()+`"`)
+	w.Write(data)
+}
+
+func (s *Server) serveSnapshot(w http.ResponseWriter, r *http.Request, cidHex string) {
+	requested, err := hexutil.Decode(cidHex)
+	if err != nil {
+		http.Error(w, "invalid cid", http.StatusBadRequest)
+		return
+	}
+	snapshotCid, _, _, fileName := s.repo.LastSnapshotManifest()
+	if snapshotCid == nil || string(snapshotCid) != string(requested) {
+		http.Error(w, "snapshot is not found", http.StatusNotFound)
+		return
+	}
+	file, err := os.Open(fileName)
+	if err != nil {
+		http.Error(w, "snapshot is not available", http.StatusServiceUnavailable)
+		return
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		http.Error(w, "snapshot is not available", http.StatusServiceUnavailable)
+		return
+	}
+	c, _ := cid.Cast(snapshotCid)
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.Header().Set("Cache-Control", immutableCache)
+	w.Header().Set("ETag", `"`+c.String()+`"`)
+	http.ServeContent(w, r, "", stat.ModTime(), file)
+}
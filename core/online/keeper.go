@@ -0,0 +1,226 @@
+package online
+
+import (
+	"fmt"
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/blockchain/attachments"
+	"github.com/idena-network/idena-go/blockchain/fee"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/mempool"
+	"github.com/idena-network/idena-go/core/standby"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/database"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/log"
+	"github.com/idena-network/idena-go/secstore"
+	"github.com/pborman/uuid"
+	"github.com/shopspring/decimal"
+	dbm "github.com/tendermint/tm-db"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+)
+
+type Status struct {
+	Enabled     bool
+	Online      bool
+	Intent      bool
+	LockOwner   string
+	NodeId      string
+	LastAttempt time.Time
+	LastTx      common.Hash
+	LastError   error
+}
+
+// StatusKeeper sends online status tx when the identity which is expected to be online has been marked offline
+type StatusKeeper struct {
+	cfg      *config.OnlineKeeperConfig
+	repo     *database.Repo
+	appState *appstate.AppState
+	txpool   *mempool.TxPool
+	secStore *secstore.SecStore
+	bus      eventbus.Bus
+	syncing  func() bool
+	standby  *standby.Guard
+	lock     *FileLock
+	nodeId   string
+	log      log.Logger
+	blocks   chan *types.Block
+
+	mutex       sync.Mutex
+	lastAttempt time.Time
+	lastTx      common.Hash
+	lastErr     error
+}
+
+func NewStatusKeeper(cfg *config.OnlineKeeperConfig, db dbm.DB, appState *appstate.AppState, txpool *mempool.TxPool,
+	secStore *secstore.SecStore, bus eventbus.Bus, syncing func() bool, standby *standby.Guard) *StatusKeeper {
+	hostname, _ := os.Hostname()
+	nodeId := fmt.Sprintf("%v-%v", hostname, uuid.NewRandom().String()[:8])
+	return &StatusKeeper{
+		cfg:      cfg,
+		repo:     database.NewRepo(db),
+		appState: appState,
+		txpool:   txpool,
+		secStore: secStore,
+		bus:      bus,
+		syncing:  syncing,
+		standby:  standby,
+		lock:     NewFileLock(cfg.LockFile, nodeId, cfg.LockTTL),
+		nodeId:   nodeId,
+		log:      log.New("component", "online-keeper"),
+		blocks:   make(chan *types.Block, 100),
+	}
+}
+
+func (k *StatusKeeper) Start() {
+	if !k.cfg.Enabled {
+		return
+	}
+	_ = k.bus.Subscribe(events.AddBlockEventID,
+		func(e eventbus.Event) {
+			select {
+			case k.blocks <- e.(*events.NewBlockEvent).Block:
+			default:
+			}
+		})
+	go k.loop()
+}
+
+// loop checks the status on every block and periodically, so the identity switched offline while blocks events
+// were missed (sync, full events queue or no blocks for a while) is turned online as well
+func (k *StatusKeeper) loop() {
+	interval := k.cfg.RetryInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case block := <-k.blocks:
+			k.processBlock(block)
+		case <-ticker.C:
+			k.check()
+		}
+	}
+}
+
+func (k *StatusKeeper) isOnline(addr common.Address) bool {
+	isOnline := k.appState.ValidatorsCache.IsOnlineIdentity(addr)
+	if k.appState.State.HasStatusSwitchAddresses(addr) {
+		return !isOnline
+	}
+	return isOnline
+}
+
+func (k *StatusKeeper) processBlock(block *types.Block) {
+	addr := k.secStore.GetAddress()
+	for _, tx := range block.Body.Transactions {
+		if tx.Type != types.OnlineStatusTx {
+			continue
+		}
+		if sender, _ := types.Sender(tx); sender != addr {
+			continue
+		}
+		if attachment := attachments.ParseOnlineStatusAttachment(tx); attachment != nil {
+			k.repo.WriteOnlineIntent(attachment.Online)
+		}
+	}
+	k.check()
+}
+
+func (k *StatusKeeper) check() {
+	addr := k.secStore.GetAddress()
+	if k.syncing() || k.standby.IsStandby() || !k.appState.ValidatorsCache.Contains(addr) {
+		return
+	}
+	if k.isOnline(addr) {
+		if !k.repo.ReadOnlineIntent() {
+			// identity was turned online before the keeper has been enabled
+			k.repo.WriteOnlineIntent(true)
+		}
+		return
+	}
+	if !k.repo.ReadOnlineIntent() {
+		return
+	}
+	if k.appState.State.ValidationPeriod() >= state.FlipLotteryPeriod {
+		return
+	}
+	for _, tx := range k.txpool.GetPendingByAddress(addr) {
+		if tx.Type == types.OnlineStatusTx {
+			return
+		}
+	}
+
+	k.mutex.Lock()
+	if time.Since(k.lastAttempt) < k.cfg.RetryInterval {
+		k.mutex.Unlock()
+		return
+	}
+	k.lastAttempt = time.Now()
+	k.mutex.Unlock()
+
+	acquired, err := k.lock.Acquire()
+	if err != nil {
+		k.log.Warn("Cannot acquire online status lock", "err", err)
+		k.setResult(common.Hash{}, err)
+		return
+	}
+	if !acquired {
+		k.log.Info("Online status lock is held by another node, skip sending tx")
+		return
+	}
+
+	hash, err := k.sendOnlineTx(addr)
+	k.setResult(hash, err)
+	if err != nil {
+		k.log.Warn("Cannot send online status tx", "err", err)
+		return
+	}
+	k.log.Info("Identity was marked offline, online status tx sent", "hash", hash.Hex())
+}
+
+func (k *StatusKeeper) sendOnlineTx(addr common.Address) (common.Hash, error) {
+	payload := attachments.CreateOnlineStatusAttachment(true)
+	tx := blockchain.BuildTx(k.appState, addr, nil, types.OnlineStatusTx, decimal.Zero, decimal.Zero, decimal.Zero, 0, 0, payload)
+	txFee := fee.CalculateFee(k.appState.ValidatorsCache.NetworkSize(), k.appState.State.FeePerByte(), tx)
+	tx.MaxFee = new(big.Int).Mul(txFee, big.NewInt(2))
+	signedTx, err := k.secStore.SignTx(tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := k.txpool.Add(signedTx); err != nil {
+		return common.Hash{}, err
+	}
+	return signedTx.Hash(), nil
+}
+
+func (k *StatusKeeper) setResult(hash common.Hash, err error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	k.lastTx = hash
+	k.lastErr = err
+}
+
+func (k *StatusKeeper) Status() *Status {
+	addr := k.secStore.GetAddress()
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	return &Status{
+		Enabled:     k.cfg.Enabled,
+		Online:      k.isOnline(addr),
+		Intent:      k.repo.ReadOnlineIntent(),
+		LockOwner:   k.lock.Owner(),
+		NodeId:      k.nodeId,
+		LastAttempt: k.lastAttempt,
+		LastTx:      k.lastTx,
+		LastError:   k.lastErr,
+	}
+}
@@ -0,0 +1,87 @@
+package online
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/mempool"
+	"github.com/idena-network/idena-go/core/standby"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/secstore"
+	"github.com/stretchr/testify/require"
+	db "github.com/tendermint/tm-db"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStatusKeeper_processBlock(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "online-keeper")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	memDb := db.NewMemDB()
+	bus := eventbus.New()
+	appState := appstate.NewAppState(memDb, bus)
+	key, _ := crypto.GenerateKey()
+	secStore := secstore.NewSecStore()
+	secStore.AddKey(crypto.FromECDSA(key))
+	addr := secStore.GetAddress()
+
+	appState.State.SetState(addr, state.Verified)
+	appState.State.SetBalance(addr, new(big.Int).Mul(common.DnaBase, big.NewInt(100)))
+	appState.IdentityState.Add(addr)
+	require.NoError(appState.Commit(nil))
+	require.NoError(appState.Initialize(0))
+
+	pool := mempool.NewTxPool(appState, bus, config.GetDefaultMempoolConfig(), big.NewInt(0))
+	cfg := &config.OnlineKeeperConfig{
+		Enabled:  true,
+		LockFile: filepath.Join(dir, "online.lock"),
+		LockTTL:  time.Minute,
+	}
+	keeper := NewStatusKeeper(cfg, memDb, appState, pool, secStore, bus, func() bool { return false },
+		standby.NewGuard(false, memDb))
+	block := &types.Block{
+		Header: &types.Header{EmptyBlockHeader: &types.EmptyBlockHeader{Height: 1}},
+		Body:   &types.Body{},
+	}
+	pool.Initialize(block.Header, addr)
+	pending := func() []*types.Transaction {
+		return pool.GetPendingByAddress(addr)
+	}
+
+	// there is no intent to be online
+	keeper.processBlock(block)
+	require.Empty(pending())
+
+	// the lease is held by the standby node
+	keeper.repo.WriteOnlineIntent(true)
+	acquired, err := NewFileLock(cfg.LockFile, "standby", time.Minute).Acquire()
+	require.NoError(err)
+	require.True(acquired)
+	keeper.processBlock(block)
+	require.Empty(pending())
+	require.Equal("standby", keeper.Status().LockOwner)
+
+	require.NoError(os.Remove(cfg.LockFile))
+	keeper.processBlock(block)
+	require.Len(pending(), 1)
+	require.Equal(types.OnlineStatusTx, pending()[0].Type)
+	status := keeper.Status()
+	require.NoError(status.LastError)
+	require.Equal(pending()[0].Hash(), status.LastTx)
+	require.Equal(keeper.nodeId, status.LockOwner)
+
+	// online status tx is pending already
+	keeper.check()
+	require.Len(pending(), 1)
+}
@@ -0,0 +1,116 @@
+package online
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// guardTimeout is the age of the guard file after which it is considered left by a crashed node
+const guardTimeout = 30 * time.Second
+
+type lockInfo struct {
+	Owner   string `json:"owner"`
+	Expires int64  `json:"expires"`
+}
+
+// FileLock is a lease stored in a file which is shared between active and hot-standby nodes.
+// Empty path means there is no standby node and the lock is always acquired.
+type FileLock struct {
+	path  string
+	owner string
+	ttl   time.Duration
+}
+
+func NewFileLock(path string, owner string, ttl time.Duration) *FileLock {
+	return &FileLock{
+		path:  path,
+		owner: owner,
+		ttl:   ttl,
+	}
+}
+
+func (l *FileLock) read() *lockInfo {
+	data, err := ioutil.ReadFile(l.path)
+	if err != nil {
+		return nil
+	}
+	info := new(lockInfo)
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil
+	}
+	return info
+}
+
+// lockGuard exclusively creates the guard file, the lease is read and replaced only by the guard holder
+func (l *FileLock) lockGuard() (bool, error) {
+	guard := l.path + ".guard"
+	for i := 0; i < 2; i++ {
+		file, err := os.OpenFile(guard, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			return true, file.Close()
+		}
+		if !os.IsExist(err) {
+			return false, err
+		}
+		stat, err := os.Stat(guard)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return false, err
+		}
+		if time.Since(stat.ModTime()) < guardTimeout {
+			return false, nil
+		}
+		if err := os.Remove(guard); err != nil && !os.IsNotExist(err) {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// Acquire takes or prolongs the lease, it fails if the lease is held by another owner and is not expired or
+// another node is acquiring it at the moment
+func (l *FileLock) Acquire() (bool, error) {
+	if l.path == "" {
+		return true, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), os.ModePerm); err != nil {
+		return false, err
+	}
+	if locked, err := l.lockGuard(); !locked || err != nil {
+		return false, err
+	}
+	defer os.Remove(l.path + ".guard")
+
+	now := time.Now()
+	if info := l.read(); info != nil && info.Owner != l.owner && info.Expires > now.Unix() {
+		return false, nil
+	}
+	data, _ := json.Marshal(&lockInfo{
+		Owner:   l.owner,
+		Expires: now.Add(l.ttl).Unix(),
+	})
+	tmp := l.path + "." + l.owner
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return false, err
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Owner returns the current lease holder or empty string if the lease is free
+func (l *FileLock) Owner() string {
+	if l.path == "" {
+		return l.owner
+	}
+	if info := l.read(); info != nil && info.Expires > time.Now().Unix() {
+		return info.Owner
+	}
+	return ""
+}
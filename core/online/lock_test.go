@@ -0,0 +1,62 @@
+package online
+
+import (
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileLock_Acquire(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "online-lock")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "online.lock")
+
+	active := NewFileLock(path, "active", time.Minute)
+	standby := NewFileLock(path, "standby", time.Minute)
+
+	acquired, err := active.Acquire()
+	require.NoError(err)
+	require.True(acquired)
+
+	acquired, err = standby.Acquire()
+	require.NoError(err)
+	require.False(acquired)
+	require.Equal("active", standby.Owner())
+
+	acquired, err = active.Acquire()
+	require.NoError(err)
+	require.True(acquired)
+
+	expired := NewFileLock(path, "active", -time.Minute)
+	_, err = expired.Acquire()
+	require.NoError(err)
+	require.Equal("", standby.Owner())
+
+	acquired, err = standby.Acquire()
+	require.NoError(err)
+	require.True(acquired)
+
+	guard := path + ".guard"
+	require.NoError(ioutil.WriteFile(guard, nil, 0644))
+	acquired, err = standby.Acquire()
+	require.NoError(err)
+	require.False(acquired)
+
+	stale := time.Now().Add(-2 * guardTimeout)
+	require.NoError(os.Chtimes(guard, stale, stale))
+	acquired, err = standby.Acquire()
+	require.NoError(err)
+	require.True(acquired)
+	_, err = os.Stat(guard)
+	require.True(os.IsNotExist(err))
+
+	acquired, err = NewFileLock("", "any", time.Minute).Acquire()
+	require.NoError(err)
+	require.True(acquired)
+}
@@ -0,0 +1,230 @@
+package penalty
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/epochreport"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/log"
+	"github.com/idena-network/idena-go/secstore"
+	"github.com/pkg/errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const badFlipsEpochsToCheck = 2
+
+type ceremony interface {
+	ShortAnswersSent() bool
+}
+
+type alert struct {
+	Address common.Address `json:"address"`
+	Warning
+}
+
+// Monitor detects conditions which lead to penalties or lost rewards of the node identity and reports them
+// before the penalty is applied
+type Monitor struct {
+	cfg          *config.PenaltyMonitorConfig
+	appState     *appstate.AppState
+	secStore     *secstore.SecStore
+	bus          eventbus.Bus
+	ceremony     ceremony
+	epochReports *epochreport.Builder
+	connected    func() bool
+	client       *http.Client
+	log          log.Logger
+
+	mutex             sync.Mutex
+	warnings          map[string]*Warning
+	disconnectedSince time.Time
+	offlineProposed   bool
+}
+
+func NewMonitor(cfg *config.PenaltyMonitorConfig, appState *appstate.AppState, secStore *secstore.SecStore,
+	bus eventbus.Bus, ceremony ceremony, epochReports *epochreport.Builder, connected func() bool) *Monitor {
+	return &Monitor{
+		cfg:          cfg,
+		appState:     appState,
+		secStore:     secStore,
+		bus:          bus,
+		ceremony:     ceremony,
+		epochReports: epochReports,
+		connected:    connected,
+		client:       &http.Client{Timeout: cfg.Timeout},
+		log:          log.New("component", "penalty-monitor"),
+		warnings:     make(map[string]*Warning),
+	}
+}
+
+func (m *Monitor) Start() {
+	_ = m.bus.Subscribe(events.AddBlockEventID,
+		func(e eventbus.Event) {
+			m.processBlock(e.(*events.NewBlockEvent).Block)
+		})
+	go m.loop()
+}
+
+func (m *Monitor) loop() {
+	interval := m.cfg.CheckInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		m.check()
+	}
+}
+
+func (m *Monitor) processBlock(block *types.Block) {
+	addr := m.secStore.GetAddress()
+	proposed := block.Header.Flags().HasFlag(types.OfflinePropose) && block.Header.OfflineAddr() != nil &&
+		*block.Header.OfflineAddr() == addr
+	m.mutex.Lock()
+	changed := m.offlineProposed != proposed
+	m.offlineProposed = proposed
+	m.mutex.Unlock()
+	if changed {
+		m.check()
+	}
+}
+
+func (m *Monitor) collect(now time.Time) conditions {
+	addr := m.secStore.GetAddress()
+	s := m.appState.State
+	period := s.ValidationPeriod()
+	c := conditions{
+		Online:          m.appState.ValidatorsCache.IsOnlineIdentity(addr),
+		Candidate:       state.IsCeremonyCandidate(s.GetIdentity(addr)),
+		Ceremony:        period != state.NonePeriod,
+		LongSession:     period == state.LongSessionPeriod,
+		UntilValidation: s.NextValidationTime().Sub(now),
+		AnswersSent:     m.ceremony.ShortAnswersSent(),
+		RequiredFlips:   s.GetRequiredFlips(addr),
+		MadeFlips:       s.GetMadeFlips(addr),
+	}
+	epoch := s.Epoch()
+	for i := uint16(1); i <= badFlipsEpochsToCheck && i <= epoch; i++ {
+		report := m.epochReports.Report(epoch-i, addr)
+		if report == nil {
+			continue
+		}
+		if i == 1 && report.Validation != nil && report.Validation.Missed {
+			c.MissedLast = true
+		}
+		if report.Flips != nil && report.Flips.BadAuthor {
+			c.BadFlipsEpochs++
+		}
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	c.Connected = m.connected()
+	if c.Connected {
+		m.disconnectedSince = time.Time{}
+	} else {
+		if m.disconnectedSince.IsZero() {
+			m.disconnectedSince = now
+		}
+		c.Disconnected = now.Sub(m.disconnectedSince)
+	}
+	c.OfflineProposed = m.offlineProposed
+	return c
+}
+
+func (m *Monitor) check() {
+	now := time.Now()
+	current := evaluate(m.collect(now), thresholds{
+		OfflineThreshold:   m.cfg.OfflineThreshold,
+		ValidationLeadTime: m.cfg.ValidationLeadTime,
+		FlipsLeadTime:      m.cfg.FlipsLeadTime,
+	})
+
+	m.mutex.Lock()
+	var raised []Warning
+	active := make(map[string]*Warning, len(current))
+	for i := range current {
+		warning := current[i]
+		if prev, ok := m.warnings[warning.Kind]; ok {
+			warning.Since = prev.Since
+		} else {
+			warning.Since = now.Unix()
+			raised = append(raised, warning)
+		}
+		active[warning.Kind] = &warning
+	}
+	var cleared []string
+	for kind := range m.warnings {
+		if _, ok := active[kind]; !ok {
+			cleared = append(cleared, kind)
+		}
+	}
+	m.warnings = active
+	m.mutex.Unlock()
+
+	for _, kind := range cleared {
+		m.log.Info("Penalty warning cleared", "kind", kind)
+	}
+	for _, warning := range raised {
+		m.alert(warning)
+	}
+}
+
+// Warnings returns active warnings, critical ones first
+func (m *Monitor) Warnings() []Warning {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	result := make([]Warning, 0, len(m.warnings))
+	for _, warning := range m.warnings {
+		result = append(result, *warning)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Severity != result[j].Severity {
+			return result[i].Severity == SeverityCritical
+		}
+		return result[i].Kind < result[j].Kind
+	})
+	return result
+}
+
+func (m *Monitor) alert(warning Warning) {
+	if warning.Severity == SeverityCritical {
+		m.log.Error("Penalty warning", "kind", warning.Kind, "message", warning.Message)
+	} else {
+		m.log.Warn("Penalty warning", "kind", warning.Kind, "message", warning.Message)
+	}
+	if m.cfg.Webhook == "" {
+		return
+	}
+	go func() {
+		if err := m.post(alert{Address: m.secStore.GetAddress(), Warning: warning}); err != nil {
+			m.log.Warn("Failed to deliver penalty warning", "url", m.cfg.Webhook, "err", err)
+		}
+	}()
+}
+
+func (m *Monitor) post(a alert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	resp, err := m.client.Post(m.cfg.Webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("unexpected status %v", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package penalty
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+const (
+	// OfflineWarning is raised if the online identity node is unsynced or has no peers, the network proposes
+	// such identities to be marked offline with the penalty
+	OfflineWarning = "offline"
+	// OfflineProposedWarning is raised if the head block proposes the identity to be marked offline
+	OfflineProposedWarning = "offlineProposed"
+	// ValidationReadinessWarning is raised if the validation is coming and the node isn't ready to take part in it
+	ValidationReadinessWarning = "validationReadiness"
+	// MissedAnswersWarning is raised if short answers haven't been sent by the long session
+	MissedAnswersWarning = "missedAnswers"
+	// MissedValidationWarning is raised if the identity missed the previous validation
+	MissedValidationWarning = "missedValidation"
+	// RequiredFlipsWarning is raised if required flips aren't submitted shortly before the validation
+	RequiredFlipsWarning = "requiredFlips"
+	// BadFlipsWarning is raised if the identity has been reported as a bad flips author recently
+	BadFlipsWarning = "badFlips"
+)
+
+type Warning struct {
+	Kind     string `json:"kind"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Since    int64  `json:"since"`
+}
+
+// conditions is the snapshot of the node and identity state warnings are evaluated for
+type conditions struct {
+	Online    bool
+	Candidate bool
+	Connected bool
+	// Disconnected is the time the node has been unsynced or without peers
+	Disconnected    time.Duration
+	Ceremony        bool
+	LongSession     bool
+	UntilValidation time.Duration
+	AnswersSent     bool
+	RequiredFlips   uint8
+	MadeFlips       uint8
+	OfflineProposed bool
+	MissedLast      bool
+	// BadFlipsEpochs is the number of recent epochs the identity has been reported as a bad flips author
+	BadFlipsEpochs int
+}
+
+type thresholds struct {
+	OfflineThreshold   time.Duration
+	ValidationLeadTime time.Duration
+	FlipsLeadTime      time.Duration
+}
+
+func evaluate(c conditions, t thresholds) []Warning {
+	var result []Warning
+	add := func(kind, severity, message string) {
+		result = append(result, Warning{Kind: kind, Severity: severity, Message: message})
+	}
+	if c.OfflineProposed {
+		add(OfflineProposedWarning, SeverityCritical, "identity is proposed to be marked offline, the offline penalty is going to be applied")
+	}
+	if c.Online && !c.Ceremony && !c.Connected && c.Disconnected >= t.OfflineThreshold {
+		add(OfflineWarning, SeverityWarning, fmt.Sprintf("mining is on but the node has been unsynced or without peers for %v", c.Disconnected.Round(time.Second)))
+	}
+	if c.Candidate {
+		if !c.Ceremony && c.UntilValidation <= t.ValidationLeadTime && !c.Connected {
+			add(ValidationReadinessWarning, SeverityCritical, fmt.Sprintf("validation starts in %v but the node is unsynced or has no peers", c.UntilValidation.Round(time.Second)))
+		}
+		if c.LongSession && !c.AnswersSent {
+			add(MissedAnswersWarning, SeverityCritical, "short session answers have not been sent, the validation is going to be failed")
+		}
+		if !c.Ceremony && c.MadeFlips < c.RequiredFlips && c.UntilValidation <= t.FlipsLeadTime {
+			add(RequiredFlipsWarning, SeverityCritical, fmt.Sprintf("%v of %v required flips submitted, validation starts in %v", c.MadeFlips, c.RequiredFlips, c.UntilValidation.Round(time.Second)))
+		}
+	}
+	if c.MissedLast && !c.Ceremony {
+		add(MissedValidationWarning, SeverityWarning, "identity missed the previous validation")
+	}
+	if c.BadFlipsEpochs > 0 {
+		severity := SeverityWarning
+		if c.BadFlipsEpochs > 1 {
+			severity = SeverityCritical
+		}
+		add(BadFlipsWarning, severity, fmt.Sprintf("identity has been reported as a bad flips author in %v recent epochs, validation rewards are lost", c.BadFlipsEpochs))
+	}
+	return result
+}
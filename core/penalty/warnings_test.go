@@ -0,0 +1,70 @@
+package penalty
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func kinds(warnings []Warning) []string {
+	var result []string
+	for _, w := range warnings {
+		result = append(result, w.Kind)
+	}
+	return result
+}
+
+func TestEvaluate(t *testing.T) {
+	th := thresholds{
+		OfflineThreshold:   10 * time.Minute,
+		ValidationLeadTime: time.Hour,
+		FlipsLeadTime:      24 * time.Hour,
+	}
+	require := require.New(t)
+
+	healthy := conditions{
+		Online:          true,
+		Candidate:       true,
+		Connected:       true,
+		UntilValidation: 30 * time.Minute,
+		RequiredFlips:   3,
+		MadeFlips:       3,
+	}
+	require.Empty(evaluate(healthy, th))
+
+	c := healthy
+	c.Connected = false
+	c.Disconnected = 5 * time.Minute
+	require.Equal([]string{ValidationReadinessWarning}, kinds(evaluate(c, th)))
+
+	c.Disconnected = 15 * time.Minute
+	require.Equal([]string{OfflineWarning, ValidationReadinessWarning}, kinds(evaluate(c, th)))
+
+	c.Candidate = false
+	c.Online = false
+	require.Empty(evaluate(c, th))
+
+	c = healthy
+	c.MadeFlips = 1
+	require.Equal([]string{RequiredFlipsWarning}, kinds(evaluate(c, th)))
+	c.UntilValidation = 48 * time.Hour
+	require.Empty(evaluate(c, th))
+
+	c = healthy
+	c.Ceremony = true
+	c.LongSession = true
+	require.Equal([]string{MissedAnswersWarning}, kinds(evaluate(c, th)))
+	c.AnswersSent = true
+	require.Empty(evaluate(c, th))
+
+	c = healthy
+	c.OfflineProposed = true
+	c.MissedLast = true
+	c.BadFlipsEpochs = 1
+	warnings := evaluate(c, th)
+	require.Equal([]string{OfflineProposedWarning, MissedValidationWarning, BadFlipsWarning}, kinds(warnings))
+	require.Equal(SeverityWarning, warnings[2].Severity)
+
+	c.BadFlipsEpochs = 2
+	require.Equal(SeverityCritical, evaluate(c, th)[2].Severity)
+}
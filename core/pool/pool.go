@@ -0,0 +1,224 @@
+package pool
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/database"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/log"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+	dbm "github.com/tendermint/tm-db"
+	"math/big"
+	"sync"
+	"time"
+)
+
+const (
+	maxQueuedChanges = 1000
+	filterTimeout    = 5 * time.Minute
+)
+
+var FilterNotFound = errors.New("filter not found")
+
+type StateChange struct {
+	Address   common.Address
+	PrevState state.IdentityState
+	State     state.IdentityState
+	Height    uint64
+}
+
+type filter struct {
+	changes  []*StateChange
+	lastPoll time.Time
+}
+
+// Manager keeps the list of pool delegators which is maintained by the pool operator and tracks their identity states
+type Manager struct {
+	repo       *database.Repo
+	appState   *appstate.AppState
+	log        log.Logger
+	mutex      sync.Mutex
+	delegators []common.Address
+	states     map[common.Address]state.IdentityState
+	filters    map[string]*filter
+}
+
+func NewManager(db dbm.DB, appState *appstate.AppState, bus eventbus.Bus) *Manager {
+	m := &Manager{
+		repo:     database.NewRepo(db),
+		appState: appState,
+		log:      log.New("component", "pool"),
+		filters:  make(map[string]*filter),
+	}
+	m.delegators = m.repo.ReadPoolDelegators()
+	_ = bus.Subscribe(events.AddBlockEventID,
+		func(e eventbus.Event) {
+			newBlockEvent := e.(*events.NewBlockEvent)
+			m.onNewBlock(newBlockEvent.Block)
+		})
+	return m
+}
+
+func (m *Manager) Delegators() []common.Address {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]common.Address{}, m.delegators...)
+}
+
+func (m *Manager) IsDelegator(addr common.Address) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.indexOf(addr) >= 0
+}
+
+// AddDelegators adds addresses to the pool and returns the number of new delegators
+func (m *Manager) AddDelegators(addresses []common.Address) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	added := 0
+	for _, addr := range addresses {
+		if m.indexOf(addr) >= 0 {
+			continue
+		}
+		m.delegators = append(m.delegators, addr)
+		added++
+	}
+	if added > 0 {
+		m.repo.WritePoolDelegators(m.delegators)
+	}
+	return added
+}
+
+// RemoveDelegators removes addresses from the pool and returns the number of removed delegators
+func (m *Manager) RemoveDelegators(addresses []common.Address) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	removed := 0
+	for _, addr := range addresses {
+		if idx := m.indexOf(addr); idx >= 0 {
+			m.delegators = append(m.delegators[:idx], m.delegators[idx+1:]...)
+			delete(m.states, addr)
+			removed++
+		}
+	}
+	if removed > 0 {
+		m.repo.WritePoolDelegators(m.delegators)
+	}
+	return removed
+}
+
+func (m *Manager) indexOf(addr common.Address) int {
+	for i, d := range m.delegators {
+		if d == addr {
+			return i
+		}
+	}
+	return -1
+}
+
+// NewStateChangesFilter creates a filter which accumulates delegator identity state changes until they are polled
+func (m *Manager) NewStateChangesFilter() string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	id := uuid.NewRandom().String()
+	m.filters[id] = &filter{
+		lastPoll: time.Now(),
+	}
+	return id
+}
+
+func (m *Manager) StateChanges(id string) ([]*StateChange, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	f, ok := m.filters[id]
+	if !ok {
+		return nil, FilterNotFound
+	}
+	changes := f.changes
+	f.changes = nil
+	f.lastPoll = time.Now()
+	return changes, nil
+}
+
+func (m *Manager) RemoveStateChangesFilter(id string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	_, ok := m.filters[id]
+	delete(m.filters, id)
+	return ok
+}
+
+func (m *Manager) onNewBlock(block *types.Block) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for id, f := range m.filters {
+		if time.Since(f.lastPoll) > filterTimeout {
+			delete(m.filters, id)
+		}
+	}
+	if len(m.delegators) == 0 {
+		return
+	}
+
+	prevStates := m.states
+	m.states = make(map[common.Address]state.IdentityState, len(m.delegators))
+	for _, addr := range m.delegators {
+		identityState := m.appState.State.GetIdentityState(addr)
+		m.states[addr] = identityState
+		prevState, ok := prevStates[addr]
+		if !ok || prevState == identityState {
+			continue
+		}
+		change := &StateChange{
+			Address:   addr,
+			PrevState: prevState,
+			State:     identityState,
+			Height:    block.Height(),
+		}
+		for _, f := range m.filters {
+			if len(f.changes) >= maxQueuedChanges {
+				f.changes = f.changes[1:]
+			}
+			f.changes = append(f.changes, change)
+		}
+	}
+}
+
+// SplitReward splits the total amount proportionally to weights, equally if all weights are zero. The rounding
+// remainder goes to the first recipient with the positive weight or to the first one if shares are equal, so shares
+// always sum up to the total.
+func SplitReward(total *big.Int, weights []*big.Int) []*big.Int {
+	result := make([]*big.Int, len(weights))
+	if len(weights) == 0 {
+		return result
+	}
+	sum := new(big.Int)
+	for _, w := range weights {
+		if w != nil {
+			sum.Add(sum, w)
+		}
+	}
+	first := -1
+	distributed := new(big.Int)
+	for i, w := range weights {
+		if sum.Sign() == 0 {
+			result[i] = new(big.Int).Div(total, big.NewInt(int64(len(weights))))
+		} else if w == nil || w.Sign() <= 0 {
+			result[i] = new(big.Int)
+			continue
+		} else {
+			result[i] = new(big.Int).Div(new(big.Int).Mul(total, w), sum)
+		}
+		if first < 0 {
+			first = i
+		}
+		distributed.Add(distributed, result[i])
+	}
+	result[first].Add(result[first], distributed.Sub(total, distributed))
+	return result
+}
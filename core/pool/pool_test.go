@@ -0,0 +1,92 @@
+package pool
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/events"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tm-db"
+	"math/big"
+	"testing"
+)
+
+func TestSplitReward(t *testing.T) {
+	require := require.New(t)
+
+	result := SplitReward(big.NewInt(100), []*big.Int{big.NewInt(1), big.NewInt(3), nil})
+	require.Equal([]*big.Int{big.NewInt(25), big.NewInt(75), big.NewInt(0)}, result)
+
+	result = SplitReward(big.NewInt(100), []*big.Int{nil, big.NewInt(0), nil})
+	require.Equal([]*big.Int{big.NewInt(34), big.NewInt(33), big.NewInt(33)}, result)
+
+	result = SplitReward(big.NewInt(100), []*big.Int{big.NewInt(1), big.NewInt(1), big.NewInt(1)})
+	require.Equal([]*big.Int{big.NewInt(34), big.NewInt(33), big.NewInt(33)}, result)
+
+	// the remainder goes to the first recipient with the share
+	result = SplitReward(big.NewInt(10), []*big.Int{nil, big.NewInt(1), big.NewInt(2)})
+	require.Equal([]*big.Int{big.NewInt(0), big.NewInt(4), big.NewInt(6)}, result)
+
+	require.Empty(SplitReward(big.NewInt(10), nil))
+}
+
+func TestManager_Delegators(t *testing.T) {
+	require := require.New(t)
+	memdb := db.NewMemDB()
+	bus := eventbus.New()
+	appState := appstate.NewAppState(memdb, bus)
+	require.NoError(appState.Initialize(0))
+
+	m := NewManager(memdb, appState, bus)
+	first, second, third := common.Address{0x1}, common.Address{0x2}, common.Address{0x3}
+	require.Equal(2, m.AddDelegators([]common.Address{first, second, first}))
+	require.Equal(1, m.AddDelegators([]common.Address{second, third}))
+	require.Equal([]common.Address{first, second, third}, m.Delegators())
+	require.True(m.IsDelegator(second))
+
+	require.Equal(1, m.RemoveDelegators([]common.Address{second, {0x4}}))
+	require.Zero(m.RemoveDelegators([]common.Address{second}))
+	require.False(m.IsDelegator(second))
+	require.Equal([]common.Address{first, third}, m.Delegators())
+
+	// the list is loaded by the manager of the same db
+	m = NewManager(memdb, appState, bus)
+	require.Equal([]common.Address{first, third}, m.Delegators())
+}
+
+func TestManager_StateChanges(t *testing.T) {
+	require := require.New(t)
+	memdb := db.NewMemDB()
+	bus := eventbus.New()
+	appState := appstate.NewAppState(memdb, bus)
+	require.NoError(appState.Initialize(0))
+
+	m := NewManager(memdb, appState, bus)
+	delegator := common.Address{0x1}
+	m.AddDelegators([]common.Address{delegator})
+	id := m.NewStateChangesFilter()
+
+	newBlock := func(height uint64) {
+		bus.Publish(&events.NewBlockEvent{Block: &types.Block{Header: &types.Header{
+			ProposedHeader: &types.ProposedHeader{Height: height},
+		}}})
+	}
+	newBlock(1)
+	appState.State.SetState(delegator, state.Verified)
+	newBlock(2)
+	newBlock(3)
+
+	changes, err := m.StateChanges(id)
+	require.NoError(err)
+	require.Equal([]*StateChange{{Address: delegator, PrevState: state.Undefined, State: state.Verified, Height: 2}}, changes)
+	changes, err = m.StateChanges(id)
+	require.NoError(err)
+	require.Empty(changes)
+
+	require.True(m.RemoveStateChangesFilter(id))
+	require.False(m.RemoveStateChangesFilter(id))
+	_, err = m.StateChanges(id)
+	require.Equal(FilterNotFound, err)
+}
@@ -0,0 +1,247 @@
+// Package privatenet coordinates ceremonies of private networks configured by the private network file
+package privatenet
+
+import (
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/blockchain/fee"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/invites"
+	"github.com/idena-network/idena-go/core/mempool"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/log"
+	"github.com/idena-network/idena-go/secstore"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+)
+
+type InviteResult struct {
+	Receiver common.Address `json:"receiver"`
+	Hash     *common.Hash   `json:"hash,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
+
+type ConfiguredInvite struct {
+	Receiver common.Address `json:"receiver"`
+	State    uint8          `json:"state"`
+	// SentEpoch is the epoch the invite has been sent by the node in, 0 - not sent
+	SentEpoch uint16 `json:"sentEpoch,omitempty"`
+}
+
+type Status struct {
+	Network           uint32             `json:"network"`
+	GodAddress        common.Address     `json:"godAddress"`
+	IsGodNode         bool               `json:"isGodNode"`
+	GodAddressInvites uint16             `json:"godAddressInvites"`
+	Epoch             uint16             `json:"epoch"`
+	NextValidation    time.Time          `json:"nextValidation"`
+	Invites           []ConfiguredInvite `json:"invites"`
+}
+
+// Coordinator performs god address actions of the private network: it sends invites listed in the config every
+// epoch until they are activated and exports identities to bootstrap new networks
+type Coordinator struct {
+	cfg      *config.PrivateNetworkConfig
+	appState *appstate.AppState
+	txpool   *mempool.TxPool
+	secStore *secstore.SecStore
+	invites  *invites.Manager
+	bus      eventbus.Bus
+	log      log.Logger
+
+	mutex   sync.Mutex
+	sending bool
+	sent    map[common.Address]uint16
+}
+
+func NewCoordinator(cfg *config.PrivateNetworkConfig, appState *appstate.AppState, txpool *mempool.TxPool,
+	secStore *secstore.SecStore, invitesManager *invites.Manager, bus eventbus.Bus) *Coordinator {
+	return &Coordinator{
+		cfg:      cfg,
+		appState: appState,
+		txpool:   txpool,
+		secStore: secStore,
+		invites:  invitesManager,
+		bus:      bus,
+		log:      log.New("component", "privatenet"),
+		sent:     make(map[common.Address]uint16),
+	}
+}
+
+func (c *Coordinator) Start() {
+	if c.cfg == nil {
+		return
+	}
+	_ = c.bus.Subscribe(events.AddBlockEventID,
+		func(e eventbus.Event) {
+			c.processBlock()
+		})
+}
+
+func (c *Coordinator) isGodNode() bool {
+	return c.secStore.GetAddress() == c.appState.State.GodAddress()
+}
+
+func (c *Coordinator) processBlock() {
+	if len(c.cfg.Invites) == 0 || !c.isGodNode() || c.appState.State.ValidationPeriod() != state.NonePeriod {
+		return
+	}
+	c.mutex.Lock()
+	if c.sending {
+		c.mutex.Unlock()
+		return
+	}
+	c.sending = true
+	c.mutex.Unlock()
+
+	// txs are not added to the pool inside block handlers
+	go func() {
+		defer func() {
+			c.mutex.Lock()
+			c.sending = false
+			c.mutex.Unlock()
+		}()
+		for _, result := range c.SendInvites(c.cfg.Invites, false) {
+			if result.Error != "" {
+				c.log.Warn("Failed to send configured invite", "receiver", result.Receiver.Hex(), "err", result.Error)
+			} else if result.Hash != nil {
+				c.log.Info("Configured invite sent", "receiver", result.Receiver.Hex(), "hash", result.Hash.Hex())
+			}
+		}
+	}()
+}
+
+// SendInvites sends god address invites to receivers without identities, receivers already invited by the node in
+// the current epoch are skipped unless force is set
+func (c *Coordinator) SendInvites(receivers []common.Address, force bool) []InviteResult {
+	result := make([]InviteResult, 0, len(receivers))
+	if !c.isGodNode() {
+		for _, receiver := range receivers {
+			result = append(result, InviteResult{Receiver: receiver, Error: "coinbase is not the god address"})
+		}
+		return result
+	}
+	epoch := c.appState.State.Epoch()
+	for _, receiver := range receivers {
+		if c.appState.State.GetIdentityState(receiver) != state.Undefined {
+			continue
+		}
+		c.mutex.Lock()
+		sentEpoch, sent := c.sent[receiver]
+		c.mutex.Unlock()
+		if sent && sentEpoch == epoch && !force {
+			continue
+		}
+		hash, err := c.sendInvite(receiver)
+		if err != nil {
+			result = append(result, InviteResult{Receiver: receiver, Error: err.Error()})
+			continue
+		}
+		c.mutex.Lock()
+		c.sent[receiver] = epoch
+		c.mutex.Unlock()
+		result = append(result, InviteResult{Receiver: receiver, Hash: &hash})
+	}
+	return result
+}
+
+func (c *Coordinator) sendInvite(receiver common.Address) (common.Hash, error) {
+	from := c.secStore.GetAddress()
+	tx := blockchain.BuildTx(c.appState, from, &receiver, types.InviteTx, decimal.Zero, decimal.Zero, decimal.Zero, 0, 0, nil)
+	txFee := fee.CalculateFee(c.appState.ValidatorsCache.NetworkSize(), c.appState.State.FeePerByte(), tx)
+	tx.MaxFee = new(big.Int).Mul(txFee, big.NewInt(2))
+	signedTx, err := c.secStore.SignTx(tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := c.txpool.AddLocal(signedTx); err != nil {
+		return common.Hash{}, err
+	}
+	if err := c.invites.Add(receiver, signedTx.Hash(), nil); err != nil {
+		c.log.Warn("Failed to record configured invite", "receiver", receiver.Hex(), "err", err)
+	}
+	return signedTx.Hash(), nil
+}
+
+func (c *Coordinator) Status() (Status, error) {
+	if c.cfg == nil {
+		return Status{}, errors.New("node is not configured for a private network")
+	}
+	result := Status{
+		Network:           c.cfg.Network,
+		GodAddress:        c.appState.State.GodAddress(),
+		IsGodNode:         c.isGodNode(),
+		GodAddressInvites: c.appState.State.GodAddressInvites(),
+		Epoch:             c.appState.State.Epoch(),
+		NextValidation:    c.appState.State.NextValidationTime(),
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, receiver := range c.cfg.Invites {
+		result.Invites = append(result.Invites, ConfiguredInvite{
+			Receiver:  receiver,
+			State:     uint8(c.appState.State.GetIdentityState(receiver)),
+			SentEpoch: c.sent[receiver],
+		})
+	}
+	return result, nil
+}
+
+// Export creates the private network config with identities of the current state, identities in states which
+// can't be assigned at genesis are skipped. The network params of the private network are kept, other networks get
+// the new swarm key and the coinbase as the god address
+func (c *Coordinator) Export(network uint32) (*config.PrivateNetworkConfig, error) {
+	var result *config.PrivateNetworkConfig
+	if c.cfg != nil {
+		copied := *c.cfg
+		copied.Invites = nil
+		result = &copied
+	} else {
+		var err error
+		if result, err = config.NewPrivateNetworkConfig(network, c.secStore.GetAddress()); err != nil {
+			return nil, err
+		}
+	}
+	if network > 0 {
+		result.Network = network
+	}
+	result.GodAddressInvites = c.appState.State.GodAddressInvites()
+	result.FirstCeremonyTime = c.appState.State.NextValidationTime().Unix()
+	result.Identities = nil
+
+	stateDb := c.appState.State
+	stateDb.IterateOverIdentities(func(addr common.Address, identity state.Identity) {
+		name := config.IdentityStateName(uint8(identity.State))
+		if name == "" || identity.State == state.Undefined {
+			return
+		}
+		result.Identities = append(result.Identities, config.PrivateIdentity{
+			Address: addr,
+			State:   name,
+			Balance: formatDna(stateDb.GetBalance(addr)),
+			Stake:   formatDna(stateDb.GetStakeBalance(addr)),
+		})
+	})
+	sort.Slice(result.Identities, func(i, j int) bool {
+		return result.Identities[i].Address.Hex() < result.Identities[j].Address.Hex()
+	})
+	if err := result.Validate(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func formatDna(value *big.Int) string {
+	if value == nil || value.Sign() == 0 {
+		return ""
+	}
+	return blockchain.ConvertToFloat(value).String()
+}
@@ -1,17 +1,37 @@
 package profile
 
 import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/events"
 	"github.com/idena-network/idena-go/ipfs"
+	"github.com/idena-network/idena-go/log"
 	"github.com/idena-network/idena-go/rlp"
+	"github.com/idena-network/idena-go/secstore"
+	"github.com/patrickmn/go-cache"
 	"github.com/pkg/errors"
+	"sync"
+	"time"
 )
 
 const (
 	maxIpfsDataSize = 1024 * 600
+	profileCacheTTL = time.Hour
+	// failedProfileTTL is the time a profile which failed to load isn't prefetched again
+	failedProfileTTL = 5 * time.Minute
+	maxPrefetches    = 10
 )
 
 type Manager struct {
 	ipfsProxy ipfs.Proxy
+	bus       eventbus.Bus
+	secStore  *secstore.SecStore
+	cache     *cache.Cache
+	failed    *cache.Cache
+	log       log.Logger
+
+	prefetchMutex sync.Mutex
+	prefetching   map[string]struct{}
 }
 
 type Profile struct {
@@ -19,9 +39,15 @@ type Profile struct {
 	Info     []byte `rlp:"nil"`
 }
 
-func NewProfileManager(ipfsProxy ipfs.Proxy) *Manager {
+func NewProfileManager(ipfsProxy ipfs.Proxy, bus eventbus.Bus, secStore *secstore.SecStore) *Manager {
 	return &Manager{
-		ipfsProxy: ipfsProxy,
+		ipfsProxy:   ipfsProxy,
+		bus:         bus,
+		secStore:    secStore,
+		cache:       cache.New(profileCacheTTL, profileCacheTTL*2),
+		failed:      cache.New(failedProfileTTL, failedProfileTTL*2),
+		log:         log.New("component", "profile"),
+		prefetching: make(map[string]struct{}),
 	}
 }
 
@@ -35,10 +61,41 @@ func (pm *Manager) AddProfile(pr Profile) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	pm.bus.Publish(&events.IpfsPinnedEvent{Cid: hash.Bytes(), DataType: ipfs.Profile})
+	pm.cache.SetDefault(string(hash.Bytes()), pr)
 	return hash.Bytes(), nil
 }
 
+// PublishProfile adds the profile encoded with the common signed schema, private data is encrypted with the node key
+func (pm *Manager) PublishProfile(nickname []byte, avatar []byte, contacts []Contact, private []byte) ([]byte, error) {
+	info := &SignedInfo{
+		Version:   signedInfoVersion,
+		Avatar:    avatar,
+		Contacts:  contacts,
+		Timestamp: uint64(time.Now().Unix()),
+	}
+	if err := validate(nickname, info); err != nil {
+		return nil, err
+	}
+	if len(private) > 0 {
+		encrypted, err := EncryptPrivate(pm.secStore.GetPubKey(), private)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encrypt private profile data")
+		}
+		info.Private = encrypted
+	}
+	info.Sign(nickname, pm.secStore.Sign)
+	data, err := EncodeSignedInfo(info)
+	if err != nil {
+		return nil, err
+	}
+	return pm.AddProfile(Profile{Nickname: nickname, Info: data})
+}
+
 func (pm *Manager) GetProfile(hash []byte) (Profile, error) {
+	if cached, ok := pm.CachedProfile(hash); ok {
+		return cached, nil
+	}
 	encodedData, err := pm.ipfsProxy.Get(hash)
 	if err != nil {
 		return Profile{}, err
@@ -47,5 +104,65 @@ func (pm *Manager) GetProfile(hash []byte) (Profile, error) {
 	if err := rlp.DecodeBytes(encodedData, &res); err != nil {
 		return Profile{}, err
 	}
+	pm.cache.SetDefault(string(hash), res)
 	return res, nil
 }
+
+// CachedProfile returns the profile if it has been loaded recently
+func (pm *Manager) CachedProfile(hash []byte) (Profile, bool) {
+	if cached, ok := pm.cache.Get(string(hash)); ok {
+		return cached.(Profile), true
+	}
+	return Profile{}, false
+}
+
+// Prefetch loads the profile into the cache in background, the profile which is being loaded or recently failed
+// to load is skipped as well as all profiles while maxPrefetches are in progress
+func (pm *Manager) Prefetch(hash []byte) {
+	if _, ok := pm.CachedProfile(hash); ok {
+		return
+	}
+	key := string(hash)
+	if _, failed := pm.failed.Get(key); failed {
+		return
+	}
+	pm.prefetchMutex.Lock()
+	if _, ok := pm.prefetching[key]; ok || len(pm.prefetching) >= maxPrefetches {
+		pm.prefetchMutex.Unlock()
+		return
+	}
+	pm.prefetching[key] = struct{}{}
+	pm.prefetchMutex.Unlock()
+
+	go func() {
+		if _, err := pm.GetProfile(hash); err != nil {
+			pm.failed.SetDefault(key, struct{}{})
+			pm.log.Debug("Failed to load profile", "err", err)
+		}
+		pm.prefetchMutex.Lock()
+		delete(pm.prefetching, key)
+		pm.prefetchMutex.Unlock()
+	}()
+}
+
+// VerifiedInfo decodes the signed profile info and checks it has been signed by the identity, it returns nil
+// if the profile doesn't use the common schema
+func VerifiedInfo(pr Profile, addr common.Address) (info *SignedInfo, verified bool, err error) {
+	info, err = DecodeSignedInfo(pr.Info)
+	if err != nil || info == nil {
+		return nil, false, err
+	}
+	signer, err := info.Signer(pr.Nickname)
+	if err != nil {
+		return info, false, nil
+	}
+	return info, signer == addr, nil
+}
+
+// DecryptPrivate decrypts private data of the own profile
+func (pm *Manager) DecryptPrivate(info *SignedInfo) ([]byte, error) {
+	if len(info.Private) == 0 {
+		return nil, nil
+	}
+	return pm.secStore.DecryptMessage(info.Private)
+}
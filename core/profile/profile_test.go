@@ -0,0 +1,41 @@
+package profile
+
+import (
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/ipfs"
+	"github.com/idena-network/idena-go/rlp"
+	"github.com/idena-network/idena-go/secstore"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestManager_Prefetch(t *testing.T) {
+	proxy := ipfs.NewMemoryIpfsProxy()
+	pm := NewProfileManager(proxy, eventbus.New(), secstore.NewSecStore())
+
+	data, _ := rlp.EncodeToBytes(Profile{Nickname: []byte("foo")})
+	c, err := proxy.Add(data, false)
+	require.NoError(t, err)
+	pm.Prefetch(c.Bytes())
+	require.Eventually(t, func() bool {
+		_, ok := pm.CachedProfile(c.Bytes())
+		return ok
+	}, time.Second, 10*time.Millisecond)
+
+	missing, _ := proxy.Cid([]byte{0x1})
+	pm.Prefetch(missing.Bytes())
+	require.Eventually(t, func() bool {
+		pm.prefetchMutex.Lock()
+		defer pm.prefetchMutex.Unlock()
+		return len(pm.prefetching) == 0
+	}, time.Second, 10*time.Millisecond)
+	_, failed := pm.failed.Get(string(missing.Bytes()))
+	require.True(t, failed)
+
+	// the failed profile isn't requested again until it expires
+	pm.Prefetch(missing.Bytes())
+	pm.prefetchMutex.Lock()
+	require.Empty(t, pm.prefetching)
+	pm.prefetchMutex.Unlock()
+}
@@ -0,0 +1,103 @@
+package profile
+
+import (
+	"bytes"
+	"crypto/rand"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/crypto/ecies"
+	"github.com/idena-network/idena-go/rlp"
+	"github.com/pkg/errors"
+)
+
+const (
+	signedInfoVersion = 1
+	maxNicknameLength = 64
+	maxContactsCount  = 16
+)
+
+// signedInfoPrefix marks profile info encoded with the common signed schema, older clients treat the info as opaque bytes
+var signedInfoPrefix = []byte("idena-profile:")
+
+type Contact struct {
+	Kind  string
+	Value string
+}
+
+// SignedInfo is the common profile schema stored in the profile info, it is signed by the identity key.
+// Private contains data encrypted with the identity public key, it can be read by the identity owner only
+type SignedInfo struct {
+	Version   uint8
+	Avatar    []byte
+	Contacts  []Contact
+	Private   []byte
+	Timestamp uint64
+	Signature []byte
+}
+
+func (i *SignedInfo) hash(nickname []byte) common.Hash {
+	return rlp.Hash([]interface{}{
+		nickname,
+		i.Version,
+		i.Avatar,
+		i.Contacts,
+		i.Private,
+		i.Timestamp,
+	})
+}
+
+// Sign sets the signature of the info and the nickname using the sign function of the identity key
+func (i *SignedInfo) Sign(nickname []byte, sign func(data []byte) []byte) {
+	hash := i.hash(nickname)
+	i.Signature = sign(hash[:])
+}
+
+// Signer returns the address of the identity which has signed the info and the nickname
+func (i *SignedInfo) Signer(nickname []byte) (common.Address, error) {
+	hash := i.hash(nickname)
+	pubKey, err := crypto.Ecrecover(hash[:], i.Signature)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "invalid profile signature")
+	}
+	return crypto.PubKeyBytesToAddress(pubKey)
+}
+
+func validate(nickname []byte, info *SignedInfo) error {
+	if len(nickname) > maxNicknameLength {
+		return errors.Errorf("nickname is too long, max length %v", maxNicknameLength)
+	}
+	if len(info.Contacts) > maxContactsCount {
+		return errors.Errorf("too many contacts, max count %v", maxContactsCount)
+	}
+	return nil
+}
+
+// EncodeSignedInfo encodes the info to be stored as the profile info
+func EncodeSignedInfo(info *SignedInfo) ([]byte, error) {
+	data, err := rlp.EncodeToBytes(info)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, signedInfoPrefix...), data...), nil
+}
+
+// DecodeSignedInfo decodes the profile info, it returns nil if the info is not encoded with the common schema
+func DecodeSignedInfo(data []byte) (*SignedInfo, error) {
+	if !bytes.HasPrefix(data, signedInfoPrefix) {
+		return nil, nil
+	}
+	info := new(SignedInfo)
+	if err := rlp.DecodeBytes(data[len(signedInfoPrefix):], info); err != nil {
+		return nil, errors.Wrap(err, "invalid signed profile info")
+	}
+	return info, nil
+}
+
+// EncryptPrivate encrypts data with the identity public key
+func EncryptPrivate(pubKey []byte, data []byte) ([]byte, error) {
+	key, err := crypto.UnmarshalPubkey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(key), data, nil, nil)
+}
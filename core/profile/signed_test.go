@@ -0,0 +1,51 @@
+package profile
+
+import (
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/crypto/ecies"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSignedInfo(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	sign := func(data []byte) []byte {
+		sig, _ := crypto.Sign(data, key)
+		return sig
+	}
+	nickname := []byte("nick")
+
+	private, err := EncryptPrivate(crypto.FromECDSAPub(&key.PublicKey), []byte("secret"))
+	require.NoError(t, err)
+	info := &SignedInfo{
+		Version:   signedInfoVersion,
+		Avatar:    []byte{0x1, 0x2},
+		Contacts:  []Contact{{Kind: "email", Value: "a@b.c"}},
+		Private:   private,
+		Timestamp: 10,
+	}
+	info.Sign(nickname, sign)
+
+	data, err := EncodeSignedInfo(info)
+	require.NoError(t, err)
+	decoded, err := DecodeSignedInfo(data)
+	require.NoError(t, err)
+	require.Equal(t, info, decoded)
+
+	signer, err := decoded.Signer(nickname)
+	require.NoError(t, err)
+	require.Equal(t, addr, signer)
+
+	signer, err = decoded.Signer([]byte("other"))
+	require.NoError(t, err)
+	require.NotEqual(t, addr, signer)
+
+	decrypted, err := ecies.ImportECDSA(key).Decrypt(decoded.Private, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte("secret"), decrypted)
+
+	decoded, err = DecodeSignedInfo([]byte("custom client info"))
+	require.NoError(t, err)
+	require.Nil(t, decoded)
+}
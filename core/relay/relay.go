@@ -0,0 +1,280 @@
+// Package relay forwards read-only data of the other network, checkpoints and snapshot manifests, for federated
+// test setups. The data is served to clients as is and never affects the sync or the consensus of the node.
+package relay
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/mirror"
+	"github.com/idena-network/idena-go/log"
+	"github.com/pkg/errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const manifestPath = "/v1/manifest"
+
+type Checkpoint struct {
+	Height       uint64      `json:"height"`
+	Hash         common.Hash `json:"hash"`
+	IdentityRoot common.Hash `json:"identityRoot"`
+	Timestamp    int64       `json:"timestamp"`
+	ReceivedAt   int64       `json:"receivedAt"`
+}
+
+type Manifest struct {
+	Height     uint64        `json:"height"`
+	Root       common.Hash   `json:"root"`
+	Cid        hexutil.Bytes `json:"cid"`
+	ReceivedAt int64         `json:"receivedAt"`
+}
+
+type Status struct {
+	Enabled     bool     `json:"enabled"`
+	Source      string   `json:"source,omitempty"`
+	Mirror      string   `json:"mirror,omitempty"`
+	Data        []string `json:"data"`
+	LastSync    int64    `json:"lastSync"`
+	LastError   string   `json:"lastError,omitempty"`
+	Checkpoints int      `json:"checkpoints"`
+	Manifests   int      `json:"manifests"`
+}
+
+type Relay struct {
+	cfg    *config.RelayConfig
+	client *http.Client
+	log    log.Logger
+	stop   chan struct{}
+
+	mutex       sync.RWMutex
+	checkpoints []*Checkpoint
+	manifests   []*Manifest
+	lastSync    time.Time
+	lastError   error
+}
+
+func NewRelay(cfg *config.RelayConfig) (*Relay, error) {
+	if cfg.Enabled() {
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return &Relay{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		log:    log.New("component", "relay"),
+		stop:   make(chan struct{}),
+	}, nil
+}
+
+func (r *Relay) Start() {
+	if !r.cfg.Enabled() {
+		return
+	}
+	r.log.Info("Relay started", "source", r.cfg.Source, "mirror", r.cfg.Mirror, "data", strings.Join(r.cfg.Data, ","))
+	go r.loop()
+}
+
+func (r *Relay) Stop() {
+	if r.cfg.Enabled() {
+		close(r.stop)
+	}
+}
+
+func (r *Relay) loop() {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if err := r.sync(time.Now()); err != nil {
+			r.log.Warn("Failed to relay data", "err", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+func (r *Relay) sync(now time.Time) error {
+	var checkpoint *Checkpoint
+	var manifest *Manifest
+	var err error
+	if r.cfg.Relays(config.RelayCheckpoints) {
+		if checkpoint, err = r.fetchCheckpoint(); err != nil {
+			err = errors.Wrap(err, "cannot load checkpoint")
+		}
+	}
+	if r.cfg.Relays(config.RelayManifests) {
+		var manifestErr error
+		if manifest, manifestErr = r.fetchManifest(); manifestErr != nil && err == nil {
+			err = errors.Wrap(manifestErr, "cannot load manifest")
+		}
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.lastError = err
+	if checkpoint != nil && (len(r.checkpoints) == 0 || r.checkpoints[len(r.checkpoints)-1].Height != checkpoint.Height) {
+		checkpoint.ReceivedAt = now.Unix()
+		r.checkpoints = append(r.checkpoints, checkpoint)
+		r.checkpoints = r.checkpoints[r.historyStart(len(r.checkpoints)):]
+	}
+	if manifest != nil && (len(r.manifests) == 0 || r.manifests[len(r.manifests)-1].Height != manifest.Height) {
+		manifest.ReceivedAt = now.Unix()
+		r.manifests = append(r.manifests, manifest)
+		r.manifests = r.manifests[r.historyStart(len(r.manifests)):]
+	}
+	if err == nil {
+		r.lastSync = now
+	}
+	return err
+}
+
+// historyStart returns the index of the first kept item of the history with the given length
+func (r *Relay) historyStart(length int) int {
+	if r.cfg.HistorySize > 0 && length > r.cfg.HistorySize {
+		return length - r.cfg.HistorySize
+	}
+	return 0
+}
+
+type rpcRequest struct {
+	Key     string        `json:"key,omitempty"`
+	JsonRPC string        `json:"jsonrpc"`
+	Id      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type block struct {
+	Hash         common.Hash `json:"hash"`
+	Height       uint64      `json:"height"`
+	Time         int64       `json:"timestamp"`
+	IdentityRoot common.Hash `json:"identityRoot"`
+}
+
+func (r *Relay) call(method string, result interface{}, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, _ := json.Marshal(&rpcRequest{
+		Key:     r.cfg.SourceApiKey,
+		JsonRPC: "2.0",
+		Id:      1,
+		Method:  method,
+		Params:  params,
+	})
+	resp, err := r.client.Post(r.cfg.Source, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("source responded with status %v", resp.Status)
+	}
+	var response rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return errors.Wrap(err, "invalid source response")
+	}
+	if response.Error != nil {
+		return errors.New(response.Error.Message)
+	}
+	if len(response.Result) == 0 || string(response.Result) == "null" {
+		return errors.Errorf("%v returned no result", method)
+	}
+	return json.Unmarshal(response.Result, result)
+}
+
+func (r *Relay) fetchCheckpoint() (*Checkpoint, error) {
+	var head block
+	if err := r.call("bcn_lastBlock", &head); err != nil {
+		return nil, err
+	}
+	if head.Height <= r.cfg.CheckpointDepth {
+		return nil, errors.Errorf("source head %v is below the checkpoint depth", head.Height)
+	}
+	var checkpoint block
+	if err := r.call("bcn_blockAt", &checkpoint, head.Height-r.cfg.CheckpointDepth); err != nil {
+		return nil, err
+	}
+	return &Checkpoint{
+		Height:       checkpoint.Height,
+		Hash:         checkpoint.Hash,
+		IdentityRoot: checkpoint.IdentityRoot,
+		Timestamp:    checkpoint.Time,
+	}, nil
+}
+
+func (r *Relay) fetchManifest() (*Manifest, error) {
+	resp, err := r.client.Get(strings.TrimSuffix(r.cfg.Mirror, "/") + manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("mirror responded with status %v", resp.Status)
+	}
+	var manifest mirror.Manifest
+	if err := json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
+		return nil, errors.Wrap(err, "invalid mirror manifest")
+	}
+	return &Manifest{
+		Height: manifest.Height,
+		Root:   manifest.Root,
+		Cid:    manifest.Cid,
+	}, nil
+}
+
+// Checkpoints returns relayed checkpoints, the most recent first
+func (r *Relay) Checkpoints() []*Checkpoint {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	result := make([]*Checkpoint, 0, len(r.checkpoints))
+	for i := len(r.checkpoints) - 1; i >= 0; i-- {
+		result = append(result, r.checkpoints[i])
+	}
+	return result
+}
+
+// Manifests returns relayed snapshot manifests, the most recent first
+func (r *Relay) Manifests() []*Manifest {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	result := make([]*Manifest, 0, len(r.manifests))
+	for i := len(r.manifests) - 1; i >= 0; i-- {
+		result = append(result, r.manifests[i])
+	}
+	return result
+}
+
+func (r *Relay) Status() *Status {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	status := &Status{
+		Enabled:     r.cfg.Enabled(),
+		Source:      r.cfg.Source,
+		Mirror:      r.cfg.Mirror,
+		Data:        r.cfg.Data,
+		Checkpoints: len(r.checkpoints),
+		Manifests:   len(r.manifests),
+	}
+	if !r.lastSync.IsZero() {
+		status.LastSync = r.lastSync.Unix()
+	}
+	if r.lastError != nil {
+		status.LastError = r.lastError.Error()
+	}
+	return status
+}
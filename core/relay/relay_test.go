@@ -0,0 +1,111 @@
+package relay
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/config"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRelay_sync(t *testing.T) {
+	require := require.New(t)
+	head := uint64(100)
+	var keys []string
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Key    string        `json:"key"`
+			Method string        `json:"method"`
+			Params []json.Number `json:"params"`
+		}
+		require.NoError(json.NewDecoder(r.Body).Decode(&req))
+		keys = append(keys, req.Key)
+		height := head
+		if req.Method == "bcn_blockAt" {
+			value, _ := req.Params[0].Int64()
+			height = uint64(value)
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"hash":"%v","height":%v,"timestamp":%v,"identityRoot":"%v"}}`,
+			common.Hash{byte(height)}.Hex(), height, height*20, common.Hash{0x1}.Hex())
+	}))
+	defer source.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(manifestPath, r.URL.Path)
+		fmt.Fprintf(w, `{"height":%v,"root":"%v","cid":"0x0102"}`, head/10*10, common.Hash{0x2}.Hex())
+	}))
+	defer mirror.Close()
+
+	cfg := config.GetDefaultRelayConfig()
+	cfg.Source = source.URL
+	cfg.SourceApiKey = "key"
+	cfg.Mirror = mirror.URL + "/"
+	cfg.HistorySize = 2
+	relay, err := NewRelay(cfg)
+	require.NoError(err)
+
+	now := time.Unix(1000, 0)
+	require.NoError(relay.sync(now))
+	checkpoints := relay.Checkpoints()
+	require.Len(checkpoints, 1)
+	require.Equal(uint64(90), checkpoints[0].Height)
+	require.Equal(common.Hash{90}, checkpoints[0].Hash)
+	require.Equal(common.Hash{0x1}, checkpoints[0].IdentityRoot)
+	require.Equal(int64(1800), checkpoints[0].Timestamp)
+	require.Equal(now.Unix(), checkpoints[0].ReceivedAt)
+	manifests := relay.Manifests()
+	require.Len(manifests, 1)
+	require.Equal(uint64(100), manifests[0].Height)
+	require.Equal([]byte{0x1, 0x2}, []byte(manifests[0].Cid))
+	require.Equal([]string{"key", "key"}, keys)
+
+	// the same data isn't duplicated
+	require.NoError(relay.sync(now))
+	require.Len(relay.Checkpoints(), 1)
+	require.Len(relay.Manifests(), 1)
+
+	for _, height := range []uint64{110, 120} {
+		head = height
+		require.NoError(relay.sync(now))
+	}
+	checkpoints = relay.Checkpoints()
+	require.Len(checkpoints, 2)
+	require.Equal(uint64(110), checkpoints[0].Height)
+	require.Equal(uint64(100), checkpoints[1].Height)
+	require.Len(relay.Manifests(), 2)
+
+	status := relay.Status()
+	require.True(status.Enabled)
+	require.Equal(now.Unix(), status.LastSync)
+	require.Empty(status.LastError)
+
+	head = 5
+	require.Error(relay.sync(now.Add(time.Minute)))
+	status = relay.Status()
+	require.Equal(now.Unix(), status.LastSync)
+	require.Contains(status.LastError, "checkpoint depth")
+}
+
+func TestNewRelay(t *testing.T) {
+	cfg := config.GetDefaultRelayConfig()
+	relay, err := NewRelay(cfg)
+	require.NoError(t, err)
+	require.False(t, relay.Status().Enabled)
+	relay.Start()
+	relay.Stop()
+
+	cfg.Source = "http://localhost:9009"
+	_, err = NewRelay(cfg)
+	require.Error(t, err)
+
+	cfg.Data = []string{config.RelayCheckpoints, "blocks"}
+	_, err = NewRelay(cfg)
+	require.Error(t, err)
+
+	cfg.Data = []string{config.RelayCheckpoints}
+	_, err = NewRelay(cfg)
+	require.NoError(t, err)
+}
@@ -0,0 +1,88 @@
+package signstore
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/database"
+	"github.com/idena-network/idena-go/log"
+	"github.com/pkg/errors"
+	dbm "github.com/tendermint/tm-db"
+	"sync"
+)
+
+const (
+	// ProposalStep is used to record block proposals, BA steps start from 1
+	ProposalStep uint8 = 0
+
+	// KeepRounds is the number of recent rounds which signed messages are kept for
+	KeepRounds = 1000
+)
+
+var ConflictingMessage = errors.New("conflicting message has already been signed")
+
+// Store persists hashes of signed consensus messages by round, attempt and step and refuses to sign a different message
+// for the same round attempt and step, which protects from double signing after restart or when the key is shared by
+// several nodes. The attempt is the number of the same round retries made by the running node since a failed round is
+// started again with new votes.
+type Store struct {
+	repo       *database.Repo
+	log        log.Logger
+	mutex      sync.Mutex
+	lastRound  uint64
+	prunedTill uint64
+}
+
+func NewStore(db dbm.DB) *Store {
+	return &Store{
+		repo: database.NewRepo(db),
+		log:  log.New("component", "signstore"),
+	}
+}
+
+// Record checks that no other message has been signed at the round attempt and step and persists the hash,
+// it must be called before the message is signed
+func (s *Store) Record(round uint64, attempt uint32, step uint8, hash common.Hash) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if signed, ok := s.repo.ReadSignedMessage(round, attempt, step); ok {
+		if signed == hash {
+			return nil
+		}
+		s.log.Error("Refused to sign conflicting message", "round", round, "attempt", attempt, "step", step,
+			"signed", signed.Hex(), "hash", hash.Hex())
+		return errors.Wrapf(ConflictingMessage, "round %v, attempt %v, step %v, signed %v", round, attempt, step, signed.Hex())
+	}
+	s.repo.WriteSignedMessage(round, attempt, step, hash)
+	if round > s.lastRound {
+		s.lastRound = round
+		s.prune()
+	}
+	return nil
+}
+
+func (s *Store) prune() {
+	if s.lastRound <= KeepRounds {
+		return
+	}
+	till := s.lastRound - KeepRounds
+	if till <= s.prunedTill {
+		return
+	}
+	s.repo.DeleteSignedMessages(s.prunedTill, till)
+	s.prunedTill = till
+}
+
+// Signed returns hashes of messages signed at the round by attempt and step
+func (s *Store) Signed(round uint64) map[uint32]map[uint8]common.Hash {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.repo.ReadSignedMessages(round)
+}
+
+// Reset removes records of the round so conflicting messages can be signed, returns the number of removed records
+func (s *Store) Reset(round uint64) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	removed := s.repo.DeleteSignedMessages(round, round+1)
+	s.log.Warn("Signed messages have been reset", "round", round, "removed", removed)
+	return removed
+}
@@ -0,0 +1,52 @@
+package signstore
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	db "github.com/tendermint/tm-db"
+	"testing"
+)
+
+func TestStore_Record(t *testing.T) {
+	require := require.New(t)
+	memdb := db.NewMemDB()
+	store := NewStore(memdb)
+
+	hash1, hash2 := common.Hash{0x1}, common.Hash{0x2}
+
+	require.NoError(store.Record(10, 0, ProposalStep, hash1))
+	require.NoError(store.Record(10, 0, ProposalStep, hash1))
+	require.Equal(ConflictingMessage, errors.Cause(store.Record(10, 0, ProposalStep, hash2)))
+	require.NoError(store.Record(10, 0, 1, hash2))
+	require.NoError(store.Record(11, 0, ProposalStep, hash2))
+
+	// the retried round signs new messages
+	require.NoError(store.Record(10, 1, ProposalStep, hash2))
+	require.Equal(ConflictingMessage, errors.Cause(store.Record(10, 1, ProposalStep, hash1)))
+
+	// records survive restart
+	store = NewStore(memdb)
+	require.Equal(ConflictingMessage, errors.Cause(store.Record(10, 0, 1, hash1)))
+	require.Equal(map[uint32]map[uint8]common.Hash{
+		0: {ProposalStep: hash1, 1: hash2},
+		1: {ProposalStep: hash2},
+	}, store.Signed(10))
+
+	require.Equal(3, store.Reset(10))
+	require.Empty(store.Signed(10))
+	require.NoError(store.Record(10, 0, 1, hash1))
+}
+
+func TestStore_Prune(t *testing.T) {
+	require := require.New(t)
+	store := NewStore(db.NewMemDB())
+
+	require.NoError(store.Record(1, 0, 1, common.Hash{0x1}))
+	require.NoError(store.Record(KeepRounds, 2, 1, common.Hash{0x1}))
+	require.NoError(store.Record(KeepRounds+2, 0, 1, common.Hash{0x2}))
+
+	require.Empty(store.Signed(1))
+	require.Len(store.Signed(KeepRounds), 1)
+	require.Len(store.Signed(KeepRounds+2), 1)
+}
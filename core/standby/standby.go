@@ -0,0 +1,92 @@
+package standby
+
+import (
+	"github.com/idena-network/idena-go/database"
+	"github.com/idena-network/idena-go/log"
+	"github.com/pkg/errors"
+	dbm "github.com/tendermint/tm-db"
+	"sync"
+)
+
+// PromotionGap is the number of rounds a promoted node skips because the former active node could have signed them
+const PromotionGap = 2
+
+var (
+	AlreadyActive = errors.New("node is already active")
+	StandbyMode   = errors.New("node is in standby mode")
+)
+
+type Status struct {
+	Standby bool
+	// LockHeight is the lowest round the node is allowed to sign consensus messages at
+	LockHeight uint64
+}
+
+// Guard keeps hot-standby node from signing consensus and ceremony messages until it is explicitly promoted.
+// The signing lock height is persisted so a promoted node doesn't sign rounds of the former active node after restart.
+type Guard struct {
+	repo       *database.Repo
+	log        log.Logger
+	mutex      sync.RWMutex
+	standby    bool
+	lockHeight uint64
+}
+
+func NewGuard(standby bool, db dbm.DB) *Guard {
+	repo := database.NewRepo(db)
+	return &Guard{
+		repo:       repo,
+		log:        log.New("component", "standby"),
+		standby:    standby,
+		lockHeight: repo.ReadSigningLock(),
+	}
+}
+
+func (g *Guard) IsStandby() bool {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	return g.standby
+}
+
+// CanSign checks if consensus messages of the given round can be signed
+func (g *Guard) CanSign(round uint64) bool {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	return !g.standby && round >= g.lockHeight
+}
+
+// Promote switches standby node to active mode, rounds up to head + PromotionGap are not signed
+func (g *Guard) Promote(head uint64) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if !g.standby {
+		return AlreadyActive
+	}
+	lockHeight := head + PromotionGap
+	if lockHeight > g.lockHeight {
+		g.lockHeight = lockHeight
+		g.repo.WriteSigningLock(lockHeight)
+	}
+	g.standby = false
+	g.log.Info("Node is promoted to active mode", "lockHeight", g.lockHeight)
+	return nil
+}
+
+// Demote switches active node to standby mode
+func (g *Guard) Demote() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if !g.standby {
+		g.standby = true
+		g.log.Info("Node is switched to standby mode")
+	}
+}
+
+func (g *Guard) Status() Status {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	return Status{
+		Standby:    g.standby,
+		LockHeight: g.lockHeight,
+	}
+}
@@ -0,0 +1,30 @@
+package standby
+
+import (
+	"github.com/stretchr/testify/require"
+	db "github.com/tendermint/tm-db"
+	"testing"
+)
+
+func TestGuard_Promote(t *testing.T) {
+	require := require.New(t)
+	memdb := db.NewMemDB()
+
+	guard := NewGuard(true, memdb)
+	require.True(guard.IsStandby())
+	require.False(guard.CanSign(1))
+
+	require.NoError(guard.Promote(10))
+	require.Equal(AlreadyActive, guard.Promote(10))
+	require.False(guard.CanSign(11))
+	require.True(guard.CanSign(12))
+
+	restarted := NewGuard(false, memdb)
+	require.False(restarted.CanSign(11))
+	require.True(restarted.CanSign(12))
+
+	restarted.Demote()
+	require.False(restarted.CanSign(12))
+	require.NoError(restarted.Promote(5))
+	require.Equal(uint64(12), restarted.Status().LockHeight)
+}
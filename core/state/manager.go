@@ -1,18 +1,21 @@
 package state
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"github.com/idena-network/idena-go/blockchain/types"
 	"github.com/idena-network/idena-go/common"
 	"github.com/idena-network/idena-go/common/eventbus"
 	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/mirror"
 	"github.com/idena-network/idena-go/core/state/snapshot"
 	"github.com/idena-network/idena-go/database"
 	"github.com/idena-network/idena-go/events"
 	"github.com/idena-network/idena-go/ipfs"
 	"github.com/idena-network/idena-go/log"
 	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
 	dbm "github.com/tendermint/tm-db"
 	"os"
 	"path/filepath"
@@ -30,6 +33,32 @@ var (
 	MaxManifestTimeouts   = byte(5)
 )
 
+// Stages of the snapshot creation
+const (
+	SnapshotIdle       = "idle"
+	SnapshotWriting    = "writing"
+	SnapshotPublishing = "publishing"
+	SnapshotDone       = "done"
+	SnapshotFailed     = "failed"
+)
+
+// SnapshotStatus reports the snapshot built by the background worker, Pending is the height of the snapshot queued
+// while the previous one is built
+type SnapshotStatus struct {
+	Stage   string  `json:"stage"`
+	Height  uint64  `json:"height"`
+	Pending *uint64 `json:"pending,omitempty"`
+	// Entries is the number of state db entries written to the snapshot file
+	Entries  int         `json:"entries"`
+	Started  int64       `json:"started,omitempty"`
+	Finished int64       `json:"finished,omitempty"`
+	Duration float64     `json:"duration"`
+	Root     common.Hash `json:"root,omitempty"`
+	Error    string      `json:"error,omitempty"`
+	// LastHeight is the height of the last published manifest
+	LastHeight uint64 `json:"lastHeight"`
+}
+
 type SnapshotManager struct {
 	db        dbm.DB
 	state     *StateDB
@@ -39,24 +68,35 @@ type SnapshotManager struct {
 	cfg       *config.Config
 	log       log.Logger
 	repo      *database.Repo
+	mirror    *mirror.Client
+
+	// snapshots are built by the single worker, the newer request replaces the pending one
+	statusMutex sync.Mutex
+	status      SnapshotStatus
+	pending     *uint64
+	wakeup      chan struct{}
 }
 
-func NewSnapshotManager(db dbm.DB, state *StateDB, bus eventbus.Bus, ipfs ipfs.Proxy, cfg *config.Config) *SnapshotManager {
+func NewSnapshotManager(db dbm.DB, state *StateDB, bus eventbus.Bus, ipfs ipfs.Proxy, cfg *config.Config, mirror *mirror.Client) *SnapshotManager {
 	pdb := dbm.NewPrefixDB(db, database.SnapshotDbPrefix)
 	m := &SnapshotManager{
-		db:    pdb,
-		state: state,
-		repo:  database.NewRepo(db),
-		bus:   bus,
-		cfg:   cfg,
-		log:   log.New(),
-		ipfs:  ipfs,
+		db:     pdb,
+		state:  state,
+		repo:   database.NewRepo(db),
+		bus:    bus,
+		cfg:    cfg,
+		log:    log.New(),
+		ipfs:   ipfs,
+		mirror: mirror,
+		status: SnapshotStatus{Stage: SnapshotIdle},
+		wakeup: make(chan struct{}, 1),
 	}
 	_ = bus.Subscribe(events.AddBlockEventID,
 		func(e eventbus.Event) {
 			newBlockEvent := e.(*events.NewBlockEvent)
 			m.createSnapshotIfNeeded(newBlockEvent.Block.Header)
 		})
+	go m.loop()
 	return m
 }
 
@@ -74,46 +114,124 @@ func createSnapshotFile(datadir string, height uint64) (fileName string, file *o
 	return filePath, f, nil
 }
 
+// createSnapshotIfNeeded is called by block import, it only queues the snapshot and pins the state version, so the
+// version is kept until the worker writes it
 func (m *SnapshotManager) createSnapshotIfNeeded(block *types.Header) {
 	if m.isSyncing {
 		return
 	}
-	if m.state.LastSnapshot() == block.Height() {
-		go m.createSnapshot(block.Height())
+	if m.state.LastSnapshot() != block.Height() {
+		return
+	}
+	height := block.Height()
+	m.state.PinVersion(int64(height))
+	m.statusMutex.Lock()
+	if m.pending != nil {
+		m.log.Warn("Pending snapshot is replaced by the newer one", "height", *m.pending, "newHeight", height)
+		m.state.UnpinVersion(int64(*m.pending))
+	}
+	m.pending = &height
+	m.status.Pending = &height
+	m.statusMutex.Unlock()
+	select {
+	case m.wakeup <- struct{}{}:
+	default:
 	}
 }
 
+func (m *SnapshotManager) loop() {
+	for range m.wakeup {
+		m.statusMutex.Lock()
+		pending := m.pending
+		m.pending = nil
+		m.status.Pending = nil
+		m.statusMutex.Unlock()
+		if pending == nil {
+			continue
+		}
+		m.createSnapshot(*pending)
+		m.state.UnpinVersion(int64(*pending))
+	}
+}
+
+// Status returns the progress of the snapshot being built or the result of the last one
+func (m *SnapshotManager) Status() SnapshotStatus {
+	m.statusMutex.Lock()
+	defer m.statusMutex.Unlock()
+	status := m.status
+	if status.Stage == SnapshotWriting || status.Stage == SnapshotPublishing {
+		status.Duration = time.Since(time.Unix(status.Started, 0)).Seconds()
+	}
+	_, _, status.LastHeight, _ = m.repo.LastSnapshotManifest()
+	return status
+}
+
+func (m *SnapshotManager) updateStatus(update func(status *SnapshotStatus)) {
+	m.statusMutex.Lock()
+	defer m.statusMutex.Unlock()
+	update(&m.status)
+}
+
 func (m *SnapshotManager) createSnapshot(height uint64) (root common.Hash) {
+	started := time.Now()
+	m.updateStatus(func(status *SnapshotStatus) {
+		*status = SnapshotStatus{Stage: SnapshotWriting, Height: height, Started: started.Unix(), Pending: status.Pending}
+	})
+	fail := func(err error) common.Hash {
+		m.updateStatus(func(status *SnapshotStatus) {
+			status.Stage = SnapshotFailed
+			status.Error = err.Error()
+			status.Finished = time.Now().Unix()
+			status.Duration = time.Since(started).Seconds()
+		})
+		return common.Hash{}
+	}
 	filePath, file, err := createSnapshotFile(m.cfg.DataDir, height)
 	if err != nil {
 		m.log.Error("Cannot create file for snapshot", "err", err)
-		return common.Hash{}
+		return fail(errors.Wrap(err, "cannot create file"))
 	}
 
-	if root, err = m.state.WriteSnapshot(height, file); err != nil {
+	if root, err = m.state.WriteSnapshotWithProgress(height, file, func(entries int) {
+		m.updateStatus(func(status *SnapshotStatus) {
+			status.Entries = entries
+		})
+	}); err != nil {
 		file.Close()
+		os.Remove(filePath)
 		m.log.Error("Cannot write snapshot to file", "err", err)
-		return common.Hash{}
+		return fail(errors.Wrap(err, "cannot write snapshot"))
 	}
 	file.Close()
+	m.updateStatus(func(status *SnapshotStatus) {
+		status.Stage = SnapshotPublishing
+		status.Root = root
+	})
 	var f *os.File
 	var cid cid.Cid
 	if f, err = os.Open(filePath); err != nil {
 		m.log.Error("Cannot open snapshot file", "err", err)
 		os.Remove(filePath)
-		return
+		return fail(errors.Wrap(err, "cannot open file"))
 	}
 	stat, _ := f.Stat()
 	if cid, err = m.ipfs.AddFile(f.Name(), f, stat); err != nil {
 		m.log.Error("Cannot add snapshot file to ipfs", "err", err)
 		f.Close()
-		if err = os.Remove(filePath); err != nil {
+		if err := os.Remove(filePath); err != nil {
 			m.log.Error("Cannot remove file", "err", err)
 		}
-		return
+		return fail(errors.Wrap(err, "cannot add snapshot to ipfs"))
 	}
 	m.clearFs(filePath)
 	m.writeLastManifest(cid.Bytes(), root, height, filePath)
+	m.bus.Publish(&events.IpfsPinnedEvent{Cid: cid.Bytes(), DataType: ipfs.Snapshot})
+	m.updateStatus(func(status *SnapshotStatus) {
+		status.Stage = SnapshotDone
+		status.Finished = time.Now().Unix()
+		status.Duration = time.Since(started).Seconds()
+	})
+	m.log.Info("Snapshot is created", "height", height, "duration", time.Since(started))
 	return root
 }
 
@@ -152,6 +270,15 @@ func (m *SnapshotManager) writeLastManifest(snapshotCid []byte, root common.Hash
 }
 
 func (m *SnapshotManager) DownloadSnapshot(snapshot *snapshot.Manifest) (filePath string, err error) {
+	if m.mirror != nil {
+		if filePath, err := m.downloadSnapshotFromMirror(snapshot); err == nil {
+			m.clearFs(filePath)
+			m.writeLastManifest(snapshot.Cid, snapshot.Root, snapshot.Height, filePath)
+			return filePath, nil
+		} else {
+			m.log.Warn("Cannot load snapshot from HTTP mirror, IPFS will be used", "err", err)
+		}
+	}
 	filePath, file, err := createSnapshotFile(m.cfg.DataDir, snapshot.Height)
 	if err != nil {
 		return "", err
@@ -199,6 +326,45 @@ func (m *SnapshotManager) DownloadSnapshot(snapshot *snapshot.Manifest) (filePat
 	return filePath, loadToErr
 }
 
+// downloadSnapshotFromMirror loads the snapshot over HTTP and verifies it by adding to IPFS, the cid of the added file
+// must be equal to the manifest's one
+func (m *SnapshotManager) downloadSnapshotFromMirror(snapshot *snapshot.Manifest) (string, error) {
+	filePath, file, err := createSnapshotFile(m.cfg.DataDir, snapshot.Height)
+	if err != nil {
+		return "", err
+	}
+	logLevels := []float32{0.15, 0.3, 0.5, 0.75}
+	err = m.mirror.LoadSnapshot(snapshot, file, func(size, read int64) {
+		if size > 0 && len(logLevels) > 0 && float32(read)/float32(size) >= logLevels[0] {
+			m.log.Info("Snapshot loading from HTTP mirror", "progress", fmt.Sprintf("%v%%", logLevels[0]*100))
+			logLevels = logLevels[1:]
+		}
+	})
+	file.Close()
+	if err != nil {
+		os.Remove(filePath)
+		return "", err
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		os.Remove(filePath)
+		return "", err
+	}
+	defer f.Close()
+	stat, _ := f.Stat()
+	c, err := m.ipfs.AddFile(f.Name(), f, stat)
+	if err != nil {
+		os.Remove(filePath)
+		return "", errors.Wrap(err, "cannot add snapshot to ipfs")
+	}
+	if !bytes.Equal(c.Bytes(), snapshot.Cid) {
+		m.ipfs.Unpin(c.Bytes())
+		os.Remove(filePath)
+		return "", errors.New("snapshot doesn't match manifest cid")
+	}
+	return filePath, nil
+}
+
 func (m *SnapshotManager) StartSync() {
 	m.isSyncing = true
 }
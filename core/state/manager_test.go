@@ -1,6 +1,8 @@
 package state
 
 import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/log"
 	"github.com/stretchr/testify/require"
 	db "github.com/tendermint/tm-db"
 	"testing"
@@ -28,3 +30,30 @@ func TestSnapshotManager_IsInvalidManifest(t *testing.T) {
 	require.True(t, m.IsInvalidManifest([]byte{0x3}))
 	require.False(t, m.IsInvalidManifest([]byte{0x4}))
 }
+
+func TestSnapshotManager_createSnapshotIfNeeded(t *testing.T) {
+	stateDb := NewLazy(db.NewMemDB())
+	m := &SnapshotManager{
+		state:  stateDb,
+		log:    log.New(),
+		wakeup: make(chan struct{}, 1),
+	}
+	header := func(height uint64) *types.Header {
+		return &types.Header{ProposedHeader: &types.ProposedHeader{Height: height}}
+	}
+	stateDb.SetLastSnapshot(1)
+	m.createSnapshotIfNeeded(header(2))
+	require.Nil(t, m.pending)
+
+	m.createSnapshotIfNeeded(header(1))
+	require.Equal(t, uint64(1), *m.pending)
+	require.True(t, stateDb.isPinned(1))
+
+	// the pending snapshot is replaced by the newer one, the worker is woken up once
+	stateDb.SetLastSnapshot(3)
+	m.createSnapshotIfNeeded(header(3))
+	require.Equal(t, uint64(3), *m.status.Pending)
+	require.False(t, stateDb.isPinned(1))
+	require.True(t, stateDb.isPinned(3))
+	require.Len(t, m.wakeup, 1)
+}
@@ -1,21 +1,71 @@
 package state
 
 import (
+	"container/list"
 	"github.com/idena-network/idena-go/common"
 	"sync"
+	"sync/atomic"
 )
 
 type account struct {
 	stateObject *stateAccount
 	nonce       uint32
+	// base is the nonce taken from the state, the account is not used by pending txs while nonce equals base
+	base uint32
 }
 
+const (
+	// nonceCacheShards is the number of independently locked account groups, must be a power of two
+	nonceCacheShards = 64
+	// evictionScanDepth is the number of least recently used addresses checked for eviction on insertion
+	evictionScanDepth = 8
+)
+
+type nonceEntry struct {
+	epochs map[uint16]*account
+	elem   *list.Element
+}
+
+type nonceShard struct {
+	mu       sync.Mutex
+	accounts map[common.Address]*nonceEntry
+	lru      *list.List
+}
+
+func newNonceShard() *nonceShard {
+	return &nonceShard{
+		accounts: make(map[common.Address]*nonceEntry),
+		lru:      list.New(),
+	}
+}
+
+type NonceCacheStats struct {
+	Addresses      int    `json:"addresses"`
+	Limit          int    `json:"limit"`
+	Hits           uint64 `json:"hits"`
+	Misses         uint64 `json:"misses"`
+	Evictions      uint64 `json:"evictions"`
+	EpochEvictions uint64 `json:"epochEvictions"`
+}
+
+// NonceCache tracks pending nonces of accounts. Accounts are split into shards with own locks, so validation of txs
+// of different senders doesn't serialize, Lock takes the whole cache over for batch updates.
+// The number of tracked addresses is bounded by the limit, least recently used addresses without pending nonces
+// are evicted first, pinned addresses are never evicted.
 type NonceCache struct {
-	fallback *StateDB
+	fallback      *StateDB
+	fallbackEpoch uint16
 
-	mu sync.Mutex
+	mu sync.RWMutex
 
-	accounts map[common.Address]map[uint16]*account
+	shards     [nonceCacheShards]*nonceShard
+	shardLimit int
+	pinned     map[common.Address]struct{}
+
+	hits           uint64
+	misses         uint64
+	evictions      uint64
+	epochEvictions uint64
 }
 
 func NewNonceCache(sdb *StateDB) (*NonceCache, error) {
@@ -23,21 +73,58 @@ func NewNonceCache(sdb *StateDB) (*NonceCache, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &NonceCache{
-		fallback: readonly,
-		accounts: make(map[common.Address]map[uint16]*account),
-	}, nil
+	ns := &NonceCache{
+		fallback:      readonly,
+		fallbackEpoch: readonly.Epoch(),
+		pinned:        make(map[common.Address]struct{}),
+	}
+	for i := range ns.shards {
+		ns.shards[i] = newNonceShard()
+	}
+	return ns, nil
 }
 
-// GetNonce returns the canonical nonce for the managed or unmanaged account.
-// Because GetNonce mutates the DB, we must take a write lock.
-func (ns *NonceCache) GetNonce(addr common.Address, epoch uint16) uint32 {
+func (ns *NonceCache) shard(addr common.Address) *nonceShard {
+	return ns.shards[addr[common.AddressLength-1]&(nonceCacheShards-1)]
+}
+
+// SetLimit bounds the number of tracked addresses, 0 - unlimited, pinned addresses are never evicted
+func (ns *NonceCache) SetLimit(limit int, pinned ...common.Address) {
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+	ns.shardLimit = 0
+	if limit > 0 {
+		ns.shardLimit = (limit + nonceCacheShards - 1) / nonceCacheShards
+	}
+	ns.pinned = make(map[common.Address]struct{}, len(pinned))
+	for _, addr := range pinned {
+		ns.pinned[addr] = struct{}{}
+	}
+}
+
+// InheritLimit applies the limit and pinned addresses of the replaced cache
+func (ns *NonceCache) InheritLimit(prev *NonceCache) {
+	prev.mu.RLock()
+	defer prev.mu.RUnlock()
 	ns.mu.Lock()
 	defer ns.mu.Unlock()
+	ns.shardLimit = prev.shardLimit
+	ns.pinned = prev.pinned
+}
+
+// GetNonce returns the canonical nonce for the managed or unmanaged account.
+// Because GetNonce mutates the cache, the shard of the address is locked exclusively.
+func (ns *NonceCache) GetNonce(addr common.Address, epoch uint16) uint32 {
+	ns.mu.RLock()
+	defer ns.mu.RUnlock()
+	shard := ns.shard(addr)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
 
-	return ns.getAccount(addr, epoch).nonce
+	return ns.getAccount(shard, addr, epoch).nonce
 }
 
+// Lock blocks all readers and writers, Unsafe methods may be called until UnLock
 func (ns *NonceCache) Lock() {
 	ns.mu.Lock()
 }
@@ -46,63 +133,147 @@ func (ns *NonceCache) UnLock() {
 	ns.mu.Unlock()
 }
 
+// ReloadFallback switches the cache to the new state, entries of past epochs are evicted
 func (ns *NonceCache) ReloadFallback(sdb *StateDB) error {
 	readonly, err := sdb.Readonly(-1)
 	if err != nil {
 		return err
 	}
 	ns.fallback = readonly
+	if epoch := readonly.Epoch(); epoch != ns.fallbackEpoch {
+		ns.fallbackEpoch = epoch
+		ns.evictStaleEpochs()
+	}
 	return nil
 }
 
+func (ns *NonceCache) evictStaleEpochs() {
+	for _, shard := range ns.shards {
+		for addr, entry := range shard.accounts {
+			for epoch := range entry.epochs {
+				if epoch < ns.fallbackEpoch {
+					delete(entry.epochs, epoch)
+				}
+			}
+			if len(entry.epochs) == 0 {
+				shard.lru.Remove(entry.elem)
+				delete(shard.accounts, addr)
+				ns.epochEvictions++
+			}
+		}
+	}
+}
+
 // SetNonce sets the new canonical nonce for the managed state
 func (ns *NonceCache) SetNonce(addr common.Address, txEpoch uint16, nonce uint32) {
-	ns.mu.Lock()
-	defer ns.mu.Unlock()
+	ns.mu.RLock()
+	defer ns.mu.RUnlock()
+	shard := ns.shard(addr)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
 
-	ns.UnsafeSetNonce(addr, txEpoch, nonce)
+	ns.setNonce(shard, addr, txEpoch, nonce)
 }
 
+// UnsafeSetNonce sets the nonce without locking, the caller must hold Lock
 func (ns *NonceCache) UnsafeSetNonce(addr common.Address, txEpoch uint16, nonce uint32) {
-	acc := ns.getAccount(addr, txEpoch)
+	ns.setNonce(ns.shard(addr), addr, txEpoch, nonce)
+}
+
+func (ns *NonceCache) setNonce(shard *nonceShard, addr common.Address, txEpoch uint16, nonce uint32) {
+	acc := ns.getAccount(shard, addr, txEpoch)
 	if acc.nonce < nonce {
 		acc.nonce = nonce
 	}
 }
 
 // populate the managed state
-func (ns *NonceCache) getAccount(addr common.Address, epoch uint16) *account {
-	if epochs, ok := ns.accounts[addr]; !ok {
+func (ns *NonceCache) getAccount(shard *nonceShard, addr common.Address, epoch uint16) *account {
+	entry, ok := shard.accounts[addr]
+	if !ok {
+		ns.evict(shard)
+		entry = &nonceEntry{epochs: make(map[uint16]*account)}
+		entry.elem = shard.lru.PushFront(addr)
+		shard.accounts[addr] = entry
+	} else {
+		shard.lru.MoveToFront(entry.elem)
+	}
+	if acc, ok := entry.epochs[epoch]; !ok {
+		atomic.AddUint64(&ns.misses, 1)
 		so := ns.fallback.GetOrNewAccountObject(addr)
-		ns.accounts[addr] = make(map[uint16]*account)
-		ns.accounts[addr][epoch] = ns.newAccount(so, epoch)
+		entry.epochs[epoch] = ns.newAccount(so, epoch)
 	} else {
-		if acc, ok := epochs[epoch]; !ok {
-			so := ns.fallback.GetOrNewAccountObject(addr)
-			ns.accounts[addr][epoch] = ns.newAccount(so, epoch)
-		} else {
-			// Always make sure the state account nonce isn't actually higher
-			// than the tracked one.
-			so := ns.fallback.getStateAccount(addr)
-			if so != nil && acc.nonce < so.Nonce() && so.Epoch() == epoch {
-				ns.accounts[addr][epoch] = ns.newAccount(so, epoch)
-			}
+		atomic.AddUint64(&ns.hits, 1)
+		// Always make sure the state account nonce isn't actually higher
+		// than the tracked one.
+		so := ns.fallback.getStateAccount(addr)
+		if so != nil && acc.nonce < so.Nonce() && so.Epoch() == epoch {
+			entry.epochs[epoch] = ns.newAccount(so, epoch)
 		}
 	}
 
-	return ns.accounts[addr][epoch]
+	return entry.epochs[epoch]
+}
+
+// evict drops the least recently used address of the full shard unless it is pinned or has pending nonces
+func (ns *NonceCache) evict(shard *nonceShard) {
+	if ns.shardLimit == 0 || len(shard.accounts) < ns.shardLimit {
+		return
+	}
+	elem := shard.lru.Back()
+	for i := 0; i < evictionScanDepth && elem != nil; i++ {
+		addr := elem.Value.(common.Address)
+		prev := elem.Prev()
+		if _, ok := ns.pinned[addr]; !ok && !shard.accounts[addr].pending() {
+			shard.lru.Remove(elem)
+			delete(shard.accounts, addr)
+			atomic.AddUint64(&ns.evictions, 1)
+			return
+		}
+		elem = prev
+	}
+}
+
+func (e *nonceEntry) pending() bool {
+	for _, acc := range e.epochs {
+		if acc.nonce != acc.base {
+			return true
+		}
+	}
+	return false
 }
 
 func (ns *NonceCache) newAccount(so *stateAccount, epoch uint16) *account {
 
 	nonce := so.Nonce()
-	if so.Epoch() < ns.fallback.Epoch() || so.Epoch() < epoch {
+	if so.Epoch() < ns.fallbackEpoch || so.Epoch() < epoch {
 		nonce = 0
 	}
 
-	return &account{so, nonce}
+	return &account{so, nonce, nonce}
 }
 
+// Clear drops all tracked nonces, the caller must hold Lock
 func (ns *NonceCache) Clear() {
-	ns.accounts = make(map[common.Address]map[uint16]*account)
+	for i := range ns.shards {
+		ns.shards[i] = newNonceShard()
+	}
+}
+
+func (ns *NonceCache) Stats() NonceCacheStats {
+	ns.mu.RLock()
+	defer ns.mu.RUnlock()
+	stats := NonceCacheStats{
+		Limit:          ns.shardLimit * nonceCacheShards,
+		Hits:           atomic.LoadUint64(&ns.hits),
+		Misses:         atomic.LoadUint64(&ns.misses),
+		Evictions:      atomic.LoadUint64(&ns.evictions),
+		EpochEvictions: ns.epochEvictions,
+	}
+	for _, shard := range ns.shards {
+		shard.mu.Lock()
+		stats.Addresses += len(shard.accounts)
+		shard.mu.Unlock()
+	}
+	return stats
 }
@@ -3,6 +3,8 @@ package state
 import (
 	"github.com/idena-network/idena-go/common"
 	"github.com/stretchr/testify/require"
+	"sync"
+	"sync/atomic"
 	"testing"
 )
 import dbm "github.com/tendermint/tm-db"
@@ -40,3 +42,98 @@ func TestNonceCache_Set_And_Get_Nonce(t *testing.T) {
 	require.Equal(uint32(0), ns.GetNonce(addr, epoch+2))
 
 }
+
+func TestNonceCache_Concurrent(t *testing.T) {
+	stateDb := NewLazy(dbm.NewMemDB())
+	stateDb.IncEpoch()
+	stateDb.Commit(false)
+	ns, _ := NewNonceCache(stateDb)
+
+	addrs := make([]common.Address, 200)
+	for i := range addrs {
+		addrs[i].SetBytes([]byte{byte(i), 0x1})
+	}
+	wg := sync.WaitGroup{}
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for nonce := uint32(1); nonce <= 50; nonce++ {
+				for _, addr := range addrs {
+					ns.SetNonce(addr, 1, nonce)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for _, addr := range addrs {
+		require.Equal(t, uint32(50), ns.GetNonce(addr, 1))
+	}
+
+	ns.Lock()
+	ns.Clear()
+	ns.UnsafeSetNonce(addrs[0], 1, 3)
+	ns.UnLock()
+	require.Equal(t, uint32(3), ns.GetNonce(addrs[0], 1))
+	require.Equal(t, uint32(0), ns.GetNonce(addrs[1], 1))
+}
+
+func TestNonceCache_Eviction(t *testing.T) {
+	stateDb := NewLazy(dbm.NewMemDB())
+	stateDb.IncEpoch()
+	stateDb.Commit(false)
+	ns, _ := NewNonceCache(stateDb)
+
+	var coinbase, busy common.Address
+	coinbase.SetBytes([]byte{0xff, 0x0})
+	busy.SetBytes([]byte{0xfe, 0x0})
+	ns.SetLimit(nonceCacheShards, coinbase)
+
+	ns.GetNonce(coinbase, 1)
+	ns.SetNonce(busy, 1, 5)
+	for i := 1; i <= 10; i++ {
+		var addr common.Address
+		addr.SetBytes([]byte{byte(i), 0x0})
+		ns.GetNonce(addr, 1)
+	}
+	stats := ns.Stats()
+	require.Equal(t, 3, stats.Addresses)
+	require.Equal(t, uint64(9), stats.Evictions)
+	require.Equal(t, uint32(5), ns.GetNonce(busy, 1))
+	require.Equal(t, uint64(1), ns.Stats().Hits)
+
+	ns.SetNonce(busy, 2, 1)
+	stateDb.IncEpoch()
+	stateDb.Commit(false)
+	ns.Lock()
+	require.NoError(t, ns.ReloadFallback(stateDb))
+	ns.UnLock()
+	stats = ns.Stats()
+	require.Equal(t, 1, stats.Addresses)
+	require.Equal(t, uint64(2), stats.EpochEvictions)
+	require.Equal(t, uint32(1), ns.GetNonce(busy, 2))
+}
+
+func BenchmarkNonceCache_Parallel(b *testing.B) {
+	db := dbm.NewMemDB()
+	stateDb := NewLazy(db)
+	stateDb.IncEpoch()
+	addrs := make([]common.Address, 10000)
+	for i := range addrs {
+		addrs[i].SetBytes([]byte{byte(i >> 8), byte(i), 0x1})
+		stateDb.SetNonce(addrs[i], 1)
+		stateDb.SetEpoch(addrs[i], 1)
+	}
+	stateDb.Commit(false)
+	ns, _ := NewNonceCache(stateDb)
+
+	var next uint64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			i := atomic.AddUint64(&next, 1)
+			addr := addrs[i%uint64(len(addrs))]
+			ns.SetNonce(addr, 1, ns.GetNonce(addr, 1)+1)
+		}
+	})
+}
@@ -0,0 +1,147 @@
+package state
+
+import (
+	"container/list"
+	"github.com/rcrowley/go-metrics"
+	"sync"
+)
+
+// readCacheSize is the number of account and identity records kept by the read cache
+const readCacheSize = 20000
+
+type readCacheKey struct {
+	version int64
+	key     string
+}
+
+type readCacheEntry struct {
+	key  readCacheKey
+	data []byte
+}
+
+type ReadCacheStats struct {
+	Size   int     `json:"size"`
+	Hits   int64   `json:"hits"`
+	Misses int64   `json:"misses"`
+	Rate   float64 `json:"hitRate"`
+}
+
+// readCache is the LRU of raw tree records shared by readonly copies of the state, so hot accounts are not
+// traversed in IAVL each time a new copy is created for tx validation or RPC. Records are keyed by the tree version,
+// the cache is dropped when the origin state commits or rewinds its tree because versions may be overwritten.
+type readCache struct {
+	mu         sync.Mutex
+	generation uint64
+	entries    map[readCacheKey]*list.Element
+	lru        *list.List
+	hits       int64
+	misses     int64
+}
+
+// counters are shared by all caches of the process
+var (
+	readCacheHits   = metrics.GetOrRegisterCounter("state/readcache/hits", metrics.DefaultRegistry)
+	readCacheMisses = metrics.GetOrRegisterCounter("state/readcache/misses", metrics.DefaultRegistry)
+)
+
+func newReadCache() *readCache {
+	return &readCache{
+		entries: make(map[readCacheKey]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+func (c *readCache) currentGeneration() uint64 {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+func (c *readCache) get(generation uint64, version int64, key []byte) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return nil, false
+	}
+	if elem, ok := c.entries[readCacheKey{version, string(key)}]; ok {
+		c.lru.MoveToFront(elem)
+		c.hits++
+		readCacheHits.Inc(1)
+		return elem.Value.(*readCacheEntry).data, true
+	}
+	c.misses++
+	readCacheMisses.Inc(1)
+	return nil, false
+}
+
+func (c *readCache) add(generation uint64, version int64, key []byte, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	k := readCacheKey{version, string(key)}
+	if _, ok := c.entries[k]; ok {
+		return
+	}
+	c.entries[k] = c.lru.PushFront(&readCacheEntry{key: k, data: data})
+	if c.lru.Len() > readCacheSize {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*readCacheEntry).key)
+	}
+}
+
+func (c *readCache) invalidate() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.entries = make(map[readCacheKey]*list.Element)
+	c.lru.Init()
+}
+
+func (c *readCache) stats() ReadCacheStats {
+	if c == nil {
+		return ReadCacheStats{}
+	}
+	c.mu.Lock()
+	stats := ReadCacheStats{Size: c.lru.Len(), Hits: c.hits, Misses: c.misses}
+	c.mu.Unlock()
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.Rate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+// cachedTreeGet reads the record through the shared cache while the tree of the copy is not modified
+func (s *StateDB) cachedTreeGet(key []byte) []byte {
+	if !s.useReadCache || s.readCache == nil || s.treeModified {
+		_, enc := s.tree.Get(key)
+		return enc
+	}
+	version := s.tree.Version()
+	if enc, ok := s.readCache.get(s.cacheGeneration, version, key); ok {
+		return enc
+	}
+	_, enc := s.tree.Get(key)
+	s.readCache.add(s.cacheGeneration, version, key, enc)
+	return enc
+}
+
+// invalidateReadCache drops the shared cache when the origin state changes its tree, changes of copies are local
+func (s *StateDB) invalidateReadCache() {
+	if !s.useReadCache {
+		s.readCache.invalidate()
+	}
+}
+
+// ReadCacheStats returns the size and hit rate of the read cache shared by readonly copies of the state
+func (s *StateDB) ReadCacheStats() ReadCacheStats {
+	return s.readCache.stats()
+}
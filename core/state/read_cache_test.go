@@ -0,0 +1,47 @@
+package state
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+	"math/big"
+	"testing"
+)
+
+func TestStateDB_ReadCache(t *testing.T) {
+	stateDb := NewLazy(dbm.NewMemDB())
+	addr := common.Address{0x1}
+	stateDb.SetBalance(addr, big.NewInt(10))
+	stateDb.Commit(false)
+
+	first, err := stateDb.Readonly(-1)
+	require.NoError(t, err)
+	require.Zero(t, big.NewInt(10).Cmp(first.GetBalance(addr)))
+	second, _ := stateDb.Readonly(-1)
+	require.Zero(t, big.NewInt(10).Cmp(second.GetBalance(addr)))
+	stats := stateDb.ReadCacheStats()
+	require.Equal(t, int64(1), stats.Hits)
+	require.Equal(t, 1, stats.Size)
+
+	stateDb.SetBalance(addr, big.NewInt(20))
+	stateDb.Commit(false)
+	require.Zero(t, stateDb.ReadCacheStats().Size)
+
+	// copy created before the commit doesn't write outdated records to the cache
+	second.stateAccounts = make(map[common.Address]*stateAccount)
+	require.Zero(t, big.NewInt(10).Cmp(second.GetBalance(addr)))
+	require.Zero(t, stateDb.ReadCacheStats().Size)
+
+	third, _ := stateDb.Readonly(-1)
+	require.Zero(t, big.NewInt(20).Cmp(third.GetBalance(addr)))
+
+	// modified copy reads its own tree and keeps the shared cache
+	forCheck, _ := stateDb.ForCheck(uint64(stateDb.Version()))
+	forCheck.SetBalance(addr, big.NewInt(30))
+	forCheck.Commit(false)
+	forCheck.stateAccounts = make(map[common.Address]*stateAccount)
+	require.Zero(t, big.NewInt(30).Cmp(forCheck.GetBalance(addr)))
+	require.Equal(t, 1, stateDb.ReadCacheStats().Size)
+	fourth, _ := stateDb.Readonly(-1)
+	require.Zero(t, big.NewInt(20).Cmp(fourth.GetBalance(addr)))
+}
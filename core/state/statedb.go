@@ -17,6 +17,7 @@
 package state
 
 import (
+	"encoding/binary"
 	"fmt"
 	"github.com/idena-network/idena-go/blockchain/types"
 	"github.com/idena-network/idena-go/core/state/snapshot"
@@ -31,6 +32,7 @@ import (
 	"time"
 
 	"github.com/idena-network/idena-go/common"
+	"github.com/tendermint/iavl"
 	dbm "github.com/tendermint/tm-db"
 	"math/big"
 	"sync"
@@ -79,6 +81,16 @@ type StateDB struct {
 
 	log  log.Logger
 	lock sync.Mutex
+
+	// pinned versions aren't pruned while snapshots of them are written
+	pinMutex sync.Mutex
+	pinned   map[int64]int
+
+	// readCache is created by the origin state and shared with its copies, only copies read through it
+	readCache       *readCache
+	useReadCache    bool
+	cacheGeneration uint64
+	treeModified    bool
 }
 
 func NewLazy(db dbm.DB) *StateDB {
@@ -92,6 +104,7 @@ func NewLazy(db dbm.DB) *StateDB {
 		stateAccountsDirty: make(map[common.Address]struct{}), stateIdentities: make(map[common.Address]*stateIdentity),
 		stateIdentitiesDirty: make(map[common.Address]struct{}),
 		log:                  log.New(),
+		readCache:            newReadCache(),
 	}
 }
 
@@ -116,6 +129,7 @@ func (s *StateDB) ForCheckWithOverwrite(height uint64) (*StateDB, error) {
 func (s *StateDB) ForCheck(height uint64) (*StateDB, error) {
 	db := database.NewBackedMemDb(s.db)
 	tree := NewMutableTreeWithOpts(db, database.NewBackedMemDb(s.tree.RecentDb()), s.tree.KeepEvery(), s.tree.KeepRecent())
+	generation := s.readCache.currentGeneration()
 	if _, err := tree.LoadVersion(int64(height)); err != nil {
 		return nil, err
 	}
@@ -127,11 +141,15 @@ func (s *StateDB) ForCheck(height uint64) (*StateDB, error) {
 		stateIdentities:      make(map[common.Address]*stateIdentity),
 		stateIdentitiesDirty: make(map[common.Address]struct{}),
 		log:                  log.New(),
+		readCache:            s.readCache,
+		useReadCache:         true,
+		cacheGeneration:      generation,
 	}, nil
 }
 
 func (s *StateDB) Readonly(height int64) (*StateDB, error) {
 	tree := NewMutableTreeWithOpts(s.db, s.tree.RecentDb(), s.tree.KeepEvery(), s.tree.KeepRecent())
+	generation := s.readCache.currentGeneration()
 	if _, err := tree.LazyLoad(height); err != nil {
 		return nil, err
 	}
@@ -143,10 +161,14 @@ func (s *StateDB) Readonly(height int64) (*StateDB, error) {
 		stateIdentities:      make(map[common.Address]*stateIdentity),
 		stateIdentitiesDirty: make(map[common.Address]struct{}),
 		log:                  log.New(),
+		readCache:            s.readCache,
+		useReadCache:         true,
+		cacheGeneration:      generation,
 	}, nil
 }
 
 func (s *StateDB) Load(height uint64) error {
+	s.invalidateReadCache()
 	_, err := s.tree.LoadVersion(int64(height))
 	return err
 }
@@ -500,6 +522,7 @@ func (s *StateDB) updateStateAccountObject(stateObject *stateAccount) {
 		panic(fmt.Errorf("can't encode object at %x: %v", addr[:], err))
 	}
 
+	s.treeModified = true
 	s.tree.Set(append(addressPrefix, addr[:]...), data)
 }
 
@@ -511,6 +534,7 @@ func (s *StateDB) updateStateIdentityObject(stateObject *stateIdentity) {
 		panic(fmt.Errorf("can't encode object at %x: %v", addr[:], err))
 	}
 
+	s.treeModified = true
 	s.tree.Set(append(identityPrefix, addr[:]...), data)
 }
 
@@ -521,6 +545,7 @@ func (s *StateDB) updateStateGlobalObject(stateObject *stateGlobal) {
 		panic(fmt.Errorf("can't encode object, %v", err))
 	}
 
+	s.treeModified = true
 	s.tree.Set(globalKey, data)
 }
 
@@ -531,6 +556,7 @@ func (s *StateDB) updateStateStatusSwitchObject(stateObject *stateStatusSwitch)
 		panic(fmt.Errorf("can't encode object, %v", err))
 	}
 
+	s.treeModified = true
 	s.tree.Set(statusSwitchKey, data)
 }
 
@@ -539,6 +565,7 @@ func (s *StateDB) deleteStateAccountObject(stateObject *stateAccount) {
 	stateObject.deleted = true
 	addr := stateObject.Address()
 
+	s.treeModified = true
 	s.tree.Remove(append(addressPrefix, addr[:]...))
 }
 
@@ -547,6 +574,7 @@ func (s *StateDB) deleteStateIdentityObject(stateObject *stateIdentity) {
 	stateObject.deleted = true
 	addr := stateObject.Address()
 
+	s.treeModified = true
 	s.tree.Remove(append(identityPrefix, addr[:]...))
 }
 
@@ -563,7 +591,7 @@ func (s *StateDB) getStateAccount(addr common.Address) (stateObject *stateAccoun
 	}
 	s.lock.Unlock()
 	// Load the object from the database.
-	_, enc := s.tree.Get(append(addressPrefix, addr[:]...))
+	enc := s.cachedTreeGet(append(addressPrefix, addr[:]...))
 	if len(enc) == 0 {
 		return nil
 	}
@@ -592,7 +620,7 @@ func (s *StateDB) getStateIdentity(addr common.Address) (stateObject *stateIdent
 	s.lock.Unlock()
 
 	// Load the object from the database.
-	_, enc := s.tree.Get(append(identityPrefix, addr[:]...))
+	enc := s.cachedTreeGet(append(identityPrefix, addr[:]...))
 	if len(enc) == 0 {
 		return nil
 	}
@@ -795,12 +823,16 @@ func (s *StateDB) SaveForcedVersion(height uint64) (root []byte, version int64,
 }
 
 func (s *StateDB) CommitTree(newVersion int64) (root []byte, version int64, err error) {
+	s.invalidateReadCache()
 	hash, version, err := s.tree.SaveVersionAt(newVersion)
 	if version > MaxSavedStatesCount {
 
 		versions := s.tree.AvailableVersions()
 
 		for i := 0; i < len(versions)-MaxSavedStatesCount; i++ {
+			if s.isPinned(int64(versions[i])) {
+				continue
+			}
 			if s.tree.ExistVersion(int64(versions[i])) {
 				err = s.tree.DeleteVersion(int64(versions[i]))
 				if err != nil {
@@ -814,6 +846,32 @@ func (s *StateDB) CommitTree(newVersion int64) (root []byte, version int64, err
 	return hash, version, err
 }
 
+// PinVersion keeps the version from pruning until it is unpinned, versions are pinned by snapshot writers
+func (s *StateDB) PinVersion(version int64) {
+	s.pinMutex.Lock()
+	defer s.pinMutex.Unlock()
+	if s.pinned == nil {
+		s.pinned = make(map[int64]int)
+	}
+	s.pinned[version]++
+}
+
+func (s *StateDB) UnpinVersion(version int64) {
+	s.pinMutex.Lock()
+	defer s.pinMutex.Unlock()
+	if s.pinned[version] <= 1 {
+		delete(s.pinned, version)
+		return
+	}
+	s.pinned[version]--
+}
+
+func (s *StateDB) isPinned(version int64) bool {
+	s.pinMutex.Lock()
+	defer s.pinMutex.Unlock()
+	return s.pinned[version] > 0
+}
+
 func (s *StateDB) Precommit(deleteEmptyObjects bool) {
 	s.lock.Lock()
 	// Commit account objects to the trie.
@@ -907,6 +965,16 @@ func (s *StateDB) IterateIdentities(fn func(key []byte, value []byte) bool) bool
 	return s.tree.GetImmutable().IterateRange(start, end, true, fn)
 }
 
+// IdentityKey returns the key of the identity in the state tree
+func IdentityKey(addr common.Address) []byte {
+	return append(append([]byte{}, identityPrefix...), addr[:]...)
+}
+
+// IdentityWithProof returns the encoded identity of the loaded version with the proof against the state root
+func (s *StateDB) IdentityWithProof(addr common.Address) ([]byte, *iavl.RangeProof, error) {
+	return s.tree.GetImmutable().GetWithProof(IdentityKey(addr))
+}
+
 func (s *StateDB) IterateAccounts(fn func(key []byte, value []byte) bool) bool {
 	start := append(addressPrefix, common.MinAddr...)
 	end := append(addressPrefix, common.MaxAddr...)
@@ -946,6 +1014,7 @@ func (s *StateDB) GetIdentityState(addr common.Address) IdentityState {
 }
 
 func (s *StateDB) ResetTo(height uint64) error {
+	s.invalidateReadCache()
 	s.Clear()
 	_, err := s.tree.LoadVersionForOverwriting(int64(height))
 	return err
@@ -1006,6 +1075,12 @@ func prefix(height uint64) []byte {
 }
 
 func (s *StateDB) WriteSnapshot(height uint64, to io.Writer) (root common.Hash, err error) {
+	return s.WriteSnapshotWithProgress(height, to, nil)
+}
+
+// WriteSnapshotWithProgress writes the tree of the version, onBlock is called with the number of written entries.
+// Blocks are imported while the snapshot is written, so roots and orphans of newer versions are skipped.
+func (s *StateDB) WriteSnapshotWithProgress(height uint64, to io.Writer, onBlock func(entries int)) (root common.Hash, err error) {
 	db := database.NewBackedMemDb(s.db)
 	tree := NewMutableTree(db)
 	if _, err := tree.LoadVersionForOverwriting(int64(height)); err != nil {
@@ -1045,15 +1120,22 @@ func (s *StateDB) WriteSnapshot(height uint64, to io.Writer) (root common.Hash,
 		})
 	}
 
-	i := 0
+	i, entries := 0, 0
 	for ; it.Valid(); it.Next() {
+		if isNewerVersionKey(it.Key(), height) {
+			continue
+		}
 		sb.Add(it.Key(), it.Value())
+		entries++
 		if sb.Full() {
 			if err := writeBlock(sb, strconv.Itoa(i)); err != nil {
 				return common.Hash{}, err
 			}
 			i++
 			sb = &snapshot.Block{}
+			if onBlock != nil {
+				onBlock(entries)
+			}
 		}
 	}
 	if len(sb.Data) > 0 {
@@ -1064,6 +1146,17 @@ func (s *StateDB) WriteSnapshot(height uint64, to io.Writer) (root common.Hash,
 	return tree.WorkingHash(), tar.Close()
 }
 
+// isNewerVersionKey checks iavl root keys r<version> and orphan keys o<last-version><first-version><hash>
+func isNewerVersionKey(key []byte, height uint64) bool {
+	switch {
+	case len(key) == 9 && key[0] == 'r':
+		return binary.BigEndian.Uint64(key[1:]) > height
+	case len(key) == 49 && key[0] == 'o':
+		return binary.BigEndian.Uint64(key[9:17]) > height
+	}
+	return false
+}
+
 func (s *StateDB) RecoverSnapshot(manifest *snapshot.Manifest, from io.Reader) error {
 	pdb := dbm.NewPrefixDB(s.original, prefix(manifest.Height))
 
@@ -1122,6 +1215,7 @@ func (s *StateDB) CommitSnapshot(manifest *snapshot.Manifest, batch dbm.Batch) (
 	}
 	s.tree = tree
 	s.Clear()
+	s.invalidateReadCache()
 	return dropDb
 }
 
@@ -1200,6 +1294,7 @@ func (s *StateDB) FlushToDisk() error {
 
 func (s *StateDB) SwitchTree(keepEvery, keepRecent int64) error {
 	version := s.tree.Version()
+	s.invalidateReadCache()
 	s.tree = NewMutableTreeWithOpts(s.db, s.tree.RecentDb(), keepEvery, keepRecent)
 	if _, err := s.tree.LoadVersion(version); err != nil {
 		return err
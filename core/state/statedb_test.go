@@ -2,6 +2,7 @@ package state
 
 import (
 	"bytes"
+	"encoding/binary"
 	"github.com/idena-network/idena-go/blockchain/types"
 	"github.com/idena-network/idena-go/common"
 	"github.com/idena-network/idena-go/core/state/snapshot"
@@ -9,14 +10,17 @@ import (
 	"github.com/idena-network/idena-go/database"
 	"github.com/stretchr/testify/require"
 	"github.com/tendermint/tm-db"
+	"io/ioutil"
 	"math/big"
+	"os"
 	"testing"
 	"time"
 )
 
-func createDb(name string) *database.BackedMemDb {
-	db, _ := db.NewGoLevelDB(name, "datadir")
-	return database.NewBackedMemDb(db)
+func createDb(name string) (*database.BackedMemDb, string) {
+	dir, _ := ioutil.TempDir("", "datadir")
+	db, _ := db.NewGoLevelDB(name, dir)
+	return database.NewBackedMemDb(db), dir
 }
 
 func TestStateDB_Version(t *testing.T) {
@@ -36,8 +40,10 @@ func TestStateDB_Version(t *testing.T) {
 func TestStateDB_CheckForkValidation(t *testing.T) {
 
 	require := require.New(t)
-	db := createDb("CheckForkValidation")
-	db2 := createDb("CheckForkValidation2")
+	db, dir := createDb("CheckForkValidation")
+	defer os.RemoveAll(dir)
+	db2, dir2 := createDb("CheckForkValidation2")
+	defer os.RemoveAll(dir2)
 
 	stateDb := NewLazy(db)
 	stateDb2 := NewLazy(db2)
@@ -429,3 +435,41 @@ func TestStateDB_Set_Has_ValidationTxBit(t *testing.T) {
 	require.True(t, stateDb.HasValidationTx(addr, types.SubmitLongAnswersTx))
 	require.False(t, stateDb.HasValidationTx(addr, types.SendTx))
 }
+
+func TestStateDB_PinVersion(t *testing.T) {
+	stateDb := NewLazy(db.NewMemDB())
+	stateDb.SetNonce(common.Address{0x1}, 1)
+	stateDb.Commit(true)
+	stateDb.PinVersion(1)
+	for i := 2; i <= MaxSavedStatesCount+2; i++ {
+		stateDb.SetNonce(common.Address{0x1}, uint32(i))
+		stateDb.Commit(true)
+	}
+	require.True(t, stateDb.tree.ExistVersion(1))
+	require.False(t, stateDb.tree.ExistVersion(2))
+
+	stateDb.UnpinVersion(1)
+	stateDb.Commit(true)
+	require.False(t, stateDb.tree.ExistVersion(1))
+}
+
+func TestIsNewerVersionKey(t *testing.T) {
+	version := func(v uint64) []byte {
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, v)
+		return b
+	}
+	root := func(v uint64) []byte {
+		return append([]byte{'r'}, version(v)...)
+	}
+	orphan := func(last, first uint64) []byte {
+		key := append([]byte{'o'}, version(last)...)
+		key = append(key, version(first)...)
+		return append(key, make([]byte, 32)...)
+	}
+	require.False(t, isNewerVersionKey(root(5), 5))
+	require.True(t, isNewerVersionKey(root(6), 5))
+	require.False(t, isNewerVersionKey(orphan(7, 5), 5))
+	require.True(t, isNewerVersionKey(orphan(7, 6), 5))
+	require.False(t, isNewerVersionKey(append([]byte{'n'}, make([]byte, 32)...), 5))
+}
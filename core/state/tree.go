@@ -282,6 +282,11 @@ func (t *ImmutableTree) Get(key []byte) (index int64, value []byte) {
 	return t.tree.Get(key)
 }
 
+// GetWithProof returns the value with the proof of its existence or absence against the tree hash
+func (t *ImmutableTree) GetWithProof(key []byte) ([]byte, *iavl.RangeProof, error) {
+	return t.tree.GetWithProof(key)
+}
+
 func (t *ImmutableTree) Set(key, value []byte) bool {
 	panic("Not implemented")
 }
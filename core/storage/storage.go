@@ -0,0 +1,241 @@
+// Package storage tracks disk usage of the chain database and the ipfs repo against the configured budget,
+// it triggers ipfs garbage collection and database compaction when the budget is approached and refuses optional
+// writes when it is almost exhausted.
+package storage
+
+import (
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/ipfsgc"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/log"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	dbm "github.com/tendermint/tm-db"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	LevelOk       = "ok"
+	LevelHigh     = "high"
+	LevelCritical = "critical"
+)
+
+// stateDbPrefixes are key prefixes of the state trees and snapshots stored in the chain database
+var stateDbPrefixes = [][]byte{[]byte("st-"), []byte("aid-"), []byte("snpsht")}
+
+type Maintenance struct {
+	Time              int64  `json:"timestamp"`
+	Level             string `json:"level"`
+	IpfsRemovedBlocks int    `json:"ipfsRemovedBlocks"`
+	Compaction        bool   `json:"compaction"`
+	Error             string `json:"error,omitempty"`
+}
+
+type Stats struct {
+	// ChainDb is the size of the chain database excluding the state, StateDb is the approximate size of state trees
+	ChainDb         uint64       `json:"chainDb"`
+	StateDb         uint64       `json:"stateDb"`
+	Ipfs            uint64       `json:"ipfs"`
+	Total           uint64       `json:"total"`
+	Budget          uint64       `json:"budget"`
+	Usage           float64      `json:"usage"`
+	Level           string       `json:"level"`
+	CheckedAt       int64        `json:"checkedAt"`
+	LastMaintenance *Maintenance `json:"lastMaintenance,omitempty"`
+}
+
+type Manager struct {
+	cfg      *config.StorageConfig
+	dbPath   string
+	ipfsPath string
+	db       dbm.DB
+	appState *appstate.AppState
+	gc       *ipfsgc.GarbageCollector
+	compact  func() error
+	bus      eventbus.Bus
+	log      log.Logger
+
+	mutex       sync.Mutex
+	stats       *Stats
+	level       string
+	maintaining bool
+	lastRun     time.Time
+	last        *Maintenance
+}
+
+func NewManager(cfg *config.Config, db dbm.DB, appState *appstate.AppState, gc *ipfsgc.GarbageCollector, bus eventbus.Bus,
+	compact func() error) *Manager {
+	return &Manager{
+		cfg:      cfg.Storage,
+		dbPath:   filepath.Join(cfg.DataDir, "idenachain.db"),
+		ipfsPath: cfg.IpfsConf.DataDir,
+		db:       db,
+		appState: appState,
+		gc:       gc,
+		compact:  compact,
+		bus:      bus,
+		log:      log.New("component", "storage"),
+		level:    LevelOk,
+	}
+}
+
+func (m *Manager) Start() {
+	if m.cfg.CheckInterval <= 0 {
+		return
+	}
+	go m.loop()
+}
+
+func (m *Manager) loop() {
+	for {
+		if m.check(time.Now()) {
+			go m.maintain()
+		}
+		time.Sleep(m.cfg.CheckInterval)
+	}
+}
+
+// check measures disk usage and updates the level, it returns true if maintenance should be started
+func (m *Manager) check(now time.Time) bool {
+	stats := m.measure(now)
+	m.mutex.Lock()
+	stats.LastMaintenance = m.last
+	m.stats = stats
+	changed := stats.Level != m.level
+	m.level = stats.Level
+	startMaintenance := stats.Level != LevelOk && !m.maintaining && now.Sub(m.lastRun) >= m.cfg.CompactCooldown
+	m.mutex.Unlock()
+
+	if changed {
+		m.log.Warn("Storage level changed", "level", stats.Level, "total", stats.Total, "budget", stats.Budget)
+		m.bus.Publish(&events.StorageLevelEvent{Level: stats.Level, Critical: stats.Level == LevelCritical})
+	}
+	if startMaintenance && m.appState.State.ValidationPeriod() != state.NonePeriod {
+		m.log.Debug("Skip storage maintenance during validation")
+		return false
+	}
+	return startMaintenance
+}
+
+func (m *Manager) measure(now time.Time) *Stats {
+	dbSize := dirSize(m.dbPath)
+	stats := &Stats{
+		StateDb:   m.stateDbSize(),
+		Ipfs:      dirSize(m.ipfsPath),
+		Budget:    m.cfg.Budget,
+		CheckedAt: now.Unix(),
+	}
+	if stats.StateDb > dbSize {
+		stats.StateDb = dbSize
+	}
+	stats.ChainDb = dbSize - stats.StateDb
+	stats.Total = dbSize + stats.Ipfs
+	if stats.Budget > 0 {
+		stats.Usage = float64(stats.Total) / float64(stats.Budget)
+	}
+	stats.Level = levelOf(stats.Usage, m.cfg)
+	return stats
+}
+
+func levelOf(usage float64, cfg *config.StorageConfig) string {
+	if cfg.Budget == 0 {
+		return LevelOk
+	}
+	if usage >= cfg.RefuseThreshold {
+		return LevelCritical
+	}
+	if usage >= cfg.CompactThreshold {
+		return LevelHigh
+	}
+	return LevelOk
+}
+
+func (m *Manager) stateDbSize() uint64 {
+	levelDb, ok := m.db.(*dbm.GoLevelDB)
+	if !ok {
+		return 0
+	}
+	ranges := make([]util.Range, 0, len(stateDbPrefixes))
+	for _, prefix := range stateDbPrefixes {
+		ranges = append(ranges, *util.BytesPrefix(prefix))
+	}
+	sizes, err := levelDb.DB().SizeOf(ranges)
+	if err != nil {
+		m.log.Debug("Failed to get state db size", "err", err)
+		return 0
+	}
+	return uint64(sizes.Sum())
+}
+
+// maintain removes unpinned ipfs blocks and compacts the database
+func (m *Manager) maintain() {
+	m.mutex.Lock()
+	if m.maintaining {
+		m.mutex.Unlock()
+		return
+	}
+	m.maintaining = true
+	m.lastRun = time.Now()
+	result := &Maintenance{
+		Time:  m.lastRun.Unix(),
+		Level: m.level,
+	}
+	m.mutex.Unlock()
+
+	m.log.Info("Storage maintenance started", "level", result.Level)
+	if m.gc != nil {
+		removed, err := m.gc.Run()
+		if err != nil && err != ipfsgc.GcInProgress {
+			result.Error = err.Error()
+		}
+		if removed != nil {
+			result.IpfsRemovedBlocks = removed.RemovedBlocks
+		}
+	}
+	if m.compact != nil {
+		if err := m.compact(); err != nil {
+			m.log.Warn("Database compaction is not started", "err", err)
+		} else {
+			result.Compaction = true
+		}
+	}
+
+	m.mutex.Lock()
+	m.maintaining = false
+	m.last = result
+	m.mutex.Unlock()
+}
+
+// Stats returns the last measured disk usage, it is measured immediately if it hasn't been checked yet
+func (m *Manager) Stats() *Stats {
+	m.mutex.Lock()
+	stats := m.stats
+	m.mutex.Unlock()
+	if stats == nil {
+		m.check(time.Now())
+		m.mutex.Lock()
+		stats = m.stats
+		m.mutex.Unlock()
+	}
+	result := *stats
+	return &result
+}
+
+func dirSize(path string) uint64 {
+	var size uint64
+	_ = filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !info.IsDir() {
+			size += uint64(info.Size())
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/events"
+	"github.com/stretchr/testify/require"
+	db "github.com/tendermint/tm-db"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestManager_check(t *testing.T) {
+	require := require.New(t)
+	dataDir, err := ioutil.TempDir("", "storage")
+	require.NoError(err)
+	defer os.RemoveAll(dataDir)
+	ipfsDir := filepath.Join(dataDir, "ipfs")
+	dbDir := filepath.Join(dataDir, "idenachain.db")
+	require.NoError(os.MkdirAll(filepath.Join(ipfsDir, "blocks"), 0700))
+	require.NoError(os.MkdirAll(dbDir, 0700))
+	require.NoError(ioutil.WriteFile(filepath.Join(dbDir, "000001.ldb"), make([]byte, 600), 0600))
+	require.NoError(ioutil.WriteFile(filepath.Join(ipfsDir, "blocks", "data"), make([]byte, 300), 0600))
+
+	bus := eventbus.New()
+	appState := appstate.NewAppState(db.NewMemDB(), bus)
+	require.NoError(appState.Initialize(0))
+	cfg := &config.Config{
+		DataDir:  dataDir,
+		IpfsConf: &config.IpfsConfig{DataDir: ipfsDir},
+		Storage:  config.GetDefaultStorageConfig(),
+	}
+	var levels []string
+	bus.Subscribe(events.StorageLevelEventID, func(e eventbus.Event) {
+		levels = append(levels, e.(*events.StorageLevelEvent).Level)
+	})
+	compactions := 0
+	m := NewManager(cfg, db.NewMemDB(), appState, nil, bus, func() error {
+		compactions++
+		return nil
+	})
+
+	now := time.Now()
+	require.False(m.check(now))
+	stats := m.Stats()
+	require.Equal(uint64(600), stats.ChainDb)
+	require.Equal(uint64(300), stats.Ipfs)
+	require.Equal(uint64(900), stats.Total)
+	require.Equal(LevelOk, stats.Level)
+	require.Empty(levels)
+
+	cfg.Storage.Budget = 1000
+	require.True(m.check(now))
+	require.Equal(LevelHigh, m.Stats().Level)
+	m.maintain()
+	require.Equal(1, compactions)
+	require.Nil(m.Stats().LastMaintenance)
+	require.False(m.check(now.Add(time.Minute)))
+	require.True(m.Stats().LastMaintenance.Compaction)
+
+	cfg.Storage.Budget = 920
+	require.False(m.check(now.Add(time.Minute)))
+	require.Equal(LevelCritical, m.Stats().Level)
+	require.True(m.check(now.Add(cfg.Storage.CompactCooldown + time.Minute)))
+
+	cfg.Storage.Budget = 0
+	m.check(now)
+	require.Equal([]string{LevelHigh, LevelCritical, LevelOk}, levels)
+}
+
+func TestLevelOf(t *testing.T) {
+	cfg := config.GetDefaultStorageConfig()
+	require.Equal(t, LevelOk, levelOf(2, cfg))
+	cfg.Budget = 100
+	require.Equal(t, LevelOk, levelOf(0.5, cfg))
+	require.Equal(t, LevelHigh, levelOf(cfg.CompactThreshold, cfg))
+	require.Equal(t, LevelCritical, levelOf(cfg.RefuseThreshold, cfg))
+}
@@ -0,0 +1,92 @@
+package supply
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/stats/collector"
+	"math/big"
+)
+
+// supplyCollector passes stats to the wrapped collector and feeds the supply tracker
+type supplyCollector struct {
+	collector.StatsCollector
+	tracker *Tracker
+}
+
+// Wrap returns the collector which feeds the tracker in addition to the given one
+func (t *Tracker) Wrap(c collector.StatsCollector) collector.StatsCollector {
+	if c == nil {
+		c = collector.NewStatsCollector()
+	}
+	return &supplyCollector{c, t}
+}
+
+func (c *supplyCollector) EnableCollecting() {
+	c.StatsCollector.EnableCollecting()
+	c.tracker.begin()
+}
+
+func (c *supplyCollector) AddMintedCoins(amount *big.Int) {
+	c.StatsCollector.AddMintedCoins(amount)
+	c.tracker.add(func(data *blockData) *big.Int { return data.minted }, amount)
+}
+
+func (c *supplyCollector) addValidationEmission(amounts ...*big.Int) {
+	for _, amount := range amounts {
+		c.tracker.add(func(data *blockData) *big.Int { return data.validation }, amount)
+	}
+}
+
+func (c *supplyCollector) AddValidationReward(addr common.Address, age uint16, balance *big.Int, stake *big.Int) {
+	c.StatsCollector.AddValidationReward(addr, age, balance, stake)
+	c.addValidationEmission(balance, stake)
+}
+
+func (c *supplyCollector) AddFlipsReward(addr common.Address, balance *big.Int, stake *big.Int, rewardedStrongFlipCids [][]byte,
+	rewardedWeakFlipCids [][]byte) {
+	c.StatsCollector.AddFlipsReward(addr, balance, stake, rewardedStrongFlipCids, rewardedWeakFlipCids)
+	c.addValidationEmission(balance, stake)
+}
+
+func (c *supplyCollector) AddInvitationsReward(addr common.Address, balance *big.Int, stake *big.Int, age uint16, txHash *common.Hash,
+	isSavedInviteWinner bool) {
+	c.StatsCollector.AddInvitationsReward(addr, balance, stake, age, txHash, isSavedInviteWinner)
+	c.addValidationEmission(balance, stake)
+}
+
+func (c *supplyCollector) AddFoundationPayout(addr common.Address, balance *big.Int) {
+	c.StatsCollector.AddFoundationPayout(addr, balance)
+	c.addValidationEmission(balance)
+}
+
+func (c *supplyCollector) AddZeroWalletFund(addr common.Address, balance *big.Int) {
+	c.StatsCollector.AddZeroWalletFund(addr, balance)
+	c.addValidationEmission(balance)
+}
+
+func (c *supplyCollector) AddPenaltyBurntCoins(addr common.Address, amount *big.Int) {
+	c.StatsCollector.AddPenaltyBurntCoins(addr, amount)
+	c.tracker.add(func(data *blockData) *big.Int { return data.penalty }, amount)
+}
+
+func (c *supplyCollector) AddInviteBurntCoins(addr common.Address, amount *big.Int, tx *types.Transaction) {
+	c.StatsCollector.AddInviteBurntCoins(addr, amount, tx)
+	c.tracker.add(func(data *blockData) *big.Int { return data.invite }, amount)
+}
+
+func (c *supplyCollector) AddFeeBurntCoins(addr common.Address, feeAmount *big.Int, burntRate float32, tx *types.Transaction) {
+	c.StatsCollector.AddFeeBurntCoins(addr, feeAmount, burntRate, tx)
+	// the block burns the share of its total fee, so the rate is applied to the sum of fees on completion
+	c.tracker.setFeeRate(burntRate)
+	c.tracker.add(func(data *blockData) *big.Int { return data.fee }, feeAmount)
+}
+
+func (c *supplyCollector) AddKilledBurntCoins(addr common.Address, amount *big.Int) {
+	c.StatsCollector.AddKilledBurntCoins(addr, amount)
+	c.tracker.add(func(data *blockData) *big.Int { return data.killed }, amount)
+}
+
+func (c *supplyCollector) AddBurnTxBurntCoins(addr common.Address, tx *types.Transaction) {
+	c.StatsCollector.AddBurnTxBurntCoins(addr, tx)
+	c.tracker.add(func(data *blockData) *big.Int { return data.burnTx }, tx.AmountOrZero())
+}
@@ -0,0 +1,332 @@
+// Package supply accumulates coin emission and burning of applied blocks, so the total supply and burn statistics
+// are served without summing all transactions.
+package supply
+
+import (
+	"encoding/json"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/common/math"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/database"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/log"
+	"github.com/idena-network/idena-go/rlp"
+	"github.com/shopspring/decimal"
+	dbm "github.com/tendermint/tm-db"
+	"math/big"
+	"sync"
+)
+
+// BlockHistorySize is the number of the latest blocks which burn stats are kept
+const BlockHistorySize = 1000
+
+type Totals struct {
+	// Since is the height the accumulator was based on the state supply at, Height is the last accounted block
+	Since              uint64   `json:"since"`
+	Height             uint64   `json:"height"`
+	Base               *big.Int `json:"base"`
+	Minted             *big.Int `json:"minted"`
+	MiningEmission     *big.Int `json:"miningEmission"`
+	ValidationEmission *big.Int `json:"validationEmission"`
+	FeeBurned          *big.Int `json:"feeBurned"`
+	PenaltyBurned      *big.Int `json:"penaltyBurned"`
+	InviteBurned       *big.Int `json:"inviteBurned"`
+	KilledBurned       *big.Int `json:"killedBurned"`
+	BurnTxBurned       *big.Int `json:"burnTxBurned"`
+}
+
+func (t *Totals) Burned() *big.Int {
+	result := new(big.Int).Add(t.FeeBurned, t.PenaltyBurned)
+	result.Add(result, t.InviteBurned)
+	result.Add(result, t.KilledBurned)
+	return result.Add(result, t.BurnTxBurned)
+}
+
+func (t *Totals) Supply() *big.Int {
+	result := new(big.Int).Add(t.Base, t.Minted)
+	return result.Sub(result, t.Burned())
+}
+
+type BlockSupply struct {
+	Height    uint64   `json:"height"`
+	Minted    *big.Int `json:"minted"`
+	FeeBurned *big.Int `json:"feeBurned"`
+	Burned    *big.Int `json:"burned"`
+}
+
+type EpochEmission struct {
+	Epoch      uint16   `json:"epoch"`
+	Mining     *big.Int `json:"mining"`
+	Validation *big.Int `json:"validation"`
+	Burned     *big.Int `json:"burned"`
+}
+
+// blockData is collected while the block is being applied, it is merged into totals if the block is added
+type blockData struct {
+	epoch      uint16
+	minted     *big.Int
+	validation *big.Int
+	fee        *big.Int
+	feeRate    float32
+	penalty    *big.Int
+	invite     *big.Int
+	killed     *big.Int
+	burnTx     *big.Int
+}
+
+func (d *blockData) feeBurned() *big.Int {
+	return math.ToInt(decimal.NewFromBigInt(d.fee, 0).Mul(decimal.NewFromFloat32(d.feeRate)))
+}
+
+// Tracker accumulates emission and burning of added blocks via stats collector hooks
+type Tracker struct {
+	repo     *database.Repo
+	appState *appstate.AppState
+	bus      eventbus.Bus
+	log      log.Logger
+
+	mutex   sync.Mutex
+	pending *blockData
+	totals  *Totals
+}
+
+func NewTracker(db dbm.DB, appState *appstate.AppState, bus eventbus.Bus) *Tracker {
+	t := &Tracker{
+		repo:     database.NewRepo(db),
+		appState: appState,
+		bus:      bus,
+		log:      log.New("component", "supply"),
+	}
+	if data := t.repo.ReadSupplyTotals(); data != nil {
+		totals := new(Totals)
+		if err := json.Unmarshal(data, totals); err != nil {
+			t.log.Error("Invalid supply totals, they will be rebased", "err", err)
+		} else {
+			t.totals = totals
+		}
+	}
+	return t
+}
+
+func (t *Tracker) Start() {
+	_ = t.bus.Subscribe(events.AddBlockEventID,
+		func(e eventbus.Event) {
+			t.complete(e.(*events.NewBlockEvent).Block)
+		})
+}
+
+// begin is called before the block is applied on the head state
+func (t *Tracker) begin() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.pending = &blockData{
+		epoch:      t.appState.State.Epoch(),
+		minted:     new(big.Int),
+		validation: new(big.Int),
+		fee:        new(big.Int),
+		penalty:    new(big.Int),
+		invite:     new(big.Int),
+		killed:     new(big.Int),
+		burnTx:     new(big.Int),
+	}
+}
+
+func (t *Tracker) add(fn func(data *blockData) *big.Int, amount *big.Int) {
+	if amount == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.pending != nil {
+		value := fn(t.pending)
+		value.Add(value, amount)
+	}
+}
+
+func (t *Tracker) setFeeRate(rate float32) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.pending != nil {
+		t.pending.feeRate = rate
+	}
+}
+
+// complete merges data of the added block into totals, the accumulator is rebased on the state supply if blocks
+// haven't been accounted one by one, e.g. after fast sync or chain reset
+func (t *Tracker) complete(block *types.Block) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	data := t.pending
+	t.pending = nil
+	height := block.Height()
+	if t.totals == nil || data == nil || t.totals.Height+1 != height {
+		t.totals = t.rebase(height)
+		t.writeTotals()
+		return
+	}
+	feeBurned := data.feeBurned()
+	burned := new(big.Int).Add(feeBurned, data.penalty)
+	burned.Add(burned, data.invite)
+	burned.Add(burned, data.killed)
+	burned.Add(burned, data.burnTx)
+	mining := new(big.Int).Sub(data.minted, data.validation)
+
+	totals := t.totals
+	totals.Height = height
+	totals.Minted.Add(totals.Minted, data.minted)
+	totals.MiningEmission.Add(totals.MiningEmission, mining)
+	totals.ValidationEmission.Add(totals.ValidationEmission, data.validation)
+	totals.FeeBurned.Add(totals.FeeBurned, feeBurned)
+	totals.PenaltyBurned.Add(totals.PenaltyBurned, data.penalty)
+	totals.InviteBurned.Add(totals.InviteBurned, data.invite)
+	totals.KilledBurned.Add(totals.KilledBurned, data.killed)
+	totals.BurnTxBurned.Add(totals.BurnTxBurned, data.burnTx)
+	t.writeTotals()
+
+	t.write(func(value []byte) { t.repo.WriteBlockSupply(height, value) }, &BlockSupply{
+		Height:    height,
+		Minted:    data.minted,
+		FeeBurned: feeBurned,
+		Burned:    burned,
+	})
+	if height > BlockHistorySize {
+		t.repo.DeleteBlockSupply(height - BlockHistorySize)
+	}
+
+	emission := t.readEpochEmission(data.epoch)
+	if emission == nil {
+		emission = &EpochEmission{
+			Epoch:      data.epoch,
+			Mining:     new(big.Int),
+			Validation: new(big.Int),
+			Burned:     new(big.Int),
+		}
+	}
+	emission.Mining.Add(emission.Mining, mining)
+	emission.Validation.Add(emission.Validation, data.validation)
+	emission.Burned.Add(emission.Burned, burned)
+	t.write(func(value []byte) { t.repo.WriteEpochEmission(data.epoch, value) }, emission)
+}
+
+// rebase returns totals based on the supply of the head state
+func (t *Tracker) rebase(height uint64) *Totals {
+	base := stateSupply(t.appState.State)
+	t.log.Info("Supply accumulator is rebased", "height", height, "supply", base)
+	return &Totals{
+		Since:              height,
+		Height:             height,
+		Base:               base,
+		Minted:             new(big.Int),
+		MiningEmission:     new(big.Int),
+		ValidationEmission: new(big.Int),
+		FeeBurned:          new(big.Int),
+		PenaltyBurned:      new(big.Int),
+		InviteBurned:       new(big.Int),
+		KilledBurned:       new(big.Int),
+		BurnTxBurned:       new(big.Int),
+	}
+}
+
+// stateSupply returns the sum of balances and stakes, stakes of killed identities are burnt
+func stateSupply(stateDB *state.StateDB) *big.Int {
+	result := new(big.Int)
+	stateDB.IterateAccounts(func(key []byte, value []byte) bool {
+		if key == nil {
+			return true
+		}
+		var account state.Account
+		if err := rlp.DecodeBytes(value, &account); err == nil && account.Balance != nil {
+			result.Add(result, account.Balance)
+		}
+		return false
+	})
+	stateDB.IterateIdentities(func(key []byte, value []byte) bool {
+		if key == nil {
+			return true
+		}
+		var identity state.Identity
+		if err := rlp.DecodeBytes(value, &identity); err == nil && identity.Stake != nil && identity.State != state.Killed {
+			result.Add(result, identity.Stake)
+		}
+		return false
+	})
+	return result
+}
+
+func (t *Tracker) writeTotals() {
+	t.write(t.repo.WriteSupplyTotals, t.totals)
+}
+
+func (t *Tracker) write(fn func(data []byte), value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.log.Error("Failed to encode supply stats", "err", err)
+		return
+	}
+	fn(data)
+}
+
+func (t *Tracker) readEpochEmission(epoch uint16) *EpochEmission {
+	data := t.repo.ReadEpochEmission(epoch)
+	if data == nil {
+		return nil
+	}
+	result := new(EpochEmission)
+	if err := json.Unmarshal(data, result); err != nil {
+		t.log.Error("Invalid epoch emission", "epoch", epoch, "err", err)
+		return nil
+	}
+	return result
+}
+
+// Totals returns a copy of accumulated totals or nil if no block has been added yet
+func (t *Tracker) Totals() *Totals {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.totals == nil {
+		return nil
+	}
+	return &Totals{
+		Since:              t.totals.Since,
+		Height:             t.totals.Height,
+		Base:               new(big.Int).Set(t.totals.Base),
+		Minted:             new(big.Int).Set(t.totals.Minted),
+		MiningEmission:     new(big.Int).Set(t.totals.MiningEmission),
+		ValidationEmission: new(big.Int).Set(t.totals.ValidationEmission),
+		FeeBurned:          new(big.Int).Set(t.totals.FeeBurned),
+		PenaltyBurned:      new(big.Int).Set(t.totals.PenaltyBurned),
+		InviteBurned:       new(big.Int).Set(t.totals.InviteBurned),
+		KilledBurned:       new(big.Int).Set(t.totals.KilledBurned),
+		BurnTxBurned:       new(big.Int).Set(t.totals.BurnTxBurned),
+	}
+}
+
+func (t *Tracker) EpochEmission(epoch uint16) *EpochEmission {
+	return t.readEpochEmission(epoch)
+}
+
+// RecentBlocks returns burn stats of up to count latest accounted blocks, the most recent first
+func (t *Tracker) RecentBlocks(count int) []*BlockSupply {
+	totals := t.Totals()
+	result := make([]*BlockSupply, 0)
+	if totals == nil {
+		return result
+	}
+	if count > BlockHistorySize {
+		count = BlockHistorySize
+	}
+	for height := totals.Height; height > totals.Since && len(result) < count; height-- {
+		data := t.repo.ReadBlockSupply(height)
+		if data == nil {
+			break
+		}
+		item := new(BlockSupply)
+		if err := json.Unmarshal(data, item); err != nil {
+			break
+		}
+		result = append(result, item)
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package supply
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tm-db"
+	"math/big"
+	"testing"
+)
+
+func TestTracker_complete(t *testing.T) {
+	require := require.New(t)
+	memdb := db.NewMemDB()
+	appState := appstate.NewAppState(memdb, eventbus.New())
+	appState.Initialize(0)
+	appState.State.SetBalance(common.Address{0x1}, big.NewInt(100))
+	appState.State.SetState(common.Address{0x2}, state.Verified)
+	appState.State.AddStake(common.Address{0x2}, big.NewInt(50))
+	appState.State.SetState(common.Address{0x3}, state.Killed)
+	appState.State.AddStake(common.Address{0x3}, big.NewInt(7))
+	appState.Commit(nil)
+
+	block := func(height uint64) *types.Block {
+		return &types.Block{Header: &types.Header{ProposedHeader: &types.ProposedHeader{Height: height}}}
+	}
+
+	tracker := NewTracker(memdb, appState, eventbus.New())
+	require.Nil(tracker.Totals())
+	tracker.complete(block(10))
+	totals := tracker.Totals()
+	require.Equal(uint64(10), totals.Since)
+	require.Equal(big.NewInt(150), totals.Supply())
+
+	c := tracker.Wrap(nil)
+	c.EnableCollecting()
+	c.AddMintedCoins(big.NewInt(20))
+	c.AddMintedCoins(big.NewInt(8))
+	c.AddMintedCoins(big.NewInt(2))
+	c.AddValidationReward(common.Address{0x2}, 1, big.NewInt(8), big.NewInt(2))
+	c.AddFeeBurntCoins(common.Address{0x1}, big.NewInt(5), 0.9, nil)
+	c.AddFeeBurntCoins(common.Address{0x1}, big.NewInt(5), 0.9, nil)
+	c.AddPenaltyBurntCoins(common.Address{0x2}, big.NewInt(1))
+	c.AddPenaltyBurntCoins(common.Address{0x2}, nil)
+	amount := big.NewInt(3)
+	c.AddBurnTxBurntCoins(common.Address{0x1}, &types.Transaction{Amount: amount})
+	tracker.complete(block(11))
+
+	totals = tracker.Totals()
+	require.Equal(uint64(11), totals.Height)
+	require.Equal(big.NewInt(30), totals.Minted)
+	require.Equal(big.NewInt(20), totals.MiningEmission)
+	require.Equal(big.NewInt(10), totals.ValidationEmission)
+	require.Equal(big.NewInt(9), totals.FeeBurned)
+	require.Equal(big.NewInt(13), totals.Burned())
+	require.Equal(big.NewInt(167), totals.Supply())
+
+	blocks := tracker.RecentBlocks(10)
+	require.Len(blocks, 1)
+	require.Equal(uint64(11), blocks[0].Height)
+	require.Equal(big.NewInt(9), blocks[0].FeeBurned)
+	require.Equal(big.NewInt(13), blocks[0].Burned)
+	emission := tracker.EpochEmission(0)
+	require.Equal(big.NewInt(20), emission.Mining)
+	require.Equal(big.NewInt(10), emission.Validation)
+
+	// totals are persisted
+	tracker = NewTracker(memdb, appState, eventbus.New())
+	require.Equal(big.NewInt(167), tracker.Totals().Supply())
+	tracker.Wrap(nil).EnableCollecting()
+	tracker.complete(block(12))
+	require.Len(tracker.RecentBlocks(10), 2)
+
+	// skipped blocks rebase the accumulator on the state
+	tracker.Wrap(nil).EnableCollecting()
+	tracker.complete(block(20))
+	totals = tracker.Totals()
+	require.Equal(uint64(20), totals.Since)
+	require.Equal(big.NewInt(150), totals.Supply())
+	require.Empty(tracker.RecentBlocks(10))
+}
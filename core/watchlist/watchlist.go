@@ -0,0 +1,333 @@
+package watchlist
+
+import (
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/database"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/log"
+	"github.com/idena-network/idena-go/rlp"
+	"github.com/idena-network/idena-go/secstore"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	dbm "github.com/tendermint/tm-db"
+	"math/big"
+	"sync"
+)
+
+const (
+	Incoming      = "incoming"
+	Outgoing      = "outgoing"
+	BalanceChange = "balance"
+)
+
+type Tx struct {
+	Hash   common.Hash     `json:"hash"`
+	Type   types.TxType    `json:"type"`
+	From   common.Address  `json:"from"`
+	To     *common.Address `json:"to"`
+	Amount decimal.Decimal `json:"amount"`
+	Nonce  uint32          `json:"nonce"`
+	Epoch  uint16          `json:"epoch"`
+}
+
+// Event is the public representation of watch event which is sent to RPC subscribers
+type Event struct {
+	Seq       uint64          `json:"seq"`
+	Kind      string          `json:"kind"`
+	Address   common.Address  `json:"address"`
+	Height    uint64          `json:"height"`
+	Timestamp int64           `json:"timestamp"`
+	Tx        *Tx             `json:"tx,omitempty"`
+	Balance   decimal.Decimal `json:"balance"`
+	Stake     decimal.Decimal `json:"stake"`
+	Nonce     uint32          `json:"nonce"`
+}
+
+func NewEvent(e *events.WatchEvent) *Event {
+	event := &Event{
+		Seq:       e.Seq,
+		Kind:      BalanceChange,
+		Address:   e.Address,
+		Height:    e.Height,
+		Timestamp: e.Timestamp,
+		Balance:   blockchain.ConvertToFloat(e.Balance),
+		Stake:     blockchain.ConvertToFloat(e.Stake),
+		Nonce:     e.Nonce,
+	}
+	if e.Tx != nil {
+		sender, _ := types.Sender(e.Tx)
+		event.Kind = Incoming
+		if sender == e.Address {
+			event.Kind = Outgoing
+		}
+		event.Tx = &Tx{
+			Hash:   e.Tx.Hash(),
+			Type:   e.Tx.Type,
+			From:   sender,
+			To:     e.Tx.To,
+			Amount: blockchain.ConvertToFloat(e.Tx.Amount),
+			Nonce:  e.Tx.AccountNonce,
+			Epoch:  e.Tx.Epoch,
+		}
+	}
+	return event
+}
+
+// BalanceEntry is stored each time the balance, stake or nonce of the watched address changes
+type BalanceEntry struct {
+	Height    uint64
+	Timestamp uint64
+	Balance   *big.Int
+	Stake     *big.Int
+	Nonce     uint32
+}
+
+type snapshot struct {
+	balance *big.Int
+	stake   *big.Int
+	nonce   uint32
+}
+
+func (s snapshot) equals(other snapshot) bool {
+	return s.balance.Cmp(other.balance) == 0 && s.stake.Cmp(other.stake) == 0 && s.nonce == other.nonce
+}
+
+// Manager keeps the persistent watch list, indexes txs and balance history of watched addresses
+// and publishes their events to the bus
+type Manager struct {
+	cfg       *config.WatchConfig
+	repo      *database.Repo
+	appState  *appstate.AppState
+	bus       eventbus.Bus
+	secStore  *secstore.SecStore
+	log       log.Logger
+	mutex     sync.Mutex
+	addresses map[common.Address]snapshot
+	// written is the number of history entries written since the last trim
+	written map[common.Address]int
+	seq     uint64
+	recent  []*events.WatchEvent
+}
+
+func NewManager(cfg *config.WatchConfig, db dbm.DB, appState *appstate.AppState, bus eventbus.Bus, secStore *secstore.SecStore) *Manager {
+	return &Manager{
+		cfg:       cfg,
+		repo:      database.NewRepo(db),
+		appState:  appState,
+		bus:       bus,
+		secStore:  secStore,
+		log:       log.New("component", "watchlist"),
+		addresses: make(map[common.Address]snapshot),
+		written:   make(map[common.Address]int),
+	}
+}
+
+func (m *Manager) Start() {
+	m.mutex.Lock()
+	list := m.repo.ReadWatchList()
+	for _, addr := range list {
+		m.addresses[addr] = m.readSnapshot(addr)
+	}
+	changed := false
+	for _, addr := range m.cfg.Addresses {
+		if _, ok := m.addresses[addr]; !ok {
+			m.addresses[addr] = m.readSnapshot(addr)
+			changed = true
+		}
+	}
+	if changed {
+		m.persist()
+	}
+	m.mutex.Unlock()
+
+	// block event is published synchronously after the block is committed, so the head state is the state of the block
+	_ = m.bus.Subscribe(events.AddBlockEventID,
+		func(e eventbus.Event) {
+			m.processBlock(e.(*events.NewBlockEvent).Block)
+		})
+	_ = m.bus.Subscribe(events.FastSyncCompleted,
+		func(e eventbus.Event) {
+			m.resetSnapshots()
+		})
+}
+
+func (m *Manager) readSnapshot(addr common.Address) snapshot {
+	return snapshot{
+		balance: m.appState.State.GetBalance(addr),
+		stake:   m.appState.State.GetStakeBalance(addr),
+		nonce:   m.appState.State.GetNonce(addr),
+	}
+}
+
+func (m *Manager) resetSnapshots() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for addr := range m.addresses {
+		m.addresses[addr] = m.readSnapshot(addr)
+	}
+}
+
+func (m *Manager) persist() {
+	list := make([]common.Address, 0, len(m.addresses))
+	for addr := range m.addresses {
+		list = append(list, addr)
+	}
+	m.repo.WriteWatchList(list)
+}
+
+func (m *Manager) Add(addr common.Address) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if _, ok := m.addresses[addr]; ok {
+		return false
+	}
+	m.addresses[addr] = m.readSnapshot(addr)
+	m.persist()
+	return true
+}
+
+// Remove removes the address from the watch list, the balance history of the address is removed too
+func (m *Manager) Remove(addr common.Address) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if _, ok := m.addresses[addr]; !ok {
+		return false
+	}
+	delete(m.addresses, addr)
+	delete(m.written, addr)
+	m.persist()
+	m.repo.TrimBalanceHistory(addr, 0)
+	return true
+}
+
+func (m *Manager) List() []common.Address {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	result := make([]common.Address, 0, len(m.addresses))
+	for addr := range m.addresses {
+		result = append(result, addr)
+	}
+	return result
+}
+
+func (m *Manager) IsWatched(addr common.Address) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	_, ok := m.addresses[addr]
+	return ok
+}
+
+func (m *Manager) processBlock(block *types.Block) {
+	for _, e := range m.detectEvents(block) {
+		m.bus.Publish(e)
+	}
+}
+
+func (m *Manager) detectEvents(block *types.Block) []*events.WatchEvent {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if len(m.addresses) == 0 {
+		return nil
+	}
+	height, timestamp := block.Height(), block.Header.Time().Int64()
+	coinbase := m.secStore.GetAddress()
+	var result []*events.WatchEvent
+	for addr, prev := range m.addresses {
+		current := m.readSnapshot(addr)
+		newEvent := func(tx *types.Transaction) *events.WatchEvent {
+			return &events.WatchEvent{
+				Address:   addr,
+				Height:    height,
+				Timestamp: timestamp,
+				Tx:        tx,
+				Balance:   current.balance,
+				Stake:     current.stake,
+				Nonce:     current.nonce,
+			}
+		}
+		hasTxs := false
+		if block.Body != nil {
+			for _, tx := range block.Body.Transactions {
+				sender, _ := types.Sender(tx)
+				if sender != addr && (tx.To == nil || *tx.To != addr) {
+					continue
+				}
+				hasTxs = true
+				result = append(result, newEvent(tx))
+				// txs of the coinbase are saved by the blockchain
+				if addr != coinbase {
+					m.repo.SaveTx(addr, block.Hash(), block.Header.Time().Uint64(), block.Header.FeePerByte(), tx)
+				}
+			}
+		}
+		if current.equals(prev) {
+			continue
+		}
+		m.addresses[addr] = current
+		m.writeHistory(addr, BalanceEntry{height, uint64(timestamp), current.balance, current.stake, current.nonce})
+		if !hasTxs {
+			result = append(result, newEvent(nil))
+		}
+	}
+	for _, e := range result {
+		m.seq++
+		e.Seq = m.seq
+		m.recent = append(m.recent, e)
+		if len(m.recent) > m.cfg.RecentSize {
+			m.recent = m.recent[len(m.recent)-m.cfg.RecentSize:]
+		}
+	}
+	return result
+}
+
+func (m *Manager) writeHistory(addr common.Address, entry BalanceEntry) {
+	data, err := rlp.EncodeToBytes(entry)
+	if err != nil {
+		m.log.Error("Cannot encode balance history entry", "err", err)
+		return
+	}
+	m.repo.WriteBalanceHistory(addr, entry.Height, data)
+	if m.cfg.HistorySize <= 0 {
+		return
+	}
+	// history is trimmed periodically to avoid iterating over it at every block
+	if m.written[addr]++; m.written[addr] > m.cfg.HistorySize/10 {
+		m.repo.TrimBalanceHistory(addr, m.cfg.HistorySize)
+		m.written[addr] = 0
+	}
+}
+
+// BalanceHistory returns at most count entries below the height in descending order
+func (m *Manager) BalanceHistory(addr common.Address, beforeHeight uint64, count int) ([]*BalanceEntry, error) {
+	if !m.IsWatched(addr) {
+		return nil, errors.Errorf("address %v is not watched", addr.Hex())
+	}
+	var result []*BalanceEntry
+	for _, data := range m.repo.ReadBalanceHistory(addr, beforeHeight, count) {
+		entry := new(BalanceEntry)
+		if err := rlp.DecodeBytes(data, entry); err != nil {
+			m.log.Error("Cannot decode balance history entry", "err", err)
+			continue
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+// Recent returns buffered events with seq greater than fromSeq
+func (m *Manager) Recent(fromSeq uint64) []*Event {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	var result []*Event
+	for _, e := range m.recent {
+		if e.Seq > fromSeq {
+			result = append(result, NewEvent(e))
+		}
+	}
+	return result
+}
@@ -0,0 +1,78 @@
+package watchlist
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/secstore"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tm-db"
+	"math/big"
+	"testing"
+)
+
+func TestManager_detectEvents(t *testing.T) {
+	require := require.New(t)
+	memdb := db.NewMemDB()
+	appState := appstate.NewAppState(memdb, eventbus.New())
+	appState.Initialize(0)
+
+	key, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	watched, other := common.Address{0x1}, common.Address{0x2}
+
+	coinbaseKey, _ := crypto.GenerateKey()
+	secStore := secstore.NewSecStore()
+	secStore.AddKey(crypto.FromECDSA(coinbaseKey))
+
+	cfg := config.GetDefaultWatchConfig()
+	cfg.Addresses = []common.Address{watched}
+	manager := NewManager(cfg, memdb, appState, eventbus.New(), secStore)
+	manager.Start()
+	require.Equal([]common.Address{watched}, manager.repo.ReadWatchList())
+	require.True(manager.Add(sender))
+	require.False(manager.Add(sender))
+
+	tx, _ := types.SignTx(&types.Transaction{AccountNonce: 1, To: &watched, Amount: big.NewInt(10)}, key)
+	appState.State.SetBalance(watched, big.NewInt(10))
+	appState.State.SetNonce(sender, 1)
+	appState.State.SetBalance(other, big.NewInt(5))
+	block := &types.Block{
+		Header: &types.Header{ProposedHeader: &types.ProposedHeader{Height: 2, Time: big.NewInt(100)}},
+		Body:   &types.Body{Transactions: []*types.Transaction{tx}},
+	}
+	result := manager.detectEvents(block)
+	require.Len(result, 2)
+	kinds := map[common.Address]string{}
+	for _, e := range result {
+		kinds[e.Address] = NewEvent(e).Kind
+	}
+	require.Equal(Incoming, kinds[watched])
+	require.Equal(Outgoing, kinds[sender])
+
+	appState.State.SetBalance(watched, big.NewInt(15))
+	block = &types.Block{Header: &types.Header{ProposedHeader: &types.ProposedHeader{Height: 3, Time: big.NewInt(120)}}}
+	result = manager.detectEvents(block)
+	require.Len(result, 1)
+	event := NewEvent(result[0])
+	require.Equal(BalanceChange, event.Kind)
+	require.Nil(event.Tx)
+	require.Len(manager.Recent(2), 1)
+
+	history, err := manager.BalanceHistory(watched, 10, 10)
+	require.NoError(err)
+	require.Len(history, 2)
+	require.Equal(uint64(3), history[0].Height)
+	require.Equal(big.NewInt(15), history[0].Balance)
+	require.Equal(uint64(100), history[1].Timestamp)
+
+	_, err = manager.BalanceHistory(other, 10, 10)
+	require.Error(err)
+
+	require.True(manager.Remove(watched))
+	require.Empty(manager.repo.ReadBalanceHistory(watched, 10, 10))
+	require.Equal([]common.Address{sender}, manager.repo.ReadWatchList())
+}
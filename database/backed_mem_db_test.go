@@ -3,18 +3,21 @@ package database
 import (
 	"github.com/stretchr/testify/require"
 	"github.com/tendermint/tm-db"
+	"io/ioutil"
+	"os"
 	"testing"
 )
 
-func createDb(name string) *BackedMemDb {
-
-	db, _ := db.NewGoLevelDB(name, "datadir")
-	return NewBackedMemDb(db)
+func createDb(name string) (*BackedMemDb, string) {
+	dir, _ := ioutil.TempDir("", "datadir")
+	db, _ := db.NewGoLevelDB(name, dir)
+	return NewBackedMemDb(db), dir
 }
 
 func TestBackedMemDb_Get(t *testing.T) {
 	require := require.New(t)
-	db := createDb("get.db")
+	db, dir := createDb("get.db")
+	defer os.RemoveAll(dir)
 	db.permanent.Set([]byte{0x1}, []byte{0x2})
 
 	v, _ := db.Get([]byte{0x1})
@@ -33,7 +36,8 @@ func TestBackedMemDb_Get(t *testing.T) {
 
 func TestBackedMemDb_Delete(t *testing.T) {
 	require := require.New(t)
-	db := createDb("delete.db")
+	db, dir := createDb("delete.db")
+	defer os.RemoveAll(dir)
 	db.permanent.Set([]byte{0x1}, []byte{0x2})
 
 	db.Delete([]byte{0x1})
@@ -50,7 +54,8 @@ func TestBackedMemDb_Delete(t *testing.T) {
 
 func TestBackedMemDb_Iterator(t *testing.T) {
 	require := require.New(t)
-	db := createDb("iterator.db")
+	db, dir := createDb("iterator.db")
+	defer os.RemoveAll(dir)
 
 	db.permanent.Set([]byte{0x1}, []byte{0x5})
 	db.permanent.Set([]byte{0x2}, []byte{0x3})
@@ -74,7 +79,8 @@ func TestBackedMemDb_Iterator(t *testing.T) {
 
 func TestBackedMemDb_Iterator2(t *testing.T) {
 	require := require.New(t)
-	db := createDb("iterator2.db")
+	db, dir := createDb("iterator2.db")
+	defer os.RemoveAll(dir)
 
 	db.permanent.Set([]byte{0x1}, []byte{0x1})
 	db.permanent.Set([]byte{0x2}, []byte{0x2})
@@ -102,7 +108,8 @@ func TestBackedMemDb_Iterator2(t *testing.T) {
 
 func TestBackedMemDb_NewBatch(t *testing.T) {
 	require := require.New(t)
-	db := createDb("newBatch.db")
+	db, dir := createDb("newBatch.db")
+	defer os.RemoveAll(dir)
 	db.permanent.Set([]byte{0x1}, []byte{0x1})
 	db.permanent.Set([]byte{0x2}, []byte{0x2})
 	db.permanent.Set([]byte{0x3}, []byte{0x3})
@@ -67,13 +67,23 @@ func txIndexKey(hash common.Hash) []byte {
 	return append(transactionIndexPrefix, hash.Bytes()...)
 }
 
-func savedTxKey(sender common.Address, timestamp uint64, nonce uint32, hash common.Hash) []byte {
-	key := append(ownTransactionIndexPrefix, sender[:]...)
-	key = append(key, encodeUint64Number(timestamp)...)
-	key = append(key, encodeUint32Number(nonce)...)
+func savedTxKeySuffix(timestamp uint64, nonce uint32, hash common.Hash) []byte {
+	key := append(encodeUint64Number(timestamp), encodeUint32Number(nonce)...)
 	return append(key, hash[:]...)
 }
 
+func savedTxKey(sender common.Address, timestamp uint64, nonce uint32, hash common.Hash) []byte {
+	key := append(append([]byte{}, ownTransactionIndexPrefix...), sender[:]...)
+	return append(key, savedTxKeySuffix(timestamp, nonce, hash)...)
+}
+
+// savedTxTypeKey = ownTransactionTypeIndexPrefix + sender + type + timestamp + nonce + hash
+func savedTxTypeKey(sender common.Address, txType types.TxType, timestamp uint64, nonce uint32, hash common.Hash) []byte {
+	key := append(append([]byte{}, ownTransactionTypeIndexPrefix...), sender[:]...)
+	key = append(key, encodeUint16Number(txType)...)
+	return append(key, savedTxKeySuffix(timestamp, nonce, hash)...)
+}
+
 func burntCoinsKey(height uint64, hash common.Hash) []byte {
 	key := append(burntCoinsPrefix, encodeUint64Number(height)...)
 	return append(key, hash[:]...)
@@ -254,6 +264,11 @@ func (r *Repo) removeCertificate(hash common.Hash) {
 	r.db.Delete(certKey(hash))
 }
 
+// RemoveCertificate deletes the certificate of the block moved to the ancient store
+func (r *Repo) RemoveCertificate(hash common.Hash) {
+	r.removeCertificate(hash)
+}
+
 func (r *Repo) WriteWeakCertificate(hash common.Hash) {
 	weakCerts := r.readWeakCertificates()
 	if weakCerts == nil {
@@ -305,6 +320,171 @@ func (r *Repo) WriteLastSnapshotManifest(cid []byte, root common.Hash, height ui
 	return nil
 }
 
+type IpfsPin struct {
+	Cid      []byte
+	DataType uint32
+	// Epoch is the last epoch the pinned data was referenced in
+	Epoch    uint16
+	PinnedAt uint64
+}
+
+func ipfsPinKey(cid []byte) []byte {
+	return append(append([]byte{}, ipfsPinPrefix...), cid...)
+}
+
+func (r *Repo) WriteIpfsPin(pin *IpfsPin) {
+	data, err := rlp.EncodeToBytes(pin)
+	if err != nil {
+		log.Crit("failed to RLP encode ipfs pin", "err", err)
+		return
+	}
+	assertNoError(r.db.Set(ipfsPinKey(pin.Cid), data))
+}
+
+func (r *Repo) ReadIpfsPin(cid []byte) *IpfsPin {
+	data, err := r.db.Get(ipfsPinKey(cid))
+	assertNoError(err)
+	if data == nil {
+		return nil
+	}
+	pin := new(IpfsPin)
+	if err := rlp.DecodeBytes(data, pin); err != nil {
+		log.Error("invalid ipfs pin RLP", "err", err)
+		return nil
+	}
+	return pin
+}
+
+func (r *Repo) DeleteIpfsPin(cid []byte) {
+	assertNoError(r.db.Delete(ipfsPinKey(cid)))
+}
+
+func (r *Repo) ReadIpfsPins() []*IpfsPin {
+	it, err := r.db.Iterator(ipfsPinPrefix, append(append([]byte{}, ipfsPinPrefix...), 0xFF))
+	assertNoError(err)
+	defer it.Close()
+	var result []*IpfsPin
+	for ; it.Valid(); it.Next() {
+		pin := new(IpfsPin)
+		if err := rlp.DecodeBytes(it.Value(), pin); err != nil {
+			log.Error("invalid ipfs pin RLP", "err", err)
+			continue
+		}
+		result = append(result, pin)
+	}
+	return result
+}
+
+func (r *Repo) WritePoolDelegators(delegators []common.Address) {
+	data, err := rlp.EncodeToBytes(delegators)
+	if err != nil {
+		log.Crit("failed to RLP encode pool delegators", "err", err)
+		return
+	}
+	assertNoError(r.db.Set(poolDelegatorsKey, data))
+}
+
+func (r *Repo) ReadPoolDelegators() []common.Address {
+	data, err := r.db.Get(poolDelegatorsKey)
+	assertNoError(err)
+	if data == nil {
+		return nil
+	}
+	var delegators []common.Address
+	if err := rlp.DecodeBytes(data, &delegators); err != nil {
+		log.Error("invalid pool delegators RLP", "err", err)
+		return nil
+	}
+	return delegators
+}
+
+// WriteOnlineIntent persists whether the node owner wants the identity to stay online
+func (r *Repo) WriteOnlineIntent(online bool) {
+	value := []byte{0}
+	if online {
+		value[0] = 1
+	}
+	assertNoError(r.db.Set(onlineIntentKey, value))
+}
+
+func (r *Repo) ReadOnlineIntent() bool {
+	data, err := r.db.Get(onlineIntentKey)
+	assertNoError(err)
+	return len(data) == 1 && data[0] == 1
+}
+
+// WriteSigningLock persists the lowest height the node is allowed to sign consensus messages at
+func (r *Repo) WriteSigningLock(height uint64) {
+	assertNoError(r.db.SetSync(signingLockKey, encodeUint64Number(height)))
+}
+
+func (r *Repo) ReadSigningLock() uint64 {
+	data, err := r.db.Get(signingLockKey)
+	assertNoError(err)
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// signedMessageKey = signedMessagePrefix + round (uint64 big endian) + attempt (uint32 big endian) + step
+func signedMessageKey(round uint64, attempt uint32, step uint8) []byte {
+	key := append(append([]byte{}, signedMessagePrefix...), encodeUint64Number(round)...)
+	key = append(key, encodeUint32Number(attempt)...)
+	return append(key, step)
+}
+
+// WriteSignedMessage persists the hash of the consensus message signed at the round attempt and step
+func (r *Repo) WriteSignedMessage(round uint64, attempt uint32, step uint8, hash common.Hash) {
+	assertNoError(r.db.SetSync(signedMessageKey(round, attempt, step), hash.Bytes()))
+}
+
+func (r *Repo) ReadSignedMessage(round uint64, attempt uint32, step uint8) (common.Hash, bool) {
+	data, err := r.db.Get(signedMessageKey(round, attempt, step))
+	assertNoError(err)
+	if len(data) != common.HashLength {
+		return common.Hash{}, false
+	}
+	return common.BytesToHash(data), true
+}
+
+// ReadSignedMessages returns hashes of messages signed at the round by attempt and step
+func (r *Repo) ReadSignedMessages(round uint64) map[uint32]map[uint8]common.Hash {
+	prefixLength := len(signedMessageKey(round, 0, 0)) - 5
+	it, err := r.db.Iterator(signedMessageKey(round, 0, 0), signedMessageKey(round+1, 0, 0))
+	assertNoError(err)
+	defer it.Close()
+	result := make(map[uint32]map[uint8]common.Hash)
+	for ; it.Valid(); it.Next() {
+		key := it.Key()
+		// records of the previous format have no attempt
+		if len(key) != prefixLength+5 {
+			continue
+		}
+		attempt := binary.BigEndian.Uint32(key[prefixLength:])
+		if result[attempt] == nil {
+			result[attempt] = make(map[uint8]common.Hash)
+		}
+		result[attempt][key[len(key)-1]] = common.BytesToHash(it.Value())
+	}
+	return result
+}
+
+// DeleteSignedMessages removes signed messages of rounds in [from, to) and returns the number of removed records
+func (r *Repo) DeleteSignedMessages(from, to uint64) int {
+	it, err := r.db.Iterator(signedMessageKey(from, 0, 0), signedMessageKey(to, 0, 0))
+	assertNoError(err)
+	var keys [][]byte
+	for ; it.Valid(); it.Next() {
+		keys = append(keys, append([]byte{}, it.Key()...))
+	}
+	it.Close()
+	for _, key := range keys {
+		assertNoError(r.db.Delete(key))
+	}
+	return len(keys)
+}
+
 func (r *Repo) WriteIdentityStateDiff(height uint64, diff []byte) {
 	r.db.Set(identityStateDiffKey(height), diff)
 }
@@ -346,6 +526,20 @@ func (r *Repo) RemovePreliminaryHead(batch dbm.Batch) {
 	}
 }
 
+func (r *Repo) WriteCleanShutdown(head common.Hash) {
+	assertNoError(r.db.SetSync(cleanShutdownKey, head.Bytes()))
+}
+
+func (r *Repo) ReadCleanShutdown() common.Hash {
+	data, err := r.db.Get(cleanShutdownKey)
+	assertNoError(err)
+	return common.BytesToHash(data)
+}
+
+func (r *Repo) RemoveCleanShutdown() {
+	assertNoError(r.db.DeleteSync(cleanShutdownKey))
+}
+
 type activityMonitorDb struct {
 	UpdateDt uint64
 	Data     []*addrActivityDb
@@ -411,28 +605,139 @@ func (r *Repo) SaveTx(address common.Address, blockHash common.Hash, timestamp u
 		return
 	}
 
-	r.db.Set(savedTxKey(address, timestamp, transaction.AccountNonce, transaction.Hash()), data)
+	batch := r.db.NewBatch()
+	defer batch.Close()
+	batch.Set(savedTxKey(address, timestamp, transaction.AccountNonce, transaction.Hash()), data)
+	batch.Set(savedTxTypeKey(address, transaction.Type, timestamp, transaction.AccountNonce, transaction.Hash()), []byte{})
+	assertNoError(batch.Write())
 }
 
-func (r *Repo) GetSavedTxs(address common.Address, count int, token []byte) (txs []*types.SavedTransaction, nextToken []byte) {
+// IndexSavedTxTypes builds the type index of saved transactions which have been stored before the index was introduced
+func (r *Repo) IndexSavedTxTypes() int {
+	if data, err := r.db.Get(ownTransactionTypeIndexedKey); err == nil && len(data) > 0 {
+		return 0
+	}
+	var maxAddr common.Address
+	for i := range maxAddr {
+		maxAddr[i] = 0xff
+	}
+	it, err := r.db.Iterator(savedTxKey(common.Address{}, 0, 0, common.BytesToHash(common.MinHash[:])),
+		savedTxKey(maxAddr, uint64(math.MaxUint64), uint32(math.MaxUint32), common.BytesToHash(common.MaxHash)))
+	assertNoError(err)
+	batch := r.db.NewBatch()
+	defer batch.Close()
+	cnt := 0
+	prefixLength := len(ownTransactionIndexPrefix)
+	for ; it.Valid(); it.Next() {
+		key := it.Key()
+		tx := new(types.SavedTransaction)
+		if err := rlp.DecodeBytes(it.Value(), tx); err != nil || tx.Tx == nil {
+			continue
+		}
+		sender := common.BytesToAddress(key[prefixLength : prefixLength+common.AddressLength])
+		batch.Set(savedTxTypeKey(sender, tx.Tx.Type, tx.Timestamp, tx.Tx.AccountNonce, tx.Tx.Hash()), []byte{})
+		cnt++
+	}
+	it.Close()
+	batch.Set(ownTransactionTypeIndexedKey, []byte{1})
+	assertNoError(batch.Write())
+	return cnt
+}
+
+// SavedTxFilter narrows saved transactions by types and timestamp range (inclusive), zero ToTime means no upper bound
+type SavedTxFilter struct {
+	Types    []types.TxType
+	FromTime uint64
+	ToTime   uint64
+}
+
+// savedTxTokenLength is the length of the pagination token: timestamp + nonce of the next transaction
+const savedTxTokenLength = 12
+
+type savedTxEntry struct {
+	suffix []byte
+	value  []byte
+}
 
-	if token == nil {
-		token = savedTxKey(address, uint64(math.MaxUint64), uint32(math.MaxUint32), common.BytesToHash(common.MaxHash))
+// GetSavedTxs returns transactions sorted by timestamp desc, the filter is applied by seeking the indexes so no full scan is done
+func (r *Repo) GetSavedTxs(address common.Address, count int, token []byte, filter *SavedTxFilter) (txs []*types.SavedTransaction, nextToken []byte) {
+	if filter == nil {
+		filter = &SavedTxFilter{}
+	}
+	if len(token) > 0 {
+		if len(token) < savedTxTokenLength {
+			return nil, nil
+		}
+		// tokens issued before the type index contain the whole key prefix, the tail is the same
+		token = token[len(token)-savedTxTokenLength:]
+	}
+
+	lower := savedTxKeySuffix(filter.FromTime, 0, common.BytesToHash(common.MinHash[:]))
+	upper := savedTxKeySuffix(uint64(math.MaxUint64), uint32(math.MaxUint32), common.BytesToHash(common.MaxHash))
+	if filter.ToTime > 0 && filter.ToTime < math.MaxUint64 {
+		upper = savedTxKeySuffix(filter.ToTime+1, 0, common.BytesToHash(common.MinHash[:]))
+	}
+	if len(token) > 0 {
+		if tokenUpper := append(append([]byte{}, token...), common.MaxHash...); bytes.Compare(tokenUpper, upper) < 0 {
+			upper = tokenUpper
+		}
+	}
+	if bytes.Compare(lower, upper) >= 0 {
+		return nil, nil
+	}
+
+	var prefixes [][]byte
+	if len(filter.Types) == 0 {
+		prefixes = append(prefixes, append(append([]byte{}, ownTransactionIndexPrefix...), address[:]...))
 	} else {
-		token = append(token, common.MaxHash...)
+		uniqueTypes := make(map[types.TxType]struct{})
+		for _, txType := range filter.Types {
+			if _, ok := uniqueTypes[txType]; ok {
+				continue
+			}
+			uniqueTypes[txType] = struct{}{}
+			prefix := append(append([]byte{}, ownTransactionTypeIndexPrefix...), address[:]...)
+			prefixes = append(prefixes, append(prefix, encodeUint16Number(txType)...))
+		}
 	}
 
-	it, err := r.db.ReverseIterator(savedTxKey(address, 0, 0, common.BytesToHash(common.MinHash[:])), token)
-	assertNoError(err)
-	defer it.Close()
-	for ; it.Valid(); it.Next() {
-		key, value := it.Key(), it.Value()
+	var entries []savedTxEntry
+	for _, prefix := range prefixes {
+		it, err := r.db.ReverseIterator(append(append([]byte{}, prefix...), lower...), append(append([]byte{}, prefix...), upper...))
+		assertNoError(err)
+		for n := 0; it.Valid() && (count <= 0 || n <= count); it.Next() {
+			entry := savedTxEntry{
+				suffix: append([]byte{}, it.Key()[len(prefix):]...),
+			}
+			if len(filter.Types) == 0 {
+				entry.value = append([]byte{}, it.Value()...)
+			}
+			entries = append(entries, entry)
+			n++
+		}
+		it.Close()
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].suffix, entries[j].suffix) > 0
+	})
+
+	primaryPrefix := append(append([]byte{}, ownTransactionIndexPrefix...), address[:]...)
+	for _, entry := range entries {
 		if len(txs) == count {
-			return txs, key[:len(key)-common.HashLength]
+			return txs, entry.suffix[:savedTxTokenLength]
+		}
+		value := entry.value
+		if value == nil {
+			var err error
+			value, err = r.db.Get(append(append([]byte{}, primaryPrefix...), entry.suffix...))
+			assertNoError(err)
+			if value == nil {
+				continue
+			}
 		}
 		tx := new(types.SavedTransaction)
 		if err := rlp.DecodeBytes(value, tx); err != nil {
-			log.Error("cannot parse tx", "key", key)
+			log.Error("cannot parse tx", "suffix", entry.suffix)
 			continue
 		}
 		txs = append(txs, tx)
@@ -506,3 +811,523 @@ func (r *Repo) GetTotalBurntCoins() []*types.BurntCoins {
 
 	return res
 }
+
+// epochReportKey = epochReportPrefix + epoch (uint16 big endian) + address
+func epochReportKey(epoch uint16, addr common.Address) []byte {
+	key := append(append([]byte{}, epochReportPrefix...), encodeUint16Number(epoch)...)
+	return append(key, addr[:]...)
+}
+
+func (r *Repo) WriteEpochReport(epoch uint16, addr common.Address, data []byte) {
+	assertNoError(r.db.Set(epochReportKey(epoch, addr), data))
+}
+
+func (r *Repo) ReadEpochReport(epoch uint16, addr common.Address) []byte {
+	data, err := r.db.Get(epochReportKey(epoch, addr))
+	assertNoError(err)
+	return data
+}
+
+func (r *Repo) WriteWatchList(addresses []common.Address) {
+	data, err := rlp.EncodeToBytes(addresses)
+	if err != nil {
+		log.Crit("failed to RLP encode watch list", "err", err)
+		return
+	}
+	assertNoError(r.db.Set(watchListKey, data))
+}
+
+func (r *Repo) ReadWatchList() []common.Address {
+	data, err := r.db.Get(watchListKey)
+	assertNoError(err)
+	if data == nil {
+		return nil
+	}
+	var addresses []common.Address
+	if err := rlp.DecodeBytes(data, &addresses); err != nil {
+		log.Error("invalid watch list RLP", "err", err)
+		return nil
+	}
+	return addresses
+}
+
+func watchBalanceKey(addr common.Address, height uint64) []byte {
+	key := append(append([]byte{}, watchBalancePrefix...), addr[:]...)
+	return append(key, encodeUint64Number(height)...)
+}
+
+func (r *Repo) WriteBalanceHistory(addr common.Address, height uint64, data []byte) {
+	assertNoError(r.db.Set(watchBalanceKey(addr, height), data))
+}
+
+// ReadBalanceHistory returns at most count balance history entries of the address below the height in descending order
+func (r *Repo) ReadBalanceHistory(addr common.Address, beforeHeight uint64, count int) [][]byte {
+	it, err := r.db.ReverseIterator(watchBalanceKey(addr, 0), watchBalanceKey(addr, beforeHeight))
+	assertNoError(err)
+	defer it.Close()
+	var result [][]byte
+	for ; it.Valid() && len(result) < count; it.Next() {
+		result = append(result, append([]byte{}, it.Value()...))
+	}
+	return result
+}
+
+// TrimBalanceHistory removes balance history entries of the address except the last keep ones
+func (r *Repo) TrimBalanceHistory(addr common.Address, keep int) int {
+	it, err := r.db.ReverseIterator(watchBalanceKey(addr, 0), watchBalanceKey(addr, math.MaxUint64))
+	assertNoError(err)
+	var keys [][]byte
+	for i := 0; it.Valid(); it.Next() {
+		if i++; i > keep {
+			keys = append(keys, append([]byte{}, it.Key()...))
+		}
+	}
+	it.Close()
+	for _, key := range keys {
+		assertNoError(r.db.Delete(key))
+	}
+	return len(keys)
+}
+
+func certIndexKey(height uint64) []byte {
+	return append(append([]byte{}, certIndexPrefix...), encodeUint64Number(height)...)
+}
+
+func (r *Repo) WriteCertificateIndex(height uint64, cert *types.BlockCert) {
+	data, err := rlp.EncodeToBytes(cert)
+	if err != nil {
+		log.Crit("failed to RLP encode block cert", "err", err)
+	}
+	assertNoError(r.db.Set(certIndexKey(height), data))
+}
+
+func (r *Repo) ReadCertificateIndex(height uint64) *types.BlockCert {
+	data, err := r.db.Get(certIndexKey(height))
+	assertNoError(err)
+	if data == nil {
+		return nil
+	}
+	cert := new(types.BlockCert)
+	if err := rlp.DecodeBytes(data, cert); err != nil {
+		log.Error("Invalid block cert RLP", "err", err)
+		return nil
+	}
+	return cert
+}
+
+func (r *Repo) RemoveCertificateIndex(height uint64) {
+	assertNoError(r.db.Delete(certIndexKey(height)))
+}
+
+// OwnFlipImages are perceptual hashes of images of the mined flip of the node
+type OwnFlipImages struct {
+	Cid    []byte
+	Hashes []uint64
+}
+
+// WriteOwnFlipImages persists image hashes of flips submitted by the node, the oldest flip first
+func (r *Repo) WriteOwnFlipImages(flips []*OwnFlipImages) {
+	data, err := rlp.EncodeToBytes(flips)
+	if err != nil {
+		log.Crit("failed to RLP encode flip image hashes", "err", err)
+	}
+	assertNoError(r.db.Set(ownFlipImagesKey, data))
+}
+
+func (r *Repo) ReadOwnFlipImages() []*OwnFlipImages {
+	data, err := r.db.Get(ownFlipImagesKey)
+	assertNoError(err)
+	if data == nil {
+		return nil
+	}
+	var flips []*OwnFlipImages
+	if err := rlp.DecodeBytes(data, &flips); err != nil {
+		log.Error("Invalid flip image hashes RLP", "err", err)
+		return nil
+	}
+	return flips
+}
+
+// IssuedInvite is the invite sent by the node identity, Key is the invite private key encrypted with the node key
+type IssuedInvite struct {
+	Receiver common.Address
+	TxHash   common.Hash
+	Epoch    uint16
+	Key      []byte
+	IssuedAt uint64
+	// RevokeTx is the hash of the kill invitee tx sent to revoke the invite
+	RevokeTx common.Hash
+}
+
+func issuedInviteKey(receiver common.Address) []byte {
+	return append(append([]byte{}, issuedInvitePrefix...), receiver[:]...)
+}
+
+func (r *Repo) WriteIssuedInvite(invite *IssuedInvite) {
+	data, err := rlp.EncodeToBytes(invite)
+	if err != nil {
+		log.Crit("failed to RLP encode issued invite", "err", err)
+		return
+	}
+	assertNoError(r.db.Set(issuedInviteKey(invite.Receiver), data))
+}
+
+func (r *Repo) ReadIssuedInvite(receiver common.Address) *IssuedInvite {
+	data, err := r.db.Get(issuedInviteKey(receiver))
+	assertNoError(err)
+	if data == nil {
+		return nil
+	}
+	invite := new(IssuedInvite)
+	if err := rlp.DecodeBytes(data, invite); err != nil {
+		log.Error("invalid issued invite RLP", "err", err)
+		return nil
+	}
+	return invite
+}
+
+func (r *Repo) DeleteIssuedInvite(receiver common.Address) {
+	assertNoError(r.db.Delete(issuedInviteKey(receiver)))
+}
+
+func (r *Repo) ReadIssuedInvites() []*IssuedInvite {
+	it, err := r.db.Iterator(issuedInvitePrefix, append(append([]byte{}, issuedInvitePrefix...), 0xFF))
+	assertNoError(err)
+	defer it.Close()
+	var result []*IssuedInvite
+	for ; it.Valid(); it.Next() {
+		invite := new(IssuedInvite)
+		if err := rlp.DecodeBytes(it.Value(), invite); err != nil {
+			log.Error("invalid issued invite RLP", "err", err)
+			continue
+		}
+		result = append(result, invite)
+	}
+	return result
+}
+
+// PriorityPeer is the static or trusted peer added at runtime
+type PriorityPeer struct {
+	Addr    string
+	Static  bool
+	Trusted bool
+}
+
+func (r *Repo) WritePriorityPeers(peers []PriorityPeer) {
+	data, err := rlp.EncodeToBytes(peers)
+	if err != nil {
+		log.Crit("failed to RLP encode priority peers", "err", err)
+		return
+	}
+	assertNoError(r.db.Set(priorityPeersKey, data))
+}
+
+func (r *Repo) ReadPriorityPeers() []PriorityPeer {
+	data, err := r.db.Get(priorityPeersKey)
+	assertNoError(err)
+	if data == nil {
+		return nil
+	}
+	var peers []PriorityPeer
+	if err := rlp.DecodeBytes(data, &peers); err != nil {
+		log.Error("invalid priority peers RLP", "err", err)
+		return nil
+	}
+	return peers
+}
+
+// AnswerAudit is the evidence of ceremony submissions of the node identity, it is kept after the epoch end
+type AnswerAudit struct {
+	Epoch        uint16
+	Address      common.Address
+	ShortFlips   [][]byte
+	LongFlips    [][]byte
+	ShortAnswers []byte
+	LongAnswers  []byte
+	AnswersHash  common.Hash
+	Salt         []byte
+	Txs          []*AuditedTx
+}
+
+// AuditedTx is the submitted ceremony tx with its delivery receipt, Raw is the signed tx
+type AuditedTx struct {
+	Type        uint16
+	Hash        common.Hash
+	Raw         []byte
+	SentAt      uint64
+	Paths       []string
+	BlockHash   common.Hash
+	BlockHeight uint64
+	MinedAt     uint64
+}
+
+// answerAuditKey = answerAuditPrefix + epoch (uint16 big endian) + address
+func answerAuditKey(epoch uint16, addr common.Address) []byte {
+	key := append(append([]byte{}, answerAuditPrefix...), encodeUint16Number(epoch)...)
+	return append(key, addr[:]...)
+}
+
+func (r *Repo) WriteAnswerAudit(audit *AnswerAudit) {
+	data, err := rlp.EncodeToBytes(audit)
+	if err != nil {
+		log.Crit("failed to RLP encode answer audit", "err", err)
+		return
+	}
+	assertNoError(r.db.Set(answerAuditKey(audit.Epoch, audit.Address), data))
+}
+
+func (r *Repo) ReadAnswerAudit(epoch uint16, addr common.Address) *AnswerAudit {
+	data, err := r.db.Get(answerAuditKey(epoch, addr))
+	assertNoError(err)
+	if data == nil {
+		return nil
+	}
+	audit := new(AnswerAudit)
+	if err := rlp.DecodeBytes(data, audit); err != nil {
+		log.Error("invalid answer audit RLP", "err", err)
+		return nil
+	}
+	return audit
+}
+
+// IdentityProofEpoch is the sealed state identity proofs of the epoch are built against
+type IdentityProofEpoch struct {
+	Epoch      uint16
+	Height     uint64
+	BlockHash  common.Hash
+	Root       common.Hash
+	Identities uint32
+}
+
+// IdentityProof is the encoded state identity with the encoded proof of its existence
+type IdentityProof struct {
+	Value []byte
+	Proof []byte
+}
+
+// identityProofKey = identityProofPrefix + epoch (uint16 big endian) [+ address], the key without address holds the epoch
+func identityProofKey(epoch uint16, addr *common.Address) []byte {
+	key := append(append([]byte{}, identityProofPrefix...), encodeUint16Number(epoch)...)
+	if addr == nil {
+		return key
+	}
+	return append(key, addr[:]...)
+}
+
+// WriteIdentityProofs stores proofs of the epoch, the epoch record is written in the same batch so it marks complete ones
+func (r *Repo) WriteIdentityProofs(epoch *IdentityProofEpoch, proofs map[common.Address]*IdentityProof) {
+	batch := r.db.NewBatch()
+	defer batch.Close()
+	for addr, proof := range proofs {
+		addr := addr
+		data, err := rlp.EncodeToBytes(proof)
+		if err != nil {
+			log.Crit("failed to RLP encode identity proof", "err", err)
+			return
+		}
+		batch.Set(identityProofKey(epoch.Epoch, &addr), data)
+	}
+	data, err := rlp.EncodeToBytes(epoch)
+	if err != nil {
+		log.Crit("failed to RLP encode identity proof epoch", "err", err)
+		return
+	}
+	batch.Set(identityProofKey(epoch.Epoch, nil), data)
+	assertNoError(batch.Write())
+}
+
+func (r *Repo) ReadIdentityProofEpoch(epoch uint16) *IdentityProofEpoch {
+	data, err := r.db.Get(identityProofKey(epoch, nil))
+	assertNoError(err)
+	if data == nil {
+		return nil
+	}
+	result := new(IdentityProofEpoch)
+	if err := rlp.DecodeBytes(data, result); err != nil {
+		log.Error("invalid identity proof epoch RLP", "err", err)
+		return nil
+	}
+	return result
+}
+
+func (r *Repo) ReadIdentityProof(epoch uint16, addr common.Address) *IdentityProof {
+	data, err := r.db.Get(identityProofKey(epoch, &addr))
+	assertNoError(err)
+	if data == nil {
+		return nil
+	}
+	result := new(IdentityProof)
+	if err := rlp.DecodeBytes(data, result); err != nil {
+		log.Error("invalid identity proof RLP", "err", err)
+		return nil
+	}
+	return result
+}
+
+// DeleteIdentityProofsBefore removes proofs of epochs preceding the given one
+func (r *Repo) DeleteIdentityProofsBefore(epoch uint16) {
+	it, err := r.db.Iterator(identityProofKey(0, nil), identityProofKey(epoch, nil))
+	assertNoError(err)
+	var keys [][]byte
+	for ; it.Valid(); it.Next() {
+		keys = append(keys, append([]byte{}, it.Key()...))
+	}
+	it.Close()
+	if len(keys) == 0 {
+		return
+	}
+	batch := r.db.NewBatch()
+	defer batch.Close()
+	for _, key := range keys {
+		batch.Delete(key)
+	}
+	assertNoError(batch.Write())
+}
+
+// AncientState is the progress of moving old blocks to the ancient store
+type AncientState struct {
+	// Archived is the height the blocks are moved up to
+	Archived uint64
+	// Scanned is the height headers are checked for epoch starts up to
+	Scanned uint64
+	// EpochStarts are heights of first blocks of epochs found during the scan
+	EpochStarts []uint64
+	// SegmentSize is the number of blocks in archived segments, it is set once and segments are located by it
+	SegmentSize uint64
+}
+
+func (r *Repo) WriteAncientState(state *AncientState) {
+	data, err := rlp.EncodeToBytes(state)
+	if err != nil {
+		log.Crit("failed to RLP encode ancient state", "err", err)
+		return
+	}
+	assertNoError(r.db.Set(ancientStateKey, data))
+}
+
+func (r *Repo) ReadAncientState() *AncientState {
+	data, err := r.db.Get(ancientStateKey)
+	assertNoError(err)
+	if data == nil {
+		return nil
+	}
+	state := new(AncientState)
+	if err := rlp.DecodeBytes(data, state); err != nil {
+		log.Error("invalid ancient state RLP", "err", err)
+		return nil
+	}
+	return state
+}
+
+func txReceiptKey(hash common.Hash) []byte {
+	return append(append([]byte{}, txReceiptPrefix...), hash.Bytes()...)
+}
+
+func (r *Repo) WriteTxReceipt(receipt *types.TxReceipt) {
+	data, err := rlp.EncodeToBytes(receipt)
+	if err != nil {
+		log.Crit("failed to RLP encode tx receipt", "err", err)
+		return
+	}
+	assertNoError(r.db.Set(txReceiptKey(receipt.TxHash), data))
+}
+
+func (r *Repo) ReadTxReceipt(hash common.Hash) *types.TxReceipt {
+	data, err := r.db.Get(txReceiptKey(hash))
+	assertNoError(err)
+	if data == nil {
+		return nil
+	}
+	receipt := new(types.TxReceipt)
+	if err := rlp.DecodeBytes(data, receipt); err != nil {
+		log.Error("invalid tx receipt RLP", "err", err)
+		return nil
+	}
+	return receipt
+}
+
+// AddressLabel is the address book entry set by the node operator
+type AddressLabel struct {
+	Address   common.Address
+	Label     string
+	Note      string
+	UpdatedAt uint64
+}
+
+func addressLabelKey(addr common.Address) []byte {
+	return append(append([]byte{}, addressLabelPrefix...), addr[:]...)
+}
+
+func (r *Repo) WriteAddressLabel(label *AddressLabel) {
+	data, err := rlp.EncodeToBytes(label)
+	if err != nil {
+		log.Crit("failed to RLP encode address label", "err", err)
+		return
+	}
+	assertNoError(r.db.Set(addressLabelKey(label.Address), data))
+}
+
+func (r *Repo) DeleteAddressLabel(addr common.Address) {
+	assertNoError(r.db.Delete(addressLabelKey(addr)))
+}
+
+func (r *Repo) ReadAddressLabels() []*AddressLabel {
+	// the upper bound is the next prefix, addresses may start with 0xFF
+	end := append([]byte{}, addressLabelPrefix...)
+	end[len(end)-1]++
+	it, err := r.db.Iterator(addressLabelPrefix, end)
+	assertNoError(err)
+	defer it.Close()
+	var result []*AddressLabel
+	for ; it.Valid(); it.Next() {
+		label := new(AddressLabel)
+		if err := rlp.DecodeBytes(it.Value(), label); err != nil {
+			log.Error("invalid address label RLP", "err", err)
+			continue
+		}
+		result = append(result, label)
+	}
+	return result
+}
+
+func (r *Repo) WriteSupplyTotals(data []byte) {
+	assertNoError(r.db.Set(supplyTotalsKey, data))
+}
+
+func (r *Repo) ReadSupplyTotals() []byte {
+	data, err := r.db.Get(supplyTotalsKey)
+	assertNoError(err)
+	return data
+}
+
+func blockSupplyKey(height uint64) []byte {
+	return append(append([]byte{}, blockSupplyPrefix...), encodeUint64Number(height)...)
+}
+
+func (r *Repo) WriteBlockSupply(height uint64, data []byte) {
+	assertNoError(r.db.Set(blockSupplyKey(height), data))
+}
+
+func (r *Repo) ReadBlockSupply(height uint64) []byte {
+	data, err := r.db.Get(blockSupplyKey(height))
+	assertNoError(err)
+	return data
+}
+
+func (r *Repo) DeleteBlockSupply(height uint64) {
+	assertNoError(r.db.Delete(blockSupplyKey(height)))
+}
+
+func epochEmissionKey(epoch uint16) []byte {
+	return append(append([]byte{}, epochEmissionPrefix...), encodeUint16Number(epoch)...)
+}
+
+func (r *Repo) WriteEpochEmission(epoch uint16, data []byte) {
+	assertNoError(r.db.Set(epochEmissionKey(epoch), data))
+}
+
+func (r *Repo) ReadEpochEmission(epoch uint16) []byte {
+	data, err := r.db.Get(epochEmissionKey(epoch))
+	assertNoError(err)
+	return data
+}
@@ -73,3 +73,140 @@ func TestRepo_WritActivityMonitor(t *testing.T) {
 	require.Equal(monitor.Data[0].Addr, readActivity.Data[0].Addr)
 	require.Equal(monitor.Data[0].Time.Unix(), readActivity.Data[0].Time.Unix())
 }
+
+func TestRepo_CleanShutdown(t *testing.T) {
+	repo := NewRepo(db.NewMemDB())
+	require.Equal(t, common.Hash{}, repo.ReadCleanShutdown())
+
+	hash := getRandHash()
+	repo.WriteCleanShutdown(hash)
+	require.Equal(t, hash, repo.ReadCleanShutdown())
+
+	repo.RemoveCleanShutdown()
+	require.Equal(t, common.Hash{}, repo.ReadCleanShutdown())
+}
+
+func TestRepo_GetSavedTxs_Filter(t *testing.T) {
+	require := require.New(t)
+	repo := NewRepo(db.NewMemDB())
+	addr := common.Address{0x1}
+
+	save := func(nonce uint32, txType types.TxType, timestamp uint64) {
+		repo.SaveTx(addr, common.Hash{}, timestamp, nil, &types.Transaction{AccountNonce: nonce, Type: txType})
+	}
+	save(1, types.SendTx, 10)
+	save(2, types.OnlineStatusTx, 20)
+	save(3, types.SendTx, 30)
+	save(4, types.BurnTx, 40)
+	save(5, types.SendTx, 50)
+	save(6, types.OnlineStatusTx, 60)
+	repo.SaveTx(common.Address{0x2}, common.Hash{}, 35, nil, &types.Transaction{AccountNonce: 1, Type: types.SendTx})
+
+	nonces := func(txs []*types.SavedTransaction) []uint32 {
+		var result []uint32
+		for _, tx := range txs {
+			result = append(result, tx.Tx.AccountNonce)
+		}
+		return result
+	}
+
+	txs, token := repo.GetSavedTxs(addr, 2, nil, &SavedTxFilter{Types: []types.TxType{types.SendTx, types.BurnTx}})
+	require.Equal([]uint32{5, 4}, nonces(txs))
+	require.NotNil(token)
+	txs, token = repo.GetSavedTxs(addr, 2, token, &SavedTxFilter{Types: []types.TxType{types.SendTx, types.BurnTx}})
+	require.Equal([]uint32{3, 1}, nonces(txs))
+	require.Nil(token)
+
+	txs, token = repo.GetSavedTxs(addr, 10, nil, &SavedTxFilter{FromTime: 20, ToTime: 50})
+	require.Equal([]uint32{5, 4, 3, 2}, nonces(txs))
+	require.Nil(token)
+
+	txs, _ = repo.GetSavedTxs(addr, 10, nil, &SavedTxFilter{Types: []types.TxType{types.OnlineStatusTx}, ToTime: 59})
+	require.Equal([]uint32{2}, nonces(txs))
+
+	txs, _ = repo.GetSavedTxs(addr, 10, nil, &SavedTxFilter{FromTime: 50, ToTime: 20})
+	require.Empty(txs)
+
+	repo.db.Delete(savedTxTypeKey(addr, types.BurnTx, 40, 4, (&types.Transaction{AccountNonce: 4, Type: types.BurnTx}).Hash()))
+	repo.db.Delete(ownTransactionTypeIndexedKey)
+	require.Equal(7, repo.IndexSavedTxTypes())
+	require.Equal(0, repo.IndexSavedTxTypes())
+	txs, _ = repo.GetSavedTxs(addr, 10, nil, &SavedTxFilter{Types: []types.TxType{types.BurnTx}})
+	require.Equal([]uint32{4}, nonces(txs))
+}
+
+func TestRepo_BalanceHistory(t *testing.T) {
+	require := require.New(t)
+	repo := NewRepo(db.NewMemDB())
+	addr, other := common.Address{0x1}, common.Address{0x2}
+
+	require.Nil(repo.ReadWatchList())
+	repo.WriteWatchList([]common.Address{addr, other})
+	require.Equal([]common.Address{addr, other}, repo.ReadWatchList())
+
+	for height := uint64(1); height <= 5; height++ {
+		repo.WriteBalanceHistory(addr, height, []byte{byte(height)})
+	}
+	repo.WriteBalanceHistory(other, 3, []byte{0xff})
+
+	require.Equal([][]byte{{5}, {4}}, repo.ReadBalanceHistory(addr, 10, 2))
+	require.Equal([][]byte{{3}, {2}, {1}}, repo.ReadBalanceHistory(addr, 4, 10))
+
+	require.Equal(2, repo.TrimBalanceHistory(addr, 3))
+	require.Equal([][]byte{{5}, {4}, {3}}, repo.ReadBalanceHistory(addr, 10, 10))
+	require.Equal([][]byte{{0xff}}, repo.ReadBalanceHistory(other, 10, 10))
+}
+
+func TestRepo_CertificateIndex(t *testing.T) {
+	repo := NewRepo(db.NewMemDB())
+	require.Nil(t, repo.ReadCertificateIndex(10))
+
+	cert := &types.BlockCert{Round: 10, VotedHash: getRandHash(), Signatures: []*types.BlockCertSignature{{Signature: []byte{0x1}}}}
+	repo.WriteCertificateIndex(10, cert)
+	require.Equal(t, cert, repo.ReadCertificateIndex(10))
+	require.Nil(t, repo.ReadCertificateIndex(11))
+}
+
+func TestRepo_IssuedInvites(t *testing.T) {
+	repo := NewRepo(db.NewMemDB())
+	first := &IssuedInvite{Receiver: common.Address{0x1}, TxHash: getRandHash(), Epoch: 3, Key: []byte{0x2}, IssuedAt: 10}
+	second := &IssuedInvite{Receiver: common.Address{0x2}, TxHash: getRandHash(), Epoch: 3}
+	repo.WriteIssuedInvite(first)
+	repo.WriteIssuedInvite(second)
+	require.Equal(t, first, repo.ReadIssuedInvite(first.Receiver))
+	require.Len(t, repo.ReadIssuedInvites(), 2)
+
+	repo.DeleteIssuedInvite(first.Receiver)
+	require.Nil(t, repo.ReadIssuedInvite(first.Receiver))
+	require.Equal(t, []*IssuedInvite{{Receiver: second.Receiver, TxHash: second.TxHash, Epoch: 3, Key: []byte{}}}, repo.ReadIssuedInvites())
+}
+
+func TestRepo_WriteAnswerAudit(t *testing.T) {
+	repo := NewRepo(db.NewMemDB())
+	addr := common.Address{0x1}
+
+	require.Nil(t, repo.ReadAnswerAudit(1, addr))
+
+	audit := &AnswerAudit{
+		Epoch:        1,
+		Address:      addr,
+		ShortFlips:   [][]byte{{0x1}, {0x2}},
+		ShortAnswers: []byte{0x3},
+		AnswersHash:  getRandHash(),
+		Salt:         []byte{0x4},
+		Txs:          []*AuditedTx{{Type: types.SubmitAnswersHashTx, Hash: getRandHash(), Raw: []byte{0x5}, SentAt: 10}},
+	}
+	repo.WriteAnswerAudit(audit)
+
+	read := repo.ReadAnswerAudit(1, addr)
+	require.NotNil(t, read)
+	require.Equal(t, audit.ShortFlips, read.ShortFlips)
+	require.Equal(t, audit.ShortAnswers, read.ShortAnswers)
+	require.Equal(t, audit.AnswersHash, read.AnswersHash)
+	require.Equal(t, audit.Salt, read.Salt)
+	require.Len(t, read.Txs, 1)
+	require.Equal(t, audit.Txs[0].Hash, read.Txs[0].Hash)
+	require.Equal(t, uint64(10), read.Txs[0].SentAt)
+	require.Nil(t, repo.ReadAnswerAudit(2, addr))
+	require.Nil(t, repo.ReadAnswerAudit(1, common.Address{0x2}))
+}
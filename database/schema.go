@@ -16,6 +16,10 @@ var (
 
 	ownTransactionIndexPrefix = []byte("oti")
 
+	ownTransactionTypeIndexPrefix = []byte("otyi")
+
+	ownTransactionTypeIndexedKey = []byte("own-tx-type-indexed")
+
 	burntCoinsPrefix = []byte("bc")
 
 	certPrefix = []byte("c")
@@ -31,4 +35,46 @@ var (
 	preliminaryHeadKey = []byte("preliminary-head")
 
 	activityMonitorKey = []byte("activity")
+
+	cleanShutdownKey = []byte("clean-shutdown")
+
+	ipfsPinPrefix = []byte("ipfs-pin")
+
+	poolDelegatorsKey = []byte("pool-delegators")
+
+	onlineIntentKey = []byte("online-intent")
+
+	signingLockKey = []byte("signing-lock")
+
+	signedMessagePrefix = []byte("signed-msg")
+
+	epochReportPrefix = []byte("epoch-report")
+
+	watchListKey = []byte("watch-list")
+
+	watchBalancePrefix = []byte("watch-bal")
+
+	certIndexPrefix = []byte("cert-idx")
+
+	ownFlipImagesKey = []byte("own-flip-images")
+
+	issuedInvitePrefix = []byte("invite")
+
+	priorityPeersKey = []byte("priority-peers")
+
+	answerAuditPrefix = []byte("answer-audit")
+
+	identityProofPrefix = []byte("id-proof")
+
+	ancientStateKey = []byte("ancient")
+
+	txReceiptPrefix = []byte("receipt")
+
+	addressLabelPrefix = []byte("addr-label")
+
+	supplyTotalsKey = []byte("supply-total")
+
+	blockSupplyPrefix = []byte("supply-block")
+
+	epochEmissionPrefix = []byte("supply-epoch")
 )
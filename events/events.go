@@ -2,8 +2,10 @@ package events
 
 import (
 	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
 	"github.com/idena-network/idena-go/common/eventbus"
 	"github.com/libp2p/go-libp2p-core"
+	"math/big"
 )
 
 const (
@@ -15,11 +17,21 @@ const (
 	NewFlipKeysPackageID   = eventbus.EventID("flip-keys-package-new")
 	IpfsPortChangedEventId = eventbus.EventID("ipfs-port-changed")
 	DeleteFlipEventID      = eventbus.EventID("flip-delete")
+	IpfsPinnedEventID      = eventbus.EventID("ipfs-pinned")
+	IdentityChangedEventID = eventbus.EventID("identity-changed")
+	WatchEventID           = eventbus.EventID("watch-event")
+	ReorgEventID           = eventbus.EventID("chain-reorg")
+	CeremonyPhaseEventID   = eventbus.EventID("ceremony-phase")
+	StorageLevelEventID    = eventbus.EventID("storage-level")
 )
 
 type NewTxEvent struct {
 	Tx  *types.Transaction
 	Own bool
+	// Local is set for txs of local senders, their propagation is traced
+	Local bool
+	// Rebroadcast is set when the pool tx is announced again to all peers
+	Rebroadcast bool
 }
 
 func (e *NewTxEvent) EventID() eventbus.EventID {
@@ -82,3 +94,86 @@ type DeleteFlipEvent struct {
 func (DeleteFlipEvent) EventID() eventbus.EventID {
 	return DeleteFlipEventID
 }
+
+type IpfsPinnedEvent struct {
+	Cid      []byte
+	DataType uint32
+}
+
+func (IpfsPinnedEvent) EventID() eventbus.EventID {
+	return IpfsPinnedEventID
+}
+
+// IdentityChangedEvent is published when identity state has changed or identity has been penalized,
+// states are state.IdentityState values
+type IdentityChangedEvent struct {
+	Seq       uint64
+	Address   common.Address
+	Height    uint64
+	Epoch     uint16
+	PrevState uint8
+	State     uint8
+	// Penalty is set if identity has been penalized by the block
+	Penalty *big.Int
+}
+
+func (IdentityChangedEvent) EventID() eventbus.EventID {
+	return IdentityChangedEventID
+}
+
+// WatchEvent is published when a tx of the watched address has been included into the block
+// or the balance of the watched address has changed without a tx
+type WatchEvent struct {
+	Seq       uint64
+	Address   common.Address
+	Height    uint64
+	Timestamp int64
+	// Tx is nil for balance changes without tx
+	Tx      *types.Transaction
+	Balance *big.Int
+	Stake   *big.Int
+	Nonce   uint32
+}
+
+func (WatchEvent) EventID() eventbus.EventID {
+	return WatchEventID
+}
+
+// ReorgEvent is published when the canonical chain has been switched to the fork,
+// DroppedTxs are txs of the replaced blocks which are not included into the fork
+type ReorgEvent struct {
+	OldHead      *types.Header
+	NewHead      *types.Header
+	CommonHeight uint64
+	Depth        uint64
+	DroppedTxs   []*types.Transaction
+}
+
+func (ReorgEvent) EventID() eventbus.EventID {
+	return ReorgEventID
+}
+
+// CeremonyPhaseEvent is published when the block starts or finishes the phase of the validation ceremony,
+// Timestamp is the block time. Height of the results sealed event is the height of the validation block
+type CeremonyPhaseEvent struct {
+	Seq       uint64
+	Phase     string
+	Epoch     uint16
+	Height    uint64
+	Timestamp int64
+}
+
+func (CeremonyPhaseEvent) EventID() eventbus.EventID {
+	return CeremonyPhaseEventID
+}
+
+// StorageLevelEvent is published when disk usage of the node data crosses thresholds of the budget,
+// optional pinning of network data is refused while Critical is set
+type StorageLevelEvent struct {
+	Level    string
+	Critical bool
+}
+
+func (StorageLevelEvent) EventID() eventbus.EventID {
+	return StorageLevelEventID
+}
@@ -0,0 +1,395 @@
+package ipfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/log"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-ipfs/core"
+	"github.com/libp2p/go-libp2p"
+	core2 "github.com/libp2p/go-libp2p-core"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/pnet"
+	"github.com/libp2p/go-libp2p-core/routing"
+	dht "github.com/libp2p/go-libp2p-kad-dht"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr-net"
+	"github.com/patrickmn/go-cache"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	externalRequestTimeout = 30 * time.Second
+	externalHostKeyFile    = "external_host.key"
+)
+
+// externalIpfsProxy stores data in external IPFS daemon via its HTTP API, the node keeps only libp2p host for gossip
+type externalIpfsProxy struct {
+	apiUrl   string
+	client   *http.Client
+	host     core2.Host
+	log      log.Logger
+	cfg      *config.IpfsConfig
+	nilNode  *core.IpfsNode
+	cidCache *cache.Cache
+}
+
+func NewExternalIpfsProxy(cfg *config.IpfsConfig) (Proxy, error) {
+	logger := log.New("component", "ipfs-external")
+
+	apiUrl, err := externalApiUrl(cfg.External)
+	if err != nil {
+		return nil, err
+	}
+
+	nilNode, err := core.NewNode(context.Background(), &core.BuildCfg{
+		NilRepo: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p := &externalIpfsProxy{
+		apiUrl:   apiUrl,
+		client:   &http.Client{},
+		log:      logger,
+		cfg:      cfg,
+		nilNode:  nilNode,
+		cidCache: cache.New(2*time.Minute, 5*time.Minute),
+	}
+
+	var version struct{ Version string }
+	if err := p.call(context.Background(), "version", nil, &version); err != nil {
+		return nil, errors.Wrap(err, "external ipfs daemon is not available")
+	}
+
+	h, err := createExternalHost(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create libp2p host")
+	}
+	p.host = h
+
+	logger.Info("External ipfs connected", "api", apiUrl, "version", version.Version, "peerId", h.ID().Pretty())
+	return p, nil
+}
+
+func externalApiUrl(addr string) (string, error) {
+	maddr, err := ma.NewMultiaddr(addr)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid external ipfs address")
+	}
+	_, hostPort, err := manet.DialArgs(maddr)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid external ipfs address")
+	}
+	return fmt.Sprintf("http://%v/api/v0/", hostPort), nil
+}
+
+func createExternalHost(cfg *config.IpfsConfig) (host.Host, error) {
+	if err := os.MkdirAll(cfg.DataDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	key, err := loadExternalHostKey(filepath.Join(cfg.DataDir, externalHostKeyFile))
+	if err != nil {
+		return nil, err
+	}
+	psk, err := pnet.DecodeV1PSK(bytes.NewReader(swarmKeyFileContent(cfg.SwarmKey)))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot decode swarm key")
+	}
+
+	ctx := context.Background()
+	var kad *dht.IpfsDHT
+	h, err := libp2p.New(ctx,
+		libp2p.Identity(key),
+		libp2p.ListenAddrStrings(fmt.Sprintf("/ip4/0.0.0.0/tcp/%v", cfg.IpfsPort), fmt.Sprintf("/ip6/::/tcp/%v", cfg.IpfsPort)),
+		libp2p.PrivateNetwork(psk),
+		libp2p.Routing(func(h host.Host) (routing.PeerRouting, error) {
+			var err error
+			kad, err = dht.New(ctx, h)
+			return kad, err
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, node := range cfg.BootNodes {
+		info, err := peer.AddrInfoFromP2pAddr(ma.StringCast(node))
+		if err != nil {
+			log.Warn("Invalid ipfs boot node", "addr", node, "err", err)
+			continue
+		}
+		go func(info peer.AddrInfo) {
+			if err := h.Connect(ctx, info); err != nil {
+				log.Warn("Cannot connect to ipfs boot node", "peer", info.ID.Pretty(), "err", err)
+			}
+		}(*info)
+	}
+	if kad != nil {
+		if err := kad.Bootstrap(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+func loadExternalHostKey(path string) (crypto.PrivKey, error) {
+	if data, err := ioutil.ReadFile(path); err == nil {
+		return crypto.UnmarshalPrivateKey(data)
+	}
+	key, _, err := crypto.GenerateKeyPair(crypto.Ed25519, 0)
+	if err != nil {
+		return nil, err
+	}
+	data, err := crypto.MarshalPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (p *externalIpfsProxy) request(ctx context.Context, method string, args url.Values, body io.Reader, contentType string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, p.apiUrl+method+"?"+args.Encode(), body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var apiErr struct{ Message string }
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err == nil && apiErr.Message != "" {
+			return nil, errors.Errorf("ipfs %v: %v", method, apiErr.Message)
+		}
+		return nil, errors.Errorf("ipfs %v: unexpected status %v", method, resp.Status)
+	}
+	return resp, nil
+}
+
+func (p *externalIpfsProxy) call(ctx context.Context, method string, args url.Values, result interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, externalRequestTimeout)
+	defer cancel()
+	resp, err := p.request(ctx, method, args, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if result == nil {
+		_, err = io.Copy(ioutil.Discard, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func (p *externalIpfsProxy) add(ctx context.Context, name string, data io.Reader, pin bool) (cid.Cid, error) {
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	go func() {
+		part, err := writer.CreateFormFile("file", name)
+		if err == nil {
+			_, err = io.Copy(part, data)
+		}
+		if err == nil {
+			err = writer.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	args := url.Values{}
+	args.Set("cid-version", "1")
+	args.Set("pin", strconv.FormatBool(pin))
+	resp, err := p.request(ctx, "add", args, pr, writer.FormDataContentType())
+	if err != nil {
+		pr.CloseWithError(err)
+		return cid.Cid{}, err
+	}
+	defer resp.Body.Close()
+	var result struct{ Hash string }
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return cid.Cid{}, err
+	}
+	return cid.Decode(result.Hash)
+}
+
+func (p *externalIpfsProxy) Add(data []byte, pin bool) (cid.Cid, error) {
+	if len(data) == 0 {
+		return EmptyCid, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), externalRequestTimeout)
+	defer cancel()
+	c, err := p.add(ctx, "data", bytes.NewReader(data), pin)
+	if err != nil {
+		return cid.Cid{}, err
+	}
+	p.log.Debug("Add ipfs data", "cid", c.String(), "pin", pin)
+	return c, nil
+}
+
+func (p *externalIpfsProxy) AddFile(absPath string, data io.ReadCloser, fi os.FileInfo) (cid.Cid, error) {
+	defer data.Close()
+	return p.add(context.Background(), filepath.Base(absPath), data, true)
+}
+
+func (p *externalIpfsProxy) Get(key []byte) ([]byte, error) {
+	c, err := cid.Cast(key)
+	if err != nil {
+		return nil, err
+	}
+	if c == EmptyCid {
+		return []byte{}, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), externalRequestTimeout)
+	defer cancel()
+	resp, err := p.request(ctx, "cat", url.Values{"arg": {c.String()}}, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (p *externalIpfsProxy) LoadTo(key []byte, to io.Writer, ctx context.Context, onLoading func(size, loaded int64)) error {
+	c, err := cid.Cast(key)
+	if err != nil {
+		return err
+	}
+	if c == EmptyCid {
+		return nil
+	}
+	resp, err := p.request(ctx, "cat", url.Values{"arg": {c.String()}}, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	size, _ := strconv.ParseInt(resp.Header.Get("X-Content-Length"), 10, 64)
+
+	buf := make([]byte, 1024*1024)
+	var loaded int64
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			if _, err := to.Write(buf[:n]); err != nil {
+				return err
+			}
+			loaded += int64(n)
+			if onLoading != nil {
+				onLoading(size, loaded)
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (p *externalIpfsProxy) Pin(key []byte) error {
+	c, err := cid.Cast(key)
+	if err != nil {
+		return err
+	}
+	if c == EmptyCid {
+		return nil
+	}
+	return p.call(context.Background(), "pin/add", url.Values{"arg": {c.String()}}, nil)
+}
+
+func (p *externalIpfsProxy) Unpin(key []byte) error {
+	c, err := cid.Cast(key)
+	if err != nil {
+		return err
+	}
+	if c == EmptyCid {
+		return nil
+	}
+	return p.call(context.Background(), "pin/rm", url.Values{"arg": {c.String()}}, nil)
+}
+
+func (p *externalIpfsProxy) Remove(key []byte) error {
+	c, err := cid.Cast(key)
+	if err != nil {
+		return err
+	}
+	if c == EmptyCid {
+		return nil
+	}
+	var object struct {
+		Links []struct{ Hash string }
+	}
+	if err := p.call(context.Background(), "object/links", url.Values{"arg": {c.String()}}, &object); err != nil {
+		return err
+	}
+	args := url.Values{"arg": {c.String()}}
+	for _, link := range object.Links {
+		args.Add("arg", link.Hash)
+	}
+	return p.call(context.Background(), "block/rm", args, nil)
+}
+
+func (p *externalIpfsProxy) Cid(data []byte) (cid.Cid, error) {
+	return calculateCid(p.nilNode, p.cidCache, data)
+}
+
+func (p *externalIpfsProxy) Port() int {
+	return p.cfg.IpfsPort
+}
+
+func (p *externalIpfsProxy) PeerId() string {
+	return p.host.ID().Pretty()
+}
+
+func (p *externalIpfsProxy) Host() core2.Host {
+	return p.host
+}
+
+func (p *externalIpfsProxy) GarbageCollect(ctx context.Context) (int, error) {
+	resp, err := p.request(ctx, "repo/gc", nil, nil, "")
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	removed := 0
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var result struct{ Error string }
+		if err := decoder.Decode(&result); err == io.EOF {
+			return removed, nil
+		} else if err != nil {
+			return removed, err
+		}
+		if result.Error != "" {
+			return removed, errors.New(result.Error)
+		}
+		removed++
+	}
+}
+
+func (p *externalIpfsProxy) ShouldPin(dataType DataType) bool {
+	return shouldPin(p.cfg, dataType)
+}
@@ -0,0 +1,50 @@
+package ipfs
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExternalApiUrl(t *testing.T) {
+	require := require.New(t)
+
+	u, err := externalApiUrl("/ip4/127.0.0.1/tcp/5001")
+	require.NoError(err)
+	require.Equal("http://127.0.0.1:5001/api/v0/", u)
+
+	_, err = externalApiUrl("127.0.0.1:5001")
+	require.Error(err)
+}
+
+func TestExternalIpfsProxy_Pin(t *testing.T) {
+	require := require.New(t)
+
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path+"?"+r.URL.RawQuery)
+		if strings.HasSuffix(r.URL.Path, "pin/rm") {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"Message":"not pinned"}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	p := &externalIpfsProxy{
+		apiUrl: server.URL + "/api/v0/",
+		client: server.Client(),
+	}
+	c, _ := EmptyCid.Prefix().Sum([]byte{0x1})
+
+	require.NoError(p.Pin(c.Bytes()))
+	require.EqualError(p.Unpin(c.Bytes()), "ipfs pin/rm: not pinned")
+	require.NoError(p.Pin(EmptyCid.Bytes()))
+	require.Equal([]string{
+		"/api/v0/pin/add?arg=" + c.String(),
+		"/api/v0/pin/rm?arg=" + c.String(),
+	}, paths)
+}
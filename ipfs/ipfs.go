@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"github.com/idena-network/idena-go/common/bandwidth"
 	"github.com/idena-network/idena-go/common/eventbus"
 	"github.com/idena-network/idena-go/config"
 	"github.com/idena-network/idena-go/events"
@@ -16,6 +17,7 @@ import (
 	"github.com/ipfs/go-ipfs-files"
 	"github.com/ipfs/go-ipfs/core"
 	"github.com/ipfs/go-ipfs/core/coreapi"
+	"github.com/ipfs/go-ipfs/core/corerepo"
 	"github.com/ipfs/go-ipfs/core/coreunix"
 	"github.com/ipfs/go-ipfs/plugin/loader"
 	"github.com/ipfs/go-ipfs/repo/fsrepo"
@@ -39,6 +41,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -50,8 +53,10 @@ const (
 type DataType = uint32
 
 const (
-	Block DataType = 1
-	Flip  DataType = 2
+	Block    DataType = 1
+	Flip     DataType = 2
+	Snapshot DataType = 3
+	Profile  DataType = 4
 )
 
 var (
@@ -75,12 +80,16 @@ type Proxy interface {
 	LoadTo(key []byte, to io.Writer, ctx context.Context, onLoading func(size, loaded int64)) error
 	Pin(key []byte) error
 	Unpin(key []byte) error
+	// Remove deletes blocks of the unpinned data from the local repo
+	Remove(key []byte) error
 	Cid(data []byte) (cid.Cid, error)
 	Port() int
 	PeerId() string
 	AddFile(absPath string, data io.ReadCloser, fi os.FileInfo) (cid.Cid, error)
 	Host() core2.Host
 	ShouldPin(dataType DataType) bool
+	// GarbageCollect removes unpinned blocks from the repo and returns the number of removed blocks
+	GarbageCollect(ctx context.Context) (int, error)
 }
 
 type ipfsProxy struct {
@@ -94,15 +103,24 @@ type ipfsProxy struct {
 	nilNode              *core.IpfsNode
 	lastPeersUpdatedTime time.Time
 	bus                  eventbus.Bus
+	bandwidth            *bandwidth.Scheduler
+	// lowStorage is set while the disk budget is almost exhausted, optional pins are refused
+	lowStorage int32
 }
 
 func (p *ipfsProxy) Host() core2.Host {
 	return p.node.PeerHost
 }
 
-func NewIpfsProxy(cfg *config.IpfsConfig, bus eventbus.Bus) (Proxy, error) {
+// NewIpfsProxy creates the embedded IPFS node or the proxy of the external one, data read through the embedded node
+// is throttled by the bandwidth scheduler: snapshots as sync and other data as flips
+func NewIpfsProxy(cfg *config.IpfsConfig, bus eventbus.Bus, bandwidth *bandwidth.Scheduler) (Proxy, error) {
 	logging.SetLevel(0, "core")
 
+	if cfg.External != "" {
+		return NewExternalIpfsProxy(cfg)
+	}
+
 	err := loadPlugins(cfg)
 
 	if err != nil {
@@ -136,6 +154,16 @@ func NewIpfsProxy(cfg *config.IpfsConfig, bus eventbus.Bus) (Proxy, error) {
 		lastPeersUpdatedTime: time.Now().UTC(),
 		nilNode:              nilNode,
 		bus:                  bus,
+		bandwidth:            bandwidth,
+	}
+	if bus != nil {
+		_ = bus.Subscribe(events.StorageLevelEventID, func(e eventbus.Event) {
+			var value int32
+			if e.(*events.StorageLevelEvent).Critical {
+				value = 1
+			}
+			atomic.StoreInt32(&p.lowStorage, value)
+		})
 	}
 
 	go p.watchPeers()
@@ -223,13 +251,28 @@ func (p *ipfsProxy) watchPeers() {
 	}
 }
 
+func (p *ipfsProxy) GarbageCollect(ctx context.Context) (int, error) {
+	removed := 0
+	err := corerepo.CollectResult(ctx, corerepo.GarbageCollectAsync(p.node, ctx), func(cid.Cid) {
+		removed++
+	})
+	return removed, err
+}
+
 func (p *ipfsProxy) ShouldPin(dataType DataType) bool {
+	if (dataType == Block || dataType == Flip) && atomic.LoadInt32(&p.lowStorage) == 1 {
+		return false
+	}
+	return shouldPin(p.cfg, dataType)
+}
+
+func shouldPin(cfg *config.IpfsConfig, dataType DataType) bool {
 	q := rand.Float32()
 	if dataType == Block {
-		return q <= p.cfg.BlockPinThreshold
+		return q <= cfg.BlockPinThreshold
 	}
 	if dataType == Flip {
-		return q <= p.cfg.FlipPinThreshold
+		return q <= cfg.FlipPinThreshold
 	}
 	return true
 }
@@ -336,7 +379,7 @@ func (p *ipfsProxy) get(path path.Path) ([]byte, error) {
 	defer file.Close()
 
 	buf := new(bytes.Buffer)
-	_, err = buf.ReadFrom(file)
+	_, err = buf.ReadFrom(p.bandwidth.Reader(bandwidth.Flips, file))
 
 	if err != nil {
 		return nil, err
@@ -375,7 +418,7 @@ func (p *ipfsProxy) LoadTo(key []byte, to io.Writer, ctx context.Context, onLoad
 	if err != nil {
 		return err
 	}
-	_, err = io.Copy(to, &progressReader{r: file, size: size, onLoading: onLoading})
+	_, err = io.Copy(to, p.bandwidth.Reader(bandwidth.Sync, &progressReader{r: file, size: size, onLoading: onLoading}))
 	return err
 }
 
@@ -429,6 +472,34 @@ func (p *ipfsProxy) Unpin(key []byte) error {
 	return err
 }
 
+func (p *ipfsProxy) Remove(key []byte) error {
+	c, err := cid.Cast(key)
+	if err != nil {
+		return err
+	}
+	if c == EmptyCid {
+		return nil
+	}
+
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	api, _ := coreapi.NewCoreAPI(p.node)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	node, err := api.Dag().Get(ctx, c)
+	if err != nil {
+		return err
+	}
+	for _, link := range node.Links() {
+		if err := api.Block().Rm(ctx, path.IpfsPath(link.Cid)); err != nil {
+			return err
+		}
+	}
+	return api.Block().Rm(ctx, path.IpfsPath(c))
+}
+
 func (p *ipfsProxy) Port() int {
 	return p.cfg.IpfsPort
 }
@@ -438,18 +509,20 @@ func (p *ipfsProxy) PeerId() string {
 }
 
 func (p *ipfsProxy) Cid(data []byte) (cid.Cid, error) {
+	return calculateCid(p.nilNode, p.cidCache, data)
+}
+
+func calculateCid(nilnode *core.IpfsNode, cidCache *cache.Cache, data []byte) (cid.Cid, error) {
 	if len(data) == 0 {
 		return EmptyCid, nil
 	}
 
 	hash := rlp.Hash(data)
 	cacheKey := string(hash[:])
-	if value, ok := p.cidCache.Get(cacheKey); ok {
+	if value, ok := cidCache.Get(cacheKey); ok {
 		return value.(cid.Cid), nil
 	}
 
-	nilnode := p.nilNode
-
 	addblockstore := nilnode.Blockstore
 	exch := nilnode.Exchange
 	pinning := nilnode.Pinning
@@ -487,7 +560,7 @@ func (p *ipfsProxy) Cid(data []byte) (cid.Cid, error) {
 	if err != nil {
 		return EmptyCid, err
 	}
-	p.cidCache.Set(cacheKey, nd.Cid(), cache.DefaultExpiration)
+	cidCache.Set(cacheKey, nd.Cid(), cache.DefaultExpiration)
 	return nd.Cid(), nil
 }
 
@@ -589,13 +662,17 @@ func configureIpfs(cfg *config.IpfsConfig) (*ipfsConf.Config, error) {
 func writeSwarmKey(dataDir string, swarmKey string) {
 	swarmPath := filepath.Join(dataDir, "swarm.key")
 	if _, err := os.Stat(swarmPath); os.IsNotExist(err) {
-		err = ioutil.WriteFile(swarmPath, []byte(fmt.Sprintf("/key/swarm/psk/1.0.0/\n/base16/\n%v", swarmKey)), 0644)
+		err = ioutil.WriteFile(swarmPath, swarmKeyFileContent(swarmKey), 0644)
 		if err != nil {
 			log.Error(fmt.Sprintf("Failed to persist swarm file: %v", err))
 		}
 	}
 }
 
+func swarmKeyFileContent(swarmKey string) []byte {
+	return []byte(fmt.Sprintf("/key/swarm/psk/1.0.0/\n/base16/\n%v", swarmKey))
+}
+
 func getNodeConfig(dataDir string) *core.BuildCfg {
 	repo, _ := fsrepo.Open(dataDir)
 
@@ -648,6 +725,10 @@ func (i *memoryIpfs) ShouldPin(dataType DataType) bool {
 	return true
 }
 
+func (i *memoryIpfs) GarbageCollect(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
 func (i *memoryIpfs) Host() core2.Host {
 	panic("implement me")
 }
@@ -664,6 +745,15 @@ func (i *memoryIpfs) Unpin(key []byte) error {
 	return nil
 }
 
+func (i *memoryIpfs) Remove(key []byte) error {
+	c, err := cid.Cast(key)
+	if err != nil {
+		return err
+	}
+	delete(i.values, c)
+	return nil
+}
+
 func (i *memoryIpfs) Add(data []byte, pin bool) (cid.Cid, error) {
 	cid, _ := i.Cid(data)
 	i.values[cid] = data
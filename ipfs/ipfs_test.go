@@ -29,7 +29,7 @@ func TestIpfsProxy_Get_Cid(t *testing.T) {
 		IpfsPort:    4012,
 		DataDir:     "./datadir-ipfs",
 		GracePeriod: "20s",
-	}, eventbus.New())
+	}, eventbus.New(), nil)
 	require.NoError(err)
 	cid, _ := proxy.Cid([]byte{0x1})
 	cid2, _ := proxy.Cid([]byte{0x1})
@@ -0,0 +1,178 @@
+package keystore
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/idena-network/idena-go/common/math"
+	"github.com/idena-network/idena-go/crypto"
+	"golang.org/x/crypto/pbkdf2"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// HardenedOffset is added to indexes of hardened derivation path components, written with the ' suffix
+const HardenedOffset = 0x80000000
+
+// DefaultBaseDerivationPath is the BIP-44 path accounts are derived under, idena keys and addresses are the same as
+// Ethereum ones, so wallets using the Ethereum coin type derive the same accounts from the mnemonic
+var DefaultBaseDerivationPath = DerivationPath{44 + HardenedOffset, 60 + HardenedOffset, HardenedOffset, 0}
+
+var masterKeySalt = []byte("Bitcoin seed")
+
+// DerivationPath is the BIP-32 path of the child key, e.g. m/44'/60'/0'/0/1
+type DerivationPath []uint32
+
+func ParseDerivationPath(path string) (DerivationPath, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if len(parts) < 2 || parts[0] != "m" {
+		return nil, fmt.Errorf("derivation path %q should start with m/", path)
+	}
+	var result DerivationPath
+	for _, part := range parts[1:] {
+		var offset uint32
+		if strings.HasSuffix(part, "'") {
+			offset = HardenedOffset
+			part = strings.TrimSuffix(part, "'")
+		}
+		index, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || index >= HardenedOffset {
+			return nil, fmt.Errorf("invalid derivation path component %q", part)
+		}
+		result = append(result, uint32(index)+offset)
+	}
+	return result, nil
+}
+
+func (p DerivationPath) String() string {
+	result := "m"
+	for _, index := range p {
+		if index >= HardenedOffset {
+			result += fmt.Sprintf("/%d'", index-HardenedOffset)
+		} else {
+			result += fmt.Sprintf("/%d", index)
+		}
+	}
+	return result
+}
+
+// Child returns the path extended by the non-hardened index
+func (p DerivationPath) Child(index uint32) DerivationPath {
+	result := make(DerivationPath, len(p), len(p)+1)
+	copy(result, p)
+	return append(result, index)
+}
+
+// SeedFromMnemonic returns the BIP-39 seed of the mnemonic, words are checked against the english wordlist and the
+// checksum of the entropy they encode is verified, only english mnemonics are supported
+func SeedFromMnemonic(mnemonic, password string) ([]byte, error) {
+	words := strings.Fields(strings.ToLower(mnemonic))
+	switch len(words) {
+	case 12, 15, 18, 21, 24:
+	default:
+		return nil, fmt.Errorf("mnemonic should have 12, 15, 18, 21 or 24 words, got %v", len(words))
+	}
+	if err := checkMnemonic(words); err != nil {
+		return nil, err
+	}
+	for _, c := range password {
+		if c > 127 {
+			return nil, errors.New("mnemonic password should be ascii")
+		}
+	}
+	return pbkdf2.Key([]byte(strings.Join(words, " ")), []byte("mnemonic"+password), 2048, 64, sha512.New), nil
+}
+
+// checkMnemonic verifies that every word is in the wordlist and the last bits of the words, 1 per 3 words, are the
+// first bits of the sha256 hash of the entropy encoded by the rest
+func checkMnemonic(words []string) error {
+	bits := make([]byte, (len(words)*11+7)/8)
+	for i, word := range words {
+		index, ok := bip39WordIndexes[word]
+		if !ok {
+			return fmt.Errorf("word %q isn't in the english mnemonic wordlist", word)
+		}
+		for j := 0; j < 11; j++ {
+			if index&(1<<uint(10-j)) != 0 {
+				pos := i*11 + j
+				bits[pos/8] |= 1 << uint(7-pos%8)
+			}
+		}
+	}
+	checksumBits := len(words) / 3
+	entropy := bits[:(len(words)*11-checksumBits)/8]
+	hash := sha256.Sum256(entropy)
+	for i := 0; i < checksumBits; i++ {
+		pos := len(entropy)*8 + i
+		if (bits[pos/8]>>uint(7-pos%8))&1 != (hash[0]>>uint(7-i))&1 {
+			return errors.New("invalid mnemonic checksum")
+		}
+	}
+	return nil
+}
+
+// DeriveKey derives the BIP-32 private key of the path from the seed
+func DeriveKey(seed []byte, path DerivationPath) (*ecdsa.PrivateKey, error) {
+	key, _, err := deriveExtendedKey(seed, path)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.ToECDSA(key)
+}
+
+func deriveExtendedKey(seed []byte, path DerivationPath) (key []byte, chainCode []byte, err error) {
+	mac := hmac.New(sha512.New, masterKeySalt)
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode = sum[:32], sum[32:]
+	if err := checkKey(new(big.Int).SetBytes(key)); err != nil {
+		return nil, nil, err
+	}
+	for _, index := range path {
+		if key, chainCode, err = deriveChild(key, chainCode, index); err != nil {
+			return nil, nil, err
+		}
+	}
+	return key, chainCode, nil
+}
+
+func deriveChild(key, chainCode []byte, index uint32) ([]byte, []byte, error) {
+	data := make([]byte, 0, 37)
+	if index >= HardenedOffset {
+		data = append(append(data, 0), key...)
+	} else {
+		priv, err := crypto.ToECDSA(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		data = append(data, crypto.CompressPubkey(&priv.PublicKey)...)
+	}
+	data = data[:len(data)+4]
+	binary.BigEndian.PutUint32(data[len(data)-4:], index)
+
+	mac := hmac.New(sha512.New, chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	tweak := new(big.Int).SetBytes(sum[:32])
+	if tweak.Cmp(crypto.S256().Params().N) >= 0 {
+		return nil, nil, fmt.Errorf("index %v derives an invalid key", index)
+	}
+	child := tweak.Add(tweak, new(big.Int).SetBytes(key))
+	child.Mod(child, crypto.S256().Params().N)
+	if err := checkKey(child); err != nil {
+		return nil, nil, fmt.Errorf("index %v derives an invalid key", index)
+	}
+	return math.PaddedBigBytes(child, 32), sum[32:], nil
+}
+
+func checkKey(key *big.Int) error {
+	if key.Sign() == 0 || key.Cmp(crypto.S256().Params().N) >= 0 {
+		return errors.New("invalid seed")
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package keystore
+
+import (
+	"encoding/hex"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/stretchr/testify/require"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestSeedFromMnemonic(t *testing.T) {
+	seed, err := SeedFromMnemonic(testMnemonic, "TREZOR")
+	require.NoError(t, err)
+	require.Equal(t, "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		hex.EncodeToString(seed))
+
+	_, err = SeedFromMnemonic("abandon about", "")
+	require.Error(t, err)
+	_, err = SeedFromMnemonic("абв абв абв абв абв абв абв абв абв абв абв абв", "")
+	require.Error(t, err)
+}
+
+func TestSeedFromMnemonic_Checksum(t *testing.T) {
+	for _, valid := range []string{
+		"legal winner thank year wave sausage worth useful legal winner thank yellow",
+		"letter advice cage absurd amount doctor acoustic avoid letter advice cage above",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+		strings.Repeat("abandon ", 23) + "art",
+		strings.Repeat("zoo ", 23) + "vote",
+	} {
+		_, err := SeedFromMnemonic(valid, "")
+		require.NoError(t, err, valid)
+	}
+
+	for _, invalid := range []string{
+		strings.Repeat("abandon ", 12),
+		strings.Repeat("abandon ", 11) + "able",
+		"legal winner thank year wave sausage worth useful legal winner thank year",
+		strings.Repeat("zoo ", 23) + "wrong",
+	} {
+		_, err := SeedFromMnemonic(invalid, "")
+		require.EqualError(t, err, "invalid mnemonic checksum", invalid)
+	}
+
+	_, err := SeedFromMnemonic(strings.Repeat("abandon ", 11)+"abut", "")
+	require.EqualError(t, err, `word "abut" isn't in the english mnemonic wordlist`)
+}
+
+func TestDeriveKey(t *testing.T) {
+	seed, err := SeedFromMnemonic(testMnemonic, "")
+	require.NoError(t, err)
+	key, err := DeriveKey(seed, DefaultBaseDerivationPath.Child(0))
+	require.NoError(t, err)
+	require.Equal(t, common.HexToAddress("0x9858EfFD232B4033E47d90003D41EC34EcaEda94"), crypto.PubkeyToAddress(key.PublicKey))
+}
+
+func TestParseDerivationPath(t *testing.T) {
+	path, err := ParseDerivationPath("m/44'/60'/0'/0/5")
+	require.NoError(t, err)
+	require.Equal(t, DefaultBaseDerivationPath.Child(5), path)
+	require.Equal(t, "m/44'/60'/0'/0/5", path.String())
+
+	for _, invalid := range []string{"", "m", "44'/60'", "m/x", "m/2147483648", "m/1''"} {
+		_, err := ParseDerivationPath(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestKeyStore_HDWallet(t *testing.T) {
+	dir, ks := tmpKeyStore(t, true)
+	defer os.RemoveAll(dir)
+
+	_, err := ks.DeriveAccounts(1, "foo")
+	require.Equal(t, ErrNoHDWallet, err)
+
+	require.NoError(t, ks.ImportMnemonic(testMnemonic, "", "foo", nil))
+	require.Equal(t, ErrHDWalletExists, ks.ImportMnemonic(testMnemonic, "", "foo", nil))
+
+	_, err = ks.DeriveAccounts(1, "bar")
+	require.Equal(t, ErrDecrypt, err)
+
+	derived, err := ks.DeriveAccounts(2, "foo")
+	require.NoError(t, err)
+	require.Len(t, derived, 2)
+	require.Equal(t, common.HexToAddress("0x9858EfFD232B4033E47d90003D41EC34EcaEda94"), derived[0].Address)
+	require.Equal(t, "m/44'/60'/0'/0/1", derived[1].Path)
+
+	more, err := ks.DeriveAccounts(1, "foo")
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), more[0].Index)
+
+	// derived accounts aren't key files
+	require.Empty(t, ks.Accounts())
+
+	// the wallet is loaded by the new keystore of the same directory
+	ks = NewKeyStore(dir, veryLightScryptN, veryLightScryptP)
+	accounts, err := ks.HDAccounts()
+	require.NoError(t, err)
+	require.Equal(t, append(derived, more...), accounts)
+
+	account, err := ks.Find(Account{Address: derived[1].Address})
+	require.NoError(t, err)
+	require.Equal(t, URL{Scheme: HDScheme, Path: derived[1].Path}, account.URL)
+
+	_, err = ks.SignHash(account, testSigData)
+	require.Equal(t, ErrLocked, err)
+	require.NoError(t, ks.TimedUnlock(account, "foo", time.Minute))
+	sig, err := ks.SignHash(account, testSigData)
+	require.NoError(t, err)
+	pub, err := crypto.SigToPub(testSigData, sig)
+	require.NoError(t, err)
+	require.Equal(t, derived[1].Address, crypto.PubkeyToAddress(*pub))
+
+	keyJSON, err := ks.Export(account, "foo", "bar")
+	require.NoError(t, err)
+	key, err := DecryptKey(keyJSON, "bar")
+	require.NoError(t, err)
+	require.Equal(t, derived[1].Address, key.Address)
+
+	require.Equal(t, ErrNotSupported, ks.Delete(account, "foo"))
+	require.Equal(t, ErrNotSupported, ks.Update(account, "foo", "bar"))
+}
@@ -0,0 +1,249 @@
+package keystore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/crypto"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// HDScheme is the URL scheme of accounts derived from the HD wallet seed, the URL path is the derivation path
+const HDScheme = "hd"
+
+// the wallet is kept in a subfolder since the account cache treats every file of the key directory as a key file
+const hdWalletFile = "hd/wallet.json"
+
+var (
+	ErrHDWalletExists = errors.New("hd wallet already exists")
+	ErrNoHDWallet     = errors.New("hd wallet isn't created, import the mnemonic first")
+)
+
+type HDAccount struct {
+	Address common.Address `json:"address"`
+	Index   uint32         `json:"index"`
+	Path    string         `json:"path"`
+}
+
+type hdWalletJSON struct {
+	BasePath string      `json:"basePath"`
+	Crypto   CryptoJSON  `json:"crypto"`
+	Accounts []HDAccount `json:"accounts"`
+}
+
+// hdWallet keeps the encrypted seed and addresses of derived accounts, so accounts are listed and found without the
+// passphrase while their keys are derived only when they are unlocked or exported
+type hdWallet struct {
+	file             string
+	scryptN, scryptP int
+
+	mutex     sync.Mutex
+	loaded    bool
+	data      *hdWalletJSON
+	basePath  DerivationPath
+	byAddress map[common.Address]HDAccount
+}
+
+func newHDWallet(keydir string, scryptN, scryptP int) *hdWallet {
+	return &hdWallet{
+		file:    filepath.Join(keydir, hdWalletFile),
+		scryptN: scryptN,
+		scryptP: scryptP,
+	}
+}
+
+func (w *hdWallet) load() error {
+	if w.loaded {
+		return nil
+	}
+	content, err := ioutil.ReadFile(w.file)
+	if os.IsNotExist(err) {
+		w.loaded = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	data := &hdWalletJSON{}
+	if err := json.Unmarshal(content, data); err != nil {
+		return fmt.Errorf("invalid hd wallet file: %v", err)
+	}
+	if err := w.set(data); err != nil {
+		return err
+	}
+	w.loaded = true
+	return nil
+}
+
+func (w *hdWallet) set(data *hdWalletJSON) error {
+	basePath, err := ParseDerivationPath(data.BasePath)
+	if err != nil {
+		return err
+	}
+	w.data, w.basePath = data, basePath
+	w.byAddress = make(map[common.Address]HDAccount, len(data.Accounts))
+	for _, account := range data.Accounts {
+		w.byAddress[account.Address] = account
+	}
+	return nil
+}
+
+func (w *hdWallet) save(data *hdWalletJSON) error {
+	content, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if err := writeKeyFile(w.file, content); err != nil {
+		return err
+	}
+	return w.set(data)
+}
+
+func (w *hdWallet) create(seed []byte, basePath DerivationPath, passphrase string) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if err := w.load(); err != nil {
+		return err
+	}
+	if w.data != nil {
+		return ErrHDWalletExists
+	}
+	if _, err := DeriveKey(seed, basePath); err != nil {
+		return err
+	}
+	cryptoStruct, err := EncryptDataV3(seed, []byte(passphrase), w.scryptN, w.scryptP)
+	if err != nil {
+		return err
+	}
+	return w.save(&hdWalletJSON{
+		BasePath: basePath.String(),
+		Crypto:   cryptoStruct,
+		Accounts: []HDAccount{},
+	})
+}
+
+func (w *hdWallet) seed(passphrase string) ([]byte, error) {
+	if w.data == nil {
+		return nil, ErrNoHDWallet
+	}
+	return DecryptDataV3(w.data.Crypto, passphrase)
+}
+
+// derive derives next count accounts, the seed is decrypted once for all of them
+func (w *hdWallet) derive(count int, passphrase string) ([]HDAccount, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if err := w.load(); err != nil {
+		return nil, err
+	}
+	seed, err := w.seed(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	key, chainCode, err := deriveExtendedKey(seed, w.basePath)
+	if err != nil {
+		return nil, err
+	}
+	next := uint32(len(w.data.Accounts))
+	var derived []HDAccount
+	for i := uint32(0); i < uint32(count); i++ {
+		index := next + i
+		if index >= HardenedOffset {
+			return nil, errors.New("hd wallet has no more non-hardened indexes")
+		}
+		childKey, _, err := deriveChild(key, chainCode, index)
+		if err != nil {
+			return nil, err
+		}
+		priv, err := crypto.ToECDSA(childKey)
+		if err != nil {
+			return nil, err
+		}
+		derived = append(derived, HDAccount{
+			Address: crypto.PubkeyToAddress(priv.PublicKey),
+			Index:   index,
+			Path:    w.basePath.Child(index).String(),
+		})
+		zeroKey(priv)
+	}
+	data := *w.data
+	data.Accounts = append(append([]HDAccount{}, w.data.Accounts...), derived...)
+	if err := w.save(&data); err != nil {
+		return nil, err
+	}
+	return derived, nil
+}
+
+func (w *hdWallet) accounts() ([]HDAccount, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if err := w.load(); err != nil {
+		return nil, err
+	}
+	if w.data == nil {
+		return nil, ErrNoHDWallet
+	}
+	return append([]HDAccount{}, w.data.Accounts...), nil
+}
+
+func (w *hdWallet) find(addr common.Address) (HDAccount, bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if err := w.load(); err != nil {
+		return HDAccount{}, false
+	}
+	account, ok := w.byAddress[addr]
+	return account, ok
+}
+
+func (w *hdWallet) key(addr common.Address, passphrase string) (*Key, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if err := w.load(); err != nil {
+		return nil, err
+	}
+	account, ok := w.byAddress[addr]
+	if !ok {
+		return nil, ErrNoMatch
+	}
+	seed, err := w.seed(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	priv, err := DeriveKey(seed, w.basePath.Child(account.Index))
+	if err != nil {
+		return nil, err
+	}
+	return newKeyFromECDSA(priv), nil
+}
+
+// ImportMnemonic creates the HD wallet from the BIP-39 mnemonic, the seed is encrypted with the passphrase which
+// unlocks derived accounts. Nil basePath derives accounts under DefaultBaseDerivationPath.
+func (ks *KeyStore) ImportMnemonic(mnemonic, mnemonicPassword, passphrase string, basePath DerivationPath) error {
+	seed, err := SeedFromMnemonic(mnemonic, mnemonicPassword)
+	if err != nil {
+		return err
+	}
+	if basePath == nil {
+		basePath = DefaultBaseDerivationPath
+	}
+	return ks.hd.create(seed, basePath, passphrase)
+}
+
+// DeriveAccounts derives next count accounts of the HD wallet, derived accounts are unlocked, used for signing and
+// exported like key file ones
+func (ks *KeyStore) DeriveAccounts(count int, passphrase string) ([]HDAccount, error) {
+	if count <= 0 {
+		return nil, errors.New("count should be positive")
+	}
+	return ks.hd.derive(count, passphrase)
+}
+
+// HDAccounts returns accounts derived from the HD wallet ordered by index
+func (ks *KeyStore) HDAccounts() ([]HDAccount, error) {
+	return ks.hd.accounts()
+}
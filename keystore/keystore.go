@@ -59,6 +59,7 @@ type KeyStore struct {
 	cache    *accountCache                // In-memory account cache over the filesystem storage
 	changes  chan struct{}                // Channel receiving change notifications from the cache
 	unlocked map[common.Address]*unlocked // Currently unlocked account (decrypted private keys)
+	hd       *hdWallet                    // Seed and accounts derived from it
 
 	updating bool // Whether the event notification loop is running
 
@@ -95,6 +96,8 @@ func (ks *KeyStore) init(keydir string) {
 	// Initialize the set of unlocked keys and the account cache
 	ks.unlocked = make(map[common.Address]*unlocked)
 	ks.cache, ks.changes = newAccountCache(keydir)
+	scryptN, scryptP := ks.scryptParams()
+	ks.hd = newHDWallet(keydir, scryptN, scryptP)
 
 	// TODO: In order for this finalizer to work, there must be no references
 	// to ks. addressCache doesn't keep a reference but unlocked keys do,
@@ -127,6 +130,10 @@ func (ks *KeyStore) Delete(a Account, passphrase string) error {
 	if err != nil {
 		return err
 	}
+	// derived accounts have no key file
+	if a.URL.Scheme == HDScheme {
+		return ErrNotSupported
+	}
 	// The order is crucial here. The key is dropped from the
 	// cache after the file is gone so that a reload happening in
 	// between won't insert it into the cache again.
@@ -244,12 +251,18 @@ func (ks *KeyStore) TimedUnlock(a Account, passphrase string, timeout time.Durat
 }
 
 // Find resolves the given account into a unique entry in the keystore.
+// Accounts derived from the HD wallet are resolved by address if there is no key file.
 func (ks *KeyStore) Find(a Account) (Account, error) {
 	ks.cache.maybeReload()
 	ks.cache.mu.Lock()
-	a, err := ks.cache.find(a)
+	found, err := ks.cache.find(a)
 	ks.cache.mu.Unlock()
-	return a, err
+	if err == ErrNoMatch && (a.URL == (URL{}) || a.URL.Scheme == HDScheme) {
+		if account, ok := ks.hd.find(a.Address); ok {
+			return Account{Address: account.Address, URL: URL{Scheme: HDScheme, Path: account.Path}}, nil
+		}
+	}
+	return found, err
 }
 
 func (ks *KeyStore) getDecryptedKey(a Account, auth string) (Account, *Key, error) {
@@ -257,6 +270,10 @@ func (ks *KeyStore) getDecryptedKey(a Account, auth string) (Account, *Key, erro
 	if err != nil {
 		return a, nil, err
 	}
+	if a.URL.Scheme == HDScheme {
+		key, err := ks.hd.key(a.Address, auth)
+		return a, key, err
+	}
 	key, err := ks.storage.GetKey(a.Address, a.URL.Path, auth)
 	return a, key, err
 }
@@ -300,13 +317,15 @@ func (ks *KeyStore) Export(a Account, passphrase, newPassphrase string) (keyJSON
 	if err != nil {
 		return nil, err
 	}
-	var N, P int
+	N, P := ks.scryptParams()
+	return EncryptKey(key, newPassphrase, N, P)
+}
+
+func (ks *KeyStore) scryptParams() (N, P int) {
 	if store, ok := ks.storage.(*keyStorePassphrase); ok {
-		N, P = store.scryptN, store.scryptP
-	} else {
-		N, P = StandardScryptN, StandardScryptP
+		return store.scryptN, store.scryptP
 	}
-	return EncryptKey(key, newPassphrase, N, P)
+	return StandardScryptN, StandardScryptP
 }
 
 // Import stores the given encrypted JSON key into the key directory.
@@ -345,6 +364,10 @@ func (ks *KeyStore) Update(a Account, passphrase, newPassphrase string) error {
 	if err != nil {
 		return err
 	}
+	if a.URL.Scheme == HDScheme {
+		zeroKey(key.PrivateKey)
+		return ErrNotSupported
+	}
 	return ks.storage.StoreKey(a.URL.Path, key, newPassphrase)
 }
 
@@ -130,6 +130,20 @@ func prepFile(path string) (*countingWriter, error) {
 // at the given path. When a file's size reaches the limit, the handler creates
 // a new file named after the timestamp of the first log record it will contain.
 func RotatingFileHandler(path string, limit uint, formatter Format) (Handler, error) {
+	return NewRotatingFileHandler(path, limit, formatter)
+}
+
+// RotatingHandler writes log records to the file which is moved to path.old when its size reaches the limit
+// or when the rotation is requested
+type RotatingHandler struct {
+	path    string
+	limit   uint
+	counter *countingWriter
+	h       Handler
+	mu      sync.RWMutex
+}
+
+func NewRotatingFileHandler(path string, limit uint, formatter Format) (*RotatingHandler, error) {
 	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
 		return nil, err
@@ -139,29 +153,50 @@ func RotatingFileHandler(path string, limit uint, formatter Format) (Handler, er
 		return nil, err
 	}
 	counter := &countingWriter{w: f, count: uint(fi.Size())}
-	h := StreamHandler(counter, formatter)
-	var mu sync.Mutex
-	return FuncHandler(func(r *Record) error {
-		if counter.count > limit {
-			mu.Lock()
-			if counter.count > limit {
-				counter.Close()
-				oldFile := fmt.Sprintf("%s.old", path)
-				os.Remove(oldFile)
-				os.Rename(path, oldFile)
-
-				f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
-				if err != nil {
-					mu.Unlock()
-					return err
-				}
-				counter.w = f
-				counter.count = 0
+	return &RotatingHandler{
+		path:    path,
+		limit:   limit,
+		counter: counter,
+		h:       StreamHandler(counter, formatter),
+	}, nil
+}
+
+func (h *RotatingHandler) Log(r *Record) error {
+	if h.counter.count > h.limit {
+		h.mu.Lock()
+		if h.counter.count > h.limit {
+			if err := h.rotate(); err != nil {
+				h.mu.Unlock()
+				return err
 			}
-			mu.Unlock()
 		}
-		return h.Log(r)
-	}), nil
+		h.mu.Unlock()
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.h.Log(r)
+}
+
+// Rotate moves the current log file to path.old and starts the new one
+func (h *RotatingHandler) Rotate() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.rotate()
+}
+
+func (h *RotatingHandler) rotate() error {
+	h.counter.Close()
+	oldFile := fmt.Sprintf("%s.old", h.path)
+	os.Remove(oldFile)
+	os.Rename(h.path, oldFile)
+
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	h.counter.w = f
+	h.counter.count = 0
+	return nil
 }
 
 // NetHandler opens a socket to the given address and writes records
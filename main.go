@@ -1,15 +1,24 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/coreos/go-semver/semver"
+	"github.com/idena-network/idena-go/blockchain/offline"
+	"github.com/idena-network/idena-go/common"
 	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/consensus/sim"
 	"github.com/idena-network/idena-go/log"
 	"github.com/idena-network/idena-go/node"
+	"github.com/pkg/errors"
 	"github.com/urfave/cli"
 	"io/ioutil"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"syscall"
+	"time"
 )
 
 const (
@@ -34,8 +43,15 @@ func main() {
 		config.RpcPortFlag,
 		config.BootNodeFlag,
 		config.AutomineFlag,
+		config.DevFlag,
+		config.DevBlockTimeFlag,
+		config.DevEpochFlag,
+		config.DevAllocFlag,
+		config.PrivateNetworkFlag,
+		config.StandbyFlag,
 		config.IpfsBootNodeFlag,
 		config.IpfsPortFlag,
+		config.IpfsExternalFlag,
 		config.NoDiscoveryFlag,
 		config.VerbosityFlag,
 		config.GodAddressFlag,
@@ -43,11 +59,180 @@ func main() {
 		config.MaxNetworkDelayFlag,
 		config.FastSyncFlag,
 		config.ForceFullSyncFlag,
+		config.SyncCheckpointFlag,
+		config.ManifestSourcesFlag,
+		config.ManifestPinFlag,
+		config.HTTPMirrorFlag,
+		config.MirrorFlag,
+		config.MirrorHostFlag,
+		config.MirrorPortFlag,
+		config.TimeSyncApplyOffsetFlag,
+		config.TimeSyncDriftThresholdFlag,
+		config.CeremonySimulateFlag,
+		config.AnswerRelayFlag,
+		config.FlipPrefetchParallelismFlag,
+		config.FlipPrefetchBandwidthFlag,
+		config.IpfsGcIntervalFlag,
+		config.IpfsGcRetentionFlag,
+		config.OnlineKeeperFlag,
+		config.OnlineKeeperLockFlag,
+		config.TxOrderingFlag,
+		config.TxLocalsFlag,
+		config.TxRpcLocalsFlag,
+		config.TxPoolMaxSizeFlag,
+		config.TxPoolMaxBytesFlag,
+		config.TxPoolAddrPendingFlag,
+		config.TxPoolAddrExecutableFlag,
+		config.TxMaxPayloadSizeFlag,
+		config.TxLifetimeFlag,
+		config.TxRebroadcastFlag,
+		config.IdentityWebhookFlag,
+		config.IdentityWebhookSecretFlag,
+		config.IdentityWatchFlag,
+		config.EpochReportWatchFlag,
+		config.WatchAddressFlag,
+		config.ForkAlertThresholdFlag,
+		config.ForkWebhookFlag,
+		config.PenaltyWebhookFlag,
+		config.PenaltyOfflineThresholdFlag,
+		config.ChainConfigFlag,
+		config.P2PStaticFlag,
+		config.P2PTrustedFlag,
+		config.P2PDnsSeedFlag,
+		config.MaxUploadFlag,
+		config.MaxDownloadFlag,
+		config.CertIndexFlag,
 		config.ProfileFlag,
 		config.IpfsPortStaticFlag,
 		config.ApiKeyFlag,
+		config.ApiKeyFileFlag,
+		config.NodeKeyFileFlag,
+		config.RpcAllowFlag,
+		config.RpcRateLimitFlag,
+		config.RpcJwtSecretFlag,
+		config.RpcCorsFlag,
+		config.RpcTrustedProxiesFlag,
+		config.RpcTlsCertFlag,
+		config.RpcTlsKeyFlag,
+		config.RpcSlowCallFlag,
+		config.RelayFlag,
+		config.RelayMirrorFlag,
+		config.RelayDataFlag,
+		config.StorageBudgetFlag,
 		config.LogFileSizeFlag,
 		config.LogColoring,
+		config.LogVmoduleFlag,
+		config.AncientFlag,
+		config.AncientKeepEpochsFlag,
+		config.AncientDirFlag,
+		config.BackupFlag,
+		config.BackupDirFlag,
+		config.BackupPasswordFileFlag,
+	}
+
+	dbFlags := []cli.Flag{
+		config.CfgFileFlag,
+		config.DataDirFlag,
+		config.DbCheckBodiesFlag,
+	}
+	app.Commands = []cli.Command{
+		{
+			Name:  "db",
+			Usage: "Chain database maintenance",
+			Subcommands: []cli.Command{
+				{
+					Name:   "verify",
+					Usage:  "Verify block store, state and indexes",
+					Flags:  dbFlags,
+					Action: dbCommand(false),
+				},
+				{
+					Name:   "repair",
+					Usage:  "Verify database and re-derive corrupted indexes",
+					Flags:  dbFlags,
+					Action: dbCommand(true),
+				},
+			},
+		},
+		{
+			Name:  "tx",
+			Usage: "Offline transaction tools",
+			Subcommands: []cli.Command{
+				{
+					Name:      "sign",
+					Usage:     "Sign unsigned tx JSON or raw tx without connecting to the network",
+					ArgsUsage: "[file, stdin is used if not set]",
+					Flags: []cli.Flag{
+						config.TxKeyFileFlag,
+						config.TxKeyPasswordFlag,
+						config.TxOutFlag,
+					},
+					Action: signTxCommand,
+				},
+			},
+		},
+		{
+			Name:  "privatenet",
+			Usage: "Private network configuration",
+			Subcommands: []cli.Command{
+				{
+					Name:  "init",
+					Usage: "Create the private network config with the new swarm key",
+					Flags: []cli.Flag{
+						config.PrivateNetworkIdFlag,
+						config.PrivateNetworkGodFlag,
+						config.PrivateNetworkOutFlag,
+					},
+					Action: initPrivateNetworkCommand,
+				},
+				{
+					Name:      "check",
+					Usage:     "Validate the private network config",
+					ArgsUsage: "<file>",
+					Action:    checkPrivateNetworkCommand,
+				},
+			},
+		},
+		{
+			Name:  "backup",
+			Usage: "Node key and ceremony state backups",
+			Subcommands: []cli.Command{
+				{
+					Name:      "restore",
+					Usage:     "Restore the backup of a stopped node, the latest backup of configured stores is used if file isn't set",
+					ArgsUsage: "[file]",
+					Flags: []cli.Flag{
+						config.CfgFileFlag,
+						config.DataDirFlag,
+						config.BackupDirFlag,
+						config.BackupPasswordFileFlag,
+						config.BackupForceFlag,
+					},
+					Action: restoreBackupCommand,
+				},
+			},
+		},
+		{
+			Name:      "export",
+			Usage:     "Export canonical blocks with certificates of a stopped node",
+			ArgsUsage: "<file>",
+			Flags:     []cli.Flag{config.CfgFileFlag, config.DataDirFlag, config.ExportFromFlag, config.ExportToFlag},
+			Action:    exportChainCommand,
+		},
+		{
+			Name:      "import",
+			Usage:     "Import blocks exported by 'export' to a stopped node",
+			ArgsUsage: "<file>",
+			Flags:     []cli.Flag{config.CfgFileFlag, config.DataDirFlag},
+			Action:    importChainCommand,
+		},
+		{
+			Name:      "simulate",
+			Usage:     "Run the consensus scenario on simulated nodes with the virtual clock, fails if expectations aren't met",
+			ArgsUsage: "<scenario file>",
+			Flags:     []cli.Flag{config.SimulateSeedFlag, config.SimulateOutFlag},
+			Action:    simulateCommand,
+		},
 	}
 
 	app.Action = func(context *cli.Context) error {
@@ -80,7 +265,12 @@ func main() {
 			return err
 		}
 
-		log.Root().SetHandler(log.LvlFilterHandler(logLvl, log.MultiHandler(handler, fileHandler)))
+		logHandler := log.NewGlogHandler(log.MultiHandler(log.StreamHandler(os.Stdout, log.TerminalFormat(useLogColor)), fileHandler))
+		logHandler.Verbosity(log.Lvl(cfg.Log.Verbosity))
+		if err := logHandler.Vmodule(cfg.Log.Vmodule); err != nil {
+			return err
+		}
+		log.Root().SetHandler(logHandler)
 
 		log.Info("Idena node is starting", "version", version)
 
@@ -88,7 +278,13 @@ func main() {
 		if err != nil {
 			return err
 		}
+		n.ProvideConfigReload(func() (*config.Config, error) {
+			return config.MakeConfig(context)
+		})
+		n.ProvideLogging(logHandler, fileHandler.Rotate)
 		n.Start()
+		go handleInterrupt(n)
+		go handleReload(n)
 		n.WaitForStop()
 		return nil
 	}
@@ -99,7 +295,204 @@ func main() {
 	}
 }
 
-func getLogFileHandler(cfg *config.Config, logFileSize int) (log.Handler, error) {
+func handleInterrupt(n *node.Node) {
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+	<-sigc
+	log.Info("Got interrupt, shutting down...")
+	go n.Stop()
+	<-sigc
+	log.Warn("Forced shutdown")
+	os.Exit(1)
+}
+
+func handleReload(n *node.Node) {
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGHUP)
+	for range sigc {
+		log.Info("Got SIGHUP, reloading config...")
+		if _, err := n.ReloadConfig(); err != nil {
+			log.Error("Failed to reload config", "err", err)
+		}
+	}
+}
+
+func dbCommand(repair bool) cli.ActionFunc {
+	return func(context *cli.Context) error {
+		log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stdout, log.TerminalFormat(runtime.GOOS != "windows"))))
+		cfg, err := config.MakeConfig(context)
+		if err != nil {
+			return err
+		}
+		report, err := node.VerifyDatabase(cfg, context.Bool(config.DbCheckBodiesFlag.Name), repair)
+		if err != nil {
+			return err
+		}
+		log.Info("Database verified", "head", report.Head, "lowest", report.Lowest, "blocks", report.CheckedBlocks,
+			"txs", report.CheckedTxs, "issues", len(report.Issues))
+		if !report.Ok() && !repair {
+			return errors.New("database is corrupted, run 'db repair' to fix indexes")
+		}
+		return nil
+	}
+}
+
+func signTxCommand(context *cli.Context) error {
+	keyFile := context.String(config.TxKeyFileFlag.Name)
+	if keyFile == "" {
+		return errors.New("key file is required")
+	}
+	key, err := offline.LoadKey(keyFile, context.String(config.TxKeyPasswordFlag.Name))
+	if err != nil {
+		return errors.Wrap(err, "cannot load key")
+	}
+	var input []byte
+	if file := context.Args().First(); file != "" {
+		input, err = ioutil.ReadFile(file)
+	} else {
+		input, err = ioutil.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		return err
+	}
+	tx, err := offline.ParseTx(input)
+	if err != nil {
+		return err
+	}
+	signed, err := offline.Sign(tx, key)
+	if err != nil {
+		return err
+	}
+	output, err := json.MarshalIndent(signed, "", "  ")
+	if err != nil {
+		return err
+	}
+	if out := context.String(config.TxOutFlag.Name); out != "" {
+		return ioutil.WriteFile(out, output, 0600)
+	}
+	_, err = fmt.Fprintln(os.Stdout, string(output))
+	return err
+}
+
+func initPrivateNetworkCommand(context *cli.Context) error {
+	god := context.String(config.PrivateNetworkGodFlag.Name)
+	if !common.IsHexAddress(god) {
+		return errors.New("valid god address is required")
+	}
+	cfg, err := config.NewPrivateNetworkConfig(uint32(context.Uint(config.PrivateNetworkIdFlag.Name)), common.HexToAddress(god))
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if out := context.String(config.PrivateNetworkOutFlag.Name); out != "" {
+		return cfg.Save(out)
+	}
+	output, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(os.Stdout, string(output))
+	return err
+}
+
+func checkPrivateNetworkCommand(context *cli.Context) error {
+	file := context.Args().First()
+	if file == "" {
+		return errors.New("config file is required")
+	}
+	cfg, err := config.LoadPrivateNetworkConfig(file)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(os.Stdout, "network: %v\ngod address: %v\nidentities: %v\ninvites: %v\nfirst ceremony: %v\n",
+		cfg.Network, cfg.GodAddress.Hex(), len(cfg.Identities), len(cfg.Invites), time.Unix(cfg.FirstCeremonyTime, 0).UTC())
+	return err
+}
+
+func restoreBackupCommand(context *cli.Context) error {
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stdout, log.TerminalFormat(runtime.GOOS != "windows"))))
+	cfg, err := config.MakeConfig(context)
+	if err != nil {
+		return err
+	}
+	key, err := node.RestoreBackup(cfg, context.Args().First(), context.Bool(config.BackupForceFlag.Name))
+	if err != nil {
+		return err
+	}
+	log.Info("Backup restored", "backup", key, "datadir", cfg.DataDir)
+	return nil
+}
+
+func exportChainCommand(context *cli.Context) error {
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stdout, log.TerminalFormat(runtime.GOOS != "windows"))))
+	file := context.Args().First()
+	if file == "" {
+		return errors.New("file is required")
+	}
+	cfg, err := config.MakeConfig(context)
+	if err != nil {
+		return err
+	}
+	count, err := node.ExportChain(cfg, context.Uint64(config.ExportFromFlag.Name), context.Uint64(config.ExportToFlag.Name), file)
+	if err != nil {
+		return err
+	}
+	log.Info("Chain exported", "blocks", count, "file", file)
+	return nil
+}
+
+func importChainCommand(context *cli.Context) error {
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stdout, log.TerminalFormat(runtime.GOOS != "windows"))))
+	file := context.Args().First()
+	if file == "" {
+		return errors.New("file is required")
+	}
+	cfg, err := config.MakeConfig(context)
+	if err != nil {
+		return err
+	}
+	report, err := node.ImportChain(cfg, file)
+	if report != nil {
+		log.Info("Chain imported", "restored", report.Restored, "applied", report.Applied, "head", report.Head)
+	}
+	return err
+}
+
+func simulateCommand(context *cli.Context) error {
+	file := context.Args().First()
+	if file == "" {
+		return errors.New("scenario file is required")
+	}
+	scenario, err := sim.LoadScenario(file)
+	if err != nil {
+		return err
+	}
+	if context.IsSet(config.SimulateSeedFlag.Name) {
+		scenario.Seed = context.Int64(config.SimulateSeedFlag.Name)
+	}
+	result, err := sim.Run(scenario)
+	if err != nil {
+		return err
+	}
+	output, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	if out := context.String(config.SimulateOutFlag.Name); out != "" {
+		err = ioutil.WriteFile(out, output, 0644)
+	} else {
+		_, err = fmt.Fprintln(os.Stdout, string(output))
+	}
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(result.Check(scenario.Expect), "scenario expectations aren't met")
+}
+
+func getLogFileHandler(cfg *config.Config, logFileSize int) (*log.RotatingHandler, error) {
 	path := filepath.Join(cfg.DataDir, LogDir)
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		if err := os.MkdirAll(path, 0755); err != nil {
@@ -107,9 +500,7 @@ func getLogFileHandler(cfg *config.Config, logFileSize int) (log.Handler, error)
 		}
 	}
 
-	fileHandler, _ := log.RotatingFileHandler(filepath.Join(path, "output.log"), uint(logFileSize*1024), log.TerminalFormat(false))
-
-	return fileHandler, nil
+	return log.NewRotatingFileHandler(filepath.Join(path, "output.log"), uint(logFileSize*1024), log.TerminalFormat(false))
 }
 
 func dropOldDirOnFork(cfg *config.Config) error {
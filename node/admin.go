@@ -0,0 +1,118 @@
+package node
+
+import (
+	"github.com/idena-network/idena-go/database"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"github.com/tendermint/tm-db"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Compact starts the compaction of the whole database in background
+func (node *Node) Compact() error {
+	levelDb, ok := node.db.(*db.GoLevelDB)
+	if !ok {
+		return errors.New("database compaction is not supported")
+	}
+	if !atomic.CompareAndSwapInt32(&node.compacting, 0, 1) {
+		return errors.New("database compaction is already in progress")
+	}
+	go func() {
+		defer atomic.StoreInt32(&node.compacting, 0)
+		start := time.Now()
+		node.log.Info("Database compaction started")
+		if err := levelDb.DB().CompactRange(util.Range{}); err != nil {
+			node.log.Error("Database compaction failed", "err", err)
+			return
+		}
+		node.log.Info("Database compaction completed", "duration", time.Since(start))
+	}()
+	return nil
+}
+
+func (node *Node) Compacting() bool {
+	return atomic.LoadInt32(&node.compacting) == 1
+}
+
+// RotateLog moves the current log file aside and starts the new one
+func (node *Node) RotateLog() error {
+	if node.rotateLog == nil {
+		return errors.New("log file is not used")
+	}
+	return node.rotateLog()
+}
+
+// RotateApiKey replaces the RPC API key without restart and returns the new key, the key is read from the secret
+// source or generated if key isn't set
+func (node *Node) RotateApiKey(key string) (string, error) {
+	node.reloadMutex.Lock()
+	defer node.reloadMutex.Unlock()
+	return node.rotateApiKey(key)
+}
+
+func (node *Node) rotateApiKey(key string) (string, error) {
+	key, err := node.config.RotateApiKey(key)
+	if err != nil {
+		return "", err
+	}
+	if node.httpHandler != nil {
+		node.httpHandler.SetApiKey(key)
+	}
+	node.log.Info("RPC API key rotated")
+	return key, nil
+}
+
+// Shutdown stops the node in background, the process exits when the node is stopped
+func (node *Node) Shutdown() {
+	node.log.Info("Shutdown requested")
+	go node.Stop()
+}
+
+// AddPriorityPeer adds the static or trusted peer, the peer is kept across restarts
+func (node *Node) AddPriorityPeer(url string, static, trusted bool) error {
+	if err := node.pm.AddPriorityPeer(url, static, trusted); err != nil {
+		return err
+	}
+	node.reloadMutex.Lock()
+	defer node.reloadMutex.Unlock()
+	repo := database.NewRepo(node.db)
+	peers := repo.ReadPriorityPeers()
+	for i := range peers {
+		if peers[i].Addr == url {
+			peers[i].Static = peers[i].Static || static
+			peers[i].Trusted = peers[i].Trusted || trusted
+			repo.WritePriorityPeers(peers)
+			return nil
+		}
+	}
+	repo.WritePriorityPeers(append(peers, database.PriorityPeer{Addr: url, Static: static, Trusted: trusted}))
+	return nil
+}
+
+// RemovePriorityPeer removes the static or trusted peer by its id
+func (node *Node) RemovePriorityPeer(id string) error {
+	if err := node.pm.RemovePriorityPeer(id); err != nil {
+		return err
+	}
+	node.reloadMutex.Lock()
+	defer node.reloadMutex.Unlock()
+	repo := database.NewRepo(node.db)
+	var kept []database.PriorityPeer
+	for _, p := range repo.ReadPriorityPeers() {
+		if !strings.HasSuffix(p.Addr, "/"+id) {
+			kept = append(kept, p)
+		}
+	}
+	repo.WritePriorityPeers(kept)
+	return nil
+}
+
+func (node *Node) restorePriorityPeers() {
+	for _, p := range database.NewRepo(node.db).ReadPriorityPeers() {
+		if err := node.pm.AddPriorityPeer(p.Addr, p.Static, p.Trusted); err != nil {
+			node.log.Warn("Failed to restore priority peer", "addr", p.Addr, "err", err)
+		}
+	}
+}
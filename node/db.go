@@ -0,0 +1,149 @@
+package node
+
+import (
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/ancient"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/backup"
+	"github.com/idena-network/idena-go/ipfs"
+	"github.com/idena-network/idena-go/log"
+	"github.com/idena-network/idena-go/secstore"
+	"io/ioutil"
+	"os"
+)
+
+// VerifyDatabase checks the chain database of a stopped node and repairs found issues if repair is set.
+// Block bodies and tx indexes are checked only with checkBodies, it requires starting ipfs.
+func VerifyDatabase(cfg *config.Config, checkBodies bool, repair bool) (*blockchain.IntegrityReport, error) {
+	db, err := OpenDatabase(cfg.DataDir, "idenachain", 16, 16)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	bus := eventbus.New()
+	var ipfsProxy ipfs.Proxy
+	if checkBodies {
+		if ipfsProxy, err = ipfs.NewIpfsProxy(cfg.IpfsConf, bus, nil); err != nil {
+			return nil, err
+		}
+	}
+	appState := appstate.NewAppState(db, bus)
+	chain := blockchain.NewBlockchain(cfg, db, nil, appState, ipfsProxy, secstore.NewSecStore(), bus, nil)
+
+	report, err := chain.VerifyIntegrity(checkBodies)
+	if err != nil {
+		return nil, err
+	}
+	for _, issue := range report.Issues {
+		log.Warn("Database issue", "issue", issue.String())
+	}
+	if !repair || report.Ok() {
+		return report, nil
+	}
+	repaired, err := chain.RepairIntegrity(report)
+	if err != nil {
+		return report, err
+	}
+	log.Info("Database repaired", "repaired", repaired, "total", len(report.Issues))
+	return report, nil
+}
+
+// RestoreBackup restores the backup file of a stopped node, the latest backup of configured stores is used if file
+// isn't set. It returns the restored backup key
+func RestoreBackup(cfg *config.Config, file string, force bool) (string, error) {
+	password, err := cfg.Backup.Password()
+	if err != nil {
+		return "", err
+	}
+	key := file
+	var data []byte
+	if file != "" {
+		data, err = ioutil.ReadFile(file)
+	} else {
+		stores, storesErr := cfg.Backup.Stores(cfg.DataDir)
+		if storesErr != nil {
+			return "", storesErr
+		}
+		key, data, err = backup.Latest(stores)
+	}
+	if err != nil {
+		return "", err
+	}
+	archive, err := backup.Decrypt(data, password)
+	if err != nil {
+		return "", err
+	}
+	db, err := OpenDatabase(cfg.DataDir, "idenachain", 16, 16)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+	return key, backup.Restore(cfg, db, archive, password, force)
+}
+
+// openChain opens the chain of a stopped node with ipfs started to read and write block bodies
+func openChain(cfg *config.Config) (*blockchain.Blockchain, func(), error) {
+	db, err := OpenDatabase(cfg.DataDir, "idenachain", 16, 16)
+	if err != nil {
+		return nil, nil, err
+	}
+	bus := eventbus.New()
+	ipfsProxy, err := ipfs.NewIpfsProxy(cfg.IpfsConf, bus, nil)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	appState := appstate.NewAppState(db, bus)
+	chain := blockchain.NewBlockchain(cfg, db, nil, appState, ipfsProxy, secstore.NewSecStore(), bus, nil)
+	if err := chain.InitializeChain(); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	if err := appState.Initialize(chain.Head.Height()); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	archiver, err := ancient.NewArchiver(cfg, db, chain, ipfsProxy)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	chain.ProvideAncientStore(archiver)
+	return chain, func() { db.Close() }, nil
+}
+
+// ExportChain writes canonical blocks of a stopped node to the file, to is the head height if it's 0
+func ExportChain(cfg *config.Config, from, to uint64, file string) (uint64, error) {
+	chain, closeChain, err := openChain(cfg)
+	if err != nil {
+		return 0, err
+	}
+	defer closeChain()
+	f, err := os.Create(file)
+	if err != nil {
+		return 0, err
+	}
+	count, err := chain.ExportChain(f, from, to)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return count, err
+}
+
+// ImportChain restores and applies blocks of the export file to the chain of a stopped node
+func ImportChain(cfg *config.Config, file string) (*blockchain.ImportReport, error) {
+	chain, closeChain, err := openChain(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer closeChain()
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return chain.ImportChain(f)
+}
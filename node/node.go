@@ -3,20 +3,44 @@ package node
 import (
 	"bytes"
 	"crypto/ecdsa"
+	"crypto/tls"
 	"fmt"
 	"github.com/idena-network/idena-go/api"
 	"github.com/idena-network/idena-go/blockchain"
 	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/bandwidth"
 	"github.com/idena-network/idena-go/common/eventbus"
 	util "github.com/idena-network/idena-go/common/ulimit"
 	"github.com/idena-network/idena-go/config"
 	"github.com/idena-network/idena-go/consensus"
+	"github.com/idena-network/idena-go/core/addressbook"
+	"github.com/idena-network/idena-go/core/ancient"
 	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/backpressure"
+	"github.com/idena-network/idena-go/core/backup"
 	"github.com/idena-network/idena-go/core/ceremony"
+	"github.com/idena-network/idena-go/core/epochreport"
 	"github.com/idena-network/idena-go/core/flip"
+	"github.com/idena-network/idena-go/core/hardfork"
+	"github.com/idena-network/idena-go/core/health"
+	"github.com/idena-network/idena-go/core/identity"
+	"github.com/idena-network/idena-go/core/identityproof"
+	"github.com/idena-network/idena-go/core/invites"
+	"github.com/idena-network/idena-go/core/ipfsgc"
 	"github.com/idena-network/idena-go/core/mempool"
+	"github.com/idena-network/idena-go/core/mirror"
+	"github.com/idena-network/idena-go/core/online"
+	"github.com/idena-network/idena-go/core/penalty"
+	"github.com/idena-network/idena-go/core/pool"
+	"github.com/idena-network/idena-go/core/privatenet"
 	"github.com/idena-network/idena-go/core/profile"
+	"github.com/idena-network/idena-go/core/relay"
+	"github.com/idena-network/idena-go/core/signstore"
+	"github.com/idena-network/idena-go/core/standby"
 	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/core/storage"
+	"github.com/idena-network/idena-go/core/supply"
+	"github.com/idena-network/idena-go/core/watchlist"
 	"github.com/idena-network/idena-go/crypto"
 	"github.com/idena-network/idena-go/ipfs"
 	"github.com/idena-network/idena-go/keystore"
@@ -29,10 +53,12 @@ import (
 	"github.com/idena-network/idena-go/stats/collector"
 	"github.com/pkg/errors"
 	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/syndtr/goleveldb/leveldb/filter"
 	"github.com/syndtr/goleveldb/leveldb/opt"
@@ -40,32 +66,71 @@ import (
 )
 
 type Node struct {
-	config          *config.Config
-	blockchain      *blockchain.Blockchain
-	appState        *appstate.AppState
-	secStore        *secstore.SecStore
-	pm              *protocol.IdenaGossipHandler
-	stop            chan struct{}
-	proposals       *pengings.Proposals
-	votes           *pengings.Votes
-	consensusEngine *consensus.Engine
-	txpool          *mempool.TxPool
-	flipKeyPool     *mempool.KeysPool
-	rpcAPIs         []rpc.API
-	httpListener    net.Listener // HTTP RPC listener socket to server API requests
-	httpHandler     *rpc.Server  // HTTP RPC request handler to process the API requests
-	log             log.Logger
-	keyStore        *keystore.KeyStore
-	fp              *flip.Flipper
-	ipfsProxy       ipfs.Proxy
-	bus             eventbus.Bus
-	ceremony        *ceremony.ValidationCeremony
-	downloader      *protocol.Downloader
-	offlineDetector *blockchain.OfflineDetector
-	appVersion      string
-	profileManager  *profile.Manager
+	config            *config.Config
+	blockchain        *blockchain.Blockchain
+	appState          *appstate.AppState
+	secStore          *secstore.SecStore
+	pm                *protocol.IdenaGossipHandler
+	stop              chan struct{}
+	proposals         *pengings.Proposals
+	votes             *pengings.Votes
+	consensusEngine   *consensus.Engine
+	txpool            *mempool.TxPool
+	flipKeyPool       *mempool.KeysPool
+	rpcAPIs           []rpc.API
+	httpListener      net.Listener // HTTP RPC listener socket to server API requests
+	httpHandler       *rpc.Server  // HTTP RPC request handler to process the API requests
+	log               log.Logger
+	keyStore          *keystore.KeyStore
+	fp                *flip.Flipper
+	flipSubmissions   *flip.SubmissionManager
+	ipfsProxy         ipfs.Proxy
+	bus               eventbus.Bus
+	ceremony          *ceremony.ValidationCeremony
+	downloader        *protocol.Downloader
+	offlineDetector   *blockchain.OfflineDetector
+	appVersion        string
+	profileManager    *profile.Manager
+	timeSync          *protocol.TimeSync
+	ceremonySimulator *ceremony.Simulator
+	ipfsGc            *ipfsgc.GarbageCollector
+	storage           *storage.Manager
+	pool              *pool.Manager
+	onlineKeeper      *online.StatusKeeper
+	standby           *standby.Guard
+	signStore         *signstore.Store
+	identityWatcher   *identity.Watcher
+	phaseTracker      *ceremony.PhaseTracker
+	epochReports      *epochreport.Builder
+	identityProofs    *identityproof.Store
+	supply            *supply.Tracker
+	penaltyMonitor    *penalty.Monitor
+	hardForks         *hardfork.Rules
+	upgradeVotes      *hardfork.VoteTracker
+	invites           *invites.Manager
+	privateNetwork    *privatenet.Coordinator
+	backups           *backup.Manager
+	ancient           *ancient.Archiver
+	health            *health.Checker
+	backpressure      *backpressure.Monitor
+	snapshots         *state.SnapshotManager
+	watchList         *watchlist.Manager
+	stopOnce          sync.Once
+	rpcAccess         *rpc.AccessPolicy
+	loadConfig        func() (*config.Config, error)
+	logHandler        *log.GlogHandler
+	rotateLog         func() error
+	reloadMutex       sync.Mutex
+	db                db.DB
+	compacting        int32
+	bandwidth         *bandwidth.Scheduler
+	mirror            *mirror.Server
+	relay             *relay.Relay
+	addressBook       *addressbook.Book
 }
 
+const ShutdownTimeout = time.Minute
+
 type NodeCtx struct {
 	Node            *Node
 	AppState        *appstate.AppState
@@ -122,6 +187,10 @@ func NewNode(config *config.Config, appVersion string) (*Node, error) {
 
 func NewNodeWithInjections(config *config.Config, bus eventbus.Bus, statsCollector collector.StatsCollector, appVersion string) (*NodeCtx, error) {
 
+	if !mempool.HasTxOrdering(config.Mempool.TxOrdering) {
+		return nil, errors.Errorf("unknown tx ordering %v", config.Mempool.TxOrdering)
+	}
+
 	db, err := OpenDatabase(config.DataDir, "idenachain", 16, 16)
 
 	if err != nil {
@@ -133,12 +202,12 @@ func NewNodeWithInjections(config *config.Config, bus eventbus.Bus, statsCollect
 		return nil, err
 	}
 
-	err = config.SetApiKey()
-	if err != nil {
-		return nil, errors.Wrap(err, "cannot set API key")
+	if err := config.LoadSecrets(); err != nil {
+		return nil, err
 	}
 
-	ipfsProxy, err := ipfs.NewIpfsProxy(config.IpfsConf, bus)
+	bandwidthScheduler := bandwidth.NewScheduler(config.Bandwidth)
+	ipfsProxy, err := ipfs.NewIpfsProxy(config.IpfsConf, bus, bandwidthScheduler)
 	if err != nil {
 		return nil, err
 	}
@@ -155,40 +224,108 @@ func NewNodeWithInjections(config *config.Config, bus eventbus.Bus, statsCollect
 
 	chain := blockchain.NewBlockchain(config, db, txpool, appState, ipfsProxy, secStore, bus, offlineDetector)
 	proposals, proofsByRound, pendingProofs := pengings.NewProposals(chain, appState, offlineDetector)
-	flipper := flip.NewFlipper(db, ipfsProxy, flipKeyPool, txpool, secStore, appState, bus)
-	pm := protocol.NewIdenaGossipHandler(ipfsProxy.Host(), config.P2P, chain, proposals, votes, txpool, flipper, bus, flipKeyPool, appVersion)
-	sm := state.NewSnapshotManager(db, appState.State, bus, ipfsProxy, config)
-	downloader := protocol.NewDownloader(pm, config, chain, ipfsProxy, appState, sm, bus, secStore, statsCollector)
+	flipper := flip.NewFlipper(db, ipfsProxy, flipKeyPool, txpool, secStore, appState, bus, config.FlipPrefetch)
+	timeSync := protocol.NewTimeSync(config.TimeSync)
+	pm := protocol.NewIdenaGossipHandler(ipfsProxy.Host(), config.P2P, chain, proposals, votes, txpool, flipper, bus, flipKeyPool, timeSync, appVersion, bandwidthScheduler)
+	var mirrorClient *mirror.Client
+	if config.Sync.HTTPMirror != "" {
+		mirrorClient = mirror.NewClient(config.Sync.HTTPMirror, ipfsProxy)
+	}
+	sm := state.NewSnapshotManager(db, appState.State, bus, ipfsProxy, config, mirrorClient)
+	epochReports := epochreport.NewBuilder(config.EpochReport, db, appState, secStore, bus)
+	statsCollector = epochReports.Wrap(statsCollector)
+	supplyTracker := supply.NewTracker(db, appState, bus)
+	statsCollector = supplyTracker.Wrap(statsCollector)
+	downloader := protocol.NewDownloader(pm, config, chain, ipfsProxy, appState, sm, bus, secStore, statsCollector, mirrorClient)
+	standbyGuard := standby.NewGuard(config.Consensus.Standby, db)
+	signStore := signstore.NewStore(db)
 	consensusEngine := consensus.NewEngine(chain, pm, proposals, config.Consensus, appState, votes, txpool, secStore,
-		downloader, offlineDetector, statsCollector)
-	ceremony := ceremony.NewValidationCeremony(appState, bus, flipper, secStore, db, txpool, chain, downloader, flipKeyPool, config)
-	profileManager := profile.NewProfileManager(ipfsProxy)
+		downloader, offlineDetector, statsCollector, standbyGuard, signStore, consensus.NewForkMonitor(config.ForkMonitor))
+	validationCeremony := ceremony.NewValidationCeremony(appState, bus, flipper, secStore, db, txpool, chain, downloader, pm, flipKeyPool, timeSync, standbyGuard, config)
+	profileManager := profile.NewProfileManager(ipfsProxy, bus, secStore)
+	flipSubmissions := flip.NewSubmissionManager(config.FlipSubmission, flipper, bus)
+	ceremonySimulator := ceremony.NewSimulator(validationCeremony, ipfsProxy, timeSync)
+	ipfsGc := ipfsgc.NewGarbageCollector(config.IpfsGc, db, appState, ipfsProxy, bus)
+	poolManager := pool.NewManager(db, appState, bus)
+	addressBook := addressbook.NewBook(db, appState, secStore, poolManager)
+	identityWatcher := identity.NewWatcher(config.IdentityEvents, appState, bus)
+	phaseTracker := ceremony.NewPhaseTracker(appState, bus)
+	watchList := watchlist.NewManager(config.Watch, db, appState, bus, secStore)
+	penaltyMonitor := penalty.NewMonitor(config.PenaltyMonitor, appState, secStore, bus, validationCeremony, epochReports,
+		func() bool { return consensusEngine.Synced() && pm.HasPeers() })
+	hardForks := hardfork.NewRules(config.HardForks, appVersion)
+	upgradeVotes := hardfork.NewVoteTracker(config.HardForks, chain, pm.PeerVersions)
+	invitesManager := invites.NewManager(config.Invites, db, appState, secStore, bus)
+	privateNetwork := privatenet.NewCoordinator(config.PrivateNetwork, appState, txpool, secStore, invitesManager, bus)
+	backups := backup.NewManager(config, db, appState, secStore, bus)
+	archiver, err := ancient.NewArchiver(config, db, chain, ipfsProxy)
+	if err != nil {
+		return nil, err
+	}
+	chain.ProvideAncientStore(archiver)
+	relayer, err := relay.NewRelay(config.Relay)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid relay config")
+	}
+	healthChecker := health.NewChecker(config.Health, chain, appState, secStore, ipfsProxy, downloader, pm.PeersCount)
+	onlineKeeper := online.NewStatusKeeper(config.OnlineKeeper, db, appState, txpool, secStore, bus, downloader.IsSyncing, standbyGuard)
 	node := &Node{
-		config:          config,
-		blockchain:      chain,
-		pm:              pm,
-		proposals:       proposals,
-		appState:        appState,
-		consensusEngine: consensusEngine,
-		txpool:          txpool,
-		log:             log.New(),
-		keyStore:        keyStore,
-		fp:              flipper,
-		ipfsProxy:       ipfsProxy,
-		secStore:        secStore,
-		bus:             bus,
-		flipKeyPool:     flipKeyPool,
-		ceremony:        ceremony,
-		downloader:      downloader,
-		offlineDetector: offlineDetector,
-		votes:           votes,
-		appVersion:      appVersion,
-		profileManager:  profileManager,
+		config:            config,
+		db:                db,
+		bandwidth:         bandwidthScheduler,
+		mirror:            mirror.NewServer(db, ipfsProxy),
+		relay:             relayer,
+		addressBook:       addressBook,
+		blockchain:        chain,
+		pm:                pm,
+		proposals:         proposals,
+		appState:          appState,
+		consensusEngine:   consensusEngine,
+		txpool:            txpool,
+		log:               log.New(),
+		keyStore:          keyStore,
+		fp:                flipper,
+		flipSubmissions:   flipSubmissions,
+		ipfsProxy:         ipfsProxy,
+		secStore:          secStore,
+		bus:               bus,
+		flipKeyPool:       flipKeyPool,
+		ceremony:          validationCeremony,
+		downloader:        downloader,
+		offlineDetector:   offlineDetector,
+		votes:             votes,
+		appVersion:        appVersion,
+		profileManager:    profileManager,
+		timeSync:          timeSync,
+		ceremonySimulator: ceremonySimulator,
+		ipfsGc:            ipfsGc,
+		pool:              poolManager,
+		onlineKeeper:      onlineKeeper,
+		standby:           standbyGuard,
+		signStore:         signStore,
+		identityWatcher:   identityWatcher,
+		phaseTracker:      phaseTracker,
+		epochReports:      epochReports,
+		identityProofs:    identityproof.NewStore(config.IdentityProof, db, appState, bus),
+		supply:            supplyTracker,
+		penaltyMonitor:    penaltyMonitor,
+		hardForks:         hardForks,
+		upgradeVotes:      upgradeVotes,
+		invites:           invitesManager,
+		privateNetwork:    privateNetwork,
+		backups:           backups,
+		ancient:           archiver,
+		health:            healthChecker,
+		backpressure:      backpressure.NewMonitor(config.Backpressure, txpool, downloader),
+		snapshots:         sm,
+		watchList:         watchList,
+		stop:              make(chan struct{}),
 	}
+	node.storage = storage.NewManager(config, db, appState, ipfsGc, bus, node.Compact)
 	return &NodeCtx{
 		Node:            node,
 		AppState:        appState,
-		Ceremony:        ceremony,
+		Ceremony:        validationCeremony,
 		Blockchain:      chain,
 		Flipper:         flipper,
 		KeysPool:        flipKeyPool,
@@ -204,6 +341,10 @@ func (node *Node) Start() {
 
 func (node *Node) StartWithHeight(height uint64) {
 	node.secStore.AddKey(crypto.FromECDSA(node.config.NodeKey()))
+	if node.config.Dev.Enabled {
+		node.config.SetDevCoinbase(node.secStore.GetAddress())
+		node.log.Info("Dev mode is enabled", "blockTime", node.config.Dev.BlockTime, "epoch", node.config.Dev.EpochDuration)
+	}
 
 	if changed, value, err := util.ManageFdLimit(); changed {
 		node.log.Info("Set new fd limit", "value", value)
@@ -222,7 +363,9 @@ func (node *Node) StartWithHeight(height uint64) {
 		}
 	}
 
-	if err := node.blockchain.EnsureIntegrity(); err != nil {
+	if node.blockchain.ConsumeCleanShutdownMarker() {
+		node.log.Info("Node was stopped gracefully, skipping recovery checks")
+	} else if err := node.blockchain.EnsureIntegrity(); err != nil {
 		node.log.Error("Failed to recover blockchain", "err", err)
 		return
 	}
@@ -238,16 +381,82 @@ func (node *Node) StartWithHeight(height uint64) {
 	node.flipKeyPool.Initialize(node.blockchain.Head)
 	node.votes.Initialize(node.blockchain.Head)
 	node.fp.Initialize()
+	node.flipSubmissions.Start()
 	node.ceremony.Initialize(node.blockchain.GetBlock(node.blockchain.Head.Hash()))
 	node.blockchain.ProvideApplyNewEpochFunc(node.ceremony.ApplyNewEpoch)
+	node.timeSync.Start()
+	node.ipfsGc.Start()
+	node.storage.Start()
+	node.onlineKeeper.Start()
+	node.identityWatcher.Start()
+	node.phaseTracker.Start()
+	node.epochReports.Start()
+	node.identityProofs.Start()
+	node.supply.Start()
+	node.penaltyMonitor.Start()
+	node.hardForks.Start(node.bus, func() uint16 { return node.appState.State.Epoch() })
+	node.upgradeVotes.Start(node.bus, node.blockchain.Head.Height())
+	node.invites.Start()
+	node.privateNetwork.Start()
+	node.backups.Start()
+	node.ancient.Start()
+	node.watchList.Start()
 	node.offlineDetector.Start(node.blockchain.Head)
 	node.consensusEngine.Start()
 	node.pm.Start()
+	node.restorePriorityPeers()
 
 	// Configure RPC
 	if err := node.startRPC(); err != nil {
 		node.log.Error("Cannot start RPC endpoint", "error", err.Error())
 	}
+
+	if node.config.Mirror.Enabled {
+		if err := node.mirror.Start(node.config.Mirror.Endpoint()); err != nil {
+			node.log.Error("Cannot start HTTP mirror", "error", err.Error())
+		}
+	}
+
+	node.relay.Start()
+
+	if node.config.Validation.Simulate {
+		go func() {
+			if report, err := node.ceremonySimulator.Run(); err == nil && report.Failed() {
+				node.log.Warn("Ceremony simulation failed, the node may not pass the validation")
+			}
+		}()
+	}
+}
+
+// Stop stops RPC and consensus, waits for the current round and block import to finish and writes clean shutdown marker
+func (node *Node) Stop() {
+	node.stopOnce.Do(func() {
+		node.log.Info("Stopping node")
+		node.stopHTTP()
+		node.mirror.Stop()
+		node.relay.Stop()
+		// the marker is written only if every component has finished its writes, otherwise the next start recovers
+		graceful := true
+		if !node.downloader.Stop(ShutdownTimeout) {
+			node.log.Warn("Sync hasn't stopped in time")
+			graceful = false
+		}
+		if !node.consensusEngine.Stop(ShutdownTimeout) {
+			node.log.Warn("Consensus or fork resolver hasn't stopped in time")
+			graceful = false
+		}
+		if !node.flipSubmissions.Stop(ShutdownTimeout) {
+			node.log.Warn("Flip submission hasn't stopped in time")
+		}
+		node.txpool.Stop()
+		if graceful {
+			node.blockchain.WriteCleanShutdownMarker()
+			node.log.Info("Node is stopped gracefully", "head", node.blockchain.Head.Height())
+		} else {
+			node.log.Warn("Node hasn't stopped gracefully, recovery checks will be performed on next start")
+		}
+		close(node.stop)
+	})
 }
 
 func (node *Node) WaitForStop() {
@@ -262,26 +471,47 @@ func (node *Node) startRPC() error {
 	// Gather all the possible APIs to surface
 	apis := node.apis()
 
-	if err := node.startHTTP(node.config.RPC.HTTPEndpoint(), apis, node.config.RPC.HTTPModules, node.config.RPC.HTTPCors, node.config.RPC.HTTPVirtualHosts, node.config.RPC.HTTPTimeouts, node.config.RPC.APIKey); err != nil {
+	access, err := rpc.NewAccessPolicy(node.config.RPC)
+	if err != nil {
+		return err
+	}
+	var tlsConfig *tls.Config
+	if node.config.RPC.TLSCertFile != "" || node.config.RPC.TLSKeyFile != "" {
+		if tlsConfig, err = rpc.NewTLSConfig(node.config.RPC.TLSCertFile, node.config.RPC.TLSKeyFile); err != nil {
+			return errors.Wrap(err, "cannot load RPC TLS certificate")
+		}
+	}
+
+	if err := node.startHTTP(node.config.RPC.HTTPEndpoint(), apis, node.config.RPC.HTTPModules, node.config.RPC.HTTPCors, node.config.RPC.HTTPVirtualHosts, node.config.RPC.HTTPTimeouts, node.config.RPC.APIKey, access, tlsConfig); err != nil {
 		return err
 	}
 
 	node.rpcAPIs = apis
+	node.rpcAccess = access
 	return nil
 }
 
 // startHTTP initializes and starts the HTTP RPC endpoint.
-func (node *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors []string, vhosts []string, timeouts rpc.HTTPTimeouts, apiKey string) error {
+func (node *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors []string, vhosts []string, timeouts rpc.HTTPTimeouts, apiKey string, access *rpc.AccessPolicy, tlsConfig *tls.Config) error {
 	// Short circuit if the HTTP endpoint isn't being exposed
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, timeouts, apiKey)
+	routes := map[string]http.Handler{
+		"/health": node.health.HealthHandler(),
+		"/ready":  node.health.ReadyHandler(),
+	}
+	listener, handler, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, timeouts, apiKey, access, tlsConfig, node.config.RPC.WSPath, routes)
 	if err != nil {
 		return err
 	}
-	node.log.Info("HTTP endpoint opened", "url", fmt.Sprintf("http://%s", endpoint), "cors", strings.Join(cors, ","), "vhosts", strings.Join(vhosts, ","))
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+	node.log.Info("HTTP endpoint opened", "url", fmt.Sprintf("%s://%s", scheme, endpoint), "cors", strings.Join(cors, ","), "vhosts", strings.Join(vhosts, ","))
 
+	handler.SetSlowCallThreshold(node.config.RPC.SlowCallThreshold)
 	node.httpListener = listener
 	node.httpHandler = handler
 
@@ -316,17 +546,17 @@ func (node *Node) apis() []rpc.API {
 
 	baseApi := api.NewBaseApi(node.consensusEngine, node.txpool, node.keyStore, node.secStore)
 
-	return []rpc.API{
+	apis := []rpc.API{
 		{
 			Namespace: "net",
 			Version:   "1.0",
-			Service:   api.NewNetApi(node.pm, node.ipfsProxy),
+			Service:   api.NewNetApi(node.pm, node.ipfsProxy, node.bandwidth),
 			Public:    true,
 		},
 		{
 			Namespace: "dna",
 			Version:   "1.0",
-			Service:   api.NewDnaApi(baseApi, node.blockchain, node.ceremony, node.appVersion, node.profileManager),
+			Service:   api.NewDnaApi(baseApi, node.blockchain, node.ceremony, node.appVersion, node.profileManager, node.ceremonySimulator, node.onlineKeeper, node.standby, node.addressBook, node.identityProofs),
 			Public:    true,
 		},
 		{
@@ -338,16 +568,127 @@ func (node *Node) apis() []rpc.API {
 		{
 			Namespace: "flip",
 			Version:   "1.0",
-			Service:   api.NewFlipApi(baseApi, node.fp, node.ipfsProxy, node.ceremony),
+			Service:   api.NewFlipApi(baseApi, node.fp, node.ipfsProxy, node.ceremony, node.flipSubmissions),
 			Public:    true,
 		},
 		{
 			Namespace: "bcn",
 			Version:   "1.0",
-			Service:   api.NewBlockchainApi(baseApi, node.blockchain, node.ipfsProxy, node.txpool, node.downloader, node.pm),
+			Service:   api.NewBlockchainApi(baseApi, node.blockchain, node.ipfsProxy, node.txpool, node.downloader, node.pm, node.hardForks, node.upgradeVotes, node.ancient, node.supply, node.backpressure),
+			Public:    true,
+		},
+		{
+			Namespace: "ipfs",
+			Version:   "1.0",
+			Service:   api.NewIpfsApi(node.ipfsGc),
+			Public:    true,
+		},
+		{
+			Namespace: "pool",
+			Version:   "1.0",
+			Service:   api.NewPoolApi(baseApi, node.pool, node.addressBook),
 			Public:    true,
 		},
+		{
+			Namespace: "consensus",
+			Version:   "1.0",
+			Service:   api.NewConsensusApi(node.consensusEngine),
+			Public:    true,
+		},
+		{
+			Namespace: "dna",
+			Version:   "1.0",
+			Service:   api.NewIdentityEventsApi(node.identityWatcher, node.bus),
+			Public:    true,
+		},
+		{
+			Namespace: "dna",
+			Version:   "1.0",
+			Service:   api.NewCeremonyEventsApi(node.bus),
+			Public:    true,
+		},
+		{
+			Namespace: "dna",
+			Version:   "1.0",
+			Service:   api.NewEpochReportApi(baseApi, node.epochReports),
+			Public:    true,
+		},
+		{
+			Namespace: "dna",
+			Version:   "1.0",
+			Service:   api.NewPenaltyApi(node.penaltyMonitor),
+			Public:    true,
+		},
+		{
+			Namespace: "invite",
+			Version:   "1.0",
+			Service:   api.NewInviteApi(baseApi, node.invites),
+			Public:    true,
+		},
+		{
+			Namespace: "relay",
+			Version:   "1.0",
+			Service:   api.NewRelayApi(node.relay),
+			Public:    true,
+		},
+		{
+			Namespace: "addressbook",
+			Version:   "1.0",
+			Service:   api.NewAddressBookApi(node.addressBook),
+			Public:    true,
+		},
+		{
+			Namespace: "watch",
+			Version:   "1.0",
+			Service:   api.NewWatchApi(node.watchList, node.bus),
+			Public:    true,
+		},
+		{
+			Namespace: "admin",
+			Version:   "1.0",
+			Service:   api.NewAdminApi(node, node.consensusEngine, node.pm),
+			Public:    true,
+		},
+		{
+			Namespace: "debug",
+			Version:   "1.0",
+			Service:   api.NewDebugApi(node.appState, node.pm, node.txpool, node.blockchain),
+			Public:    true,
+		},
+		{
+			Namespace: "privatenet",
+			Version:   "1.0",
+			Service:   api.NewPrivateNetworkApi(node.privateNetwork),
+			Public:    true,
+		},
+		{
+			Namespace: "node",
+			Version:   "1.0",
+			Service:   api.NewNodeApi(node.health, node.storage, node.backpressure, node.snapshots),
+			Public:    true,
+		},
+		{
+			Namespace: "backup",
+			Version:   "1.0",
+			Service:   api.NewBackupApi(node.backups),
+			Public:    true,
+		},
+		{
+			Namespace: "unsafe",
+			Version:   "1.0",
+			Service:   api.NewUnsafeApi(node.signStore),
+			Public:    false,
+		},
+	}
+	if node.config.Dev.Enabled {
+		apis = append(apis, rpc.API{
+			Namespace: "dev",
+			Version:   "1.0",
+			Service:   api.NewDevApi(node.blockchain, baseApi, node.ceremony),
+			Public:    true,
+		})
 	}
+	return apis
 }
 
 func (node *Node) generateSyntheticP2PKey() *ecdsa.PrivateKey {
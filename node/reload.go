@@ -0,0 +1,147 @@
+package node
+
+import (
+	"encoding/json"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/log"
+	"github.com/pkg/errors"
+	"reflect"
+)
+
+const redacted = "<redacted>"
+
+// ProvideConfigReload enables config reload, load should build the config the same way it has been built at the start
+func (node *Node) ProvideConfigReload(load func() (*config.Config, error)) {
+	node.loadConfig = load
+}
+
+// ProvideLogging sets the root log handler levels of which are changed on config reload and the log file rotation
+func (node *Node) ProvideLogging(handler *log.GlogHandler, rotate func() error) {
+	node.logHandler = handler
+	node.rotateLog = rotate
+}
+
+// ReloadConfig loads the config and applies log levels, RPC rate limits, the external RPC API key, mempool limits,
+// peer limits, bandwidth caps and the watch list without restart, it returns names of changed settings. Other settings require restart
+func (node *Node) ReloadConfig() ([]string, error) {
+	if node.loadConfig == nil {
+		return nil, errors.New("config reload is not supported")
+	}
+	cfg, err := node.loadConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot load config")
+	}
+	node.reloadMutex.Lock()
+	defer node.reloadMutex.Unlock()
+	current := node.config
+	var changed []string
+
+	if node.logHandler != nil && !reflect.DeepEqual(current.Log, cfg.Log) {
+		if err := node.logHandler.Vmodule(cfg.Log.Vmodule); err != nil {
+			return changed, errors.Wrap(err, "invalid log vmodule")
+		}
+		node.logHandler.Verbosity(log.Lvl(cfg.Log.Verbosity))
+		*current.Log = *cfg.Log
+		changed = append(changed, "Log")
+	}
+
+	if !reflect.DeepEqual(current.RPC.RateLimit, cfg.RPC.RateLimit) {
+		if node.rpcAccess != nil {
+			node.rpcAccess.SetRateLimit(cfg.RPC.RateLimit)
+		}
+		current.RPC.RateLimit = cfg.RPC.RateLimit
+		changed = append(changed, "RPC.RateLimit")
+	}
+
+	if current.RPC.SlowCallThreshold != cfg.RPC.SlowCallThreshold {
+		if node.httpHandler != nil {
+			node.httpHandler.SetSlowCallThreshold(cfg.RPC.SlowCallThreshold)
+		}
+		current.RPC.SlowCallThreshold = cfg.RPC.SlowCallThreshold
+		changed = append(changed, "RPC.SlowCallThreshold")
+	}
+
+	if mempoolLimits(current.Mempool) != mempoolLimits(cfg.Mempool) ||
+		!reflect.DeepEqual(current.Mempool.Locals, cfg.Mempool.Locals) {
+		node.txpool.SetLimits(cfg.Mempool)
+		changed = append(changed, "Mempool")
+	}
+
+	if current.P2P.MaxInboundPeers != cfg.P2P.MaxInboundPeers || current.P2P.MaxOutboundPeers != cfg.P2P.MaxOutboundPeers {
+		node.pm.SetPeerLimits(cfg.P2P.MaxInboundPeers, cfg.P2P.MaxOutboundPeers)
+		current.P2P.MaxInboundPeers = cfg.P2P.MaxInboundPeers
+		current.P2P.MaxOutboundPeers = cfg.P2P.MaxOutboundPeers
+		changed = append(changed, "P2P")
+	}
+
+	if !reflect.DeepEqual(current.Bandwidth, cfg.Bandwidth) {
+		node.bandwidth.SetLimits(cfg.Bandwidth)
+		*current.Bandwidth = *cfg.Bandwidth
+		changed = append(changed, "Bandwidth")
+	}
+
+	// the mounted secret file may be replaced without changing the config
+	if key, ok, err := cfg.ExternalApiKey(); err != nil {
+		return changed, err
+	} else if ok && key != current.RPC.APIKey {
+		*current.Secrets = *cfg.Secrets
+		if _, err := node.rotateApiKey(key); err != nil {
+			return changed, err
+		}
+		changed = append(changed, "RPC.APIKey")
+	}
+
+	added := false
+	for _, addr := range cfg.Watch.Addresses {
+		added = node.watchList.Add(addr) || added
+	}
+	if added {
+		current.Watch.Addresses = cfg.Watch.Addresses
+		changed = append(changed, "Watch")
+	}
+
+	node.log.Info("Config reloaded", "changed", changed)
+	return changed, nil
+}
+
+func mempoolLimits(cfg *config.Mempool) [5]int {
+	return [5]int{cfg.TxPoolQueueSlots, cfg.TxPoolExecutableSlots, cfg.TxPoolMaxSize, cfg.TxPoolMaxBytes, cfg.TxMaxPayloadSize}
+}
+
+// EffectiveConfig returns the copy of the config the node is running with, secrets are redacted
+func (node *Node) EffectiveConfig() (*config.Config, error) {
+	node.reloadMutex.Lock()
+	data, err := json.Marshal(node.config)
+	node.reloadMutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	result := new(config.Config)
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, err
+	}
+	if result.RPC != nil {
+		if result.RPC.APIKey != "" {
+			result.RPC.APIKey = redacted
+		}
+		if result.RPC.JWTSecret != "" {
+			result.RPC.JWTSecret = redacted
+		}
+	}
+	if result.IdentityEvents != nil && result.IdentityEvents.WebhookSecret != "" {
+		result.IdentityEvents.WebhookSecret = redacted
+	}
+	if result.Backup != nil {
+		redactObjectStore(result.Backup.S3)
+	}
+	if result.Ancient != nil {
+		redactObjectStore(result.Ancient.S3)
+	}
+	return result, nil
+}
+
+func redactObjectStore(cfg *config.ObjectStoreConfig) {
+	if cfg != nil && cfg.SecretKey != "" {
+		cfg.SecretKey = redacted
+	}
+}
@@ -135,6 +135,30 @@ func (proposals *Proposals) GetProposerPubKey(round uint64) []byte {
 	return nil
 }
 
+// GetProofs returns valid proposer proofs of the round
+func (proposals *Proposals) GetProofs(round uint64) []*Proof {
+	var result []*Proof
+	if m, ok := proposals.proofsByRound.Load(round); ok {
+		m.(*sync.Map).Range(func(key, value interface{}) bool {
+			result = append(result, value.(*Proof))
+			return true
+		})
+	}
+	return result
+}
+
+// GetBlockHashes returns hashes of blocks proposed at the round
+func (proposals *Proposals) GetBlockHashes(round uint64) []common.Hash {
+	var result []common.Hash
+	if m, ok := proposals.blocksByRound.Load(round); ok {
+		m.(*sync.Map).Range(func(key, value interface{}) bool {
+			result = append(result, key.(common.Hash))
+			return true
+		})
+	}
+	return result
+}
+
 func (proposals *Proposals) CompleteRound(height uint64) {
 	proposals.proofsByRound.Range(func(key, value interface{}) bool {
 		if key.(uint64) <= height {
@@ -154,6 +178,7 @@ func (proposals *Proposals) CompleteRound(height uint64) {
 func (proposals *Proposals) ProcessPendingProofs() []*Proof {
 	var result []*Proof
 
+	proposals.preverifyPendingProofs()
 	proposals.pendingProofs.Range(func(key, value interface{}) bool {
 		proof := value.(*Proof)
 		if added, pending := proposals.AddProposeProof(proof.Proof, proof.Hash, proof.PubKey, proof.Round); added {
@@ -168,9 +193,39 @@ func (proposals *Proposals) ProcessPendingProofs() []*Proof {
 	return result
 }
 
+// preverifyPendingProofs validates deferred proofs of the current round in a batch, invalid proofs are dropped and
+// valid ones are added with cached VRF results
+func (proposals *Proposals) preverifyPendingProofs() {
+	currentRound := proposals.chain.Round()
+	var keys []interface{}
+	var proofs []blockchain.ProposerProof
+	proposals.pendingProofs.Range(func(key, value interface{}) bool {
+		if proof := value.(*Proof); proof.Round == currentRound {
+			keys = append(keys, key)
+			proofs = append(proofs, blockchain.ProposerProof{Proof: proof.Proof, Hash: proof.Hash, PubKey: proof.PubKey})
+		}
+		return true
+	})
+	if len(proofs) < 2 {
+		return
+	}
+	for i, err := range proposals.chain.ValidateProposerProofs(proofs) {
+		if err != nil {
+			proposals.pendingProofs.Delete(keys[i])
+		}
+	}
+}
+
 func (proposals *Proposals) ProcessPendingBlocks() []*types.BlockProposal {
 	var result []*types.BlockProposal
 
+	var headers []*types.Header
+	proposals.pendingBlocks.Range(func(key, value interface{}) bool {
+		headers = append(headers, value.(*blockPeer).proposal.Header)
+		return true
+	})
+	proposals.chain.PreverifySeedProofs(headers)
+
 	checkState, err := proposals.appState.ForCheck(proposals.chain.Head.Height())
 	if err != nil {
 		proposals.log.Warn("failed to create checkState", "err", err)
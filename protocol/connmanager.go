@@ -34,12 +34,14 @@ type ConnManager struct {
 	connMutex sync.Mutex
 	host      core.Host
 	cfg       config.P2P
+	priority  *priorityPeers
 }
 
-func NewConnManager(host core.Host, cfg config.P2P) *ConnManager {
+func NewConnManager(host core.Host, cfg config.P2P, priority *priorityPeers) *ConnManager {
 	return &ConnManager{
 		host:              host,
 		cfg:               cfg,
+		priority:          priority,
 		bannedPeers:       mapset.NewSet(),
 		activeConnections: make(map[peer.ID]network.Conn),
 		inboundPeers:      make(map[peer.ID]struct{}),
@@ -51,6 +53,9 @@ func NewConnManager(host core.Host, cfg config.P2P) *ConnManager {
 }
 
 func (m *ConnManager) CanConnect(id peer.ID) bool {
+	if m.priority.contains(id) {
+		return true
+	}
 	if m.bannedPeers.Contains(id) {
 		return false
 	}
@@ -63,6 +68,9 @@ func (m *ConnManager) CanConnect(id peer.ID) bool {
 }
 
 func (m *ConnManager) Connected(id peer.ID, inbound bool) {
+	if m.priority.contains(id) {
+		return
+	}
 	m.peerMutex.Lock()
 	defer m.peerMutex.Unlock()
 	if inbound {
@@ -85,6 +93,9 @@ func (m *ConnManager) Disconnected(id peer.ID, reason error) {
 }
 
 func (m *ConnManager) BanPeer(id peer.ID) {
+	if m.priority.contains(id) {
+		return
+	}
 	m.bannedPeers.Add(id)
 	if m.bannedPeers.Cardinality() > MaxBannedPeers {
 		m.bannedPeers.Pop()
@@ -206,13 +217,25 @@ func (m *ConnManager) RemoveConnection(conn network.Conn) {
 }
 
 func (m *ConnManager) CanAcceptStream() bool {
+	m.peerMutex.RLock()
+	defer m.peerMutex.RUnlock()
 	return len(m.inboundPeers) < m.cfg.MaxInboundPeers
 }
 
 func (m *ConnManager) CanDial() bool {
+	m.peerMutex.RLock()
+	defer m.peerMutex.RUnlock()
 	return len(m.outboundPeers) < m.cfg.MaxOutboundPeers
 }
 
+// SetPeerLimits changes max numbers of peers, connected peers above limits are not dropped
+func (m *ConnManager) SetPeerLimits(inbound, outbound int) {
+	m.peerMutex.Lock()
+	defer m.peerMutex.Unlock()
+	m.cfg.MaxInboundPeers = inbound
+	m.cfg.MaxOutboundPeers = outbound
+}
+
 func (m *ConnManager) GetRandomInboundPeer() peer.ID {
 	m.peerMutex.RLock()
 	defer m.peerMutex.RUnlock()
@@ -0,0 +1,82 @@
+package protocol
+
+import (
+	"context"
+	"github.com/idena-network/idena-go/protocol/dnsdisc"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/peerstore"
+	"math/rand"
+	"time"
+)
+
+const (
+	dnsSeedResolveTimeout = time.Minute
+	// dnsSeedDialCount is the number of discovered peers dialed per refresh, remaining ones are kept in the peer store
+	dnsSeedDialCount = 8
+)
+
+func (h *IdenaGossipHandler) runDnsDiscovery() {
+	for _, url := range h.cfg.DnsSeeds {
+		if _, _, err := dnsdisc.ParseURL(url); err != nil {
+			h.log.Error("Invalid DNS seed", "url", url, "err", err)
+		}
+	}
+	refresh := h.cfg.DnsSeedsRefresh
+	if refresh <= 0 {
+		refresh = time.Minute * 30
+	}
+	client := dnsdisc.NewClient(nil)
+	for {
+		h.refreshDnsSeeds(client)
+		time.Sleep(refresh)
+	}
+}
+
+func (h *IdenaGossipHandler) refreshDnsSeeds(client *dnsdisc.Client) {
+	var discovered []peer.AddrInfo
+	for _, url := range h.cfg.DnsSeeds {
+		ctx, cancel := context.WithTimeout(context.Background(), dnsSeedResolveTimeout)
+		addrs, err := client.SyncTree(ctx, url)
+		cancel()
+		if err != nil {
+			h.log.Warn("Failed to resolve DNS seed", "url", url, "err", err)
+		}
+		for _, addr := range addrs {
+			info, err := parsePeerAddr(addr)
+			if err != nil {
+				h.log.Debug("Invalid peer address in DNS seed", "url", url, "addr", addr, "err", err)
+				continue
+			}
+			if info.ID == h.host.ID() {
+				continue
+			}
+			discovered = append(discovered, info)
+		}
+	}
+	if len(discovered) == 0 {
+		return
+	}
+	h.log.Info("DNS seeds resolved", "peers", len(discovered))
+	for _, info := range discovered {
+		h.host.Peerstore().AddAddrs(info.ID, info.Addrs, peerstore.AddressTTL)
+	}
+	rand.Shuffle(len(discovered), func(i, j int) {
+		discovered[i], discovered[j] = discovered[j], discovered[i]
+	})
+	dialed := 0
+	for _, info := range discovered {
+		if dialed >= dnsSeedDialCount || !h.connManager.CanDial() {
+			break
+		}
+		if h.IsConnected(info.ID) {
+			continue
+		}
+		dialed++
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+		err := h.host.Connect(ctx, info)
+		cancel()
+		if err != nil {
+			h.log.Debug("Failed to dial DNS seed peer", "id", info.ID.Pretty(), "err", err)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package dnsdisc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"github.com/pkg/errors"
+	"net"
+	"strings"
+	"sync"
+)
+
+const maxLinkDepth = 4
+
+type Resolver interface {
+	LookupTXT(ctx context.Context, domain string) ([]string, error)
+}
+
+// Client resolves peer lists from DNS, entries are cached by hash and trees are resolved incrementally
+type Client struct {
+	resolver Resolver
+
+	mutex   sync.Mutex
+	entries map[string]entry
+	seqs    map[string]uint64
+}
+
+func NewClient(resolver Resolver) *Client {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &Client{
+		resolver: resolver,
+		entries:  make(map[string]entry),
+		seqs:     make(map[string]uint64),
+	}
+}
+
+// SyncTree resolves the tree by its url and returns multiaddresses of all peers including ones of linked trees
+func (c *Client) SyncTree(ctx context.Context, url string) ([]string, error) {
+	pubkey, domain, err := ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+	var peers []string
+	visited := make(map[string]bool)
+	if err := c.syncTree(ctx, pubkey, domain, 0, visited, &peers); err != nil {
+		return peers, err
+	}
+	return peers, nil
+}
+
+func (c *Client) syncTree(ctx context.Context, pubkey *ecdsa.PublicKey, domain string, depth int, visited map[string]bool, peers *[]string) error {
+	if visited[domain] {
+		return nil
+	}
+	visited[domain] = true
+	root, err := c.resolveRoot(ctx, pubkey, domain)
+	if err != nil {
+		return errors.Wrapf(err, "cannot resolve root of %v", domain)
+	}
+	var links []*linkEntry
+	pending := []string{root.eRoot}
+	for len(pending) > 0 {
+		h := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		e, err := c.resolveEntry(ctx, domain, h)
+		if err != nil {
+			return err
+		}
+		switch e := e.(type) {
+		case *branchEntry:
+			pending = append(pending, e.children...)
+		case *peerEntry:
+			*peers = append(*peers, e.addr)
+		case *linkEntry:
+			links = append(links, e)
+		}
+	}
+	if depth >= maxLinkDepth {
+		return nil
+	}
+	for _, link := range links {
+		if err := c.syncTree(ctx, link.pubkey, link.domain, depth+1, visited, peers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) resolveRoot(ctx context.Context, pubkey *ecdsa.PublicKey, domain string) (*rootEntry, error) {
+	txts, err := c.resolver.LookupTXT(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	for _, txt := range txts {
+		if !strings.HasPrefix(txt, rootPrefix) {
+			continue
+		}
+		root, err := parseRoot(txt)
+		if err != nil {
+			return nil, err
+		}
+		if !root.verify(pubkey) {
+			return nil, errInvalidSig
+		}
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		if seq, ok := c.seqs[domain]; ok && root.seq < seq {
+			return nil, errors.Errorf("root seq %v is lower than known %v", root.seq, seq)
+		}
+		c.seqs[domain] = root.seq
+		return root, nil
+	}
+	return nil, errors.New("no root entry found")
+}
+
+func (c *Client) resolveEntry(ctx context.Context, domain, hash string) (entry, error) {
+	c.mutex.Lock()
+	e, ok := c.entries[hash]
+	c.mutex.Unlock()
+	if ok {
+		return e, nil
+	}
+	txts, err := c.resolver.LookupTXT(ctx, hash+"."+domain)
+	if err != nil {
+		return nil, err
+	}
+	for _, txt := range txts {
+		e, err := parseEntry(txt)
+		if err == errUnknownEntry {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid entry %v", hash)
+		}
+		if subdomain(e) != hash {
+			return nil, errHashMismatch
+		}
+		c.mutex.Lock()
+		c.entries[hash] = e
+		c.mutex.Unlock()
+		return e, nil
+	}
+	return nil, errors.Errorf("entry %v not found", hash)
+}
@@ -0,0 +1,78 @@
+package dnsdisc
+
+import (
+	"context"
+	"fmt"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"sort"
+	"testing"
+)
+
+type mapResolver map[string]string
+
+func (r mapResolver) LookupTXT(ctx context.Context, domain string) ([]string, error) {
+	if txt, ok := r[domain]; ok {
+		return []string{txt}, nil
+	}
+	return nil, errors.Errorf("%v not found", domain)
+}
+
+func (r mapResolver) add(records map[string]string) {
+	for k, v := range records {
+		r[k] = v
+	}
+}
+
+func TestClient_SyncTree(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	linkKey, _ := crypto.GenerateKey()
+
+	var peers []string
+	for i := 0; i < 40; i++ {
+		peers = append(peers, fmt.Sprintf("/ip4/10.0.0.%d/tcp/40404/ipfs/peer%d", i, i))
+	}
+	linked, err := MakeTree(1, []string{"/ip4/10.0.1.1/tcp/40404/ipfs/linked"}, nil)
+	require.NoError(t, err)
+	require.NoError(t, linked.Sign(linkKey))
+
+	tree, err := MakeTree(2, peers, []string{MakeURL(&linkKey.PublicKey, "linked.example.org")})
+	require.NoError(t, err)
+	require.NoError(t, tree.Sign(key))
+
+	resolver := mapResolver{}
+	resolver.add(tree.ToTXT("nodes.example.org"))
+	resolver.add(linked.ToTXT("linked.example.org"))
+
+	client := NewClient(resolver)
+	result, err := client.SyncTree(context.Background(), MakeURL(&key.PublicKey, "nodes.example.org"))
+	require.NoError(t, err)
+	expected := append(append([]string{}, peers...), "/ip4/10.0.1.1/tcp/40404/ipfs/linked")
+	sort.Strings(expected)
+	sort.Strings(result)
+	require.Equal(t, expected, result)
+
+	otherKey, _ := crypto.GenerateKey()
+	_, err = NewClient(resolver).SyncTree(context.Background(), MakeURL(&otherKey.PublicKey, "nodes.example.org"))
+	require.Error(t, err)
+
+	outdated, _ := MakeTree(1, peers[:1], nil)
+	require.NoError(t, outdated.Sign(key))
+	resolver.add(outdated.ToTXT("nodes.example.org"))
+	_, err = client.SyncTree(context.Background(), MakeURL(&key.PublicKey, "nodes.example.org"))
+	require.Error(t, err)
+}
+
+func TestParseURL(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	pubkey, domain, err := ParseURL(MakeURL(&key.PublicKey, "nodes.example.org"))
+	require.NoError(t, err)
+	require.Equal(t, "nodes.example.org", domain)
+	require.Equal(t, key.PublicKey, *pubkey)
+
+	_, _, err = ParseURL("idenatree://nodes.example.org")
+	require.Error(t, err)
+	_, _, err = ParseURL("enrtree://AAAA@nodes.example.org")
+	require.Error(t, err)
+}
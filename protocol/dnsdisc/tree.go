@@ -0,0 +1,266 @@
+// Package dnsdisc implements bootstrap peer discovery through signed peer lists published in DNS TXT records.
+// The scheme follows EIP-1459: the root record is signed by the list operator and references a merkle tree of
+// branch records, leaves of the tree are peer multiaddresses or links to other trees.
+package dnsdisc
+
+import (
+	"crypto/ecdsa"
+	"encoding/base32"
+	"encoding/base64"
+	"fmt"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+)
+
+const (
+	rootPrefix   = "idenatree-root:v1"
+	branchPrefix = "idenatree-branch:"
+	peerPrefix   = "idenapeer:"
+	linkPrefix   = "idenatree://"
+
+	hashLength = 16
+	// maxChildren keeps branch records within a single UDP DNS response
+	maxChildren = 13
+)
+
+var (
+	b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+	b64 = base64.RawURLEncoding
+
+	errUnknownEntry = errors.New("unknown entry type")
+	errHashMismatch = errors.New("entry hash mismatch")
+	errInvalidSig   = errors.New("invalid root signature")
+)
+
+type entry interface {
+	fmt.Stringer
+}
+
+type (
+	rootEntry struct {
+		eRoot string
+		seq   uint64
+		sig   []byte
+	}
+	branchEntry struct {
+		children []string
+	}
+	peerEntry struct {
+		addr string
+	}
+	linkEntry struct {
+		str    string
+		domain string
+		pubkey *ecdsa.PublicKey
+	}
+)
+
+func (e *rootEntry) String() string {
+	return fmt.Sprintf("%s e=%s seq=%d sig=%s", rootPrefix, e.eRoot, e.seq, b64.EncodeToString(e.sig))
+}
+
+func (e *rootEntry) sigHash() []byte {
+	return crypto.Keccak256([]byte(fmt.Sprintf("%s e=%s seq=%d", rootPrefix, e.eRoot, e.seq)))
+}
+
+func (e *rootEntry) verify(pubkey *ecdsa.PublicKey) bool {
+	if len(e.sig) != 65 {
+		return false
+	}
+	return crypto.VerifySignature(crypto.CompressPubkey(pubkey), e.sigHash(), e.sig[:64])
+}
+
+func (e *branchEntry) String() string {
+	return branchPrefix + strings.Join(e.children, ",")
+}
+
+func (e *peerEntry) String() string {
+	return peerPrefix + e.addr
+}
+
+func (e *linkEntry) String() string {
+	return e.str
+}
+
+func subdomain(e entry) string {
+	h := crypto.Keccak256([]byte(e.String()))
+	return b32.EncodeToString(h[:hashLength])
+}
+
+func parseEntry(s string) (entry, error) {
+	switch {
+	case strings.HasPrefix(s, linkPrefix):
+		return parseLink(s)
+	case strings.HasPrefix(s, branchPrefix):
+		return parseBranch(s)
+	case strings.HasPrefix(s, peerPrefix):
+		addr := strings.TrimPrefix(s, peerPrefix)
+		if addr == "" {
+			return nil, errors.New("empty peer address")
+		}
+		return &peerEntry{addr: addr}, nil
+	default:
+		return nil, errUnknownEntry
+	}
+}
+
+func parseRoot(s string) (*rootEntry, error) {
+	if !strings.HasPrefix(s, rootPrefix+" ") {
+		return nil, errUnknownEntry
+	}
+	e := new(rootEntry)
+	for _, field := range strings.Fields(strings.TrimPrefix(s, rootPrefix)) {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			return nil, errors.Errorf("invalid root field %q", field)
+		}
+		switch kv[0] {
+		case "e":
+			if !isValidHash(kv[1]) {
+				return nil, errors.New("invalid root hash")
+			}
+			e.eRoot = kv[1]
+		case "seq":
+			seq, err := strconv.ParseUint(kv[1], 10, 64)
+			if err != nil {
+				return nil, errors.Wrap(err, "invalid root seq")
+			}
+			e.seq = seq
+		case "sig":
+			sig, err := b64.DecodeString(kv[1])
+			if err != nil {
+				return nil, errors.Wrap(err, "invalid root signature encoding")
+			}
+			e.sig = sig
+		}
+	}
+	if e.eRoot == "" || e.sig == nil {
+		return nil, errors.New("incomplete root entry")
+	}
+	return e, nil
+}
+
+func parseBranch(s string) (entry, error) {
+	list := strings.TrimPrefix(s, branchPrefix)
+	if list == "" {
+		return &branchEntry{}, nil
+	}
+	children := strings.Split(list, ",")
+	for _, c := range children {
+		if !isValidHash(c) {
+			return nil, errors.Errorf("invalid child hash %q", c)
+		}
+	}
+	return &branchEntry{children: children}, nil
+}
+
+func parseLink(s string) (*linkEntry, error) {
+	pubkey, domain, err := ParseURL(s)
+	if err != nil {
+		return nil, err
+	}
+	return &linkEntry{str: s, domain: domain, pubkey: pubkey}, nil
+}
+
+// ParseURL parses the tree location of the form idenatree://<base32 compressed public key>@<domain>
+func ParseURL(url string) (*ecdsa.PublicKey, string, error) {
+	if !strings.HasPrefix(url, linkPrefix) {
+		return nil, "", errors.New("invalid tree url scheme")
+	}
+	parts := strings.SplitN(strings.TrimPrefix(url, linkPrefix), "@", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, "", errors.New("tree url should contain public key and domain")
+	}
+	keyBytes, err := b32.DecodeString(parts[0])
+	if err != nil {
+		return nil, "", errors.Wrap(err, "invalid tree public key encoding")
+	}
+	pubkey, err := crypto.DecompressPubkey(keyBytes)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "invalid tree public key")
+	}
+	return pubkey, parts[1], nil
+}
+
+// MakeURL builds the tree location for the key of the list operator
+func MakeURL(pubkey *ecdsa.PublicKey, domain string) string {
+	return linkPrefix + b32.EncodeToString(crypto.CompressPubkey(pubkey)) + "@" + domain
+}
+
+func isValidHash(s string) bool {
+	b, err := b32.DecodeString(s)
+	return err == nil && len(b) == hashLength && !strings.ContainsAny(s, "\n=")
+}
+
+// Tree is a signed peer list ready to be published in DNS
+type Tree struct {
+	root    *rootEntry
+	entries map[string]entry
+}
+
+// MakeTree builds the tree of peer multiaddresses and links to other trees, the tree must be signed before publishing
+func MakeTree(seq uint64, peers []string, links []string) (*Tree, error) {
+	t := &Tree{entries: make(map[string]entry)}
+	var leaves []entry
+	for _, addr := range peers {
+		leaves = append(leaves, &peerEntry{addr: addr})
+	}
+	for _, url := range links {
+		link, err := parseLink(url)
+		if err != nil {
+			return nil, err
+		}
+		leaves = append(leaves, link)
+	}
+	top := t.build(leaves)
+	t.root = &rootEntry{eRoot: subdomain(top), seq: seq}
+	return t, nil
+}
+
+func (t *Tree) build(leaves []entry) entry {
+	if len(leaves) == 1 {
+		t.entries[subdomain(leaves[0])] = leaves[0]
+		return leaves[0]
+	}
+	if len(leaves) <= maxChildren {
+		b := &branchEntry{children: make([]string, 0, len(leaves))}
+		for _, leaf := range leaves {
+			h := subdomain(leaf)
+			t.entries[h] = leaf
+			b.children = append(b.children, h)
+		}
+		t.entries[subdomain(b)] = b
+		return b
+	}
+	var subtrees []entry
+	for len(leaves) > 0 {
+		n := maxChildren
+		if len(leaves) < n {
+			n = len(leaves)
+		}
+		subtrees = append(subtrees, t.build(leaves[:n]))
+		leaves = leaves[n:]
+	}
+	return t.build(subtrees)
+}
+
+// Sign signs the tree root with the key of the list operator
+func (t *Tree) Sign(key *ecdsa.PrivateKey) error {
+	sig, err := crypto.Sign(t.root.sigHash(), key)
+	if err != nil {
+		return err
+	}
+	t.root.sig = sig
+	return nil
+}
+
+// ToTXT returns TXT records of the tree keyed by fully qualified names
+func (t *Tree) ToTXT(domain string) map[string]string {
+	records := map[string]string{domain: t.root.String()}
+	for h, e := range t.entries {
+		records[h+"."+domain] = e.String()
+	}
+	return records
+}
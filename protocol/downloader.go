@@ -11,6 +11,7 @@ import (
 	"github.com/idena-network/idena-go/common/math"
 	"github.com/idena-network/idena-go/config"
 	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/mirror"
 	"github.com/idena-network/idena-go/core/state"
 	"github.com/idena-network/idena-go/core/state/snapshot"
 	"github.com/idena-network/idena-go/ipfs"
@@ -18,6 +19,8 @@ import (
 	"github.com/idena-network/idena-go/secstore"
 	"github.com/idena-network/idena-go/stats/collector"
 	"github.com/libp2p/go-libp2p-core/peer"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -27,6 +30,7 @@ const (
 
 var (
 	BanReasonTimeout = errors.New("timeout")
+	errStopped       = errors.New("downloader is stopped")
 )
 
 type Syncer interface {
@@ -55,7 +59,6 @@ type Downloader struct {
 	chain                *blockchain.Blockchain
 	batches              chan *batch
 	ipfs                 ipfs.Proxy
-	isSyncing            bool
 	appState             *appstate.AppState
 	top                  uint64
 	potentialForkedPeers mapset.Set
@@ -63,10 +66,18 @@ type Downloader struct {
 	bus                  eventbus.Bus
 	secStore             *secstore.SecStore
 	statsCollector       collector.StatsCollector
+	mirror               *mirror.Client
+	// stopped is set when the node shuts down, sync in progress stops requesting new batches
+	stopped int32
+	// syncDone is closed when the current sync is completed, it is nil if there is no sync
+	syncDone  chan struct{}
+	syncMutex sync.Mutex
 }
 
 func (d *Downloader) IsSyncing() bool {
-	return d.isSyncing
+	d.syncMutex.Lock()
+	defer d.syncMutex.Unlock()
+	return d.syncDone != nil
 }
 
 func (d *Downloader) SyncProgress() (head uint64, top uint64) {
@@ -87,6 +98,7 @@ func NewDownloader(
 	bus eventbus.Bus,
 	secStore *secstore.SecStore,
 	statsCollector collector.StatsCollector,
+	mirror *mirror.Client,
 ) *Downloader {
 	return &Downloader{
 		pm:                   pm,
@@ -95,12 +107,12 @@ func NewDownloader(
 		log:                  log.New("component", "downloader"),
 		ipfs:                 ipfs,
 		appState:             appState,
-		isSyncing:            false,
 		potentialForkedPeers: mapset.NewSet(),
 		sm:                   sm,
 		bus:                  bus,
 		secStore:             secStore,
 		statsCollector:       statsCollector,
+		mirror:               mirror,
 	}
 }
 
@@ -120,9 +132,34 @@ func (d *Downloader) filterForkedPeers(peers map[peer.ID]uint64) {
 	}
 }
 
+// Stop stops the current sync after the requested batches are applied and waits until it is completed or timeout
+// expires, next syncs are refused
+func (d *Downloader) Stop(timeout time.Duration) bool {
+	d.syncMutex.Lock()
+	atomic.StoreInt32(&d.stopped, 1)
+	done := d.syncDone
+	d.syncMutex.Unlock()
+	if done == nil {
+		return true
+	}
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func (d *Downloader) isStopped() bool {
+	return atomic.LoadInt32(&d.stopped) == 1
+}
+
 func (d *Downloader) SyncBlockchain(forkResolver ForkResolver) error {
 
 	for {
+		if d.isStopped() {
+			return errStopped
+		}
 		if forkResolver.HasLoadedFork() {
 			return errors.New("loaded fork is detected")
 		}
@@ -144,8 +181,10 @@ func (d *Downloader) SyncBlockchain(forkResolver ForkResolver) error {
 			d.log.Info(fmt.Sprintf("Node is synchronized"))
 			return nil
 		}
-		if !d.isSyncing {
-			d.startSync()
+		if !d.IsSyncing() {
+			if !d.startSync() {
+				return errStopped
+			}
 			defer d.stopSync()
 		}
 		d.Load()
@@ -173,7 +212,7 @@ func (d *Downloader) Load() {
 
 	knownHeights := d.pm.GetKnownHeights()
 loop:
-	for from <= toHeight && len(knownHeights) > 0 {
+	for from <= toHeight && len(knownHeights) > 0 && !d.isStopped() {
 		for peer, height := range knownHeights {
 			if height < from {
 				delete(knownHeights, peer)
@@ -248,11 +287,11 @@ func (d *Downloader) consumeBlocks(applier blockApplier, term chan interface{},
 }
 
 func (d *Downloader) SeekBlocks(fromBlock, toBlock uint64, peers []peer.ID) chan *types.BlockBundle {
-	return NewFullSync(d.pm, d.log, d.chain, d.ipfs, d.appState, d.potentialForkedPeers, 0, d.statsCollector).SeekBlocks(fromBlock, toBlock, peers)
+	return NewFullSync(d.pm, d.log, d.chain, d.ipfs, d.appState, d.potentialForkedPeers, 0, d.statsCollector, d.mirror).SeekBlocks(fromBlock, toBlock, peers)
 }
 
 func (d *Downloader) SeekForkedBlocks(ownBlocks []common.Hash, peerId peer.ID) chan types.BlockBundle {
-	return NewFullSync(d.pm, d.log, d.chain, d.ipfs, d.appState, d.potentialForkedPeers, 0, d.statsCollector).SeekForkedBlocks(ownBlocks, peerId)
+	return NewFullSync(d.pm, d.log, d.chain, d.ipfs, d.appState, d.potentialForkedPeers, 0, d.statsCollector, d.mirror).SeekForkedBlocks(ownBlocks, peerId)
 }
 
 func (d *Downloader) HasPotentialFork() bool {
@@ -284,11 +323,11 @@ func (d *Downloader) createBlockApplier() (loader blockApplier, toHeight uint64)
 
 	if canUseFastSync {
 		d.log.Info("Fast sync will be used")
-		return NewFastSync(d.pm, d.log, d.chain, d.ipfs, d.appState, d.potentialForkedPeers, manifest, d.sm, d.bus, d.secStore.GetAddress()), manifest.Height
+		return NewFastSync(d.pm, d.log, d.chain, d.ipfs, d.appState, d.potentialForkedPeers, manifest, d.sm, d.bus, d.secStore.GetAddress(), d.mirror), manifest.Height
 	} else {
 		d.log.Info("Full sync will be used")
 		top := d.top
-		return NewFullSync(d.pm, d.log, d.chain, d.ipfs, d.appState, d.potentialForkedPeers, top, d.statsCollector), top
+		return NewFullSync(d.pm, d.log, d.chain, d.ipfs, d.appState, d.potentialForkedPeers, top, d.statsCollector, d.mirror), top
 	}
 }
 
@@ -305,31 +344,42 @@ func (d *Downloader) getBestManifest() *snapshot.Manifest {
 		}
 	}
 
-	var best *snapshot.Manifest
-	for _, m := range manifests {
-		if (best == nil || best.Height < m.Height) && !d.sm.IsInvalidManifest(m.Cid) {
-			best = m
-		}
+	best, mismatched := selectManifest(manifests, d.cfg.Sync.ManifestSources, d.cfg.Sync.ManifestPins, d.sm.IsInvalidManifest)
+	for _, id := range mismatched {
+		m := manifests[id]
+		d.log.Warn("Snapshot manifest doesn't match the pinned root", "peer", id.Pretty(), "height", m.Height, "root", m.Root.Hex())
+	}
+	if len(mismatched) > 0 {
+		d.log.Warn("Snapshot manifests mismatching pins are ignored", "count", len(mismatched), "peers", len(manifests))
 	}
 	if best == nil {
-		d.log.Info("Snapshot manifest is not found")
+		d.log.Info("Snapshot manifest confirmed by enough peers is not found", "peers", len(manifests), "required", d.cfg.Sync.ManifestSources)
 	} else {
 		d.log.Info("Found manifest", "height", best.Height)
 	}
 	return best
 }
 
-func (d *Downloader) startSync() {
-	d.isSyncing = true
+func (d *Downloader) startSync() bool {
+	d.syncMutex.Lock()
+	defer d.syncMutex.Unlock()
+	if d.isStopped() {
+		return false
+	}
+	d.syncDone = make(chan struct{})
 	d.chain.StartSync()
 	d.sm.StartSync()
+	return true
 }
 
 func (d *Downloader) stopSync() {
 	d.chain.StopSync()
 	d.sm.StopSync()
-	d.isSyncing = false
 	d.top = 0
+	d.syncMutex.Lock()
+	close(d.syncDone)
+	d.syncDone = nil
+	d.syncMutex.Unlock()
 }
 
 func (d *Downloader) BanPeer(peerId peer.ID, reason error) {
@@ -354,3 +404,15 @@ func requestBatch(pm *IdenaGossipHandler, from, to uint64, ignoredPeer peer.ID)
 	}
 	return nil
 }
+
+// loadBody loads the block body from the HTTP mirror if it's configured and falls back to IPFS
+func loadBody(mirror *mirror.Client, ipfsProxy ipfs.Proxy, header *types.Header) ([]byte, error) {
+	if mirror != nil {
+		if data, err := mirror.Body(header); err == nil {
+			return data, nil
+		} else {
+			log.Debug("Cannot load block body from HTTP mirror", "hash", header.Hash().Hex(), "err", err)
+		}
+	}
+	return ipfsProxy.Get(header.ProposedHeader.IpfsHash)
+}
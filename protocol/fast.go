@@ -8,6 +8,7 @@ import (
 	"github.com/idena-network/idena-go/common"
 	"github.com/idena-network/idena-go/common/eventbus"
 	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/mirror"
 	"github.com/idena-network/idena-go/core/state"
 	"github.com/idena-network/idena-go/core/state/snapshot"
 	"github.com/idena-network/idena-go/core/validators"
@@ -37,6 +38,7 @@ type fastSync struct {
 	bus                  eventbus.Bus
 	deferredHeaders      []blockPeer
 	coinBase             common.Address
+	mirror               *mirror.Client
 }
 
 func (fs *fastSync) batchSize() uint64 {
@@ -48,7 +50,7 @@ func NewFastSync(pm *IdenaGossipHandler, log log.Logger,
 	ipfs ipfs.Proxy,
 	appState *appstate.AppState,
 	potentialForkedPeers mapset.Set,
-	manifest *snapshot.Manifest, sm *state.SnapshotManager, bus eventbus.Bus, coinbase common.Address) *fastSync {
+	manifest *snapshot.Manifest, sm *state.SnapshotManager, bus eventbus.Bus, coinbase common.Address, mirror *mirror.Client) *fastSync {
 
 	return &fastSync{
 		appState:             appState,
@@ -63,6 +65,7 @@ func NewFastSync(pm *IdenaGossipHandler, log log.Logger,
 		sm:                   sm,
 		bus:                  bus,
 		coinBase:             coinbase,
+		mirror:               mirror,
 	}
 }
 
@@ -137,7 +140,7 @@ func (fs *fastSync) applyDeferredBlocks() (uint64, error) {
 			return b.Header.Height(), err
 		}
 		if bloom.Has(fs.coinBase) {
-			txs, err := fs.GetBlockTransactions(b.Header.Hash(), b.Header.ProposedHeader.IpfsHash)
+			txs, err := fs.GetBlockTransactions(b.Header)
 			if err != nil {
 				return b.Header.Height(), err
 			}
@@ -148,12 +151,12 @@ func (fs *fastSync) applyDeferredBlocks() (uint64, error) {
 	return 0, nil
 }
 
-func (fs *fastSync) GetBlockTransactions(hash common.Hash, ipfsHash []byte) (types.Transactions, error) {
-	if txs, err := fs.ipfs.Get(ipfsHash); err != nil {
+func (fs *fastSync) GetBlockTransactions(header *types.Header) (types.Transactions, error) {
+	if txs, err := loadBody(fs.mirror, fs.ipfs, header); err != nil {
 		return nil, err
 	} else {
 		if len(txs) > 0 {
-			fs.log.Debug("Retrieve block body from ipfs", "hash", hash.Hex())
+			fs.log.Debug("Retrieve block body from ipfs", "hash", header.Hash().Hex())
 		}
 		body := &types.Body{}
 		body.FromBytes(txs)
@@ -7,6 +7,7 @@ import (
 	"github.com/idena-network/idena-go/blockchain/types"
 	"github.com/idena-network/idena-go/common"
 	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/mirror"
 	"github.com/idena-network/idena-go/ipfs"
 	"github.com/idena-network/idena-go/log"
 	"github.com/idena-network/idena-go/stats/collector"
@@ -34,6 +35,7 @@ type fullSync struct {
 	deferredHeaders      []blockPeer
 	targetHeight         uint64
 	statsCollector       collector.StatsCollector
+	mirror               *mirror.Client
 }
 
 func (fs *fullSync) batchSize() uint64 {
@@ -49,6 +51,7 @@ func NewFullSync(
 	potentialForkedPeers mapset.Set,
 	targetHeight uint64,
 	statsCollector collector.StatsCollector,
+	mirror *mirror.Client,
 ) *fullSync {
 
 	return &fullSync{
@@ -62,6 +65,7 @@ func NewFullSync(
 		ipfs:                 ipfs,
 		targetHeight:         targetHeight,
 		statsCollector:       statsCollector,
+		mirror:               mirror,
 	}
 }
 
@@ -210,7 +214,7 @@ func (fs *fullSync) GetBlock(header *types.Header) (*types.Block, error) {
 			Body:   &types.Body{},
 		}, nil
 	}
-	if txs, err := fs.ipfs.Get(header.ProposedHeader.IpfsHash); err != nil {
+	if txs, err := loadBody(fs.mirror, fs.ipfs, header); err != nil {
 		return nil, err
 	} else {
 		if len(txs) > 0 {
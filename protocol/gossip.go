@@ -7,6 +7,7 @@ import (
 	"github.com/idena-network/idena-go/blockchain"
 	"github.com/idena-network/idena-go/blockchain/types"
 	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/bandwidth"
 	"github.com/idena-network/idena-go/common/entry"
 	"github.com/idena-network/idena-go/common/eventbus"
 	"github.com/idena-network/idena-go/common/maputil"
@@ -23,6 +24,7 @@ import (
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/multiformats/go-multiaddr"
 	"github.com/pkg/errors"
+	"math/rand"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -55,14 +57,19 @@ type IdenaGossipHandler struct {
 	incomeBatches       *sync.Map
 	batchedLock         sync.Mutex
 	bus                 eventbus.Bus
-	wrongTime           bool
+	timeSync            *TimeSync
 	appVersion          string
+	bandwidth           *bandwidth.Scheduler
 
 	log          log.Logger
 	mutex        sync.Mutex
 	pendingPeers map[peer.ID]struct{}
 	metrics      *metricCollector
 	connManager  *ConnManager
+	priority     *priorityPeers
+	txTracer     *txTracer
+	// dialingStatic is set while static peers are being dialed
+	dialingStatic int32
 }
 
 type metricCollector struct {
@@ -70,7 +77,8 @@ type metricCollector struct {
 	outcomeMessage func(msg *Msg)
 }
 
-func NewIdenaGossipHandler(host core.Host, cfg config.P2P, chain *blockchain.Blockchain, proposals *pengings.Proposals, votes *pengings.Votes, txpool *mempool.TxPool, fp *flip.Flipper, bus eventbus.Bus, flipKeyPool *mempool.KeysPool, appVersion string) *IdenaGossipHandler {
+func NewIdenaGossipHandler(host core.Host, cfg config.P2P, chain *blockchain.Blockchain, proposals *pengings.Proposals, votes *pengings.Votes, txpool *mempool.TxPool, fp *flip.Flipper, bus eventbus.Bus, flipKeyPool *mempool.KeysPool, timeSync *TimeSync, appVersion string, bandwidth *bandwidth.Scheduler) *IdenaGossipHandler {
+	priority := newPriorityPeers()
 	handler := &IdenaGossipHandler{
 		host:                host,
 		cfg:                 cfg,
@@ -92,7 +100,21 @@ func NewIdenaGossipHandler(host core.Host, cfg config.P2P, chain *blockchain.Blo
 		log:                 log.New(),
 		pendingPeers:        make(map[peer.ID]struct{}),
 		metrics:             new(metricCollector),
-		connManager:         NewConnManager(host, cfg),
+		connManager:         NewConnManager(host, cfg, priority),
+		priority:            priority,
+		timeSync:            timeSync,
+		bandwidth:           bandwidth,
+		txTracer:            newTxTracer(),
+	}
+	for _, url := range cfg.StaticPeers {
+		if _, err := priority.add(url, true, false); err != nil {
+			handler.log.Error("Invalid static peer", "url", url, "err", err)
+		}
+	}
+	for _, url := range cfg.TrustedPeers {
+		if _, err := priority.add(url, false, true); err != nil {
+			handler.log.Error("Invalid trusted peer", "url", url, "err", err)
+		}
 	}
 	handler.pushPullManager.AddEntryHolder(pushVote, entry.NewDefaultHolder(3))
 	handler.pushPullManager.AddEntryHolder(pushBlock, entry.NewDefaultHolder(3))
@@ -108,11 +130,16 @@ func (h *IdenaGossipHandler) Start() {
 
 	setHandler := func() {
 		h.host.SetStreamHandler(IdenaProtocol, h.acceptStream)
-		h.connManager = NewConnManager(h.host, h.cfg)
+		h.connManager = NewConnManager(h.host, h.cfg, h.priority)
 		notifiee := &notifiee{
 			connManager: h.connManager,
 		}
 		h.host.Network().Notify(notifiee)
+		for _, p := range h.priority.list(h.IsConnected) {
+			if id, err := peer.IDB58Decode(p.ID); err == nil {
+				h.host.ConnManager().Protect(id, priorityPeerTag)
+			}
+		}
 	}
 	setHandler()
 
@@ -139,8 +166,10 @@ func (h *IdenaGossipHandler) Start() {
 	})
 
 	go h.broadcastLoop()
-	go h.checkTime()
 	go h.background()
+	if len(h.cfg.DnsSeeds) > 0 {
+		go h.runDnsDiscovery()
+	}
 }
 
 func (h *IdenaGossipHandler) background() {
@@ -151,19 +180,18 @@ func (h *IdenaGossipHandler) background() {
 		select {
 		case <-dialTicker.C:
 			h.dialPeers()
+			if atomic.CompareAndSwapInt32(&h.dialingStatic, 0, 1) {
+				go func() {
+					defer atomic.StoreInt32(&h.dialingStatic, 0)
+					h.dialStaticPeers()
+				}()
+			}
 		case <-renewTicker.C:
 			h.renewPeers()
 		}
 	}
 }
 
-func (h *IdenaGossipHandler) checkTime() {
-	for {
-		h.wrongTime = !checkClockDrift()
-		time.Sleep(time.Minute)
-	}
-}
-
 func (h *IdenaGossipHandler) handle(p *protoPeer) error {
 	msg, err := p.ReadMsg()
 	if err != nil {
@@ -328,6 +356,9 @@ func (h *IdenaGossipHandler) handle(p *protoPeer) error {
 			return nil
 		}
 		if entry, ok := h.pushPullManager.GetEntry(pullHash); ok {
+			if pullHash.Type == pushTx {
+				h.txTracer.fetched(pullHash.Hash, p.id, time.Now())
+			}
 			h.sendEntry(p, pullHash, entry)
 		}
 	case Block:
@@ -346,7 +377,8 @@ func (h *IdenaGossipHandler) handle(p *protoPeer) error {
 }
 
 func (h *IdenaGossipHandler) acceptStream(stream network.Stream) {
-	if h.connManager.CanConnect(stream.Conn().RemotePeer()) && h.connManager.CanAcceptStream() {
+	id := stream.Conn().RemotePeer()
+	if h.connManager.CanConnect(id) && (h.priority.contains(id) || h.connManager.CanAcceptStream()) {
 		h.runPeer(stream, true)
 	}
 }
@@ -371,7 +403,7 @@ func (h *IdenaGossipHandler) runPeer(stream network.Stream, inbound bool) (*prot
 		h.mutex.Unlock()
 	}()
 
-	peer := newPeer(stream, h.cfg.MaxDelay, h.metrics)
+	peer := newPeer(stream, h.cfg.MaxDelay, h.metrics, h.bandwidth)
 
 	if err := peer.Handshake(h.bcn.Network(), h.bcn.Head.Height(), h.bcn.Genesis(), h.appVersion, uint32(h.peers.Len())); err != nil {
 		current := semver.New(h.appVersion)
@@ -450,6 +482,10 @@ func (h *IdenaGossipHandler) renewPeers() {
 }
 
 func (h *IdenaGossipHandler) BanPeer(peerId peer.ID, reason error) {
+	if h.priority.contains(peerId) {
+		h.log.Warn("Static or trusted peer is not banned", "id", peerId.Pretty(), "reason", reason)
+		return
+	}
 	h.connManager.BanPeer(peerId)
 
 	peer := h.peers.Peer(peerId)
@@ -519,7 +555,7 @@ func (h *IdenaGossipHandler) broadcastLoop() {
 	for {
 		select {
 		case tx := <-h.txChan:
-			h.broadcastTx(tx.Tx, tx.Own)
+			h.broadcastTx(tx)
 		case key := <-h.flipKeyChan:
 			h.broadcastFlipKey(key.Key, key.Own)
 		case key := <-h.flipKeysPackageChan:
@@ -542,6 +578,15 @@ func (h *IdenaGossipHandler) GetKnownHeights() map[peer.ID]uint64 {
 	return result
 }
 
+// PeerVersions returns app versions connected peers have reported in handshakes
+func (h *IdenaGossipHandler) PeerVersions() map[string]string {
+	result := make(map[string]string)
+	for _, peer := range h.peers.Peers() {
+		result[peer.ID()] = peer.appVersion
+	}
+	return result
+}
+
 func (h *IdenaGossipHandler) GetKnownManifests() map[peer.ID]*snapshot.Manifest {
 	result := make(map[peer.ID]*snapshot.Manifest)
 	peers := h.peers.Peers()
@@ -672,14 +717,47 @@ func errResp(code int, format string, v ...interface{}) error {
 	return fmt.Errorf("%v - %v", code, fmt.Sprintf(format, v...))
 }
 
-func (h *IdenaGossipHandler) broadcastTx(tx *types.Transaction, own bool) {
+func (h *IdenaGossipHandler) broadcastTx(e *events.NewTxEvent) {
 
 	hash := pushPullHash{
 		Type: pushTx,
-		Hash: rlp.Hash128(tx),
+		Hash: rlp.Hash128(e.Tx),
+	}
+	h.pushPullManager.AddEntry(hash, e.Tx)
+	if e.Rebroadcast {
+		// peers could have dropped the tx, so it is announced again to all of them
+		peers := h.peers.SendToAll(Push, hash, e.Own)
+		h.txTracer.announced(e.Tx.Hash(), hash.Hash, TxRebroadcast, peers, time.Now())
+		return
 	}
-	h.pushPullManager.AddEntry(hash, tx)
-	h.peers.SendWithFilter(Push, hash, own)
+	peers := h.peers.SendWithFilter(Push, hash, e.Own)
+	if e.Local {
+		h.txTracer.announced(e.Tx.Hash(), hash.Hash, TxAnnounced, peers, time.Now())
+	}
+}
+
+// TxPropagation returns the propagation trace of the local tx or nil if the tx isn't traced
+func (h *IdenaGossipHandler) TxPropagation(hash common.Hash) *TxPropagation {
+	return h.txTracer.get(hash)
+}
+
+// SendTxDirect sends the full transaction to random peers bypassing the push-pull, returns the number of peers
+func (h *IdenaGossipHandler) SendTxDirect(tx *types.Transaction, count int) int {
+	peers := h.peers.Peers()
+	rand.Shuffle(len(peers), func(i, j int) {
+		peers[i], peers[j] = peers[j], peers[i]
+	})
+	if len(peers) > count {
+		peers = peers[:count]
+	}
+	ids := make([]peer.ID, 0, len(peers))
+	for _, p := range peers {
+		p.markPayload(tx)
+		go p.sendMsg(NewTx, tx, true)
+		ids = append(ids, p.id)
+	}
+	h.txTracer.announced(tx.Hash(), rlp.Hash128(tx), TxSentDirect, ids, time.Now())
+	return len(peers)
 }
 
 func (h *IdenaGossipHandler) sendFlip(flip *types.Flip) {
@@ -719,6 +797,9 @@ func (h *IdenaGossipHandler) RequestBlockByHash(hash common.Hash) {
 func (h *IdenaGossipHandler) syncTxPool(p *protoPeer) {
 	pending := h.txpool.GetPendingTransaction()
 	for _, tx := range pending {
+		if h.txpool.CheckAdmission(tx) != nil {
+			continue
+		}
 		payload := pushPullHash{
 			Type: pushTx,
 			Hash: rlp.Hash128(tx),
@@ -782,6 +863,27 @@ func (h *IdenaGossipHandler) PeerHeights() []uint64 {
 	return result
 }
 
+// RemovePeer disconnects the peer, the peer is not dialed again for a while
+func (h *IdenaGossipHandler) RemovePeer(id string) error {
+	peerId, err := peer.IDB58Decode(id)
+	if err != nil {
+		return errors.Wrap(err, "invalid peer id")
+	}
+	p := h.peers.Peer(peerId)
+	if p == nil {
+		return errors.New("peer is not connected")
+	}
+	p.disconnect()
+	return h.host.Network().ClosePeer(peerId)
+}
+
+// SetPeerLimits changes max numbers of inbound and outbound peers
+func (h *IdenaGossipHandler) SetPeerLimits(inbound, outbound int) {
+	h.cfg.MaxInboundPeers = inbound
+	h.cfg.MaxOutboundPeers = outbound
+	h.connManager.SetPeerLimits(inbound, outbound)
+}
+
 func (h *IdenaGossipHandler) Endpoint() string {
 	addrs := h.host.Network().ListenAddresses()
 	for _, a := range addrs {
@@ -817,7 +919,11 @@ func (h *IdenaGossipHandler) AddPeer(url string) error {
 }
 
 func (h *IdenaGossipHandler) WrongTime() bool {
-	return h.wrongTime
+	return h.timeSync.WrongTime()
+}
+
+func (h *IdenaGossipHandler) TimeSync() *TimeSync {
+	return h.timeSync
 }
 
 func (h *IdenaGossipHandler) IsConnected(id peer.ID) bool {
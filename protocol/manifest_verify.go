@@ -0,0 +1,80 @@
+package protocol
+
+import (
+	"bytes"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/state/snapshot"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"sort"
+)
+
+type manifestCandidate struct {
+	manifest *snapshot.Manifest
+	peers    []peer.ID
+}
+
+func sameManifest(a, b *snapshot.Manifest) bool {
+	return a.Height == b.Height && a.Root == b.Root && bytes.Equal(a.Cid, b.Cid)
+}
+
+// selectManifest returns the highest manifest announced by at least minSources peers and by the majority of peers
+// announcing the height. Candidates which don't match the operator-pinned root and minority ones announced by
+// conflicting peers are ignored, the next height is tried if no candidate has the majority. Peers which announced
+// manifests mismatching the pins are returned to be reported.
+func selectManifest(manifests map[peer.ID]*snapshot.Manifest, minSources int, pins []config.ManifestPin,
+	isInvalid func(cid []byte) bool) (*snapshot.Manifest, []peer.ID) {
+	if minSources < 1 {
+		minSources = 1
+	}
+	pinned := make(map[uint64]common.Hash)
+	for _, pin := range pins {
+		pinned[pin.Height] = pin.Root
+	}
+
+	byHeight := make(map[uint64][]*manifestCandidate)
+	var mismatched []peer.ID
+	for id, m := range manifests {
+		if isInvalid(m.Cid) {
+			continue
+		}
+		if root, ok := pinned[m.Height]; ok && root != m.Root {
+			mismatched = append(mismatched, id)
+			continue
+		}
+		var candidate *manifestCandidate
+		for _, c := range byHeight[m.Height] {
+			if sameManifest(c.manifest, m) {
+				candidate = c
+				break
+			}
+		}
+		if candidate == nil {
+			candidate = &manifestCandidate{manifest: m}
+			byHeight[m.Height] = append(byHeight[m.Height], candidate)
+		}
+		candidate.peers = append(candidate.peers, id)
+	}
+
+	heights := make([]uint64, 0, len(byHeight))
+	for height := range byHeight {
+		heights = append(heights, height)
+	}
+	sort.Slice(heights, func(i, j int) bool {
+		return heights[i] > heights[j]
+	})
+	for _, height := range heights {
+		var best *manifestCandidate
+		total := 0
+		for _, candidate := range byHeight[height] {
+			total += len(candidate.peers)
+			if best == nil || len(candidate.peers) > len(best.peers) {
+				best = candidate
+			}
+		}
+		if len(best.peers) >= minSources && len(best.peers)*2 > total {
+			return best.manifest, mismatched
+		}
+	}
+	return nil, mismatched
+}
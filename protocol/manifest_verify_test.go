@@ -0,0 +1,60 @@
+package protocol
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/core/state/snapshot"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_selectManifest(t *testing.T) {
+	noInvalid := func(cid []byte) bool { return false }
+	m100 := &snapshot.Manifest{Height: 100, Root: common.Hash{0x1}, Cid: []byte{0x1}}
+	m200 := &snapshot.Manifest{Height: 200, Root: common.Hash{0x2}, Cid: []byte{0x2}}
+
+	manifests := map[peer.ID]*snapshot.Manifest{
+		"p1": m100,
+		"p2": {Height: 100, Root: common.Hash{0x1}, Cid: []byte{0x1}},
+		"p3": m200,
+	}
+	// the highest manifest is announced by one peer only
+	require.Equal(t, m100, manifestOf(selectManifest(manifests, 2, nil, noInvalid)))
+	require.Equal(t, m200, manifestOf(selectManifest(manifests, 1, nil, noInvalid)))
+	require.Nil(t, manifestOf(selectManifest(manifests, 3, nil, noInvalid)))
+	require.Equal(t, m100, manifestOf(selectManifest(manifests, 1, nil, func(cid []byte) bool { return cid[0] == 0x2 })))
+
+	// manifests which don't match the pinned root are ignored and reported
+	_, mismatched := selectManifest(manifests, 2, []config.ManifestPin{{Height: 100, Root: common.Hash{0x3}}}, noInvalid)
+	require.ElementsMatch(t, []peer.ID{"p1", "p2"}, mismatched)
+	require.Nil(t, manifestOf(selectManifest(manifests, 2, []config.ManifestPin{{Height: 100, Root: common.Hash{0x3}}}, noInvalid)))
+	require.Equal(t, m100, manifestOf(selectManifest(manifests, 2, []config.ManifestPin{{Height: 100, Root: common.Hash{0x1}}}, noInvalid)))
+	require.Equal(t, m100, manifestOf(selectManifest(manifests, 1, []config.ManifestPin{{Height: 200, Root: common.Hash{0x3}}}, noInvalid)))
+
+	// the tie at the top height falls back to the next height
+	manifests["p4"] = &snapshot.Manifest{Height: 200, Root: common.Hash{0x4}, Cid: []byte{0x4}}
+	require.Equal(t, m100, manifestOf(selectManifest(manifests, 1, nil, noInvalid)))
+}
+
+func Test_selectManifestWithConflictingPeer(t *testing.T) {
+	noInvalid := func(cid []byte) bool { return false }
+	honest := &snapshot.Manifest{Height: 200, Root: common.Hash{0x2}, Cid: []byte{0x2}}
+	manifests := map[peer.ID]*snapshot.Manifest{
+		"p1": honest,
+		"p2": {Height: 200, Root: common.Hash{0x2}, Cid: []byte{0x2}},
+		"p3": {Height: 200, Root: common.Hash{0x2}, Cid: []byte{0x2}},
+		"p4": {Height: 200, Root: common.Hash{0x6}, Cid: []byte{0x6}},
+	}
+	require.Equal(t, honest, manifestOf(selectManifest(manifests, 2, nil, noInvalid)))
+	require.Equal(t, honest, manifestOf(selectManifest(manifests, 3, nil, noInvalid)))
+	require.Nil(t, manifestOf(selectManifest(manifests, 4, nil, noInvalid)))
+
+	// the conflicting peer announcing the higher manifest alone doesn't block honest ones
+	manifests["p4"] = &snapshot.Manifest{Height: 300, Root: common.Hash{0x6}, Cid: []byte{0x6}}
+	require.Equal(t, honest, manifestOf(selectManifest(manifests, 2, nil, noInvalid)))
+}
+
+func manifestOf(m *snapshot.Manifest, _ []peer.ID) *snapshot.Manifest {
+	return m
+}
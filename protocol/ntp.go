@@ -20,17 +20,14 @@
 package protocol
 
 import (
-	"fmt"
-	"github.com/idena-network/idena-go/log"
 	"net"
 	"sort"
 	"time"
 )
 
 const (
-	ntpPool        = "pool.ntp.org" // ntpPool is the NTP server to query for the current time
-	ntpChecks      = 3              // Number of measurements to do against the NTP server
-	driftThreshold = 10 * time.Second
+	ntpPool   = "pool.ntp.org" // ntpPool is the NTP server to query for the current time
+	ntpChecks = 3              // Number of measurements to do against the NTP server
 )
 
 // durationSlice attaches the methods of sort.Interface to []time.Duration,
@@ -41,23 +38,6 @@ func (s durationSlice) Len() int           { return len(s) }
 func (s durationSlice) Less(i, j int) bool { return s[i] < s[j] }
 func (s durationSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
 
-// checkClockDrift queries an NTP server for clock drifts and warns the user if
-// one large enough is detected.
-func checkClockDrift() bool{
-	drift, err := SntpDrift(ntpChecks)
-	if err != nil {
-		return true
-	}
-	if drift < -driftThreshold || drift > driftThreshold {
-		log.Warn(fmt.Sprintf("System clock seems off by %v, which can prevent network connectivity", drift))
-		log.Warn("Please enable network time synchronisation in system settings.")
-		return false
-	} else {
-		log.Debug("NTP sanity check done", "drift", drift)
-		return true
-	}
-}
-
 // SntpDrift does a naive time resolution against an NTP server and returns the
 // measured drift. This method uses the simple version of NTP. It's not precise
 // but should be fine for these purposes.
@@ -65,8 +45,12 @@ func checkClockDrift() bool{
 // Note, it executes two extra measurements compared to the number of requested
 // ones to be able to discard the two extremes as outliers.
 func SntpDrift(measurements int) (time.Duration, error) {
+	return sntpDrift(ntpPool, measurements)
+}
+
+func sntpDrift(server string, measurements int) (time.Duration, error) {
 	// Resolve the address of the NTP server
-	addr, err := net.ResolveUDPAddr("udp", ntpPool+":123")
+	addr, err := net.ResolveUDPAddr("udp", server+":123")
 	if err != nil {
 		return 0, err
 	}
@@ -5,6 +5,7 @@ import (
 	"github.com/golang/snappy"
 	"github.com/idena-network/idena-go/blockchain/types"
 	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/bandwidth"
 	"github.com/idena-network/idena-go/core/state/snapshot"
 	"github.com/idena-network/idena-go/log"
 	"github.com/idena-network/idena-go/rlp"
@@ -45,9 +46,10 @@ type protoPeer struct {
 	transportErr         error
 	peers                uint32
 	metrics              *metricCollector
+	bandwidth            *bandwidth.Scheduler
 }
 
-func newPeer(stream network.Stream, maxDelayMs int, metrics *metricCollector) *protoPeer {
+func newPeer(stream network.Stream, maxDelayMs int, metrics *metricCollector, bandwidth *bandwidth.Scheduler) *protoPeer {
 	stream.Conn().RemotePeer()
 	rw := msgio.NewReadWriter(stream)
 
@@ -64,6 +66,7 @@ func newPeer(stream network.Stream, maxDelayMs int, metrics *metricCollector) *p
 		log:                  log.New("id", stream.Conn().RemotePeer().Pretty()),
 		createdAt:            time.Now().UTC(),
 		metrics:              metrics,
+		bandwidth:            bandwidth,
 	}
 	return p
 }
@@ -97,6 +100,7 @@ func (p *protoPeer) broadcast() {
 	defer p.disconnect()
 	send := func(request *request) error {
 		msg := makeMsg(request.msgcode, request.data)
+		p.bandwidth.WaitUpload(msgSubsystem(request.msgcode), len(msg))
 
 		ch := make(chan error, 1)
 		timer := time.NewTimer(time.Minute)
@@ -149,6 +153,17 @@ func (p *protoPeer) broadcast() {
 	}
 }
 
+func msgSubsystem(msgcode uint64) bandwidth.Subsystem {
+	switch msgcode {
+	case GetBlocksRange, BlocksRange, GetForkBlockRange, GetBlockByHash, Block, SnapshotManifest:
+		return bandwidth.Sync
+	case FlipBody, FlipKey, FlipKeysPackage:
+		return bandwidth.Flips
+	default:
+		return bandwidth.Gossip
+	}
+}
+
 func makeMsg(msgcode uint64, payload interface{}) []byte {
 	data, err := rlp.EncodeToBytes(payload)
 	if err != nil {
@@ -204,6 +219,7 @@ func (p *protoPeer) ReadMsg() (*Msg, error) {
 		p.transportErr = err
 		return nil, err
 	}
+	size := len(msg)
 	msg, err = snappy.Decode(nil, msg)
 	if err != nil {
 		return nil, err
@@ -212,6 +228,7 @@ func (p *protoPeer) ReadMsg() (*Msg, error) {
 	if err := rlp.DecodeBytes(msg, result); err != nil {
 		return nil, err
 	}
+	p.bandwidth.WaitDownload(msgSubsystem(result.Code), size)
 	p.metrics.incomeMessage(result)
 	return result, nil
 }
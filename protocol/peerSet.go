@@ -84,17 +84,35 @@ func (ps *peerSet) Peers() []*protoPeer {
 	return list
 }
 
-func (ps *peerSet) SendWithFilter(msgcode uint64, payload interface{}, highPriority bool) {
+// SendWithFilter sends the message to peers which haven't seen it yet, returns ids of these peers
+func (ps *peerSet) SendWithFilter(msgcode uint64, payload interface{}, highPriority bool) []peer2.ID {
 
 	peers := ps.Peers()
 	key := msgKey(payload)
 
+	var sent []peer2.ID
 	for _, p := range peers {
 		if _, ok := p.msgCache.Get(key); !ok {
 			p.markKey(key)
 			p.sendMsg(msgcode, payload, highPriority)
+			sent = append(sent, p.id)
 		}
 	}
+	return sent
+}
+
+// SendToAll sends the message to all peers regardless of seen messages, returns ids of the peers
+func (ps *peerSet) SendToAll(msgcode uint64, payload interface{}, highPriority bool) []peer2.ID {
+	peers := ps.Peers()
+	key := msgKey(payload)
+
+	sent := make([]peer2.ID, 0, len(peers))
+	for _, p := range peers {
+		p.markKey(key)
+		p.sendMsg(msgcode, payload, highPriority)
+		sent = append(sent, p.id)
+	}
+	return sent
 }
 
 func (ps *peerSet) Send(msgcode uint64, payload interface{}) {
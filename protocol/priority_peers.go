@@ -0,0 +1,174 @@
+package protocol
+
+import (
+	"context"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/pkg/errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+const priorityPeerTag = "idena-priority"
+
+type PriorityPeer struct {
+	ID   string `json:"id"`
+	Addr string `json:"addr"`
+	// Static peers are always redialed
+	Static bool `json:"static"`
+	// Trusted peers are always accepted
+	Trusted   bool `json:"trusted"`
+	Connected bool `json:"connected"`
+}
+
+type priorityPeer struct {
+	info    peer.AddrInfo
+	addr    string
+	static  bool
+	trusted bool
+}
+
+// priorityPeers are static and trusted peers, they don't occupy peer slots, aren't evicted on peers renewal and aren't banned
+type priorityPeers struct {
+	mutex sync.RWMutex
+	peers map[peer.ID]*priorityPeer
+}
+
+func newPriorityPeers() *priorityPeers {
+	return &priorityPeers{
+		peers: make(map[peer.ID]*priorityPeer),
+	}
+}
+
+func parsePeerAddr(url string) (peer.AddrInfo, error) {
+	ma, err := multiaddr.NewMultiaddr(url)
+	if err != nil {
+		return peer.AddrInfo{}, err
+	}
+	transportAddr, peerId := peer.SplitAddr(ma)
+	if transportAddr == nil || peerId == "" {
+		return peer.AddrInfo{}, errors.New("invalid url")
+	}
+	return peer.AddrInfo{
+		ID:    peerId,
+		Addrs: []multiaddr.Multiaddr{transportAddr},
+	}, nil
+}
+
+func (p *priorityPeers) add(url string, static, trusted bool) (peer.ID, error) {
+	info, err := parsePeerAddr(url)
+	if err != nil {
+		return "", err
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if existing, ok := p.peers[info.ID]; ok {
+		static = static || existing.static
+		trusted = trusted || existing.trusted
+	}
+	p.peers[info.ID] = &priorityPeer{info: info, addr: url, static: static, trusted: trusted}
+	return info.ID, nil
+}
+
+func (p *priorityPeers) remove(id peer.ID) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	_, ok := p.peers[id]
+	delete(p.peers, id)
+	return ok
+}
+
+func (p *priorityPeers) contains(id peer.ID) bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	_, ok := p.peers[id]
+	return ok
+}
+
+func (p *priorityPeers) static() []peer.AddrInfo {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	var result []peer.AddrInfo
+	for _, item := range p.peers {
+		if item.static {
+			result = append(result, item.info)
+		}
+	}
+	return result
+}
+
+func (p *priorityPeers) list(connected func(id peer.ID) bool) []PriorityPeer {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	result := make([]PriorityPeer, 0, len(p.peers))
+	for id, item := range p.peers {
+		result = append(result, PriorityPeer{
+			ID:        id.Pretty(),
+			Addr:      item.addr,
+			Static:    item.static,
+			Trusted:   item.trusted,
+			Connected: connected(id),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// AddPriorityPeer adds the static or trusted peer by its multiaddress, the static peer is dialed immediately
+func (h *IdenaGossipHandler) AddPriorityPeer(url string, static, trusted bool) error {
+	if !static && !trusted {
+		return errors.New("peer should be static or trusted")
+	}
+	id, err := h.priority.add(url, static, trusted)
+	if err != nil {
+		return err
+	}
+	h.host.ConnManager().Protect(id, priorityPeerTag)
+	if static {
+		go h.dialStaticPeers()
+	}
+	return nil
+}
+
+// RemovePriorityPeer removes the static or trusted peer, the peer stays connected as a regular one
+func (h *IdenaGossipHandler) RemovePriorityPeer(id string) error {
+	peerId, err := peer.IDB58Decode(id)
+	if err != nil {
+		return errors.Wrap(err, "invalid peer id")
+	}
+	if !h.priority.remove(peerId) {
+		return errors.New("peer is not static or trusted")
+	}
+	h.host.ConnManager().Unprotect(peerId, priorityPeerTag)
+	return nil
+}
+
+func (h *IdenaGossipHandler) PriorityPeers() []PriorityPeer {
+	return h.priority.list(h.IsConnected)
+}
+
+func (h *IdenaGossipHandler) dialStaticPeers() {
+	for _, info := range h.priority.static() {
+		if h.IsConnected(info.ID) {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+		err := h.host.Connect(ctx, info)
+		cancel()
+		if err != nil {
+			h.log.Debug("Failed to dial static peer", "id", info.ID.Pretty(), "err", err)
+			continue
+		}
+		stream, err := h.connManager.newStream(info.ID)
+		if err != nil {
+			h.log.Debug("Failed to open stream to static peer", "id", info.ID.Pretty(), "err", err)
+			continue
+		}
+		if _, err := h.runPeer(stream, false); err != nil {
+			h.log.Debug("Failed to connect static peer", "id", info.ID.Pretty(), "err", err)
+		}
+	}
+}
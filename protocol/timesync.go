@@ -0,0 +1,114 @@
+package protocol
+
+import (
+	"fmt"
+	"github.com/idena-network/idena-go/config"
+	"github.com/idena-network/idena-go/log"
+	"github.com/rcrowley/go-metrics"
+	"sync"
+	"time"
+)
+
+type TimeSyncStatus struct {
+	Drift     time.Duration
+	Offset    time.Duration
+	WrongTime bool
+	LastCheck time.Time
+	Error     error
+}
+
+// TimeSync periodically measures local clock drift against NTP server
+type TimeSync struct {
+	cfg       *config.TimeSyncConfig
+	log       log.Logger
+	mutex     sync.RWMutex
+	drift     time.Duration
+	lastCheck time.Time
+	lastErr   error
+	measure   func(server string, measurements int) (time.Duration, error)
+}
+
+func NewTimeSync(cfg *config.TimeSyncConfig) *TimeSync {
+	return &TimeSync{
+		cfg:     cfg,
+		log:     log.New("component", "timesync"),
+		measure: sntpDrift,
+	}
+}
+
+func (t *TimeSync) Start() {
+	go t.loop()
+}
+
+func (t *TimeSync) loop() {
+	for {
+		t.check()
+		time.Sleep(t.cfg.CheckInterval)
+	}
+}
+
+func (t *TimeSync) check() {
+	drift, err := t.measure(t.cfg.NtpServer, ntpChecks)
+	t.mutex.Lock()
+	t.lastCheck = time.Now().UTC()
+	t.lastErr = err
+	if err == nil {
+		t.drift = drift
+	}
+	t.mutex.Unlock()
+
+	if err != nil {
+		t.log.Debug("NTP check failed", "err", err)
+		return
+	}
+	metrics.GetOrRegisterGauge("ntp.drift_ms", metrics.DefaultRegistry).Update(drift.Milliseconds())
+	if t.wrongTime(drift) {
+		t.log.Warn(fmt.Sprintf("System clock seems off by %v, which can prevent network connectivity and validation", drift))
+		t.log.Warn("Please enable network time synchronisation in system settings.")
+	} else {
+		t.log.Debug("NTP sanity check done", "drift", drift)
+	}
+}
+
+func (t *TimeSync) wrongTime(drift time.Duration) bool {
+	return drift < -t.cfg.DriftThreshold || drift > t.cfg.DriftThreshold
+}
+
+// Drift returns the last measured difference between local and NTP time, positive value means local clock is ahead
+func (t *TimeSync) Drift() time.Duration {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.drift
+}
+
+func (t *TimeSync) WrongTime() bool {
+	return t.wrongTime(t.Drift())
+}
+
+// Offset returns the correction applied to ceremony timers
+func (t *TimeSync) Offset() time.Duration {
+	if !t.cfg.ApplyOffset {
+		return 0
+	}
+	return t.Drift()
+}
+
+// Now returns current UTC time corrected by Offset
+func (t *TimeSync) Now() time.Time {
+	return time.Now().UTC().Add(-t.Offset())
+}
+
+func (t *TimeSync) Status() TimeSyncStatus {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	status := TimeSyncStatus{
+		Drift:     t.drift,
+		WrongTime: t.wrongTime(t.drift),
+		LastCheck: t.lastCheck,
+		Error:     t.lastErr,
+	}
+	if t.cfg.ApplyOffset {
+		status.Offset = t.drift
+	}
+	return status
+}
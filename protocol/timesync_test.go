@@ -0,0 +1,40 @@
+package protocol
+
+import (
+	"errors"
+	"github.com/idena-network/idena-go/config"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestTimeSync_Check(t *testing.T) {
+	cfg := config.GetDefaultTimeSyncConfig()
+	timeSync := NewTimeSync(cfg)
+
+	drift := 15 * time.Second
+	var measureErr error
+	timeSync.measure = func(server string, measurements int) (time.Duration, error) {
+		return drift, measureErr
+	}
+
+	timeSync.check()
+	require.Equal(t, drift, timeSync.Drift())
+	require.True(t, timeSync.WrongTime())
+	require.Zero(t, timeSync.Offset())
+
+	cfg.ApplyOffset = true
+	require.Equal(t, drift, timeSync.Offset())
+	require.WithinDuration(t, time.Now().UTC().Add(-drift), timeSync.Now(), time.Second)
+
+	measureErr = errors.New("timeout")
+	drift = time.Second
+	timeSync.check()
+	status := timeSync.Status()
+	require.Equal(t, 15*time.Second, status.Drift)
+	require.Equal(t, measureErr, status.Error)
+
+	measureErr = nil
+	timeSync.check()
+	require.False(t, timeSync.WrongTime())
+}
@@ -0,0 +1,121 @@
+package protocol
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"sync"
+	"time"
+)
+
+const (
+	maxTracedTxs        = 1000
+	maxTxTraceEvents    = 200
+	TxAnnounced         = "announced"
+	TxRebroadcast       = "rebroadcast"
+	TxSentDirect        = "direct"
+	txTraceLimitWarning = "events limit is reached"
+)
+
+type TxPeerEvent struct {
+	Peer string `json:"peer"`
+	Kind string `json:"kind,omitempty"`
+	Time int64  `json:"timestamp"`
+}
+
+// TxPropagation is the trace of local tx delivery, announcements are pushes of the tx hash, fetches are pull requests
+// of peers which didn't have the tx
+type TxPropagation struct {
+	Hash          common.Hash   `json:"hash"`
+	FirstSeen     int64         `json:"firstSeen"`
+	Rebroadcasts  int           `json:"rebroadcasts"`
+	Announcements []TxPeerEvent `json:"announcements"`
+	Fetches       []TxPeerEvent `json:"fetches"`
+	Warning       string        `json:"warning,omitempty"`
+}
+
+// txTracer keeps propagation traces of the latest local txs
+type txTracer struct {
+	mutex  sync.Mutex
+	traces map[common.Hash]*TxPropagation
+	order  []common.Hash
+	// hashes maps push-pull hashes of traced txs to tx hashes
+	hashes map[common.Hash128]common.Hash
+}
+
+func newTxTracer() *txTracer {
+	return &txTracer{
+		traces: make(map[common.Hash]*TxPropagation),
+		hashes: make(map[common.Hash128]common.Hash),
+	}
+}
+
+func (t *txTracer) trace(hash common.Hash, pushHash common.Hash128, now time.Time) *TxPropagation {
+	if trace, ok := t.traces[hash]; ok {
+		return trace
+	}
+	if len(t.order) >= maxTracedTxs {
+		oldest := t.order[0]
+		t.order = t.order[1:]
+		delete(t.traces, oldest)
+		for key, value := range t.hashes {
+			if value == oldest {
+				delete(t.hashes, key)
+				break
+			}
+		}
+	}
+	trace := &TxPropagation{
+		Hash:          hash,
+		FirstSeen:     now.Unix(),
+		Announcements: []TxPeerEvent{},
+		Fetches:       []TxPeerEvent{},
+	}
+	t.traces[hash] = trace
+	t.order = append(t.order, hash)
+	t.hashes[pushHash] = hash
+	return trace
+}
+
+func addTraceEvent(trace *TxPropagation, events []TxPeerEvent, event TxPeerEvent) []TxPeerEvent {
+	if len(events) >= maxTxTraceEvents {
+		trace.Warning = txTraceLimitWarning
+		return events
+	}
+	return append(events, event)
+}
+
+func (t *txTracer) announced(hash common.Hash, pushHash common.Hash128, kind string, peers []peer.ID, now time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	trace := t.trace(hash, pushHash, now)
+	if kind == TxRebroadcast {
+		trace.Rebroadcasts++
+	}
+	for _, id := range peers {
+		trace.Announcements = addTraceEvent(trace, trace.Announcements, TxPeerEvent{Peer: id.Pretty(), Kind: kind, Time: now.Unix()})
+	}
+}
+
+func (t *txTracer) fetched(pushHash common.Hash128, id peer.ID, now time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	hash, ok := t.hashes[pushHash]
+	if !ok {
+		return
+	}
+	trace := t.traces[hash]
+	trace.Fetches = addTraceEvent(trace, trace.Fetches, TxPeerEvent{Peer: id.Pretty(), Time: now.Unix()})
+}
+
+func (t *txTracer) get(hash common.Hash) *TxPropagation {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	trace, ok := t.traces[hash]
+	if !ok {
+		return nil
+	}
+	result := *trace
+	result.Announcements = append([]TxPeerEvent{}, trace.Announcements...)
+	result.Fetches = append([]TxPeerEvent{}, trace.Fetches...)
+	return &result
+}
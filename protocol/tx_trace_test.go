@@ -0,0 +1,46 @@
+package protocol
+
+import (
+	"github.com/idena-network/idena-go/common"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestTxTracer(t *testing.T) {
+	tracer := newTxTracer()
+	now := time.Unix(100, 0)
+	hash, pushHash := common.Hash{0x1}, common.Hash128{0x1}
+	peers := []peer.ID{peer.ID("peer1"), peer.ID("peer2")}
+
+	require.Nil(t, tracer.get(hash))
+	tracer.fetched(pushHash, peers[0], now)
+	require.Nil(t, tracer.get(hash))
+
+	tracer.announced(hash, pushHash, TxAnnounced, peers, now)
+	tracer.fetched(pushHash, peers[1], now.Add(time.Second))
+	tracer.announced(hash, pushHash, TxRebroadcast, peers[:1], now.Add(time.Minute))
+
+	trace := tracer.get(hash)
+	require.NotNil(t, trace)
+	require.Equal(t, now.Unix(), trace.FirstSeen)
+	require.Equal(t, 1, trace.Rebroadcasts)
+	require.Len(t, trace.Announcements, 3)
+	require.Equal(t, TxRebroadcast, trace.Announcements[2].Kind)
+	require.Equal(t, []TxPeerEvent{{Peer: peers[1].Pretty(), Time: now.Unix() + 1}}, trace.Fetches)
+
+	for i := 0; i < maxTxTraceEvents; i++ {
+		tracer.fetched(pushHash, peers[0], now)
+	}
+	trace = tracer.get(hash)
+	require.Len(t, trace.Fetches, maxTxTraceEvents)
+	require.NotEmpty(t, trace.Warning)
+
+	for i := 0; i < maxTracedTxs; i++ {
+		tracer.announced(common.Hash{0x2, byte(i), byte(i >> 8)}, common.Hash128{0x2, byte(i), byte(i >> 8)}, TxSentDirect, peers, now)
+	}
+	require.Nil(t, tracer.get(hash))
+	require.Len(t, tracer.traces, maxTracedTxs)
+	require.Len(t, tracer.hashes, maxTracedTxs)
+}
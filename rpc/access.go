@@ -0,0 +1,315 @@
+package rpc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	remoteCtxKey        = "remote"
+	authorizationCtxKey = "Authorization"
+	forwardedForCtxKey  = "X-Forwarded-For"
+	bucketsCleanupDelay = time.Minute
+)
+
+var (
+	errInvalidToken = errors.New("invalid token")
+	errTokenExpired = errors.New("token is expired")
+)
+
+type authenticatedCtxKey struct{}
+
+// IsAuthenticated reports if the request has been made with the valid API key or JWT
+func IsAuthenticated(ctx context.Context) bool {
+	authenticated, _ := ctx.Value(authenticatedCtxKey{}).(bool)
+	return authenticated
+}
+
+type RateLimitConfig struct {
+	// PerIP is the number of requests per second allowed from one IP, 0 - unlimited
+	PerIP float64
+	// Burst is the number of requests which can be sent at once
+	Burst int
+	// PerMethod sets the number of requests per second allowed from one IP by method pattern (e.g. flip_*)
+	PerMethod map[string]float64
+}
+
+// AccessPolicy authenticates requests by API key or JWT, checks the method allowlist and rate limits
+type AccessPolicy struct {
+	allow     []string
+	jwtSecret []byte
+	limitsMu  sync.RWMutex
+	perIP     *rateLimiter
+	perMethod map[string]*rateLimiter
+	// trusted are proxies which X-Forwarded-For header is used to get the client IP
+	trusted []*net.IPNet
+}
+
+func NewAccessPolicy(cfg *Config) (*AccessPolicy, error) {
+	policy := &AccessPolicy{
+		allow: cfg.Allow,
+	}
+	for _, proxy := range cfg.TrustedProxies {
+		cidr := proxy
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %v", proxy)
+		}
+		policy.trusted = append(policy.trusted, ipNet)
+	}
+	if cfg.JWTSecret != "" {
+		policy.jwtSecret = []byte(cfg.JWTSecret)
+	}
+	policy.SetRateLimit(cfg.RateLimit)
+	return policy, nil
+}
+
+// SetRateLimit replaces rate limits, counters of limited clients are reset
+func (p *AccessPolicy) SetRateLimit(cfg *RateLimitConfig) {
+	var perIP *rateLimiter
+	perMethod := make(map[string]*rateLimiter)
+	if cfg != nil {
+		if cfg.PerIP > 0 {
+			perIP = newRateLimiter(cfg.PerIP, cfg.Burst)
+		}
+		for pattern, limit := range cfg.PerMethod {
+			if limit > 0 {
+				perMethod[pattern] = newRateLimiter(limit, cfg.Burst)
+			}
+		}
+	}
+	p.limitsMu.Lock()
+	p.perIP, p.perMethod = perIP, perMethod
+	p.limitsMu.Unlock()
+}
+
+// matchMethod checks if method (service_method) matches the pattern, trailing * matches any suffix
+func matchMethod(pattern, method string) bool {
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(method, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == method
+}
+
+func (p *AccessPolicy) allowed(method string) bool {
+	if len(p.allow) == 0 {
+		return true
+	}
+	for _, pattern := range p.allow {
+		if matchMethod(pattern, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *AccessPolicy) authenticated(ctx context.Context) bool {
+	if p.jwtSecret == nil {
+		return false
+	}
+	auth, _ := ctx.Value(authorizationCtxKey).(string)
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return false
+	}
+	return verifyJWT(strings.TrimPrefix(auth, "Bearer "), p.jwtSecret, time.Now()) == nil
+}
+
+func (p *AccessPolicy) checkRateLimit(ctx context.Context, method string) bool {
+	ip := p.clientIP(ctx)
+	p.limitsMu.RLock()
+	defer p.limitsMu.RUnlock()
+	if p.perIP != nil && !p.perIP.allow(ip) {
+		return false
+	}
+	for pattern, limiter := range p.perMethod {
+		if matchMethod(pattern, method) && !limiter.allow(ip+"/"+pattern) {
+			return false
+		}
+	}
+	return true
+}
+
+// check verifies the request, the API key has already been checked by the server
+func (p *AccessPolicy) check(ctx context.Context, method string, validKey bool) Error {
+	if !validKey && !p.authenticated(ctx) {
+		return &invalidApiKeyError{}
+	}
+	if !p.allowed(method) {
+		return &methodNotAllowedError{method}
+	}
+	if !p.checkRateLimit(ctx, method) {
+		return &rateLimitError{}
+	}
+	return nil
+}
+
+func remoteIP(ctx context.Context) string {
+	remote, _ := ctx.Value(remoteCtxKey).(string)
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		return host
+	}
+	return remote
+}
+
+func (p *AccessPolicy) isTrusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, ipNet := range p.trusted {
+		if ipNet.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the remote IP, requests of trusted proxies are attributed to the last untrusted X-Forwarded-For hop
+func (p *AccessPolicy) clientIP(ctx context.Context) string {
+	ip := remoteIP(ctx)
+	if !p.isTrusted(ip) {
+		return ip
+	}
+	forwarded, _ := ctx.Value(forwardedForCtxKey).(string)
+	if forwarded == "" {
+		return ip
+	}
+	hops := strings.Split(forwarded, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		ip = hop
+		if !p.isTrusted(hop) {
+			break
+		}
+	}
+	return ip
+}
+
+type jwtClaims struct {
+	ExpiresAt int64 `json:"exp"`
+	NotBefore int64 `json:"nbf"`
+}
+
+// verifyJWT verifies HS256 signed token and its exp/nbf claims
+func verifyJWT(token string, secret []byte, now time.Time) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return errInvalidToken
+	}
+	headerData, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return errInvalidToken
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(headerData, &header); err != nil || header.Alg != "HS256" {
+		return errInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return errInvalidToken
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return errInvalidToken
+	}
+	claimsData, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return errInvalidToken
+	}
+	var claims jwtClaims
+	if err := json.Unmarshal(claimsData, &claims); err != nil {
+		return errInvalidToken
+	}
+	if claims.ExpiresAt > 0 && now.Unix() >= claims.ExpiresAt {
+		return errTokenExpired
+	}
+	if claims.NotBefore > 0 && now.Unix() < claims.NotBefore {
+		return errInvalidToken
+	}
+	return nil
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter is a token bucket limiter by key
+type rateLimiter struct {
+	mutex       sync.Mutex
+	rate        float64
+	burst       float64
+	buckets     map[string]*bucket
+	lastCleanup time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:        rate,
+		burst:       float64(burst),
+		buckets:     make(map[string]*bucket),
+		lastCleanup: time.Now(),
+	}
+}
+
+func (l *rateLimiter) allow(key string) bool {
+	return l.allowAt(key, time.Now())
+}
+
+func (l *rateLimiter) allowAt(key string, now time.Time) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if now.Sub(l.lastCleanup) > bucketsCleanupDelay {
+		l.cleanup(now)
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// cleanup removes buckets which have been refilled completely
+func (l *rateLimiter) cleanup(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastCleanup = now
+}
@@ -0,0 +1,132 @@
+package rpc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net"
+	"testing"
+	"time"
+)
+
+func signJWT(claims string, secret []byte) string {
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(claims))
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyJWT(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Unix(1000, 0)
+
+	require.NoError(t, verifyJWT(signJWT(`{"exp":1001}`, secret), secret, now))
+	require.Equal(t, errTokenExpired, verifyJWT(signJWT(`{"exp":1000}`, secret), secret, now))
+	require.Equal(t, errInvalidToken, verifyJWT(signJWT(`{"nbf":1001}`, secret), secret, now))
+	require.Equal(t, errInvalidToken, verifyJWT(signJWT(`{}`, []byte("other")), secret, now))
+	require.Equal(t, errInvalidToken, verifyJWT("invalid", secret, now))
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := newRateLimiter(2, 2)
+	now := time.Now()
+
+	require.True(t, limiter.allowAt("a", now))
+	require.True(t, limiter.allowAt("a", now))
+	require.False(t, limiter.allowAt("a", now))
+	require.True(t, limiter.allowAt("b", now))
+	require.True(t, limiter.allowAt("a", now.Add(time.Millisecond*500)))
+	require.False(t, limiter.allowAt("a", now.Add(time.Millisecond*500)))
+
+	limiter.allowAt("c", now.Add(time.Hour))
+	require.Len(t, limiter.buckets, 1)
+}
+
+func TestAccessPolicy_check(t *testing.T) {
+	secret := "secret"
+	policy, _ := NewAccessPolicy(&Config{
+		Allow:     []string{"bcn_*", "dna_identity"},
+		JWTSecret: secret,
+		RateLimit: &RateLimitConfig{
+			PerMethod: map[string]float64{"bcn_syncing": 0.001},
+		},
+	})
+	ctx := context.WithValue(context.Background(), remoteCtxKey, "127.0.0.1:1234")
+
+	require.Nil(t, policy.check(ctx, "bcn_lastBlock", true))
+	require.Nil(t, policy.check(ctx, "dna_identity", true))
+	require.IsType(t, &methodNotAllowedError{}, policy.check(ctx, "dna_sendTransaction", true))
+	require.IsType(t, &invalidApiKeyError{}, policy.check(ctx, "bcn_lastBlock", false))
+
+	authCtx := context.WithValue(ctx, authorizationCtxKey, "Bearer "+signJWT(`{}`, []byte(secret)))
+	require.Nil(t, policy.check(authCtx, "bcn_lastBlock", false))
+
+	require.Nil(t, policy.check(ctx, "bcn_syncing", true))
+	require.IsType(t, &rateLimitError{}, policy.check(ctx, "bcn_syncing", true))
+	require.Nil(t, policy.check(context.WithValue(ctx, remoteCtxKey, "127.0.0.2:1234"), "bcn_syncing", true))
+}
+
+func TestAccessPolicy_SetRateLimit(t *testing.T) {
+	policy, _ := NewAccessPolicy(&Config{})
+	ctx := context.WithValue(context.Background(), remoteCtxKey, "127.0.0.1:1234")
+
+	require.Nil(t, policy.check(ctx, "bcn_syncing", true))
+	require.Nil(t, policy.check(ctx, "bcn_syncing", true))
+
+	policy.SetRateLimit(&RateLimitConfig{PerIP: 0.001, Burst: 1})
+	require.Nil(t, policy.check(ctx, "bcn_syncing", true))
+	require.IsType(t, &rateLimitError{}, policy.check(ctx, "bcn_syncing", true))
+
+	policy.SetRateLimit(nil)
+	require.Nil(t, policy.check(ctx, "bcn_syncing", true))
+}
+
+func TestAccessPolicy_clientIP(t *testing.T) {
+	policy, err := NewAccessPolicy(&Config{
+		TrustedProxies: []string{"10.0.0.0/8", "192.168.1.1"},
+	})
+	require.NoError(t, err)
+
+	ctx := func(remote, forwarded string) context.Context {
+		ctx := context.WithValue(context.Background(), remoteCtxKey, remote)
+		return context.WithValue(ctx, forwardedForCtxKey, forwarded)
+	}
+	require.Equal(t, "1.1.1.1", policy.clientIP(ctx("1.1.1.1:80", "2.2.2.2")))
+	require.Equal(t, "2.2.2.2", policy.clientIP(ctx("192.168.1.1:80", "2.2.2.2")))
+	require.Equal(t, "3.3.3.3", policy.clientIP(ctx("10.1.1.1:80", "2.2.2.2, 3.3.3.3, 10.0.0.2")))
+	require.Equal(t, "10.0.0.3", policy.clientIP(ctx("10.1.1.1:80", "10.0.0.3, 10.0.0.2")))
+	require.Equal(t, "10.1.1.1", policy.clientIP(ctx("10.1.1.1:80", "")))
+
+	_, err = NewAccessPolicy(&Config{TrustedProxies: []string{"invalid"}})
+	require.Error(t, err)
+}
+
+type AuthService struct{}
+
+func (s *AuthService) Authenticated(ctx context.Context) bool {
+	return IsAuthenticated(ctx)
+}
+
+func TestIsAuthenticated(t *testing.T) {
+	call := func(apiKey string, key string) bool {
+		server := NewServer(apiKey)
+		require.NoError(t, server.RegisterName("test", new(AuthService)))
+		clientConn, serverConn := net.Pipe()
+		defer clientConn.Close()
+		go server.ServeCodec(NewJSONCodec(serverConn), OptionMethodInvocation)
+
+		request := map[string]interface{}{"id": 1, "method": "test_authenticated", "version": "2.0", "key": key}
+		require.NoError(t, json.NewEncoder(clientConn).Encode(request))
+		var result bool
+		response := jsonSuccessResponse{Result: &result}
+		require.NoError(t, json.NewDecoder(clientConn).Decode(&response))
+		return result
+	}
+	require.True(t, call("key", "key"))
+	require.False(t, call("", ""))
+	require.False(t, call("", "key"))
+}
@@ -79,6 +79,7 @@ type BatchElem struct {
 // A value of this type can a JSON-RPC request, notification, successful response or
 // error response. Which one it is depends on the fields.
 type jsonrpcMessage struct {
+	Key     string          `json:"key,omitempty"`
 	Version string          `json:"jsonrpc"`
 	ID      json.RawMessage `json:"id,omitempty"`
 	Method  string          `json:"method,omitempty"`
@@ -109,6 +110,7 @@ type Client struct {
 	idCounter   uint32
 	connectFunc func(ctx context.Context) (net.Conn, error)
 	isHTTP      bool
+	apiKey      atomic.Value
 
 	// writeConn is only safe to access outside dispatch, with the
 	// write lock held. The write lock is taken by sending on
@@ -413,7 +415,13 @@ func (c *Client) newMessage(method string, paramsIn ...interface{}) (*jsonrpcMes
 	if err != nil {
 		return nil, err
 	}
-	return &jsonrpcMessage{Version: "2.0", ID: c.nextID(), Method: method, Params: params}, nil
+	key, _ := c.apiKey.Load().(string)
+	return &jsonrpcMessage{Key: key, Version: "2.0", ID: c.nextID(), Method: method, Params: params}, nil
+}
+
+// SetApiKey sets the API key sent with all following requests
+func (c *Client) SetApiKey(key string) {
+	c.apiKey.Store(key)
 }
 
 // send registers op with the dispatch loop, then sends msg on the connection.
@@ -1,6 +1,9 @@
 package rpc
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type Config struct {
 	// HTTPCors is the Cross-Origin Resource Sharing header to send to requesting
@@ -36,6 +39,28 @@ type Config struct {
 	HTTPPort int `toml:",omitempty"`
 
 	APIKey string
+
+	// Allow is the list of allowed methods, trailing * matches any suffix (e.g. bcn_*), empty list allows all methods
+	Allow []string `toml:",omitempty"`
+
+	// JWTSecret enables authentication by HS256 signed JWT passed in Authorization header as an alternative to API key
+	JWTSecret string `toml:",omitempty"`
+
+	RateLimit *RateLimitConfig `toml:",omitempty"`
+
+	// TrustedProxies is the list of reverse proxy IPs or CIDRs which X-Forwarded-For header is trusted
+	TrustedProxies []string `toml:",omitempty"`
+
+	// TLSCertFile and TLSKeyFile enable TLS, files are reloaded when they are changed
+	TLSCertFile string `toml:",omitempty"`
+	TLSKeyFile  string `toml:",omitempty"`
+
+	// WSPath is the path of the HTTP endpoint which accepts websocket connections for subscriptions, empty path
+	// disables websocket connections
+	WSPath string `toml:",omitempty"`
+
+	// SlowCallThreshold is the duration calls exceeding which are logged with redacted params, 0 disables the log
+	SlowCallThreshold time.Duration `toml:",omitempty"`
 }
 
 func (c *Config) HTTPEndpoint() string {
@@ -48,11 +73,13 @@ func (c *Config) HTTPEndpoint() string {
 func GetDefaultRPCConfig(host string, port int) *Config {
 	// DefaultConfig contains reasonable default settings.
 	return &Config{
-		HTTPCors:         []string{"*"},
-		HTTPHost:         host,
-		HTTPPort:         port,
-		HTTPModules:      []string{"net", "dna", "account", "flip", "bcn"},
-		HTTPVirtualHosts: []string{"localhost"},
-		HTTPTimeouts:     DefaultHTTPTimeouts,
+		HTTPCors:          []string{"*"},
+		HTTPHost:          host,
+		HTTPPort:          port,
+		HTTPModules:       []string{"net", "dna", "account", "flip", "bcn", "pool", "consensus"},
+		HTTPVirtualHosts:  []string{"localhost"},
+		HTTPTimeouts:      DefaultHTTPTimeouts,
+		WSPath:            "/ws",
+		SlowCallThreshold: time.Second,
 	}
 }
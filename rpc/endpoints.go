@@ -17,13 +17,17 @@
 package rpc
 
 import (
+	"crypto/tls"
 	"net"
+	"net/http"
 
 	"github.com/idena-network/idena-go/log"
 )
 
-// StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules
-func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []string, vhosts []string, timeouts HTTPTimeouts, apiKey string) (net.Listener, *Server, error) {
+// StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules.
+// Routes are served by their own handlers without the API key check, websocket connections with subscriptions
+// are accepted at wsPath if it is set.
+func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []string, vhosts []string, timeouts HTTPTimeouts, apiKey string, access *AccessPolicy, tlsConfig *tls.Config, wsPath string, routes map[string]http.Handler) (net.Listener, *Server, error) {
 	// Generate the whitelist based on the allowed modules
 	whitelist := make(map[string]bool)
 	for _, module := range modules {
@@ -31,6 +35,7 @@ func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []str
 	}
 	// Register all the APIs exposed by the services
 	handler := NewServer(apiKey)
+	handler.SetAccessPolicy(access)
 	for _, api := range apis {
 		if whitelist[api.Namespace] || (len(whitelist) == 0 && api.Public) {
 			if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
@@ -47,7 +52,22 @@ func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []str
 	if listener, err = net.Listen("tcp", endpoint); err != nil {
 		return nil, nil, err
 	}
-	go NewHTTPServer(cors, vhosts, timeouts, handler).Serve(listener)
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	server := NewHTTPServer(cors, vhosts, timeouts, handler)
+	if len(routes) > 0 || wsPath != "" {
+		mux := http.NewServeMux()
+		for path, route := range routes {
+			mux.Handle(path, route)
+		}
+		if wsPath != "" {
+			mux.Handle(wsPath, handler.WebsocketHandler(cors))
+		}
+		mux.Handle("/", server.Handler)
+		server.Handler = mux
+	}
+	go server.Serve(listener)
 	return listener, handler, err
 }
 
@@ -71,3 +71,21 @@ type invalidApiKeyError struct{}
 func (e *invalidApiKeyError) ErrorCode() int { return -32800 }
 
 func (e *invalidApiKeyError) Error() string { return "the provided API key is invalid" }
+
+// method is not in the allowlist
+type methodNotAllowedError struct {
+	method string
+}
+
+func (e *methodNotAllowedError) ErrorCode() int { return -32801 }
+
+func (e *methodNotAllowedError) Error() string {
+	return fmt.Sprintf("the method %s is not allowed", e.method)
+}
+
+// too many requests
+type rateLimitError struct{}
+
+func (e *rateLimitError) ErrorCode() int { return -32802 }
+
+func (e *rateLimitError) Error() string { return "rate limit exceeded" }
@@ -244,6 +244,12 @@ func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if origin := r.Header.Get("Origin"); origin != "" {
 		ctx = context.WithValue(ctx, "Origin", origin)
 	}
+	if auth := r.Header.Get(authorizationCtxKey); auth != "" {
+		ctx = context.WithValue(ctx, authorizationCtxKey, auth)
+	}
+	if forwarded := r.Header.Get(forwardedForCtxKey); forwarded != "" {
+		ctx = context.WithValue(ctx, forwardedForCtxKey, forwarded)
+	}
 
 	body := io.LimitReader(r.Body, maxRequestContentLength)
 	codec := NewJSONCodec(&httpReadWriteNopCloser{body, w})
@@ -0,0 +1,185 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/idena-network/idena-go/log"
+	"github.com/rcrowley/go-metrics"
+)
+
+const redactedParam = "[redacted]"
+
+var (
+	// hex strings up to the hash length are kept in logs, they are addresses, hashes and numbers
+	loggedHexParam     = regexp.MustCompile("^0x[0-9a-fA-F]{0,64}$")
+	sensitiveParamName = regexp.MustCompile("(?i)pass|secret|key|token|signature|mnemonic|seed")
+)
+
+type methodMetrics struct {
+	calls   metrics.Counter
+	errors  metrics.Counter
+	latency metrics.Timer
+}
+
+// callMetrics records calls of RPC methods in the default metrics registry
+type callMetrics struct {
+	mutex   sync.RWMutex
+	methods map[string]*methodMetrics
+	// slowThreshold is the duration in nanoseconds calls exceeding which are logged, 0 disables the log
+	slowThreshold int64
+}
+
+func newCallMetrics() *callMetrics {
+	return &callMetrics{methods: make(map[string]*methodMetrics)}
+}
+
+func (m *callMetrics) method(name string) *methodMetrics {
+	m.mutex.RLock()
+	result, ok := m.methods[name]
+	m.mutex.RUnlock()
+	if ok {
+		return result
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if result, ok = m.methods[name]; !ok {
+		result = &methodMetrics{
+			calls:   metrics.GetOrRegisterCounter("rpc/"+name+"/calls", metrics.DefaultRegistry),
+			errors:  metrics.GetOrRegisterCounter("rpc/"+name+"/errors", metrics.DefaultRegistry),
+			latency: metrics.GetOrRegisterTimer("rpc/"+name+"/latency", metrics.DefaultRegistry),
+		}
+		m.methods[name] = result
+	}
+	return result
+}
+
+func (m *callMetrics) record(ctx context.Context, name string, duration time.Duration, failed bool, args []reflect.Value) {
+	method := m.method(name)
+	method.calls.Inc(1)
+	method.latency.Update(duration)
+	if failed {
+		method.errors.Inc(1)
+	}
+	if threshold := atomic.LoadInt64(&m.slowThreshold); threshold > 0 && int64(duration) > threshold {
+		log.Warn("Slow RPC call", "method", name, "duration", duration, "failed", failed,
+			"params", redactParams(args), "ip", ctx.Value("remote"))
+	}
+}
+
+// redactParams encodes call params to JSON keeping only the structure, numbers, booleans and short hex strings,
+// values of fields which look like secrets are removed as well
+func redactParams(args []reflect.Value) string {
+	params := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		data, err := json.Marshal(arg.Interface())
+		if err != nil {
+			params = append(params, redactedParam)
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			params = append(params, redactedParam)
+			continue
+		}
+		params = append(params, redactValue(value))
+	}
+	data, _ := json.Marshal(params)
+	return string(data)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if loggedHexParam.MatchString(v) {
+			return v
+		}
+		return redactedParam
+	case []interface{}:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+		return v
+	case map[string]interface{}:
+		for key, item := range v {
+			if sensitiveParamName.MatchString(key) {
+				v[key] = redactedParam
+			} else {
+				v[key] = redactValue(item)
+			}
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// SetSlowCallThreshold sets the duration calls exceeding which are logged with redacted params, 0 disables the log
+func (s *Server) SetSlowCallThreshold(threshold time.Duration) {
+	atomic.StoreInt64(&s.metrics.slowThreshold, int64(threshold))
+}
+
+type MethodStats struct {
+	Method string `json:"method"`
+	Calls  int64  `json:"calls"`
+	Errors int64  `json:"errors"`
+	// ErrorRate is the share of failed calls
+	ErrorRate float64 `json:"errorRate"`
+	// Latencies are in milliseconds, percentiles are calculated for recent calls
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+	// Rate1 is the one-minute moving average rate of calls per second
+	Rate1 float64 `json:"rate1"`
+}
+
+// Stats returns statistics of called methods, the most called methods first
+func (m *callMetrics) Stats() []MethodStats {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	result := make([]MethodStats, 0, len(m.methods))
+	for name, method := range m.methods {
+		latency := method.latency.Snapshot()
+		percentiles := latency.Percentiles([]float64{0.5, 0.95, 0.99})
+		stats := MethodStats{
+			Method: name,
+			Calls:  method.calls.Count(),
+			Errors: method.errors.Count(),
+			Mean:   latency.Mean() / float64(time.Millisecond),
+			P50:    percentiles[0] / float64(time.Millisecond),
+			P95:    percentiles[1] / float64(time.Millisecond),
+			P99:    percentiles[2] / float64(time.Millisecond),
+			Max:    float64(latency.Max()) / float64(time.Millisecond),
+			Rate1:  latency.Rate1(),
+		}
+		if stats.Calls > 0 {
+			stats.ErrorRate = float64(stats.Errors) / float64(stats.Calls)
+		}
+		result = append(result, stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Calls != result[j].Calls {
+			return result[i].Calls > result[j].Calls
+		}
+		return strings.Compare(result[i].Method, result[j].Method) < 0
+	})
+	return result
+}
+
+// Metrics returns per method call counts, error rates and latencies, the API key or JWT is required
+func (s *RPCService) Metrics(ctx context.Context) ([]MethodStats, error) {
+	if !IsAuthenticated(ctx) {
+		return nil, errors.New("authentication is required")
+	}
+	return s.server.metrics.Stats(), nil
+}
@@ -0,0 +1,45 @@
+package rpc
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRedactParams(t *testing.T) {
+	type args struct {
+		From     string `json:"from"`
+		Amount   float64
+		Password string `json:"password"`
+		Payload  string `json:"payload"`
+	}
+	params := []reflect.Value{
+		reflect.ValueOf("0x1a2b"),
+		reflect.ValueOf("plain text"),
+		reflect.ValueOf(args{From: "0xff", Amount: 1.5, Password: "0x01", Payload: "0x" + string(make([]byte, 70))}),
+		reflect.ValueOf(uint64(5)),
+	}
+	require.Equal(t, `["0x1a2b","[redacted]",{"Amount":1.5,"from":"0xff","password":"[redacted]","payload":"[redacted]"},5]`,
+		redactParams(params))
+}
+
+func TestCallMetrics_Stats(t *testing.T) {
+	m := newCallMetrics()
+	ctx := context.Background()
+	m.record(ctx, "test_metricsA", time.Millisecond, false, nil)
+	m.record(ctx, "test_metricsA", 3*time.Millisecond, true, nil)
+	m.record(ctx, "test_metricsB", time.Millisecond, false, nil)
+
+	stats := m.Stats()
+	require.Len(t, stats, 2)
+	require.Equal(t, "test_metricsA", stats[0].Method)
+	require.Equal(t, int64(2), stats[0].Calls)
+	require.Equal(t, int64(1), stats[0].Errors)
+	require.Equal(t, 0.5, stats[0].ErrorRate)
+	require.Equal(t, 2.0, stats[0].Mean)
+	require.Equal(t, 3.0, stats[0].Max)
+	require.Equal(t, "test_metricsB", stats[1].Method)
+	require.Equal(t, int64(0), stats[1].Errors)
+}
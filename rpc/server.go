@@ -24,6 +24,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	mapset "github.com/deckarep/golang-set"
 	"github.com/idena-network/idena-go/log"
@@ -45,11 +46,12 @@ const (
 // NewServer will create a new server instance with no registered handlers.
 func NewServer(apiKey string) *Server {
 	server := &Server{
-		apiKey:   apiKey,
 		services: make(serviceRegistry),
 		codecs:   mapset.NewSet(),
 		run:      1,
+		metrics:  newCallMetrics(),
 	}
+	server.SetApiKey(apiKey)
 
 	// register a default service which will provide meta information about the RPC service such as the services and
 	// methods it offers.
@@ -59,6 +61,20 @@ func NewServer(apiKey string) *Server {
 	return server
 }
 
+// SetAccessPolicy enables authentication by JWT, method allowlist and rate limits
+func (s *Server) SetAccessPolicy(policy *AccessPolicy) {
+	s.access = policy
+}
+
+// SetApiKey replaces the API key, requests being read with the previous key are still served
+func (s *Server) SetApiKey(apiKey string) {
+	s.apiKey.Store(apiKey)
+}
+
+func (s *Server) ApiKey() string {
+	return s.apiKey.Load().(string)
+}
+
 // RPCService gives meta information about the server.
 // e.g. gives information about the loaded modules.
 type RPCService struct {
@@ -158,7 +174,7 @@ func (s *Server) serveRequest(ctx context.Context, codec ServerCodec, singleShot
 
 	// test if the server is ordered to stop
 	for atomic.LoadInt32(&s.run) == 1 {
-		reqs, batch, err := s.readRequest(codec)
+		reqs, batch, err := s.readRequest(ctx, codec)
 		if err != nil {
 			// If a parsing error occurred, send an error
 			if err.Error() != "EOF" {
@@ -300,6 +316,9 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 
 	arguments := []reflect.Value{req.callb.rcvr}
 	if req.callb.hasCtx {
+		if req.authenticated {
+			ctx = context.WithValue(ctx, authenticatedCtxKey{}, true)
+		}
 		arguments = append(arguments, reflect.ValueOf(ctx))
 	}
 	if len(req.args) > 0 {
@@ -307,20 +326,34 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 	}
 
 	// execute RPC method and return result
+	start := time.Now()
 	reply := req.callb.method.Func.Call(arguments)
+	failed := req.callb.errPos >= 0 && len(reply) > 0 && !reply[req.callb.errPos].IsNil()
+	s.metrics.record(ctx, req.svcname+serviceMethodSeparator+formatName(req.callb.method.Name), time.Since(start), failed, req.args)
 	if len(reply) == 0 {
 		return codec.CreateResponse(req.id, nil), nil
 	}
 	if req.callb.errPos >= 0 { // test if method returned an error
-		if !reply[req.callb.errPos].IsNil() {
+		if failed {
 			e := reply[req.callb.errPos].Interface().(error)
-			res := codec.CreateErrorResponse(&req.id, &callbackError{e.Error()})
-			return res, nil
+			return callbackErrorResponse(codec, &req.id, e), nil
 		}
 	}
 	return codec.CreateResponse(req.id, reply[0].Interface()), nil
 }
 
+// callbackErrorResponse keeps the code and data of errors implementing Error and DataError
+func callbackErrorResponse(codec ServerCodec, id interface{}, e error) interface{} {
+	err, ok := e.(Error)
+	if !ok {
+		err = &callbackError{e.Error()}
+	}
+	if dataErr, ok := e.(DataError); ok {
+		return codec.CreateErrorResponseWithInfo(id, err, dataErr.ErrorData())
+	}
+	return codec.CreateErrorResponse(id, err)
+}
+
 // exec executes the given request and writes the result back using the codec.
 func (s *Server) exec(ctx context.Context, codec ServerCodec, req *serverRequest) {
 	var response interface{}
@@ -372,13 +405,14 @@ func (s *Server) execBatch(ctx context.Context, codec ServerCodec, requests []*s
 // readRequest requests the next (batch) request from the codec. It will return the collection
 // of requests, an indication if the request was a batch, the invalid request identifier and an
 // error when the request could not be read/parsed.
-func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error) {
+func (s *Server) readRequest(ctx context.Context, codec ServerCodec) ([]*serverRequest, bool, Error) {
 	reqs, batch, err := codec.ReadRequestHeaders()
 	if err != nil {
 		return nil, batch, err
 	}
 
 	requests := make([]*serverRequest, len(reqs))
+	apiKey := s.ApiKey()
 
 	// verify requests
 	for i, r := range reqs {
@@ -390,7 +424,13 @@ func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error)
 			continue
 		}
 
-		if s.apiKey != "" && r.key != s.apiKey {
+		validKey := apiKey == "" || r.key == apiKey
+		if s.access != nil {
+			if err := s.access.check(ctx, r.service+serviceMethodSeparator+r.method, validKey); err != nil {
+				requests[i] = &serverRequest{id: r.id, err: err}
+				continue
+			}
+		} else if !validKey {
 			requests[i] = &serverRequest{id: r.id, err: &invalidApiKeyError{}}
 			continue
 		}
@@ -430,7 +470,8 @@ func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error)
 		}
 
 		if callb, ok := svc.callbacks[r.method]; ok { // lookup RPC method
-			requests[i] = &serverRequest{id: r.id, svcname: svc.name, callb: callb}
+			authenticated := apiKey != "" && r.key == apiKey || s.access != nil && s.access.authenticated(ctx)
+			requests[i] = &serverRequest{id: r.id, svcname: svc.name, callb: callb, authenticated: authenticated}
 			if r.params != nil && len(callb.argTypes) > 0 {
 				if args, err := codec.ParseRequestArguments(callb.argTypes, r.params); err == nil {
 					requests[i].args = args
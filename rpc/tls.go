@@ -0,0 +1,82 @@
+package rpc
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/idena-network/idena-go/log"
+)
+
+const certCheckInterval = 10 * time.Second
+
+// certReloader loads TLS certificate and reloads it when cert or key files are changed
+type certReloader struct {
+	certFile  string
+	keyFile   string
+	mutex     sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+// NewTLSConfig creates TLS config which picks up renewed certificate without restart
+func NewTLSConfig(certFile, keyFile string) (*tls.Config, error) {
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.getCertificate,
+	}, nil
+}
+
+func (r *certReloader) filesModTime() (time.Time, error) {
+	var result time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(result) {
+			result = info.ModTime()
+		}
+	}
+	return result, nil
+}
+
+func (r *certReloader) load() error {
+	modTime, err := r.filesModTime()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.cert = &cert
+	r.modTime = modTime
+	return nil
+}
+
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if time.Since(r.lastCheck) < certCheckInterval {
+		return r.cert, nil
+	}
+	r.lastCheck = time.Now()
+	if modTime, err := r.filesModTime(); err == nil && modTime.After(r.modTime) {
+		if err := r.load(); err != nil {
+			log.Warn("Cannot reload RPC TLS certificate, previous certificate is used", "err", err)
+		} else {
+			log.Info("RPC TLS certificate reloaded")
+		}
+	}
+	return r.cert, nil
+}
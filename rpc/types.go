@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	mapset "github.com/deckarep/golang-set"
 	"github.com/idena-network/idena-go/common/hexutil"
@@ -60,6 +61,8 @@ type serverRequest struct {
 	callb         *callback
 	args          []reflect.Value
 	isUnsubscribe bool
+	// authenticated is set if the request has the valid API key or JWT
+	authenticated bool
 	err           Error
 }
 
@@ -70,7 +73,10 @@ type subscriptions map[string]*callback  // collection of subscription callbacks
 // Server represents a RPC server
 type Server struct {
 	services serviceRegistry
-	apiKey   string
+	// apiKey holds the string key, it's replaced on the key rotation
+	apiKey  atomic.Value
+	access  *AccessPolicy
+	metrics *callMetrics
 
 	run      int32
 	codecsMu sync.Mutex
@@ -94,6 +100,12 @@ type Error interface {
 	ErrorCode() int // returns the code
 }
 
+// DataError is implemented by errors of callbacks which carry structured data in addition to the message
+type DataError interface {
+	Error() string
+	ErrorData() interface{}
+}
+
 // ServerCodec implements reading, parsing and writing RPC messages for the server side of
 // a RPC session. Implementations must be go-routine safe since the codec can be called in
 // multiple go-routines concurrently.
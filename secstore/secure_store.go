@@ -2,6 +2,7 @@ package secstore
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/awnumar/memguard"
 	"github.com/idena-network/idena-go/blockchain/types"
@@ -10,10 +11,21 @@ import (
 	"github.com/idena-network/idena-go/crypto/ecies"
 	"github.com/idena-network/idena-go/crypto/vrf/p256"
 	"os"
+	"sync"
+	"time"
 )
 
+// ErrSigningLocked is returned by SignMessage until message signing by the node key is unlocked
+var ErrSigningLocked = errors.New("authentication needed: unlock message signing by the node key")
+
 type SecStore struct {
 	buffer *memguard.LockedBuffer
+
+	signingMutex sync.Mutex
+	// signingUnlocked allows signing of arbitrary messages by the node key till signingUnlockedTill, zero time means
+	// until LockSigning is called
+	signingUnlocked     bool
+	signingUnlockedTill time.Time
 }
 
 func NewSecStore() *SecStore {
@@ -70,6 +82,34 @@ func (s *SecStore) Sign(data []byte) []byte {
 	return sig
 }
 
+// UnlockSigning allows SignMessage to sign by the node key for the timeout, 0 - until LockSigning is called
+func (s *SecStore) UnlockSigning(timeout time.Duration) {
+	s.signingMutex.Lock()
+	defer s.signingMutex.Unlock()
+	s.signingUnlocked = true
+	s.signingUnlockedTill = time.Time{}
+	if timeout > 0 {
+		s.signingUnlockedTill = time.Now().Add(timeout)
+	}
+}
+
+func (s *SecStore) LockSigning() {
+	s.signingMutex.Lock()
+	defer s.signingMutex.Unlock()
+	s.signingUnlocked = false
+}
+
+// SignMessage signs the hash of the message requested by the user, unlike Sign it is refused while signing is locked
+func (s *SecStore) SignMessage(hash []byte) ([]byte, error) {
+	s.signingMutex.Lock()
+	unlocked := s.signingUnlocked && (s.signingUnlockedTill.IsZero() || time.Now().Before(s.signingUnlockedTill))
+	s.signingMutex.Unlock()
+	if !unlocked {
+		return nil, ErrSigningLocked
+	}
+	return s.Sign(hash), nil
+}
+
 func (s *SecStore) Destroy() {
 	if s.buffer != nil {
 		s.buffer.Destroy()
@@ -4,6 +4,7 @@ import (
 	"github.com/idena-network/idena-go/crypto"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestSecStore_VrfEvaluate(t *testing.T) {
@@ -16,3 +17,29 @@ func TestSecStore_VrfEvaluate(t *testing.T) {
 	require.Equal(t, index, index2)
 	require.NotEqual(t, proof, proof2)
 }
+
+func TestSecStore_SignMessage(t *testing.T) {
+	secStore := NewSecStore()
+	key, _ := crypto.GenerateKey()
+	secStore.AddKey(crypto.FromECDSA(key))
+	hash := crypto.Keccak256([]byte{0x1})
+
+	_, err := secStore.SignMessage(hash)
+	require.Equal(t, ErrSigningLocked, err)
+
+	secStore.UnlockSigning(0)
+	signature, err := secStore.SignMessage(hash)
+	require.NoError(t, err)
+	require.Equal(t, secStore.Sign(hash), signature)
+
+	secStore.LockSigning()
+	_, err = secStore.SignMessage(hash)
+	require.Equal(t, ErrSigningLocked, err)
+
+	secStore.UnlockSigning(time.Millisecond * 50)
+	_, err = secStore.SignMessage(hash)
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond * 100)
+	_, err = secStore.SignMessage(hash)
+	require.Equal(t, ErrSigningLocked, err)
+}